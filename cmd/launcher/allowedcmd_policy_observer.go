@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/kolide/launcher/v2/ee/agent/flags/keys"
+	"github.com/kolide/launcher/v2/ee/agent/types"
+	"github.com/kolide/launcher/v2/ee/allowedcmd"
+)
+
+// allowedcmdPolicyObserver watches for changes to the AllowedCommandsAllowlist,
+// AllowedCommandsDenylist, and AllowedCommandsVerifyIntegrity flags, and applies them
+// at runtime without requiring a restart
+type allowedcmdPolicyObserver struct {
+	slogger  *slog.Logger
+	knapsack types.Knapsack
+}
+
+func newAllowedcmdPolicyObserver(slogger *slog.Logger, k types.Knapsack) *allowedcmdPolicyObserver {
+	return &allowedcmdPolicyObserver{
+		slogger:  slogger.With("component", "allowedcmd_policy_observer"),
+		knapsack: k,
+	}
+}
+
+func (a *allowedcmdPolicyObserver) FlagsChanged(ctx context.Context, _ ...keys.FlagKey) {
+	a.applyPolicy(ctx)
+}
+
+// applyPolicy sets the allowedcmd policy from the current flag values.
+func (a *allowedcmdPolicyObserver) applyPolicy(ctx context.Context) {
+	allowlist := allowedcmd.ParsePolicyList(a.knapsack.AllowedCommandsAllowlist())
+	denylist := allowedcmd.ParsePolicyList(a.knapsack.AllowedCommandsDenylist())
+
+	if len(allowlist) > 0 || len(denylist) > 0 {
+		a.slogger.Log(ctx, slog.LevelInfo,
+			"applying allowed command policy",
+			"allowlist", allowlist,
+			"denylist", denylist,
+		)
+	}
+
+	allowedcmd.SetPolicy(allowlist, denylist)
+	allowedcmd.SetIntegrityVerification(a.knapsack.AllowedCommandsVerifyIntegrity(), a.slogger)
+}
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
@@ -10,6 +12,7 @@ import (
 	"os"
 	"os/signal"
 	"os/user"
+	"path/filepath"
 	"runtime"
 	"strconv"
 	"time"
@@ -19,6 +22,7 @@ import (
 	runnerserver "github.com/kolide/launcher/v2/ee/desktop/runner/server"
 	"github.com/kolide/launcher/v2/ee/desktop/user/menu"
 	"github.com/kolide/launcher/v2/ee/desktop/user/notify"
+	"github.com/kolide/launcher/v2/ee/desktop/user/secretstore"
 	userserver "github.com/kolide/launcher/v2/ee/desktop/user/server"
 	"github.com/kolide/launcher/v2/ee/desktop/user/universallink"
 	"github.com/kolide/launcher/v2/ee/gowrapper"
@@ -77,6 +81,11 @@ func runDesktop(_ *multislogger.MultiSlogger, args []string) error {
 			"",
 			"path to icon file",
 		)
+		flIdentifier = flagset.String(
+			"identifier",
+			"",
+			"launcher identifier, used to find the presence detection polkit action",
+		)
 		flDesktopEnabled = flagset.Bool(
 			"desktop_enabled",
 			false,
@@ -120,6 +129,8 @@ func runDesktop(_ *multislogger.MultiSlogger, args []string) error {
 		"starting",
 	)
 
+	// Hold on to the runner-provided socket path, if any, to locate the user's secrets
+	runnerSocketPath := *flUserServerSocketPath
 	if *flUserServerSocketPath == "" {
 		*flUserServerSocketPath = defaultUserServerSocketPath()
 		slogger.Log(context.TODO(), slog.LevelInfo,
@@ -154,9 +165,12 @@ func runDesktop(_ *multislogger.MultiSlogger, args []string) error {
 
 	// Set up notification sending and listening
 	notifier := notify.NewDesktopNotifier(slogger, *flIconPath, *flLocalizationPath)
+	notifier.SetResponseHandler(func(response notify.Response) {
+		sendNotificationResponseToRunnerServer(slogger, *flRunnerServerUrl, *flRunnerServerAuthToken, response)
+	})
 	runGroup.Add("desktopNotifier", notifier.Execute, notifier.Interrupt)
 
-	server, err := userserver.New(slogger, *flUserServerAuthToken, *flUserServerSocketPath, shutdownChan, showDesktopChan, notifier)
+	server, err := userserver.New(slogger, *flUserServerAuthToken, *flUserServerSocketPath, *flIdentifier, shutdownChan, showDesktopChan, notifier)
 	if err != nil {
 		slogger.Log(context.TODO(), slog.LevelError,
 			"could not create user server",
@@ -165,6 +179,24 @@ func runDesktop(_ *multislogger.MultiSlogger, args []string) error {
 		return fmt.Errorf("creating server: %w", err)
 	}
 
+	if secretsDir, err := userSecretsDir(runnerSocketPath); err != nil {
+		slogger.Log(context.TODO(), slog.LevelWarn,
+			"could not determine directory for user secrets, secret storage will be unavailable",
+			"err", err,
+		)
+	} else if store, err := secretstore.New(slogger, secretsDir); err != nil {
+		slogger.Log(context.TODO(), slog.LevelWarn,
+			"could not create secret store, secret storage will be unavailable",
+			"err", err,
+		)
+	} else {
+		slogger.Log(context.TODO(), slog.LevelInfo,
+			"initialized user secret store",
+			"backend", store.Backend(),
+		)
+		server.SetSecretStore(store)
+	}
+
 	universalLinkHandler, urlInput := universallink.NewUniversalLinkHandler(slogger)
 	runGroup.Add("universalLinkHandler", universalLinkHandler.Execute, universalLinkHandler.Interrupt)
 	// Pass through channel so that systray can alert the link handler when it receives a universal link request
@@ -280,6 +312,54 @@ func notifyRunnerServerMenuOpened(slogger *slog.Logger, rootServerUrl, authToken
 	}
 }
 
+// sendNotificationResponseToRunnerServer reports the user's response to a notification to the runner
+// server, which forwards it on to the control server.
+func sendNotificationResponseToRunnerServer(slogger *slog.Logger, runnerServerUrl, authToken string, response notify.Response) {
+	client := authedclient.New(authToken, 5*time.Second)
+	notificationResponseUrl := fmt.Sprintf("%s%s", runnerServerUrl, runnerserver.NotificationResponseEndpoint)
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		slogger.Log(context.TODO(), slog.LevelError,
+			"marshalling notification response",
+			"err", err,
+		)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), client.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, notificationResponseUrl, bytes.NewReader(body))
+	if err != nil {
+		slogger.Log(context.TODO(), slog.LevelError,
+			"creating notification response request",
+			"err", err,
+		)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	httpResponse, err := client.Do(req)
+	if err != nil {
+		slogger.Log(context.TODO(), slog.LevelError,
+			"sending notification response to root server",
+			"notification_id", response.NotificationID,
+			"err", err,
+		)
+		return
+	}
+	defer httpResponse.Body.Close()
+
+	if httpResponse.StatusCode != http.StatusOK {
+		slogger.Log(context.TODO(), slog.LevelError,
+			"sending notification response to root server",
+			"notification_id", response.NotificationID,
+			"status_code", httpResponse.StatusCode,
+		)
+	}
+}
+
 // monitorParentProcess continuously checks to see if parent is a live and sends on provided channel if it is not
 func monitorParentProcess(slogger *slog.Logger, runnerServerUrl, runnerServerAuthToken string, interval time.Duration) {
 	ticker := time.NewTicker(interval)
@@ -355,6 +435,23 @@ func defaultUserServerSocketPath() string {
 	return agent.TempPath(fmt.Sprintf("%s_%d", socketBaseName, os.Getpid()))
 }
 
+// userSecretsDir returns the directory for the fallback file secret store. On posix,
+// the runner places the user server socket in a per-user directory that is owned by the user
+// and preserved across desktop restarts, so we keep secrets alongside it. Windows uses a
+// named pipe, and desktop may be run without the runner, so otherwise we use the user's
+// config directory.
+func userSecretsDir(runnerSocketPath string) (string, error) {
+	if runtime.GOOS == "windows" || runnerSocketPath == "" {
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			return "", fmt.Errorf("getting user config dir: %w", err)
+		}
+		return filepath.Join(configDir, "Kolide", "secrets"), nil
+	}
+
+	return filepath.Join(filepath.Dir(runnerSocketPath), "secrets"), nil
+}
+
 // applyGomaxprocs reads the GOMAXPROCS environment variable and applies it via gomaxprocsLimiter
 // to ensure proper logging. If GOMAXPROCS is not set, Go will use the number of CPUs by default.
 func applyGomaxprocs(slogger *slog.Logger) {
@@ -2,8 +2,11 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"strings"
 
 	"github.com/kolide/launcher/v2/ee/agent/flags"
 	"github.com/kolide/launcher/v2/ee/agent/knapsack"
@@ -12,6 +15,21 @@ import (
 	"github.com/kolide/launcher/v2/pkg/log/multislogger"
 )
 
+const (
+	doctorFormatText  = "text"
+	doctorFormatJSON  = "json"
+	doctorFormatJUnit = "junit"
+)
+
+// Exit codes for doctor reflect the worst checkup status, so that scripts can gate on them.
+// 1 is reserved for doctor itself failing to run.
+const (
+	doctorExitPassing  = 0
+	doctorExitWarning  = 2
+	doctorExitFailing  = 3
+	doctorExitErroring = 4
+)
+
 func runDoctor(systemMultiSlogger *multislogger.MultiSlogger, args []string) error {
 	attachConsole()
 	defer detachConsole()
@@ -21,7 +39,12 @@ func runDoctor(systemMultiSlogger *multislogger.MultiSlogger, args []string) err
 	launcher.DefaultAutoupdate = true
 	launcher.SetDefaultPaths()
 
-	opts, err := launcher.ParseOptions("doctor", os.Args[2:])
+	format, optionArgs, err := extractFormatArg(args)
+	if err != nil {
+		return err
+	}
+
+	opts, err := launcher.ParseOptions("doctor", optionArgs)
 	if err != nil {
 		return err
 	}
@@ -33,8 +56,12 @@ func runDoctor(systemMultiSlogger *multislogger.MultiSlogger, args []string) err
 		slogLevel = slog.LevelDebug
 	}
 
-	// Add handler to write to stdout
-	systemMultiSlogger.AddHandler(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+	// Add handler to write to stdout -- or stderr, if stdout is reserved for machine-readable output
+	var logOut io.Writer = os.Stdout
+	if format != doctorFormatText {
+		logOut = os.Stderr
+	}
+	systemMultiSlogger.AddHandler(slog.NewTextHandler(logOut, &slog.HandlerOptions{
 		Level:     slogLevel,
 		AddSource: true,
 	}))
@@ -45,7 +72,78 @@ func runDoctor(systemMultiSlogger *multislogger.MultiSlogger, args []string) err
 	w := os.Stdout //tabwriter.NewWriter(os.Stdout, 0, 8, 1, '\t', tabwriter.AlignRight)
 
 	ctx := context.Background()
-	checkups.RunDoctor(ctx, k, w)
+
+	var worstStatus checkups.Status
+	switch format {
+	case doctorFormatJSON:
+		report := checkups.RunDoctorReport(ctx, k)
+		if err := report.WriteJSON(w); err != nil {
+			return err
+		}
+		worstStatus = report.Status
+	case doctorFormatJUnit:
+		report := checkups.RunDoctorReport(ctx, k)
+		if err := report.WriteJUnit(w); err != nil {
+			return err
+		}
+		worstStatus = report.Status
+	default:
+		worstStatus = checkups.RunDoctor(ctx, k, w)
+	}
+
+	if exitCode := doctorExitCode(worstStatus); exitCode != doctorExitPassing {
+		return &exitCodeError{
+			code: exitCode,
+			err:  fmt.Errorf("doctor checkups reported status %s", worstStatus),
+		}
+	}
 
 	return nil
 }
+
+// doctorExitCode maps the worst checkup status to doctor's exit code.
+func doctorExitCode(worstStatus checkups.Status) int {
+	switch worstStatus {
+	case checkups.Warning:
+		return doctorExitWarning
+	case checkups.Failing:
+		return doctorExitFailing
+	case checkups.Erroring:
+		return doctorExitErroring
+	case checkups.Unknown, checkups.Informational, checkups.Passing:
+		return doctorExitPassing
+	default:
+		return doctorExitPassing
+	}
+}
+
+// extractFormatArg removes the doctor-only `--format` flag from args, so that the remaining
+// args can be parsed as launcher options.
+func extractFormatArg(args []string) (string, []string, error) {
+	format := doctorFormatText
+	remaining := make([]string, 0, len(args))
+
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(args[i], "-"), "=")
+		if !strings.HasPrefix(args[i], "-") || name != "format" {
+			remaining = append(remaining, args[i])
+			continue
+		}
+
+		if !hasValue {
+			if i+1 >= len(args) {
+				return "", nil, fmt.Errorf("flag needs an argument: %s", args[i])
+			}
+			i += 1
+			value = args[i]
+		}
+		format = strings.ToLower(value)
+	}
+
+	switch format {
+	case doctorFormatText, doctorFormatJSON, doctorFormatJUnit:
+		return format, remaining, nil
+	default:
+		return "", nil, fmt.Errorf(`invalid format %q, expected "text", "json", or "junit"`, format)
+	}
+}
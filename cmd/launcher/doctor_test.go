@@ -0,0 +1,72 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/kolide/launcher/v2/ee/debug/checkups"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_extractFormatArg(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name              string
+		args              []string
+		expectedFormat    string
+		expectedRemaining []string
+		expectErr         bool
+	}{
+		{
+			name:              "default",
+			args:              []string{"-config", "/etc/kolide-k2/launcher.flags"},
+			expectedFormat:    "text",
+			expectedRemaining: []string{"-config", "/etc/kolide-k2/launcher.flags"},
+		},
+		{
+			name:              "equals",
+			args:              []string{"--format=json", "-debug"},
+			expectedFormat:    "json",
+			expectedRemaining: []string{"-debug"},
+		},
+		{
+			name:              "separate value",
+			args:              []string{"-config", "launcher.flags", "-format", "JUnit"},
+			expectedFormat:    "junit",
+			expectedRemaining: []string{"-config", "launcher.flags"},
+		},
+		{
+			name:      "missing value",
+			args:      []string{"--format"},
+			expectErr: true,
+		},
+		{
+			name:      "invalid format",
+			args:      []string{"--format=yaml"},
+			expectErr: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			format, remaining, err := extractFormatArg(tt.args)
+			if tt.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedFormat, format)
+			require.Equal(t, tt.expectedRemaining, remaining)
+		})
+	}
+}
+
+func Test_doctorExitCode(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, 0, doctorExitCode(checkups.Passing))
+	require.Equal(t, 0, doctorExitCode(checkups.Informational))
+	require.Equal(t, 2, doctorExitCode(checkups.Warning))
+	require.Equal(t, 3, doctorExitCode(checkups.Failing))
+	require.Equal(t, 4, doctorExitCode(checkups.Erroring))
+}
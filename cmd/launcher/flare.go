@@ -38,6 +38,11 @@ func runFlare(systemMultiSlogger *multislogger.MultiSlogger, args []string) erro
 		flOutputDir        = flagset.String("output_dir", ".", "path to directory to save flare output")
 		flUploadRequestURL = flagset.String("upload_request_url", "https://api.kolide.com/api/agent/flare", "URL to request a signed upload URL")
 		flConfigFilePath   = flagset.String("config", launcher.DefaultConfigFilePath, "config file to parse options from (optional)")
+		flRedactionRules   = flagset.String("redaction_rules", "", "path to a JSON file with additional flare redaction rules (optional)")
+		flInclude          = flagset.String("include", "", "comma-separated checkup names to include, e.g. osquery,network (optional)")
+		flExclude          = flagset.String("exclude", "", "comma-separated checkup names to exclude (optional)")
+		flLogWindow        = flagset.Duration("log_window", 0, "only collect logs from this far back, e.g. 24h (optional)")
+		flSizeBudgetKB     = flagset.Int("size_budget_kb", 0, "limit the flare to roughly this size, skipping expensive checkups (optional)")
 	)
 
 	if err := ff.Parse(flagset, args); err != nil {
@@ -64,6 +69,16 @@ func runFlare(systemMultiSlogger *multislogger.MultiSlogger, args []string) erro
 	flagController := flags.NewFlagController(systemMultiSlogger.Logger, inmemory.NewStore(), fcOpts...)
 
 	k := knapsack.New(nil, flagController, nil, nil, nil)
+
+	if *flRedactionRules != "" {
+		rules, err := os.ReadFile(*flRedactionRules)
+		if err != nil {
+			return fmt.Errorf("reading redaction rules file %s: %w", *flRedactionRules, err)
+		}
+		if err := k.SetFlareRedactionRules(string(rules)); err != nil {
+			return fmt.Errorf("setting redaction rules: %w", err)
+		}
+	}
 	ctx := context.Background()
 
 	type flareDestinationTyp interface {
@@ -96,7 +111,14 @@ func runFlare(systemMultiSlogger *multislogger.MultiSlogger, args []string) erro
 		return fmt.Errorf(`invalid save option: %s, expected "local" or "upload"`, *flSave)
 	}
 
-	if err := checkups.RunFlare(ctx, k, flareDest, checkups.StandaloneEnviroment); err != nil {
+	flareOpts := checkups.FlareOptions{
+		IncludeCheckups: splitCheckupNames(*flInclude),
+		ExcludeCheckups: splitCheckupNames(*flExclude),
+		LogWindow:       *flLogWindow,
+		SizeBudgetKB:    *flSizeBudgetKB,
+	}
+
+	if err := checkups.RunFlare(ctx, k, flareDest, checkups.StandaloneEnviroment, flareOpts); err != nil {
 		return err
 	}
 
@@ -109,3 +131,18 @@ func runFlare(systemMultiSlogger *multislogger.MultiSlogger, args []string) erro
 
 	return nil
 }
+
+// splitCheckupNames parses a comma-separated list of checkup names.
+func splitCheckupNames(names string) []string {
+	if strings.TrimSpace(names) == "" {
+		return nil
+	}
+
+	split := make([]string, 0)
+	for _, name := range strings.Split(names, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			split = append(split, name)
+		}
+	}
+	return split
+}
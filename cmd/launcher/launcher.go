@@ -37,6 +37,7 @@ import (
 	"github.com/kolide/launcher/v2/ee/control/consumers/acceleratecontrolconsumer"
 	"github.com/kolide/launcher/v2/ee/control/consumers/dtainfoconsumer"
 	"github.com/kolide/launcher/v2/ee/control/consumers/flareconsumer"
+	"github.com/kolide/launcher/v2/ee/control/consumers/hardwarekeyrotationconsumer"
 	"github.com/kolide/launcher/v2/ee/control/consumers/keyvalueconsumer"
 	"github.com/kolide/launcher/v2/ee/control/consumers/localizationconsumer"
 	"github.com/kolide/launcher/v2/ee/control/consumers/notificationconsumer"
@@ -51,8 +52,10 @@ import (
 	"github.com/kolide/launcher/v2/ee/nativemessaging"
 	"github.com/kolide/launcher/v2/ee/observability"
 	"github.com/kolide/launcher/v2/ee/observability/exporter"
+	"github.com/kolide/launcher/v2/ee/observability/metricsendpoint"
 	"github.com/kolide/launcher/v2/ee/osquerypublisher"
 	"github.com/kolide/launcher/v2/ee/powereventwatcher"
+	"github.com/kolide/launcher/v2/ee/queryprofiler"
 	"github.com/kolide/launcher/v2/ee/tables/windowsupdatetable"
 	"github.com/kolide/launcher/v2/ee/tuf"
 	"github.com/kolide/launcher/v2/ee/watchdog"
@@ -243,12 +246,23 @@ func runLauncher(ctx context.Context, cancel func(), multiSlogger, systemMultiSl
 	// Apply GOMAXPROCS limit from control flag
 	gomaxprocsLimiter(ctx, slogger, k.LauncherGoMaxProcs())
 
+	// Apply the allowed command policy from control flags, and watch for changes to it
+	allowedcmdPolicyObs := newAllowedcmdPolicyObserver(slogger, k)
+	flagController.RegisterChangeObserver(allowedcmdPolicyObs, keys.AllowedCommandsAllowlist, keys.AllowedCommandsDenylist, keys.AllowedCommandsVerifyIntegrity)
+	allowedcmdPolicyObs.applyPolicy(ctx)
+
 	// Set up flag-driven dedup configuration on the main slogger
 	// (following the user's preference that early logs and system logs don't need deduplication)
 	multiSlogger.SetFlags(flagController)
-	flagController.RegisterChangeObserver(multiSlogger, keys.DuplicateLogWindow)
-	// Set initial dedup window from current flag value
+	flagController.RegisterChangeObserver(multiSlogger, keys.DuplicateLogWindow, keys.DuplicateLogWindowOverrides)
+	// Set initial dedup window and per-component overrides from current flag values
 	multiSlogger.UpdateDuplicateLogWindow(flagController.DuplicateLogWindow())
+	if err := multiSlogger.UpdateDuplicateLogWindowOverrides(flagController.DuplicateLogWindowOverrides()); err != nil {
+		slogger.Log(ctx, slog.LevelWarn,
+			"could not set duplicate log window overrides",
+			"err", err,
+		)
+	}
 
 	// set start time, first runtime, first version
 	initLauncherHistory(k)
@@ -316,6 +330,40 @@ func runLauncher(ctx context.Context, cancel func(), multiSlogger, systemMultiSl
 		startupSpan.AddEvent("log_shipper_init_completed")
 	}
 
+	// The telemetry exporter also manages export to a customer collector, and the meter provider that the local
+	// metrics endpoint collects from, so we need one for those even when we aren't exporting to a control server.
+	// The customer collector may be configured by the control server later, so we always create the exporter,
+	// and it observes changes to the customer collector flags.
+	if telemetryExporter == nil {
+		telemetryExporter, err = exporter.NewTelemetryExporter(ctx, k, initialTraceBuffer)
+		if err != nil {
+			slogger.Log(ctx, slog.LevelError,
+				"could not set up telemetry exporter",
+				"err", err,
+			)
+		} else {
+			runGroup.Add("telemetryExporter", telemetryExporter.Execute, telemetryExporter.Interrupt)
+		}
+	}
+
+	if telemetryExporter != nil {
+		// Ship logs to the customer collector, if configured
+		k.AddSlogHandler(telemetryExporter.CustomerLogHandler())
+	}
+
+	// Serve metrics locally, if configured
+	if k.MetricsEndpointAddress() != "" && telemetryExporter != nil {
+		metricsEndpoint, err := metricsendpoint.New(k, telemetryExporter)
+		if err != nil {
+			slogger.Log(ctx, slog.LevelError,
+				"could not set up metrics endpoint",
+				"err", err,
+			)
+		} else {
+			runGroup.Add("metricsEndpoint", metricsEndpoint.Execute, metricsEndpoint.Interrupt)
+		}
+	}
+
 	// Now that log shipping is set up, set the slogger on the rungroup so that rungroup logs
 	// will also be shipped.
 	runGroup.SetSlogger(k.Slogger())
@@ -437,6 +485,10 @@ func runLauncher(ctx context.Context, cancel func(), multiSlogger, systemMultiSl
 	runGroup.Add("osqueryRunner", osqueryRunner.Run, osqueryRunner.Interrupt)
 	k.SetInstanceQuerier(osqueryRunner)
 
+	// Periodically collect execution stats for scheduled queries and Kolide tables
+	queryProfiler := queryprofiler.New(k, k.QueryProfilesStore(), osqueryRunner)
+	runGroup.Add("queryProfiler", queryProfiler.Execute, queryProfiler.Interrupt)
+
 	launcherListener, err := listener.NewLauncherListener(k, slogger, listener.RootLauncherListenerSocketPrefix)
 	if err != nil {
 		return fmt.Errorf("initializing launcher listener: %w", err)
@@ -453,12 +505,13 @@ func runLauncher(ctx context.Context, cancel func(), multiSlogger, systemMultiSl
 	// Create the control service and services that depend on it
 	var runner *desktopRunner.DesktopUsersProcessesRunner
 	var actionsQueue *actionqueue.ActionQueue
+	var controlService *control.ControlService
 	if k.ControlServerURL() == "" {
 		slogger.Log(ctx, slog.LevelDebug,
 			"control server URL not set, will not create control service",
 		)
 	} else {
-		controlService, err := createControlService(ctx, k)
+		controlService, err = createControlService(ctx, k)
 		if err != nil {
 			return fmt.Errorf("failed to setup control service: %w", err)
 		}
@@ -493,6 +546,7 @@ func runLauncher(ctx context.Context, cancel func(), multiSlogger, systemMultiSl
 			controlService,
 			desktopRunner.WithAuthToken(ulid.New()),
 			desktopRunner.WithUsersFilesRoot(rootDirectory),
+			desktopRunner.WithQuerier(osqueryRunner),
 		)
 		if err != nil {
 			return fmt.Errorf("failed to create desktop runner: %w", err)
@@ -553,6 +607,11 @@ func runLauncher(ctx context.Context, cancel func(), multiSlogger, systemMultiSl
 		runGroup.Add("remoteRestart", remoteRestartConsumer.Execute, remoteRestartConsumer.Interrupt)
 		actionsQueue.RegisterActor(remoterestartconsumer.RemoteRestartActorType, remoteRestartConsumer)
 
+		// register hardware key rotation consumer, which also performs scheduled rotations
+		hardwareKeyRotationConsumer := hardwarekeyrotationconsumer.New(k)
+		runGroup.Add("hardwareKeyRotation", hardwareKeyRotationConsumer.Execute, hardwareKeyRotationConsumer.Interrupt)
+		actionsQueue.RegisterActor(hardwarekeyrotationconsumer.HardwareKeyRotationActorType, hardwareKeyRotationConsumer)
+
 		// Set up our tracing instrumentation
 		authTokenConsumer := keyvalueconsumer.New(k.TokenStore())
 		if err := controlService.RegisterConsumer(authTokensSubsystemName, authTokenConsumer); err != nil {
@@ -621,8 +680,22 @@ func runLauncher(ctx context.Context, cancel func(), multiSlogger, systemMultiSl
 		ls.SetQuerier(osqueryRunner)
 		runGroup.Add("localserver", ls.Start, ls.Interrupt)
 
-		// Support native messaging as an alternative to the localserver
-		if err := nativemessaging.WriteNativeMessagingManifest(rootDirectory, k.Identifier()); err != nil {
+		// Requests from the native messaging host reach the localserver via the desktop runner
+		if runner != nil {
+			runner.SetNativeMessageHandler(ls)
+		}
+
+		// Support native messaging as an alternative to the localserver. The native messaging host reaches
+		// root launcher using a connection file that the desktop runner writes to the user's desktop folder.
+		// We don't write that file on Windows yet, so there we make sure the host is not registered.
+		if runtime.GOOS == "windows" {
+			if err := nativemessaging.RemoveNativeMessagingManifest(rootDirectory, k.Identifier()); err != nil {
+				slogger.Log(ctx, slog.LevelError,
+					"could not remove native messaging manifest",
+					"err", err,
+				)
+			}
+		} else if err := nativemessaging.WriteNativeMessagingManifest(rootDirectory, k.Identifier()); err != nil {
 			slogger.Log(ctx, slog.LevelError,
 				"could not write native messaging manifest",
 				"err", err,
@@ -645,6 +718,7 @@ func runLauncher(ctx context.Context, cancel func(), multiSlogger, systemMultiSl
 			mirrorClient,
 			tuf.WithOsqueryRestart(osqueryRunner.Restart),
 			tuf.WithOsqueryHistory(k.OsqueryHistory()),
+			tuf.WithPinStore(k.AutoupdatePinsStore()),
 		)
 		if err != nil {
 			return fmt.Errorf("creating TUF autoupdater updater: %w", err)
@@ -655,6 +729,18 @@ func runLauncher(ctx context.Context, cancel func(), multiSlogger, systemMultiSl
 			actionsQueue.RegisterActor(tuf.AutoupdateSubsystemName, tufAutoupdater)
 		}
 
+		// Watch newly-updated launcher versions, rolling back if they are unhealthy
+		probationOpts := []tuf.UpdateProbationOption{tuf.WithOsqueryHealthChecker(osqueryRunner)}
+		if controlService != nil {
+			probationOpts = append(probationOpts, tuf.WithControlServerConnection(controlService))
+		}
+		updateProbation := tuf.NewUpdateProbation(k, k.LauncherHistoryStore(), probationOpts...)
+		runGroup.Add("updateProbation", updateProbation.Execute, updateProbation.Interrupt)
+
+		// Serve verified update archives to peers on the local network, if configured
+		lanCacheServer := tuf.NewLanCacheServer(k)
+		runGroup.Add("lanCacheServer", lanCacheServer.Execute, lanCacheServer.Interrupt)
+
 		// in some cases, (e.g. rolling back a windows installation to a previous osquery version) it is possible that
 		// the installer leaves us in a situation where there is no osqueryd on disk.
 		// we can detect this and attempt to download the correct version into the TUF update library to run from that.
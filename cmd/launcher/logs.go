@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kolide/launcher/v2/pkg/launcher"
+	"github.com/kolide/launcher/v2/pkg/log/logquery"
+	"github.com/kolide/launcher/v2/pkg/log/multislogger"
+	"github.com/peterbourgon/ff/v3"
+)
+
+// runLogs searches launcher's local logs. It only reads log files, so it works whether or not
+// launcher is running.
+func runLogs(_ *multislogger.MultiSlogger, args []string) error {
+	attachConsole()
+	defer detachConsole()
+
+	// Assume a launcher installation exists, so that we can find its root directory by default
+	launcher.DefaultAutoupdate = true
+	launcher.SetDefaultPaths()
+
+	var (
+		flagset = flag.NewFlagSet("launcher logs", flag.ExitOnError)
+		// Flags specific to this subcommand
+		flLevel        = flagset.String("level", "info", "Minimum log level to show: debug, info, warn, or error")
+		flComponents   = flagset.String("component", "", "Only show logs from these components (comma-separated)")
+		flSince        = flagset.String("since", "", "Only show logs after this time: an RFC3339 timestamp, or a duration ago (e.g. 2h)")
+		flUntil        = flagset.String("until", "", "Only show logs before this time: an RFC3339 timestamp, or a duration ago (e.g. 30m)")
+		flEnrollmentID = flagset.String("enrollment_id", "", "Only show logs for this enrollment")
+		flGrep         = flagset.String("grep", "", "Only show logs containing this text (case-insensitive)")
+		flSource       = flagset.String("source", string(logquery.SourceAll), "Only show logs from this source: all, launcher, or osquery")
+		flFollow       = flagset.Bool("follow", false, "Continue to show new logs as they are written")
+		flJSON         = flagset.Bool("json", false, "Output logs as JSON lines, as they were written")
+		// Flags shared by runLauncher/other subcommands, to be parsed by launcher.ParseOptions
+		flRootDirectory  = flagset.String("root_directory", "", "The location of the local database, pidfiles, etc.")
+		flConfigFilePath = flagset.String("config", "", "config file to parse options from (optional)")
+	)
+	flagset.Usage = commandUsage(flagset, "launcher logs")
+	if err := ff.Parse(flagset, args); err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+
+	// Only parse the full launcher options (from the config file) if we weren't told where the logs are,
+	// since parsing them requires a complete installation.
+	rootDirectory := *flRootDirectory
+	if rootDirectory == "" {
+		launcherOptions := make([]string, 0)
+		if *flConfigFilePath != "" {
+			launcherOptions = append(launcherOptions, "-config", *flConfigFilePath)
+		}
+
+		opts, err := launcher.ParseOptions("logs", launcherOptions)
+		if err != nil {
+			return fmt.Errorf("parsing launcher options: %w", err)
+		}
+		rootDirectory = opts.RootDirectory
+	}
+	if rootDirectory == "" {
+		return errors.New("no root directory specified")
+	}
+
+	filter, err := logsFilter(time.Now(), *flLevel, *flComponents, *flSince, *flUntil, *flEnrollmentID, *flGrep, *flSource)
+	if err != nil {
+		return err
+	}
+
+	printEntry := func(e logquery.Entry) error {
+		if *flJSON {
+			_, err := fmt.Fprintln(os.Stdout, string(e.Raw))
+			return err
+		}
+		_, err := fmt.Fprintln(os.Stdout, e.Text())
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	logPath := filepath.Join(rootDirectory, "debug.json")
+	if err := logquery.Query(ctx, logPath, filter, printEntry); err != nil && !errors.Is(err, context.Canceled) {
+		return fmt.Errorf("querying logs in %s: %w", rootDirectory, err)
+	}
+
+	if !*flFollow {
+		return nil
+	}
+
+	return logquery.Follow(ctx, logPath, filter, printEntry)
+}
+
+// logsFilter builds the log filter from the `launcher logs` flags.
+func logsFilter(now time.Time, level, components, since, until, enrollmentID, text, source string) (logquery.Filter, error) {
+	filter := logquery.Filter{
+		EnrollmentID: enrollmentID,
+		Text:         text,
+		Source:       logquery.Source(source),
+	}
+
+	if err := filter.MinLevel.UnmarshalText([]byte(level)); err != nil {
+		return filter, fmt.Errorf("invalid level %q: %w", level, err)
+	}
+
+	for _, c := range strings.Split(components, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			filter.Components = append(filter.Components, c)
+		}
+	}
+
+	var err error
+	if filter.Since, err = parseLogsTime(now, since); err != nil {
+		return filter, fmt.Errorf("invalid since: %w", err)
+	}
+	if filter.Until, err = parseLogsTime(now, until); err != nil {
+		return filter, fmt.Errorf("invalid until: %w", err)
+	}
+
+	switch filter.Source {
+	case logquery.SourceAll, logquery.SourceLauncher, logquery.SourceOsquery:
+	default:
+		return filter, fmt.Errorf("invalid source %q, must be one of all, launcher, or osquery", source)
+	}
+
+	return filter, nil
+}
+
+// parseLogsTime parses either an RFC3339 timestamp, or a duration before now.
+func parseLogsTime(now time.Time, val string) (time.Time, error) {
+	if val == "" {
+		return time.Time{}, nil
+	}
+
+	if d, err := time.ParseDuration(val); err == nil {
+		return now.Add(-d), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, val)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither an RFC3339 timestamp nor a duration", val)
+	}
+
+	return t, nil
+}
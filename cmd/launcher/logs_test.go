@@ -0,0 +1,41 @@
+package main
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/kolide/launcher/v2/pkg/log/logquery"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_logsFilter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	filter, err := logsFilter(now, "warn", "osquery, tuf_autoupdater,", "2h", "2024-05-01T11:30:00Z", "default", "checkin", "osquery")
+	require.NoError(t, err)
+	require.Equal(t, logquery.Filter{
+		MinLevel:     slog.LevelWarn,
+		Components:   []string{"osquery", "tuf_autoupdater"},
+		Since:        time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		Until:        time.Date(2024, 5, 1, 11, 30, 0, 0, time.UTC),
+		EnrollmentID: "default",
+		Text:         "checkin",
+		Source:       logquery.SourceOsquery,
+	}, filter)
+
+	filter, err = logsFilter(now, "debug", "", "", "", "", "", "all")
+	require.NoError(t, err)
+	require.Equal(t, logquery.Filter{MinLevel: slog.LevelDebug, Source: logquery.SourceAll}, filter)
+
+	_, err = logsFilter(now, "verbose", "", "", "", "", "", "all")
+	require.Error(t, err)
+
+	_, err = logsFilter(now, "info", "", "yesterday", "", "", "", "all")
+	require.Error(t, err)
+
+	_, err = logsFilter(now, "info", "", "", "", "", "", "extension")
+	require.Error(t, err)
+}
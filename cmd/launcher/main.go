@@ -138,6 +138,10 @@ func runMain() int {
 	// handle that argument.
 	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], `-`) {
 		if err := runSubcommands(systemSlogger); err != nil {
+			var exitErr *exitCodeError
+			if errors.As(err, &exitErr) {
+				return exitErr.code
+			}
 			systemSlogger.Log(ctx, slog.LevelError,
 				"running with positional args",
 				"err", err,
@@ -213,6 +217,17 @@ func runMain() int {
 			if err := runNewerLauncherIfAvailable(ctx, systemSlogger.Logger); err != nil {
 				return 1
 			}
+
+			// We couldn't find another version to run. When rolling back, the current executable is the
+			// version we just marked bad, so we must not exec it again -- exit with an error instead, so
+			// that the service manager restarts launcher and library lookup selects a good version.
+			if tuf.IsLauncherRollbackNeededErr(err) {
+				slogger.Log(ctx, slog.LevelError,
+					"could not find version to roll back to, exiting instead of restarting bad version",
+					"err", err,
+				)
+				return 1
+			}
 		}
 
 		// A restart was requested -- run this version of launcher again.
@@ -239,6 +254,21 @@ func runMain() int {
 	return 0
 }
 
+// exitCodeError is returned by subcommands that need to exit with a specific exit code,
+// e.g. to report a result rather than a failure to run.
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitCodeError) Unwrap() error {
+	return e.err
+}
+
 func runSubcommands(systemMultiSlogger *multislogger.MultiSlogger) error {
 	var run func(*multislogger.MultiSlogger, []string) error
 	switch os.Args[1] {
@@ -274,6 +304,8 @@ func runSubcommands(systemMultiSlogger *multislogger.MultiSlogger) error {
 		run = runEnroll
 	case "specs":
 		run = runSpecs
+	case "logs":
+		run = runLogs
 	default:
 		return fmt.Errorf("unknown subcommand %s", os.Args[1])
 	}
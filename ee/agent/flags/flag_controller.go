@@ -572,8 +572,18 @@ func (fc *FlagController) AutoupdateInterval() time.Duration {
 func (fc *FlagController) SetUpdateChannel(channel string) error {
 	return fc.setControlServerValue(keys.UpdateChannel, []byte(channel))
 }
+func (fc *FlagController) SetUpdateChannelOverride(channel string, duration time.Duration) {
+	ctx, span := observability.StartSpan(context.TODO())
+	defer span.End()
+
+	fc.overrideFlag(ctx, keys.UpdateChannel, duration, channel)
+}
 func (fc *FlagController) UpdateChannel() string {
+	fc.overrideMutex.RLock()
+	defer fc.overrideMutex.RUnlock()
+
 	return NewStringFlagValue(
+		WithOverrideString(fc.overrides[keys.UpdateChannel]),
 		WithSanitizer(launcher.SanitizeUpdateChannel),
 		WithDefaultString(string(fc.cmdLineOpts.UpdateChannel)),
 	).get(fc.getControlServerValue(keys.UpdateChannel))
@@ -612,6 +622,12 @@ func (fc *FlagController) UpdateDirectory() string {
 func (fc *FlagController) SetPinnedLauncherVersion(version string) error {
 	return fc.setControlServerValue(keys.PinnedLauncherVersion, []byte(version))
 }
+func (fc *FlagController) SetPinnedLauncherVersionOverride(version string, duration time.Duration) {
+	ctx, span := observability.StartSpan(context.TODO())
+	defer span.End()
+
+	fc.overrideFlag(ctx, keys.PinnedLauncherVersion, duration, version)
+}
 func (fc *FlagController) PinnedLauncherVersion() string {
 	fc.overrideMutex.RLock()
 	defer fc.overrideMutex.RUnlock()
@@ -628,6 +644,12 @@ func (fc *FlagController) PinnedLauncherVersion() string {
 func (fc *FlagController) SetPinnedOsquerydVersion(version string) error {
 	return fc.setControlServerValue(keys.PinnedOsquerydVersion, []byte(version))
 }
+func (fc *FlagController) SetPinnedOsquerydVersionOverride(version string, duration time.Duration) {
+	ctx, span := observability.StartSpan(context.TODO())
+	defer span.End()
+
+	fc.overrideFlag(ctx, keys.PinnedOsquerydVersion, duration, version)
+}
 func (fc *FlagController) PinnedOsquerydVersion() string {
 	fc.overrideMutex.RLock()
 	defer fc.overrideMutex.RUnlock()
@@ -641,6 +663,24 @@ func (fc *FlagController) PinnedOsquerydVersion() string {
 	).get(fc.getControlServerValue(keys.PinnedOsquerydVersion))
 }
 
+func (fc *FlagController) SetLanCacheListenAddress(address string) error {
+	return fc.setControlServerValue(keys.LanCacheListenAddress, []byte(address))
+}
+func (fc *FlagController) LanCacheListenAddress() string {
+	return NewStringFlagValue(
+		WithDefaultString(""),
+	).get(fc.getControlServerValue(keys.LanCacheListenAddress))
+}
+
+func (fc *FlagController) SetLanCachePeers(peers string) error {
+	return fc.setControlServerValue(keys.LanCachePeers, []byte(peers))
+}
+func (fc *FlagController) LanCachePeers() string {
+	return NewStringFlagValue(
+		WithDefaultString(""),
+	).get(fc.getControlServerValue(keys.LanCachePeers))
+}
+
 func (fc *FlagController) SetExportTraces(enabled bool) error {
 	return fc.setControlServerValue(keys.ExportTraces, boolToBytes(enabled))
 }
@@ -764,6 +804,68 @@ func (fc *FlagController) DisableTraceIngestTLS() bool {
 	).get(fc.getControlServerValue(keys.DisableTraceIngestTLS))
 }
 
+func (fc *FlagController) MetricsEndpointAddress() string {
+	return NewStringFlagValue(
+		WithDefaultString(fc.cmdLineOpts.MetricsEndpointAddress),
+	).get(nil)
+}
+
+func (fc *FlagController) SetCustomerOTLPEndpoint(endpoint string) error {
+	return fc.setControlServerValue(keys.CustomerOTLPEndpoint, []byte(endpoint))
+}
+func (fc *FlagController) CustomerOTLPEndpoint() string {
+	return NewStringFlagValue(
+		WithDefaultString(fc.cmdLineOpts.CustomerOTLPEndpoint),
+	).get(fc.getControlServerValue(keys.CustomerOTLPEndpoint))
+}
+
+func (fc *FlagController) SetCustomerOTLPHeaders(headers string) error {
+	return fc.setControlServerValue(keys.CustomerOTLPHeaders, []byte(headers))
+}
+func (fc *FlagController) CustomerOTLPHeaders() string {
+	return NewStringFlagValue(
+		WithDefaultString(fc.cmdLineOpts.CustomerOTLPHeaders),
+	).get(fc.getControlServerValue(keys.CustomerOTLPHeaders))
+}
+
+func (fc *FlagController) SetCustomerOTLPCAPath(path string) error {
+	return fc.setControlServerValue(keys.CustomerOTLPCAPath, []byte(path))
+}
+func (fc *FlagController) CustomerOTLPCAPath() string {
+	return NewStringFlagValue(
+		WithDefaultString(fc.cmdLineOpts.CustomerOTLPCAPath),
+	).get(fc.getControlServerValue(keys.CustomerOTLPCAPath))
+}
+
+func (fc *FlagController) SetDisableCustomerOTLPTLS(disabled bool) error {
+	return fc.setControlServerValue(keys.DisableCustomerOTLPTLS, boolToBytes(disabled))
+}
+func (fc *FlagController) DisableCustomerOTLPTLS() bool {
+	return NewBoolFlagValue(
+		WithDefaultBool(fc.cmdLineOpts.DisableCustomerOTLPTLS),
+	).get(fc.getControlServerValue(keys.DisableCustomerOTLPTLS))
+}
+
+func (fc *FlagController) SetCustomerOTLPSamplingRate(rate float64) error {
+	return fc.setControlServerValue(keys.CustomerOTLPSamplingRate, float64ToBytes(rate))
+}
+func (fc *FlagController) CustomerOTLPSamplingRate() float64 {
+	return NewFloat64FlagValue(fc.slogger, keys.CustomerOTLPSamplingRate,
+		WithFloat64ValueDefault(fc.cmdLineOpts.CustomerOTLPSamplingRate),
+		WithFloat64ValueMin(0.0),
+		WithFloat64ValueMax(1.0),
+	).get(fc.getControlServerValue(keys.CustomerOTLPSamplingRate))
+}
+
+func (fc *FlagController) SetCustomerOTLPOnly(only bool) error {
+	return fc.setControlServerValue(keys.CustomerOTLPOnly, boolToBytes(only))
+}
+func (fc *FlagController) CustomerOTLPOnly() bool {
+	return NewBoolFlagValue(
+		WithDefaultBool(fc.cmdLineOpts.CustomerOTLPOnly),
+	).get(fc.getControlServerValue(keys.CustomerOTLPOnly))
+}
+
 func (fc *FlagController) SetInModernStandby(enabled bool) error {
 	return fc.setControlServerValue(keys.InModernStandby, boolToBytes(enabled))
 }
@@ -854,6 +956,18 @@ func (fc *FlagController) SetAutoupdateDownloadSplay(val time.Duration) error {
 	return fc.setControlServerValue(keys.AutoupdateDownloadSplay, durationToBytes(val))
 }
 
+func (fc *FlagController) AutoupdateBandwidthLimitKB() int {
+	return NewIntFlagValue(fc.slogger, keys.AutoupdateBandwidthLimitKB,
+		WithIntValueDefault(fc.cmdLineOpts.AutoupdateBandwidthLimitKB),
+		WithIntValueMin(0),
+		WithIntValueMax(1000000), // 1 GB/s
+	).get(fc.getControlServerValue(keys.AutoupdateBandwidthLimitKB))
+}
+
+func (fc *FlagController) SetAutoupdateBandwidthLimitKB(limit int) error {
+	return fc.setControlServerValue(keys.AutoupdateBandwidthLimitKB, intToBytes(limit))
+}
+
 func (fc *FlagController) SetPerformanceMonitoringEnabled(enabled bool) error {
 	return fc.setControlServerValue(keys.PerformanceMonitoringEnabled, boolToBytes(enabled))
 }
@@ -876,6 +990,78 @@ func (fc *FlagController) DuplicateLogWindow() time.Duration {
 	).get(fc.getControlServerValue(keys.DuplicateLogWindow))
 }
 
+func (fc *FlagController) SetDuplicateLogWindowOverrides(overrides string) error {
+	return fc.setControlServerValue(keys.DuplicateLogWindowOverrides, []byte(overrides))
+}
+
+func (fc *FlagController) DuplicateLogWindowOverrides() string {
+	return NewStringFlagValue(
+		WithDefaultString(""),
+	).get(fc.getControlServerValue(keys.DuplicateLogWindowOverrides))
+}
+
+func (fc *FlagController) SetAllowedCommandsAllowlist(allowlist string) error {
+	return fc.setControlServerValue(keys.AllowedCommandsAllowlist, []byte(allowlist))
+}
+
+func (fc *FlagController) AllowedCommandsAllowlist() string {
+	return NewStringFlagValue(
+		WithDefaultString(""),
+	).get(fc.getControlServerValue(keys.AllowedCommandsAllowlist))
+}
+
+func (fc *FlagController) SetAllowedCommandsDenylist(denylist string) error {
+	return fc.setControlServerValue(keys.AllowedCommandsDenylist, []byte(denylist))
+}
+
+func (fc *FlagController) AllowedCommandsDenylist() string {
+	return NewStringFlagValue(
+		WithDefaultString(""),
+	).get(fc.getControlServerValue(keys.AllowedCommandsDenylist))
+}
+
+func (fc *FlagController) SetAllowedCommandsVerifyIntegrity(enabled bool) error {
+	return fc.setControlServerValue(keys.AllowedCommandsVerifyIntegrity, boolToBytes(enabled))
+}
+
+func (fc *FlagController) AllowedCommandsVerifyIntegrity() bool {
+	return NewBoolFlagValue(
+		WithDefaultBool(false),
+	).get(fc.getControlServerValue(keys.AllowedCommandsVerifyIntegrity))
+}
+
+func (fc *FlagController) SetHardwareKeyRotationInterval(interval time.Duration) error {
+	return fc.setControlServerValue(keys.HardwareKeyRotationInterval, durationToBytes(interval))
+}
+
+func (fc *FlagController) HardwareKeyRotationInterval() time.Duration {
+	return NewDurationFlagValue(fc.slogger, keys.HardwareKeyRotationInterval,
+		WithDefault(0),
+		WithMin(0),
+		WithMax(2*365*24*time.Hour),
+	).get(fc.getControlServerValue(keys.HardwareKeyRotationInterval))
+}
+
+func (fc *FlagController) SetFlareRedactionRules(rules string) error {
+	return fc.setControlServerValue(keys.FlareRedactionRules, []byte(rules))
+}
+
+func (fc *FlagController) FlareRedactionRules() string {
+	return NewStringFlagValue(
+		WithDefaultString(""),
+	).get(fc.getControlServerValue(keys.FlareRedactionRules))
+}
+
+func (fc *FlagController) SetExternalCheckups(definitions string) error {
+	return fc.setControlServerValue(keys.ExternalCheckups, []byte(definitions))
+}
+
+func (fc *FlagController) ExternalCheckups() string {
+	return NewStringFlagValue(
+		WithDefaultString(""),
+	).get(fc.getControlServerValue(keys.ExternalCheckups))
+}
+
 // OsqueryLogPublish helpers
 func (fc *FlagController) OsqueryPublisherURL() string {
 	return NewStringFlagValue(
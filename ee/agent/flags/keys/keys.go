@@ -47,9 +47,12 @@ const (
 	UpdateChannel                    FlagKey = "update_channel"
 	AutoupdateInitialDelay           FlagKey = "autoupdater_initial_delay"
 	AutoupdateDownloadSplay          FlagKey = "autoupdate_download_splay"
+	AutoupdateBandwidthLimitKB       FlagKey = "autoupdate_bandwidth_limit_kb"
 	UpdateDirectory                  FlagKey = "update_directory"
 	PinnedLauncherVersion            FlagKey = "pinned_launcher_version"
 	PinnedOsquerydVersion            FlagKey = "pinned_osqueryd_version"
+	LanCacheListenAddress            FlagKey = "lan_cache_listen_address"
+	LanCachePeers                    FlagKey = "lan_cache_peers"
 	ExportTraces                     FlagKey = "export_traces"
 	TraceSamplingRate                FlagKey = "trace_sampling_rate"
 	TraceBatchTimeout                FlagKey = "trace_batch_timeout"
@@ -57,6 +60,13 @@ const (
 	LogShippingLevel                 FlagKey = "log_shipping_level"
 	TraceIngestServerURL             FlagKey = "trace_ingest_url"
 	DisableTraceIngestTLS            FlagKey = "disable_trace_ingest_tls"
+	MetricsEndpointAddress           FlagKey = "metrics_endpoint_address"
+	CustomerOTLPEndpoint             FlagKey = "customer_otlp_endpoint"
+	CustomerOTLPHeaders              FlagKey = "customer_otlp_headers"
+	CustomerOTLPCAPath               FlagKey = "customer_otlp_ca_path"
+	DisableCustomerOTLPTLS           FlagKey = "disable_customer_otlp_tls"
+	CustomerOTLPSamplingRate         FlagKey = "customer_otlp_sampling_rate"
+	CustomerOTLPOnly                 FlagKey = "customer_otlp_only"
 	InModernStandby                  FlagKey = "in_modern_standby"
 	LocalDevelopmentPath             FlagKey = "localdev_path"
 	LauncherWatchdogDisabled         FlagKey = "prevent_launcher_watchdog_installation" // note that this will only impact windows deployments for now
@@ -68,6 +78,13 @@ const (
 	ResetOnHardwareChangeEnabled     FlagKey = "reset_on_hardware_change_enabled"
 	PerformanceMonitoringEnabled     FlagKey = "performance_monitoring_enabled"
 	DuplicateLogWindow               FlagKey = "duplicate_log_window"
+	DuplicateLogWindowOverrides      FlagKey = "duplicate_log_window_overrides"
+	AllowedCommandsAllowlist         FlagKey = "allowed_commands_allowlist"
+	AllowedCommandsDenylist          FlagKey = "allowed_commands_denylist"
+	AllowedCommandsVerifyIntegrity   FlagKey = "allowed_commands_verify_integrity"
+	HardwareKeyRotationInterval      FlagKey = "hardware_key_rotation_interval"
+	FlareRedactionRules              FlagKey = "flare_redaction_rules"
+	ExternalCheckups                 FlagKey = "external_checkups"
 	// Osquery log publication cutover flags
 	OsqueryPublisherURL            FlagKey = "osquery_publisher_url"
 	OsqueryPublisherPercentEnabled FlagKey = "osquery_publisher_percent_enabled"
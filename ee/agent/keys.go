@@ -24,6 +24,13 @@ func HardwareKeys() keyInt {
 	return hardwareKeys
 }
 
+// HardwareKeyRotator returns the hardware keys as a keys.Rotator, if they support rotation.
+// As with HardwareKeys, do not cache this value.
+func HardwareKeyRotator() (keys.Rotator, bool) {
+	rotator, ok := hardwareKeys.(keys.Rotator)
+	return rotator, ok
+}
+
 func LocalDbKeys() keyInt {
 	return localDbKeys
 }
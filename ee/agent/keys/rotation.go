@@ -0,0 +1,38 @@
+package keys
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"time"
+)
+
+var (
+	ErrRotationPending   = errors.New("a key rotation is already awaiting acknowledgement")
+	ErrNoPendingRotation = errors.New("no key rotation is awaiting acknowledgement")
+	ErrRotationMismatch  = errors.New("acknowledged key does not match pending key")
+)
+
+// Rotator is implemented by hardware keys that support rotation.
+type Rotator interface {
+	// RotateKey creates a new key to replace the current one, pending server acknowledgement.
+	RotateKey(ctx context.Context) error
+	// AcknowledgeKeyRotation promotes the pending key, given its PKIX DER encoding, to be the current key.
+	AcknowledgeKeyRotation(ctx context.Context, publicKeyDer []byte) error
+	// PendingKeyRotation returns the pending rotation, or nil if there is none.
+	PendingKeyRotation() *PendingRotation
+	// KeyCreatedAt returns when the current key was created, or the zero time if there is no key yet.
+	KeyCreatedAt() time.Time
+}
+
+// PendingRotation describes a new hardware key that has been created to replace the current
+// one, but that the server has not yet acknowledged. Until it is acknowledged, launcher keeps
+// using the current key, and reports both.
+type PendingRotation struct {
+	// Signer is the new key.
+	Signer crypto.Signer
+	// Signature is the current key's signature over the new key's PKIX DER encoding, which lets
+	// the server trust the new key based on the old one. It is nil if the current key cannot sign.
+	Signature []byte
+	RotatedAt time.Time
+}
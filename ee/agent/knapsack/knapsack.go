@@ -434,6 +434,14 @@ func (k *knapsack) LocalizationStore() types.KVStore {
 	return k.getKVStore(storage.LocalizationStore)
 }
 
+func (k *knapsack) QueryProfilesStore() types.KVStore {
+	return k.getKVStore(storage.QueryProfilesStore)
+}
+
+func (k *knapsack) AutoupdatePinsStore() types.KVStore {
+	return k.getKVStore(storage.AutoupdatePinsStore)
+}
+
 func (k *knapsack) SetLauncherWatchdogDisabled(disabled bool) error {
 	return k.flags.SetLauncherWatchdogDisabled(disabled)
 }
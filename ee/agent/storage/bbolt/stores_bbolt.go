@@ -41,6 +41,8 @@ func MakeStores(ctx context.Context, slogger *slog.Logger, db *bbolt.DB) (map[st
 		storage.EnrollmentDetailsStore,
 		storage.ServerReleaseTrackerDataStore,
 		storage.LocalizationStore,
+		storage.QueryProfilesStore,
+		storage.AutoupdatePinsStore,
 	}
 
 	for _, storeName := range storeNames {
@@ -38,6 +38,8 @@ func MakeStores(t *testing.T, slogger *slog.Logger, db *bbolt.DB) (map[storage.S
 		storage.EnrollmentDetailsStore,
 		storage.ServerReleaseTrackerDataStore,
 		storage.LocalizationStore,
+		storage.QueryProfilesStore,
+		storage.AutoupdatePinsStore,
 	}
 
 	if os.Getenv("CI") == "true" {
@@ -25,6 +25,7 @@ type storeName int
 const (
 	StartupSettingsStore storeName = iota
 	WatchdogLogStore     storeName = 1
+	LogSpoolStore        storeName = 2
 
 	busyTimeoutMs int = 10000 // 10 seconds
 )
@@ -39,6 +40,8 @@ func (s storeName) String() string {
 		return "startup_settings"
 	case WatchdogLogStore:
 		return "watchdog_logs"
+	case LogSpoolStore:
+		return "log_spool"
 	}
 
 	return ""
@@ -391,6 +394,8 @@ func (s *sqliteStore) getColumns() *sqliteColumns {
 		return &sqliteColumns{pk: "name", valueColumn: "value", isLogstore: false}
 	case WatchdogLogStore.String():
 		return &sqliteColumns{pk: "timestamp", valueColumn: "log", isLogstore: true}
+	case LogSpoolStore.String():
+		return &sqliteColumns{pk: "timestamp", valueColumn: "log", isLogstore: true}
 	}
 
 	return nil
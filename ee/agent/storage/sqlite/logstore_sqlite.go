@@ -73,7 +73,7 @@ func (s *sqliteStore) ForEach(fn func(rowid, timestamp int64, v []byte) error) e
 	}
 
 	query := fmt.Sprintf(
-		`SELECT rowid, %s, %s FROM %s;`,
+		`SELECT rowid, %s, %s FROM %s ORDER BY rowid;`,
 		colInfo.pk,
 		colInfo.valueColumn,
 		s.tableName,
@@ -26,6 +26,8 @@ const (
 	EnrollmentDetailsStore        Store = "enrollment_details"                 // The store used for persisting enrollment details
 	ServerReleaseTrackerDataStore Store = "kolide_server_release_tracker_data" // The store used for release tracking data sent by control server.
 	LocalizationStore             Store = "localization"                       // The store used for localization data sent by control server.
+	QueryProfilesStore            Store = "query_profiles"                     // The store used for per-query execution profiling data.
+	AutoupdatePinsStore           Store = "autoupdate_pins"                    // The store used for temporary version pins sent by control server via the autoupdate action.
 )
 
 func (storeType Store) String() string {
@@ -181,6 +181,7 @@ type Flags interface {
 
 	// UpdateChannel is the channel to pull options from (stable, beta, nightly).
 	SetUpdateChannel(channel string) error
+	SetUpdateChannelOverride(channel string, duration time.Duration)
 	UpdateChannel() string
 
 	// AutoupdateInitialDelay set an initial startup delay on the autoupdater process.
@@ -194,12 +195,23 @@ type Flags interface {
 
 	// PinnedLauncherVersion is the launcher version to lock the autoupdater to, rather than autoupdating via the update channel.
 	SetPinnedLauncherVersion(version string) error
+	SetPinnedLauncherVersionOverride(version string, duration time.Duration)
 	PinnedLauncherVersion() string
 
 	// PinnedOsquerydVersion is the osqueryd version to lock the autoupdater to, rather than autoupdating via the update channel.
 	SetPinnedOsquerydVersion(version string) error
+	SetPinnedOsquerydVersionOverride(version string, duration time.Duration)
 	PinnedOsquerydVersion() string
 
+	// LanCacheListenAddress is the address on which launcher serves verified update archives to peers on the local network.
+	// Empty disables serving.
+	SetLanCacheListenAddress(address string) error
+	LanCacheListenAddress() string
+
+	// LanCachePeers is a comma-separated list of peer addresses (host:port) to try downloading updates from before the mirror.
+	SetLanCachePeers(peers string) error
+	LanCachePeers() string
+
 	// ExportTraces enables exporting our traces
 	SetExportTraces(enabled bool) error
 	SetExportTracesOverride(value bool, duration time.Duration)
@@ -231,6 +243,34 @@ type Flags interface {
 	SetTraceBatchTimeout(duration time.Duration) error
 	TraceBatchTimeout() time.Duration
 
+	// MetricsEndpointAddress is the localhost address to serve launcher metrics on in Prometheus text format; empty if disabled
+	MetricsEndpointAddress() string
+
+	// CustomerOTLPEndpoint is the host:port of a customer-operated OpenTelemetry collector to export
+	// traces, metrics, and logs to via OTLP over gRPC; empty if disabled
+	SetCustomerOTLPEndpoint(endpoint string) error
+	CustomerOTLPEndpoint() string
+
+	// CustomerOTLPHeaders are additional headers to send to the customer collector, as comma-separated key=value pairs
+	SetCustomerOTLPHeaders(headers string) error
+	CustomerOTLPHeaders() string
+
+	// CustomerOTLPCAPath is the path to a PEM file of CA certificates to verify the customer collector against
+	SetCustomerOTLPCAPath(path string) error
+	CustomerOTLPCAPath() string
+
+	// DisableCustomerOTLPTLS disables TLS when connecting to the customer collector
+	SetDisableCustomerOTLPTLS(disabled bool) error
+	DisableCustomerOTLPTLS() bool
+
+	// CustomerOTLPSamplingRate is a number between 0.0 and 1.0 that indicates what fraction of traces should be sent to the customer collector
+	SetCustomerOTLPSamplingRate(rate float64) error
+	CustomerOTLPSamplingRate() float64
+
+	// CustomerOTLPOnly indicates that traces and metrics should be exported only to the customer collector, and not to Kolide
+	SetCustomerOTLPOnly(only bool) error
+	CustomerOTLPOnly() bool
+
 	// InModernStandby indicates whether a Windows machine is awake or in modern standby
 	SetInModernStandby(enabled bool) error
 	InModernStandby() bool
@@ -274,6 +314,10 @@ type Flags interface {
 	AutoupdateDownloadSplay() time.Duration
 	SetAutoupdateDownloadSplay(val time.Duration) error
 
+	// AutoupdateBandwidthLimitKB is the maximum rate, in KB per second, at which launcher will download updates. 0 is unlimited.
+	AutoupdateBandwidthLimitKB() int
+	SetAutoupdateBandwidthLimitKB(limit int) error
+
 	// PerformanceMonitoringEnabled controls whether launcher self-monitors for performance issues
 	SetPerformanceMonitoringEnabled(enabled bool) error
 	PerformanceMonitoringEnabled() bool
@@ -282,6 +326,37 @@ type Flags interface {
 	SetDuplicateLogWindow(duration time.Duration) error
 	DuplicateLogWindow() time.Duration
 
+	// DuplicateLogWindowOverrides overrides the deduplication window for specific log components,
+	// as comma-separated component=duration pairs (e.g. "osquery_instance=5m,tablehelpers=0s")
+	SetDuplicateLogWindowOverrides(overrides string) error
+	DuplicateLogWindowOverrides() string
+
+	// AllowedCommandsAllowlist is a comma-separated list of command names; when set, launcher will
+	// only execute the listed commands (launcher itself is always allowed)
+	SetAllowedCommandsAllowlist(allowlist string) error
+	AllowedCommandsAllowlist() string
+
+	// AllowedCommandsDenylist is a comma-separated list of command names that launcher will not execute
+	SetAllowedCommandsDenylist(denylist string) error
+	AllowedCommandsDenylist() string
+
+	// AllowedCommandsVerifyIntegrity enables ownership, permission, and package provenance checks
+	// on command binaries before launcher executes them
+	SetAllowedCommandsVerifyIntegrity(enabled bool) error
+	AllowedCommandsVerifyIntegrity() bool
+
+	// HardwareKeyRotationInterval is how long a hardware key is used before launcher rotates it; 0 disables scheduled rotation
+	SetHardwareKeyRotationInterval(interval time.Duration) error
+	HardwareKeyRotationInterval() time.Duration
+
+	// FlareRedactionRules is a JSON document with additional rules for redacting flare contents
+	SetFlareRedactionRules(rules string) error
+	FlareRedactionRules() string
+
+	// ExternalCheckups is a JSON array of additional checkup definitions to run in doctor, flare, and the log checkpointer
+	SetExternalCheckups(definitions string) error
+	ExternalCheckups() string
+
 	// Osquery log ingest cutover helpers
 	OsqueryPublisherURL() string
 	SetOsqueryPublisherURL(url string) error
@@ -13,7 +13,7 @@ type RowDeleter interface {
 //mockery:generate: true
 //mockery:filename: logstore.go
 type TimestampedIterator interface {
-	// ForEach executes a function for each timestamp/value pair in a store.
+	// ForEach executes a function for each timestamp/value pair in a store, in insertion order.
 	// If the provided function returns an error then the iteration is stopped and
 	// the error is returned to the caller. The provided function must not modify
 	// the store; this will result in undefined behavior.
@@ -83,6 +83,138 @@ func (_c *Flags_AllowOverlyBroadDt4aAcceleration_Call) RunAndReturn(run func() b
 	return _c
 }
 
+// AllowedCommandsAllowlist provides a mock function for the type Flags
+func (_mock *Flags) AllowedCommandsAllowlist() string {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for AllowedCommandsAllowlist")
+	}
+
+	var r0 string
+	if returnFunc, ok := ret.Get(0).(func() string); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	return r0
+}
+
+// Flags_AllowedCommandsAllowlist_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AllowedCommandsAllowlist'
+type Flags_AllowedCommandsAllowlist_Call struct {
+	*mock.Call
+}
+
+// AllowedCommandsAllowlist is a helper method to define mock.On call
+func (_e *Flags_Expecter) AllowedCommandsAllowlist() *Flags_AllowedCommandsAllowlist_Call {
+	return &Flags_AllowedCommandsAllowlist_Call{Call: _e.mock.On("AllowedCommandsAllowlist")}
+}
+
+func (_c *Flags_AllowedCommandsAllowlist_Call) Run(run func()) *Flags_AllowedCommandsAllowlist_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Flags_AllowedCommandsAllowlist_Call) Return(r0 string) *Flags_AllowedCommandsAllowlist_Call {
+	_c.Call.Return(r0)
+	return _c
+}
+
+func (_c *Flags_AllowedCommandsAllowlist_Call) RunAndReturn(run func() string) *Flags_AllowedCommandsAllowlist_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// AllowedCommandsDenylist provides a mock function for the type Flags
+func (_mock *Flags) AllowedCommandsDenylist() string {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for AllowedCommandsDenylist")
+	}
+
+	var r0 string
+	if returnFunc, ok := ret.Get(0).(func() string); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	return r0
+}
+
+// Flags_AllowedCommandsDenylist_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AllowedCommandsDenylist'
+type Flags_AllowedCommandsDenylist_Call struct {
+	*mock.Call
+}
+
+// AllowedCommandsDenylist is a helper method to define mock.On call
+func (_e *Flags_Expecter) AllowedCommandsDenylist() *Flags_AllowedCommandsDenylist_Call {
+	return &Flags_AllowedCommandsDenylist_Call{Call: _e.mock.On("AllowedCommandsDenylist")}
+}
+
+func (_c *Flags_AllowedCommandsDenylist_Call) Run(run func()) *Flags_AllowedCommandsDenylist_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Flags_AllowedCommandsDenylist_Call) Return(r0 string) *Flags_AllowedCommandsDenylist_Call {
+	_c.Call.Return(r0)
+	return _c
+}
+
+func (_c *Flags_AllowedCommandsDenylist_Call) RunAndReturn(run func() string) *Flags_AllowedCommandsDenylist_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// AllowedCommandsVerifyIntegrity provides a mock function for the type Flags
+func (_mock *Flags) AllowedCommandsVerifyIntegrity() bool {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for AllowedCommandsVerifyIntegrity")
+	}
+
+	var r0 bool
+	if returnFunc, ok := ret.Get(0).(func() bool); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	return r0
+}
+
+// Flags_AllowedCommandsVerifyIntegrity_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AllowedCommandsVerifyIntegrity'
+type Flags_AllowedCommandsVerifyIntegrity_Call struct {
+	*mock.Call
+}
+
+// AllowedCommandsVerifyIntegrity is a helper method to define mock.On call
+func (_e *Flags_Expecter) AllowedCommandsVerifyIntegrity() *Flags_AllowedCommandsVerifyIntegrity_Call {
+	return &Flags_AllowedCommandsVerifyIntegrity_Call{Call: _e.mock.On("AllowedCommandsVerifyIntegrity")}
+}
+
+func (_c *Flags_AllowedCommandsVerifyIntegrity_Call) Run(run func()) *Flags_AllowedCommandsVerifyIntegrity_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Flags_AllowedCommandsVerifyIntegrity_Call) Return(r0 bool) *Flags_AllowedCommandsVerifyIntegrity_Call {
+	_c.Call.Return(r0)
+	return _c
+}
+
+func (_c *Flags_AllowedCommandsVerifyIntegrity_Call) RunAndReturn(run func() bool) *Flags_AllowedCommandsVerifyIntegrity_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Autoupdate provides a mock function for the type Flags
 func (_mock *Flags) Autoupdate() bool {
 	ret := _mock.Called()
@@ -127,6 +259,50 @@ func (_c *Flags_Autoupdate_Call) RunAndReturn(run func() bool) *Flags_Autoupdate
 	return _c
 }
 
+// AutoupdateBandwidthLimitKB provides a mock function for the type Flags
+func (_mock *Flags) AutoupdateBandwidthLimitKB() int {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for AutoupdateBandwidthLimitKB")
+	}
+
+	var r0 int
+	if returnFunc, ok := ret.Get(0).(func() int); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+	return r0
+}
+
+// Flags_AutoupdateBandwidthLimitKB_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AutoupdateBandwidthLimitKB'
+type Flags_AutoupdateBandwidthLimitKB_Call struct {
+	*mock.Call
+}
+
+// AutoupdateBandwidthLimitKB is a helper method to define mock.On call
+func (_e *Flags_Expecter) AutoupdateBandwidthLimitKB() *Flags_AutoupdateBandwidthLimitKB_Call {
+	return &Flags_AutoupdateBandwidthLimitKB_Call{Call: _e.mock.On("AutoupdateBandwidthLimitKB")}
+}
+
+func (_c *Flags_AutoupdateBandwidthLimitKB_Call) Run(run func()) *Flags_AutoupdateBandwidthLimitKB_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Flags_AutoupdateBandwidthLimitKB_Call) Return(_a0 int) *Flags_AutoupdateBandwidthLimitKB_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Flags_AutoupdateBandwidthLimitKB_Call) RunAndReturn(run func() int) *Flags_AutoupdateBandwidthLimitKB_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // AutoupdateDownloadSplay provides a mock function for the type Flags
 func (_mock *Flags) AutoupdateDownloadSplay() time.Duration {
 	ret := _mock.Called()
@@ -481,56 +657,56 @@ func (_c *Flags_CurrentRunningOsqueryVersion_Call) RunAndReturn(run func() strin
 	return _c
 }
 
-// Debug provides a mock function for the type Flags
-func (_mock *Flags) Debug() bool {
+// CustomerOTLPCAPath provides a mock function for the type Flags
+func (_mock *Flags) CustomerOTLPCAPath() string {
 	ret := _mock.Called()
 
 	if len(ret) == 0 {
-		panic("no return value specified for Debug")
+		panic("no return value specified for CustomerOTLPCAPath")
 	}
 
-	var r0 bool
-	if returnFunc, ok := ret.Get(0).(func() bool); ok {
+	var r0 string
+	if returnFunc, ok := ret.Get(0).(func() string); ok {
 		r0 = returnFunc()
 	} else {
-		r0 = ret.Get(0).(bool)
+		r0 = ret.Get(0).(string)
 	}
 	return r0
 }
 
-// Flags_Debug_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Debug'
-type Flags_Debug_Call struct {
+// Flags_CustomerOTLPCAPath_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CustomerOTLPCAPath'
+type Flags_CustomerOTLPCAPath_Call struct {
 	*mock.Call
 }
 
-// Debug is a helper method to define mock.On call
-func (_e *Flags_Expecter) Debug() *Flags_Debug_Call {
-	return &Flags_Debug_Call{Call: _e.mock.On("Debug")}
+// CustomerOTLPCAPath is a helper method to define mock.On call
+func (_e *Flags_Expecter) CustomerOTLPCAPath() *Flags_CustomerOTLPCAPath_Call {
+	return &Flags_CustomerOTLPCAPath_Call{Call: _e.mock.On("CustomerOTLPCAPath")}
 }
 
-func (_c *Flags_Debug_Call) Run(run func()) *Flags_Debug_Call {
+func (_c *Flags_CustomerOTLPCAPath_Call) Run(run func()) *Flags_CustomerOTLPCAPath_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run()
 	})
 	return _c
 }
 
-func (_c *Flags_Debug_Call) Return(b bool) *Flags_Debug_Call {
-	_c.Call.Return(b)
+func (_c *Flags_CustomerOTLPCAPath_Call) Return(_a string) *Flags_CustomerOTLPCAPath_Call {
+	_c.Call.Return(_a)
 	return _c
 }
 
-func (_c *Flags_Debug_Call) RunAndReturn(run func() bool) *Flags_Debug_Call {
+func (_c *Flags_CustomerOTLPCAPath_Call) RunAndReturn(run func() string) *Flags_CustomerOTLPCAPath_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// DebugLogFile provides a mock function for the type Flags
-func (_mock *Flags) DebugLogFile() string {
+// CustomerOTLPEndpoint provides a mock function for the type Flags
+func (_mock *Flags) CustomerOTLPEndpoint() string {
 	ret := _mock.Called()
 
 	if len(ret) == 0 {
-		panic("no return value specified for DebugLogFile")
+		panic("no return value specified for CustomerOTLPEndpoint")
 	}
 
 	var r0 string
@@ -542,197 +718,417 @@ func (_mock *Flags) DebugLogFile() string {
 	return r0
 }
 
-// Flags_DebugLogFile_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DebugLogFile'
-type Flags_DebugLogFile_Call struct {
+// Flags_CustomerOTLPEndpoint_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CustomerOTLPEndpoint'
+type Flags_CustomerOTLPEndpoint_Call struct {
 	*mock.Call
 }
 
-// DebugLogFile is a helper method to define mock.On call
-func (_e *Flags_Expecter) DebugLogFile() *Flags_DebugLogFile_Call {
-	return &Flags_DebugLogFile_Call{Call: _e.mock.On("DebugLogFile")}
+// CustomerOTLPEndpoint is a helper method to define mock.On call
+func (_e *Flags_Expecter) CustomerOTLPEndpoint() *Flags_CustomerOTLPEndpoint_Call {
+	return &Flags_CustomerOTLPEndpoint_Call{Call: _e.mock.On("CustomerOTLPEndpoint")}
 }
 
-func (_c *Flags_DebugLogFile_Call) Run(run func()) *Flags_DebugLogFile_Call {
+func (_c *Flags_CustomerOTLPEndpoint_Call) Run(run func()) *Flags_CustomerOTLPEndpoint_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run()
 	})
 	return _c
 }
 
-func (_c *Flags_DebugLogFile_Call) Return(s string) *Flags_DebugLogFile_Call {
-	_c.Call.Return(s)
+func (_c *Flags_CustomerOTLPEndpoint_Call) Return(_a string) *Flags_CustomerOTLPEndpoint_Call {
+	_c.Call.Return(_a)
 	return _c
 }
 
-func (_c *Flags_DebugLogFile_Call) RunAndReturn(run func() string) *Flags_DebugLogFile_Call {
+func (_c *Flags_CustomerOTLPEndpoint_Call) RunAndReturn(run func() string) *Flags_CustomerOTLPEndpoint_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// DebugServerData provides a mock function for the type Flags
-func (_mock *Flags) DebugServerData() bool {
+// CustomerOTLPHeaders provides a mock function for the type Flags
+func (_mock *Flags) CustomerOTLPHeaders() string {
 	ret := _mock.Called()
 
 	if len(ret) == 0 {
-		panic("no return value specified for DebugServerData")
+		panic("no return value specified for CustomerOTLPHeaders")
 	}
 
-	var r0 bool
-	if returnFunc, ok := ret.Get(0).(func() bool); ok {
+	var r0 string
+	if returnFunc, ok := ret.Get(0).(func() string); ok {
 		r0 = returnFunc()
 	} else {
-		r0 = ret.Get(0).(bool)
+		r0 = ret.Get(0).(string)
 	}
 	return r0
 }
 
-// Flags_DebugServerData_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DebugServerData'
-type Flags_DebugServerData_Call struct {
+// Flags_CustomerOTLPHeaders_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CustomerOTLPHeaders'
+type Flags_CustomerOTLPHeaders_Call struct {
 	*mock.Call
 }
 
-// DebugServerData is a helper method to define mock.On call
-func (_e *Flags_Expecter) DebugServerData() *Flags_DebugServerData_Call {
-	return &Flags_DebugServerData_Call{Call: _e.mock.On("DebugServerData")}
+// CustomerOTLPHeaders is a helper method to define mock.On call
+func (_e *Flags_Expecter) CustomerOTLPHeaders() *Flags_CustomerOTLPHeaders_Call {
+	return &Flags_CustomerOTLPHeaders_Call{Call: _e.mock.On("CustomerOTLPHeaders")}
 }
 
-func (_c *Flags_DebugServerData_Call) Run(run func()) *Flags_DebugServerData_Call {
+func (_c *Flags_CustomerOTLPHeaders_Call) Run(run func()) *Flags_CustomerOTLPHeaders_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run()
 	})
 	return _c
 }
 
-func (_c *Flags_DebugServerData_Call) Return(b bool) *Flags_DebugServerData_Call {
-	_c.Call.Return(b)
+func (_c *Flags_CustomerOTLPHeaders_Call) Return(_a string) *Flags_CustomerOTLPHeaders_Call {
+	_c.Call.Return(_a)
 	return _c
 }
 
-func (_c *Flags_DebugServerData_Call) RunAndReturn(run func() bool) *Flags_DebugServerData_Call {
+func (_c *Flags_CustomerOTLPHeaders_Call) RunAndReturn(run func() string) *Flags_CustomerOTLPHeaders_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// DeregisterChangeObserver provides a mock function for the type Flags
-func (_mock *Flags) DeregisterChangeObserver(observer types.FlagsChangeObserver) {
-	_mock.Called(observer)
-	return
+// CustomerOTLPOnly provides a mock function for the type Flags
+func (_mock *Flags) CustomerOTLPOnly() bool {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for CustomerOTLPOnly")
+	}
+
+	var r0 bool
+	if returnFunc, ok := ret.Get(0).(func() bool); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	return r0
 }
 
-// Flags_DeregisterChangeObserver_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeregisterChangeObserver'
-type Flags_DeregisterChangeObserver_Call struct {
+// Flags_CustomerOTLPOnly_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CustomerOTLPOnly'
+type Flags_CustomerOTLPOnly_Call struct {
 	*mock.Call
 }
 
-// DeregisterChangeObserver is a helper method to define mock.On call
-//   - observer types.FlagsChangeObserver
-func (_e *Flags_Expecter) DeregisterChangeObserver(observer interface{}) *Flags_DeregisterChangeObserver_Call {
-	return &Flags_DeregisterChangeObserver_Call{Call: _e.mock.On("DeregisterChangeObserver", observer)}
+// CustomerOTLPOnly is a helper method to define mock.On call
+func (_e *Flags_Expecter) CustomerOTLPOnly() *Flags_CustomerOTLPOnly_Call {
+	return &Flags_CustomerOTLPOnly_Call{Call: _e.mock.On("CustomerOTLPOnly")}
 }
 
-func (_c *Flags_DeregisterChangeObserver_Call) Run(run func(observer types.FlagsChangeObserver)) *Flags_DeregisterChangeObserver_Call {
+func (_c *Flags_CustomerOTLPOnly_Call) Run(run func()) *Flags_CustomerOTLPOnly_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		var arg0 types.FlagsChangeObserver
-		if args[0] != nil {
-			arg0 = args[0].(types.FlagsChangeObserver)
-		}
-		run(
-			arg0,
-		)
+		run()
 	})
 	return _c
 }
 
-func (_c *Flags_DeregisterChangeObserver_Call) Return() *Flags_DeregisterChangeObserver_Call {
-	_c.Call.Return()
+func (_c *Flags_CustomerOTLPOnly_Call) Return(_a bool) *Flags_CustomerOTLPOnly_Call {
+	_c.Call.Return(_a)
 	return _c
 }
 
-func (_c *Flags_DeregisterChangeObserver_Call) RunAndReturn(run func(observer types.FlagsChangeObserver)) *Flags_DeregisterChangeObserver_Call {
-	_c.Run(run)
+func (_c *Flags_CustomerOTLPOnly_Call) RunAndReturn(run func() bool) *Flags_CustomerOTLPOnly_Call {
+	_c.Call.Return(run)
 	return _c
 }
 
-// DesktopEnabled provides a mock function for the type Flags
-func (_mock *Flags) DesktopEnabled() bool {
+// CustomerOTLPSamplingRate provides a mock function for the type Flags
+func (_mock *Flags) CustomerOTLPSamplingRate() float64 {
 	ret := _mock.Called()
 
 	if len(ret) == 0 {
-		panic("no return value specified for DesktopEnabled")
+		panic("no return value specified for CustomerOTLPSamplingRate")
 	}
 
-	var r0 bool
-	if returnFunc, ok := ret.Get(0).(func() bool); ok {
+	var r0 float64
+	if returnFunc, ok := ret.Get(0).(func() float64); ok {
 		r0 = returnFunc()
 	} else {
-		r0 = ret.Get(0).(bool)
+		r0 = ret.Get(0).(float64)
 	}
 	return r0
 }
 
-// Flags_DesktopEnabled_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DesktopEnabled'
-type Flags_DesktopEnabled_Call struct {
+// Flags_CustomerOTLPSamplingRate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CustomerOTLPSamplingRate'
+type Flags_CustomerOTLPSamplingRate_Call struct {
 	*mock.Call
 }
 
-// DesktopEnabled is a helper method to define mock.On call
-func (_e *Flags_Expecter) DesktopEnabled() *Flags_DesktopEnabled_Call {
-	return &Flags_DesktopEnabled_Call{Call: _e.mock.On("DesktopEnabled")}
+// CustomerOTLPSamplingRate is a helper method to define mock.On call
+func (_e *Flags_Expecter) CustomerOTLPSamplingRate() *Flags_CustomerOTLPSamplingRate_Call {
+	return &Flags_CustomerOTLPSamplingRate_Call{Call: _e.mock.On("CustomerOTLPSamplingRate")}
 }
 
-func (_c *Flags_DesktopEnabled_Call) Run(run func()) *Flags_DesktopEnabled_Call {
+func (_c *Flags_CustomerOTLPSamplingRate_Call) Run(run func()) *Flags_CustomerOTLPSamplingRate_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run()
 	})
 	return _c
 }
 
-func (_c *Flags_DesktopEnabled_Call) Return(b bool) *Flags_DesktopEnabled_Call {
-	_c.Call.Return(b)
+func (_c *Flags_CustomerOTLPSamplingRate_Call) Return(_a float64) *Flags_CustomerOTLPSamplingRate_Call {
+	_c.Call.Return(_a)
 	return _c
 }
 
-func (_c *Flags_DesktopEnabled_Call) RunAndReturn(run func() bool) *Flags_DesktopEnabled_Call {
+func (_c *Flags_CustomerOTLPSamplingRate_Call) RunAndReturn(run func() float64) *Flags_CustomerOTLPSamplingRate_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// DesktopGoMaxProcs provides a mock function for the type Flags
-func (_mock *Flags) DesktopGoMaxProcs() int {
+// Debug provides a mock function for the type Flags
+func (_mock *Flags) Debug() bool {
 	ret := _mock.Called()
 
 	if len(ret) == 0 {
-		panic("no return value specified for DesktopGoMaxProcs")
+		panic("no return value specified for Debug")
 	}
 
-	var r0 int
-	if returnFunc, ok := ret.Get(0).(func() int); ok {
+	var r0 bool
+	if returnFunc, ok := ret.Get(0).(func() bool); ok {
 		r0 = returnFunc()
 	} else {
-		r0 = ret.Get(0).(int)
+		r0 = ret.Get(0).(bool)
 	}
 	return r0
 }
 
-// Flags_DesktopGoMaxProcs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DesktopGoMaxProcs'
-type Flags_DesktopGoMaxProcs_Call struct {
+// Flags_Debug_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Debug'
+type Flags_Debug_Call struct {
 	*mock.Call
 }
 
-// DesktopGoMaxProcs is a helper method to define mock.On call
-func (_e *Flags_Expecter) DesktopGoMaxProcs() *Flags_DesktopGoMaxProcs_Call {
-	return &Flags_DesktopGoMaxProcs_Call{Call: _e.mock.On("DesktopGoMaxProcs")}
+// Debug is a helper method to define mock.On call
+func (_e *Flags_Expecter) Debug() *Flags_Debug_Call {
+	return &Flags_Debug_Call{Call: _e.mock.On("Debug")}
 }
 
-func (_c *Flags_DesktopGoMaxProcs_Call) Run(run func()) *Flags_DesktopGoMaxProcs_Call {
+func (_c *Flags_Debug_Call) Run(run func()) *Flags_Debug_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run()
 	})
 	return _c
 }
 
-func (_c *Flags_DesktopGoMaxProcs_Call) Return(n int) *Flags_DesktopGoMaxProcs_Call {
-	_c.Call.Return(n)
+func (_c *Flags_Debug_Call) Return(b bool) *Flags_Debug_Call {
+	_c.Call.Return(b)
+	return _c
+}
+
+func (_c *Flags_Debug_Call) RunAndReturn(run func() bool) *Flags_Debug_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DebugLogFile provides a mock function for the type Flags
+func (_mock *Flags) DebugLogFile() string {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for DebugLogFile")
+	}
+
+	var r0 string
+	if returnFunc, ok := ret.Get(0).(func() string); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	return r0
+}
+
+// Flags_DebugLogFile_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DebugLogFile'
+type Flags_DebugLogFile_Call struct {
+	*mock.Call
+}
+
+// DebugLogFile is a helper method to define mock.On call
+func (_e *Flags_Expecter) DebugLogFile() *Flags_DebugLogFile_Call {
+	return &Flags_DebugLogFile_Call{Call: _e.mock.On("DebugLogFile")}
+}
+
+func (_c *Flags_DebugLogFile_Call) Run(run func()) *Flags_DebugLogFile_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Flags_DebugLogFile_Call) Return(s string) *Flags_DebugLogFile_Call {
+	_c.Call.Return(s)
+	return _c
+}
+
+func (_c *Flags_DebugLogFile_Call) RunAndReturn(run func() string) *Flags_DebugLogFile_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DebugServerData provides a mock function for the type Flags
+func (_mock *Flags) DebugServerData() bool {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for DebugServerData")
+	}
+
+	var r0 bool
+	if returnFunc, ok := ret.Get(0).(func() bool); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	return r0
+}
+
+// Flags_DebugServerData_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DebugServerData'
+type Flags_DebugServerData_Call struct {
+	*mock.Call
+}
+
+// DebugServerData is a helper method to define mock.On call
+func (_e *Flags_Expecter) DebugServerData() *Flags_DebugServerData_Call {
+	return &Flags_DebugServerData_Call{Call: _e.mock.On("DebugServerData")}
+}
+
+func (_c *Flags_DebugServerData_Call) Run(run func()) *Flags_DebugServerData_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Flags_DebugServerData_Call) Return(b bool) *Flags_DebugServerData_Call {
+	_c.Call.Return(b)
+	return _c
+}
+
+func (_c *Flags_DebugServerData_Call) RunAndReturn(run func() bool) *Flags_DebugServerData_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeregisterChangeObserver provides a mock function for the type Flags
+func (_mock *Flags) DeregisterChangeObserver(observer types.FlagsChangeObserver) {
+	_mock.Called(observer)
+	return
+}
+
+// Flags_DeregisterChangeObserver_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeregisterChangeObserver'
+type Flags_DeregisterChangeObserver_Call struct {
+	*mock.Call
+}
+
+// DeregisterChangeObserver is a helper method to define mock.On call
+//   - observer types.FlagsChangeObserver
+func (_e *Flags_Expecter) DeregisterChangeObserver(observer interface{}) *Flags_DeregisterChangeObserver_Call {
+	return &Flags_DeregisterChangeObserver_Call{Call: _e.mock.On("DeregisterChangeObserver", observer)}
+}
+
+func (_c *Flags_DeregisterChangeObserver_Call) Run(run func(observer types.FlagsChangeObserver)) *Flags_DeregisterChangeObserver_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 types.FlagsChangeObserver
+		if args[0] != nil {
+			arg0 = args[0].(types.FlagsChangeObserver)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *Flags_DeregisterChangeObserver_Call) Return() *Flags_DeregisterChangeObserver_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *Flags_DeregisterChangeObserver_Call) RunAndReturn(run func(observer types.FlagsChangeObserver)) *Flags_DeregisterChangeObserver_Call {
+	_c.Run(run)
+	return _c
+}
+
+// DesktopEnabled provides a mock function for the type Flags
+func (_mock *Flags) DesktopEnabled() bool {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for DesktopEnabled")
+	}
+
+	var r0 bool
+	if returnFunc, ok := ret.Get(0).(func() bool); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	return r0
+}
+
+// Flags_DesktopEnabled_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DesktopEnabled'
+type Flags_DesktopEnabled_Call struct {
+	*mock.Call
+}
+
+// DesktopEnabled is a helper method to define mock.On call
+func (_e *Flags_Expecter) DesktopEnabled() *Flags_DesktopEnabled_Call {
+	return &Flags_DesktopEnabled_Call{Call: _e.mock.On("DesktopEnabled")}
+}
+
+func (_c *Flags_DesktopEnabled_Call) Run(run func()) *Flags_DesktopEnabled_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Flags_DesktopEnabled_Call) Return(b bool) *Flags_DesktopEnabled_Call {
+	_c.Call.Return(b)
+	return _c
+}
+
+func (_c *Flags_DesktopEnabled_Call) RunAndReturn(run func() bool) *Flags_DesktopEnabled_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DesktopGoMaxProcs provides a mock function for the type Flags
+func (_mock *Flags) DesktopGoMaxProcs() int {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for DesktopGoMaxProcs")
+	}
+
+	var r0 int
+	if returnFunc, ok := ret.Get(0).(func() int); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+	return r0
+}
+
+// Flags_DesktopGoMaxProcs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DesktopGoMaxProcs'
+type Flags_DesktopGoMaxProcs_Call struct {
+	*mock.Call
+}
+
+// DesktopGoMaxProcs is a helper method to define mock.On call
+func (_e *Flags_Expecter) DesktopGoMaxProcs() *Flags_DesktopGoMaxProcs_Call {
+	return &Flags_DesktopGoMaxProcs_Call{Call: _e.mock.On("DesktopGoMaxProcs")}
+}
+
+func (_c *Flags_DesktopGoMaxProcs_Call) Run(run func()) *Flags_DesktopGoMaxProcs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Flags_DesktopGoMaxProcs_Call) Return(n int) *Flags_DesktopGoMaxProcs_Call {
+	_c.Call.Return(n)
 	return _c
 }
 
@@ -873,6 +1269,50 @@ func (_c *Flags_DisableControlTLS_Call) RunAndReturn(run func() bool) *Flags_Dis
 	return _c
 }
 
+// DisableCustomerOTLPTLS provides a mock function for the type Flags
+func (_mock *Flags) DisableCustomerOTLPTLS() bool {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for DisableCustomerOTLPTLS")
+	}
+
+	var r0 bool
+	if returnFunc, ok := ret.Get(0).(func() bool); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	return r0
+}
+
+// Flags_DisableCustomerOTLPTLS_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DisableCustomerOTLPTLS'
+type Flags_DisableCustomerOTLPTLS_Call struct {
+	*mock.Call
+}
+
+// DisableCustomerOTLPTLS is a helper method to define mock.On call
+func (_e *Flags_Expecter) DisableCustomerOTLPTLS() *Flags_DisableCustomerOTLPTLS_Call {
+	return &Flags_DisableCustomerOTLPTLS_Call{Call: _e.mock.On("DisableCustomerOTLPTLS")}
+}
+
+func (_c *Flags_DisableCustomerOTLPTLS_Call) Run(run func()) *Flags_DisableCustomerOTLPTLS_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Flags_DisableCustomerOTLPTLS_Call) Return(_a bool) *Flags_DisableCustomerOTLPTLS_Call {
+	_c.Call.Return(_a)
+	return _c
+}
+
+func (_c *Flags_DisableCustomerOTLPTLS_Call) RunAndReturn(run func() bool) *Flags_DisableCustomerOTLPTLS_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // DisableTraceIngestTLS provides a mock function for the type Flags
 func (_mock *Flags) DisableTraceIngestTLS() bool {
 	ret := _mock.Called()
@@ -1005,6 +1445,50 @@ func (_c *Flags_DuplicateLogWindow_Call) RunAndReturn(run func() time.Duration)
 	return _c
 }
 
+// DuplicateLogWindowOverrides provides a mock function for the type Flags
+func (_mock *Flags) DuplicateLogWindowOverrides() string {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for DuplicateLogWindowOverrides")
+	}
+
+	var r0 string
+	if returnFunc, ok := ret.Get(0).(func() string); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	return r0
+}
+
+// Flags_DuplicateLogWindowOverrides_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DuplicateLogWindowOverrides'
+type Flags_DuplicateLogWindowOverrides_Call struct {
+	*mock.Call
+}
+
+// DuplicateLogWindowOverrides is a helper method to define mock.On call
+func (_e *Flags_Expecter) DuplicateLogWindowOverrides() *Flags_DuplicateLogWindowOverrides_Call {
+	return &Flags_DuplicateLogWindowOverrides_Call{Call: _e.mock.On("DuplicateLogWindowOverrides")}
+}
+
+func (_c *Flags_DuplicateLogWindowOverrides_Call) Run(run func()) *Flags_DuplicateLogWindowOverrides_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Flags_DuplicateLogWindowOverrides_Call) Return(r0 string) *Flags_DuplicateLogWindowOverrides_Call {
+	_c.Call.Return(r0)
+	return _c
+}
+
+func (_c *Flags_DuplicateLogWindowOverrides_Call) RunAndReturn(run func() string) *Flags_DuplicateLogWindowOverrides_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // EnableInitialRunner provides a mock function for the type Flags
 func (_mock *Flags) EnableInitialRunner() bool {
 	ret := _mock.Called()
@@ -1181,6 +1665,94 @@ func (_c *Flags_ExportTraces_Call) RunAndReturn(run func() bool) *Flags_ExportTr
 	return _c
 }
 
+// ExternalCheckups provides a mock function for the type Flags
+func (_mock *Flags) ExternalCheckups() string {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExternalCheckups")
+	}
+
+	var r0 string
+	if returnFunc, ok := ret.Get(0).(func() string); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	return r0
+}
+
+// Flags_ExternalCheckups_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExternalCheckups'
+type Flags_ExternalCheckups_Call struct {
+	*mock.Call
+}
+
+// ExternalCheckups is a helper method to define mock.On call
+func (_e *Flags_Expecter) ExternalCheckups() *Flags_ExternalCheckups_Call {
+	return &Flags_ExternalCheckups_Call{Call: _e.mock.On("ExternalCheckups")}
+}
+
+func (_c *Flags_ExternalCheckups_Call) Run(run func()) *Flags_ExternalCheckups_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Flags_ExternalCheckups_Call) Return(_a string) *Flags_ExternalCheckups_Call {
+	_c.Call.Return(_a)
+	return _c
+}
+
+func (_c *Flags_ExternalCheckups_Call) RunAndReturn(run func() string) *Flags_ExternalCheckups_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FlareRedactionRules provides a mock function for the type Flags
+func (_mock *Flags) FlareRedactionRules() string {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for FlareRedactionRules")
+	}
+
+	var r0 string
+	if returnFunc, ok := ret.Get(0).(func() string); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	return r0
+}
+
+// Flags_FlareRedactionRules_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FlareRedactionRules'
+type Flags_FlareRedactionRules_Call struct {
+	*mock.Call
+}
+
+// FlareRedactionRules is a helper method to define mock.On call
+func (_e *Flags_Expecter) FlareRedactionRules() *Flags_FlareRedactionRules_Call {
+	return &Flags_FlareRedactionRules_Call{Call: _e.mock.On("FlareRedactionRules")}
+}
+
+func (_c *Flags_FlareRedactionRules_Call) Run(run func()) *Flags_FlareRedactionRules_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Flags_FlareRedactionRules_Call) Return(_a string) *Flags_FlareRedactionRules_Call {
+	_c.Call.Return(_a)
+	return _c
+}
+
+func (_c *Flags_FlareRedactionRules_Call) RunAndReturn(run func() string) *Flags_FlareRedactionRules_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // ForceControlSubsystems provides a mock function for the type Flags
 func (_mock *Flags) ForceControlSubsystems() bool {
 	ret := _mock.Called()
@@ -1225,6 +1797,50 @@ func (_c *Flags_ForceControlSubsystems_Call) RunAndReturn(run func() bool) *Flag
 	return _c
 }
 
+// HardwareKeyRotationInterval provides a mock function for the type Flags
+func (_mock *Flags) HardwareKeyRotationInterval() time.Duration {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for HardwareKeyRotationInterval")
+	}
+
+	var r0 time.Duration
+	if returnFunc, ok := ret.Get(0).(func() time.Duration); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(time.Duration)
+	}
+	return r0
+}
+
+// Flags_HardwareKeyRotationInterval_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HardwareKeyRotationInterval'
+type Flags_HardwareKeyRotationInterval_Call struct {
+	*mock.Call
+}
+
+// HardwareKeyRotationInterval is a helper method to define mock.On call
+func (_e *Flags_Expecter) HardwareKeyRotationInterval() *Flags_HardwareKeyRotationInterval_Call {
+	return &Flags_HardwareKeyRotationInterval_Call{Call: _e.mock.On("HardwareKeyRotationInterval")}
+}
+
+func (_c *Flags_HardwareKeyRotationInterval_Call) Run(run func()) *Flags_HardwareKeyRotationInterval_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Flags_HardwareKeyRotationInterval_Call) Return(r0 time.Duration) *Flags_HardwareKeyRotationInterval_Call {
+	_c.Call.Return(r0)
+	return _c
+}
+
+func (_c *Flags_HardwareKeyRotationInterval_Call) RunAndReturn(run func() time.Duration) *Flags_HardwareKeyRotationInterval_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // IAmBreakingEELicense provides a mock function for the type Flags
 func (_mock *Flags) IAmBreakingEELicense() bool {
 	ret := _mock.Called()
@@ -1440,105 +2056,193 @@ func (_c *Flags_InsecureTLS_Call) Return(b bool) *Flags_InsecureTLS_Call {
 	return _c
 }
 
-func (_c *Flags_InsecureTLS_Call) RunAndReturn(run func() bool) *Flags_InsecureTLS_Call {
+func (_c *Flags_InsecureTLS_Call) RunAndReturn(run func() bool) *Flags_InsecureTLS_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// InsecureTransportTLS provides a mock function for the type Flags
+func (_mock *Flags) InsecureTransportTLS() bool {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsecureTransportTLS")
+	}
+
+	var r0 bool
+	if returnFunc, ok := ret.Get(0).(func() bool); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	return r0
+}
+
+// Flags_InsecureTransportTLS_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'InsecureTransportTLS'
+type Flags_InsecureTransportTLS_Call struct {
+	*mock.Call
+}
+
+// InsecureTransportTLS is a helper method to define mock.On call
+func (_e *Flags_Expecter) InsecureTransportTLS() *Flags_InsecureTransportTLS_Call {
+	return &Flags_InsecureTransportTLS_Call{Call: _e.mock.On("InsecureTransportTLS")}
+}
+
+func (_c *Flags_InsecureTransportTLS_Call) Run(run func()) *Flags_InsecureTransportTLS_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Flags_InsecureTransportTLS_Call) Return(b bool) *Flags_InsecureTransportTLS_Call {
+	_c.Call.Return(b)
+	return _c
+}
+
+func (_c *Flags_InsecureTransportTLS_Call) RunAndReturn(run func() bool) *Flags_InsecureTransportTLS_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// KolideHosted provides a mock function for the type Flags
+func (_mock *Flags) KolideHosted() bool {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for KolideHosted")
+	}
+
+	var r0 bool
+	if returnFunc, ok := ret.Get(0).(func() bool); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	return r0
+}
+
+// Flags_KolideHosted_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'KolideHosted'
+type Flags_KolideHosted_Call struct {
+	*mock.Call
+}
+
+// KolideHosted is a helper method to define mock.On call
+func (_e *Flags_Expecter) KolideHosted() *Flags_KolideHosted_Call {
+	return &Flags_KolideHosted_Call{Call: _e.mock.On("KolideHosted")}
+}
+
+func (_c *Flags_KolideHosted_Call) Run(run func()) *Flags_KolideHosted_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Flags_KolideHosted_Call) Return(b bool) *Flags_KolideHosted_Call {
+	_c.Call.Return(b)
+	return _c
+}
+
+func (_c *Flags_KolideHosted_Call) RunAndReturn(run func() bool) *Flags_KolideHosted_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// InsecureTransportTLS provides a mock function for the type Flags
-func (_mock *Flags) InsecureTransportTLS() bool {
+// KolideServerURL provides a mock function for the type Flags
+func (_mock *Flags) KolideServerURL() string {
 	ret := _mock.Called()
 
 	if len(ret) == 0 {
-		panic("no return value specified for InsecureTransportTLS")
+		panic("no return value specified for KolideServerURL")
 	}
 
-	var r0 bool
-	if returnFunc, ok := ret.Get(0).(func() bool); ok {
+	var r0 string
+	if returnFunc, ok := ret.Get(0).(func() string); ok {
 		r0 = returnFunc()
 	} else {
-		r0 = ret.Get(0).(bool)
+		r0 = ret.Get(0).(string)
 	}
 	return r0
 }
 
-// Flags_InsecureTransportTLS_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'InsecureTransportTLS'
-type Flags_InsecureTransportTLS_Call struct {
+// Flags_KolideServerURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'KolideServerURL'
+type Flags_KolideServerURL_Call struct {
 	*mock.Call
 }
 
-// InsecureTransportTLS is a helper method to define mock.On call
-func (_e *Flags_Expecter) InsecureTransportTLS() *Flags_InsecureTransportTLS_Call {
-	return &Flags_InsecureTransportTLS_Call{Call: _e.mock.On("InsecureTransportTLS")}
+// KolideServerURL is a helper method to define mock.On call
+func (_e *Flags_Expecter) KolideServerURL() *Flags_KolideServerURL_Call {
+	return &Flags_KolideServerURL_Call{Call: _e.mock.On("KolideServerURL")}
 }
 
-func (_c *Flags_InsecureTransportTLS_Call) Run(run func()) *Flags_InsecureTransportTLS_Call {
+func (_c *Flags_KolideServerURL_Call) Run(run func()) *Flags_KolideServerURL_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run()
 	})
 	return _c
 }
 
-func (_c *Flags_InsecureTransportTLS_Call) Return(b bool) *Flags_InsecureTransportTLS_Call {
-	_c.Call.Return(b)
+func (_c *Flags_KolideServerURL_Call) Return(s string) *Flags_KolideServerURL_Call {
+	_c.Call.Return(s)
 	return _c
 }
 
-func (_c *Flags_InsecureTransportTLS_Call) RunAndReturn(run func() bool) *Flags_InsecureTransportTLS_Call {
+func (_c *Flags_KolideServerURL_Call) RunAndReturn(run func() string) *Flags_KolideServerURL_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// KolideHosted provides a mock function for the type Flags
-func (_mock *Flags) KolideHosted() bool {
+// LanCacheListenAddress provides a mock function for the type Flags
+func (_mock *Flags) LanCacheListenAddress() string {
 	ret := _mock.Called()
 
 	if len(ret) == 0 {
-		panic("no return value specified for KolideHosted")
+		panic("no return value specified for LanCacheListenAddress")
 	}
 
-	var r0 bool
-	if returnFunc, ok := ret.Get(0).(func() bool); ok {
+	var r0 string
+	if returnFunc, ok := ret.Get(0).(func() string); ok {
 		r0 = returnFunc()
 	} else {
-		r0 = ret.Get(0).(bool)
+		r0 = ret.Get(0).(string)
 	}
 	return r0
 }
 
-// Flags_KolideHosted_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'KolideHosted'
-type Flags_KolideHosted_Call struct {
+// Flags_LanCacheListenAddress_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LanCacheListenAddress'
+type Flags_LanCacheListenAddress_Call struct {
 	*mock.Call
 }
 
-// KolideHosted is a helper method to define mock.On call
-func (_e *Flags_Expecter) KolideHosted() *Flags_KolideHosted_Call {
-	return &Flags_KolideHosted_Call{Call: _e.mock.On("KolideHosted")}
+// LanCacheListenAddress is a helper method to define mock.On call
+func (_e *Flags_Expecter) LanCacheListenAddress() *Flags_LanCacheListenAddress_Call {
+	return &Flags_LanCacheListenAddress_Call{Call: _e.mock.On("LanCacheListenAddress")}
 }
 
-func (_c *Flags_KolideHosted_Call) Run(run func()) *Flags_KolideHosted_Call {
+func (_c *Flags_LanCacheListenAddress_Call) Run(run func()) *Flags_LanCacheListenAddress_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run()
 	})
 	return _c
 }
 
-func (_c *Flags_KolideHosted_Call) Return(b bool) *Flags_KolideHosted_Call {
-	_c.Call.Return(b)
+func (_c *Flags_LanCacheListenAddress_Call) Return(s string) *Flags_LanCacheListenAddress_Call {
+	_c.Call.Return(s)
 	return _c
 }
 
-func (_c *Flags_KolideHosted_Call) RunAndReturn(run func() bool) *Flags_KolideHosted_Call {
+func (_c *Flags_LanCacheListenAddress_Call) RunAndReturn(run func() string) *Flags_LanCacheListenAddress_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// KolideServerURL provides a mock function for the type Flags
-func (_mock *Flags) KolideServerURL() string {
+// LanCachePeers provides a mock function for the type Flags
+func (_mock *Flags) LanCachePeers() string {
 	ret := _mock.Called()
 
 	if len(ret) == 0 {
-		panic("no return value specified for KolideServerURL")
+		panic("no return value specified for LanCachePeers")
 	}
 
 	var r0 string
@@ -1550,29 +2254,29 @@ func (_mock *Flags) KolideServerURL() string {
 	return r0
 }
 
-// Flags_KolideServerURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'KolideServerURL'
-type Flags_KolideServerURL_Call struct {
+// Flags_LanCachePeers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LanCachePeers'
+type Flags_LanCachePeers_Call struct {
 	*mock.Call
 }
 
-// KolideServerURL is a helper method to define mock.On call
-func (_e *Flags_Expecter) KolideServerURL() *Flags_KolideServerURL_Call {
-	return &Flags_KolideServerURL_Call{Call: _e.mock.On("KolideServerURL")}
+// LanCachePeers is a helper method to define mock.On call
+func (_e *Flags_Expecter) LanCachePeers() *Flags_LanCachePeers_Call {
+	return &Flags_LanCachePeers_Call{Call: _e.mock.On("LanCachePeers")}
 }
 
-func (_c *Flags_KolideServerURL_Call) Run(run func()) *Flags_KolideServerURL_Call {
+func (_c *Flags_LanCachePeers_Call) Run(run func()) *Flags_LanCachePeers_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run()
 	})
 	return _c
 }
 
-func (_c *Flags_KolideServerURL_Call) Return(s string) *Flags_KolideServerURL_Call {
+func (_c *Flags_LanCachePeers_Call) Return(s string) *Flags_LanCachePeers_Call {
 	_c.Call.Return(s)
 	return _c
 }
 
-func (_c *Flags_KolideServerURL_Call) RunAndReturn(run func() string) *Flags_KolideServerURL_Call {
+func (_c *Flags_LanCachePeers_Call) RunAndReturn(run func() string) *Flags_LanCachePeers_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -1885,6 +2589,50 @@ func (_c *Flags_LoggingInterval_Call) RunAndReturn(run func() time.Duration) *Fl
 	return _c
 }
 
+// MetricsEndpointAddress provides a mock function for the type Flags
+func (_mock *Flags) MetricsEndpointAddress() string {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for MetricsEndpointAddress")
+	}
+
+	var r0 string
+	if returnFunc, ok := ret.Get(0).(func() string); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	return r0
+}
+
+// Flags_MetricsEndpointAddress_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MetricsEndpointAddress'
+type Flags_MetricsEndpointAddress_Call struct {
+	*mock.Call
+}
+
+// MetricsEndpointAddress is a helper method to define mock.On call
+func (_e *Flags_Expecter) MetricsEndpointAddress() *Flags_MetricsEndpointAddress_Call {
+	return &Flags_MetricsEndpointAddress_Call{Call: _e.mock.On("MetricsEndpointAddress")}
+}
+
+func (_c *Flags_MetricsEndpointAddress_Call) Run(run func()) *Flags_MetricsEndpointAddress_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Flags_MetricsEndpointAddress_Call) Return(_a string) *Flags_MetricsEndpointAddress_Call {
+	_c.Call.Return(_a)
+	return _c
+}
+
+func (_c *Flags_MetricsEndpointAddress_Call) RunAndReturn(run func() string) *Flags_MetricsEndpointAddress_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // MirrorServerURL provides a mock function for the type Flags
 func (_mock *Flags) MirrorServerURL() string {
 	ret := _mock.Called()
@@ -2569,39 +3317,243 @@ func (_c *Flags_SetAllowOverlyBroadDt4aAcceleration_Call) RunAndReturn(run func(
 	return _c
 }
 
-// SetAutoupdate provides a mock function for the type Flags
-func (_mock *Flags) SetAutoupdate(enabled bool) error {
-	ret := _mock.Called(enabled)
+// SetAllowedCommandsAllowlist provides a mock function for the type Flags
+func (_mock *Flags) SetAllowedCommandsAllowlist(allowlist string) error {
+	ret := _mock.Called(allowlist)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetAllowedCommandsAllowlist")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(string) error); ok {
+		r0 = returnFunc(allowlist)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// Flags_SetAllowedCommandsAllowlist_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetAllowedCommandsAllowlist'
+type Flags_SetAllowedCommandsAllowlist_Call struct {
+	*mock.Call
+}
+
+// SetAllowedCommandsAllowlist is a helper method to define mock.On call
+//   - allowlist string
+func (_e *Flags_Expecter) SetAllowedCommandsAllowlist(allowlist interface{}) *Flags_SetAllowedCommandsAllowlist_Call {
+	return &Flags_SetAllowedCommandsAllowlist_Call{Call: _e.mock.On("SetAllowedCommandsAllowlist", allowlist)}
+}
+
+func (_c *Flags_SetAllowedCommandsAllowlist_Call) Run(run func(allowlist string)) *Flags_SetAllowedCommandsAllowlist_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *Flags_SetAllowedCommandsAllowlist_Call) Return(r0 error) *Flags_SetAllowedCommandsAllowlist_Call {
+	_c.Call.Return(r0)
+	return _c
+}
+
+func (_c *Flags_SetAllowedCommandsAllowlist_Call) RunAndReturn(run func(allowlist string) error) *Flags_SetAllowedCommandsAllowlist_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetAllowedCommandsDenylist provides a mock function for the type Flags
+func (_mock *Flags) SetAllowedCommandsDenylist(denylist string) error {
+	ret := _mock.Called(denylist)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetAllowedCommandsDenylist")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(string) error); ok {
+		r0 = returnFunc(denylist)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// Flags_SetAllowedCommandsDenylist_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetAllowedCommandsDenylist'
+type Flags_SetAllowedCommandsDenylist_Call struct {
+	*mock.Call
+}
+
+// SetAllowedCommandsDenylist is a helper method to define mock.On call
+//   - denylist string
+func (_e *Flags_Expecter) SetAllowedCommandsDenylist(denylist interface{}) *Flags_SetAllowedCommandsDenylist_Call {
+	return &Flags_SetAllowedCommandsDenylist_Call{Call: _e.mock.On("SetAllowedCommandsDenylist", denylist)}
+}
+
+func (_c *Flags_SetAllowedCommandsDenylist_Call) Run(run func(denylist string)) *Flags_SetAllowedCommandsDenylist_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *Flags_SetAllowedCommandsDenylist_Call) Return(r0 error) *Flags_SetAllowedCommandsDenylist_Call {
+	_c.Call.Return(r0)
+	return _c
+}
+
+func (_c *Flags_SetAllowedCommandsDenylist_Call) RunAndReturn(run func(denylist string) error) *Flags_SetAllowedCommandsDenylist_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetAllowedCommandsVerifyIntegrity provides a mock function for the type Flags
+func (_mock *Flags) SetAllowedCommandsVerifyIntegrity(enabled bool) error {
+	ret := _mock.Called(enabled)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetAllowedCommandsVerifyIntegrity")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(bool) error); ok {
+		r0 = returnFunc(enabled)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// Flags_SetAllowedCommandsVerifyIntegrity_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetAllowedCommandsVerifyIntegrity'
+type Flags_SetAllowedCommandsVerifyIntegrity_Call struct {
+	*mock.Call
+}
+
+// SetAllowedCommandsVerifyIntegrity is a helper method to define mock.On call
+//   - enabled bool
+func (_e *Flags_Expecter) SetAllowedCommandsVerifyIntegrity(enabled interface{}) *Flags_SetAllowedCommandsVerifyIntegrity_Call {
+	return &Flags_SetAllowedCommandsVerifyIntegrity_Call{Call: _e.mock.On("SetAllowedCommandsVerifyIntegrity", enabled)}
+}
+
+func (_c *Flags_SetAllowedCommandsVerifyIntegrity_Call) Run(run func(enabled bool)) *Flags_SetAllowedCommandsVerifyIntegrity_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 bool
+		if args[0] != nil {
+			arg0 = args[0].(bool)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *Flags_SetAllowedCommandsVerifyIntegrity_Call) Return(r0 error) *Flags_SetAllowedCommandsVerifyIntegrity_Call {
+	_c.Call.Return(r0)
+	return _c
+}
+
+func (_c *Flags_SetAllowedCommandsVerifyIntegrity_Call) RunAndReturn(run func(enabled bool) error) *Flags_SetAllowedCommandsVerifyIntegrity_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetAutoupdate provides a mock function for the type Flags
+func (_mock *Flags) SetAutoupdate(enabled bool) error {
+	ret := _mock.Called(enabled)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetAutoupdate")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(bool) error); ok {
+		r0 = returnFunc(enabled)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// Flags_SetAutoupdate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetAutoupdate'
+type Flags_SetAutoupdate_Call struct {
+	*mock.Call
+}
+
+// SetAutoupdate is a helper method to define mock.On call
+//   - enabled bool
+func (_e *Flags_Expecter) SetAutoupdate(enabled interface{}) *Flags_SetAutoupdate_Call {
+	return &Flags_SetAutoupdate_Call{Call: _e.mock.On("SetAutoupdate", enabled)}
+}
+
+func (_c *Flags_SetAutoupdate_Call) Run(run func(enabled bool)) *Flags_SetAutoupdate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 bool
+		if args[0] != nil {
+			arg0 = args[0].(bool)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *Flags_SetAutoupdate_Call) Return(err error) *Flags_SetAutoupdate_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *Flags_SetAutoupdate_Call) RunAndReturn(run func(enabled bool) error) *Flags_SetAutoupdate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetAutoupdateBandwidthLimitKB provides a mock function for the type Flags
+func (_mock *Flags) SetAutoupdateBandwidthLimitKB(limit int) error {
+	ret := _mock.Called(limit)
 
 	if len(ret) == 0 {
-		panic("no return value specified for SetAutoupdate")
+		panic("no return value specified for SetAutoupdateBandwidthLimitKB")
 	}
 
 	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(bool) error); ok {
-		r0 = returnFunc(enabled)
+	if returnFunc, ok := ret.Get(0).(func(int) error); ok {
+		r0 = returnFunc(limit)
 	} else {
 		r0 = ret.Error(0)
 	}
 	return r0
 }
 
-// Flags_SetAutoupdate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetAutoupdate'
-type Flags_SetAutoupdate_Call struct {
+// Flags_SetAutoupdateBandwidthLimitKB_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetAutoupdateBandwidthLimitKB'
+type Flags_SetAutoupdateBandwidthLimitKB_Call struct {
 	*mock.Call
 }
 
-// SetAutoupdate is a helper method to define mock.On call
-//   - enabled bool
-func (_e *Flags_Expecter) SetAutoupdate(enabled interface{}) *Flags_SetAutoupdate_Call {
-	return &Flags_SetAutoupdate_Call{Call: _e.mock.On("SetAutoupdate", enabled)}
+// SetAutoupdateBandwidthLimitKB is a helper method to define mock.On call
+//   - limit int
+func (_e *Flags_Expecter) SetAutoupdateBandwidthLimitKB(limit interface{}) *Flags_SetAutoupdateBandwidthLimitKB_Call {
+	return &Flags_SetAutoupdateBandwidthLimitKB_Call{Call: _e.mock.On("SetAutoupdateBandwidthLimitKB", limit)}
 }
 
-func (_c *Flags_SetAutoupdate_Call) Run(run func(enabled bool)) *Flags_SetAutoupdate_Call {
+func (_c *Flags_SetAutoupdateBandwidthLimitKB_Call) Run(run func(limit int)) *Flags_SetAutoupdateBandwidthLimitKB_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		var arg0 bool
+		var arg0 int
 		if args[0] != nil {
-			arg0 = args[0].(bool)
+			arg0 = args[0].(int)
 		}
 		run(
 			arg0,
@@ -2610,12 +3562,12 @@ func (_c *Flags_SetAutoupdate_Call) Run(run func(enabled bool)) *Flags_SetAutoup
 	return _c
 }
 
-func (_c *Flags_SetAutoupdate_Call) Return(err error) *Flags_SetAutoupdate_Call {
+func (_c *Flags_SetAutoupdateBandwidthLimitKB_Call) Return(err error) *Flags_SetAutoupdateBandwidthLimitKB_Call {
 	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *Flags_SetAutoupdate_Call) RunAndReturn(run func(enabled bool) error) *Flags_SetAutoupdate_Call {
+func (_c *Flags_SetAutoupdateBandwidthLimitKB_Call) RunAndReturn(run func(limit int) error) *Flags_SetAutoupdateBandwidthLimitKB_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -2921,34 +3873,284 @@ func (_mock *Flags) SetControlRequestInterval(interval time.Duration) error {
 	ret := _mock.Called(interval)
 
 	if len(ret) == 0 {
-		panic("no return value specified for SetControlRequestInterval")
+		panic("no return value specified for SetControlRequestInterval")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(time.Duration) error); ok {
+		r0 = returnFunc(interval)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// Flags_SetControlRequestInterval_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetControlRequestInterval'
+type Flags_SetControlRequestInterval_Call struct {
+	*mock.Call
+}
+
+// SetControlRequestInterval is a helper method to define mock.On call
+//   - interval time.Duration
+func (_e *Flags_Expecter) SetControlRequestInterval(interval interface{}) *Flags_SetControlRequestInterval_Call {
+	return &Flags_SetControlRequestInterval_Call{Call: _e.mock.On("SetControlRequestInterval", interval)}
+}
+
+func (_c *Flags_SetControlRequestInterval_Call) Run(run func(interval time.Duration)) *Flags_SetControlRequestInterval_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 time.Duration
+		if args[0] != nil {
+			arg0 = args[0].(time.Duration)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *Flags_SetControlRequestInterval_Call) Return(err error) *Flags_SetControlRequestInterval_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *Flags_SetControlRequestInterval_Call) RunAndReturn(run func(interval time.Duration) error) *Flags_SetControlRequestInterval_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetControlRequestIntervalOverride provides a mock function for the type Flags
+func (_mock *Flags) SetControlRequestIntervalOverride(value time.Duration, duration time.Duration) {
+	_mock.Called(value, duration)
+	return
+}
+
+// Flags_SetControlRequestIntervalOverride_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetControlRequestIntervalOverride'
+type Flags_SetControlRequestIntervalOverride_Call struct {
+	*mock.Call
+}
+
+// SetControlRequestIntervalOverride is a helper method to define mock.On call
+//   - value time.Duration
+//   - duration time.Duration
+func (_e *Flags_Expecter) SetControlRequestIntervalOverride(value interface{}, duration interface{}) *Flags_SetControlRequestIntervalOverride_Call {
+	return &Flags_SetControlRequestIntervalOverride_Call{Call: _e.mock.On("SetControlRequestIntervalOverride", value, duration)}
+}
+
+func (_c *Flags_SetControlRequestIntervalOverride_Call) Run(run func(value time.Duration, duration time.Duration)) *Flags_SetControlRequestIntervalOverride_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 time.Duration
+		if args[0] != nil {
+			arg0 = args[0].(time.Duration)
+		}
+		var arg1 time.Duration
+		if args[1] != nil {
+			arg1 = args[1].(time.Duration)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *Flags_SetControlRequestIntervalOverride_Call) Return() *Flags_SetControlRequestIntervalOverride_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *Flags_SetControlRequestIntervalOverride_Call) RunAndReturn(run func(value time.Duration, duration time.Duration)) *Flags_SetControlRequestIntervalOverride_Call {
+	_c.Run(run)
+	return _c
+}
+
+// SetControlServerURL provides a mock function for the type Flags
+func (_mock *Flags) SetControlServerURL(url string) error {
+	ret := _mock.Called(url)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetControlServerURL")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(string) error); ok {
+		r0 = returnFunc(url)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// Flags_SetControlServerURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetControlServerURL'
+type Flags_SetControlServerURL_Call struct {
+	*mock.Call
+}
+
+// SetControlServerURL is a helper method to define mock.On call
+//   - url string
+func (_e *Flags_Expecter) SetControlServerURL(url interface{}) *Flags_SetControlServerURL_Call {
+	return &Flags_SetControlServerURL_Call{Call: _e.mock.On("SetControlServerURL", url)}
+}
+
+func (_c *Flags_SetControlServerURL_Call) Run(run func(url string)) *Flags_SetControlServerURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *Flags_SetControlServerURL_Call) Return(err error) *Flags_SetControlServerURL_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *Flags_SetControlServerURL_Call) RunAndReturn(run func(url string) error) *Flags_SetControlServerURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetCurrentRunningOsqueryVersion provides a mock function for the type Flags
+func (_mock *Flags) SetCurrentRunningOsqueryVersion(version string) error {
+	ret := _mock.Called(version)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetCurrentRunningOsqueryVersion")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(string) error); ok {
+		r0 = returnFunc(version)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// Flags_SetCurrentRunningOsqueryVersion_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetCurrentRunningOsqueryVersion'
+type Flags_SetCurrentRunningOsqueryVersion_Call struct {
+	*mock.Call
+}
+
+// SetCurrentRunningOsqueryVersion is a helper method to define mock.On call
+//   - version string
+func (_e *Flags_Expecter) SetCurrentRunningOsqueryVersion(version interface{}) *Flags_SetCurrentRunningOsqueryVersion_Call {
+	return &Flags_SetCurrentRunningOsqueryVersion_Call{Call: _e.mock.On("SetCurrentRunningOsqueryVersion", version)}
+}
+
+func (_c *Flags_SetCurrentRunningOsqueryVersion_Call) Run(run func(version string)) *Flags_SetCurrentRunningOsqueryVersion_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *Flags_SetCurrentRunningOsqueryVersion_Call) Return(err error) *Flags_SetCurrentRunningOsqueryVersion_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *Flags_SetCurrentRunningOsqueryVersion_Call) RunAndReturn(run func(version string) error) *Flags_SetCurrentRunningOsqueryVersion_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetCustomerOTLPCAPath provides a mock function for the type Flags
+func (_mock *Flags) SetCustomerOTLPCAPath(path string) error {
+	ret := _mock.Called(path)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetCustomerOTLPCAPath")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(string) error); ok {
+		r0 = returnFunc(path)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// Flags_SetCustomerOTLPCAPath_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetCustomerOTLPCAPath'
+type Flags_SetCustomerOTLPCAPath_Call struct {
+	*mock.Call
+}
+
+// SetCustomerOTLPCAPath is a helper method to define mock.On call
+//   - path string
+func (_e *Flags_Expecter) SetCustomerOTLPCAPath(path interface{}) *Flags_SetCustomerOTLPCAPath_Call {
+	return &Flags_SetCustomerOTLPCAPath_Call{Call: _e.mock.On("SetCustomerOTLPCAPath", path)}
+}
+
+func (_c *Flags_SetCustomerOTLPCAPath_Call) Run(run func(path string)) *Flags_SetCustomerOTLPCAPath_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *Flags_SetCustomerOTLPCAPath_Call) Return(_a error) *Flags_SetCustomerOTLPCAPath_Call {
+	_c.Call.Return(_a)
+	return _c
+}
+
+func (_c *Flags_SetCustomerOTLPCAPath_Call) RunAndReturn(run func(path string) error) *Flags_SetCustomerOTLPCAPath_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetCustomerOTLPEndpoint provides a mock function for the type Flags
+func (_mock *Flags) SetCustomerOTLPEndpoint(endpoint string) error {
+	ret := _mock.Called(endpoint)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetCustomerOTLPEndpoint")
 	}
 
 	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(time.Duration) error); ok {
-		r0 = returnFunc(interval)
+	if returnFunc, ok := ret.Get(0).(func(string) error); ok {
+		r0 = returnFunc(endpoint)
 	} else {
 		r0 = ret.Error(0)
 	}
 	return r0
 }
 
-// Flags_SetControlRequestInterval_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetControlRequestInterval'
-type Flags_SetControlRequestInterval_Call struct {
+// Flags_SetCustomerOTLPEndpoint_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetCustomerOTLPEndpoint'
+type Flags_SetCustomerOTLPEndpoint_Call struct {
 	*mock.Call
 }
 
-// SetControlRequestInterval is a helper method to define mock.On call
-//   - interval time.Duration
-func (_e *Flags_Expecter) SetControlRequestInterval(interval interface{}) *Flags_SetControlRequestInterval_Call {
-	return &Flags_SetControlRequestInterval_Call{Call: _e.mock.On("SetControlRequestInterval", interval)}
+// SetCustomerOTLPEndpoint is a helper method to define mock.On call
+//   - endpoint string
+func (_e *Flags_Expecter) SetCustomerOTLPEndpoint(endpoint interface{}) *Flags_SetCustomerOTLPEndpoint_Call {
+	return &Flags_SetCustomerOTLPEndpoint_Call{Call: _e.mock.On("SetCustomerOTLPEndpoint", endpoint)}
 }
 
-func (_c *Flags_SetControlRequestInterval_Call) Run(run func(interval time.Duration)) *Flags_SetControlRequestInterval_Call {
+func (_c *Flags_SetCustomerOTLPEndpoint_Call) Run(run func(endpoint string)) *Flags_SetCustomerOTLPEndpoint_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		var arg0 time.Duration
+		var arg0 string
 		if args[0] != nil {
-			arg0 = args[0].(time.Duration)
+			arg0 = args[0].(string)
 		}
 		run(
 			arg0,
@@ -2957,95 +4159,100 @@ func (_c *Flags_SetControlRequestInterval_Call) Run(run func(interval time.Durat
 	return _c
 }
 
-func (_c *Flags_SetControlRequestInterval_Call) Return(err error) *Flags_SetControlRequestInterval_Call {
-	_c.Call.Return(err)
+func (_c *Flags_SetCustomerOTLPEndpoint_Call) Return(_a error) *Flags_SetCustomerOTLPEndpoint_Call {
+	_c.Call.Return(_a)
 	return _c
 }
 
-func (_c *Flags_SetControlRequestInterval_Call) RunAndReturn(run func(interval time.Duration) error) *Flags_SetControlRequestInterval_Call {
+func (_c *Flags_SetCustomerOTLPEndpoint_Call) RunAndReturn(run func(endpoint string) error) *Flags_SetCustomerOTLPEndpoint_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// SetControlRequestIntervalOverride provides a mock function for the type Flags
-func (_mock *Flags) SetControlRequestIntervalOverride(value time.Duration, duration time.Duration) {
-	_mock.Called(value, duration)
-	return
+// SetCustomerOTLPHeaders provides a mock function for the type Flags
+func (_mock *Flags) SetCustomerOTLPHeaders(headers string) error {
+	ret := _mock.Called(headers)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetCustomerOTLPHeaders")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(string) error); ok {
+		r0 = returnFunc(headers)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
 }
 
-// Flags_SetControlRequestIntervalOverride_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetControlRequestIntervalOverride'
-type Flags_SetControlRequestIntervalOverride_Call struct {
+// Flags_SetCustomerOTLPHeaders_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetCustomerOTLPHeaders'
+type Flags_SetCustomerOTLPHeaders_Call struct {
 	*mock.Call
 }
 
-// SetControlRequestIntervalOverride is a helper method to define mock.On call
-//   - value time.Duration
-//   - duration time.Duration
-func (_e *Flags_Expecter) SetControlRequestIntervalOverride(value interface{}, duration interface{}) *Flags_SetControlRequestIntervalOverride_Call {
-	return &Flags_SetControlRequestIntervalOverride_Call{Call: _e.mock.On("SetControlRequestIntervalOverride", value, duration)}
+// SetCustomerOTLPHeaders is a helper method to define mock.On call
+//   - headers string
+func (_e *Flags_Expecter) SetCustomerOTLPHeaders(headers interface{}) *Flags_SetCustomerOTLPHeaders_Call {
+	return &Flags_SetCustomerOTLPHeaders_Call{Call: _e.mock.On("SetCustomerOTLPHeaders", headers)}
 }
 
-func (_c *Flags_SetControlRequestIntervalOverride_Call) Run(run func(value time.Duration, duration time.Duration)) *Flags_SetControlRequestIntervalOverride_Call {
+func (_c *Flags_SetCustomerOTLPHeaders_Call) Run(run func(headers string)) *Flags_SetCustomerOTLPHeaders_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		var arg0 time.Duration
+		var arg0 string
 		if args[0] != nil {
-			arg0 = args[0].(time.Duration)
-		}
-		var arg1 time.Duration
-		if args[1] != nil {
-			arg1 = args[1].(time.Duration)
+			arg0 = args[0].(string)
 		}
 		run(
 			arg0,
-			arg1,
 		)
 	})
 	return _c
 }
 
-func (_c *Flags_SetControlRequestIntervalOverride_Call) Return() *Flags_SetControlRequestIntervalOverride_Call {
-	_c.Call.Return()
+func (_c *Flags_SetCustomerOTLPHeaders_Call) Return(_a error) *Flags_SetCustomerOTLPHeaders_Call {
+	_c.Call.Return(_a)
 	return _c
 }
 
-func (_c *Flags_SetControlRequestIntervalOverride_Call) RunAndReturn(run func(value time.Duration, duration time.Duration)) *Flags_SetControlRequestIntervalOverride_Call {
-	_c.Run(run)
+func (_c *Flags_SetCustomerOTLPHeaders_Call) RunAndReturn(run func(headers string) error) *Flags_SetCustomerOTLPHeaders_Call {
+	_c.Call.Return(run)
 	return _c
 }
 
-// SetControlServerURL provides a mock function for the type Flags
-func (_mock *Flags) SetControlServerURL(url string) error {
-	ret := _mock.Called(url)
+// SetCustomerOTLPOnly provides a mock function for the type Flags
+func (_mock *Flags) SetCustomerOTLPOnly(only bool) error {
+	ret := _mock.Called(only)
 
 	if len(ret) == 0 {
-		panic("no return value specified for SetControlServerURL")
+		panic("no return value specified for SetCustomerOTLPOnly")
 	}
 
 	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(string) error); ok {
-		r0 = returnFunc(url)
+	if returnFunc, ok := ret.Get(0).(func(bool) error); ok {
+		r0 = returnFunc(only)
 	} else {
 		r0 = ret.Error(0)
 	}
 	return r0
 }
 
-// Flags_SetControlServerURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetControlServerURL'
-type Flags_SetControlServerURL_Call struct {
+// Flags_SetCustomerOTLPOnly_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetCustomerOTLPOnly'
+type Flags_SetCustomerOTLPOnly_Call struct {
 	*mock.Call
 }
 
-// SetControlServerURL is a helper method to define mock.On call
-//   - url string
-func (_e *Flags_Expecter) SetControlServerURL(url interface{}) *Flags_SetControlServerURL_Call {
-	return &Flags_SetControlServerURL_Call{Call: _e.mock.On("SetControlServerURL", url)}
+// SetCustomerOTLPOnly is a helper method to define mock.On call
+//   - only bool
+func (_e *Flags_Expecter) SetCustomerOTLPOnly(only interface{}) *Flags_SetCustomerOTLPOnly_Call {
+	return &Flags_SetCustomerOTLPOnly_Call{Call: _e.mock.On("SetCustomerOTLPOnly", only)}
 }
 
-func (_c *Flags_SetControlServerURL_Call) Run(run func(url string)) *Flags_SetControlServerURL_Call {
+func (_c *Flags_SetCustomerOTLPOnly_Call) Run(run func(only bool)) *Flags_SetCustomerOTLPOnly_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		var arg0 string
+		var arg0 bool
 		if args[0] != nil {
-			arg0 = args[0].(string)
+			arg0 = args[0].(bool)
 		}
 		run(
 			arg0,
@@ -3054,49 +4261,49 @@ func (_c *Flags_SetControlServerURL_Call) Run(run func(url string)) *Flags_SetCo
 	return _c
 }
 
-func (_c *Flags_SetControlServerURL_Call) Return(err error) *Flags_SetControlServerURL_Call {
-	_c.Call.Return(err)
+func (_c *Flags_SetCustomerOTLPOnly_Call) Return(_a error) *Flags_SetCustomerOTLPOnly_Call {
+	_c.Call.Return(_a)
 	return _c
 }
 
-func (_c *Flags_SetControlServerURL_Call) RunAndReturn(run func(url string) error) *Flags_SetControlServerURL_Call {
+func (_c *Flags_SetCustomerOTLPOnly_Call) RunAndReturn(run func(only bool) error) *Flags_SetCustomerOTLPOnly_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// SetCurrentRunningOsqueryVersion provides a mock function for the type Flags
-func (_mock *Flags) SetCurrentRunningOsqueryVersion(version string) error {
-	ret := _mock.Called(version)
+// SetCustomerOTLPSamplingRate provides a mock function for the type Flags
+func (_mock *Flags) SetCustomerOTLPSamplingRate(rate float64) error {
+	ret := _mock.Called(rate)
 
 	if len(ret) == 0 {
-		panic("no return value specified for SetCurrentRunningOsqueryVersion")
+		panic("no return value specified for SetCustomerOTLPSamplingRate")
 	}
 
 	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(string) error); ok {
-		r0 = returnFunc(version)
+	if returnFunc, ok := ret.Get(0).(func(float64) error); ok {
+		r0 = returnFunc(rate)
 	} else {
 		r0 = ret.Error(0)
 	}
 	return r0
 }
 
-// Flags_SetCurrentRunningOsqueryVersion_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetCurrentRunningOsqueryVersion'
-type Flags_SetCurrentRunningOsqueryVersion_Call struct {
+// Flags_SetCustomerOTLPSamplingRate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetCustomerOTLPSamplingRate'
+type Flags_SetCustomerOTLPSamplingRate_Call struct {
 	*mock.Call
 }
 
-// SetCurrentRunningOsqueryVersion is a helper method to define mock.On call
-//   - version string
-func (_e *Flags_Expecter) SetCurrentRunningOsqueryVersion(version interface{}) *Flags_SetCurrentRunningOsqueryVersion_Call {
-	return &Flags_SetCurrentRunningOsqueryVersion_Call{Call: _e.mock.On("SetCurrentRunningOsqueryVersion", version)}
+// SetCustomerOTLPSamplingRate is a helper method to define mock.On call
+//   - rate float64
+func (_e *Flags_Expecter) SetCustomerOTLPSamplingRate(rate interface{}) *Flags_SetCustomerOTLPSamplingRate_Call {
+	return &Flags_SetCustomerOTLPSamplingRate_Call{Call: _e.mock.On("SetCustomerOTLPSamplingRate", rate)}
 }
 
-func (_c *Flags_SetCurrentRunningOsqueryVersion_Call) Run(run func(version string)) *Flags_SetCurrentRunningOsqueryVersion_Call {
+func (_c *Flags_SetCustomerOTLPSamplingRate_Call) Run(run func(rate float64)) *Flags_SetCustomerOTLPSamplingRate_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		var arg0 string
+		var arg0 float64
 		if args[0] != nil {
-			arg0 = args[0].(string)
+			arg0 = args[0].(float64)
 		}
 		run(
 			arg0,
@@ -3105,12 +4312,12 @@ func (_c *Flags_SetCurrentRunningOsqueryVersion_Call) Run(run func(version strin
 	return _c
 }
 
-func (_c *Flags_SetCurrentRunningOsqueryVersion_Call) Return(err error) *Flags_SetCurrentRunningOsqueryVersion_Call {
-	_c.Call.Return(err)
+func (_c *Flags_SetCustomerOTLPSamplingRate_Call) Return(_a error) *Flags_SetCustomerOTLPSamplingRate_Call {
+	_c.Call.Return(_a)
 	return _c
 }
 
-func (_c *Flags_SetCurrentRunningOsqueryVersion_Call) RunAndReturn(run func(version string) error) *Flags_SetCurrentRunningOsqueryVersion_Call {
+func (_c *Flags_SetCustomerOTLPSamplingRate_Call) RunAndReturn(run func(rate float64) error) *Flags_SetCustomerOTLPSamplingRate_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -3472,6 +4679,57 @@ func (_c *Flags_SetDisableControlTLS_Call) RunAndReturn(run func(disabled bool)
 	return _c
 }
 
+// SetDisableCustomerOTLPTLS provides a mock function for the type Flags
+func (_mock *Flags) SetDisableCustomerOTLPTLS(disabled bool) error {
+	ret := _mock.Called(disabled)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetDisableCustomerOTLPTLS")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(bool) error); ok {
+		r0 = returnFunc(disabled)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// Flags_SetDisableCustomerOTLPTLS_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetDisableCustomerOTLPTLS'
+type Flags_SetDisableCustomerOTLPTLS_Call struct {
+	*mock.Call
+}
+
+// SetDisableCustomerOTLPTLS is a helper method to define mock.On call
+//   - disabled bool
+func (_e *Flags_Expecter) SetDisableCustomerOTLPTLS(disabled interface{}) *Flags_SetDisableCustomerOTLPTLS_Call {
+	return &Flags_SetDisableCustomerOTLPTLS_Call{Call: _e.mock.On("SetDisableCustomerOTLPTLS", disabled)}
+}
+
+func (_c *Flags_SetDisableCustomerOTLPTLS_Call) Run(run func(disabled bool)) *Flags_SetDisableCustomerOTLPTLS_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 bool
+		if args[0] != nil {
+			arg0 = args[0].(bool)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *Flags_SetDisableCustomerOTLPTLS_Call) Return(_a error) *Flags_SetDisableCustomerOTLPTLS_Call {
+	_c.Call.Return(_a)
+	return _c
+}
+
+func (_c *Flags_SetDisableCustomerOTLPTLS_Call) RunAndReturn(run func(disabled bool) error) *Flags_SetDisableCustomerOTLPTLS_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // SetDisableTraceIngestTLS provides a mock function for the type Flags
 func (_mock *Flags) SetDisableTraceIngestTLS(enabled bool) error {
 	ret := _mock.Called(enabled)
@@ -3671,6 +4929,57 @@ func (_c *Flags_SetDuplicateLogWindow_Call) RunAndReturn(run func(duration time.
 	return _c
 }
 
+// SetDuplicateLogWindowOverrides provides a mock function for the type Flags
+func (_mock *Flags) SetDuplicateLogWindowOverrides(overrides string) error {
+	ret := _mock.Called(overrides)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetDuplicateLogWindowOverrides")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(string) error); ok {
+		r0 = returnFunc(overrides)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// Flags_SetDuplicateLogWindowOverrides_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetDuplicateLogWindowOverrides'
+type Flags_SetDuplicateLogWindowOverrides_Call struct {
+	*mock.Call
+}
+
+// SetDuplicateLogWindowOverrides is a helper method to define mock.On call
+//   - overrides string
+func (_e *Flags_Expecter) SetDuplicateLogWindowOverrides(overrides interface{}) *Flags_SetDuplicateLogWindowOverrides_Call {
+	return &Flags_SetDuplicateLogWindowOverrides_Call{Call: _e.mock.On("SetDuplicateLogWindowOverrides", overrides)}
+}
+
+func (_c *Flags_SetDuplicateLogWindowOverrides_Call) Run(run func(overrides string)) *Flags_SetDuplicateLogWindowOverrides_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *Flags_SetDuplicateLogWindowOverrides_Call) Return(r0 error) *Flags_SetDuplicateLogWindowOverrides_Call {
+	_c.Call.Return(r0)
+	return _c
+}
+
+func (_c *Flags_SetDuplicateLogWindowOverrides_Call) RunAndReturn(run func(overrides string) error) *Flags_SetDuplicateLogWindowOverrides_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // SetExportTraces provides a mock function for the type Flags
 func (_mock *Flags) SetExportTraces(enabled bool) error {
 	ret := _mock.Called(enabled)
@@ -3717,54 +5026,156 @@ func (_c *Flags_SetExportTraces_Call) Return(err error) *Flags_SetExportTraces_C
 	return _c
 }
 
-func (_c *Flags_SetExportTraces_Call) RunAndReturn(run func(enabled bool) error) *Flags_SetExportTraces_Call {
+func (_c *Flags_SetExportTraces_Call) RunAndReturn(run func(enabled bool) error) *Flags_SetExportTraces_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetExportTracesOverride provides a mock function for the type Flags
+func (_mock *Flags) SetExportTracesOverride(value bool, duration time.Duration) {
+	_mock.Called(value, duration)
+	return
+}
+
+// Flags_SetExportTracesOverride_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetExportTracesOverride'
+type Flags_SetExportTracesOverride_Call struct {
+	*mock.Call
+}
+
+// SetExportTracesOverride is a helper method to define mock.On call
+//   - value bool
+//   - duration time.Duration
+func (_e *Flags_Expecter) SetExportTracesOverride(value interface{}, duration interface{}) *Flags_SetExportTracesOverride_Call {
+	return &Flags_SetExportTracesOverride_Call{Call: _e.mock.On("SetExportTracesOverride", value, duration)}
+}
+
+func (_c *Flags_SetExportTracesOverride_Call) Run(run func(value bool, duration time.Duration)) *Flags_SetExportTracesOverride_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 bool
+		if args[0] != nil {
+			arg0 = args[0].(bool)
+		}
+		var arg1 time.Duration
+		if args[1] != nil {
+			arg1 = args[1].(time.Duration)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *Flags_SetExportTracesOverride_Call) Return() *Flags_SetExportTracesOverride_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *Flags_SetExportTracesOverride_Call) RunAndReturn(run func(value bool, duration time.Duration)) *Flags_SetExportTracesOverride_Call {
+	_c.Run(run)
+	return _c
+}
+
+// SetExternalCheckups provides a mock function for the type Flags
+func (_mock *Flags) SetExternalCheckups(definitions string) error {
+	ret := _mock.Called(definitions)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetExternalCheckups")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(string) error); ok {
+		r0 = returnFunc(definitions)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// Flags_SetExternalCheckups_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetExternalCheckups'
+type Flags_SetExternalCheckups_Call struct {
+	*mock.Call
+}
+
+// SetExternalCheckups is a helper method to define mock.On call
+//   - definitions string
+func (_e *Flags_Expecter) SetExternalCheckups(definitions interface{}) *Flags_SetExternalCheckups_Call {
+	return &Flags_SetExternalCheckups_Call{Call: _e.mock.On("SetExternalCheckups", definitions)}
+}
+
+func (_c *Flags_SetExternalCheckups_Call) Run(run func(definitions string)) *Flags_SetExternalCheckups_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *Flags_SetExternalCheckups_Call) Return(_a error) *Flags_SetExternalCheckups_Call {
+	_c.Call.Return(_a)
+	return _c
+}
+
+func (_c *Flags_SetExternalCheckups_Call) RunAndReturn(run func(definitions string) error) *Flags_SetExternalCheckups_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// SetExportTracesOverride provides a mock function for the type Flags
-func (_mock *Flags) SetExportTracesOverride(value bool, duration time.Duration) {
-	_mock.Called(value, duration)
-	return
+// SetFlareRedactionRules provides a mock function for the type Flags
+func (_mock *Flags) SetFlareRedactionRules(rules string) error {
+	ret := _mock.Called(rules)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetFlareRedactionRules")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(string) error); ok {
+		r0 = returnFunc(rules)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
 }
 
-// Flags_SetExportTracesOverride_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetExportTracesOverride'
-type Flags_SetExportTracesOverride_Call struct {
+// Flags_SetFlareRedactionRules_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetFlareRedactionRules'
+type Flags_SetFlareRedactionRules_Call struct {
 	*mock.Call
 }
 
-// SetExportTracesOverride is a helper method to define mock.On call
-//   - value bool
-//   - duration time.Duration
-func (_e *Flags_Expecter) SetExportTracesOverride(value interface{}, duration interface{}) *Flags_SetExportTracesOverride_Call {
-	return &Flags_SetExportTracesOverride_Call{Call: _e.mock.On("SetExportTracesOverride", value, duration)}
+// SetFlareRedactionRules is a helper method to define mock.On call
+//   - rules string
+func (_e *Flags_Expecter) SetFlareRedactionRules(rules interface{}) *Flags_SetFlareRedactionRules_Call {
+	return &Flags_SetFlareRedactionRules_Call{Call: _e.mock.On("SetFlareRedactionRules", rules)}
 }
 
-func (_c *Flags_SetExportTracesOverride_Call) Run(run func(value bool, duration time.Duration)) *Flags_SetExportTracesOverride_Call {
+func (_c *Flags_SetFlareRedactionRules_Call) Run(run func(rules string)) *Flags_SetFlareRedactionRules_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		var arg0 bool
+		var arg0 string
 		if args[0] != nil {
-			arg0 = args[0].(bool)
-		}
-		var arg1 time.Duration
-		if args[1] != nil {
-			arg1 = args[1].(time.Duration)
+			arg0 = args[0].(string)
 		}
 		run(
 			arg0,
-			arg1,
 		)
 	})
 	return _c
 }
 
-func (_c *Flags_SetExportTracesOverride_Call) Return() *Flags_SetExportTracesOverride_Call {
-	_c.Call.Return()
+func (_c *Flags_SetFlareRedactionRules_Call) Return(_a error) *Flags_SetFlareRedactionRules_Call {
+	_c.Call.Return(_a)
 	return _c
 }
 
-func (_c *Flags_SetExportTracesOverride_Call) RunAndReturn(run func(value bool, duration time.Duration)) *Flags_SetExportTracesOverride_Call {
-	_c.Run(run)
+func (_c *Flags_SetFlareRedactionRules_Call) RunAndReturn(run func(rules string) error) *Flags_SetFlareRedactionRules_Call {
+	_c.Call.Return(run)
 	return _c
 }
 
@@ -3819,6 +5230,57 @@ func (_c *Flags_SetForceControlSubsystems_Call) RunAndReturn(run func(force bool
 	return _c
 }
 
+// SetHardwareKeyRotationInterval provides a mock function for the type Flags
+func (_mock *Flags) SetHardwareKeyRotationInterval(interval time.Duration) error {
+	ret := _mock.Called(interval)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetHardwareKeyRotationInterval")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(time.Duration) error); ok {
+		r0 = returnFunc(interval)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// Flags_SetHardwareKeyRotationInterval_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetHardwareKeyRotationInterval'
+type Flags_SetHardwareKeyRotationInterval_Call struct {
+	*mock.Call
+}
+
+// SetHardwareKeyRotationInterval is a helper method to define mock.On call
+//   - interval time.Duration
+func (_e *Flags_Expecter) SetHardwareKeyRotationInterval(interval interface{}) *Flags_SetHardwareKeyRotationInterval_Call {
+	return &Flags_SetHardwareKeyRotationInterval_Call{Call: _e.mock.On("SetHardwareKeyRotationInterval", interval)}
+}
+
+func (_c *Flags_SetHardwareKeyRotationInterval_Call) Run(run func(interval time.Duration)) *Flags_SetHardwareKeyRotationInterval_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 time.Duration
+		if args[0] != nil {
+			arg0 = args[0].(time.Duration)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *Flags_SetHardwareKeyRotationInterval_Call) Return(r0 error) *Flags_SetHardwareKeyRotationInterval_Call {
+	_c.Call.Return(r0)
+	return _c
+}
+
+func (_c *Flags_SetHardwareKeyRotationInterval_Call) RunAndReturn(run func(interval time.Duration) error) *Flags_SetHardwareKeyRotationInterval_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // SetInModernStandby provides a mock function for the type Flags
 func (_mock *Flags) SetInModernStandby(enabled bool) error {
 	ret := _mock.Called(enabled)
@@ -4074,6 +5536,108 @@ func (_c *Flags_SetKolideServerURL_Call) RunAndReturn(run func(url string) error
 	return _c
 }
 
+// SetLanCacheListenAddress provides a mock function for the type Flags
+func (_mock *Flags) SetLanCacheListenAddress(address string) error {
+	ret := _mock.Called(address)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetLanCacheListenAddress")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(string) error); ok {
+		r0 = returnFunc(address)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// Flags_SetLanCacheListenAddress_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetLanCacheListenAddress'
+type Flags_SetLanCacheListenAddress_Call struct {
+	*mock.Call
+}
+
+// SetLanCacheListenAddress is a helper method to define mock.On call
+//   - address string
+func (_e *Flags_Expecter) SetLanCacheListenAddress(address interface{}) *Flags_SetLanCacheListenAddress_Call {
+	return &Flags_SetLanCacheListenAddress_Call{Call: _e.mock.On("SetLanCacheListenAddress", address)}
+}
+
+func (_c *Flags_SetLanCacheListenAddress_Call) Run(run func(address string)) *Flags_SetLanCacheListenAddress_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *Flags_SetLanCacheListenAddress_Call) Return(err error) *Flags_SetLanCacheListenAddress_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *Flags_SetLanCacheListenAddress_Call) RunAndReturn(run func(address string) error) *Flags_SetLanCacheListenAddress_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetLanCachePeers provides a mock function for the type Flags
+func (_mock *Flags) SetLanCachePeers(peers string) error {
+	ret := _mock.Called(peers)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetLanCachePeers")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(string) error); ok {
+		r0 = returnFunc(peers)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// Flags_SetLanCachePeers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetLanCachePeers'
+type Flags_SetLanCachePeers_Call struct {
+	*mock.Call
+}
+
+// SetLanCachePeers is a helper method to define mock.On call
+//   - peers string
+func (_e *Flags_Expecter) SetLanCachePeers(peers interface{}) *Flags_SetLanCachePeers_Call {
+	return &Flags_SetLanCachePeers_Call{Call: _e.mock.On("SetLanCachePeers", peers)}
+}
+
+func (_c *Flags_SetLanCachePeers_Call) Run(run func(peers string)) *Flags_SetLanCachePeers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *Flags_SetLanCachePeers_Call) Return(err error) *Flags_SetLanCachePeers_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *Flags_SetLanCachePeers_Call) RunAndReturn(run func(peers string) error) *Flags_SetLanCachePeers_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // SetLauncherGoMaxProcs provides a mock function for the type Flags
 func (_mock *Flags) SetLauncherGoMaxProcs(maxProcs int) error {
 	ret := _mock.Called(maxProcs)
@@ -4732,6 +6296,52 @@ func (_c *Flags_SetPinnedLauncherVersion_Call) RunAndReturn(run func(version str
 	return _c
 }
 
+// SetPinnedLauncherVersionOverride provides a mock function for the type Flags
+func (_mock *Flags) SetPinnedLauncherVersionOverride(version string, duration time.Duration) {
+	_mock.Called(version, duration)
+	return
+}
+
+// Flags_SetPinnedLauncherVersionOverride_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetPinnedLauncherVersionOverride'
+type Flags_SetPinnedLauncherVersionOverride_Call struct {
+	*mock.Call
+}
+
+// SetPinnedLauncherVersionOverride is a helper method to define mock.On call
+//   - version string
+//   - duration time.Duration
+func (_e *Flags_Expecter) SetPinnedLauncherVersionOverride(version interface{}, duration interface{}) *Flags_SetPinnedLauncherVersionOverride_Call {
+	return &Flags_SetPinnedLauncherVersionOverride_Call{Call: _e.mock.On("SetPinnedLauncherVersionOverride", version, duration)}
+}
+
+func (_c *Flags_SetPinnedLauncherVersionOverride_Call) Run(run func(version string, duration time.Duration)) *Flags_SetPinnedLauncherVersionOverride_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		var arg1 time.Duration
+		if args[1] != nil {
+			arg1 = args[1].(time.Duration)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *Flags_SetPinnedLauncherVersionOverride_Call) Return() *Flags_SetPinnedLauncherVersionOverride_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *Flags_SetPinnedLauncherVersionOverride_Call) RunAndReturn(run func(version string, duration time.Duration)) *Flags_SetPinnedLauncherVersionOverride_Call {
+	_c.Run(run)
+	return _c
+}
+
 // SetPinnedOsquerydVersion provides a mock function for the type Flags
 func (_mock *Flags) SetPinnedOsquerydVersion(version string) error {
 	ret := _mock.Called(version)
@@ -4783,6 +6393,52 @@ func (_c *Flags_SetPinnedOsquerydVersion_Call) RunAndReturn(run func(version str
 	return _c
 }
 
+// SetPinnedOsquerydVersionOverride provides a mock function for the type Flags
+func (_mock *Flags) SetPinnedOsquerydVersionOverride(version string, duration time.Duration) {
+	_mock.Called(version, duration)
+	return
+}
+
+// Flags_SetPinnedOsquerydVersionOverride_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetPinnedOsquerydVersionOverride'
+type Flags_SetPinnedOsquerydVersionOverride_Call struct {
+	*mock.Call
+}
+
+// SetPinnedOsquerydVersionOverride is a helper method to define mock.On call
+//   - version string
+//   - duration time.Duration
+func (_e *Flags_Expecter) SetPinnedOsquerydVersionOverride(version interface{}, duration interface{}) *Flags_SetPinnedOsquerydVersionOverride_Call {
+	return &Flags_SetPinnedOsquerydVersionOverride_Call{Call: _e.mock.On("SetPinnedOsquerydVersionOverride", version, duration)}
+}
+
+func (_c *Flags_SetPinnedOsquerydVersionOverride_Call) Run(run func(version string, duration time.Duration)) *Flags_SetPinnedOsquerydVersionOverride_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		var arg1 time.Duration
+		if args[1] != nil {
+			arg1 = args[1].(time.Duration)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *Flags_SetPinnedOsquerydVersionOverride_Call) Return() *Flags_SetPinnedOsquerydVersionOverride_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *Flags_SetPinnedOsquerydVersionOverride_Call) RunAndReturn(run func(version string, duration time.Duration)) *Flags_SetPinnedOsquerydVersionOverride_Call {
+	_c.Run(run)
+	return _c
+}
+
 // SetResetOnHardwareChangeEnabled provides a mock function for the type Flags
 func (_mock *Flags) SetResetOnHardwareChangeEnabled(enabled bool) error {
 	ret := _mock.Called(enabled)
@@ -5237,6 +6893,52 @@ func (_c *Flags_SetUpdateChannel_Call) RunAndReturn(run func(channel string) err
 	return _c
 }
 
+// SetUpdateChannelOverride provides a mock function for the type Flags
+func (_mock *Flags) SetUpdateChannelOverride(channel string, duration time.Duration) {
+	_mock.Called(channel, duration)
+	return
+}
+
+// Flags_SetUpdateChannelOverride_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetUpdateChannelOverride'
+type Flags_SetUpdateChannelOverride_Call struct {
+	*mock.Call
+}
+
+// SetUpdateChannelOverride is a helper method to define mock.On call
+//   - channel string
+//   - duration time.Duration
+func (_e *Flags_Expecter) SetUpdateChannelOverride(channel interface{}, duration interface{}) *Flags_SetUpdateChannelOverride_Call {
+	return &Flags_SetUpdateChannelOverride_Call{Call: _e.mock.On("SetUpdateChannelOverride", channel, duration)}
+}
+
+func (_c *Flags_SetUpdateChannelOverride_Call) Run(run func(channel string, duration time.Duration)) *Flags_SetUpdateChannelOverride_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		var arg1 time.Duration
+		if args[1] != nil {
+			arg1 = args[1].(time.Duration)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *Flags_SetUpdateChannelOverride_Call) Return() *Flags_SetUpdateChannelOverride_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *Flags_SetUpdateChannelOverride_Call) RunAndReturn(run func(channel string, duration time.Duration)) *Flags_SetUpdateChannelOverride_Call {
+	_c.Run(run)
+	return _c
+}
+
 // SetUpdateDirectory provides a mock function for the type Flags
 func (_mock *Flags) SetUpdateDirectory(directory string) error {
 	ret := _mock.Called(directory)
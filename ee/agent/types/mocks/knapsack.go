@@ -185,6 +185,138 @@ func (_c *Knapsack_AllowOverlyBroadDt4aAcceleration_Call) RunAndReturn(run func(
 	return _c
 }
 
+// AllowedCommandsAllowlist provides a mock function for the type Knapsack
+func (_mock *Knapsack) AllowedCommandsAllowlist() string {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for AllowedCommandsAllowlist")
+	}
+
+	var r0 string
+	if returnFunc, ok := ret.Get(0).(func() string); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	return r0
+}
+
+// Knapsack_AllowedCommandsAllowlist_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AllowedCommandsAllowlist'
+type Knapsack_AllowedCommandsAllowlist_Call struct {
+	*mock.Call
+}
+
+// AllowedCommandsAllowlist is a helper method to define mock.On call
+func (_e *Knapsack_Expecter) AllowedCommandsAllowlist() *Knapsack_AllowedCommandsAllowlist_Call {
+	return &Knapsack_AllowedCommandsAllowlist_Call{Call: _e.mock.On("AllowedCommandsAllowlist")}
+}
+
+func (_c *Knapsack_AllowedCommandsAllowlist_Call) Run(run func()) *Knapsack_AllowedCommandsAllowlist_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Knapsack_AllowedCommandsAllowlist_Call) Return(r0 string) *Knapsack_AllowedCommandsAllowlist_Call {
+	_c.Call.Return(r0)
+	return _c
+}
+
+func (_c *Knapsack_AllowedCommandsAllowlist_Call) RunAndReturn(run func() string) *Knapsack_AllowedCommandsAllowlist_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// AllowedCommandsDenylist provides a mock function for the type Knapsack
+func (_mock *Knapsack) AllowedCommandsDenylist() string {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for AllowedCommandsDenylist")
+	}
+
+	var r0 string
+	if returnFunc, ok := ret.Get(0).(func() string); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	return r0
+}
+
+// Knapsack_AllowedCommandsDenylist_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AllowedCommandsDenylist'
+type Knapsack_AllowedCommandsDenylist_Call struct {
+	*mock.Call
+}
+
+// AllowedCommandsDenylist is a helper method to define mock.On call
+func (_e *Knapsack_Expecter) AllowedCommandsDenylist() *Knapsack_AllowedCommandsDenylist_Call {
+	return &Knapsack_AllowedCommandsDenylist_Call{Call: _e.mock.On("AllowedCommandsDenylist")}
+}
+
+func (_c *Knapsack_AllowedCommandsDenylist_Call) Run(run func()) *Knapsack_AllowedCommandsDenylist_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Knapsack_AllowedCommandsDenylist_Call) Return(r0 string) *Knapsack_AllowedCommandsDenylist_Call {
+	_c.Call.Return(r0)
+	return _c
+}
+
+func (_c *Knapsack_AllowedCommandsDenylist_Call) RunAndReturn(run func() string) *Knapsack_AllowedCommandsDenylist_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// AllowedCommandsVerifyIntegrity provides a mock function for the type Knapsack
+func (_mock *Knapsack) AllowedCommandsVerifyIntegrity() bool {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for AllowedCommandsVerifyIntegrity")
+	}
+
+	var r0 bool
+	if returnFunc, ok := ret.Get(0).(func() bool); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	return r0
+}
+
+// Knapsack_AllowedCommandsVerifyIntegrity_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AllowedCommandsVerifyIntegrity'
+type Knapsack_AllowedCommandsVerifyIntegrity_Call struct {
+	*mock.Call
+}
+
+// AllowedCommandsVerifyIntegrity is a helper method to define mock.On call
+func (_e *Knapsack_Expecter) AllowedCommandsVerifyIntegrity() *Knapsack_AllowedCommandsVerifyIntegrity_Call {
+	return &Knapsack_AllowedCommandsVerifyIntegrity_Call{Call: _e.mock.On("AllowedCommandsVerifyIntegrity")}
+}
+
+func (_c *Knapsack_AllowedCommandsVerifyIntegrity_Call) Run(run func()) *Knapsack_AllowedCommandsVerifyIntegrity_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Knapsack_AllowedCommandsVerifyIntegrity_Call) Return(r0 bool) *Knapsack_AllowedCommandsVerifyIntegrity_Call {
+	_c.Call.Return(r0)
+	return _c
+}
+
+func (_c *Knapsack_AllowedCommandsVerifyIntegrity_Call) RunAndReturn(run func() bool) *Knapsack_AllowedCommandsVerifyIntegrity_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Autoupdate provides a mock function for the type Knapsack
 func (_mock *Knapsack) Autoupdate() bool {
 	ret := _mock.Called()
@@ -229,6 +361,50 @@ func (_c *Knapsack_Autoupdate_Call) RunAndReturn(run func() bool) *Knapsack_Auto
 	return _c
 }
 
+// AutoupdateBandwidthLimitKB provides a mock function for the type Knapsack
+func (_mock *Knapsack) AutoupdateBandwidthLimitKB() int {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for AutoupdateBandwidthLimitKB")
+	}
+
+	var r0 int
+	if returnFunc, ok := ret.Get(0).(func() int); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+	return r0
+}
+
+// Knapsack_AutoupdateBandwidthLimitKB_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AutoupdateBandwidthLimitKB'
+type Knapsack_AutoupdateBandwidthLimitKB_Call struct {
+	*mock.Call
+}
+
+// AutoupdateBandwidthLimitKB is a helper method to define mock.On call
+func (_e *Knapsack_Expecter) AutoupdateBandwidthLimitKB() *Knapsack_AutoupdateBandwidthLimitKB_Call {
+	return &Knapsack_AutoupdateBandwidthLimitKB_Call{Call: _e.mock.On("AutoupdateBandwidthLimitKB")}
+}
+
+func (_c *Knapsack_AutoupdateBandwidthLimitKB_Call) Run(run func()) *Knapsack_AutoupdateBandwidthLimitKB_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Knapsack_AutoupdateBandwidthLimitKB_Call) Return(_a0 int) *Knapsack_AutoupdateBandwidthLimitKB_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Knapsack_AutoupdateBandwidthLimitKB_Call) RunAndReturn(run func() int) *Knapsack_AutoupdateBandwidthLimitKB_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // AutoupdateDownloadSplay provides a mock function for the type Knapsack
 func (_mock *Knapsack) AutoupdateDownloadSplay() time.Duration {
 	ret := _mock.Called()
@@ -361,6 +537,52 @@ func (_c *Knapsack_AutoupdateInterval_Call) RunAndReturn(run func() time.Duratio
 	return _c
 }
 
+// AutoupdatePinsStore provides a mock function for the type Knapsack
+func (_mock *Knapsack) AutoupdatePinsStore() types.KVStore {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for AutoupdatePinsStore")
+	}
+
+	var r0 types.KVStore
+	if returnFunc, ok := ret.Get(0).(func() types.KVStore); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(types.KVStore)
+		}
+	}
+	return r0
+}
+
+// Knapsack_AutoupdatePinsStore_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AutoupdatePinsStore'
+type Knapsack_AutoupdatePinsStore_Call struct {
+	*mock.Call
+}
+
+// AutoupdatePinsStore is a helper method to define mock.On call
+func (_e *Knapsack_Expecter) AutoupdatePinsStore() *Knapsack_AutoupdatePinsStore_Call {
+	return &Knapsack_AutoupdatePinsStore_Call{Call: _e.mock.On("AutoupdatePinsStore")}
+}
+
+func (_c *Knapsack_AutoupdatePinsStore_Call) Run(run func()) *Knapsack_AutoupdatePinsStore_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Knapsack_AutoupdatePinsStore_Call) Return(r0 types.KVStore) *Knapsack_AutoupdatePinsStore_Call {
+	_c.Call.Return(r0)
+	return _c
+}
+
+func (_c *Knapsack_AutoupdatePinsStore_Call) RunAndReturn(run func() types.KVStore) *Knapsack_AutoupdatePinsStore_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // BboltDB provides a mock function for the type Knapsack
 func (_mock *Knapsack) BboltDB() *bbolt.DB {
 	ret := _mock.Called()
@@ -774,56 +996,56 @@ func (_c *Knapsack_CurrentRunningOsqueryVersion_Call) RunAndReturn(run func() st
 	return _c
 }
 
-// Debug provides a mock function for the type Knapsack
-func (_mock *Knapsack) Debug() bool {
+// CustomerOTLPCAPath provides a mock function for the type Knapsack
+func (_mock *Knapsack) CustomerOTLPCAPath() string {
 	ret := _mock.Called()
 
 	if len(ret) == 0 {
-		panic("no return value specified for Debug")
+		panic("no return value specified for CustomerOTLPCAPath")
 	}
 
-	var r0 bool
-	if returnFunc, ok := ret.Get(0).(func() bool); ok {
+	var r0 string
+	if returnFunc, ok := ret.Get(0).(func() string); ok {
 		r0 = returnFunc()
 	} else {
-		r0 = ret.Get(0).(bool)
+		r0 = ret.Get(0).(string)
 	}
 	return r0
 }
 
-// Knapsack_Debug_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Debug'
-type Knapsack_Debug_Call struct {
+// Knapsack_CustomerOTLPCAPath_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CustomerOTLPCAPath'
+type Knapsack_CustomerOTLPCAPath_Call struct {
 	*mock.Call
 }
 
-// Debug is a helper method to define mock.On call
-func (_e *Knapsack_Expecter) Debug() *Knapsack_Debug_Call {
-	return &Knapsack_Debug_Call{Call: _e.mock.On("Debug")}
+// CustomerOTLPCAPath is a helper method to define mock.On call
+func (_e *Knapsack_Expecter) CustomerOTLPCAPath() *Knapsack_CustomerOTLPCAPath_Call {
+	return &Knapsack_CustomerOTLPCAPath_Call{Call: _e.mock.On("CustomerOTLPCAPath")}
 }
 
-func (_c *Knapsack_Debug_Call) Run(run func()) *Knapsack_Debug_Call {
+func (_c *Knapsack_CustomerOTLPCAPath_Call) Run(run func()) *Knapsack_CustomerOTLPCAPath_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run()
 	})
 	return _c
 }
 
-func (_c *Knapsack_Debug_Call) Return(b bool) *Knapsack_Debug_Call {
-	_c.Call.Return(b)
+func (_c *Knapsack_CustomerOTLPCAPath_Call) Return(_a string) *Knapsack_CustomerOTLPCAPath_Call {
+	_c.Call.Return(_a)
 	return _c
 }
 
-func (_c *Knapsack_Debug_Call) RunAndReturn(run func() bool) *Knapsack_Debug_Call {
+func (_c *Knapsack_CustomerOTLPCAPath_Call) RunAndReturn(run func() string) *Knapsack_CustomerOTLPCAPath_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// DebugLogFile provides a mock function for the type Knapsack
-func (_mock *Knapsack) DebugLogFile() string {
+// CustomerOTLPEndpoint provides a mock function for the type Knapsack
+func (_mock *Knapsack) CustomerOTLPEndpoint() string {
 	ret := _mock.Called()
 
 	if len(ret) == 0 {
-		panic("no return value specified for DebugLogFile")
+		panic("no return value specified for CustomerOTLPEndpoint")
 	}
 
 	var r0 string
@@ -835,142 +1057,362 @@ func (_mock *Knapsack) DebugLogFile() string {
 	return r0
 }
 
-// Knapsack_DebugLogFile_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DebugLogFile'
-type Knapsack_DebugLogFile_Call struct {
+// Knapsack_CustomerOTLPEndpoint_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CustomerOTLPEndpoint'
+type Knapsack_CustomerOTLPEndpoint_Call struct {
 	*mock.Call
 }
 
-// DebugLogFile is a helper method to define mock.On call
-func (_e *Knapsack_Expecter) DebugLogFile() *Knapsack_DebugLogFile_Call {
-	return &Knapsack_DebugLogFile_Call{Call: _e.mock.On("DebugLogFile")}
+// CustomerOTLPEndpoint is a helper method to define mock.On call
+func (_e *Knapsack_Expecter) CustomerOTLPEndpoint() *Knapsack_CustomerOTLPEndpoint_Call {
+	return &Knapsack_CustomerOTLPEndpoint_Call{Call: _e.mock.On("CustomerOTLPEndpoint")}
 }
 
-func (_c *Knapsack_DebugLogFile_Call) Run(run func()) *Knapsack_DebugLogFile_Call {
+func (_c *Knapsack_CustomerOTLPEndpoint_Call) Run(run func()) *Knapsack_CustomerOTLPEndpoint_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run()
 	})
 	return _c
 }
 
-func (_c *Knapsack_DebugLogFile_Call) Return(s string) *Knapsack_DebugLogFile_Call {
-	_c.Call.Return(s)
+func (_c *Knapsack_CustomerOTLPEndpoint_Call) Return(_a string) *Knapsack_CustomerOTLPEndpoint_Call {
+	_c.Call.Return(_a)
 	return _c
 }
 
-func (_c *Knapsack_DebugLogFile_Call) RunAndReturn(run func() string) *Knapsack_DebugLogFile_Call {
+func (_c *Knapsack_CustomerOTLPEndpoint_Call) RunAndReturn(run func() string) *Knapsack_CustomerOTLPEndpoint_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// DebugServerData provides a mock function for the type Knapsack
-func (_mock *Knapsack) DebugServerData() bool {
+// CustomerOTLPHeaders provides a mock function for the type Knapsack
+func (_mock *Knapsack) CustomerOTLPHeaders() string {
 	ret := _mock.Called()
 
 	if len(ret) == 0 {
-		panic("no return value specified for DebugServerData")
+		panic("no return value specified for CustomerOTLPHeaders")
 	}
 
-	var r0 bool
-	if returnFunc, ok := ret.Get(0).(func() bool); ok {
+	var r0 string
+	if returnFunc, ok := ret.Get(0).(func() string); ok {
 		r0 = returnFunc()
 	} else {
-		r0 = ret.Get(0).(bool)
+		r0 = ret.Get(0).(string)
 	}
 	return r0
 }
 
-// Knapsack_DebugServerData_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DebugServerData'
-type Knapsack_DebugServerData_Call struct {
+// Knapsack_CustomerOTLPHeaders_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CustomerOTLPHeaders'
+type Knapsack_CustomerOTLPHeaders_Call struct {
 	*mock.Call
 }
 
-// DebugServerData is a helper method to define mock.On call
-func (_e *Knapsack_Expecter) DebugServerData() *Knapsack_DebugServerData_Call {
-	return &Knapsack_DebugServerData_Call{Call: _e.mock.On("DebugServerData")}
+// CustomerOTLPHeaders is a helper method to define mock.On call
+func (_e *Knapsack_Expecter) CustomerOTLPHeaders() *Knapsack_CustomerOTLPHeaders_Call {
+	return &Knapsack_CustomerOTLPHeaders_Call{Call: _e.mock.On("CustomerOTLPHeaders")}
 }
 
-func (_c *Knapsack_DebugServerData_Call) Run(run func()) *Knapsack_DebugServerData_Call {
+func (_c *Knapsack_CustomerOTLPHeaders_Call) Run(run func()) *Knapsack_CustomerOTLPHeaders_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run()
 	})
 	return _c
 }
 
-func (_c *Knapsack_DebugServerData_Call) Return(b bool) *Knapsack_DebugServerData_Call {
-	_c.Call.Return(b)
+func (_c *Knapsack_CustomerOTLPHeaders_Call) Return(_a string) *Knapsack_CustomerOTLPHeaders_Call {
+	_c.Call.Return(_a)
 	return _c
 }
 
-func (_c *Knapsack_DebugServerData_Call) RunAndReturn(run func() bool) *Knapsack_DebugServerData_Call {
+func (_c *Knapsack_CustomerOTLPHeaders_Call) RunAndReturn(run func() string) *Knapsack_CustomerOTLPHeaders_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// DeleteEnrollment provides a mock function for the type Knapsack
-func (_mock *Knapsack) DeleteEnrollment(enrollmentId string) error {
-	ret := _mock.Called(enrollmentId)
+// CustomerOTLPOnly provides a mock function for the type Knapsack
+func (_mock *Knapsack) CustomerOTLPOnly() bool {
+	ret := _mock.Called()
 
 	if len(ret) == 0 {
-		panic("no return value specified for DeleteEnrollment")
+		panic("no return value specified for CustomerOTLPOnly")
 	}
 
-	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(string) error); ok {
-		r0 = returnFunc(enrollmentId)
+	var r0 bool
+	if returnFunc, ok := ret.Get(0).(func() bool); ok {
+		r0 = returnFunc()
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(bool)
 	}
 	return r0
 }
 
-// Knapsack_DeleteEnrollment_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteEnrollment'
-type Knapsack_DeleteEnrollment_Call struct {
+// Knapsack_CustomerOTLPOnly_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CustomerOTLPOnly'
+type Knapsack_CustomerOTLPOnly_Call struct {
 	*mock.Call
 }
 
-// DeleteEnrollment is a helper method to define mock.On call
-//   - enrollmentId string
-func (_e *Knapsack_Expecter) DeleteEnrollment(enrollmentId interface{}) *Knapsack_DeleteEnrollment_Call {
-	return &Knapsack_DeleteEnrollment_Call{Call: _e.mock.On("DeleteEnrollment", enrollmentId)}
+// CustomerOTLPOnly is a helper method to define mock.On call
+func (_e *Knapsack_Expecter) CustomerOTLPOnly() *Knapsack_CustomerOTLPOnly_Call {
+	return &Knapsack_CustomerOTLPOnly_Call{Call: _e.mock.On("CustomerOTLPOnly")}
 }
 
-func (_c *Knapsack_DeleteEnrollment_Call) Run(run func(enrollmentId string)) *Knapsack_DeleteEnrollment_Call {
+func (_c *Knapsack_CustomerOTLPOnly_Call) Run(run func()) *Knapsack_CustomerOTLPOnly_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		var arg0 string
-		if args[0] != nil {
-			arg0 = args[0].(string)
-		}
-		run(
-			arg0,
-		)
+		run()
 	})
 	return _c
 }
 
-func (_c *Knapsack_DeleteEnrollment_Call) Return(err error) *Knapsack_DeleteEnrollment_Call {
-	_c.Call.Return(err)
+func (_c *Knapsack_CustomerOTLPOnly_Call) Return(_a bool) *Knapsack_CustomerOTLPOnly_Call {
+	_c.Call.Return(_a)
 	return _c
 }
 
-func (_c *Knapsack_DeleteEnrollment_Call) RunAndReturn(run func(enrollmentId string) error) *Knapsack_DeleteEnrollment_Call {
+func (_c *Knapsack_CustomerOTLPOnly_Call) RunAndReturn(run func() bool) *Knapsack_CustomerOTLPOnly_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// DeregisterChangeObserver provides a mock function for the type Knapsack
-func (_mock *Knapsack) DeregisterChangeObserver(observer types.FlagsChangeObserver) {
-	_mock.Called(observer)
-	return
-}
+// CustomerOTLPSamplingRate provides a mock function for the type Knapsack
+func (_mock *Knapsack) CustomerOTLPSamplingRate() float64 {
+	ret := _mock.Called()
 
-// Knapsack_DeregisterChangeObserver_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeregisterChangeObserver'
-type Knapsack_DeregisterChangeObserver_Call struct {
-	*mock.Call
-}
+	if len(ret) == 0 {
+		panic("no return value specified for CustomerOTLPSamplingRate")
+	}
 
-// DeregisterChangeObserver is a helper method to define mock.On call
-//   - observer types.FlagsChangeObserver
-func (_e *Knapsack_Expecter) DeregisterChangeObserver(observer interface{}) *Knapsack_DeregisterChangeObserver_Call {
+	var r0 float64
+	if returnFunc, ok := ret.Get(0).(func() float64); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(float64)
+	}
+	return r0
+}
+
+// Knapsack_CustomerOTLPSamplingRate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CustomerOTLPSamplingRate'
+type Knapsack_CustomerOTLPSamplingRate_Call struct {
+	*mock.Call
+}
+
+// CustomerOTLPSamplingRate is a helper method to define mock.On call
+func (_e *Knapsack_Expecter) CustomerOTLPSamplingRate() *Knapsack_CustomerOTLPSamplingRate_Call {
+	return &Knapsack_CustomerOTLPSamplingRate_Call{Call: _e.mock.On("CustomerOTLPSamplingRate")}
+}
+
+func (_c *Knapsack_CustomerOTLPSamplingRate_Call) Run(run func()) *Knapsack_CustomerOTLPSamplingRate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Knapsack_CustomerOTLPSamplingRate_Call) Return(_a float64) *Knapsack_CustomerOTLPSamplingRate_Call {
+	_c.Call.Return(_a)
+	return _c
+}
+
+func (_c *Knapsack_CustomerOTLPSamplingRate_Call) RunAndReturn(run func() float64) *Knapsack_CustomerOTLPSamplingRate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Debug provides a mock function for the type Knapsack
+func (_mock *Knapsack) Debug() bool {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Debug")
+	}
+
+	var r0 bool
+	if returnFunc, ok := ret.Get(0).(func() bool); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	return r0
+}
+
+// Knapsack_Debug_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Debug'
+type Knapsack_Debug_Call struct {
+	*mock.Call
+}
+
+// Debug is a helper method to define mock.On call
+func (_e *Knapsack_Expecter) Debug() *Knapsack_Debug_Call {
+	return &Knapsack_Debug_Call{Call: _e.mock.On("Debug")}
+}
+
+func (_c *Knapsack_Debug_Call) Run(run func()) *Knapsack_Debug_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Knapsack_Debug_Call) Return(b bool) *Knapsack_Debug_Call {
+	_c.Call.Return(b)
+	return _c
+}
+
+func (_c *Knapsack_Debug_Call) RunAndReturn(run func() bool) *Knapsack_Debug_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DebugLogFile provides a mock function for the type Knapsack
+func (_mock *Knapsack) DebugLogFile() string {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for DebugLogFile")
+	}
+
+	var r0 string
+	if returnFunc, ok := ret.Get(0).(func() string); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	return r0
+}
+
+// Knapsack_DebugLogFile_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DebugLogFile'
+type Knapsack_DebugLogFile_Call struct {
+	*mock.Call
+}
+
+// DebugLogFile is a helper method to define mock.On call
+func (_e *Knapsack_Expecter) DebugLogFile() *Knapsack_DebugLogFile_Call {
+	return &Knapsack_DebugLogFile_Call{Call: _e.mock.On("DebugLogFile")}
+}
+
+func (_c *Knapsack_DebugLogFile_Call) Run(run func()) *Knapsack_DebugLogFile_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Knapsack_DebugLogFile_Call) Return(s string) *Knapsack_DebugLogFile_Call {
+	_c.Call.Return(s)
+	return _c
+}
+
+func (_c *Knapsack_DebugLogFile_Call) RunAndReturn(run func() string) *Knapsack_DebugLogFile_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DebugServerData provides a mock function for the type Knapsack
+func (_mock *Knapsack) DebugServerData() bool {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for DebugServerData")
+	}
+
+	var r0 bool
+	if returnFunc, ok := ret.Get(0).(func() bool); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	return r0
+}
+
+// Knapsack_DebugServerData_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DebugServerData'
+type Knapsack_DebugServerData_Call struct {
+	*mock.Call
+}
+
+// DebugServerData is a helper method to define mock.On call
+func (_e *Knapsack_Expecter) DebugServerData() *Knapsack_DebugServerData_Call {
+	return &Knapsack_DebugServerData_Call{Call: _e.mock.On("DebugServerData")}
+}
+
+func (_c *Knapsack_DebugServerData_Call) Run(run func()) *Knapsack_DebugServerData_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Knapsack_DebugServerData_Call) Return(b bool) *Knapsack_DebugServerData_Call {
+	_c.Call.Return(b)
+	return _c
+}
+
+func (_c *Knapsack_DebugServerData_Call) RunAndReturn(run func() bool) *Knapsack_DebugServerData_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteEnrollment provides a mock function for the type Knapsack
+func (_mock *Knapsack) DeleteEnrollment(enrollmentId string) error {
+	ret := _mock.Called(enrollmentId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteEnrollment")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(string) error); ok {
+		r0 = returnFunc(enrollmentId)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// Knapsack_DeleteEnrollment_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteEnrollment'
+type Knapsack_DeleteEnrollment_Call struct {
+	*mock.Call
+}
+
+// DeleteEnrollment is a helper method to define mock.On call
+//   - enrollmentId string
+func (_e *Knapsack_Expecter) DeleteEnrollment(enrollmentId interface{}) *Knapsack_DeleteEnrollment_Call {
+	return &Knapsack_DeleteEnrollment_Call{Call: _e.mock.On("DeleteEnrollment", enrollmentId)}
+}
+
+func (_c *Knapsack_DeleteEnrollment_Call) Run(run func(enrollmentId string)) *Knapsack_DeleteEnrollment_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *Knapsack_DeleteEnrollment_Call) Return(err error) *Knapsack_DeleteEnrollment_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *Knapsack_DeleteEnrollment_Call) RunAndReturn(run func(enrollmentId string) error) *Knapsack_DeleteEnrollment_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeregisterChangeObserver provides a mock function for the type Knapsack
+func (_mock *Knapsack) DeregisterChangeObserver(observer types.FlagsChangeObserver) {
+	_mock.Called(observer)
+	return
+}
+
+// Knapsack_DeregisterChangeObserver_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeregisterChangeObserver'
+type Knapsack_DeregisterChangeObserver_Call struct {
+	*mock.Call
+}
+
+// DeregisterChangeObserver is a helper method to define mock.On call
+//   - observer types.FlagsChangeObserver
+func (_e *Knapsack_Expecter) DeregisterChangeObserver(observer interface{}) *Knapsack_DeregisterChangeObserver_Call {
 	return &Knapsack_DeregisterChangeObserver_Call{Call: _e.mock.On("DeregisterChangeObserver", observer)}
 }
 
@@ -1217,6 +1659,50 @@ func (_c *Knapsack_DisableControlTLS_Call) RunAndReturn(run func() bool) *Knapsa
 	return _c
 }
 
+// DisableCustomerOTLPTLS provides a mock function for the type Knapsack
+func (_mock *Knapsack) DisableCustomerOTLPTLS() bool {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for DisableCustomerOTLPTLS")
+	}
+
+	var r0 bool
+	if returnFunc, ok := ret.Get(0).(func() bool); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	return r0
+}
+
+// Knapsack_DisableCustomerOTLPTLS_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DisableCustomerOTLPTLS'
+type Knapsack_DisableCustomerOTLPTLS_Call struct {
+	*mock.Call
+}
+
+// DisableCustomerOTLPTLS is a helper method to define mock.On call
+func (_e *Knapsack_Expecter) DisableCustomerOTLPTLS() *Knapsack_DisableCustomerOTLPTLS_Call {
+	return &Knapsack_DisableCustomerOTLPTLS_Call{Call: _e.mock.On("DisableCustomerOTLPTLS")}
+}
+
+func (_c *Knapsack_DisableCustomerOTLPTLS_Call) Run(run func()) *Knapsack_DisableCustomerOTLPTLS_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Knapsack_DisableCustomerOTLPTLS_Call) Return(_a bool) *Knapsack_DisableCustomerOTLPTLS_Call {
+	_c.Call.Return(_a)
+	return _c
+}
+
+func (_c *Knapsack_DisableCustomerOTLPTLS_Call) RunAndReturn(run func() bool) *Knapsack_DisableCustomerOTLPTLS_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // DisableTraceIngestTLS provides a mock function for the type Knapsack
 func (_mock *Knapsack) DisableTraceIngestTLS() bool {
 	ret := _mock.Called()
@@ -1395,6 +1881,50 @@ func (_c *Knapsack_DuplicateLogWindow_Call) RunAndReturn(run func() time.Duratio
 	return _c
 }
 
+// DuplicateLogWindowOverrides provides a mock function for the type Knapsack
+func (_mock *Knapsack) DuplicateLogWindowOverrides() string {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for DuplicateLogWindowOverrides")
+	}
+
+	var r0 string
+	if returnFunc, ok := ret.Get(0).(func() string); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	return r0
+}
+
+// Knapsack_DuplicateLogWindowOverrides_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DuplicateLogWindowOverrides'
+type Knapsack_DuplicateLogWindowOverrides_Call struct {
+	*mock.Call
+}
+
+// DuplicateLogWindowOverrides is a helper method to define mock.On call
+func (_e *Knapsack_Expecter) DuplicateLogWindowOverrides() *Knapsack_DuplicateLogWindowOverrides_Call {
+	return &Knapsack_DuplicateLogWindowOverrides_Call{Call: _e.mock.On("DuplicateLogWindowOverrides")}
+}
+
+func (_c *Knapsack_DuplicateLogWindowOverrides_Call) Run(run func()) *Knapsack_DuplicateLogWindowOverrides_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Knapsack_DuplicateLogWindowOverrides_Call) Return(r0 string) *Knapsack_DuplicateLogWindowOverrides_Call {
+	_c.Call.Return(r0)
+	return _c
+}
+
+func (_c *Knapsack_DuplicateLogWindowOverrides_Call) RunAndReturn(run func() string) *Knapsack_DuplicateLogWindowOverrides_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // EnableInitialRunner provides a mock function for the type Knapsack
 func (_mock *Knapsack) EnableInitialRunner() bool {
 	ret := _mock.Called()
@@ -1815,6 +2345,50 @@ func (_c *Knapsack_ExportTraces_Call) RunAndReturn(run func() bool) *Knapsack_Ex
 	return _c
 }
 
+// ExternalCheckups provides a mock function for the type Knapsack
+func (_mock *Knapsack) ExternalCheckups() string {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExternalCheckups")
+	}
+
+	var r0 string
+	if returnFunc, ok := ret.Get(0).(func() string); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	return r0
+}
+
+// Knapsack_ExternalCheckups_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExternalCheckups'
+type Knapsack_ExternalCheckups_Call struct {
+	*mock.Call
+}
+
+// ExternalCheckups is a helper method to define mock.On call
+func (_e *Knapsack_Expecter) ExternalCheckups() *Knapsack_ExternalCheckups_Call {
+	return &Knapsack_ExternalCheckups_Call{Call: _e.mock.On("ExternalCheckups")}
+}
+
+func (_c *Knapsack_ExternalCheckups_Call) Run(run func()) *Knapsack_ExternalCheckups_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Knapsack_ExternalCheckups_Call) Return(_a string) *Knapsack_ExternalCheckups_Call {
+	_c.Call.Return(_a)
+	return _c
+}
+
+func (_c *Knapsack_ExternalCheckups_Call) RunAndReturn(run func() string) *Knapsack_ExternalCheckups_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // FilewalkConfigStore provides a mock function for the type Knapsack
 func (_mock *Knapsack) FilewalkConfigStore() types.KVStore {
 	ret := _mock.Called()
@@ -1907,6 +2481,50 @@ func (_c *Knapsack_FilewalkResultsStore_Call) RunAndReturn(run func() types.KVSt
 	return _c
 }
 
+// FlareRedactionRules provides a mock function for the type Knapsack
+func (_mock *Knapsack) FlareRedactionRules() string {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for FlareRedactionRules")
+	}
+
+	var r0 string
+	if returnFunc, ok := ret.Get(0).(func() string); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	return r0
+}
+
+// Knapsack_FlareRedactionRules_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FlareRedactionRules'
+type Knapsack_FlareRedactionRules_Call struct {
+	*mock.Call
+}
+
+// FlareRedactionRules is a helper method to define mock.On call
+func (_e *Knapsack_Expecter) FlareRedactionRules() *Knapsack_FlareRedactionRules_Call {
+	return &Knapsack_FlareRedactionRules_Call{Call: _e.mock.On("FlareRedactionRules")}
+}
+
+func (_c *Knapsack_FlareRedactionRules_Call) Run(run func()) *Knapsack_FlareRedactionRules_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Knapsack_FlareRedactionRules_Call) Return(_a string) *Knapsack_FlareRedactionRules_Call {
+	_c.Call.Return(_a)
+	return _c
+}
+
+func (_c *Knapsack_FlareRedactionRules_Call) RunAndReturn(run func() string) *Knapsack_FlareRedactionRules_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // ForceControlSubsystems provides a mock function for the type Knapsack
 func (_mock *Knapsack) ForceControlSubsystems() bool {
 	ret := _mock.Called()
@@ -2039,6 +2657,50 @@ func (_c *Knapsack_GetRunID_Call) RunAndReturn(run func() string) *Knapsack_GetR
 	return _c
 }
 
+// HardwareKeyRotationInterval provides a mock function for the type Knapsack
+func (_mock *Knapsack) HardwareKeyRotationInterval() time.Duration {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for HardwareKeyRotationInterval")
+	}
+
+	var r0 time.Duration
+	if returnFunc, ok := ret.Get(0).(func() time.Duration); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(time.Duration)
+	}
+	return r0
+}
+
+// Knapsack_HardwareKeyRotationInterval_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HardwareKeyRotationInterval'
+type Knapsack_HardwareKeyRotationInterval_Call struct {
+	*mock.Call
+}
+
+// HardwareKeyRotationInterval is a helper method to define mock.On call
+func (_e *Knapsack_Expecter) HardwareKeyRotationInterval() *Knapsack_HardwareKeyRotationInterval_Call {
+	return &Knapsack_HardwareKeyRotationInterval_Call{Call: _e.mock.On("HardwareKeyRotationInterval")}
+}
+
+func (_c *Knapsack_HardwareKeyRotationInterval_Call) Run(run func()) *Knapsack_HardwareKeyRotationInterval_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Knapsack_HardwareKeyRotationInterval_Call) Return(r0 time.Duration) *Knapsack_HardwareKeyRotationInterval_Call {
+	_c.Call.Return(r0)
+	return _c
+}
+
+func (_c *Knapsack_HardwareKeyRotationInterval_Call) RunAndReturn(run func() time.Duration) *Knapsack_HardwareKeyRotationInterval_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // IAmBreakingEELicense provides a mock function for the type Knapsack
 func (_mock *Knapsack) IAmBreakingEELicense() bool {
 	ret := _mock.Called()
@@ -2529,6 +3191,94 @@ func (_c *Knapsack_KolideServerURL_Call) RunAndReturn(run func() string) *Knapsa
 	return _c
 }
 
+// LanCacheListenAddress provides a mock function for the type Knapsack
+func (_mock *Knapsack) LanCacheListenAddress() string {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for LanCacheListenAddress")
+	}
+
+	var r0 string
+	if returnFunc, ok := ret.Get(0).(func() string); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	return r0
+}
+
+// Knapsack_LanCacheListenAddress_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LanCacheListenAddress'
+type Knapsack_LanCacheListenAddress_Call struct {
+	*mock.Call
+}
+
+// LanCacheListenAddress is a helper method to define mock.On call
+func (_e *Knapsack_Expecter) LanCacheListenAddress() *Knapsack_LanCacheListenAddress_Call {
+	return &Knapsack_LanCacheListenAddress_Call{Call: _e.mock.On("LanCacheListenAddress")}
+}
+
+func (_c *Knapsack_LanCacheListenAddress_Call) Run(run func()) *Knapsack_LanCacheListenAddress_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Knapsack_LanCacheListenAddress_Call) Return(s string) *Knapsack_LanCacheListenAddress_Call {
+	_c.Call.Return(s)
+	return _c
+}
+
+func (_c *Knapsack_LanCacheListenAddress_Call) RunAndReturn(run func() string) *Knapsack_LanCacheListenAddress_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LanCachePeers provides a mock function for the type Knapsack
+func (_mock *Knapsack) LanCachePeers() string {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for LanCachePeers")
+	}
+
+	var r0 string
+	if returnFunc, ok := ret.Get(0).(func() string); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	return r0
+}
+
+// Knapsack_LanCachePeers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LanCachePeers'
+type Knapsack_LanCachePeers_Call struct {
+	*mock.Call
+}
+
+// LanCachePeers is a helper method to define mock.On call
+func (_e *Knapsack_Expecter) LanCachePeers() *Knapsack_LanCachePeers_Call {
+	return &Knapsack_LanCachePeers_Call{Call: _e.mock.On("LanCachePeers")}
+}
+
+func (_c *Knapsack_LanCachePeers_Call) Run(run func()) *Knapsack_LanCachePeers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Knapsack_LanCachePeers_Call) Return(s string) *Knapsack_LanCachePeers_Call {
+	_c.Call.Return(s)
+	return _c
+}
+
+func (_c *Knapsack_LanCachePeers_Call) RunAndReturn(run func() string) *Knapsack_LanCachePeers_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // LatestOsquerydPath provides a mock function for the type Knapsack
 func (_mock *Knapsack) LatestOsquerydPath(ctx context.Context) string {
 	ret := _mock.Called(ctx)
@@ -3024,6 +3774,50 @@ func (_c *Knapsack_LoggingInterval_Call) RunAndReturn(run func() time.Duration)
 	return _c
 }
 
+// MetricsEndpointAddress provides a mock function for the type Knapsack
+func (_mock *Knapsack) MetricsEndpointAddress() string {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for MetricsEndpointAddress")
+	}
+
+	var r0 string
+	if returnFunc, ok := ret.Get(0).(func() string); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	return r0
+}
+
+// Knapsack_MetricsEndpointAddress_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MetricsEndpointAddress'
+type Knapsack_MetricsEndpointAddress_Call struct {
+	*mock.Call
+}
+
+// MetricsEndpointAddress is a helper method to define mock.On call
+func (_e *Knapsack_Expecter) MetricsEndpointAddress() *Knapsack_MetricsEndpointAddress_Call {
+	return &Knapsack_MetricsEndpointAddress_Call{Call: _e.mock.On("MetricsEndpointAddress")}
+}
+
+func (_c *Knapsack_MetricsEndpointAddress_Call) Run(run func()) *Knapsack_MetricsEndpointAddress_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Knapsack_MetricsEndpointAddress_Call) Return(_a string) *Knapsack_MetricsEndpointAddress_Call {
+	_c.Call.Return(_a)
+	return _c
+}
+
+func (_c *Knapsack_MetricsEndpointAddress_Call) RunAndReturn(run func() string) *Knapsack_MetricsEndpointAddress_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // MirrorServerURL provides a mock function for the type Knapsack
 func (_mock *Knapsack) MirrorServerURL() string {
 	ret := _mock.Called()
@@ -3768,51 +4562,97 @@ func (_c *Knapsack_PinnedOsquerydVersion_Call) RunAndReturn(run func() string) *
 	return _c
 }
 
-// ReadEnrollSecret provides a mock function for the type Knapsack
-func (_mock *Knapsack) ReadEnrollSecret() (string, error) {
+// QueryProfilesStore provides a mock function for the type Knapsack
+func (_mock *Knapsack) QueryProfilesStore() types.KVStore {
 	ret := _mock.Called()
 
 	if len(ret) == 0 {
-		panic("no return value specified for ReadEnrollSecret")
+		panic("no return value specified for QueryProfilesStore")
 	}
 
-	var r0 string
-	var r1 error
-	if returnFunc, ok := ret.Get(0).(func() (string, error)); ok {
-		return returnFunc()
-	}
-	if returnFunc, ok := ret.Get(0).(func() string); ok {
+	var r0 types.KVStore
+	if returnFunc, ok := ret.Get(0).(func() types.KVStore); ok {
 		r0 = returnFunc()
 	} else {
-		r0 = ret.Get(0).(string)
-	}
-	if returnFunc, ok := ret.Get(1).(func() error); ok {
-		r1 = returnFunc()
-	} else {
-		r1 = ret.Error(1)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(types.KVStore)
+		}
 	}
-	return r0, r1
+	return r0
 }
 
-// Knapsack_ReadEnrollSecret_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReadEnrollSecret'
-type Knapsack_ReadEnrollSecret_Call struct {
+// Knapsack_QueryProfilesStore_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'QueryProfilesStore'
+type Knapsack_QueryProfilesStore_Call struct {
 	*mock.Call
 }
 
-// ReadEnrollSecret is a helper method to define mock.On call
-func (_e *Knapsack_Expecter) ReadEnrollSecret() *Knapsack_ReadEnrollSecret_Call {
-	return &Knapsack_ReadEnrollSecret_Call{Call: _e.mock.On("ReadEnrollSecret")}
+// QueryProfilesStore is a helper method to define mock.On call
+func (_e *Knapsack_Expecter) QueryProfilesStore() *Knapsack_QueryProfilesStore_Call {
+	return &Knapsack_QueryProfilesStore_Call{Call: _e.mock.On("QueryProfilesStore")}
 }
 
-func (_c *Knapsack_ReadEnrollSecret_Call) Run(run func()) *Knapsack_ReadEnrollSecret_Call {
+func (_c *Knapsack_QueryProfilesStore_Call) Run(run func()) *Knapsack_QueryProfilesStore_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run()
 	})
 	return _c
 }
 
-func (_c *Knapsack_ReadEnrollSecret_Call) Return(s string, err error) *Knapsack_ReadEnrollSecret_Call {
-	_c.Call.Return(s, err)
+func (_c *Knapsack_QueryProfilesStore_Call) Return(v types.KVStore) *Knapsack_QueryProfilesStore_Call {
+	_c.Call.Return(v)
+	return _c
+}
+
+func (_c *Knapsack_QueryProfilesStore_Call) RunAndReturn(run func() types.KVStore) *Knapsack_QueryProfilesStore_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ReadEnrollSecret provides a mock function for the type Knapsack
+func (_mock *Knapsack) ReadEnrollSecret() (string, error) {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReadEnrollSecret")
+	}
+
+	var r0 string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func() (string, error)); ok {
+		return returnFunc()
+	}
+	if returnFunc, ok := ret.Get(0).(func() string); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	if returnFunc, ok := ret.Get(1).(func() error); ok {
+		r1 = returnFunc()
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// Knapsack_ReadEnrollSecret_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReadEnrollSecret'
+type Knapsack_ReadEnrollSecret_Call struct {
+	*mock.Call
+}
+
+// ReadEnrollSecret is a helper method to define mock.On call
+func (_e *Knapsack_Expecter) ReadEnrollSecret() *Knapsack_ReadEnrollSecret_Call {
+	return &Knapsack_ReadEnrollSecret_Call{Call: _e.mock.On("ReadEnrollSecret")}
+}
+
+func (_c *Knapsack_ReadEnrollSecret_Call) Run(run func()) *Knapsack_ReadEnrollSecret_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Knapsack_ReadEnrollSecret_Call) Return(s string, err error) *Knapsack_ReadEnrollSecret_Call {
+	_c.Call.Return(s, err)
 	return _c
 }
 
@@ -4384,6 +5224,159 @@ func (_c *Knapsack_SetAllowOverlyBroadDt4aAcceleration_Call) RunAndReturn(run fu
 	return _c
 }
 
+// SetAllowedCommandsAllowlist provides a mock function for the type Knapsack
+func (_mock *Knapsack) SetAllowedCommandsAllowlist(allowlist string) error {
+	ret := _mock.Called(allowlist)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetAllowedCommandsAllowlist")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(string) error); ok {
+		r0 = returnFunc(allowlist)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// Knapsack_SetAllowedCommandsAllowlist_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetAllowedCommandsAllowlist'
+type Knapsack_SetAllowedCommandsAllowlist_Call struct {
+	*mock.Call
+}
+
+// SetAllowedCommandsAllowlist is a helper method to define mock.On call
+//   - allowlist string
+func (_e *Knapsack_Expecter) SetAllowedCommandsAllowlist(allowlist interface{}) *Knapsack_SetAllowedCommandsAllowlist_Call {
+	return &Knapsack_SetAllowedCommandsAllowlist_Call{Call: _e.mock.On("SetAllowedCommandsAllowlist", allowlist)}
+}
+
+func (_c *Knapsack_SetAllowedCommandsAllowlist_Call) Run(run func(allowlist string)) *Knapsack_SetAllowedCommandsAllowlist_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *Knapsack_SetAllowedCommandsAllowlist_Call) Return(r0 error) *Knapsack_SetAllowedCommandsAllowlist_Call {
+	_c.Call.Return(r0)
+	return _c
+}
+
+func (_c *Knapsack_SetAllowedCommandsAllowlist_Call) RunAndReturn(run func(allowlist string) error) *Knapsack_SetAllowedCommandsAllowlist_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetAllowedCommandsDenylist provides a mock function for the type Knapsack
+func (_mock *Knapsack) SetAllowedCommandsDenylist(denylist string) error {
+	ret := _mock.Called(denylist)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetAllowedCommandsDenylist")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(string) error); ok {
+		r0 = returnFunc(denylist)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// Knapsack_SetAllowedCommandsDenylist_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetAllowedCommandsDenylist'
+type Knapsack_SetAllowedCommandsDenylist_Call struct {
+	*mock.Call
+}
+
+// SetAllowedCommandsDenylist is a helper method to define mock.On call
+//   - denylist string
+func (_e *Knapsack_Expecter) SetAllowedCommandsDenylist(denylist interface{}) *Knapsack_SetAllowedCommandsDenylist_Call {
+	return &Knapsack_SetAllowedCommandsDenylist_Call{Call: _e.mock.On("SetAllowedCommandsDenylist", denylist)}
+}
+
+func (_c *Knapsack_SetAllowedCommandsDenylist_Call) Run(run func(denylist string)) *Knapsack_SetAllowedCommandsDenylist_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *Knapsack_SetAllowedCommandsDenylist_Call) Return(r0 error) *Knapsack_SetAllowedCommandsDenylist_Call {
+	_c.Call.Return(r0)
+	return _c
+}
+
+func (_c *Knapsack_SetAllowedCommandsDenylist_Call) RunAndReturn(run func(denylist string) error) *Knapsack_SetAllowedCommandsDenylist_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetAllowedCommandsVerifyIntegrity provides a mock function for the type Knapsack
+func (_mock *Knapsack) SetAllowedCommandsVerifyIntegrity(enabled bool) error {
+	ret := _mock.Called(enabled)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetAllowedCommandsVerifyIntegrity")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(bool) error); ok {
+		r0 = returnFunc(enabled)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// Knapsack_SetAllowedCommandsVerifyIntegrity_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetAllowedCommandsVerifyIntegrity'
+type Knapsack_SetAllowedCommandsVerifyIntegrity_Call struct {
+	*mock.Call
+}
+
+// SetAllowedCommandsVerifyIntegrity is a helper method to define mock.On call
+//   - enabled bool
+func (_e *Knapsack_Expecter) SetAllowedCommandsVerifyIntegrity(enabled interface{}) *Knapsack_SetAllowedCommandsVerifyIntegrity_Call {
+	return &Knapsack_SetAllowedCommandsVerifyIntegrity_Call{Call: _e.mock.On("SetAllowedCommandsVerifyIntegrity", enabled)}
+}
+
+func (_c *Knapsack_SetAllowedCommandsVerifyIntegrity_Call) Run(run func(enabled bool)) *Knapsack_SetAllowedCommandsVerifyIntegrity_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 bool
+		if args[0] != nil {
+			arg0 = args[0].(bool)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *Knapsack_SetAllowedCommandsVerifyIntegrity_Call) Return(r0 error) *Knapsack_SetAllowedCommandsVerifyIntegrity_Call {
+	_c.Call.Return(r0)
+	return _c
+}
+
+func (_c *Knapsack_SetAllowedCommandsVerifyIntegrity_Call) RunAndReturn(run func(enabled bool) error) *Knapsack_SetAllowedCommandsVerifyIntegrity_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // SetAutoupdate provides a mock function for the type Knapsack
 func (_mock *Knapsack) SetAutoupdate(enabled bool) error {
 	ret := _mock.Called(enabled)
@@ -4435,6 +5428,57 @@ func (_c *Knapsack_SetAutoupdate_Call) RunAndReturn(run func(enabled bool) error
 	return _c
 }
 
+// SetAutoupdateBandwidthLimitKB provides a mock function for the type Knapsack
+func (_mock *Knapsack) SetAutoupdateBandwidthLimitKB(limit int) error {
+	ret := _mock.Called(limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetAutoupdateBandwidthLimitKB")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(int) error); ok {
+		r0 = returnFunc(limit)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// Knapsack_SetAutoupdateBandwidthLimitKB_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetAutoupdateBandwidthLimitKB'
+type Knapsack_SetAutoupdateBandwidthLimitKB_Call struct {
+	*mock.Call
+}
+
+// SetAutoupdateBandwidthLimitKB is a helper method to define mock.On call
+//   - limit int
+func (_e *Knapsack_Expecter) SetAutoupdateBandwidthLimitKB(limit interface{}) *Knapsack_SetAutoupdateBandwidthLimitKB_Call {
+	return &Knapsack_SetAutoupdateBandwidthLimitKB_Call{Call: _e.mock.On("SetAutoupdateBandwidthLimitKB", limit)}
+}
+
+func (_c *Knapsack_SetAutoupdateBandwidthLimitKB_Call) Run(run func(limit int)) *Knapsack_SetAutoupdateBandwidthLimitKB_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 int
+		if args[0] != nil {
+			arg0 = args[0].(int)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *Knapsack_SetAutoupdateBandwidthLimitKB_Call) Return(err error) *Knapsack_SetAutoupdateBandwidthLimitKB_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *Knapsack_SetAutoupdateBandwidthLimitKB_Call) RunAndReturn(run func(limit int) error) *Knapsack_SetAutoupdateBandwidthLimitKB_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // SetAutoupdateDownloadSplay provides a mock function for the type Knapsack
 func (_mock *Knapsack) SetAutoupdateDownloadSplay(val time.Duration) error {
 	ret := _mock.Called(val)
@@ -4736,34 +5780,284 @@ func (_mock *Knapsack) SetControlRequestInterval(interval time.Duration) error {
 	ret := _mock.Called(interval)
 
 	if len(ret) == 0 {
-		panic("no return value specified for SetControlRequestInterval")
+		panic("no return value specified for SetControlRequestInterval")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(time.Duration) error); ok {
+		r0 = returnFunc(interval)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// Knapsack_SetControlRequestInterval_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetControlRequestInterval'
+type Knapsack_SetControlRequestInterval_Call struct {
+	*mock.Call
+}
+
+// SetControlRequestInterval is a helper method to define mock.On call
+//   - interval time.Duration
+func (_e *Knapsack_Expecter) SetControlRequestInterval(interval interface{}) *Knapsack_SetControlRequestInterval_Call {
+	return &Knapsack_SetControlRequestInterval_Call{Call: _e.mock.On("SetControlRequestInterval", interval)}
+}
+
+func (_c *Knapsack_SetControlRequestInterval_Call) Run(run func(interval time.Duration)) *Knapsack_SetControlRequestInterval_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 time.Duration
+		if args[0] != nil {
+			arg0 = args[0].(time.Duration)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *Knapsack_SetControlRequestInterval_Call) Return(err error) *Knapsack_SetControlRequestInterval_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *Knapsack_SetControlRequestInterval_Call) RunAndReturn(run func(interval time.Duration) error) *Knapsack_SetControlRequestInterval_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetControlRequestIntervalOverride provides a mock function for the type Knapsack
+func (_mock *Knapsack) SetControlRequestIntervalOverride(value time.Duration, duration time.Duration) {
+	_mock.Called(value, duration)
+	return
+}
+
+// Knapsack_SetControlRequestIntervalOverride_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetControlRequestIntervalOverride'
+type Knapsack_SetControlRequestIntervalOverride_Call struct {
+	*mock.Call
+}
+
+// SetControlRequestIntervalOverride is a helper method to define mock.On call
+//   - value time.Duration
+//   - duration time.Duration
+func (_e *Knapsack_Expecter) SetControlRequestIntervalOverride(value interface{}, duration interface{}) *Knapsack_SetControlRequestIntervalOverride_Call {
+	return &Knapsack_SetControlRequestIntervalOverride_Call{Call: _e.mock.On("SetControlRequestIntervalOverride", value, duration)}
+}
+
+func (_c *Knapsack_SetControlRequestIntervalOverride_Call) Run(run func(value time.Duration, duration time.Duration)) *Knapsack_SetControlRequestIntervalOverride_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 time.Duration
+		if args[0] != nil {
+			arg0 = args[0].(time.Duration)
+		}
+		var arg1 time.Duration
+		if args[1] != nil {
+			arg1 = args[1].(time.Duration)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *Knapsack_SetControlRequestIntervalOverride_Call) Return() *Knapsack_SetControlRequestIntervalOverride_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *Knapsack_SetControlRequestIntervalOverride_Call) RunAndReturn(run func(value time.Duration, duration time.Duration)) *Knapsack_SetControlRequestIntervalOverride_Call {
+	_c.Run(run)
+	return _c
+}
+
+// SetControlServerURL provides a mock function for the type Knapsack
+func (_mock *Knapsack) SetControlServerURL(url string) error {
+	ret := _mock.Called(url)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetControlServerURL")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(string) error); ok {
+		r0 = returnFunc(url)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// Knapsack_SetControlServerURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetControlServerURL'
+type Knapsack_SetControlServerURL_Call struct {
+	*mock.Call
+}
+
+// SetControlServerURL is a helper method to define mock.On call
+//   - url string
+func (_e *Knapsack_Expecter) SetControlServerURL(url interface{}) *Knapsack_SetControlServerURL_Call {
+	return &Knapsack_SetControlServerURL_Call{Call: _e.mock.On("SetControlServerURL", url)}
+}
+
+func (_c *Knapsack_SetControlServerURL_Call) Run(run func(url string)) *Knapsack_SetControlServerURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *Knapsack_SetControlServerURL_Call) Return(err error) *Knapsack_SetControlServerURL_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *Knapsack_SetControlServerURL_Call) RunAndReturn(run func(url string) error) *Knapsack_SetControlServerURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetCurrentRunningOsqueryVersion provides a mock function for the type Knapsack
+func (_mock *Knapsack) SetCurrentRunningOsqueryVersion(version string) error {
+	ret := _mock.Called(version)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetCurrentRunningOsqueryVersion")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(string) error); ok {
+		r0 = returnFunc(version)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// Knapsack_SetCurrentRunningOsqueryVersion_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetCurrentRunningOsqueryVersion'
+type Knapsack_SetCurrentRunningOsqueryVersion_Call struct {
+	*mock.Call
+}
+
+// SetCurrentRunningOsqueryVersion is a helper method to define mock.On call
+//   - version string
+func (_e *Knapsack_Expecter) SetCurrentRunningOsqueryVersion(version interface{}) *Knapsack_SetCurrentRunningOsqueryVersion_Call {
+	return &Knapsack_SetCurrentRunningOsqueryVersion_Call{Call: _e.mock.On("SetCurrentRunningOsqueryVersion", version)}
+}
+
+func (_c *Knapsack_SetCurrentRunningOsqueryVersion_Call) Run(run func(version string)) *Knapsack_SetCurrentRunningOsqueryVersion_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *Knapsack_SetCurrentRunningOsqueryVersion_Call) Return(err error) *Knapsack_SetCurrentRunningOsqueryVersion_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *Knapsack_SetCurrentRunningOsqueryVersion_Call) RunAndReturn(run func(version string) error) *Knapsack_SetCurrentRunningOsqueryVersion_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetCustomerOTLPCAPath provides a mock function for the type Knapsack
+func (_mock *Knapsack) SetCustomerOTLPCAPath(path string) error {
+	ret := _mock.Called(path)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetCustomerOTLPCAPath")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(string) error); ok {
+		r0 = returnFunc(path)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// Knapsack_SetCustomerOTLPCAPath_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetCustomerOTLPCAPath'
+type Knapsack_SetCustomerOTLPCAPath_Call struct {
+	*mock.Call
+}
+
+// SetCustomerOTLPCAPath is a helper method to define mock.On call
+//   - path string
+func (_e *Knapsack_Expecter) SetCustomerOTLPCAPath(path interface{}) *Knapsack_SetCustomerOTLPCAPath_Call {
+	return &Knapsack_SetCustomerOTLPCAPath_Call{Call: _e.mock.On("SetCustomerOTLPCAPath", path)}
+}
+
+func (_c *Knapsack_SetCustomerOTLPCAPath_Call) Run(run func(path string)) *Knapsack_SetCustomerOTLPCAPath_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *Knapsack_SetCustomerOTLPCAPath_Call) Return(_a error) *Knapsack_SetCustomerOTLPCAPath_Call {
+	_c.Call.Return(_a)
+	return _c
+}
+
+func (_c *Knapsack_SetCustomerOTLPCAPath_Call) RunAndReturn(run func(path string) error) *Knapsack_SetCustomerOTLPCAPath_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetCustomerOTLPEndpoint provides a mock function for the type Knapsack
+func (_mock *Knapsack) SetCustomerOTLPEndpoint(endpoint string) error {
+	ret := _mock.Called(endpoint)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetCustomerOTLPEndpoint")
 	}
 
 	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(time.Duration) error); ok {
-		r0 = returnFunc(interval)
+	if returnFunc, ok := ret.Get(0).(func(string) error); ok {
+		r0 = returnFunc(endpoint)
 	} else {
 		r0 = ret.Error(0)
 	}
 	return r0
 }
 
-// Knapsack_SetControlRequestInterval_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetControlRequestInterval'
-type Knapsack_SetControlRequestInterval_Call struct {
+// Knapsack_SetCustomerOTLPEndpoint_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetCustomerOTLPEndpoint'
+type Knapsack_SetCustomerOTLPEndpoint_Call struct {
 	*mock.Call
 }
 
-// SetControlRequestInterval is a helper method to define mock.On call
-//   - interval time.Duration
-func (_e *Knapsack_Expecter) SetControlRequestInterval(interval interface{}) *Knapsack_SetControlRequestInterval_Call {
-	return &Knapsack_SetControlRequestInterval_Call{Call: _e.mock.On("SetControlRequestInterval", interval)}
+// SetCustomerOTLPEndpoint is a helper method to define mock.On call
+//   - endpoint string
+func (_e *Knapsack_Expecter) SetCustomerOTLPEndpoint(endpoint interface{}) *Knapsack_SetCustomerOTLPEndpoint_Call {
+	return &Knapsack_SetCustomerOTLPEndpoint_Call{Call: _e.mock.On("SetCustomerOTLPEndpoint", endpoint)}
 }
 
-func (_c *Knapsack_SetControlRequestInterval_Call) Run(run func(interval time.Duration)) *Knapsack_SetControlRequestInterval_Call {
+func (_c *Knapsack_SetCustomerOTLPEndpoint_Call) Run(run func(endpoint string)) *Knapsack_SetCustomerOTLPEndpoint_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		var arg0 time.Duration
+		var arg0 string
 		if args[0] != nil {
-			arg0 = args[0].(time.Duration)
+			arg0 = args[0].(string)
 		}
 		run(
 			arg0,
@@ -4772,95 +6066,100 @@ func (_c *Knapsack_SetControlRequestInterval_Call) Run(run func(interval time.Du
 	return _c
 }
 
-func (_c *Knapsack_SetControlRequestInterval_Call) Return(err error) *Knapsack_SetControlRequestInterval_Call {
-	_c.Call.Return(err)
+func (_c *Knapsack_SetCustomerOTLPEndpoint_Call) Return(_a error) *Knapsack_SetCustomerOTLPEndpoint_Call {
+	_c.Call.Return(_a)
 	return _c
 }
 
-func (_c *Knapsack_SetControlRequestInterval_Call) RunAndReturn(run func(interval time.Duration) error) *Knapsack_SetControlRequestInterval_Call {
+func (_c *Knapsack_SetCustomerOTLPEndpoint_Call) RunAndReturn(run func(endpoint string) error) *Knapsack_SetCustomerOTLPEndpoint_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// SetControlRequestIntervalOverride provides a mock function for the type Knapsack
-func (_mock *Knapsack) SetControlRequestIntervalOverride(value time.Duration, duration time.Duration) {
-	_mock.Called(value, duration)
-	return
+// SetCustomerOTLPHeaders provides a mock function for the type Knapsack
+func (_mock *Knapsack) SetCustomerOTLPHeaders(headers string) error {
+	ret := _mock.Called(headers)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetCustomerOTLPHeaders")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(string) error); ok {
+		r0 = returnFunc(headers)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
 }
 
-// Knapsack_SetControlRequestIntervalOverride_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetControlRequestIntervalOverride'
-type Knapsack_SetControlRequestIntervalOverride_Call struct {
+// Knapsack_SetCustomerOTLPHeaders_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetCustomerOTLPHeaders'
+type Knapsack_SetCustomerOTLPHeaders_Call struct {
 	*mock.Call
 }
 
-// SetControlRequestIntervalOverride is a helper method to define mock.On call
-//   - value time.Duration
-//   - duration time.Duration
-func (_e *Knapsack_Expecter) SetControlRequestIntervalOverride(value interface{}, duration interface{}) *Knapsack_SetControlRequestIntervalOverride_Call {
-	return &Knapsack_SetControlRequestIntervalOverride_Call{Call: _e.mock.On("SetControlRequestIntervalOverride", value, duration)}
+// SetCustomerOTLPHeaders is a helper method to define mock.On call
+//   - headers string
+func (_e *Knapsack_Expecter) SetCustomerOTLPHeaders(headers interface{}) *Knapsack_SetCustomerOTLPHeaders_Call {
+	return &Knapsack_SetCustomerOTLPHeaders_Call{Call: _e.mock.On("SetCustomerOTLPHeaders", headers)}
 }
 
-func (_c *Knapsack_SetControlRequestIntervalOverride_Call) Run(run func(value time.Duration, duration time.Duration)) *Knapsack_SetControlRequestIntervalOverride_Call {
+func (_c *Knapsack_SetCustomerOTLPHeaders_Call) Run(run func(headers string)) *Knapsack_SetCustomerOTLPHeaders_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		var arg0 time.Duration
+		var arg0 string
 		if args[0] != nil {
-			arg0 = args[0].(time.Duration)
-		}
-		var arg1 time.Duration
-		if args[1] != nil {
-			arg1 = args[1].(time.Duration)
+			arg0 = args[0].(string)
 		}
 		run(
 			arg0,
-			arg1,
 		)
 	})
 	return _c
 }
 
-func (_c *Knapsack_SetControlRequestIntervalOverride_Call) Return() *Knapsack_SetControlRequestIntervalOverride_Call {
-	_c.Call.Return()
+func (_c *Knapsack_SetCustomerOTLPHeaders_Call) Return(_a error) *Knapsack_SetCustomerOTLPHeaders_Call {
+	_c.Call.Return(_a)
 	return _c
 }
 
-func (_c *Knapsack_SetControlRequestIntervalOverride_Call) RunAndReturn(run func(value time.Duration, duration time.Duration)) *Knapsack_SetControlRequestIntervalOverride_Call {
-	_c.Run(run)
+func (_c *Knapsack_SetCustomerOTLPHeaders_Call) RunAndReturn(run func(headers string) error) *Knapsack_SetCustomerOTLPHeaders_Call {
+	_c.Call.Return(run)
 	return _c
 }
 
-// SetControlServerURL provides a mock function for the type Knapsack
-func (_mock *Knapsack) SetControlServerURL(url string) error {
-	ret := _mock.Called(url)
+// SetCustomerOTLPOnly provides a mock function for the type Knapsack
+func (_mock *Knapsack) SetCustomerOTLPOnly(only bool) error {
+	ret := _mock.Called(only)
 
 	if len(ret) == 0 {
-		panic("no return value specified for SetControlServerURL")
+		panic("no return value specified for SetCustomerOTLPOnly")
 	}
 
 	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(string) error); ok {
-		r0 = returnFunc(url)
+	if returnFunc, ok := ret.Get(0).(func(bool) error); ok {
+		r0 = returnFunc(only)
 	} else {
 		r0 = ret.Error(0)
 	}
 	return r0
 }
 
-// Knapsack_SetControlServerURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetControlServerURL'
-type Knapsack_SetControlServerURL_Call struct {
+// Knapsack_SetCustomerOTLPOnly_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetCustomerOTLPOnly'
+type Knapsack_SetCustomerOTLPOnly_Call struct {
 	*mock.Call
 }
 
-// SetControlServerURL is a helper method to define mock.On call
-//   - url string
-func (_e *Knapsack_Expecter) SetControlServerURL(url interface{}) *Knapsack_SetControlServerURL_Call {
-	return &Knapsack_SetControlServerURL_Call{Call: _e.mock.On("SetControlServerURL", url)}
+// SetCustomerOTLPOnly is a helper method to define mock.On call
+//   - only bool
+func (_e *Knapsack_Expecter) SetCustomerOTLPOnly(only interface{}) *Knapsack_SetCustomerOTLPOnly_Call {
+	return &Knapsack_SetCustomerOTLPOnly_Call{Call: _e.mock.On("SetCustomerOTLPOnly", only)}
 }
 
-func (_c *Knapsack_SetControlServerURL_Call) Run(run func(url string)) *Knapsack_SetControlServerURL_Call {
+func (_c *Knapsack_SetCustomerOTLPOnly_Call) Run(run func(only bool)) *Knapsack_SetCustomerOTLPOnly_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		var arg0 string
+		var arg0 bool
 		if args[0] != nil {
-			arg0 = args[0].(string)
+			arg0 = args[0].(bool)
 		}
 		run(
 			arg0,
@@ -4869,49 +6168,49 @@ func (_c *Knapsack_SetControlServerURL_Call) Run(run func(url string)) *Knapsack
 	return _c
 }
 
-func (_c *Knapsack_SetControlServerURL_Call) Return(err error) *Knapsack_SetControlServerURL_Call {
-	_c.Call.Return(err)
+func (_c *Knapsack_SetCustomerOTLPOnly_Call) Return(_a error) *Knapsack_SetCustomerOTLPOnly_Call {
+	_c.Call.Return(_a)
 	return _c
 }
 
-func (_c *Knapsack_SetControlServerURL_Call) RunAndReturn(run func(url string) error) *Knapsack_SetControlServerURL_Call {
+func (_c *Knapsack_SetCustomerOTLPOnly_Call) RunAndReturn(run func(only bool) error) *Knapsack_SetCustomerOTLPOnly_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// SetCurrentRunningOsqueryVersion provides a mock function for the type Knapsack
-func (_mock *Knapsack) SetCurrentRunningOsqueryVersion(version string) error {
-	ret := _mock.Called(version)
+// SetCustomerOTLPSamplingRate provides a mock function for the type Knapsack
+func (_mock *Knapsack) SetCustomerOTLPSamplingRate(rate float64) error {
+	ret := _mock.Called(rate)
 
 	if len(ret) == 0 {
-		panic("no return value specified for SetCurrentRunningOsqueryVersion")
+		panic("no return value specified for SetCustomerOTLPSamplingRate")
 	}
 
 	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(string) error); ok {
-		r0 = returnFunc(version)
+	if returnFunc, ok := ret.Get(0).(func(float64) error); ok {
+		r0 = returnFunc(rate)
 	} else {
 		r0 = ret.Error(0)
 	}
 	return r0
 }
 
-// Knapsack_SetCurrentRunningOsqueryVersion_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetCurrentRunningOsqueryVersion'
-type Knapsack_SetCurrentRunningOsqueryVersion_Call struct {
+// Knapsack_SetCustomerOTLPSamplingRate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetCustomerOTLPSamplingRate'
+type Knapsack_SetCustomerOTLPSamplingRate_Call struct {
 	*mock.Call
 }
 
-// SetCurrentRunningOsqueryVersion is a helper method to define mock.On call
-//   - version string
-func (_e *Knapsack_Expecter) SetCurrentRunningOsqueryVersion(version interface{}) *Knapsack_SetCurrentRunningOsqueryVersion_Call {
-	return &Knapsack_SetCurrentRunningOsqueryVersion_Call{Call: _e.mock.On("SetCurrentRunningOsqueryVersion", version)}
+// SetCustomerOTLPSamplingRate is a helper method to define mock.On call
+//   - rate float64
+func (_e *Knapsack_Expecter) SetCustomerOTLPSamplingRate(rate interface{}) *Knapsack_SetCustomerOTLPSamplingRate_Call {
+	return &Knapsack_SetCustomerOTLPSamplingRate_Call{Call: _e.mock.On("SetCustomerOTLPSamplingRate", rate)}
 }
 
-func (_c *Knapsack_SetCurrentRunningOsqueryVersion_Call) Run(run func(version string)) *Knapsack_SetCurrentRunningOsqueryVersion_Call {
+func (_c *Knapsack_SetCustomerOTLPSamplingRate_Call) Run(run func(rate float64)) *Knapsack_SetCustomerOTLPSamplingRate_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		var arg0 string
+		var arg0 float64
 		if args[0] != nil {
-			arg0 = args[0].(string)
+			arg0 = args[0].(float64)
 		}
 		run(
 			arg0,
@@ -4920,12 +6219,12 @@ func (_c *Knapsack_SetCurrentRunningOsqueryVersion_Call) Run(run func(version st
 	return _c
 }
 
-func (_c *Knapsack_SetCurrentRunningOsqueryVersion_Call) Return(err error) *Knapsack_SetCurrentRunningOsqueryVersion_Call {
-	_c.Call.Return(err)
+func (_c *Knapsack_SetCustomerOTLPSamplingRate_Call) Return(_a error) *Knapsack_SetCustomerOTLPSamplingRate_Call {
+	_c.Call.Return(_a)
 	return _c
 }
 
-func (_c *Knapsack_SetCurrentRunningOsqueryVersion_Call) RunAndReturn(run func(version string) error) *Knapsack_SetCurrentRunningOsqueryVersion_Call {
+func (_c *Knapsack_SetCustomerOTLPSamplingRate_Call) RunAndReturn(run func(rate float64) error) *Knapsack_SetCustomerOTLPSamplingRate_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -5327,6 +6626,57 @@ func (_c *Knapsack_SetDisableControlTLS_Call) RunAndReturn(run func(disabled boo
 	return _c
 }
 
+// SetDisableCustomerOTLPTLS provides a mock function for the type Knapsack
+func (_mock *Knapsack) SetDisableCustomerOTLPTLS(disabled bool) error {
+	ret := _mock.Called(disabled)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetDisableCustomerOTLPTLS")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(bool) error); ok {
+		r0 = returnFunc(disabled)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// Knapsack_SetDisableCustomerOTLPTLS_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetDisableCustomerOTLPTLS'
+type Knapsack_SetDisableCustomerOTLPTLS_Call struct {
+	*mock.Call
+}
+
+// SetDisableCustomerOTLPTLS is a helper method to define mock.On call
+//   - disabled bool
+func (_e *Knapsack_Expecter) SetDisableCustomerOTLPTLS(disabled interface{}) *Knapsack_SetDisableCustomerOTLPTLS_Call {
+	return &Knapsack_SetDisableCustomerOTLPTLS_Call{Call: _e.mock.On("SetDisableCustomerOTLPTLS", disabled)}
+}
+
+func (_c *Knapsack_SetDisableCustomerOTLPTLS_Call) Run(run func(disabled bool)) *Knapsack_SetDisableCustomerOTLPTLS_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 bool
+		if args[0] != nil {
+			arg0 = args[0].(bool)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *Knapsack_SetDisableCustomerOTLPTLS_Call) Return(_a error) *Knapsack_SetDisableCustomerOTLPTLS_Call {
+	_c.Call.Return(_a)
+	return _c
+}
+
+func (_c *Knapsack_SetDisableCustomerOTLPTLS_Call) RunAndReturn(run func(disabled bool) error) *Knapsack_SetDisableCustomerOTLPTLS_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // SetDisableTraceIngestTLS provides a mock function for the type Knapsack
 func (_mock *Knapsack) SetDisableTraceIngestTLS(enabled bool) error {
 	ret := _mock.Called(enabled)
@@ -5526,6 +6876,57 @@ func (_c *Knapsack_SetDuplicateLogWindow_Call) RunAndReturn(run func(duration ti
 	return _c
 }
 
+// SetDuplicateLogWindowOverrides provides a mock function for the type Knapsack
+func (_mock *Knapsack) SetDuplicateLogWindowOverrides(overrides string) error {
+	ret := _mock.Called(overrides)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetDuplicateLogWindowOverrides")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(string) error); ok {
+		r0 = returnFunc(overrides)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// Knapsack_SetDuplicateLogWindowOverrides_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetDuplicateLogWindowOverrides'
+type Knapsack_SetDuplicateLogWindowOverrides_Call struct {
+	*mock.Call
+}
+
+// SetDuplicateLogWindowOverrides is a helper method to define mock.On call
+//   - overrides string
+func (_e *Knapsack_Expecter) SetDuplicateLogWindowOverrides(overrides interface{}) *Knapsack_SetDuplicateLogWindowOverrides_Call {
+	return &Knapsack_SetDuplicateLogWindowOverrides_Call{Call: _e.mock.On("SetDuplicateLogWindowOverrides", overrides)}
+}
+
+func (_c *Knapsack_SetDuplicateLogWindowOverrides_Call) Run(run func(overrides string)) *Knapsack_SetDuplicateLogWindowOverrides_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *Knapsack_SetDuplicateLogWindowOverrides_Call) Return(r0 error) *Knapsack_SetDuplicateLogWindowOverrides_Call {
+	_c.Call.Return(r0)
+	return _c
+}
+
+func (_c *Knapsack_SetDuplicateLogWindowOverrides_Call) RunAndReturn(run func(overrides string) error) *Knapsack_SetDuplicateLogWindowOverrides_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // SetEnrollmentDetails provides a mock function for the type Knapsack
 func (_mock *Knapsack) SetEnrollmentDetails(details types.EnrollmentDetails) {
 	_mock.Called(details)
@@ -5612,54 +7013,156 @@ func (_c *Knapsack_SetExportTraces_Call) Return(err error) *Knapsack_SetExportTr
 	return _c
 }
 
-func (_c *Knapsack_SetExportTraces_Call) RunAndReturn(run func(enabled bool) error) *Knapsack_SetExportTraces_Call {
+func (_c *Knapsack_SetExportTraces_Call) RunAndReturn(run func(enabled bool) error) *Knapsack_SetExportTraces_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetExportTracesOverride provides a mock function for the type Knapsack
+func (_mock *Knapsack) SetExportTracesOverride(value bool, duration time.Duration) {
+	_mock.Called(value, duration)
+	return
+}
+
+// Knapsack_SetExportTracesOverride_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetExportTracesOverride'
+type Knapsack_SetExportTracesOverride_Call struct {
+	*mock.Call
+}
+
+// SetExportTracesOverride is a helper method to define mock.On call
+//   - value bool
+//   - duration time.Duration
+func (_e *Knapsack_Expecter) SetExportTracesOverride(value interface{}, duration interface{}) *Knapsack_SetExportTracesOverride_Call {
+	return &Knapsack_SetExportTracesOverride_Call{Call: _e.mock.On("SetExportTracesOverride", value, duration)}
+}
+
+func (_c *Knapsack_SetExportTracesOverride_Call) Run(run func(value bool, duration time.Duration)) *Knapsack_SetExportTracesOverride_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 bool
+		if args[0] != nil {
+			arg0 = args[0].(bool)
+		}
+		var arg1 time.Duration
+		if args[1] != nil {
+			arg1 = args[1].(time.Duration)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *Knapsack_SetExportTracesOverride_Call) Return() *Knapsack_SetExportTracesOverride_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *Knapsack_SetExportTracesOverride_Call) RunAndReturn(run func(value bool, duration time.Duration)) *Knapsack_SetExportTracesOverride_Call {
+	_c.Run(run)
+	return _c
+}
+
+// SetExternalCheckups provides a mock function for the type Knapsack
+func (_mock *Knapsack) SetExternalCheckups(definitions string) error {
+	ret := _mock.Called(definitions)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetExternalCheckups")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(string) error); ok {
+		r0 = returnFunc(definitions)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// Knapsack_SetExternalCheckups_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetExternalCheckups'
+type Knapsack_SetExternalCheckups_Call struct {
+	*mock.Call
+}
+
+// SetExternalCheckups is a helper method to define mock.On call
+//   - definitions string
+func (_e *Knapsack_Expecter) SetExternalCheckups(definitions interface{}) *Knapsack_SetExternalCheckups_Call {
+	return &Knapsack_SetExternalCheckups_Call{Call: _e.mock.On("SetExternalCheckups", definitions)}
+}
+
+func (_c *Knapsack_SetExternalCheckups_Call) Run(run func(definitions string)) *Knapsack_SetExternalCheckups_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *Knapsack_SetExternalCheckups_Call) Return(_a error) *Knapsack_SetExternalCheckups_Call {
+	_c.Call.Return(_a)
+	return _c
+}
+
+func (_c *Knapsack_SetExternalCheckups_Call) RunAndReturn(run func(definitions string) error) *Knapsack_SetExternalCheckups_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// SetExportTracesOverride provides a mock function for the type Knapsack
-func (_mock *Knapsack) SetExportTracesOverride(value bool, duration time.Duration) {
-	_mock.Called(value, duration)
-	return
+// SetFlareRedactionRules provides a mock function for the type Knapsack
+func (_mock *Knapsack) SetFlareRedactionRules(rules string) error {
+	ret := _mock.Called(rules)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetFlareRedactionRules")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(string) error); ok {
+		r0 = returnFunc(rules)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
 }
 
-// Knapsack_SetExportTracesOverride_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetExportTracesOverride'
-type Knapsack_SetExportTracesOverride_Call struct {
+// Knapsack_SetFlareRedactionRules_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetFlareRedactionRules'
+type Knapsack_SetFlareRedactionRules_Call struct {
 	*mock.Call
 }
 
-// SetExportTracesOverride is a helper method to define mock.On call
-//   - value bool
-//   - duration time.Duration
-func (_e *Knapsack_Expecter) SetExportTracesOverride(value interface{}, duration interface{}) *Knapsack_SetExportTracesOverride_Call {
-	return &Knapsack_SetExportTracesOverride_Call{Call: _e.mock.On("SetExportTracesOverride", value, duration)}
+// SetFlareRedactionRules is a helper method to define mock.On call
+//   - rules string
+func (_e *Knapsack_Expecter) SetFlareRedactionRules(rules interface{}) *Knapsack_SetFlareRedactionRules_Call {
+	return &Knapsack_SetFlareRedactionRules_Call{Call: _e.mock.On("SetFlareRedactionRules", rules)}
 }
 
-func (_c *Knapsack_SetExportTracesOverride_Call) Run(run func(value bool, duration time.Duration)) *Knapsack_SetExportTracesOverride_Call {
+func (_c *Knapsack_SetFlareRedactionRules_Call) Run(run func(rules string)) *Knapsack_SetFlareRedactionRules_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		var arg0 bool
+		var arg0 string
 		if args[0] != nil {
-			arg0 = args[0].(bool)
-		}
-		var arg1 time.Duration
-		if args[1] != nil {
-			arg1 = args[1].(time.Duration)
+			arg0 = args[0].(string)
 		}
 		run(
 			arg0,
-			arg1,
 		)
 	})
 	return _c
 }
 
-func (_c *Knapsack_SetExportTracesOverride_Call) Return() *Knapsack_SetExportTracesOverride_Call {
-	_c.Call.Return()
+func (_c *Knapsack_SetFlareRedactionRules_Call) Return(_a error) *Knapsack_SetFlareRedactionRules_Call {
+	_c.Call.Return(_a)
 	return _c
 }
 
-func (_c *Knapsack_SetExportTracesOverride_Call) RunAndReturn(run func(value bool, duration time.Duration)) *Knapsack_SetExportTracesOverride_Call {
-	_c.Run(run)
+func (_c *Knapsack_SetFlareRedactionRules_Call) RunAndReturn(run func(rules string) error) *Knapsack_SetFlareRedactionRules_Call {
+	_c.Call.Return(run)
 	return _c
 }
 
@@ -5714,6 +7217,57 @@ func (_c *Knapsack_SetForceControlSubsystems_Call) RunAndReturn(run func(force b
 	return _c
 }
 
+// SetHardwareKeyRotationInterval provides a mock function for the type Knapsack
+func (_mock *Knapsack) SetHardwareKeyRotationInterval(interval time.Duration) error {
+	ret := _mock.Called(interval)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetHardwareKeyRotationInterval")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(time.Duration) error); ok {
+		r0 = returnFunc(interval)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// Knapsack_SetHardwareKeyRotationInterval_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetHardwareKeyRotationInterval'
+type Knapsack_SetHardwareKeyRotationInterval_Call struct {
+	*mock.Call
+}
+
+// SetHardwareKeyRotationInterval is a helper method to define mock.On call
+//   - interval time.Duration
+func (_e *Knapsack_Expecter) SetHardwareKeyRotationInterval(interval interface{}) *Knapsack_SetHardwareKeyRotationInterval_Call {
+	return &Knapsack_SetHardwareKeyRotationInterval_Call{Call: _e.mock.On("SetHardwareKeyRotationInterval", interval)}
+}
+
+func (_c *Knapsack_SetHardwareKeyRotationInterval_Call) Run(run func(interval time.Duration)) *Knapsack_SetHardwareKeyRotationInterval_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 time.Duration
+		if args[0] != nil {
+			arg0 = args[0].(time.Duration)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *Knapsack_SetHardwareKeyRotationInterval_Call) Return(r0 error) *Knapsack_SetHardwareKeyRotationInterval_Call {
+	_c.Call.Return(r0)
+	return _c
+}
+
+func (_c *Knapsack_SetHardwareKeyRotationInterval_Call) RunAndReturn(run func(interval time.Duration) error) *Knapsack_SetHardwareKeyRotationInterval_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // SetInModernStandby provides a mock function for the type Knapsack
 func (_mock *Knapsack) SetInModernStandby(enabled bool) error {
 	ret := _mock.Called(enabled)
@@ -6009,6 +7563,108 @@ func (_c *Knapsack_SetKolideServerURL_Call) RunAndReturn(run func(url string) er
 	return _c
 }
 
+// SetLanCacheListenAddress provides a mock function for the type Knapsack
+func (_mock *Knapsack) SetLanCacheListenAddress(address string) error {
+	ret := _mock.Called(address)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetLanCacheListenAddress")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(string) error); ok {
+		r0 = returnFunc(address)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// Knapsack_SetLanCacheListenAddress_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetLanCacheListenAddress'
+type Knapsack_SetLanCacheListenAddress_Call struct {
+	*mock.Call
+}
+
+// SetLanCacheListenAddress is a helper method to define mock.On call
+//   - address string
+func (_e *Knapsack_Expecter) SetLanCacheListenAddress(address interface{}) *Knapsack_SetLanCacheListenAddress_Call {
+	return &Knapsack_SetLanCacheListenAddress_Call{Call: _e.mock.On("SetLanCacheListenAddress", address)}
+}
+
+func (_c *Knapsack_SetLanCacheListenAddress_Call) Run(run func(address string)) *Knapsack_SetLanCacheListenAddress_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *Knapsack_SetLanCacheListenAddress_Call) Return(err error) *Knapsack_SetLanCacheListenAddress_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *Knapsack_SetLanCacheListenAddress_Call) RunAndReturn(run func(address string) error) *Knapsack_SetLanCacheListenAddress_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetLanCachePeers provides a mock function for the type Knapsack
+func (_mock *Knapsack) SetLanCachePeers(peers string) error {
+	ret := _mock.Called(peers)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetLanCachePeers")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(string) error); ok {
+		r0 = returnFunc(peers)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// Knapsack_SetLanCachePeers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetLanCachePeers'
+type Knapsack_SetLanCachePeers_Call struct {
+	*mock.Call
+}
+
+// SetLanCachePeers is a helper method to define mock.On call
+//   - peers string
+func (_e *Knapsack_Expecter) SetLanCachePeers(peers interface{}) *Knapsack_SetLanCachePeers_Call {
+	return &Knapsack_SetLanCachePeers_Call{Call: _e.mock.On("SetLanCachePeers", peers)}
+}
+
+func (_c *Knapsack_SetLanCachePeers_Call) Run(run func(peers string)) *Knapsack_SetLanCachePeers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *Knapsack_SetLanCachePeers_Call) Return(err error) *Knapsack_SetLanCachePeers_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *Knapsack_SetLanCachePeers_Call) RunAndReturn(run func(peers string) error) *Knapsack_SetLanCachePeers_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // SetLauncherGoMaxProcs provides a mock function for the type Knapsack
 func (_mock *Knapsack) SetLauncherGoMaxProcs(maxProcs int) error {
 	ret := _mock.Called(maxProcs)
@@ -6787,6 +8443,52 @@ func (_c *Knapsack_SetPinnedLauncherVersion_Call) RunAndReturn(run func(version
 	return _c
 }
 
+// SetPinnedLauncherVersionOverride provides a mock function for the type Knapsack
+func (_mock *Knapsack) SetPinnedLauncherVersionOverride(version string, duration time.Duration) {
+	_mock.Called(version, duration)
+	return
+}
+
+// Knapsack_SetPinnedLauncherVersionOverride_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetPinnedLauncherVersionOverride'
+type Knapsack_SetPinnedLauncherVersionOverride_Call struct {
+	*mock.Call
+}
+
+// SetPinnedLauncherVersionOverride is a helper method to define mock.On call
+//   - version string
+//   - duration time.Duration
+func (_e *Knapsack_Expecter) SetPinnedLauncherVersionOverride(version interface{}, duration interface{}) *Knapsack_SetPinnedLauncherVersionOverride_Call {
+	return &Knapsack_SetPinnedLauncherVersionOverride_Call{Call: _e.mock.On("SetPinnedLauncherVersionOverride", version, duration)}
+}
+
+func (_c *Knapsack_SetPinnedLauncherVersionOverride_Call) Run(run func(version string, duration time.Duration)) *Knapsack_SetPinnedLauncherVersionOverride_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		var arg1 time.Duration
+		if args[1] != nil {
+			arg1 = args[1].(time.Duration)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *Knapsack_SetPinnedLauncherVersionOverride_Call) Return() *Knapsack_SetPinnedLauncherVersionOverride_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *Knapsack_SetPinnedLauncherVersionOverride_Call) RunAndReturn(run func(version string, duration time.Duration)) *Knapsack_SetPinnedLauncherVersionOverride_Call {
+	_c.Run(run)
+	return _c
+}
+
 // SetPinnedOsquerydVersion provides a mock function for the type Knapsack
 func (_mock *Knapsack) SetPinnedOsquerydVersion(version string) error {
 	ret := _mock.Called(version)
@@ -6838,6 +8540,52 @@ func (_c *Knapsack_SetPinnedOsquerydVersion_Call) RunAndReturn(run func(version
 	return _c
 }
 
+// SetPinnedOsquerydVersionOverride provides a mock function for the type Knapsack
+func (_mock *Knapsack) SetPinnedOsquerydVersionOverride(version string, duration time.Duration) {
+	_mock.Called(version, duration)
+	return
+}
+
+// Knapsack_SetPinnedOsquerydVersionOverride_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetPinnedOsquerydVersionOverride'
+type Knapsack_SetPinnedOsquerydVersionOverride_Call struct {
+	*mock.Call
+}
+
+// SetPinnedOsquerydVersionOverride is a helper method to define mock.On call
+//   - version string
+//   - duration time.Duration
+func (_e *Knapsack_Expecter) SetPinnedOsquerydVersionOverride(version interface{}, duration interface{}) *Knapsack_SetPinnedOsquerydVersionOverride_Call {
+	return &Knapsack_SetPinnedOsquerydVersionOverride_Call{Call: _e.mock.On("SetPinnedOsquerydVersionOverride", version, duration)}
+}
+
+func (_c *Knapsack_SetPinnedOsquerydVersionOverride_Call) Run(run func(version string, duration time.Duration)) *Knapsack_SetPinnedOsquerydVersionOverride_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		var arg1 time.Duration
+		if args[1] != nil {
+			arg1 = args[1].(time.Duration)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *Knapsack_SetPinnedOsquerydVersionOverride_Call) Return() *Knapsack_SetPinnedOsquerydVersionOverride_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *Knapsack_SetPinnedOsquerydVersionOverride_Call) RunAndReturn(run func(version string, duration time.Duration)) *Knapsack_SetPinnedOsquerydVersionOverride_Call {
+	_c.Run(run)
+	return _c
+}
+
 // SetResetOnHardwareChangeEnabled provides a mock function for the type Knapsack
 func (_mock *Knapsack) SetResetOnHardwareChangeEnabled(enabled bool) error {
 	ret := _mock.Called(enabled)
@@ -7292,6 +9040,52 @@ func (_c *Knapsack_SetUpdateChannel_Call) RunAndReturn(run func(channel string)
 	return _c
 }
 
+// SetUpdateChannelOverride provides a mock function for the type Knapsack
+func (_mock *Knapsack) SetUpdateChannelOverride(channel string, duration time.Duration) {
+	_mock.Called(channel, duration)
+	return
+}
+
+// Knapsack_SetUpdateChannelOverride_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetUpdateChannelOverride'
+type Knapsack_SetUpdateChannelOverride_Call struct {
+	*mock.Call
+}
+
+// SetUpdateChannelOverride is a helper method to define mock.On call
+//   - channel string
+//   - duration time.Duration
+func (_e *Knapsack_Expecter) SetUpdateChannelOverride(channel interface{}, duration interface{}) *Knapsack_SetUpdateChannelOverride_Call {
+	return &Knapsack_SetUpdateChannelOverride_Call{Call: _e.mock.On("SetUpdateChannelOverride", channel, duration)}
+}
+
+func (_c *Knapsack_SetUpdateChannelOverride_Call) Run(run func(channel string, duration time.Duration)) *Knapsack_SetUpdateChannelOverride_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		var arg1 time.Duration
+		if args[1] != nil {
+			arg1 = args[1].(time.Duration)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *Knapsack_SetUpdateChannelOverride_Call) Return() *Knapsack_SetUpdateChannelOverride_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *Knapsack_SetUpdateChannelOverride_Call) RunAndReturn(run func(channel string, duration time.Duration)) *Knapsack_SetUpdateChannelOverride_Call {
+	_c.Run(run)
+	return _c
+}
+
 // SetUpdateDirectory provides a mock function for the type Knapsack
 func (_mock *Knapsack) SetUpdateDirectory(directory string) error {
 	ret := _mock.Called(directory)
@@ -25,4 +25,6 @@ type Stores interface {
 	EnrollmentDetailsStore() KVStore
 	ServerReleaseTrackerDataStore() KVStore
 	LocalizationStore() KVStore
+	QueryProfilesStore() KVStore
+	AutoupdatePinsStore() KVStore
 }
@@ -0,0 +1,131 @@
+package allowedcmd
+
+import (
+	"io"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const maxAuditRecords = 1000
+
+// notWaitedError is recorded for commands that were started, but never waited on, so their result is unknown.
+const notWaitedError = "command was not waited on, result unknown"
+
+// AuditRecord describes a single invocation of an allowed command. Arguments may contain secrets,
+// so only their number is recorded.
+type AuditRecord struct {
+	Timestamp   time.Time
+	Name        string
+	Path        string
+	ArgCount    int
+	Uid         int // -1 when unknown, e.g. on Windows
+	Duration    time.Duration
+	ExitCode    int // -1 when the command did not exit, e.g. it is still running or could not start, or when unknown
+	OutputBytes int64
+	Running     bool
+	Denied      bool
+	Error       string
+}
+
+// auditLog is a bounded, in-memory record of command invocations. Once full, the oldest
+// records are overwritten.
+type auditLog struct {
+	lock    sync.Mutex
+	records []AuditRecord
+	ids     []uint64
+	next    int
+	lastId  uint64
+}
+
+func newAuditLog(size int) *auditLog {
+	return &auditLog{
+		records: make([]AuditRecord, 0, size),
+		ids:     make([]uint64, 0, size),
+	}
+}
+
+// processAuditLog records every command launcher runs.
+var processAuditLog = newAuditLog(maxAuditRecords)
+
+// AuditRecords returns the recorded command invocations, oldest first.
+func AuditRecords() []AuditRecord {
+	return processAuditLog.all()
+}
+
+// add stores the record, returning an ID that can be used to update it later.
+func (a *auditLog) add(record AuditRecord) uint64 {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.lastId++
+	if len(a.records) < cap(a.records) {
+		a.records = append(a.records, record)
+		a.ids = append(a.ids, a.lastId)
+		return a.lastId
+	}
+
+	a.records[a.next] = record
+	a.ids[a.next] = a.lastId
+	a.next = (a.next + 1) % len(a.records)
+	return a.lastId
+}
+
+// update modifies the record with the given ID, if it has not yet been overwritten.
+func (a *auditLog) update(id uint64, updateFunc func(*AuditRecord)) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	for i := range a.ids {
+		if a.ids[i] == id {
+			updateFunc(&a.records[i])
+			return
+		}
+	}
+}
+
+func (a *auditLog) all() []AuditRecord {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	records := make([]AuditRecord, 0, len(a.records))
+	records = append(records, a.records[a.next:]...)
+	records = append(records, a.records[:a.next]...)
+	return records
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	w     io.Writer
+	count *atomic.Int64
+}
+
+func (c countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.count.Add(int64(n))
+	return n, err
+}
+
+// exitCode returns the exit code of a finished command, or -1 if it did not exit.
+func exitCode(cmd *exec.Cmd) int {
+	if cmd.ProcessState == nil {
+		return -1
+	}
+	return cmd.ProcessState.ExitCode()
+}
+
+func cmdName(name string, path string) string {
+	if name != "" {
+		return name
+	}
+	return filepath.Base(path)
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
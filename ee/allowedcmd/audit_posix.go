@@ -0,0 +1,16 @@
+//go:build !windows
+
+package allowedcmd
+
+import (
+	"os"
+	"os/exec"
+)
+
+// commandUid returns the uid the command runs as.
+func commandUid(cmd *exec.Cmd) int {
+	if cmd.SysProcAttr != nil && cmd.SysProcAttr.Credential != nil {
+		return int(cmd.SysProcAttr.Credential.Uid)
+	}
+	return os.Getuid()
+}
@@ -0,0 +1,137 @@
+package allowedcmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/kolide/kit/ulid"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_auditLog_bounded(t *testing.T) {
+	t.Parallel()
+
+	log := newAuditLog(3)
+	ids := make([]uint64, 0)
+	for i := range 5 {
+		ids = append(ids, log.add(AuditRecord{Name: fmt.Sprintf("cmd%d", i)}))
+	}
+
+	records := log.all()
+	require.Len(t, records, 3)
+	require.Equal(t, "cmd2", records[0].Name)
+	require.Equal(t, "cmd3", records[1].Name)
+	require.Equal(t, "cmd4", records[2].Name)
+
+	// Updating an overwritten record is a no-op
+	log.update(ids[0], func(r *AuditRecord) { r.Name = "updated" })
+	log.update(ids[4], func(r *AuditRecord) { r.Error = "updated" })
+	records = log.all()
+	for _, record := range records {
+		require.NotEqual(t, "updated", record.Name)
+	}
+	require.Equal(t, "updated", records[2].Error)
+}
+
+func TestTracedCmd_audit(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("echo is a shell builtin on windows")
+	}
+
+	for _, tt := range []struct {
+		name string
+		run  func(*TracedCmd) error
+	}{
+		{
+			name: "Output",
+			run: func(c *TracedCmd) error {
+				_, err := c.Output()
+				return err
+			},
+		},
+		{
+			name: "Run",
+			run: func(c *TracedCmd) error {
+				c.Stdout = &bytes.Buffer{}
+				return c.Run()
+			},
+		},
+		{
+			name: "Start and Wait",
+			run: func(c *TracedCmd) error {
+				c.Stdout = &bytes.Buffer{}
+				if err := c.Start(); err != nil {
+					return err
+				}
+				return c.Wait()
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			marker := ulid.New()
+			tracedCmd, err := Echo.Cmd(t.Context(), marker)
+			require.NoError(t, err)
+			require.NoError(t, tt.run(tracedCmd))
+
+			record := auditRecordById(t, tracedCmd.auditId)
+			require.Equal(t, "echo", record.Name)
+			require.Equal(t, tracedCmd.Path, record.Path)
+			require.Equal(t, 1, record.ArgCount)
+			require.Equal(t, 0, record.ExitCode)
+			require.Equal(t, int64(len(marker)+1), record.OutputBytes)
+			require.False(t, record.Running)
+			require.False(t, record.Denied)
+			require.Empty(t, record.Error)
+		})
+	}
+}
+
+func TestTracedCmd_auditNotWaited(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("echo is a shell builtin on windows")
+	}
+
+	auditId := startWithoutWaiting(t)
+	require.True(t, auditRecordById(t, auditId).Running)
+
+	// Once the command is garbage collected, we know it will never be waited on
+	require.Eventually(t, func() bool {
+		runtime.GC()
+		return !auditRecordById(t, auditId).Running
+	}, 10*time.Second, 50*time.Millisecond)
+
+	record := auditRecordById(t, auditId)
+	require.Equal(t, -1, record.ExitCode)
+	require.Equal(t, notWaitedError, record.Error)
+}
+
+// startWithoutWaiting starts a command, and drops it without waiting on it, returning its audit ID.
+func startWithoutWaiting(t *testing.T) uint64 {
+	tracedCmd, err := Echo.Cmd(context.Background(), ulid.New()) //nolint:usetesting // exec would leave a goroutine watching a cancelable context
+	require.NoError(t, err)
+	require.NoError(t, tracedCmd.Start())
+	return tracedCmd.auditId
+}
+
+func auditRecordById(t *testing.T, id uint64) AuditRecord {
+	processAuditLog.lock.Lock()
+	defer processAuditLog.lock.Unlock()
+
+	for i := range processAuditLog.ids {
+		if processAuditLog.ids[i] == id {
+			return processAuditLog.records[i]
+		}
+	}
+	t.Fatalf("no audit record found with id %d", id)
+	return AuditRecord{}
+}
@@ -0,0 +1,10 @@
+//go:build windows
+
+package allowedcmd
+
+import "os/exec"
+
+// commandUid returns -1, since Windows does not have uids.
+func commandUid(_ *exec.Cmd) int {
+	return -1
+}
@@ -30,8 +30,9 @@ type AllowedCommand interface {
 
 // allowedCommand is an internal struct that conforms to the AllowedCommand interface
 type allowedCommand struct {
-	knownPaths []string
-	env        []string
+	knownPaths          []string
+	env                 []string
+	skipIntegrityChecks bool
 }
 
 func newAllowedCommand(knownPaths ...string) allowedCommand {
@@ -45,6 +46,13 @@ func (ac allowedCommand) WithEnv(env string) allowedCommand {
 	return ac
 }
 
+// WithoutIntegrityVerification exempts the command from integrity verification, for commands
+// that are legitimately installed outside of root's control (e.g. Homebrew).
+func (ac allowedCommand) WithoutIntegrityVerification() allowedCommand {
+	ac.skipIntegrityChecks = true
+	return ac
+}
+
 func (ac allowedCommand) Name() string {
 	if len(ac.knownPaths) == 0 {
 		return "~unknown~"
@@ -54,12 +62,24 @@ func (ac allowedCommand) Name() string {
 }
 
 func (ac allowedCommand) Cmd(ctx context.Context, arg ...string) (*TracedCmd, error) {
+	if err := checkPolicy(ac.Name(), arg); err != nil {
+		return nil, err
+	}
+
 	cmdpath, err := findExecutable(ac.knownPaths)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", ac.Name(), err)
 	}
 
-	return newCmd(ctx, ac.env, cmdpath, arg...), nil
+	if !ac.skipIntegrityChecks {
+		if err := processIntegrityVerifier.verify(ac.Name(), cmdpath); err != nil {
+			err = fmt.Errorf("%s: %w", ac.Name(), err)
+			recordDenied(ac.Name(), cmdpath, arg, err)
+			return nil, err
+		}
+	}
+
+	return newCmd(ctx, ac.Name(), ac.env, cmdpath, arg...), nil
 }
 
 // findExecutable handles the logic of finding an executable. It searches the shared paths,
@@ -89,13 +109,14 @@ func findExecutable(knownPaths []string) (string, error) {
 	return "", fmt.Errorf("not found and could not be located elsewhere: %w", ErrCommandNotFound)
 }
 
-func newCmd(ctx context.Context, env []string, fullPathToCmd string, arg ...string) *TracedCmd {
+func newCmd(ctx context.Context, name string, env []string, fullPathToCmd string, arg ...string) *TracedCmd {
 	cmd := exec.CommandContext(ctx, fullPathToCmd, arg...) //nolint:forbidigo // This is our approved usage of exec.CommandContext
 	cmd.Env = append(cmd.Environ(), fmt.Sprintf("GOMAXPROCS=%d", cmdGoMaxProcs))
 	cmd.Env = append(cmd.Env, env...)
 	return &TracedCmd{
-		Ctx: ctx,
-		Cmd: cmd,
+		Ctx:  ctx,
+		Cmd:  cmd,
+		name: name,
 	}
 }
 
@@ -146,7 +167,7 @@ func (launcherCommand) Cmd(ctx context.Context, args ...string) (*TracedCmd, err
 		"LAUNCHER_SKIP_UPDATES=TRUE",
 	}
 
-	return newCmd(ctx, envAdditions, selfPath, args...), nil
+	return newCmd(ctx, "launcher", envAdditions, selfPath, args...), nil
 }
 
 var Launcher = launcherCommand{}
@@ -8,7 +8,7 @@ var Bioutil = newAllowedCommand("/usr/bin/bioutil")
 
 var Bputil = newAllowedCommand("/usr/bin/bputil")
 
-var Brew = newAllowedCommand("/opt/homebrew/bin/brew", "/usr/local/bin/brew").WithEnv("HOMEBREW_NO_AUTO_UPDATE=1")
+var Brew = newAllowedCommand("/opt/homebrew/bin/brew", "/usr/local/bin/brew").WithEnv("HOMEBREW_NO_AUTO_UPDATE=1").WithoutIntegrityVerification()
 
 var Codesign = newAllowedCommand("/usr/bin/codesign")
 
@@ -42,6 +42,8 @@ var NixEnv = newAllowedCommand("/nix/var/nix/profiles/default/bin/nix-env")
 
 var Open = newAllowedCommand("/usr/bin/open")
 
+var Osascript = newAllowedCommand("/usr/bin/osascript")
+
 var Pkgutil = newAllowedCommand("/usr/sbin/pkgutil")
 
 var Powermetrics = newAllowedCommand("/usr/bin/powermetrics")
@@ -60,6 +62,8 @@ var Scutil = newAllowedCommand("/usr/sbin/scutil")
 
 var Security = newAllowedCommand("/usr/bin/security")
 
+var Sh = newAllowedCommand("/bin/sh")
+
 var Socketfilterfw = newAllowedCommand("/usr/libexec/ApplicationFirewall/socketfilterfw")
 
 var Softwareupdate = newAllowedCommand("/usr/sbin/softwareupdate")
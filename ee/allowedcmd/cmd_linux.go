@@ -4,7 +4,7 @@ package allowedcmd
 
 var Apt = newAllowedCommand("/usr/bin/apt")
 
-var Brew = newAllowedCommand("/home/linuxbrew/.linuxbrew/bin/brew").WithEnv("HOMEBREW_NO_AUTO_UPDATE=1")
+var Brew = newAllowedCommand("/home/linuxbrew/.linuxbrew/bin/brew").WithEnv("HOMEBREW_NO_AUTO_UPDATE=1").WithoutIntegrityVerification()
 
 var Coredumpctl = newAllowedCommand("/usr/bin/coredumpctl")
 
@@ -32,6 +32,8 @@ var Ip = newAllowedCommand("/usr/sbin/ip")
 
 var Journalctl = newAllowedCommand("/usr/bin/journalctl")
 
+var Kdialog = newAllowedCommand("/usr/bin/kdialog")
+
 var Loginctl = newAllowedCommand("/usr/bin/loginctl")
 
 var Lsblk = newAllowedCommand("/bin/lsblk", "/usr/bin/lsblk")
@@ -58,6 +60,8 @@ var Repcli = newAllowedCommand("/opt/carbonblack/psc/bin/repcli")
 
 var Rpm = newAllowedCommand("/bin/rpm", "/usr/bin/rpm")
 
+var Sh = newAllowedCommand("/bin/sh", "/usr/bin/sh")
+
 var Snap = newAllowedCommand("/usr/bin/snap")
 
 var Systemctl = newAllowedCommand("/usr/bin/systemctl")
@@ -70,6 +74,8 @@ var Xrdb = newAllowedCommand("/usr/bin/xrdb")
 
 var XWwwBrowser = newAllowedCommand("/usr/bin/x-www-browser")
 
+var Zenity = newAllowedCommand("/usr/bin/zenity")
+
 var ZerotierCli = newAllowedCommand("/usr/local/bin/zerotier-cli")
 
 var Zfs = newAllowedCommand("/usr/sbin/zfs")
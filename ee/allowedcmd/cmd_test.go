@@ -73,7 +73,7 @@ func Test_newCmd(t *testing.T) {
 	t.Parallel()
 
 	cmdPath := filepath.Join("some", "path", "to", "a", "command")
-	tracedCmd := newCmd(t.Context(), nil, cmdPath)
+	tracedCmd := newCmd(t.Context(), "command", nil, cmdPath)
 	require.Equal(t, cmdPath, tracedCmd.Path)
 }
 
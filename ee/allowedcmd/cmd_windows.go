@@ -24,7 +24,11 @@ type echoCommand struct {
 func (echoCommand) Name() string { return "echo" }
 
 func (ac echoCommand) Cmd(ctx context.Context, arg ...string) (*TracedCmd, error) {
-	return newCmd(ctx, ac.env, "echo", arg...), nil
+	if err := checkPolicy(ac.Name(), arg); err != nil {
+		return nil, err
+	}
+
+	return newCmd(ctx, ac.Name(), ac.env, "echo", arg...), nil
 }
 
 var Echo AllowedCommand = echoCommand{}
@@ -49,6 +53,10 @@ type zerotierCli struct{}
 
 func (zerotierCli) Name() string { return "ZerotierCli" }
 func (ac zerotierCli) Cmd(ctx context.Context, arg ...string) (*TracedCmd, error) {
+	if err := checkPolicy(ac.Name(), arg); err != nil {
+		return nil, err
+	}
+
 	knownPaths := []string{
 		filepath.Join(os.Getenv("SYSTEMROOT"), "ProgramData", "ZeroTier", "One", "zerotier-one_x64.exe"),
 	}
@@ -59,7 +67,7 @@ func (ac zerotierCli) Cmd(ctx context.Context, arg ...string) (*TracedCmd, error
 	}
 
 	// For windows, "-q" should be prepended before all other args
-	return newCmd(ctx, nil, cmdpath, append([]string{"-q"}, arg...)...), nil
+	return newCmd(ctx, ac.Name(), nil, cmdpath, append([]string{"-q"}, arg...)...), nil
 }
 
 var ZerotierCli = zerotierCli{}
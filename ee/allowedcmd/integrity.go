@@ -0,0 +1,104 @@
+package allowedcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var ErrIntegrityViolation = errors.New("command failed integrity verification")
+
+// integrityVerifier checks command binaries before they are executed, so that a replaced
+// binary is not run with launcher's privileges. The binary, and every directory above it, must
+// be owned by root and not writable by group or others; on Linux, the binary must also belong
+// to an installed package, when the package database is available. Ownership and permission
+// checks are cheap and always run; the package lookup is cached by inode and modification time.
+type integrityVerifier struct {
+	enabled atomic.Bool
+	slogger atomic.Pointer[slog.Logger]
+
+	cacheLock sync.Mutex
+	cache     map[string]provenanceCacheEntry
+}
+
+type provenanceCacheEntry struct {
+	inode   uint64
+	modTime time.Time
+	err     error
+}
+
+var processIntegrityVerifier = &integrityVerifier{
+	cache: make(map[string]provenanceCacheEntry),
+}
+
+// SetIntegrityVerification enables or disables integrity verification of command binaries.
+// Violations are logged to the given slogger.
+func SetIntegrityVerification(enabled bool, slogger *slog.Logger) {
+	if slogger != nil {
+		processIntegrityVerifier.slogger.Store(slogger.With("component", "allowedcmd_integrity"))
+	}
+	processIntegrityVerifier.enabled.Store(enabled)
+}
+
+// verify returns ErrIntegrityViolation if the binary at cmdPath should not be executed.
+func (v *integrityVerifier) verify(name string, cmdPath string) error {
+	if !v.enabled.Load() {
+		return nil
+	}
+
+	err := v.check(cmdPath)
+	if err == nil {
+		return nil
+	}
+
+	if slogger := v.slogger.Load(); slogger != nil {
+		slogger.Log(context.TODO(), slog.LevelWarn,
+			"refusing to execute command that failed integrity verification",
+			"command", name,
+			"path", cmdPath,
+			"err", err,
+		)
+	}
+
+	return fmt.Errorf("%w: %w", ErrIntegrityViolation, err)
+}
+
+func (v *integrityVerifier) check(cmdPath string) error {
+	resolvedPath, err := filepath.EvalSymlinks(cmdPath)
+	if err != nil {
+		return fmt.Errorf("resolving %s: %w", cmdPath, err)
+	}
+
+	if err := checkOwnershipAndPermissions(resolvedPath); err != nil {
+		return err
+	}
+
+	inode, modTime, err := fileIdentity(resolvedPath)
+	if err != nil {
+		return err
+	}
+
+	v.cacheLock.Lock()
+	entry, ok := v.cache[resolvedPath]
+	v.cacheLock.Unlock()
+	if ok && entry.inode == inode && entry.modTime.Equal(modTime) {
+		return entry.err
+	}
+
+	provenanceErr := checkPackageProvenance(cmdPath, resolvedPath)
+
+	v.cacheLock.Lock()
+	v.cache[resolvedPath] = provenanceCacheEntry{
+		inode:   inode,
+		modTime: modTime,
+		err:     provenanceErr,
+	}
+	v.cacheLock.Unlock()
+
+	return provenanceErr
+}
@@ -0,0 +1,140 @@
+//go:build linux
+
+package allowedcmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	dpkgInfoDir          = "/var/lib/dpkg/info"
+	nixStoreDir          = "/nix/store/"
+	rpmProvenanceTimeout = 10 * time.Second
+)
+
+var rpmPaths = []string{"/bin/rpm", "/usr/bin/rpm"}
+
+// checkPackageProvenance checks that the binary was installed by the system package manager,
+// when there is a package database available to consult. cmdPath is the path we found the binary
+// at, and resolvedPath is that path with symlinks resolved; the package database may record either.
+func checkPackageProvenance(cmdPath string, resolvedPath string) error {
+	// The nix store is immutable, and only ever populated by nix
+	if strings.HasPrefix(resolvedPath, nixStoreDir) {
+		return nil
+	}
+
+	candidates := provenanceCandidates(cmdPath, resolvedPath)
+
+	if _, err := os.Stat(dpkgInfoDir); err == nil {
+		return checkDpkgProvenance(dpkgInfoDir, candidates)
+	}
+
+	for _, rpmPath := range rpmPaths {
+		if _, err := os.Stat(rpmPath); err == nil {
+			return checkRpmProvenance(rpmPath, candidates)
+		}
+	}
+
+	// No package database available
+	return nil
+}
+
+// provenanceCandidates returns the paths that the package database may know the binary by,
+// including its alias on systems where /bin and /sbin are symlinks into /usr.
+func provenanceCandidates(paths ...string) []string {
+	candidates := make([]string, 0)
+	seen := make(map[string]struct{})
+	add := func(p string) {
+		if _, ok := seen[p]; ok {
+			return
+		}
+		seen[p] = struct{}{}
+		candidates = append(candidates, p)
+	}
+
+	for _, p := range paths {
+		add(p)
+		if strings.HasPrefix(p, "/usr/bin/") || strings.HasPrefix(p, "/usr/sbin/") {
+			add(strings.TrimPrefix(p, "/usr"))
+		} else if strings.HasPrefix(p, "/bin/") || strings.HasPrefix(p, "/sbin/") {
+			add("/usr" + p)
+		}
+	}
+
+	return candidates
+}
+
+// checkDpkgProvenance looks for any of the candidate paths in dpkg's per-package file lists.
+func checkDpkgProvenance(infoDir string, candidates []string) error {
+	lists, err := filepath.Glob(filepath.Join(infoDir, "*.list"))
+	if err != nil {
+		return fmt.Errorf("listing dpkg file lists: %w", err)
+	}
+
+	for _, list := range lists {
+		found, err := fileListContains(list, candidates)
+		if err != nil {
+			continue
+		}
+		if found {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%s is not owned by any dpkg package", candidates[0])
+}
+
+func fileListContains(listPath string, candidates []string) (bool, error) {
+	f, err := os.Open(listPath)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		for _, candidate := range candidates {
+			if line == candidate {
+				return true, nil
+			}
+		}
+	}
+
+	return false, scanner.Err()
+}
+
+// checkRpmProvenance asks rpm whether any package owns any of the candidate paths. rpm itself
+// is only checked for ownership and permissions, so that we don't recurse.
+func checkRpmProvenance(rpmPath string, candidates []string) error {
+	if err := checkOwnershipAndPermissions(rpmPath); err != nil {
+		return fmt.Errorf("cannot use rpm to check provenance: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), rpmProvenanceTimeout)
+	defer cancel()
+
+	var lastErr error
+	for _, candidate := range candidates {
+		cmd := newCmd(ctx, "rpm", nil, rpmPath, "-qf", candidate)
+		if err := cmd.Run(); err != nil {
+			lastErr = err
+			continue
+		}
+		return nil
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(lastErr, &exitErr) {
+		return fmt.Errorf("%s is not owned by any rpm package", candidates[0])
+	}
+	return fmt.Errorf("querying rpm for %s: %w", candidates[0], lastErr)
+}
@@ -0,0 +1,30 @@
+//go:build linux
+
+package allowedcmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_provenanceCandidates(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []string{"/bin/lsblk", "/usr/bin/lsblk"}, provenanceCandidates("/bin/lsblk", "/usr/bin/lsblk"))
+	require.Equal(t, []string{"/usr/sbin/zfs", "/sbin/zfs"}, provenanceCandidates("/usr/sbin/zfs", "/usr/sbin/zfs"))
+	require.Equal(t, []string{"/opt/CrowdStrike/falconctl"}, provenanceCandidates("/opt/CrowdStrike/falconctl", "/opt/CrowdStrike/falconctl"))
+}
+
+func Test_checkDpkgProvenance(t *testing.T) {
+	t.Parallel()
+
+	infoDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(infoDir, "util-linux.list"), []byte("/.\n/bin\n/bin/lsblk\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(infoDir, "util-linux.md5sums"), []byte("abc  usr/bin/dpkg\n"), 0644))
+
+	require.NoError(t, checkDpkgProvenance(infoDir, provenanceCandidates("/usr/bin/lsblk")))
+	require.ErrorContains(t, checkDpkgProvenance(infoDir, provenanceCandidates("/usr/bin/dpkg")), "not owned by any dpkg package")
+}
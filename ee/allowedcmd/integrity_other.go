@@ -0,0 +1,9 @@
+//go:build !linux
+
+package allowedcmd
+
+// checkPackageProvenance is only implemented on Linux; elsewhere, binaries are not installed
+// via a package database that we can consult.
+func checkPackageProvenance(_ string, _ string) error {
+	return nil
+}
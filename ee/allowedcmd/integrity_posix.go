@@ -0,0 +1,57 @@
+//go:build !windows
+
+package allowedcmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// checkOwnershipAndPermissions checks that the file at path, and every directory above it,
+// is owned by root and is not writable by group or others -- otherwise, a non-root user could
+// replace the binary. World-writable directories with the sticky bit set are not permitted
+// either, since anyone can create new files in them.
+func checkOwnershipAndPermissions(path string) error {
+	current := path
+	for {
+		info, err := os.Stat(current)
+		if err != nil {
+			return fmt.Errorf("checking %s: %w", current, err)
+		}
+
+		stat, ok := info.Sys().(*syscall.Stat_t)
+		if !ok {
+			return fmt.Errorf("checking %s: unexpected stat type %T", current, info.Sys())
+		}
+		if stat.Uid != 0 {
+			return fmt.Errorf("%s is owned by uid %d, not root", current, stat.Uid)
+		}
+		if info.Mode().Perm()&0o022 != 0 {
+			return fmt.Errorf("%s is writable by group or others (mode %s)", current, info.Mode().Perm())
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			return nil
+		}
+		current = parent
+	}
+}
+
+// fileIdentity returns the inode and modification time of the file at path.
+func fileIdentity(path string) (uint64, time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("checking %s: %w", path, err)
+	}
+
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, time.Time{}, fmt.Errorf("checking %s: unexpected stat type %T", path, info.Sys())
+	}
+
+	return uint64(stat.Ino), info.ModTime(), nil //nolint:unconvert // Ino is not uint64 on all platforms
+}
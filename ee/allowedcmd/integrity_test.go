@@ -0,0 +1,41 @@
+//go:build !windows
+
+package allowedcmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kolide/launcher/v2/pkg/log/multislogger"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_integrityVerifier_verify(t *testing.T) {
+	t.Parallel()
+
+	// A binary in a temp directory is not protected from replacement by non-root users
+	tempBinary := filepath.Join(t.TempDir(), "echo")
+	require.NoError(t, os.WriteFile(tempBinary, []byte("#!/bin/sh\n"), 0755))
+
+	v := &integrityVerifier{cache: make(map[string]provenanceCacheEntry)}
+	require.NoError(t, v.verify("echo", tempBinary), "verification should be a no-op when disabled")
+
+	v.enabled.Store(true)
+	v.slogger.Store(multislogger.NewNopLogger())
+	require.ErrorIs(t, v.verify("echo", tempBinary), ErrIntegrityViolation)
+}
+
+func Test_checkOwnershipAndPermissions(t *testing.T) {
+	t.Parallel()
+
+	echoPath, err := findExecutable([]string{"/bin/echo", "/usr/bin/echo"})
+	require.NoError(t, err)
+	resolvedEchoPath, err := filepath.EvalSymlinks(echoPath)
+	require.NoError(t, err)
+	require.NoError(t, checkOwnershipAndPermissions(resolvedEchoPath))
+
+	tempDir := t.TempDir()
+	require.NoError(t, os.Chmod(tempDir, 0777))
+	require.ErrorContains(t, checkOwnershipAndPermissions(tempDir), "writable by group or others")
+}
@@ -0,0 +1,97 @@
+package allowedcmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrCommandDisallowed = errors.New("command disallowed by policy")
+
+// commandPolicy lets commands be disabled at runtime, without a release. Commands are
+// identified by name, as returned by AllowedCommand.Name. A command on the denylist is
+// never allowed; when the allowlist is non-empty, only commands on it are allowed.
+// Launcher itself is always allowed.
+type commandPolicy struct {
+	lock      sync.RWMutex
+	allowlist map[string]struct{}
+	denylist  map[string]struct{}
+}
+
+var processCommandPolicy = &commandPolicy{}
+
+// SetPolicy replaces the current allowlist and denylist. Names are matched case-insensitively.
+func SetPolicy(allowlist []string, denylist []string) {
+	processCommandPolicy.set(allowlist, denylist)
+}
+
+// ParsePolicyList parses a comma-separated list of command names, as set in agent flags.
+func ParsePolicyList(raw string) []string {
+	names := make([]string, 0)
+	for name := range strings.SplitSeq(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// checkPolicy returns ErrCommandDisallowed if the named command may not be run, recording the
+// denied invocation in the audit log.
+func checkPolicy(name string, arg []string) error {
+	if processCommandPolicy.allowed(name) {
+		return nil
+	}
+
+	err := fmt.Errorf("%s: %w", name, ErrCommandDisallowed)
+	recordDenied(name, "", arg, err)
+	return err
+}
+
+// recordDenied records a command that was refused in the audit log.
+func recordDenied(name string, path string, arg []string, err error) {
+	processAuditLog.add(AuditRecord{
+		Timestamp: time.Now(),
+		Name:      name,
+		Path:      path,
+		ArgCount:  len(arg),
+		Uid:       -1,
+		ExitCode:  -1,
+		Denied:    true,
+		Error:     err.Error(),
+	})
+}
+
+func (p *commandPolicy) set(allowlist []string, denylist []string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.allowlist = policyLookup(allowlist)
+	p.denylist = policyLookup(denylist)
+}
+
+func (p *commandPolicy) allowed(name string) bool {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	name = strings.ToLower(name)
+	if _, denied := p.denylist[name]; denied {
+		return false
+	}
+	if len(p.allowlist) == 0 {
+		return true
+	}
+	_, allowed := p.allowlist[name]
+	return allowed
+}
+
+func policyLookup(names []string) map[string]struct{} {
+	lookup := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		lookup[strings.ToLower(name)] = struct{}{}
+	}
+	return lookup
+}
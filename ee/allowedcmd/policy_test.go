@@ -0,0 +1,79 @@
+package allowedcmd
+
+import (
+	"testing"
+
+	"github.com/kolide/kit/ulid"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_commandPolicy_allowed(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name      string
+		allowlist []string
+		denylist  []string
+		expected  map[string]bool
+	}{
+		{
+			name:     "no policy",
+			expected: map[string]bool{"echo": true, "lsof": true},
+		},
+		{
+			name:     "denylist",
+			denylist: []string{"LSOF"},
+			expected: map[string]bool{"echo": true, "lsof": false},
+		},
+		{
+			name:      "allowlist",
+			allowlist: []string{"echo"},
+			expected:  map[string]bool{"echo": true, "lsof": false},
+		},
+		{
+			name:      "denylist takes precedence over allowlist",
+			allowlist: []string{"echo", "lsof"},
+			denylist:  []string{"lsof"},
+			expected:  map[string]bool{"echo": true, "lsof": false},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := &commandPolicy{}
+			p.set(tt.allowlist, tt.denylist)
+			for name, expected := range tt.expected {
+				require.Equal(t, expected, p.allowed(name), name)
+			}
+		})
+	}
+}
+
+func TestParsePolicyList(t *testing.T) {
+	t.Parallel()
+
+	require.Empty(t, ParsePolicyList(""))
+	require.Equal(t, []string{"lsof", "zfs"}, ParsePolicyList(" lsof, ,zfs,"))
+}
+
+// TestSetPolicy is not parallel, since it changes the policy for the whole package.
+func TestSetPolicy(t *testing.T) {
+	SetPolicy(nil, []string{Echo.Name()})
+	t.Cleanup(func() {
+		SetPolicy(nil, nil)
+	})
+
+	_, err := Echo.Cmd(t.Context(), ulid.New())
+	require.ErrorIs(t, err, ErrCommandDisallowed)
+
+	records := AuditRecords()
+	record := records[len(records)-1]
+	require.True(t, record.Denied)
+	require.Equal(t, Echo.Name(), record.Name)
+	require.Equal(t, 1, record.ArgCount)
+
+	// Launcher itself is always allowed
+	SetPolicy([]string{Echo.Name()}, []string{Launcher.Name()})
+	_, err = Launcher.Cmd(t.Context())
+	require.NoError(t, err)
+}
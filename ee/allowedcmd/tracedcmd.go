@@ -3,7 +3,11 @@ package allowedcmd
 import (
 	"context"
 	"fmt"
+	"os"
 	"os/exec"
+	"runtime"
+	"sync/atomic"
+	"time"
 
 	"github.com/kolide/launcher/v2/ee/observability"
 )
@@ -11,14 +15,48 @@ import (
 type TracedCmd struct {
 	Ctx context.Context // nolint:containedctx // This is an approved usage of context for short lived cmd
 	*exec.Cmd
+
+	name        string
+	auditId     uint64
+	started     time.Time
+	outputBytes atomic.Int64
 }
 
 // Start overrides the Start method to add tracing before executing the command.
+// The invocation is recorded in the audit log when it starts, and updated by Wait.
+// If the command is never waited on, its record is marked as finished, with an unknown
+// result, once the command is garbage collected.
 func (t *TracedCmd) Start() error {
 	_, span := observability.StartSpan(t.Ctx, "path", t.Path, "args", fmt.Sprintf("%+v", t.Args))
 	defer span.End()
 
-	return t.Cmd.Start() //nolint:forbidigo // This is our approved usage of t.Cmd.Start()
+	t.beginAudit()
+	err := t.Cmd.Start() //nolint:forbidigo // This is our approved usage of t.Cmd.Start()
+	if err != nil {
+		t.endAudit(err)
+		return err
+	}
+
+	runtime.AddCleanup(t, markNotWaited, t.auditId)
+	return nil
+}
+
+// markNotWaited marks the record for a command that was started, but never waited on, as finished.
+func markNotWaited(auditId uint64) {
+	processAuditLog.update(auditId, func(record *AuditRecord) {
+		if !record.Running {
+			return
+		}
+		record.Running = false
+		record.Error = notWaitedError
+	})
+}
+
+// Wait overrides the Wait method to record the result of a command started with Start.
+func (t *TracedCmd) Wait() error {
+	err := t.Cmd.Wait()
+	t.endAudit(err)
+	return err
 }
 
 func (t *TracedCmd) String() string {
@@ -30,7 +68,10 @@ func (t *TracedCmd) Run() error {
 	_, span := observability.StartSpan(t.Ctx, "path", t.Path, "args", fmt.Sprintf("%+v", t.Args))
 	defer span.End()
 
-	return t.Cmd.Run() //nolint:forbidigo // This is our approved usage of t.Cmd.Run()
+	t.beginAudit()
+	err := t.Cmd.Run() //nolint:forbidigo // This is our approved usage of t.Cmd.Run()
+	t.endAudit(err)
+	return err
 }
 
 // Output overrides the Output method to add tracing before capturing output.
@@ -38,7 +79,11 @@ func (t *TracedCmd) Output() ([]byte, error) {
 	_, span := observability.StartSpan(t.Ctx, "path", t.Path, "args", fmt.Sprintf("%+v", t.Args))
 	defer span.End()
 
-	return t.Cmd.Output() //nolint:forbidigo // This is our approved usage of t.Cmd.Output()
+	t.beginAudit()
+	out, err := t.Cmd.Output() //nolint:forbidigo // This is our approved usage of t.Cmd.Output()
+	t.outputBytes.Add(int64(len(out)))
+	t.endAudit(err)
+	return out, err
 }
 
 // CombinedOutput overrides the CombinedOutput method to add tracing before capturing combined output.
@@ -46,5 +91,59 @@ func (t *TracedCmd) CombinedOutput() ([]byte, error) {
 	_, span := observability.StartSpan(t.Ctx, "path", t.Path, "args", fmt.Sprintf("%+v", t.Args))
 	defer span.End()
 
-	return t.Cmd.CombinedOutput() //nolint:forbidigo // This is our approved usage of t.Cmd.CombinedOutput()
+	t.beginAudit()
+	out, err := t.Cmd.CombinedOutput() //nolint:forbidigo // This is our approved usage of t.Cmd.CombinedOutput()
+	t.outputBytes.Add(int64(len(out)))
+	t.endAudit(err)
+	return out, err
+}
+
+// beginAudit records the invocation as running. Output written to caller-provided writers
+// is counted; writers that are files are left alone, since wrapping them would change how
+// the child process's output is plumbed.
+func (t *TracedCmd) beginAudit() {
+	if t.Stdout != nil {
+		if _, isFile := t.Stdout.(*os.File); !isFile {
+			t.Stdout = countingWriter{w: t.Stdout, count: &t.outputBytes}
+		}
+	}
+	if t.Stderr != nil {
+		if _, isFile := t.Stderr.(*os.File); !isFile {
+			t.Stderr = countingWriter{w: t.Stderr, count: &t.outputBytes}
+		}
+	}
+
+	argCount := 0
+	if len(t.Args) > 1 {
+		argCount = len(t.Args) - 1
+	}
+
+	t.started = time.Now()
+	t.auditId = processAuditLog.add(AuditRecord{
+		Timestamp: t.started,
+		Name:      cmdName(t.name, t.Path),
+		Path:      t.Path,
+		ArgCount:  argCount,
+		Uid:       commandUid(t.Cmd),
+		ExitCode:  -1,
+		Running:   true,
+	})
+}
+
+// endAudit updates the invocation's record with its result.
+func (t *TracedCmd) endAudit(err error) {
+	if t.auditId == 0 {
+		return
+	}
+
+	duration := time.Since(t.started)
+	code := exitCode(t.Cmd)
+	outputBytes := t.outputBytes.Load()
+	processAuditLog.update(t.auditId, func(record *AuditRecord) {
+		record.Running = false
+		record.Duration = duration
+		record.ExitCode = code
+		record.OutputBytes = outputBytes
+		record.Error = errString(err)
+	})
 }
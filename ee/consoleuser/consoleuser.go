@@ -7,6 +7,22 @@ import (
 	"github.com/kolide/launcher/v2/ee/observability"
 )
 
+// Session is a graphical session belonging to a console user. On Linux, a user may have several
+// sessions at once (e.g. on multi-seat machines, or terminal servers running xrdp); elsewhere,
+// each console user has exactly one session, and Id is empty.
+type Session struct {
+	Uid string
+	// Id is the logind session ID
+	Id string
+	// Type is the session type, e.g. x11 or wayland
+	Type string
+	// Seat is the seat the session is attached to, if any -- remote sessions have no seat
+	Seat string
+	// Display is the X11 display for the session, if logind knows it
+	Display string
+	Remote  bool
+}
+
 func CurrentUsers(ctx context.Context) ([]*user.User, error) {
 	ctx, span := observability.StartSpan(ctx)
 	defer span.End()
@@ -63,6 +63,90 @@ func CurrentUids(ctx context.Context) ([]string, error) {
 	return uids, nil
 }
 
+// CurrentSessions returns all graphical sessions belonging to human users. Unlike CurrentUids,
+// this includes sessions that are not the active session on a seat -- sessions without a seat
+// (e.g. xrdp) are never active, multi-seat machines have one active session per seat, and
+// fast user switching leaves the other users' sessions online in the background. Callers may
+// treat a session missing from the result as ended, so we return an error rather than omit a
+// session we could not inspect.
+func CurrentSessions(ctx context.Context) ([]Session, error) {
+	sessions, err := listSessions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing sessions: %w", err)
+	}
+
+	var graphicalSessions []Session
+	for _, s := range sessions {
+		if s.UID < 1000 || s.UID == 65534 || s.Username == "nobody" {
+			continue
+		}
+
+		cmd, err := allowedcmd.Loginctl.Cmd(ctx,
+			"show-session", s.Session,
+			"--property=Type",
+			"--property=Class",
+			"--property=State",
+			"--property=Active",
+			"--property=Remote",
+			"--property=Display",
+			"--property=Seat",
+		)
+		if err != nil {
+			return nil, fmt.Errorf("creating loginctl command: %w", err)
+		}
+
+		output, err := cmd.Output()
+		if err != nil {
+			// The session may have ended since we listed it, in which case it won't be listed next time
+			return nil, fmt.Errorf("showing session %s: %w", s.Session, err)
+		}
+
+		properties := parseSessionProperties(output)
+		if !isGraphicalUserSession(properties) {
+			continue
+		}
+
+		graphicalSessions = append(graphicalSessions, Session{
+			Uid:     fmt.Sprintf("%d", s.UID),
+			Id:      s.Session,
+			Type:    properties["Type"],
+			Seat:    properties["Seat"],
+			Display: properties["Display"],
+			Remote:  properties["Remote"] == "yes",
+		})
+	}
+
+	return graphicalSessions, nil
+}
+
+// parseSessionProperties parses the key=value output of `loginctl show-session`.
+func parseSessionProperties(output []byte) map[string]string {
+	properties := make(map[string]string)
+	for _, line := range strings.Split(string(output), "\n") {
+		k, v, found := strings.Cut(strings.TrimSpace(line), "=")
+		if !found {
+			continue
+		}
+		properties[k] = v
+	}
+	return properties
+}
+
+// isGraphicalUserSession reports whether the session described by properties is a live
+// graphical session that should get a desktop process. Sessions in the background of their seat
+// (State=online) are still live: their user can switch back to them at any time.
+func isGraphicalUserSession(properties map[string]string) bool {
+	if properties["Class"] != "user" {
+		return false
+	}
+
+	if properties["Type"] != "x11" && properties["Type"] != "wayland" {
+		return false
+	}
+
+	return properties["State"] != "closing"
+}
+
 // listSessions execs `loginctl list-sessions` in order to retrieve the current list of sessions.
 // Depending on the systemd version, we have to use different flags to output the results as JSON.
 // We may want to attempt parsing the output regardless in the future -- see launcher #1522.
@@ -0,0 +1,79 @@
+//go:build linux
+
+package consoleuser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseSessionProperties(t *testing.T) {
+	t.Parallel()
+
+	output := []byte("Type=x11\nClass=user\nState=active\nActive=yes\nRemote=no\nDisplay=:0\nSeat=seat0\n")
+	require.Equal(t, map[string]string{
+		"Type":    "x11",
+		"Class":   "user",
+		"State":   "active",
+		"Active":  "yes",
+		"Remote":  "no",
+		"Display": ":0",
+		"Seat":    "seat0",
+	}, parseSessionProperties(output))
+
+	// Empty values are retained, so we can tell a seatless session apart
+	require.Equal(t, map[string]string{"Seat": "", "Type": "wayland"}, parseSessionProperties([]byte("Seat=\nType=wayland\n\nnot a property")))
+}
+
+func Test_isGraphicalUserSession(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		testCaseName string
+		properties   map[string]string
+		expected     bool
+	}{
+		{
+			testCaseName: "active local x11 session",
+			properties:   map[string]string{"Type": "x11", "Class": "user", "State": "active", "Active": "yes", "Seat": "seat0"},
+			expected:     true,
+		},
+		{
+			testCaseName: "active wayland session on second seat",
+			properties:   map[string]string{"Type": "wayland", "Class": "user", "State": "active", "Active": "yes", "Seat": "seat1"},
+			expected:     true,
+		},
+		{
+			testCaseName: "xrdp session without seat",
+			properties:   map[string]string{"Type": "x11", "Class": "user", "State": "online", "Active": "no", "Seat": ""},
+			expected:     true,
+		},
+		{
+			testCaseName: "background session on seat",
+			properties:   map[string]string{"Type": "x11", "Class": "user", "State": "online", "Active": "no", "Seat": "seat0"},
+			expected:     true,
+		},
+		{
+			testCaseName: "ssh session",
+			properties:   map[string]string{"Type": "tty", "Class": "user", "State": "active", "Active": "no", "Remote": "yes", "Seat": ""},
+			expected:     false,
+		},
+		{
+			testCaseName: "greeter session",
+			properties:   map[string]string{"Type": "wayland", "Class": "greeter", "State": "active", "Active": "yes", "Seat": "seat0"},
+			expected:     false,
+		},
+		{
+			testCaseName: "closing session",
+			properties:   map[string]string{"Type": "x11", "Class": "user", "State": "closing", "Active": "no", "Seat": ""},
+			expected:     false,
+		},
+	} {
+		t.Run(tt.testCaseName, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.expected, isGraphicalUserSession(tt.properties))
+		})
+	}
+}
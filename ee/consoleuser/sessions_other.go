@@ -0,0 +1,22 @@
+//go:build !linux
+
+package consoleuser
+
+import (
+	"context"
+)
+
+// CurrentSessions returns one session per console user.
+func CurrentSessions(ctx context.Context) ([]Session, error) {
+	uids, err := CurrentUids(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]Session, len(uids))
+	for i, uid := range uids {
+		sessions[i] = Session{Uid: uid}
+	}
+
+	return sessions, nil
+}
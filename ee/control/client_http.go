@@ -46,6 +46,12 @@ const (
 	HeaderSignature2 = "X-Kolide-Signature2"
 	HeaderKey2       = "X-Kolide-Key2"
 
+	// While a hardware key rotation awaits acknowledgement, the new key is sent alongside the
+	// current one, with a signature over the challenge and the current key's signature over it
+	HeaderSignature3            = "X-Kolide-Signature3"
+	HeaderKey3                  = "X-Kolide-Key3"
+	HeaderKey3RotationSignature = "X-Kolide-Key3-Rotation-Signature"
+
 	defaultRequestTimeout = 30 * time.Second
 )
 
@@ -174,6 +180,45 @@ func (c *HTTPClient) setHardwareKeyHeader(req *http.Request, challenge []byte) e
 
 	req.Header.Set(HeaderKey2, string(key2))
 	req.Header.Set(HeaderSignature2, sig2)
+
+	if err := setPendingHardwareKeyHeader(req, challenge); err != nil {
+		c.slogger.Log(req.Context(), slog.LevelWarn,
+			"failed to set pending hardware key header, not fatal moving on",
+			"err", err,
+		)
+	}
+
+	return nil
+}
+
+func setPendingHardwareKeyHeader(req *http.Request, challenge []byte) error {
+	rotator, ok := agent.HardwareKeyRotator()
+	if !ok {
+		return nil
+	}
+
+	pending := rotator.PendingKeyRotation()
+	if pending == nil {
+		return nil
+	}
+
+	ecdsaPubKey, ok := pending.Signer.Public().(*ecdsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("pending hardware key in unexpected format (expected ECDSA, got %T)", pending.Signer.Public())
+	}
+	key3, err := echelper.PublicEcdsaToB64Der(ecdsaPubKey)
+	if err != nil {
+		return fmt.Errorf("could not get key header from pending hardware key: %w", err)
+	}
+
+	sig3, err := signatureHeaderValue(pending.Signer, challenge)
+	if err != nil {
+		return fmt.Errorf("could not get signature header from pending hardware key: %w", err)
+	}
+
+	req.Header.Set(HeaderKey3, string(key3))
+	req.Header.Set(HeaderSignature3, sig3)
+	req.Header.Set(HeaderKey3RotationSignature, base64.StdEncoding.EncodeToString(pending.Signature))
 	return nil
 }
 
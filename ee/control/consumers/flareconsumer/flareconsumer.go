@@ -32,13 +32,13 @@ type FlareConsumer struct {
 //mockery:filename: flarer.go
 //mockery:structname: Flarer
 type flarer interface {
-	RunFlare(ctx context.Context, k types.Knapsack, flareStream io.WriteCloser) error
+	RunFlare(ctx context.Context, k types.Knapsack, flareStream io.WriteCloser, opts checkups.FlareOptions) error
 }
 
 type FlareRunner struct{}
 
-func (f *FlareRunner) RunFlare(ctx context.Context, k types.Knapsack, flareStream io.WriteCloser) error {
-	return checkups.RunFlare(ctx, k, flareStream, checkups.InSituEnvironment)
+func (f *FlareRunner) RunFlare(ctx context.Context, k types.Knapsack, flareStream io.WriteCloser, opts checkups.FlareOptions) error {
+	return checkups.RunFlare(ctx, k, flareStream, checkups.InSituEnvironment, opts)
 }
 
 func New(knapsack types.Knapsack) *FlareConsumer {
@@ -78,8 +78,12 @@ func (fc *FlareConsumer) Do(data io.Reader) error {
 	}
 
 	flareData := struct {
-		Note             string `json:"note"`
-		UploadRequestURL string `json:"upload_request_url"`
+		Note             string   `json:"note"`
+		UploadRequestURL string   `json:"upload_request_url"`
+		IncludeCheckups  []string `json:"include_checkups"`
+		ExcludeCheckups  []string `json:"exclude_checkups"`
+		LogWindow        string   `json:"log_window"`
+		SizeBudgetKB     int      `json:"size_budget_kb"`
 	}{}
 
 	if err := json.NewDecoder(data).Decode(&flareData); err != nil {
@@ -90,8 +94,28 @@ func (fc *FlareConsumer) Do(data io.Reader) error {
 		return nil
 	}
 
+	flareOpts := checkups.FlareOptions{
+		IncludeCheckups: flareData.IncludeCheckups,
+		ExcludeCheckups: flareData.ExcludeCheckups,
+		SizeBudgetKB:    flareData.SizeBudgetKB,
+	}
+	if flareData.LogWindow != "" {
+		logWindow, err := time.ParseDuration(flareData.LogWindow)
+		if err != nil {
+			// Better to collect too many logs than no flare at all
+			fc.slogger.Log(ctx, slog.LevelWarn,
+				"invalid log window in flare request, collecting all logs",
+				"log_window", flareData.LogWindow,
+				"err", err,
+			)
+		} else {
+			flareOpts.LogWindow = logWindow
+		}
+	}
+
 	fc.slogger.Log(ctx, slog.LevelInfo, "received remote flare request",
 		"note", flareData.Note,
+		"flare_options", flareOpts.String(),
 	)
 
 	flareStream, err := fc.newFlareStream(flareData.Note, flareData.UploadRequestURL)
@@ -104,7 +128,7 @@ func (fc *FlareConsumer) Do(data io.Reader) error {
 		return nil
 	}
 
-	if err := fc.flarer.RunFlare(context.Background(), fc.knapsack, flareStream); err != nil {
+	if err := fc.flarer.RunFlare(context.Background(), fc.knapsack, flareStream, flareOpts); err != nil {
 		fc.slogger.Log(ctx, slog.LevelError,
 			"failed to run flare, not retrying",
 			"err", err,
@@ -5,9 +5,11 @@ import (
 	"io"
 	"log/slog"
 	"testing"
+	"time"
 
 	knapsackMock "github.com/kolide/launcher/v2/ee/agent/types/mocks"
 	"github.com/kolide/launcher/v2/ee/control/consumers/flareconsumer/mocks"
+	"github.com/kolide/launcher/v2/ee/debug/checkups"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/goleak"
@@ -52,3 +54,26 @@ func TestFlareConsumer(t *testing.T) {
 		})
 	}
 }
+
+func TestFlareConsumer_scoped(t *testing.T) {
+	t.Parallel()
+
+	expectedOpts := checkups.FlareOptions{
+		IncludeCheckups: []string{"osquery", "network"},
+		LogWindow:       6 * time.Hour,
+		SizeBudgetKB:    2048,
+	}
+
+	flarer := mocks.NewFlarer(t)
+	flarer.On("RunFlare", mock.Anything, mock.Anything, mock.Anything, expectedOpts).Return(nil)
+
+	mockSack := knapsackMock.NewKnapsack(t)
+	mockSack.On("Slogger").Return(slog.New(slog.DiscardHandler)).Maybe()
+	f := New(mockSack)
+	f.flarer = flarer
+	f.newFlareStream = func(note, uploadRequestURL string) (io.WriteCloser, error) {
+		return &io.PipeWriter{}, nil
+	}
+
+	require.NoError(t, f.Do(bytes.NewBuffer([]byte(`{"upload_url":"https://example.com","include_checkups":["osquery","network"],"log_window":"6h","size_budget_kb":2048}`))))
+}
@@ -9,6 +9,7 @@ import (
 	"io"
 
 	"github.com/kolide/launcher/v2/ee/agent/types"
+	"github.com/kolide/launcher/v2/ee/debug/checkups"
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -40,16 +41,16 @@ func (_m *Flarer) EXPECT() *Flarer_Expecter {
 }
 
 // RunFlare provides a mock function for the type Flarer
-func (_mock *Flarer) RunFlare(ctx context.Context, k types.Knapsack, flareStream io.WriteCloser) error {
-	ret := _mock.Called(ctx, k, flareStream)
+func (_mock *Flarer) RunFlare(ctx context.Context, k types.Knapsack, flareStream io.WriteCloser, opts checkups.FlareOptions) error {
+	ret := _mock.Called(ctx, k, flareStream, opts)
 
 	if len(ret) == 0 {
 		panic("no return value specified for RunFlare")
 	}
 
 	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, types.Knapsack, io.WriteCloser) error); ok {
-		r0 = returnFunc(ctx, k, flareStream)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, types.Knapsack, io.WriteCloser, checkups.FlareOptions) error); ok {
+		r0 = returnFunc(ctx, k, flareStream, opts)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -65,11 +66,12 @@ type Flarer_RunFlare_Call struct {
 //   - ctx context.Context
 //   - k types.Knapsack
 //   - flareStream io.WriteCloser
-func (_e *Flarer_Expecter) RunFlare(ctx interface{}, k interface{}, flareStream interface{}) *Flarer_RunFlare_Call {
-	return &Flarer_RunFlare_Call{Call: _e.mock.On("RunFlare", ctx, k, flareStream)}
+//   - opts checkups.FlareOptions
+func (_e *Flarer_Expecter) RunFlare(ctx interface{}, k interface{}, flareStream interface{}, opts interface{}) *Flarer_RunFlare_Call {
+	return &Flarer_RunFlare_Call{Call: _e.mock.On("RunFlare", ctx, k, flareStream, opts)}
 }
 
-func (_c *Flarer_RunFlare_Call) Run(run func(ctx context.Context, k types.Knapsack, flareStream io.WriteCloser)) *Flarer_RunFlare_Call {
+func (_c *Flarer_RunFlare_Call) Run(run func(ctx context.Context, k types.Knapsack, flareStream io.WriteCloser, opts checkups.FlareOptions)) *Flarer_RunFlare_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -83,10 +85,15 @@ func (_c *Flarer_RunFlare_Call) Run(run func(ctx context.Context, k types.Knapsa
 		if args[2] != nil {
 			arg2 = args[2].(io.WriteCloser)
 		}
+		var arg3 checkups.FlareOptions
+		if args[3] != nil {
+			arg3 = args[3].(checkups.FlareOptions)
+		}
 		run(
 			arg0,
 			arg1,
 			arg2,
+			arg3,
 		)
 	})
 	return _c
@@ -97,7 +104,7 @@ func (_c *Flarer_RunFlare_Call) Return(err error) *Flarer_RunFlare_Call {
 	return _c
 }
 
-func (_c *Flarer_RunFlare_Call) RunAndReturn(run func(ctx context.Context, k types.Knapsack, flareStream io.WriteCloser) error) *Flarer_RunFlare_Call {
+func (_c *Flarer_RunFlare_Call) RunAndReturn(run func(ctx context.Context, k types.Knapsack, flareStream io.WriteCloser, opts checkups.FlareOptions) error) *Flarer_RunFlare_Call {
 	_c.Call.Return(run)
 	return _c
 }
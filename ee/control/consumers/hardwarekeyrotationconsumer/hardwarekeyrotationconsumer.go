@@ -0,0 +1,173 @@
+// Package hardwarekeyrotationconsumer rotates the agent hardware keys, on a schedule set by
+// the hardware_key_rotation_interval flag and on demand via the actionqueue, and promotes the
+// new key once the control server acknowledges it.
+package hardwarekeyrotationconsumer
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/kolide/launcher/v2/ee/agent"
+	"github.com/kolide/launcher/v2/ee/agent/keys"
+	"github.com/kolide/launcher/v2/ee/agent/types"
+)
+
+const (
+	// HardwareKeyRotationActorType identifies this action/actor type, which rotates the hardware
+	// keys or acknowledges a rotation when requested by the control server. This actor type
+	// belongs to the action subsystem.
+	HardwareKeyRotationActorType = "hardware_key_rotation"
+
+	actionRotate      = "rotate"
+	actionAcknowledge = "acknowledge"
+
+	// scheduleCheckInterval is how often we check whether the current key is due for rotation
+	scheduleCheckInterval = 1 * time.Hour
+)
+
+type HardwareKeyRotationConsumer struct {
+	knapsack    types.Knapsack
+	slogger     *slog.Logger
+	rotator     func() (keys.Rotator, bool)
+	interrupt   chan struct{}
+	interrupted atomic.Bool
+}
+
+type hardwareKeyRotationAction struct {
+	Action string `json:"action"`
+	// PublicKey is the base64-encoded PKIX DER of the key being acknowledged
+	PublicKey string `json:"public_key,omitempty"`
+}
+
+func New(knapsack types.Knapsack) *HardwareKeyRotationConsumer {
+	return &HardwareKeyRotationConsumer{
+		knapsack: knapsack,
+		slogger:  knapsack.Slogger().With("component", "hardware_key_rotation_consumer"),
+		// The hardware keys may change at runtime, so look them up each time rather than caching them
+		rotator:   agent.HardwareKeyRotator,
+		interrupt: make(chan struct{}, 1),
+	}
+}
+
+// Do implements the `actionqueue.actor` interface, and allows the actionqueue
+// to pass `hardware_key_rotation` type actions to this consumer.
+func (h *HardwareKeyRotationConsumer) Do(data io.Reader) error {
+	var rotationAction hardwareKeyRotationAction
+	if err := json.NewDecoder(data).Decode(&rotationAction); err != nil {
+		return fmt.Errorf("decoding hardware key rotation action: %w", err)
+	}
+
+	rotator, ok := h.rotator()
+	if !ok {
+		h.slogger.Log(context.TODO(), slog.LevelInfo,
+			"received hardware key rotation action, but hardware keys do not support rotation -- discarding",
+			"action", rotationAction.Action,
+		)
+		return nil
+	}
+
+	switch rotationAction.Action {
+	case actionRotate:
+		if err := rotator.RotateKey(context.TODO()); err != nil {
+			// A rotation already awaiting acknowledgement satisfies the request
+			if errors.Is(err, keys.ErrRotationPending) {
+				return nil
+			}
+			return fmt.Errorf("rotating hardware key: %w", err)
+		}
+		return nil
+
+	case actionAcknowledge:
+		publicKeyDer, err := base64.StdEncoding.DecodeString(rotationAction.PublicKey)
+		if err != nil {
+			return fmt.Errorf("decoding acknowledged public key: %w", err)
+		}
+		if err := rotator.AcknowledgeKeyRotation(context.TODO(), publicKeyDer); err != nil {
+			// The rotation may already have been acknowledged, e.g. if this action is redelivered
+			if errors.Is(err, keys.ErrNoPendingRotation) {
+				h.slogger.Log(context.TODO(), slog.LevelInfo,
+					"received hardware key rotation acknowledgement with no rotation pending -- discarding",
+				)
+				return nil
+			}
+			return fmt.Errorf("acknowledging hardware key rotation: %w", err)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unknown hardware key rotation action %q", rotationAction.Action)
+	}
+}
+
+// Execute allows the consumer to run in the main launcher rungroup, rotating the hardware
+// keys whenever the current key is older than the configured rotation interval.
+func (h *HardwareKeyRotationConsumer) Execute() error {
+	ticker := time.NewTicker(scheduleCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		h.rotateIfDue(context.TODO())
+
+		select {
+		case <-h.interrupt:
+			return nil
+		case <-ticker.C:
+			continue
+		}
+	}
+}
+
+// Interrupt allows the consumer to run in the main launcher rungroup
+// and be shut down when the rungroup shuts down.
+func (h *HardwareKeyRotationConsumer) Interrupt(_ error) {
+	// Only perform shutdown tasks on first call to interrupt -- no need to repeat on potential extra calls.
+	if h.interrupted.Swap(true) {
+		return
+	}
+
+	h.interrupt <- struct{}{}
+}
+
+func (h *HardwareKeyRotationConsumer) rotateIfDue(ctx context.Context) {
+	rotationInterval := h.knapsack.HardwareKeyRotationInterval()
+	if rotationInterval <= 0 {
+		return
+	}
+
+	rotator, ok := h.rotator()
+	if !ok {
+		return
+	}
+
+	// Wait for the server to acknowledge the previous rotation before starting another
+	if rotator.PendingKeyRotation() != nil {
+		return
+	}
+
+	createdAt := rotator.KeyCreatedAt()
+	if createdAt.IsZero() || time.Since(createdAt) < rotationInterval {
+		return
+	}
+
+	if err := rotator.RotateKey(ctx); err != nil {
+		h.slogger.Log(ctx, slog.LevelWarn,
+			"could not perform scheduled hardware key rotation",
+			"key_created_at", createdAt,
+			"err", err,
+		)
+		return
+	}
+
+	h.slogger.Log(ctx, slog.LevelInfo,
+		"performed scheduled hardware key rotation",
+		"key_created_at", createdAt,
+		"rotation_interval", rotationInterval.String(),
+	)
+}
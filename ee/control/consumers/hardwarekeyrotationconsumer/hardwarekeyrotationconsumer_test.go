@@ -0,0 +1,271 @@
+package hardwarekeyrotationconsumer
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kolide/launcher/v2/ee/agent/keys"
+	typesmocks "github.com/kolide/launcher/v2/ee/agent/types/mocks"
+	"github.com/kolide/launcher/v2/pkg/log/multislogger"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+type testRotator struct {
+	lock         sync.Mutex
+	rotateErr    error
+	ackErr       error
+	rotations    int
+	acknowledged [][]byte
+	pending      *keys.PendingRotation
+	createdAt    time.Time
+}
+
+func (r *testRotator) RotateKey(_ context.Context) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.rotateErr != nil {
+		return r.rotateErr
+	}
+	r.rotations++
+	r.pending = &keys.PendingRotation{RotatedAt: time.Now()}
+	return nil
+}
+
+func (r *testRotator) AcknowledgeKeyRotation(_ context.Context, publicKeyDer []byte) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.ackErr != nil {
+		return r.ackErr
+	}
+	r.acknowledged = append(r.acknowledged, publicKeyDer)
+	r.pending = nil
+	return nil
+}
+
+func (r *testRotator) PendingKeyRotation() *keys.PendingRotation {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.pending
+}
+
+func (r *testRotator) KeyCreatedAt() time.Time {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.createdAt
+}
+
+func testConsumer(t *testing.T, rotator *testRotator) (*HardwareKeyRotationConsumer, *typesmocks.Knapsack) {
+	mockKnapsack := typesmocks.NewKnapsack(t)
+	mockKnapsack.On("Slogger").Return(multislogger.NewNopLogger())
+
+	consumer := New(mockKnapsack)
+	consumer.rotator = func() (keys.Rotator, bool) {
+		if rotator == nil {
+			return nil, false
+		}
+		return rotator, true
+	}
+
+	return consumer, mockKnapsack
+}
+
+func actionReader(t *testing.T, action hardwareKeyRotationAction) *bytes.Reader {
+	actionRaw, err := json.Marshal(action)
+	require.NoError(t, err)
+	return bytes.NewReader(actionRaw)
+}
+
+func TestDo(t *testing.T) {
+	t.Parallel()
+
+	publicKeyDer := []byte("test public key der")
+
+	for _, tt := range []struct {
+		testCaseName         string
+		action               hardwareKeyRotationAction
+		rotator              *testRotator
+		expectErr            bool
+		expectedRotations    int
+		expectedAcknowledged int
+	}{
+		{
+			testCaseName:      "rotate",
+			action:            hardwareKeyRotationAction{Action: actionRotate},
+			rotator:           &testRotator{},
+			expectedRotations: 1,
+		},
+		{
+			testCaseName: "rotate with rotation already pending",
+			action:       hardwareKeyRotationAction{Action: actionRotate},
+			rotator:      &testRotator{rotateErr: keys.ErrRotationPending},
+		},
+		{
+			testCaseName: "rotate fails",
+			action:       hardwareKeyRotationAction{Action: actionRotate},
+			rotator:      &testRotator{rotateErr: errors.New("test error")},
+			expectErr:    true,
+		},
+		{
+			testCaseName:         "acknowledge",
+			action:               hardwareKeyRotationAction{Action: actionAcknowledge, PublicKey: base64.StdEncoding.EncodeToString(publicKeyDer)},
+			rotator:              &testRotator{pending: &keys.PendingRotation{}},
+			expectedAcknowledged: 1,
+		},
+		{
+			testCaseName: "acknowledge with no rotation pending",
+			action:       hardwareKeyRotationAction{Action: actionAcknowledge, PublicKey: base64.StdEncoding.EncodeToString(publicKeyDer)},
+			rotator:      &testRotator{ackErr: keys.ErrNoPendingRotation},
+		},
+		{
+			testCaseName: "acknowledge wrong key",
+			action:       hardwareKeyRotationAction{Action: actionAcknowledge, PublicKey: base64.StdEncoding.EncodeToString(publicKeyDer)},
+			rotator:      &testRotator{ackErr: keys.ErrRotationMismatch},
+			expectErr:    true,
+		},
+		{
+			testCaseName: "acknowledge invalid public key encoding",
+			action:       hardwareKeyRotationAction{Action: actionAcknowledge, PublicKey: "not base64!"},
+			rotator:      &testRotator{},
+			expectErr:    true,
+		},
+		{
+			testCaseName: "unknown action",
+			action:       hardwareKeyRotationAction{Action: "explode"},
+			rotator:      &testRotator{},
+			expectErr:    true,
+		},
+		{
+			testCaseName: "hardware keys do not support rotation",
+			action:       hardwareKeyRotationAction{Action: actionRotate},
+		},
+	} {
+		t.Run(tt.testCaseName, func(t *testing.T) {
+			t.Parallel()
+
+			consumer, _ := testConsumer(t, tt.rotator)
+
+			err := consumer.Do(actionReader(t, tt.action))
+			if tt.expectErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			if tt.rotator == nil {
+				return
+			}
+			require.Equal(t, tt.expectedRotations, tt.rotator.rotations)
+			require.Len(t, tt.rotator.acknowledged, tt.expectedAcknowledged)
+			if tt.expectedAcknowledged > 0 {
+				require.Equal(t, publicKeyDer, tt.rotator.acknowledged[0])
+			}
+		})
+	}
+}
+
+func TestDo_InvalidJson(t *testing.T) {
+	t.Parallel()
+
+	consumer, _ := testConsumer(t, &testRotator{})
+	require.Error(t, consumer.Do(bytes.NewReader([]byte("{not json"))))
+}
+
+func Test_rotateIfDue(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		testCaseName      string
+		rotationInterval  time.Duration
+		rotator           *testRotator
+		expectedRotations int
+	}{
+		{
+			testCaseName:      "key due for rotation",
+			rotationInterval:  24 * time.Hour,
+			rotator:           &testRotator{createdAt: time.Now().Add(-48 * time.Hour)},
+			expectedRotations: 1,
+		},
+		{
+			testCaseName:     "key not yet due for rotation",
+			rotationInterval: 24 * time.Hour,
+			rotator:          &testRotator{createdAt: time.Now().Add(-1 * time.Hour)},
+		},
+		{
+			testCaseName:     "scheduled rotation disabled",
+			rotationInterval: 0,
+			rotator:          &testRotator{createdAt: time.Now().Add(-48 * time.Hour)},
+		},
+		{
+			testCaseName:     "rotation already pending",
+			rotationInterval: 24 * time.Hour,
+			rotator:          &testRotator{createdAt: time.Now().Add(-48 * time.Hour), pending: &keys.PendingRotation{}},
+		},
+		{
+			testCaseName:     "key creation time unknown",
+			rotationInterval: 24 * time.Hour,
+			rotator:          &testRotator{},
+		},
+	} {
+		t.Run(tt.testCaseName, func(t *testing.T) {
+			t.Parallel()
+
+			consumer, mockKnapsack := testConsumer(t, tt.rotator)
+			mockKnapsack.On("HardwareKeyRotationInterval").Return(tt.rotationInterval)
+
+			consumer.rotateIfDue(t.Context())
+			require.Equal(t, tt.expectedRotations, tt.rotator.rotations)
+		})
+	}
+}
+
+func TestInterrupt_Multiple(t *testing.T) {
+	t.Parallel()
+
+	consumer, mockKnapsack := testConsumer(t, &testRotator{})
+	mockKnapsack.On("HardwareKeyRotationInterval").Return(time.Duration(0))
+
+	// Start and then interrupt
+	go consumer.Execute()
+	time.Sleep(100 * time.Millisecond)
+	interruptStart := time.Now()
+	consumer.Interrupt(errors.New("test error"))
+
+	// Confirm we can call Interrupt multiple times without blocking
+	interruptComplete := make(chan struct{})
+	expectedInterrupts := 3
+	for i := 0; i < expectedInterrupts; i += 1 {
+		go func() {
+			consumer.Interrupt(nil)
+			interruptComplete <- struct{}{}
+		}()
+	}
+
+	receivedInterrupts := 0
+	for {
+		if receivedInterrupts >= expectedInterrupts {
+			break
+		}
+
+		select {
+		case <-interruptComplete:
+			receivedInterrupts += 1
+			continue
+		case <-time.After(5 * time.Second):
+			t.Errorf("could not call interrupt multiple times and return within 5 seconds -- interrupted at %s, received %d interrupts before timeout", interruptStart.String(), receivedInterrupts)
+			t.FailNow()
+		}
+	}
+
+	require.Equal(t, expectedInterrupts, receivedInterrupts)
+}
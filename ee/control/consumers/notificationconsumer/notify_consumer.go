@@ -7,6 +7,7 @@ import (
 	"io"
 	"log/slog"
 	"net/url"
+	"time"
 
 	"github.com/kolide/launcher/v2/ee/agent/types"
 	desktopRunner "github.com/kolide/launcher/v2/ee/desktop/runner"
@@ -80,6 +81,67 @@ func (nc *NotificationConsumer) notificationIsValid(notificationToCheck notify.N
 		}
 	}
 
+	if !notificationToCheck.Priority.IsValid() {
+		nc.slogger.Log(context.TODO(), slog.LevelWarn,
+			"received invalid priority from K2",
+			"notification_id", notificationToCheck.ID,
+			"priority", notificationToCheck.Priority,
+		)
+		return false
+	}
+
+	if !nc.actionsAreValid(notificationToCheck) {
+		return false
+	}
+
+	// Notification must not be past its delivery window, or have already expired
+	now := time.Now().Unix()
+	if (notificationToCheck.ValidUntil > 0 && notificationToCheck.ValidUntil <= now) ||
+		(notificationToCheck.ExpiresAt > 0 && notificationToCheck.ExpiresAt <= now) {
+		nc.slogger.Log(context.TODO(), slog.LevelInfo,
+			"received expired notification from K2, discarding",
+			"notification_id", notificationToCheck.ID,
+			"valid_until", notificationToCheck.ValidUntil,
+			"expires_at", notificationToCheck.ExpiresAt,
+		)
+		return false
+	}
+
 	// Notification must not be blank
 	return notificationToCheck.Title != "" && notificationToCheck.Body != ""
 }
+
+// actionsAreValid checks that each action has a unique ID and a label, and that
+// any action URI is a valid URI.
+func (nc *NotificationConsumer) actionsAreValid(notificationToCheck notify.Notification) bool {
+	seenIds := make(map[string]struct{})
+	for _, action := range notificationToCheck.Actions {
+		_, seen := seenIds[action.ID]
+		if action.ID == "" || action.ID == notify.DefaultActionID || seen || action.Label == "" {
+			nc.slogger.Log(context.TODO(), slog.LevelWarn,
+				"received invalid action from K2",
+				"notification_id", notificationToCheck.ID,
+				"action_id", action.ID,
+				"action_label", action.Label,
+			)
+			return false
+		}
+		seenIds[action.ID] = struct{}{}
+
+		if action.Uri == "" {
+			continue
+		}
+		if _, err := url.Parse(action.Uri); err != nil {
+			nc.slogger.Log(context.TODO(), slog.LevelWarn,
+				"received invalid action uri from K2",
+				"notification_id", notificationToCheck.ID,
+				"action_id", action.ID,
+				"action_uri", action.Uri,
+				"err", err,
+			)
+			return false
+		}
+	}
+
+	return true
+}
@@ -146,3 +146,121 @@ func TestUpdate_ValidatesNotifications(t *testing.T) {
 func getValidUntil() int64 {
 	return time.Now().Add(1 * time.Hour).Unix()
 }
+
+func TestUpdate_ValidatesActionsPriorityAndExpiry(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		testCaseName     string
+		testNotification notify.Notification
+		expectSent       bool
+	}{
+		{
+			testCaseName: "valid notification with actions",
+			testNotification: notify.Notification{
+				Title:      "Test notification",
+				Body:       "This notification has actions",
+				ID:         ulid.New(),
+				ValidUntil: getValidUntil(),
+				Actions: []notify.Action{
+					{ID: "fix", Label: "Fix it", Uri: "https://www.kolide.com/fix"},
+					{ID: "snooze", Label: "Remind me later"},
+				},
+				Priority: notify.PriorityCritical,
+				DedupKey: "test-dedup-key",
+			},
+			expectSent: true,
+		},
+		{
+			testCaseName: "action without ID",
+			testNotification: notify.Notification{
+				Title:   "Test notification",
+				Body:    "This notification has an action without an ID",
+				Actions: []notify.Action{{Label: "Fix it"}},
+			},
+		},
+		{
+			testCaseName: "action without label",
+			testNotification: notify.Notification{
+				Title:   "Test notification",
+				Body:    "This notification has an action without a label",
+				Actions: []notify.Action{{ID: "fix"}},
+			},
+		},
+		{
+			testCaseName: "duplicate action IDs",
+			testNotification: notify.Notification{
+				Title: "Test notification",
+				Body:  "This notification has duplicate actions",
+				Actions: []notify.Action{
+					{ID: "fix", Label: "Fix it"},
+					{ID: "fix", Label: "Fix it again"},
+				},
+			},
+		},
+		{
+			testCaseName: "action with reserved ID",
+			testNotification: notify.Notification{
+				Title:   "Test notification",
+				Body:    "This notification uses the default action ID",
+				Actions: []notify.Action{{ID: notify.DefaultActionID, Label: "Fix it"}},
+			},
+		},
+		{
+			testCaseName: "action with invalid URI",
+			testNotification: notify.Notification{
+				Title:   "Test notification",
+				Body:    "This notification has an action with an invalid URI",
+				Actions: []notify.Action{{ID: "fix", Label: "Fix it", Uri: "https://www.kolide.com/%zz"}},
+			},
+		},
+		{
+			testCaseName: "unknown priority",
+			testNotification: notify.Notification{
+				Title:    "Test notification",
+				Body:     "This notification has an unknown priority",
+				Priority: "urgent",
+			},
+		},
+		{
+			testCaseName: "already expired",
+			testNotification: notify.Notification{
+				Title:      "Test notification",
+				Body:       "This notification has already expired",
+				ValidUntil: time.Now().Add(-1 * time.Minute).Unix(),
+			},
+		},
+		{
+			testCaseName: "already withdrawn",
+			testNotification: notify.Notification{
+				Title:      "Test notification",
+				Body:       "This notification has already been withdrawn",
+				ValidUntil: getValidUntil(),
+				ExpiresAt:  time.Now().Add(-1 * time.Minute).Unix(),
+			},
+		},
+	} {
+		t.Run(tt.testCaseName, func(t *testing.T) {
+			t.Parallel()
+
+			mockNotifier := newNotifierMock()
+			testNc := &NotificationConsumer{
+				runner:  mockNotifier,
+				slogger: multislogger.NewNopLogger(),
+			}
+			if tt.expectSent {
+				mockNotifier.On("SendNotification", tt.testNotification).Return(nil)
+			}
+
+			testNotificationRaw, err := json.Marshal(tt.testNotification)
+			require.NoError(t, err)
+
+			require.NoError(t, testNc.Do(bytes.NewReader(testNotificationRaw)))
+			if tt.expectSent {
+				mockNotifier.AssertNumberOfCalls(t, "SendNotification", 1)
+			} else {
+				mockNotifier.AssertNumberOfCalls(t, "SendNotification", 0)
+			}
+		})
+	}
+}
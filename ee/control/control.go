@@ -26,6 +26,7 @@ type ControlService struct {
 	knapsack        types.Knapsack
 	cancel          context.CancelFunc
 	requestInterval *atomic.Duration
+	lastFetchTime   *atomic.Time // time of the last successful fetch of the subsystems map
 	requestTicker   *time.Ticker
 	fetcher         dataProvider
 	fetchMutex      sync.Mutex
@@ -66,6 +67,7 @@ func New(k types.Knapsack, fetcher dataProvider, opts ...Option) *ControlService
 		slogger:         k.Slogger().With("component", "control"),
 		knapsack:        k,
 		requestInterval: atomic.NewDuration(k.ControlRequestInterval()),
+		lastFetchTime:   atomic.NewTime(time.Time{}),
 		fetcher:         fetcher,
 		lastFetched:     make(map[string]string),
 		consumers:       make(map[string]consumer),
@@ -303,6 +305,7 @@ func (cs *ControlService) Fetch(ctx context.Context) error {
 	if err := json.NewDecoder(data).Decode(&subsystems); err != nil {
 		return fmt.Errorf("decoding subsystems map: %w", err)
 	}
+	cs.lastFetchTime.Store(time.Now())
 
 	fetchFull := false
 	cs.fetchFullMutex.Lock()
@@ -420,6 +423,12 @@ func (cs *ControlService) RegisterSubscriber(subsystem string, subscriber subscr
 	cs.subscribers[subsystem] = append(cs.subscribers[subsystem], subscriber)
 }
 
+// LastSuccessfulFetch returns the time that launcher last successfully fetched the subsystems map
+// from the control server, or the zero time if it has not yet done so since startup.
+func (cs *ControlService) LastSuccessfulFetch() time.Time {
+	return cs.lastFetchTime.Load()
+}
+
 func (cs *ControlService) SendMessage(method string, params any) error {
 	return cs.fetcher.SendMessage(context.TODO(), method, params)
 }
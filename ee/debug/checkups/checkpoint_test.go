@@ -38,6 +38,7 @@ func TestInterrupt_Multiple(t *testing.T) {
 	mockKnapsack.On("ServerProvidedDataStore").Return(nil).Maybe()
 	mockKnapsack.On("CurrentEnrollmentStatus").Return(types.Enrolled, nil).Maybe()
 	mockKnapsack.On("LauncherHistoryStore").Return(nil).Maybe()
+	mockKnapsack.On("ExternalCheckups").Return("").Maybe()
 	var logBytes threadsafebuffer.ThreadSafeBuffer
 	slogger := slog.New(slog.NewTextHandler(&logBytes, &slog.HandlerOptions{
 		Level: slog.LevelDebug,
@@ -15,7 +15,6 @@
 package checkups
 
 import (
-	"archive/zip"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -32,6 +31,7 @@ import (
 
 	"github.com/kolide/kit/version"
 	"github.com/kolide/launcher/v2/ee/agent/types"
+	"github.com/kolide/launcher/v2/ee/debug/redact"
 	"github.com/kolide/launcher/v2/pkg/launcher"
 )
 
@@ -72,15 +72,17 @@ const (
 
 //const checkupFor iota
 
+type potentialCheckup struct {
+	c       checkupInt
+	targets targetBits
+}
+
 func checkupsFor(k types.Knapsack, target targetBits) []checkupInt {
 	// This encodes what checkups run in which contexts. This could be pushed down into the checkups directly,
 	// but it seems nice to have it here. TBD
 	var runEverywhere targetBits = 255
 
-	var potentialCheckups = []struct {
-		c       checkupInt
-		targets targetBits
-	}{
+	var potentialCheckups = []potentialCheckup{
 		{&Platform{}, runEverywhere},
 		{&hostInfoCheckup{k: k}, doctorSupported | flareSupported | logSupported | startupLogSupported},
 		{&Version{k: k}, runEverywhere},
@@ -110,6 +112,7 @@ func checkupsFor(k types.Knapsack, target targetBits) []checkupInt {
 		{&osqDataCollector{k: k}, doctorSupported | flareSupported},
 		{&intuneCheckup{}, flareSupported},
 		{&osqRestartCheckup{k: k}, doctorSupported | flareSupported},
+		{&queryProfilesCheckup{k: k}, doctorSupported | flareSupported},
 		{&uninstallHistoryCheckup{k: k}, flareSupported},
 		{&desktopMenu{k: k}, flareSupported},
 		{&coredumpCheckup{}, doctorSupported | flareSupported},
@@ -117,6 +120,9 @@ func checkupsFor(k types.Knapsack, target targetBits) []checkupInt {
 		{&perfCheckup{}, flareSupported | logSupported}, // Not startupLogSupported -- we get inaccurate data on first startup
 	}
 
+	// Additional checkups may be declared as config, in the root directory or via the control server
+	potentialCheckups = append(potentialCheckups, externalCheckups(k)...)
+
 	checkupsToRun := make([]checkupInt, 0)
 	for _, p := range potentialCheckups {
 		if p.targets&target == 0 {
@@ -135,21 +141,29 @@ func checkupsFor(k types.Knapsack, target targetBits) []checkupInt {
 	return checkupsToRun
 }
 
-// doctorCheckup runs a checkup for the doctor command line. Its a small bit of sugar over the io channels
-func doctorCheckup(ctx context.Context, c checkupInt, w io.Writer) {
+// doctorCheckup runs a checkup for the doctor command line, collecting its results.
+func doctorCheckup(ctx context.Context, c checkupInt) CheckupResult {
+	start := time.Now()
+	result := CheckupResult{Name: c.Name()}
+
 	if err := c.Run(ctx, io.Discard); err != nil {
-		writeSummary(w, Erroring, c.Name(), fmt.Sprintf("failed to run: %s", err))
-		return
+		result.Status = Erroring
+		result.Summary = fmt.Sprintf("failed to run: %s", err)
+	} else {
+		result.Status = c.Status()
+		result.Summary = c.Summary()
+		result.Data = c.Data()
 	}
 
-	writeSummary(w, c.Status(), c.Name(), c.Summary())
+	result.DurationSeconds = time.Since(start).Seconds()
+	return result
 }
 
 type zipFile interface {
 	Create(name string) (io.Writer, error)
 }
 
-func flareCheckup(ctx context.Context, c checkupInt, combinedSummary io.Writer, flare zipFile) {
+func flareCheckup(ctx context.Context, c checkupInt, combinedSummary io.Writer, flare zipFile, budget *flareBudget) {
 	// zip can only have a single open file. So defer writing the summary.
 	summary := bytes.Buffer{}
 	defer func() {
@@ -166,13 +180,34 @@ func flareCheckup(ctx context.Context, c checkupInt, combinedSummary io.Writer,
 	}()
 
 	fullFH := io.Discard
+	var extraFH io.Writer
+	var extraSpool *os.File
 	if filename := c.ExtraFileName(); filename != "" {
-		var err error
-		fullFH, err = flare.Create(path.Join(c.Name(), filename))
-
-		if err != nil {
-			writeSummary(&summary, Erroring, c.Name(), fmt.Sprintf("error creating flare full file: %s", err))
-			return
+		if !budget.unlimited() && budget.remaining() == 0 {
+			writeSummary(&summary, Informational, c.Name(), "skipped extra data to stay within flare size budget")
+		} else {
+			var err error
+			extraFH, err = flare.Create(path.Join(c.Name(), filename))
+			if err != nil {
+				writeSummary(&summary, Erroring, c.Name(), fmt.Sprintf("error creating flare full file: %s", err))
+				return
+			}
+			fullFH = extraFH
+
+			// With a size budget, we spool the extra data, so that it can be trimmed a file at a time
+			// without corrupting it.
+			if !budget.unlimited() {
+				extraSpool, err = os.CreateTemp("", "launcher-flare-extra-*")
+				if err != nil {
+					writeSummary(&summary, Erroring, c.Name(), fmt.Sprintf("error creating flare spool file: %s", err))
+					return
+				}
+				defer func() {
+					extraSpool.Close()
+					os.Remove(extraSpool.Name())
+				}()
+				fullFH = extraSpool
+			}
 		}
 	}
 
@@ -183,6 +218,19 @@ func flareCheckup(ctx context.Context, c checkupInt, combinedSummary io.Writer,
 
 	writeSummary(&summary, c.Status(), c.Name(), c.Summary())
 
+	if extraSpool != nil {
+		limit := budget.remaining()
+		omitted, truncated, err := copyWithinBudget(extraFH, extraSpool, path.Ext(c.ExtraFileName()) == ".zip", limit)
+		switch {
+		case err != nil:
+			writeSummary(&summary, Erroring, c.Name(), fmt.Sprintf("error copying extra data into flare: %s", err))
+		case omitted > 0:
+			writeSummary(&summary, Informational, c.Name(), fmt.Sprintf("omitted %d files from extra data to stay within flare size budget of %d bytes", omitted, limit))
+		case truncated:
+			writeSummary(&summary, Informational, c.Name(), fmt.Sprintf("extra data truncated at %d bytes to stay within flare size budget", limit))
+		}
+	}
+
 	if data := c.Data(); data != nil {
 		dataFH, err := flare.Create(path.Join(c.Name(), "data.json"))
 		if err != nil {
@@ -217,12 +265,19 @@ func logCheckup(ctx context.Context, c checkupInt, slogger *slog.Logger) { // no
 	)
 }
 
-func RunDoctor(ctx context.Context, k types.Knapsack, w io.Writer) {
+// RunDoctor runs the doctor checkups, writing a human-readable summary of each to w as it completes.
+// It returns the worst status of all the checkups.
+func RunDoctor(ctx context.Context, k types.Knapsack, w io.Writer) Status {
 	failingCheckups := []string{}
 	warningCheckups := []string{}
+	worstStatus := Passing
 
 	for _, c := range checkupsFor(k, doctorSupported) {
-		switch runDoctorCheckup(ctx, c, w) {
+		result := runDoctorCheckup(ctx, c)
+		writeSummary(w, result.Status, result.Name, result.Summary)
+		worstStatus = worseStatus(worstStatus, result.Status)
+
+		switch result.Status {
 		case Warning:
 			warningCheckups = append(warningCheckups, c.Name())
 		case Failing, Erroring:
@@ -249,15 +304,15 @@ func RunDoctor(ctx context.Context, k types.Knapsack, w io.Writer) {
 		}
 		fmt.Fprintf(w, "\n")
 	}
+
+	return worstStatus
 }
 
-func runDoctorCheckup(ctx context.Context, c checkupInt, w io.Writer) Status {
+func runDoctorCheckup(ctx context.Context, c checkupInt) CheckupResult {
 	ctx, cancel := context.WithTimeout(ctx, 20*time.Second)
 	defer cancel()
 
-	doctorCheckup(ctx, c, w)
-
-	return c.Status()
+	return doctorCheckup(ctx, c)
 }
 
 type runtimeEnvironmentType string
@@ -268,9 +323,24 @@ const (
 	InSituPerformanceEnvironment runtimeEnvironmentType = "in situ performance"
 )
 
-func RunFlare(ctx context.Context, k types.Knapsack, flareStream io.WriteCloser, runtimeEnvironment runtimeEnvironmentType) error {
-	flare := zip.NewWriter(flareStream)
+func RunFlare(ctx context.Context, k types.Knapsack, flareStream io.WriteCloser, runtimeEnvironment runtimeEnvironmentType, opts FlareOptions) error {
 	combinedSummary := bytes.Buffer{}
+	budget := newFlareBudget(opts.SizeBudgetKB)
+
+	// Every file written into the flare is redacted. If the configured rules are invalid, we still
+	// want a flare, so fall back to the built-in rules.
+	redactor, err := flareRedactor(k)
+	if err != nil {
+		k.Slogger().Log(ctx, slog.LevelWarn,
+			"invalid flare redaction rules, falling back to built-in rules",
+			"err", err,
+		)
+		writeSummary(&combinedSummary, Warning, "flare", fmt.Sprintf("invalid redaction rules, using built-in rules: %s", err))
+		if redactor, err = defaultFlareRedactor(k); err != nil {
+			return errors.Join(fmt.Errorf("configuring flare redaction: %w", err), flareStream.Close())
+		}
+	}
+	flare := redact.NewZipWriter(io.MultiWriter(flareStream, budget), redactor)
 
 	// If this is trigger for performance reasons, we run a limited set of checkups
 	flareType := flareSupported
@@ -298,18 +368,29 @@ func RunFlare(ctx context.Context, k types.Knapsack, flareStream io.WriteCloser,
 
 	// Note our runtime context.
 	writeSummary(&combinedSummary, Informational, "flare", fmt.Sprintf("running %s", runtimeEnvironment))
+	if opts.isScoped() {
+		writeSummary(&combinedSummary, Informational, "flare", fmt.Sprintf("scoped flare: %s", opts))
+	}
 	if err := writeFlareEnv(flare, runtimeEnvironment); err != nil {
 		return errors.Join(fmt.Errorf("writing flare environment: %w", err), finalize())
 	}
 
-	for _, c := range checkupsFor(k, flareType) {
+	logsSince := opts.logsSince(time.Now())
+	for _, c := range scopedCheckups(checkupsFor(k, flareType), opts) {
 		// Log that we're doing this, because sometimes we seem to hang, and we want to debug it.
 		k.Slogger().Log(ctx, slog.LevelInfo,
 			"running flare checkup",
 			"name", c.Name(),
 			"runtime_environment", runtimeEnvironment,
 		)
-		flareCheckup(ctx, c, &combinedSummary, flare)
+		if sc, ok := c.(scopeable); ok {
+			var maxExtraBytes int64
+			if !budget.unlimited() {
+				maxExtraBytes = budget.remaining()
+			}
+			sc.setScope(logsSince, maxExtraBytes)
+		}
+		flareCheckup(ctx, c, &combinedSummary, flare, budget)
 		if err := flare.Flush(); err != nil {
 			return errors.Join(fmt.Errorf("writing flare zip: %w", err), finalize())
 		}
@@ -327,7 +408,7 @@ func RunFlare(ctx context.Context, k types.Knapsack, flareStream io.WriteCloser,
 // by multiple installations. This is less of an issue when the flare is run in situ, but should be noted because
 // we may need to pay closer attention to the results. When run standalone without a config argument passed, it would be
 // possible for flare to default to reading the directories for the wrong installation
-func noteMultipleInstallations(z *zip.Writer) {
+func noteMultipleInstallations(z zipFile) {
 	defaultPath := strings.TrimSuffix(launcher.DefaultPath(launcher.BinDirectory), string(filepath.Separator))
 	pathParts := strings.Split(defaultPath, string(filepath.Separator))
 
@@ -371,7 +452,7 @@ func noteMultipleInstallations(z *zip.Writer) {
 	json.NewEncoder(w).Encode(matchingDirs)
 }
 
-func writeFlareEnv(z *zip.Writer, runtimeEnvironment runtimeEnvironmentType) error {
+func writeFlareEnv(z zipFile, runtimeEnvironment runtimeEnvironmentType) error {
 	if _, err := z.Create(fmt.Sprintf("FLARE_RUNNING_%s", strings.ReplaceAll(strings.ToUpper(string(runtimeEnvironment)), " ", "_"))); err != nil {
 		return fmt.Errorf("making env note file: %s", err)
 	}
@@ -0,0 +1,199 @@
+package checkups
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/kolide/kit/version"
+	"github.com/kolide/launcher/v2/ee/agent/types"
+)
+
+// CheckupResult is the machine-readable result of a single doctor checkup. The JSON field names
+// are a stable schema that fleet scripts depend on -- do not rename them.
+type CheckupResult struct {
+	Name            string  `json:"name"`
+	Status          Status  `json:"status"`
+	Summary         string  `json:"summary"`
+	Data            any     `json:"data,omitempty"`
+	DurationSeconds float64 `json:"duration_seconds"`
+}
+
+// DoctorReport is the machine-readable result of running doctor.
+type DoctorReport struct {
+	SchemaVersion   int             `json:"schema_version"`
+	LauncherVersion string          `json:"launcher_version"`
+	Hostname        string          `json:"hostname"`
+	Timestamp       time.Time       `json:"timestamp"`
+	Status          Status          `json:"status"` // The worst status of all the checkups
+	Checkups        []CheckupResult `json:"checkups"`
+}
+
+// doctorReportSchemaVersion should be incremented on any breaking change to the report schema.
+const doctorReportSchemaVersion = 1
+
+// RunDoctorReport runs the doctor checkups, returning their results.
+func RunDoctorReport(ctx context.Context, k types.Knapsack) *DoctorReport {
+	hostname, _ := os.Hostname()
+	report := &DoctorReport{
+		SchemaVersion:   doctorReportSchemaVersion,
+		LauncherVersion: version.Version().Version,
+		Hostname:        hostname,
+		Timestamp:       time.Now().UTC(),
+		Status:          Passing,
+		Checkups:        make([]CheckupResult, 0),
+	}
+
+	for _, c := range checkupsFor(k, doctorSupported) {
+		report.addResult(runDoctorCheckup(ctx, c))
+	}
+
+	return report
+}
+
+func (r *DoctorReport) addResult(result CheckupResult) {
+	r.Checkups = append(r.Checkups, result)
+	r.Status = worseStatus(r.Status, result.Status)
+}
+
+// statusSeverity orders statuses from best to worst. A checkup that ran without
+// reporting a status is not considered a problem.
+func statusSeverity(s Status) int {
+	switch s {
+	case Informational, Passing, Unknown:
+		return 0
+	case Warning:
+		return 1
+	case Failing:
+		return 2
+	case Erroring:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// worseStatus returns the worse of the two statuses.
+func worseStatus(a, b Status) Status {
+	if statusSeverity(b) > statusSeverity(a) {
+		return b
+	}
+	return a
+}
+
+// WriteJSON writes the report as JSON.
+func (r *DoctorReport) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(r); err != nil {
+		return fmt.Errorf("encoding doctor report: %w", err)
+	}
+	return nil
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Hostname  string          `xml:"hostname,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Error     *junitFailure `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the report as JUnit XML, with a test case per checkup. Failing checkups
+// are reported as failures, and checkups that could not run as errors. JUnit has no notion of
+// warnings, so warning checkups pass, with their status noted in the test case output.
+func (r *DoctorReport) WriteJUnit(w io.Writer) error {
+	suite := junitTestSuite{
+		Name:      "launcher doctor",
+		Tests:     len(r.Checkups),
+		Timestamp: r.Timestamp.Format(time.RFC3339),
+		Hostname:  r.Hostname,
+		Cases:     make([]junitTestCase, 0, len(r.Checkups)),
+	}
+
+	var totalSeconds float64
+	for _, c := range r.Checkups {
+		totalSeconds += c.DurationSeconds
+
+		testCase := junitTestCase{
+			Name:      c.Name,
+			ClassName: "launcher.doctor",
+			Time:      fmt.Sprintf("%.3f", c.DurationSeconds),
+			SystemOut: fmt.Sprintf("%s: %s", c.Status, c.Summary),
+		}
+
+		if c.Data != nil {
+			if data, err := json.Marshal(c.Data); err == nil {
+				testCase.SystemOut += "\n" + string(data)
+			}
+		}
+
+		switch c.Status {
+		case Failing:
+			suite.Failures += 1
+			testCase.Failure = &junitFailure{Message: c.Summary, Type: string(c.Status), Text: c.Summary}
+		case Erroring:
+			suite.Errors += 1
+			testCase.Error = &junitFailure{Message: c.Summary, Type: string(c.Status), Text: c.Summary}
+		case Unknown, Informational, Passing, Warning:
+			// Passing, as far as JUnit is concerned
+		}
+
+		suite.Cases = append(suite.Cases, testCase)
+	}
+	suite.Time = fmt.Sprintf("%.3f", totalSeconds)
+
+	suites := junitTestSuites{
+		Name:     "launcher doctor",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Errors:   suite.Errors,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("writing xml header: %w", err)
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return fmt.Errorf("encoding doctor report: %w", err)
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
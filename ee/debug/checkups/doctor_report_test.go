@@ -0,0 +1,85 @@
+package checkups
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testDoctorReport() *DoctorReport {
+	report := &DoctorReport{
+		SchemaVersion: doctorReportSchemaVersion,
+		Hostname:      "test-host",
+		Timestamp:     time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC),
+		Status:        Passing,
+	}
+	report.addResult(CheckupResult{Name: "Platform", Status: Informational, Summary: "linux", Data: map[string]string{"os": "linux"}})
+	report.addResult(CheckupResult{Name: "Osquery", Status: Passing, Summary: "osquery is running"})
+	report.addResult(CheckupResult{Name: "DNS Resolution", Status: Warning, Summary: "slow"})
+	report.addResult(CheckupResult{Name: "Check communication with Kolide", Status: Failing, Summary: "unreachable"})
+	report.addResult(CheckupResult{Name: "Network Report", Status: Erroring, Summary: "failed to run: timeout"})
+	return report
+}
+
+func Test_worseStatus(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, Passing, worseStatus(Passing, Informational), "equally good statuses keep the first")
+	require.Equal(t, Warning, worseStatus(Passing, Warning))
+	require.Equal(t, Failing, worseStatus(Failing, Warning))
+	require.Equal(t, Erroring, worseStatus(Failing, Erroring))
+	require.Equal(t, Passing, worseStatus(Passing, Unknown))
+	require.Equal(t, Erroring, testDoctorReport().Status)
+}
+
+func TestDoctorReport_WriteJSON(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	require.NoError(t, testDoctorReport().WriteJSON(&out))
+
+	// Check the schema, rather than the Go types, since that's what scripts depend on
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	require.Equal(t, float64(1), decoded["schema_version"])
+	require.Equal(t, "Error", decoded["status"])
+	require.Equal(t, "test-host", decoded["hostname"])
+
+	checkups, ok := decoded["checkups"].([]any)
+	require.True(t, ok)
+	require.Len(t, checkups, 5)
+	require.Equal(t, map[string]any{
+		"name":             "Platform",
+		"status":           "Informational",
+		"summary":          "linux",
+		"data":             map[string]any{"os": "linux"},
+		"duration_seconds": float64(0),
+	}, checkups[0])
+}
+
+func TestDoctorReport_WriteJUnit(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	require.NoError(t, testDoctorReport().WriteJUnit(&out))
+
+	var decoded junitTestSuites
+	require.NoError(t, xml.Unmarshal(out.Bytes(), &decoded))
+	require.Equal(t, 5, decoded.Tests)
+	require.Equal(t, 1, decoded.Failures)
+	require.Equal(t, 1, decoded.Errors)
+	require.Len(t, decoded.Suites, 1)
+
+	cases := decoded.Suites[0].Cases
+	require.Len(t, cases, 5)
+	require.Nil(t, cases[2].Failure, "warnings should not fail")
+	require.Contains(t, cases[2].SystemOut, "Warning: slow")
+	require.NotNil(t, cases[3].Failure)
+	require.Equal(t, "unreachable", cases[3].Failure.Message)
+	require.NotNil(t, cases[4].Error)
+	require.Equal(t, "Error", cases[4].Error.Type)
+}
@@ -0,0 +1,453 @@
+package checkups
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kolide/launcher/v2/ee/agent/types"
+	"github.com/kolide/launcher/v2/ee/allowedcmd"
+)
+
+const (
+	// externalCheckupsDirectory is the directory, under the root directory, that additional checkup
+	// definitions are loaded from. Each *.json file holds a single definition, or an array of them.
+	externalCheckupsDirectory = "checkups.d"
+
+	externalCheckupTimeout = 10 * time.Second
+	externalOutputLimit    = 1024
+)
+
+// externalCheckupDefinition declares a checkup as config, rather than code. Exactly one of
+// Osquery, File, or Command must be set.
+type externalCheckupDefinition struct {
+	Name string `json:"name"`
+	// Targets are where this checkup runs: any of "doctor", "flare", and "log". Defaults to all.
+	Targets []string `json:"targets,omitempty"`
+	// FailureStatus is the status reported when the assertion does not hold: "Warning" or "Failing". Defaults to Failing.
+	FailureStatus Status `json:"failure_status,omitempty"`
+
+	Osquery *osqueryAssertion `json:"osquery,omitempty"`
+	File    *fileAssertion    `json:"file,omitempty"`
+	Command *commandAssertion `json:"command,omitempty"`
+}
+
+// osqueryAssertion runs a query, and checks the number of rows returned, and optionally the value
+// of a column in every row.
+type osqueryAssertion struct {
+	Query   string  `json:"query"`
+	MinRows *int    `json:"min_rows,omitempty"`
+	MaxRows *int    `json:"max_rows,omitempty"`
+	Column  string  `json:"column,omitempty"`
+	Equals  *string `json:"equals,omitempty"`
+	Matches string  `json:"matches,omitempty"`
+}
+
+// fileAssertion checks whether a file exists, and optionally that it is not more permissive than MaxPermissions.
+type fileAssertion struct {
+	Path           string `json:"path"`
+	Exists         *bool  `json:"exists,omitempty"` // Defaults to true
+	MaxPermissions string `json:"max_permissions,omitempty"`
+}
+
+// commandAssertion runs an allowed command, and checks its exit code and optionally its output.
+// Only the commands and subcommands in externalCommands may be run.
+type commandAssertion struct {
+	Command  string   `json:"command"`
+	Args     []string `json:"args,omitempty"`
+	Matches  string   `json:"matches,omitempty"`
+	ExitCode int      `json:"exit_code,omitempty"`
+}
+
+var externalTargets = map[string]targetBits{
+	"doctor": doctorSupported,
+	"flare":  flareSupported,
+	"log":    logSupported | startupLogSupported,
+}
+
+// parseExternalCheckupDefinitions parses either a single definition, or an array of definitions.
+func parseExternalCheckupDefinitions(raw []byte) ([]externalCheckupDefinition, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	if raw[0] == '{' {
+		var def externalCheckupDefinition
+		if err := json.Unmarshal(raw, &def); err != nil {
+			return nil, fmt.Errorf("unmarshalling checkup definition: %w", err)
+		}
+		return []externalCheckupDefinition{def}, nil
+	}
+
+	var defs []externalCheckupDefinition
+	if err := json.Unmarshal(raw, &defs); err != nil {
+		return nil, fmt.Errorf("unmarshalling checkup definitions: %w", err)
+	}
+	return defs, nil
+}
+
+// externalCheckups returns the checkups declared in the external checkups directory, and via the
+// control server. Control server definitions replace directory definitions with the same name.
+// Definitions that cannot be loaded are returned as checkups that report the problem, so that
+// they are not silently ignored.
+func externalCheckups(k types.Knapsack) []potentialCheckup {
+	defs := make(map[string]externalCheckupDefinition)
+	invalid := make([]potentialCheckup, 0)
+
+	addInvalid := func(source string, err error) {
+		invalid = append(invalid, potentialCheckup{
+			c:       &externalCheckup{name: fmt.Sprintf("External checkups from %s", source), loadErr: err},
+			targets: doctorSupported | flareSupported,
+		})
+	}
+
+	addDefinitions := func(source string, raw []byte) {
+		parsed, err := parseExternalCheckupDefinitions(raw)
+		if err != nil {
+			addInvalid(source, err)
+			return
+		}
+		for _, def := range parsed {
+			defs[def.Name] = def
+		}
+	}
+
+	if k.RootDirectory() != "" {
+		paths, _ := filepath.Glob(filepath.Join(k.RootDirectory(), externalCheckupsDirectory, "*.json"))
+		sort.Strings(paths)
+		for _, p := range paths {
+			raw, err := os.ReadFile(p)
+			if err != nil {
+				addInvalid(filepath.Base(p), fmt.Errorf("reading checkup definitions: %w", err))
+				continue
+			}
+			addDefinitions(filepath.Base(p), raw)
+		}
+	}
+
+	if fromFlag := k.ExternalCheckups(); fromFlag != "" {
+		addDefinitions("control server", []byte(fromFlag))
+	}
+
+	names := make([]string, 0, len(defs))
+	for name := range defs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	checkups := make([]potentialCheckup, 0, len(defs)+len(invalid))
+	for _, name := range names {
+		checkups = append(checkups, newExternalCheckup(k, defs[name]))
+	}
+
+	return append(checkups, invalid...)
+}
+
+func newExternalCheckup(k types.Knapsack, def externalCheckupDefinition) potentialCheckup {
+	c := &externalCheckup{k: k, def: def, name: def.Name}
+	targets, err := c.load()
+	if err != nil {
+		c.loadErr = fmt.Errorf("invalid checkup definition: %w", err)
+		// Still surface the problem, but not in the checkpointer logs
+		targets = doctorSupported | flareSupported
+	}
+	if c.name == "" {
+		c.name = "Unnamed external checkup"
+	}
+
+	return potentialCheckup{c: c, targets: targets}
+}
+
+type externalCheckup struct {
+	k       types.Knapsack
+	def     externalCheckupDefinition
+	name    string
+	loadErr error
+	matches *regexp.Regexp
+	maxPerm os.FileMode
+
+	status  Status
+	summary string
+	data    map[string]any
+}
+
+// load validates the definition, and returns the targets it runs in.
+func (e *externalCheckup) load() (targetBits, error) {
+	if e.def.Name == "" {
+		return 0, errors.New("name is required")
+	}
+
+	var targets targetBits
+	if len(e.def.Targets) == 0 {
+		targets = doctorSupported | flareSupported | logSupported | startupLogSupported
+	}
+	for _, t := range e.def.Targets {
+		bits, ok := externalTargets[strings.ToLower(t)]
+		if !ok {
+			return 0, fmt.Errorf("unknown target %q", t)
+		}
+		targets |= bits
+	}
+
+	switch e.def.FailureStatus {
+	case "":
+		e.def.FailureStatus = Failing
+	case Warning, Failing:
+	default:
+		return 0, fmt.Errorf("failure_status must be %s or %s, got %q", Warning, Failing, e.def.FailureStatus)
+	}
+
+	assertions := 0
+	var matches string
+	if e.def.Osquery != nil {
+		assertions += 1
+		if e.def.Osquery.Query == "" {
+			return 0, errors.New("osquery query is required")
+		}
+		if e.def.Osquery.Column == "" && (e.def.Osquery.Equals != nil || e.def.Osquery.Matches != "") {
+			return 0, errors.New("osquery column is required to check a value")
+		}
+		matches = e.def.Osquery.Matches
+	}
+	if e.def.File != nil {
+		assertions += 1
+		if e.def.File.Path == "" {
+			return 0, errors.New("file path is required")
+		}
+		if e.def.File.MaxPermissions != "" {
+			perm, err := strconv.ParseUint(e.def.File.MaxPermissions, 8, 32)
+			if err != nil || perm > 0o777 {
+				return 0, fmt.Errorf("file max_permissions must be octal permissions like 0644, got %q", e.def.File.MaxPermissions)
+			}
+			e.maxPerm = os.FileMode(perm)
+		}
+	}
+	if e.def.Command != nil {
+		assertions += 1
+		if err := checkExternalCommandAllowed(e.def.Command.Command, e.def.Command.Args); err != nil {
+			return 0, err
+		}
+		matches = e.def.Command.Matches
+	}
+	if assertions != 1 {
+		return 0, errors.New("exactly one of osquery, file, or command must be set")
+	}
+
+	if matches != "" {
+		re, err := regexp.Compile(matches)
+		if err != nil {
+			return 0, fmt.Errorf("compiling matches: %w", err)
+		}
+		e.matches = re
+	}
+
+	return targets, nil
+}
+
+func (e *externalCheckup) Name() string          { return e.name }
+func (e *externalCheckup) ExtraFileName() string { return "" }
+func (e *externalCheckup) Status() Status        { return e.status }
+func (e *externalCheckup) Summary() string       { return e.summary }
+func (e *externalCheckup) Data() any             { return e.data }
+
+func (e *externalCheckup) Run(ctx context.Context, extraWriter io.Writer) error {
+	e.data = make(map[string]any)
+
+	if e.loadErr != nil {
+		e.status = Erroring
+		e.summary = e.loadErr.Error()
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, externalCheckupTimeout)
+	defer cancel()
+
+	var failure string
+	var err error
+	switch {
+	case e.def.Osquery != nil:
+		failure, err = e.runOsquery(ctx)
+	case e.def.File != nil:
+		failure = e.runFile()
+	case e.def.Command != nil:
+		failure, err = e.runCommand(ctx)
+	}
+
+	switch {
+	case err != nil:
+		e.status = Erroring
+		e.summary = err.Error()
+	case failure != "":
+		e.status = e.def.FailureStatus
+		e.summary = failure
+	default:
+		e.status = Passing
+		e.summary = "assertion passed"
+	}
+
+	return nil
+}
+
+// runOsquery returns a description of the failure, if the assertion does not hold.
+func (e *externalCheckup) runOsquery(ctx context.Context) (string, error) {
+	a := e.def.Osquery
+
+	rows, err := runOsqueryQuery(ctx, e.k.LatestOsquerydPath(ctx), a.Query, externalCheckupTimeout)
+	if err != nil {
+		return "", fmt.Errorf("running query: %w", err)
+	}
+	e.data["rows"] = len(rows)
+
+	if a.MinRows != nil && len(rows) < *a.MinRows {
+		return fmt.Sprintf("query returned %d rows, expected at least %d", len(rows), *a.MinRows), nil
+	}
+	if a.MaxRows != nil && len(rows) > *a.MaxRows {
+		return fmt.Sprintf("query returned %d rows, expected at most %d", len(rows), *a.MaxRows), nil
+	}
+
+	if a.Column == "" {
+		return "", nil
+	}
+
+	for i, row := range rows {
+		value, ok := row[a.Column]
+		if !ok {
+			return fmt.Sprintf("row %d has no column %s", i, a.Column), nil
+		}
+		if a.Equals != nil && value != *a.Equals {
+			e.data["value"] = value
+			return fmt.Sprintf("row %d: %s is %q, expected %q", i, a.Column, value, *a.Equals), nil
+		}
+		if e.matches != nil && !e.matches.MatchString(value) {
+			e.data["value"] = value
+			return fmt.Sprintf("row %d: %s is %q, expected to match %s", i, a.Column, value, a.Matches), nil
+		}
+	}
+
+	return "", nil
+}
+
+// runFile returns a description of the failure, if the assertion does not hold.
+func (e *externalCheckup) runFile() string {
+	a := e.def.File
+	expectExists := a.Exists == nil || *a.Exists
+
+	info, err := os.Stat(a.Path)
+	exists := err == nil
+	e.data["exists"] = exists
+
+	if !exists {
+		if expectExists {
+			return fmt.Sprintf("%s does not exist", a.Path)
+		}
+		return ""
+	}
+
+	if !expectExists {
+		return fmt.Sprintf("%s exists", a.Path)
+	}
+
+	e.data["permissions"] = fmt.Sprintf("%#o", info.Mode().Perm())
+	if a.MaxPermissions != "" && info.Mode().Perm()&^e.maxPerm != 0 {
+		return fmt.Sprintf("%s has permissions %#o, expected at most %#o", a.Path, info.Mode().Perm(), e.maxPerm)
+	}
+
+	return ""
+}
+
+// runCommand returns a description of the failure, if the assertion does not hold.
+func (e *externalCheckup) runCommand(ctx context.Context) (string, error) {
+	a := e.def.Command
+
+	cmd, err := externalCommands[a.Command].cmd.Cmd(ctx, a.Args...)
+	if err != nil {
+		return "", fmt.Errorf("creating command: %w", err)
+	}
+
+	out, err := cmd.CombinedOutput()
+	exitCode := 0
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return "", fmt.Errorf("running %s: %w", a.Command, err)
+		}
+		exitCode = exitErr.ExitCode()
+	}
+
+	e.data["exit_code"] = exitCode
+	if len(out) > externalOutputLimit {
+		e.data["output"] = string(out[:externalOutputLimit]) + "..."
+	} else {
+		e.data["output"] = string(out)
+	}
+
+	if exitCode != a.ExitCode {
+		return fmt.Sprintf("%s exited with %d, expected %d", a.Command, exitCode, a.ExitCode), nil
+	}
+	if e.matches != nil && !e.matches.Match(out) {
+		return fmt.Sprintf("%s output did not match %s", a.Command, a.Matches), nil
+	}
+
+	return "", nil
+}
+
+// externalCommand is a command that external checkups may run. Since checkup definitions can be
+// pushed remotely, and run as root, we only allow the read-only invocations listed here.
+type externalCommand struct {
+	cmd         allowedcmd.AllowedCommand
+	invocations []externalInvocation
+}
+
+// externalInvocation is an allowed invocation of an external command: the args must start with
+// exactly prefix, and may be followed by at most maxOperands operands. Operands are never options,
+// so that they cannot change what the command does.
+type externalInvocation struct {
+	prefix      []string
+	maxOperands int
+}
+
+func (i externalInvocation) allows(args []string) bool {
+	if len(args) < len(i.prefix) || !slices.Equal(args[:len(i.prefix)], i.prefix) {
+		return false
+	}
+
+	operands := args[len(i.prefix):]
+	if len(operands) > i.maxOperands {
+		return false
+	}
+	for _, o := range operands {
+		if o == "" || strings.HasPrefix(o, "-") {
+			return false
+		}
+	}
+
+	return true
+}
+
+func checkExternalCommandAllowed(command string, args []string) error {
+	allowed, ok := externalCommands[command]
+	if !ok {
+		return fmt.Errorf("command %q is not allowed in external checkups", command)
+	}
+
+	for _, invocation := range allowed.invocations {
+		if invocation.allows(args) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%s %s is not allowed in external checkups", command, strings.Join(args, " "))
+}
@@ -0,0 +1,54 @@
+//go:build darwin
+
+package checkups
+
+import "github.com/kolide/launcher/v2/ee/allowedcmd"
+
+var externalCommands = map[string]externalCommand{
+	"launchctl": {cmd: allowedcmd.Launchctl, invocations: []externalInvocation{
+		{prefix: []string{"print"}, maxOperands: 1},
+		{prefix: []string{"list"}, maxOperands: 1},
+	}},
+	"profiles": {cmd: allowedcmd.Profiles, invocations: []externalInvocation{
+		{prefix: []string{"show"}},
+		{prefix: []string{"list"}},
+		{prefix: []string{"status", "-type", "enrollment"}},
+	}},
+	"fdesetup": {cmd: allowedcmd.Fdesetup, invocations: []externalInvocation{
+		{prefix: []string{"status"}},
+		{prefix: []string{"isactive"}},
+	}},
+	"ps": {cmd: allowedcmd.Ps, invocations: []externalInvocation{
+		{prefix: []string{"-e"}},
+		{prefix: []string{"-ef"}},
+		{prefix: []string{"aux"}},
+	}},
+	"diskutil": {cmd: allowedcmd.Diskutil, invocations: []externalInvocation{
+		{prefix: []string{"list"}, maxOperands: 1},
+		{prefix: []string{"info"}, maxOperands: 1},
+	}},
+	"pkgutil": {cmd: allowedcmd.Pkgutil, invocations: []externalInvocation{
+		{prefix: []string{"--pkgs"}},
+		{prefix: []string{"--pkg-info"}, maxOperands: 1},
+		{prefix: []string{"--files"}, maxOperands: 1},
+	}},
+	"scutil": {cmd: allowedcmd.Scutil, invocations: []externalInvocation{
+		{prefix: []string{"--get"}, maxOperands: 1},
+		{prefix: []string{"--dns"}},
+		{prefix: []string{"--proxy"}},
+	}},
+	"codesign": {cmd: allowedcmd.Codesign, invocations: []externalInvocation{
+		{prefix: []string{"-dv"}, maxOperands: 1},
+		{prefix: []string{"-d"}, maxOperands: 1},
+		{prefix: []string{"-v"}, maxOperands: 1},
+		{prefix: []string{"--verify"}, maxOperands: 1},
+	}},
+	"system_profiler": {cmd: allowedcmd.SystemProfiler, invocations: []externalInvocation{
+		{prefix: []string{}, maxOperands: 1},
+		{prefix: []string{"-json"}, maxOperands: 1},
+	}},
+	"ioreg": {cmd: allowedcmd.Ioreg, invocations: []externalInvocation{
+		{prefix: []string{"-c"}, maxOperands: 1},
+		{prefix: []string{"-rd1", "-c"}, maxOperands: 1},
+	}},
+}
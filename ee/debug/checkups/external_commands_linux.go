@@ -0,0 +1,50 @@
+//go:build linux
+
+package checkups
+
+import "github.com/kolide/launcher/v2/ee/allowedcmd"
+
+var externalCommands = map[string]externalCommand{
+	"systemctl": {cmd: allowedcmd.Systemctl, invocations: []externalInvocation{
+		{prefix: []string{"status"}, maxOperands: 1},
+		{prefix: []string{"is-active"}, maxOperands: 1},
+		{prefix: []string{"is-enabled"}, maxOperands: 1},
+		{prefix: []string{"is-failed"}, maxOperands: 1},
+		{prefix: []string{"show"}, maxOperands: 1},
+	}},
+	"ps": {cmd: allowedcmd.Ps, invocations: []externalInvocation{
+		{prefix: []string{"-e"}},
+		{prefix: []string{"-ef"}},
+		{prefix: []string{"aux"}},
+	}},
+	"lsblk": {cmd: allowedcmd.Lsblk, invocations: []externalInvocation{
+		{prefix: []string{}},
+		{prefix: []string{"-J"}},
+		{prefix: []string{"-f"}},
+	}},
+	"loginctl": {cmd: allowedcmd.Loginctl, invocations: []externalInvocation{
+		{prefix: []string{"list-sessions"}},
+		{prefix: []string{"show-session"}, maxOperands: 1},
+		{prefix: []string{"show-user"}, maxOperands: 1},
+	}},
+	"dpkg": {cmd: allowedcmd.Dpkg, invocations: []externalInvocation{
+		{prefix: []string{"-l"}, maxOperands: 1},
+		{prefix: []string{"--list"}, maxOperands: 1},
+		{prefix: []string{"-s"}, maxOperands: 1},
+		{prefix: []string{"--status"}, maxOperands: 1},
+	}},
+	"rpm": {cmd: allowedcmd.Rpm, invocations: []externalInvocation{
+		{prefix: []string{"-q"}, maxOperands: 1},
+		{prefix: []string{"-qa"}},
+	}},
+	"snap": {cmd: allowedcmd.Snap, invocations: []externalInvocation{
+		{prefix: []string{"list"}, maxOperands: 1},
+	}},
+	"flatpak": {cmd: allowedcmd.Flatpak, invocations: []externalInvocation{
+		{prefix: []string{"list"}},
+	}},
+	"gsettings": {cmd: allowedcmd.Gsettings, invocations: []externalInvocation{
+		{prefix: []string{"get"}, maxOperands: 2},
+		{prefix: []string{"list-recursively"}, maxOperands: 1},
+	}},
+}
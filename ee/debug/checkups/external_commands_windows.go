@@ -0,0 +1,19 @@
+//go:build windows
+
+package checkups
+
+import "github.com/kolide/launcher/v2/ee/allowedcmd"
+
+var externalCommands = map[string]externalCommand{
+	"dsregcmd": {cmd: allowedcmd.Dsregcmd, invocations: []externalInvocation{
+		{prefix: []string{"/status"}},
+	}},
+	"powercfg": {cmd: allowedcmd.Powercfg, invocations: []externalInvocation{
+		{prefix: []string{"/query"}},
+		{prefix: []string{"/list"}},
+		{prefix: []string{"/a"}},
+	}},
+	"ipconfig": {cmd: allowedcmd.Ipconfig, invocations: []externalInvocation{
+		{prefix: []string{"/all"}},
+	}},
+}
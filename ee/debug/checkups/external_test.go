@@ -0,0 +1,245 @@
+package checkups
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	typesmocks "github.com/kolide/launcher/v2/ee/agent/types/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseExternalCheckupDefinitions(t *testing.T) {
+	t.Parallel()
+
+	defs, err := parseExternalCheckupDefinitions([]byte(`{"name": "single", "file": {"path": "/etc/hosts"}}`))
+	require.NoError(t, err)
+	require.Len(t, defs, 1)
+	require.Equal(t, "single", defs[0].Name)
+
+	defs, err = parseExternalCheckupDefinitions([]byte(` [{"name": "one"}, {"name": "two"}]`))
+	require.NoError(t, err)
+	require.Len(t, defs, 2)
+
+	defs, err = parseExternalCheckupDefinitions([]byte("  "))
+	require.NoError(t, err)
+	require.Empty(t, defs)
+
+	_, err = parseExternalCheckupDefinitions([]byte(`{"name": `))
+	require.Error(t, err)
+}
+
+func TestExternalCheckup_load(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name            string
+		def             string
+		expectedTargets targetBits
+		expectErr       bool
+	}{
+		{
+			name:            "defaults to all targets",
+			def:             `{"name": "hosts", "file": {"path": "/etc/hosts"}}`,
+			expectedTargets: doctorSupported | flareSupported | logSupported | startupLogSupported,
+		},
+		{
+			name:            "selected targets",
+			def:             `{"name": "hosts", "targets": ["doctor", "Flare"], "file": {"path": "/etc/hosts"}}`,
+			expectedTargets: doctorSupported | flareSupported,
+		},
+		{
+			name:      "missing name",
+			def:       `{"file": {"path": "/etc/hosts"}}`,
+			expectErr: true,
+		},
+		{
+			name:      "unknown target",
+			def:       `{"name": "hosts", "targets": ["everywhere"], "file": {"path": "/etc/hosts"}}`,
+			expectErr: true,
+		},
+		{
+			name:      "no assertion",
+			def:       `{"name": "nothing"}`,
+			expectErr: true,
+		},
+		{
+			name:      "multiple assertions",
+			def:       `{"name": "both", "file": {"path": "/etc/hosts"}, "osquery": {"query": "select 1"}}`,
+			expectErr: true,
+		},
+		{
+			name:      "invalid failure status",
+			def:       `{"name": "hosts", "failure_status": "Passing", "file": {"path": "/etc/hosts"}}`,
+			expectErr: true,
+		},
+		{
+			name:      "invalid permissions",
+			def:       `{"name": "hosts", "file": {"path": "/etc/hosts", "max_permissions": "rw-r--r--"}}`,
+			expectErr: true,
+		},
+		{
+			name:      "invalid regex",
+			def:       `{"name": "query", "osquery": {"query": "select 1 as one", "column": "one", "matches": "("}}`,
+			expectErr: true,
+		},
+		{
+			name:      "value check without column",
+			def:       `{"name": "query", "osquery": {"query": "select 1 as one", "equals": "1"}}`,
+			expectErr: true,
+		},
+		{
+			name:      "command not allowed",
+			def:       `{"name": "shell", "command": {"command": "sh", "args": ["-c", "true"]}}`,
+			expectErr: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			defs, err := parseExternalCheckupDefinitions([]byte(tt.def))
+			require.NoError(t, err)
+			require.Len(t, defs, 1)
+
+			c := &externalCheckup{def: defs[0]}
+			targets, err := c.load()
+			if tt.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedTargets, targets)
+		})
+	}
+}
+
+func TestExternalCheckup_file(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("file permissions are not meaningful on windows")
+	}
+
+	dir := t.TempDir()
+	restrictive := filepath.Join(dir, "restrictive")
+	require.NoError(t, os.WriteFile(restrictive, []byte("test"), 0600))
+	permissive := filepath.Join(dir, "permissive")
+	require.NoError(t, os.WriteFile(permissive, []byte("test"), 0600))
+	require.NoError(t, os.Chmod(permissive, 0666))
+
+	for _, tt := range []struct {
+		name           string
+		file           fileAssertion
+		failureStatus  Status
+		expectedStatus Status
+	}{
+		{
+			name:           "exists",
+			file:           fileAssertion{Path: restrictive},
+			expectedStatus: Passing,
+		},
+		{
+			name:           "does not exist",
+			file:           fileAssertion{Path: filepath.Join(dir, "missing")},
+			expectedStatus: Failing,
+		},
+		{
+			name:           "does not exist, with warning",
+			file:           fileAssertion{Path: filepath.Join(dir, "missing")},
+			failureStatus:  Warning,
+			expectedStatus: Warning,
+		},
+		{
+			name:           "expected not to exist",
+			file:           fileAssertion{Path: restrictive, Exists: new(bool)},
+			expectedStatus: Failing,
+		},
+		{
+			name:           "permissions within limit",
+			file:           fileAssertion{Path: restrictive, MaxPermissions: "0644"},
+			expectedStatus: Passing,
+		},
+		{
+			name:           "permissions too broad",
+			file:           fileAssertion{Path: permissive, MaxPermissions: "0644"},
+			expectedStatus: Failing,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := newExternalCheckup(nil, externalCheckupDefinition{Name: tt.name, FailureStatus: tt.failureStatus, File: &tt.file})
+			require.NoError(t, p.c.Run(context.TODO(), io.Discard))
+			require.Equal(t, tt.expectedStatus, p.c.Status(), p.c.Summary())
+		})
+	}
+}
+
+func Test_externalCheckups(t *testing.T) {
+	t.Parallel()
+
+	rootDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(rootDir, externalCheckupsDirectory), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(rootDir, externalCheckupsDirectory, "a.json"), []byte(`[
+		{"name": "from directory", "targets": ["doctor"], "file": {"path": "/does/not/exist"}},
+		{"name": "overridden", "targets": ["doctor"], "file": {"path": "/does/not/exist"}}
+	]`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(rootDir, externalCheckupsDirectory, "b.json"), []byte(`not json`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(rootDir, externalCheckupsDirectory, "ignored.txt"), []byte(`not json`), 0644))
+
+	mockKnapsack := typesmocks.NewKnapsack(t)
+	mockKnapsack.On("RootDirectory").Return(rootDir)
+	mockKnapsack.On("ExternalCheckups").Return(`{"name": "overridden", "targets": ["log"], "file": {"path": "/does/not/exist"}}`)
+
+	checkups := externalCheckups(mockKnapsack)
+	require.Len(t, checkups, 3)
+
+	require.Equal(t, "from directory", checkups[0].c.Name())
+	require.Equal(t, doctorSupported, checkups[0].targets)
+
+	require.Equal(t, "overridden", checkups[1].c.Name())
+	require.Equal(t, logSupported|startupLogSupported, checkups[1].targets)
+
+	// The invalid file is reported, rather than ignored
+	require.Equal(t, "External checkups from b.json", checkups[2].c.Name())
+	require.NoError(t, checkups[2].c.Run(context.TODO(), io.Discard))
+	require.Equal(t, Erroring, checkups[2].c.Status())
+}
+
+func TestExternalInvocation_allows(t *testing.T) {
+	t.Parallel()
+
+	invocation := externalInvocation{prefix: []string{"-q"}, maxOperands: 1}
+
+	for _, tt := range []struct {
+		name          string
+		args          []string
+		expectAllowed bool
+	}{
+		{name: "prefix only", args: []string{"-q"}, expectAllowed: true},
+		{name: "with operand", args: []string{"-q", "openssl"}, expectAllowed: true},
+		{name: "no args", args: nil},
+		{name: "different subcommand", args: []string{"-e", "openssl"}},
+		{name: "option after subcommand", args: []string{"-q", "--pipe", "sh -c id"}},
+		{name: "option after operand", args: []string{"-q", "openssl", "--pipe"}},
+		{name: "too many operands", args: []string{"-q", "openssl", "curl"}},
+		{name: "empty operand", args: []string{"-q", ""}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.expectAllowed, invocation.allows(tt.args))
+		})
+	}
+}
+
+func TestExternalCommands_restricted(t *testing.T) {
+	t.Parallel()
+
+	for name, c := range externalCommands {
+		require.NotEmpty(t, c.invocations, "%s must list its allowed invocations", name)
+	}
+}
@@ -0,0 +1,193 @@
+package checkups
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// FlareOptions scope a flare, so that support can request a smaller flare from a device
+// on a slow link. The zero value collects a full flare.
+type FlareOptions struct {
+	// IncludeCheckups, if set, limits the flare to checkups whose names contain one of these
+	// terms (case-insensitive). For example, "osquery" selects all the osquery checkups.
+	IncludeCheckups []string
+	// ExcludeCheckups skips checkups whose names contain one of these terms (case-insensitive).
+	ExcludeCheckups []string
+	// LogWindow, if set, limits log collection to logs from this far back.
+	LogWindow time.Duration
+	// SizeBudgetKB, if set, limits the size of the flare. Expensive checkups are skipped unless
+	// explicitly included, and extra data is truncated once the budget is exhausted.
+	SizeBudgetKB int
+}
+
+func (o FlareOptions) isScoped() bool {
+	return len(o.IncludeCheckups) > 0 || len(o.ExcludeCheckups) > 0 || o.LogWindow > 0 || o.SizeBudgetKB > 0
+}
+
+func (o FlareOptions) String() string {
+	return fmt.Sprintf("include=%s exclude=%s log_window=%s size_budget_kb=%d",
+		strings.Join(o.IncludeCheckups, ","), strings.Join(o.ExcludeCheckups, ","), o.LogWindow, o.SizeBudgetKB)
+}
+
+// logsSince returns the earliest time to collect logs from, or the zero time for all logs.
+func (o FlareOptions) logsSince(now time.Time) time.Time {
+	if o.LogWindow <= 0 {
+		return time.Time{}
+	}
+	return now.Add(-o.LogWindow)
+}
+
+// scopedCheckups filters the given checkups according to the flare options.
+func scopedCheckups(checkups []checkupInt, opts FlareOptions) []checkupInt {
+	scoped := make([]checkupInt, 0, len(checkups))
+	for _, c := range checkups {
+		explicitlyIncluded := nameMatches(c.Name(), opts.IncludeCheckups)
+		if len(opts.IncludeCheckups) > 0 && !explicitlyIncluded {
+			continue
+		}
+		if nameMatches(c.Name(), opts.ExcludeCheckups) {
+			continue
+		}
+		if opts.SizeBudgetKB > 0 && isExpensive(c) && !explicitlyIncluded {
+			continue
+		}
+		scoped = append(scoped, c)
+	}
+	return scoped
+}
+
+func nameMatches(name string, terms []string) bool {
+	for _, term := range terms {
+		term = strings.TrimSpace(term)
+		if term != "" && strings.Contains(strings.ToLower(name), strings.ToLower(term)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isExpensive identifies checkups that collect large amounts of data, or take a long time to run.
+// These are skipped in flares with a size budget, unless explicitly included.
+func isExpensive(c checkupInt) bool {
+	switch c.(type) {
+	case *Logs, *InitLogs, *Processes, *runtimeCheckup, *bboltdbCheckup, *installCheckup,
+		*servicesCheckup, *intuneCheckup, *coredumpCheckup, *perfCheckup:
+		return true
+	default:
+		return false
+	}
+}
+
+// scopeable is implemented by checkups that can limit the data they collect for a scoped flare.
+type scopeable interface {
+	// setScope limits collection to logs since the given time (if non-zero), and to extra data
+	// of at most maxExtraBytes (if positive).
+	setScope(logsSince time.Time, maxExtraBytes int64)
+}
+
+// flareBudget tracks how much of the flare size budget has been used.
+type flareBudget struct {
+	limit   int64 // zero for unlimited
+	written int64 // bytes written to the flare stream
+}
+
+func newFlareBudget(sizeBudgetKB int) *flareBudget {
+	return &flareBudget{limit: int64(sizeBudgetKB) * 1024}
+}
+
+// Write counts the bytes written to the flare stream.
+func (b *flareBudget) Write(p []byte) (int, error) {
+	b.written += int64(len(p))
+	return len(p), nil
+}
+
+func (b *flareBudget) unlimited() bool {
+	return b.limit <= 0
+}
+
+// remaining returns the number of bytes left in the budget.
+func (b *flareBudget) remaining() int64 {
+	return max(b.limit-b.written, 0)
+}
+
+// truncatingWriter writes up to limit bytes, silently discarding the rest so that checkups
+// still run to completion and report their status.
+type truncatingWriter struct {
+	w         io.Writer
+	limit     int64
+	written   int64
+	truncated bool
+}
+
+func (t *truncatingWriter) Write(p []byte) (int, error) {
+	remaining := t.limit - t.written
+	if int64(len(p)) > remaining {
+		t.truncated = true
+		if remaining <= 0 {
+			return len(p), nil
+		}
+		n, err := t.w.Write(p[:remaining])
+		t.written += int64(n)
+		if err != nil {
+			return n, err
+		}
+		return len(p), nil
+	}
+
+	n, err := t.w.Write(p)
+	t.written += int64(n)
+	return n, err
+}
+
+// copyWithinBudget copies the spooled extra data of a checkup to dst, writing at most limit bytes.
+// Zip files are copied an entry at a time, omitting entries that do not fit, so that the result
+// is still a valid zip. Anything else is truncated.
+func copyWithinBudget(dst io.Writer, spool *os.File, isZip bool, limit int64) (omitted int, truncated bool, err error) {
+	size, err := spool.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, false, fmt.Errorf("seeking spool file: %w", err)
+	}
+
+	if isZip {
+		if zr, err := zip.NewReader(spool, size); err == nil {
+			omitted, err := copyZipWithinBudget(dst, zr, limit)
+			return omitted, false, err
+		}
+		// If we can't read the zip, we can't do better than truncating it
+	}
+
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return 0, false, fmt.Errorf("seeking spool file: %w", err)
+	}
+	truncator := &truncatingWriter{w: dst, limit: limit}
+	if _, err := io.Copy(truncator, spool); err != nil {
+		return 0, false, fmt.Errorf("copying spool file: %w", err)
+	}
+	return 0, truncator.truncated, nil
+}
+
+// copyZipWithinBudget copies the entries of zr to a new zip written to dst, omitting entries
+// once they would take the compressed size past limit. It returns the number omitted.
+func copyZipWithinBudget(dst io.Writer, zr *zip.Reader, limit int64) (int, error) {
+	out := zip.NewWriter(dst)
+
+	var used int64
+	omitted := 0
+	for _, f := range zr.File {
+		if used+int64(f.CompressedSize64) > limit {
+			omitted += 1
+			continue
+		}
+		if err := out.Copy(f); err != nil {
+			return omitted, errors.Join(fmt.Errorf("copying %s: %w", f.Name, err), out.Close())
+		}
+		used += int64(f.CompressedSize64)
+	}
+
+	return omitted, out.Close()
+}
@@ -0,0 +1,208 @@
+package checkups
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	typesMocks "github.com/kolide/launcher/v2/ee/agent/types/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_scopedCheckups(t *testing.T) {
+	t.Parallel()
+
+	allCheckups := []checkupInt{
+		&Platform{},
+		&Logs{},
+		&osqueryCheckup{},
+		&osqDataCollector{},
+		&networkCheckup{},
+		&dnsCheckup{},
+	}
+
+	checkupNames := func(checkups []checkupInt) []string {
+		names := make([]string, len(checkups))
+		for i, c := range checkups {
+			names[i] = c.Name()
+		}
+		return names
+	}
+
+	for _, tt := range []struct {
+		name          string
+		opts          FlareOptions
+		expectedNames []string
+	}{
+		{
+			name:          "unscoped",
+			opts:          FlareOptions{},
+			expectedNames: []string{"Platform", "Logs", "Osquery", "Osquery Data", "Network Report", "DNS Resolution"},
+		},
+		{
+			name:          "include",
+			opts:          FlareOptions{IncludeCheckups: []string{"osquery", " NETWORK "}},
+			expectedNames: []string{"Osquery", "Osquery Data", "Network Report"},
+		},
+		{
+			name:          "exclude",
+			opts:          FlareOptions{ExcludeCheckups: []string{"osquery data", "dns"}},
+			expectedNames: []string{"Platform", "Logs", "Osquery", "Network Report"},
+		},
+		{
+			name:          "size budget skips expensive checkups",
+			opts:          FlareOptions{SizeBudgetKB: 1024},
+			expectedNames: []string{"Platform", "Osquery", "Osquery Data", "Network Report", "DNS Resolution"},
+		},
+		{
+			name:          "size budget with explicitly included expensive checkup",
+			opts:          FlareOptions{SizeBudgetKB: 1024, IncludeCheckups: []string{"logs", "platform"}},
+			expectedNames: []string{"Platform", "Logs"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.expectedNames, checkupNames(scopedCheckups(allCheckups, tt.opts)))
+		})
+	}
+}
+
+func Test_truncatingWriter(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	tw := &truncatingWriter{w: &buf, limit: 10}
+
+	n, err := tw.Write([]byte("12345"))
+	require.NoError(t, err)
+	require.Equal(t, 5, n)
+	require.False(t, tw.truncated)
+
+	// Writes past the limit are reported as successful, so checkups run to completion
+	n, err = tw.Write([]byte("6789012345"))
+	require.NoError(t, err)
+	require.Equal(t, 10, n)
+	n, err = tw.Write([]byte("more"))
+	require.NoError(t, err)
+	require.Equal(t, 4, n)
+
+	require.True(t, tw.truncated)
+	require.Equal(t, "1234567890", buf.String())
+}
+
+func Test_filterLogsSince(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	logs := strings.Join([]string{
+		`{"time":"2026-10-01T11:59:59Z","msg":"too old"}`,
+		`{"time":"2026-10-01T12:00:00Z","msg":"at start of window"}`,
+		`not json`,
+		`{"time":"2026-10-02T08:00:00-04:00","msg":"in window"}`,
+		`{"msg":"no time"}`,
+	}, "\n")
+
+	var filtered bytes.Buffer
+	require.NoError(t, filterLogsSince(strings.NewReader(logs), &filtered, since))
+	require.Equal(t, strings.Join([]string{
+		`{"time":"2026-10-01T12:00:00Z","msg":"at start of window"}`,
+		`not json`,
+		`{"time":"2026-10-02T08:00:00-04:00","msg":"in window"}`,
+		`{"msg":"no time"}`,
+	}, "\n"), filtered.String())
+}
+
+func Test_copyWithinBudget(t *testing.T) {
+	t.Parallel()
+
+	spool, err := os.CreateTemp(t.TempDir(), "spool")
+	require.NoError(t, err)
+	t.Cleanup(func() { spool.Close() })
+
+	zw := zip.NewWriter(spool)
+	for _, name := range []string{"first.txt", "second.txt", "third.txt"} {
+		out, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store})
+		require.NoError(t, err)
+		_, err = out.Write(bytes.Repeat([]byte("a"), 100))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+
+	// Only the first two entries fit in the budget, but the result must still be a valid zip
+	var dst bytes.Buffer
+	omitted, truncated, err := copyWithinBudget(&dst, spool, true, 250)
+	require.NoError(t, err)
+	require.Equal(t, 1, omitted)
+	require.False(t, truncated)
+
+	zr, err := zip.NewReader(bytes.NewReader(dst.Bytes()), int64(dst.Len()))
+	require.NoError(t, err)
+	require.Len(t, zr.File, 2)
+	require.Equal(t, "first.txt", zr.File[0].Name)
+	require.Equal(t, "second.txt", zr.File[1].Name)
+
+	// Anything else is truncated
+	dst.Reset()
+	omitted, truncated, err = copyWithinBudget(&dst, spool, false, 10)
+	require.NoError(t, err)
+	require.Zero(t, omitted)
+	require.True(t, truncated)
+	require.Equal(t, 10, dst.Len())
+}
+
+func TestLogs_scopedRotatedLogs(t *testing.T) {
+	t.Parallel()
+
+	rootDir := t.TempDir()
+	since := time.Now().Add(-1 * time.Hour)
+	logs := strings.Join([]string{
+		`{"time":"` + since.Add(-1*time.Minute).Format(time.RFC3339) + `","msg":"too old"}`,
+		`{"time":"` + since.Add(1*time.Minute).Format(time.RFC3339) + `","msg":"in window"}`,
+	}, "\n") + "\n"
+
+	var compressed bytes.Buffer
+	gw := gzip.NewWriter(&compressed)
+	_, err := gw.Write([]byte(logs))
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+	require.NoError(t, os.WriteFile(filepath.Join(rootDir, "debug.json"), []byte(logs), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(rootDir, "debug-2026-10-01.json.gz"), compressed.Bytes(), 0600))
+
+	k := typesMocks.NewKnapsack(t)
+	k.On("RootDirectory").Return(rootDir)
+
+	c := &Logs{k: k}
+	c.setScope(since, 0)
+
+	var logZip bytes.Buffer
+	require.NoError(t, c.Run(context.TODO(), &logZip))
+
+	zr, err := zip.NewReader(bytes.NewReader(logZip.Bytes()), int64(logZip.Len()))
+	require.NoError(t, err)
+	contents := make(map[string]string)
+	for _, f := range zr.File {
+		if strings.HasSuffix(f.Name, ".flaremeta") {
+			continue
+		}
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		contents[filepath.Base(f.Name)] = string(data)
+	}
+
+	// The rotated log is decompressed, so that it can be filtered
+	inWindow := `{"time":"` + since.Add(1*time.Minute).Format(time.RFC3339) + `","msg":"in window"}` + "\n"
+	require.Equal(t, map[string]string{
+		"debug.json":            inWindow,
+		"debug-2026-10-01.json": inWindow,
+	}, contents)
+}
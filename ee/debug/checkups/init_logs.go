@@ -4,11 +4,17 @@ import (
 	"archive/zip"
 	"context"
 	"io"
+	"time"
 )
 
 type InitLogs struct {
-	status  Status
-	summary string
+	status    Status
+	summary   string
+	logsSince time.Time
+}
+
+func (c *InitLogs) setScope(logsSince time.Time, _ int64) {
+	c.logsSince = logsSince
 }
 
 func (c *InitLogs) Name() string {
@@ -26,7 +32,7 @@ func (c *InitLogs) Run(ctx context.Context, fullFH io.Writer) error {
 	logZip := zip.NewWriter(fullFH)
 	defer logZip.Close()
 
-	return writeInitLogs(ctx, logZip)
+	return writeInitLogs(ctx, logZip, c.logsSince)
 }
 
 func (c *InitLogs) Status() Status {
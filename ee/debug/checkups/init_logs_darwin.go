@@ -4,10 +4,12 @@ import (
 	"archive/zip"
 	"context"
 	"fmt"
+	"os"
 	"path/filepath"
+	"time"
 )
 
-func writeInitLogs(_ context.Context, logZip *zip.Writer) error {
+func writeInitLogs(_ context.Context, logZip *zip.Writer, logsSince time.Time) error {
 	stdMatches, err := filepath.Glob("/var/log/kolide-k2/*")
 	if err != nil {
 		return fmt.Errorf("globbing /var/log/kolide-k2/*: %w", err)
@@ -15,6 +17,10 @@ func writeInitLogs(_ context.Context, logZip *zip.Writer) error {
 
 	var lastErr error
 	for _, f := range stdMatches {
+		// Skip logs that were last written before the window
+		if info, err := os.Stat(f); err == nil && !logsSince.IsZero() && info.ModTime().Before(logsSince) {
+			continue
+		}
 		if err := addFileToZip(logZip, f); err != nil {
 			lastErr = err
 		}
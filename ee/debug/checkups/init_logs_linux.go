@@ -10,8 +10,13 @@ import (
 	"github.com/kolide/launcher/v2/ee/allowedcmd"
 )
 
-func writeInitLogs(ctx context.Context, logZip *zip.Writer) error {
-	cmd, err := allowedcmd.Journalctl.Cmd(ctx, "-u", "launcher.kolide-k2.service")
+func writeInitLogs(ctx context.Context, logZip *zip.Writer, logsSince time.Time) error {
+	args := []string{"-u", "launcher.kolide-k2.service"}
+	if !logsSince.IsZero() {
+		args = append(args, "--since", logsSince.Format(time.DateTime))
+	}
+
+	cmd, err := allowedcmd.Journalctl.Cmd(ctx, args...)
 	if err != nil {
 		return fmt.Errorf("creating journalctl command: %w", err)
 	}
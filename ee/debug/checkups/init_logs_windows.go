@@ -10,8 +10,12 @@ import (
 	"github.com/kolide/launcher/v2/ee/allowedcmd"
 )
 
-func writeInitLogs(ctx context.Context, logZip *zip.Writer) error {
-	cmdStr := `Get-WinEvent -FilterHashtable @{LogName='Application'; ProviderName='launcher'} | ConvertTo-Json`
+func writeInitLogs(ctx context.Context, logZip *zip.Writer, logsSince time.Time) error {
+	filter := `LogName='Application'; ProviderName='launcher'`
+	if !logsSince.IsZero() {
+		filter += fmt.Sprintf(`; StartTime=[datetime]'%s'`, logsSince.Format("2006-01-02T15:04:05"))
+	}
+	cmdStr := fmt.Sprintf(`Get-WinEvent -FilterHashtable @{%s} | ConvertTo-Json`, filter)
 	cmd, err := allowedcmd.Powershell.Cmd(ctx, cmdStr)
 	if err != nil {
 		return fmt.Errorf("creating powershell command: %w", err)
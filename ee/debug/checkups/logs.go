@@ -2,19 +2,32 @@ package checkups
 
 import (
 	"archive/zip"
+	"bufio"
+	"compress/gzip"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/kolide/launcher/v2/ee/agent/types"
 )
 
 type Logs struct {
-	k       types.Knapsack
-	status  Status
-	summary string
+	k             types.Knapsack
+	status        Status
+	summary       string
+	logsSince     time.Time
+	maxExtraBytes int64
+}
+
+func (c *Logs) setScope(logsSince time.Time, maxExtraBytes int64) {
+	c.logsSince = logsSince
+	c.maxExtraBytes = maxExtraBytes
 }
 
 func (c *Logs) Name() string {
@@ -42,19 +55,157 @@ func (c *Logs) Run(_ context.Context, fullFH io.Writer) error {
 		return nil
 	}
 
-	logZip := zip.NewWriter(fullFH)
+	matches, _ := filepath.Glob(filepath.Join(c.k.RootDirectory(), "debug*"))
+
+	if c.logsSince.IsZero() && c.maxExtraBytes <= 0 {
+		logZip := zip.NewWriter(fullFH)
+		defer logZip.Close()
+
+		for _, f := range matches {
+			if err := addFileToZip(logZip, f); err != nil {
+				return fmt.Errorf("adding %s to zip: %w", f, err)
+			}
+		}
+		return nil
+	}
+
+	// Track the bytes actually written, so that the budget reflects the compressed size of the logs
+	budget := &flareBudget{limit: c.maxExtraBytes}
+	logZip := zip.NewWriter(io.MultiWriter(fullFH, budget))
 	defer logZip.Close()
 
-	matches, _ := filepath.Glob(filepath.Join(c.k.RootDirectory(), "debug*"))
+	return c.addScopedLogs(logZip, budget, matches)
+}
 
+// addScopedLogs adds the logs within the time window to the zip, newest first, stopping
+// once the size budget is exhausted.
+func (c *Logs) addScopedLogs(logZip *zip.Writer, budget *flareBudget, matches []string) error {
+	type logFile struct {
+		path string
+		info os.FileInfo
+	}
+	logFiles := make([]logFile, 0, len(matches))
 	for _, f := range matches {
-		if err := addFileToZip(logZip, f); err != nil {
-			return fmt.Errorf("adding %s to zip: %w", f, err)
+		info, err := os.Stat(f)
+		if err != nil {
+			continue
+		}
+		// Rotated logs that were last written before the window contain nothing we want
+		if !c.logsSince.IsZero() && info.ModTime().Before(c.logsSince) {
+			continue
+		}
+		logFiles = append(logFiles, logFile{path: f, info: info})
+	}
+	sort.Slice(logFiles, func(i, j int) bool {
+		return logFiles[i].info.ModTime().After(logFiles[j].info.ModTime())
+	})
+
+	omitted := 0
+	truncated := 0
+	for _, f := range logFiles {
+		// Flush, so that the budget includes everything written so far
+		if err := logZip.Flush(); err != nil {
+			return fmt.Errorf("flushing zip: %w", err)
+		}
+
+		var limit int64 // zero for unlimited
+		if !budget.unlimited() {
+			limit = budget.remaining()
+			if limit == 0 {
+				omitted += 1
+				continue
+			}
+		}
+
+		wasTruncated, err := c.addLogsSince(logZip, f.path, f.info.ModTime(), limit)
+		if err != nil {
+			return fmt.Errorf("adding %s to zip: %w", f.path, err)
+		}
+		if wasTruncated {
+			truncated += 1
 		}
 	}
 
+	if truncated > 0 {
+		c.summary = fmt.Sprintf("%s; truncated %d log files to stay within flare size budget", c.summary, truncated)
+	}
+	if omitted > 0 {
+		c.summary = fmt.Sprintf("%s; omitted %d older log files to stay within flare size budget", c.summary, omitted)
+	}
+
 	return nil
+}
+
+// addLogsSince adds the log lines at or after c.logsSince to the zip, decompressing rotated logs.
+// If limit is positive, the log is truncated after limit bytes, and addLogsSince reports whether
+// that happened.
+func (c *Logs) addLogsSince(logZip *zip.Writer, location string, modTime time.Time, limit int64) (bool, error) {
+	fh, err := os.Open(location)
+	if err != nil {
+		return false, fmt.Errorf("opening %s: %w", location, err)
+	}
+	defer fh.Close()
+
+	var logReader io.Reader = fh
+	entryName := filepath.Join(".", location)
+	if filepath.Ext(location) == ".gz" {
+		gzipReader, err := gzip.NewReader(fh)
+		if err != nil {
+			return false, fmt.Errorf("decompressing %s: %w", location, err)
+		}
+		defer gzipReader.Close()
+		logReader = gzipReader
+		entryName = strings.TrimSuffix(entryName, ".gz")
+	}
+
+	pr, pw := io.Pipe()
+	var w io.Writer = pw
+	var truncator *truncatingWriter
+	if limit > 0 {
+		truncator = &truncatingWriter{w: pw, limit: limit}
+		w = truncator
+	}
+	go func() {
+		if c.logsSince.IsZero() {
+			_, err := io.Copy(w, logReader)
+			pw.CloseWithError(err)
+			return
+		}
+		pw.CloseWithError(filterLogsSince(logReader, w, c.logsSince))
+	}()
+	defer pr.Close()
+
+	if err := addStreamToZip(logZip, entryName, modTime, pr); err != nil {
+		return false, err
+	}
+
+	// The pipe has been drained, so the truncator is done
+	return truncator != nil && truncator.truncated, nil
+}
 
+// filterLogsSince copies the JSON log lines from r to w, skipping lines with a time before since.
+// Lines without a parseable time are retained.
+func filterLogsSince(r io.Reader, w io.Writer, since time.Time) error {
+	reader := bufio.NewReader(r)
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			var logLine struct {
+				Time time.Time `json:"time"`
+			}
+			if err := json.Unmarshal(line, &logLine); err != nil || logLine.Time.IsZero() || !logLine.Time.Before(since) {
+				if _, err := w.Write(line); err != nil {
+					return err
+				}
+			}
+		}
+		if readErr == io.EOF {
+			return nil
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
 }
 
 func (c *Logs) Status() Status {
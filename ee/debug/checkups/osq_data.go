@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"strings"
@@ -71,9 +72,22 @@ func (odc *osqDataCollector) Run(ctx context.Context, extraFH io.Writer) error {
 // it was done this way to avoid bringing Querier into knapsack for a task that will only be run
 // during flare or doctor
 func (odc *osqDataCollector) queryData(ctx context.Context, query string) (map[string]string, error) {
-	osqPath := odc.k.LatestOsquerydPath(ctx)
+	results, err := runOsqueryQuery(ctx, odc.k.LatestOsquerydPath(ctx), query, 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return nil, errors.New("got empty result set from osq_data query")
+	}
+
+	return results[0], nil
+}
+
+// runOsqueryQuery runs the given query in a runsimple osq process, returning the result rows.
+func runOsqueryQuery(ctx context.Context, osqPath string, query string, timeout time.Duration) (osqResp, error) {
 	var resultBuffer bytes.Buffer
-	osqCtx, cmdCancel := context.WithTimeout(ctx, 5*time.Second)
+	osqCtx, cmdCancel := context.WithTimeout(ctx, timeout)
 	defer cmdCancel()
 
 	osq, err := runsimple.NewOsqueryProcess(osqPath, runsimple.WithStdout(&resultBuffer))
@@ -94,9 +108,5 @@ func (odc *osqDataCollector) queryData(ctx context.Context, query string) (map[s
 		return nil, fmt.Errorf("unable to parse osq data query results from output %s. error: %w", string(queryResponse), err)
 	}
 
-	if len(results) == 0 {
-		return nil, fmt.Errorf("got empty result set from osq_data query: output %s", string(queryResponse))
-	}
-
-	return results[0], nil
+	return results, nil
 }
@@ -0,0 +1,66 @@
+package checkups
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/kolide/launcher/v2/ee/agent/types"
+	"github.com/kolide/launcher/v2/ee/queryprofiler"
+)
+
+const maxQueryProfileSummaries = 15
+
+type (
+	queryProfilesCheckup struct {
+		k       types.Knapsack
+		status  Status
+		summary string
+		data    map[string]any
+	}
+)
+
+func (qpc *queryProfilesCheckup) Data() any             { return qpc.data }
+func (qpc *queryProfilesCheckup) ExtraFileName() string { return "" }
+func (qpc *queryProfilesCheckup) Name() string          { return "Query Profiles" }
+func (qpc *queryProfilesCheckup) Status() Status        { return qpc.status }
+func (qpc *queryProfilesCheckup) Summary() string       { return qpc.summary }
+
+func (qpc *queryProfilesCheckup) Run(ctx context.Context, extraFH io.Writer) error {
+	qpc.data = make(map[string]any)
+
+	store := qpc.k.QueryProfilesStore()
+	if store == nil {
+		// We are probably running standalone instead of in situ
+		qpc.status = Informational
+		qpc.summary = "No query profiles available"
+		return nil
+	}
+
+	profiles, err := queryprofiler.Profiles(store)
+	if err != nil {
+		qpc.status = Erroring
+		qpc.summary = "Unable to collect query profiles"
+		qpc.data["error"] = err.Error()
+		return nil
+	}
+
+	if len(profiles) == 0 {
+		qpc.status = Informational
+		qpc.summary = "No query profiles have been collected yet"
+		return nil
+	}
+
+	summaries := queryprofiler.Summarize(profiles)
+	if len(summaries) > maxQueryProfileSummaries {
+		summaries = summaries[:maxQueryProfileSummaries]
+	}
+
+	mostExpensive := summaries[0]
+	qpc.status = Informational
+	qpc.data["most_expensive"] = summaries
+	qpc.summary = fmt.Sprintf("most expensive query is %s (%s): %d ms across %d executions",
+		mostExpensive.Name, mostExpensive.Source, mostExpensive.WallTimeMs, mostExpensive.Executions)
+
+	return nil
+}
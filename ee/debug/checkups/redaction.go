@@ -0,0 +1,75 @@
+package checkups
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kolide/launcher/v2/ee/agent/types"
+	"github.com/kolide/launcher/v2/ee/debug/redact"
+)
+
+// redactionRulesFileName is the name of the optional redaction rules file in the root directory.
+// Rules set via the flare_redaction_rules flag take precedence over this file.
+const redactionRulesFileName = "flare_redaction_rules.json"
+
+// flareRedactor returns a redactor for the configured redaction rules.
+func flareRedactor(k types.Knapsack) (*redact.Redactor, error) {
+	rules, err := flareRedactionRules(k)
+	if err != nil {
+		return nil, err
+	}
+
+	return redact.New(rules, knownSecretOptions(k)...)
+}
+
+// defaultFlareRedactor returns a redactor with only the built-in rules.
+func defaultFlareRedactor(k types.Knapsack) (*redact.Redactor, error) {
+	return redact.New(nil, knownSecretOptions(k)...)
+}
+
+func flareRedactionRules(k types.Knapsack) (*redact.Rules, error) {
+	if rawRules := strings.TrimSpace(k.FlareRedactionRules()); rawRules != "" {
+		return redact.ParseRules([]byte(rawRules))
+	}
+
+	rulesFile := filepath.Join(k.RootDirectory(), redactionRulesFileName)
+	if _, err := os.Stat(rulesFile); err != nil {
+		// No rules file -- use the defaults
+		return nil, nil
+	}
+
+	rules, err := redact.LoadRulesFile(rulesFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading %s: %w", rulesFile, err)
+	}
+	return rules, nil
+}
+
+// knownSecretOptions returns options to redact the secrets this installation knows about, and to hash
+// the device hostname if requested.
+func knownSecretOptions(k types.Knapsack) []redact.Option {
+	opts := make([]redact.Option, 0)
+
+	if enrollSecret, err := k.ReadEnrollSecret(); err == nil {
+		opts = append(opts, redact.WithSecrets("enroll_secret", strings.TrimSpace(enrollSecret)))
+	}
+
+	if enrollments, err := k.Enrollments(); err == nil {
+		for _, enrollment := range enrollments {
+			opts = append(opts,
+				redact.WithSecrets("enroll_secret", enrollment.EnrollmentSecret),
+				redact.WithSecrets("node_key", enrollment.NodeKey),
+			)
+		}
+	}
+
+	if hostname, err := os.Hostname(); err == nil {
+		shortHostname, _, _ := strings.Cut(hostname, ".")
+		// Hash the full hostname first, so that we don't leave the domain behind
+		opts = append(opts, redact.WithHostnames(hostname, shortHostname))
+	}
+
+	return opts
+}
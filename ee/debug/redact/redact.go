@@ -0,0 +1,268 @@
+// Package redact scrubs secrets and identifying information from flare contents before they leave
+// the device. A Redactor applies a set of rules to text: built-in rules that replace known secret shapes
+// (bearer tokens, JWTs, enroll secrets, node keys), optional rules that replace usernames and hostnames
+// with salted hashes, and any additional patterns supplied via configuration. The ZipWriter applies a
+// Redactor to every file written into a flare, and records what it redacted in a manifest.
+package redact
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Rule is an additional, user-configured redaction pattern. If the pattern contains a capture group,
+// only the first group is redacted; otherwise, the entire match is redacted.
+type Rule struct {
+	Name    string `json:"name"`
+	Pattern string `json:"pattern"`
+}
+
+// Rules configures a Redactor.
+type Rules struct {
+	// DisableBuiltinRules turns off the built-in secret rules. Secrets known to this launcher
+	// installation (e.g. its enroll secret and node keys) are always redacted.
+	DisableBuiltinRules bool `json:"disable_builtin_rules"`
+	// HashUsernames replaces usernames found in home directory paths and username fields with a salted hash.
+	HashUsernames bool `json:"hash_usernames"`
+	// HashHostnames replaces this device's hostname with a salted hash.
+	HashHostnames bool `json:"hash_hostnames"`
+	// Patterns are additional patterns to redact.
+	Patterns []Rule `json:"patterns"`
+}
+
+// ParseRules parses a JSON rules document.
+func ParseRules(raw []byte) (*Rules, error) {
+	var rules Rules
+	if err := json.Unmarshal(raw, &rules); err != nil {
+		return nil, fmt.Errorf("unmarshalling redaction rules: %w", err)
+	}
+	return &rules, nil
+}
+
+// LoadRulesFile reads and parses the JSON rules document at the given path.
+func LoadRulesFile(path string) (*Rules, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading redaction rules file %s: %w", path, err)
+	}
+	return ParseRules(raw)
+}
+
+const (
+	redactedTemplate = "[REDACTED:%s]"
+
+	usernameRuleName = "username"
+	hostnameRuleName = "hostname"
+)
+
+var redactedPrefix = []byte("[REDACTED:")
+
+// builtinRules match well-known secret shapes.
+var builtinRules = []Rule{
+	{Name: "jwt", Pattern: `eyJ[A-Za-z0-9_-]{4,}\.eyJ[A-Za-z0-9_-]{4,}\.[A-Za-z0-9_-]*`},
+	{Name: "bearer_token", Pattern: `(?i)\bbearer\s+([A-Za-z0-9\-._~+/]+=*)`},
+	{Name: "enroll_secret", Pattern: `(?i)enroll(?:ment)?_?secret\\?["']?\s*[:=]\s*\\?["']?([^\s"'\\,}]+)`},
+	{Name: "node_key", Pattern: `(?i)node_?key\\?["']?\s*[:=]\s*\\?["']?([^\s"'\\,}]+)`},
+}
+
+// usernameRules match usernames in home directory paths (including JSON-escaped Windows paths)
+// and in common username fields.
+var usernameRules = []Rule{
+	{Name: usernameRuleName, Pattern: `(?i)(?:/Users/|/home/|\\+Users\\+)([^/\\\s"':;,]+)`},
+	{Name: usernameRuleName, Pattern: `(?i)"(?:username|user_name|console_user)"\s*:\s*"([^"]+)"`},
+}
+
+// usernameExclusions are directories found alongside user home directories that are not usernames.
+var usernameExclusions = map[string]bool{
+	"shared":       true,
+	"public":       true,
+	"default":      true,
+	"default user": true,
+	"all users":    true,
+	"guest":        true,
+}
+
+type compiledRule struct {
+	name string
+	re   *regexp.Regexp
+	hash bool // replace matches with a salted hash rather than a redaction marker
+}
+
+// Redactor applies redaction rules to text. It is safe for concurrent use.
+type Redactor struct {
+	rules         []compiledRule
+	salt          []byte
+	hashUsernames bool
+	hashHostnames bool
+}
+
+type Option func(*Redactor)
+
+// WithSecrets redacts the given literal values wherever they appear, under the given rule name.
+// This lets us redact secrets we know about even when they do not match a built-in pattern.
+func WithSecrets(name string, secrets ...string) Option {
+	return func(r *Redactor) {
+		for _, secret := range secrets {
+			// Avoid redacting short, meaningless values
+			if len(secret) < 8 {
+				continue
+			}
+			r.rules = append(r.rules, compiledRule{name: name, re: regexp.MustCompile(regexp.QuoteMeta(secret))})
+		}
+	}
+}
+
+// WithHostnames sets the hostnames to hash when the rules request hostname hashing.
+func WithHostnames(hostnames ...string) Option {
+	return func(r *Redactor) {
+		if !r.hashHostnames {
+			return
+		}
+		for _, hostname := range hostnames {
+			if len(hostname) < 3 || strings.EqualFold(hostname, "localhost") {
+				continue
+			}
+			r.rules = append(r.rules, compiledRule{name: hostnameRuleName, re: regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(hostname) + `\b`), hash: true})
+		}
+	}
+}
+
+// New returns a Redactor for the given rules; nil rules gets the defaults.
+func New(rules *Rules, opts ...Option) (*Redactor, error) {
+	if rules == nil {
+		rules = &Rules{}
+	}
+
+	// Hashes are salted per-Redactor, so that they are consistent within a flare but cannot be
+	// reversed by hashing candidate usernames.
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("generating salt: %w", err)
+	}
+
+	r := &Redactor{
+		salt:          salt,
+		hashUsernames: rules.HashUsernames,
+		hashHostnames: rules.HashHostnames,
+	}
+
+	// Options add literal rules, which we want to apply first -- before a pattern rule
+	// can partially replace a known secret.
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	patterns := make([]Rule, 0)
+	if !rules.DisableBuiltinRules {
+		patterns = append(patterns, builtinRules...)
+	}
+	patterns = append(patterns, rules.Patterns...)
+
+	var errs []error
+	for _, p := range patterns {
+		if p.Name == "" {
+			errs = append(errs, fmt.Errorf("redaction rule with pattern %q is missing a name", p.Pattern))
+			continue
+		}
+		re, err := regexp.Compile(p.Pattern)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("compiling redaction rule %s: %w", p.Name, err))
+			continue
+		}
+		r.rules = append(r.rules, compiledRule{name: p.Name, re: re})
+	}
+
+	if r.hashUsernames {
+		for _, p := range usernameRules {
+			r.rules = append(r.rules, compiledRule{name: p.Name, re: regexp.MustCompile(p.Pattern), hash: true})
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return r, nil
+}
+
+// RuleNames returns the names of the rules this Redactor applies.
+func (r *Redactor) RuleNames() []string {
+	seen := make(map[string]bool)
+	names := make([]string, 0, len(r.rules))
+	for _, rule := range r.rules {
+		if seen[rule.name] {
+			continue
+		}
+		seen[rule.name] = true
+		names = append(names, rule.name)
+	}
+	return names
+}
+
+// Redact applies all rules to the given text, returning the redacted text. If counts is non-nil,
+// it is incremented with the number of redactions made per rule.
+func (r *Redactor) Redact(text []byte, counts map[string]int) []byte {
+	for _, rule := range r.rules {
+		text = r.apply(rule, text, counts)
+	}
+	return text
+}
+
+func (r *Redactor) apply(rule compiledRule, text []byte, counts map[string]int) []byte {
+	matches := rule.re.FindAllSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return text
+	}
+
+	var out []byte
+	last := 0
+	for _, m := range matches {
+		// Redact the first capture group if there is one, otherwise the whole match
+		start, end := m[0], m[1]
+		if len(m) >= 4 && m[2] >= 0 {
+			start, end = m[2], m[3]
+		}
+
+		value := text[start:end]
+		if bytes.HasPrefix(value, redactedPrefix) {
+			// Already redacted by an earlier rule
+			continue
+		}
+		if rule.name == usernameRuleName && usernameExclusions[strings.ToLower(string(value))] {
+			continue
+		}
+
+		out = append(out, text[last:start]...)
+		out = append(out, r.replacement(rule, value)...)
+		last = end
+
+		if counts != nil {
+			counts[rule.name] += 1
+		}
+	}
+
+	if out == nil {
+		return text
+	}
+
+	return append(out, text[last:]...)
+}
+
+func (r *Redactor) replacement(rule compiledRule, value []byte) string {
+	if !rule.hash {
+		return fmt.Sprintf(redactedTemplate, rule.name)
+	}
+
+	mac := hmac.New(sha256.New, r.salt)
+	mac.Write([]byte(strings.ToLower(string(value))))
+	return fmt.Sprintf("%s-%s", rule.name, hex.EncodeToString(mac.Sum(nil))[:12])
+}
@@ -0,0 +1,355 @@
+package redact
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// ManifestFileName is the name of the manifest the ZipWriter adds to the flare.
+	ManifestFileName = "redactions.json"
+
+	// maxLineLength bounds how much of a single line we buffer before redacting it anyway.
+	maxLineLength = 1024 * 1024
+)
+
+// binaryExtensions are file types that we do not attempt to redact. They are noted in the manifest instead.
+var binaryExtensions = map[string]bool{
+	".pprof": true,
+	".png":   true,
+	".jpg":   true,
+	".ico":   true,
+	".bin":   true,
+}
+
+// binaryNameSuffixes identify binary files that are written without an extension, e.g. the
+// memprofile and cpuprofile added by the runtime checkup.
+var binaryNameSuffixes = []string{
+	"cpuprofile",
+	"memprofile",
+}
+
+// excludedExtensions are file types that may hold secrets we cannot redact, e.g. databases.
+// They are left out of the flare entirely, and noted in the manifest.
+var excludedExtensions = map[string]bool{
+	".db":     true,
+	".sqlite": true,
+}
+
+func isBinary(name string) bool {
+	if binaryExtensions[path.Ext(name)] {
+		return true
+	}
+
+	base := path.Base(name)
+	for _, suffix := range binaryNameSuffixes {
+		if strings.HasSuffix(base, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Manifest records what was redacted from a flare. It does not contain any of the redacted values.
+type Manifest struct {
+	Rules          []string                  `json:"rules"`
+	Files          map[string]map[string]int `json:"files"`
+	SkippedBinary  []string                  `json:"skipped_binary,omitempty"`
+	Excluded       []string                  `json:"excluded,omitempty"`
+	OmittedOnError map[string]string         `json:"omitted_on_error,omitempty"`
+}
+
+// ZipWriter wraps a zip.Writer, redacting every file written into it. Like zip.Writer, only one file
+// may be written at a time -- a file is complete once the next file is created, or the writer is flushed
+// or closed.
+type ZipWriter struct {
+	z        *zip.Writer
+	redactor *Redactor
+	current  finisher
+	lock     sync.Mutex
+	manifest Manifest
+}
+
+type finisher interface {
+	finish() error
+}
+
+func NewZipWriter(w io.Writer, redactor *Redactor) *ZipWriter {
+	return &ZipWriter{
+		z:        zip.NewWriter(w),
+		redactor: redactor,
+		manifest: Manifest{
+			Rules:          redactor.RuleNames(),
+			Files:          make(map[string]map[string]int),
+			OmittedOnError: make(map[string]string),
+		},
+	}
+}
+
+// Create adds a file to the zip, returning a writer that redacts the file contents.
+func (zw *ZipWriter) Create(name string) (io.Writer, error) {
+	zw.lock.Lock()
+	defer zw.lock.Unlock()
+
+	zw.finishCurrent()
+
+	if excludedExtensions[path.Ext(name)] {
+		zw.manifest.Excluded = append(zw.manifest.Excluded, name)
+		return io.Discard, nil
+	}
+
+	out, err := zw.z.Create(name)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case isBinary(name):
+		zw.manifest.SkippedBinary = append(zw.manifest.SkippedBinary, name)
+		return out, nil
+	case path.Ext(name) == ".zip":
+		// We need the complete nested zip before we can rewrite its contents
+		nested := &bufferedFileWriter{name: name, dst: out, rewrite: func(data []byte, dst io.Writer) error {
+			return zw.rewriteZip(name, data, dst)
+		}}
+		zw.current = nested
+		return nested, nil
+	case path.Ext(name) == ".gz":
+		// Compressed files, e.g. rotated logs, are decompressed so that they can be redacted
+		compressed := &bufferedFileWriter{name: name, dst: out, rewrite: func(data []byte, dst io.Writer) error {
+			return zw.redactGzip(name, dst, bytes.NewReader(data))
+		}}
+		zw.current = compressed
+		return compressed, nil
+	default:
+		lw := &lineWriter{dst: out, redactor: zw.redactor, counts: zw.countsFor(name)}
+		zw.current = lw
+		return lw, nil
+	}
+}
+
+// Flush completes the current file, and flushes the underlying zip.
+func (zw *ZipWriter) Flush() error {
+	zw.lock.Lock()
+	defer zw.lock.Unlock()
+
+	zw.finishCurrent()
+	return zw.z.Flush()
+}
+
+// Close completes the current file, writes the manifest, and closes the underlying zip.
+func (zw *ZipWriter) Close() error {
+	zw.lock.Lock()
+	defer zw.lock.Unlock()
+
+	zw.finishCurrent()
+
+	// Drop files where nothing was redacted, to keep the manifest readable
+	for name, counts := range zw.manifest.Files {
+		if len(counts) == 0 {
+			delete(zw.manifest.Files, name)
+		}
+	}
+	sort.Strings(zw.manifest.SkippedBinary)
+	sort.Strings(zw.manifest.Excluded)
+
+	manifestOut, err := zw.z.Create(ManifestFileName)
+	if err != nil {
+		return errors.Join(fmt.Errorf("creating %s: %w", ManifestFileName, err), zw.z.Close())
+	}
+
+	enc := json.NewEncoder(manifestOut)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(zw.manifest); err != nil {
+		return errors.Join(fmt.Errorf("writing %s: %w", ManifestFileName, err), zw.z.Close())
+	}
+
+	return zw.z.Close()
+}
+
+// finishCurrent completes the file currently being written. Errors are recorded in the manifest
+// rather than returned, so that one bad file does not prevent the rest of the flare from being written.
+func (zw *ZipWriter) finishCurrent() {
+	if zw.current == nil {
+		return
+	}
+	if err := zw.current.finish(); err != nil {
+		if buffered, ok := zw.current.(*bufferedFileWriter); ok {
+			zw.manifest.OmittedOnError[buffered.name] = err.Error()
+		}
+	}
+	zw.current = nil
+}
+
+func (zw *ZipWriter) countsFor(name string) map[string]int {
+	if _, ok := zw.manifest.Files[name]; !ok {
+		zw.manifest.Files[name] = make(map[string]int)
+	}
+	return zw.manifest.Files[name]
+}
+
+// copyRedacted copies src to dst, redacting according to the file type of name.
+func (zw *ZipWriter) copyRedacted(name string, dst io.Writer, src io.Reader) error {
+	switch {
+	case isBinary(name):
+		zw.manifest.SkippedBinary = append(zw.manifest.SkippedBinary, name)
+		_, err := io.Copy(dst, src)
+		return err
+	case path.Ext(name) == ".zip":
+		data, err := io.ReadAll(src)
+		if err != nil {
+			return fmt.Errorf("reading nested zip %s: %w", name, err)
+		}
+		return zw.rewriteZip(name, data, dst)
+	case path.Ext(name) == ".gz":
+		return zw.redactGzip(name, dst, src)
+	default:
+		lw := &lineWriter{dst: dst, redactor: zw.redactor, counts: zw.countsFor(name)}
+		if _, err := io.Copy(lw, src); err != nil {
+			return err
+		}
+		return lw.finish()
+	}
+}
+
+// rewriteZip writes a redacted copy of the given zip to dst. Entries are recorded in the manifest
+// under the path of the nested zip.
+func (zw *ZipWriter) rewriteZip(name string, data []byte, dst io.Writer) error {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return fmt.Errorf("reading nested zip %s: %w", name, err)
+	}
+
+	out := zip.NewWriter(dst)
+	for _, f := range zr.File {
+		entryName := path.Join(name, strings.TrimPrefix(f.Name, "/"))
+		if excludedExtensions[path.Ext(f.Name)] {
+			zw.manifest.Excluded = append(zw.manifest.Excluded, entryName)
+			continue
+		}
+
+		header := &zip.FileHeader{
+			Name:     f.Name,
+			Comment:  f.Comment,
+			Method:   f.Method,
+			Modified: f.Modified,
+		}
+		header.SetMode(f.Mode())
+
+		entryOut, err := out.CreateHeader(header)
+		if err != nil {
+			return errors.Join(fmt.Errorf("creating %s: %w", entryName, err), out.Close())
+		}
+		if f.FileInfo().IsDir() {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return errors.Join(fmt.Errorf("opening %s: %w", entryName, err), out.Close())
+		}
+		err = zw.copyRedacted(entryName, entryOut, rc)
+		rc.Close()
+		if err != nil {
+			return errors.Join(fmt.Errorf("redacting %s: %w", entryName, err), out.Close())
+		}
+	}
+
+	return out.Close()
+}
+
+// redactGzip decompresses src, redacts it a line at a time, and writes it recompressed to dst.
+func (zw *ZipWriter) redactGzip(name string, dst io.Writer, src io.Reader) error {
+	gr, err := gzip.NewReader(src)
+	if err != nil {
+		return fmt.Errorf("decompressing %s: %w", name, err)
+	}
+	defer gr.Close()
+
+	gw := gzip.NewWriter(dst)
+	lw := &lineWriter{dst: gw, redactor: zw.redactor, counts: zw.countsFor(name)}
+	if _, err := io.Copy(lw, gr); err != nil {
+		return errors.Join(fmt.Errorf("redacting %s: %w", name, err), gw.Close())
+	}
+	if err := lw.finish(); err != nil {
+		return errors.Join(fmt.Errorf("redacting %s: %w", name, err), gw.Close())
+	}
+
+	return gw.Close()
+}
+
+// bufferedFileWriter buffers a file written into the flare that cannot be redacted as a stream,
+// e.g. a nested zip, so that it can be rewritten with redacted contents once complete.
+type bufferedFileWriter struct {
+	name    string
+	dst     io.Writer
+	buf     bytes.Buffer
+	done    bool
+	rewrite func(data []byte, dst io.Writer) error
+}
+
+func (b *bufferedFileWriter) Write(p []byte) (int, error) {
+	if b.done {
+		return 0, errors.New("write to completed file")
+	}
+	return b.buf.Write(p)
+}
+
+func (b *bufferedFileWriter) finish() error {
+	b.done = true
+	if b.buf.Len() == 0 {
+		return nil
+	}
+	// If the file is incomplete or corrupt, we cannot redact it, so its contents are omitted.
+	return b.rewrite(b.buf.Bytes(), b.dst)
+}
+
+// lineWriter redacts its input a line at a time, so that large files can be streamed through it.
+type lineWriter struct {
+	dst      io.Writer
+	redactor *Redactor
+	counts   map[string]int
+	buf      []byte
+	done     bool
+}
+
+func (lw *lineWriter) Write(p []byte) (int, error) {
+	if lw.done {
+		return 0, errors.New("write to completed file")
+	}
+
+	lw.buf = append(lw.buf, p...)
+
+	end := bytes.LastIndexByte(lw.buf, '\n') + 1
+	if end == 0 && len(lw.buf) >= maxLineLength {
+		end = len(lw.buf)
+	}
+	if end > 0 {
+		if _, err := lw.dst.Write(lw.redactor.Redact(lw.buf[:end], lw.counts)); err != nil {
+			return 0, err
+		}
+		lw.buf = append(lw.buf[:0], lw.buf[end:]...)
+	}
+
+	return len(p), nil
+}
+
+func (lw *lineWriter) finish() error {
+	lw.done = true
+	if len(lw.buf) == 0 {
+		return nil
+	}
+	_, err := lw.dst.Write(lw.redactor.Redact(lw.buf, lw.counts))
+	lw.buf = nil
+	return err
+}
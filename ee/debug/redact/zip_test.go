@@ -0,0 +1,141 @@
+package redact
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestZipWriter(t *testing.T) {
+	t.Parallel()
+
+	r, err := New(nil, WithSecrets("enroll_secret", "super-secret-value"))
+	require.NoError(t, err)
+
+	var flare bytes.Buffer
+	zw := NewZipWriter(&flare, r)
+
+	// A plain text file, written in pieces that split a secret across writes
+	out, err := zw.Create("doctor.log")
+	require.NoError(t, err)
+	_, err = out.Write([]byte("first line\nsecret is super-sec"))
+	require.NoError(t, err)
+	_, err = out.Write([]byte("ret-value\nlast line without newline Bearer abc"))
+	require.NoError(t, err)
+
+	// A nested zip, as written by many checkups
+	out, err = zw.Create("Logs/logs.zip")
+	require.NoError(t, err)
+	nested := zip.NewWriter(out)
+	nestedOut, err := nested.Create("debug.json")
+	require.NoError(t, err)
+	_, err = nestedOut.Write([]byte(`{"msg":"request","authorization":"Bearer xyz"}` + "\n"))
+	require.NoError(t, err)
+	nestedOut, err = nested.Create("cpu.pprof")
+	require.NoError(t, err)
+	_, err = nestedOut.Write([]byte("Bearer binary-data"))
+	require.NoError(t, err)
+	nestedOut, err = nested.Create("debug-2024-01-01.json.gz")
+	require.NoError(t, err)
+	_, err = nestedOut.Write(gzipped(t, `{"msg":"rotated","authorization":"Bearer old"}`+"\n"))
+	require.NoError(t, err)
+	nestedOut, err = nested.Create("launcher.db")
+	require.NoError(t, err)
+	_, err = nestedOut.Write([]byte("Bearer stored-in-db"))
+	require.NoError(t, err)
+	require.NoError(t, nested.Close())
+
+	// A compressed file at the top level
+	out, err = zw.Create("Logs/rotated.log.gz")
+	require.NoError(t, err)
+	_, err = out.Write(gzipped(t, "secret is super-secret-value\n"))
+	require.NoError(t, err)
+
+	// A database, which we cannot redact
+	out, err = zw.Create("Databases/launcher.db")
+	require.NoError(t, err)
+	_, err = out.Write([]byte("super-secret-value"))
+	require.NoError(t, err)
+
+	// A profile written without an extension
+	out, err = zw.Create("Runtime/desktop_501_cpuprofile")
+	require.NoError(t, err)
+	_, err = out.Write([]byte("Bearer\nbinary-profile"))
+	require.NoError(t, err)
+
+	// A corrupt nested zip
+	out, err = zw.Create("Broken/broken.zip")
+	require.NoError(t, err)
+	_, err = out.Write([]byte("not a zip"))
+	require.NoError(t, err)
+
+	require.NoError(t, zw.Flush())
+	require.NoError(t, zw.Close())
+
+	flareReader, err := zip.NewReader(bytes.NewReader(flare.Bytes()), int64(flare.Len()))
+	require.NoError(t, err)
+	files := readZip(t, flareReader)
+
+	require.Equal(t, "first line\nsecret is [REDACTED:enroll_secret]\nlast line without newline Bearer [REDACTED:bearer_token]", string(files["doctor.log"]))
+	require.Empty(t, files["Broken/broken.zip"])
+	require.Equal(t, "secret is [REDACTED:enroll_secret]\n", gunzipped(t, files["Logs/rotated.log.gz"]))
+	require.NotContains(t, files, "Databases/launcher.db")
+	require.Equal(t, "Bearer\nbinary-profile", string(files["Runtime/desktop_501_cpuprofile"]))
+
+	nestedReader, err := zip.NewReader(bytes.NewReader(files["Logs/logs.zip"]), int64(len(files["Logs/logs.zip"])))
+	require.NoError(t, err)
+	nestedFiles := readZip(t, nestedReader)
+	require.Equal(t, `{"msg":"request","authorization":"Bearer [REDACTED:bearer_token]"}`+"\n", string(nestedFiles["debug.json"]))
+	require.Equal(t, "Bearer binary-data", string(nestedFiles["cpu.pprof"]))
+	require.Equal(t, `{"msg":"rotated","authorization":"Bearer [REDACTED:bearer_token]"}`+"\n", gunzipped(t, nestedFiles["debug-2024-01-01.json.gz"]))
+	require.NotContains(t, nestedFiles, "launcher.db")
+
+	var manifest Manifest
+	require.NoError(t, json.Unmarshal(files[ManifestFileName], &manifest))
+	require.Equal(t, map[string]map[string]int{
+		"doctor.log":                             {"enroll_secret": 1, "bearer_token": 1},
+		"Logs/logs.zip/debug.json":               {"bearer_token": 1},
+		"Logs/logs.zip/debug-2024-01-01.json.gz": {"bearer_token": 1},
+		"Logs/rotated.log.gz":                    {"enroll_secret": 1},
+	}, manifest.Files)
+	require.Equal(t, []string{"Logs/logs.zip/cpu.pprof", "Runtime/desktop_501_cpuprofile"}, manifest.SkippedBinary)
+	require.Equal(t, []string{"Databases/launcher.db", "Logs/logs.zip/launcher.db"}, manifest.Excluded)
+	require.Contains(t, manifest.OmittedOnError, "Broken/broken.zip")
+	require.Contains(t, manifest.Rules, "jwt")
+	require.Contains(t, manifest.Rules, "enroll_secret")
+}
+
+func readZip(t *testing.T, zr *zip.Reader) map[string][]byte {
+	files := make(map[string][]byte)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		contents, err := io.ReadAll(rc)
+		rc.Close()
+		require.NoError(t, err)
+		files[f.Name] = contents
+	}
+	return files
+}
+
+func gzipped(t *testing.T, s string) []byte {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	_, err := gw.Write([]byte(s))
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+	return buf.Bytes()
+}
+
+func gunzipped(t *testing.T, data []byte) string {
+	gr, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	decompressed, err := io.ReadAll(gr)
+	require.NoError(t, err)
+	return string(decompressed)
+}
@@ -0,0 +1,182 @@
+package runner
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/kolide/launcher/v2/ee/agent/types"
+	"github.com/kolide/launcher/v2/ee/desktop/user/menu"
+)
+
+const (
+	// menuQueryCheckInterval is how often we check whether any menu queries are due to run
+	menuQueryCheckInterval = 1 * time.Minute
+	// defaultMenuQueryInterval is how often menu queries run, if they don't specify an interval
+	defaultMenuQueryInterval = 5 * time.Minute
+	// minMenuQueryInterval is the most often that menu queries may run
+	minMenuQueryInterval = 1 * time.Minute
+)
+
+// menuQueryCache holds the queries declared by the current menu, and their most recent results.
+type menuQueryCache struct {
+	lock    sync.Mutex
+	queries map[string]menu.MenuQuery
+	results map[string][]map[string]string
+	lastRun map[string]time.Time
+}
+
+func newMenuQueryCache() *menuQueryCache {
+	return &menuQueryCache{
+		queries: make(map[string]menu.MenuQuery),
+		results: make(map[string][]map[string]string),
+		lastRun: make(map[string]time.Time),
+	}
+}
+
+// setQueries replaces the set of queries to run, discarding results for queries that were removed
+// and for queries whose SQL changed.
+func (c *menuQueryCache) setQueries(queries []menu.MenuQuery) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	newQueries := make(map[string]menu.MenuQuery)
+	for _, q := range queries {
+		if q.Name == "" || q.Query == "" {
+			continue
+		}
+		newQueries[q.Name] = q
+	}
+
+	for name, q := range c.queries {
+		if newQuery, found := newQueries[name]; found && newQuery.Query == q.Query {
+			continue
+		}
+		delete(c.results, name)
+		delete(c.lastRun, name)
+	}
+
+	c.queries = newQueries
+}
+
+// dueQueries returns the queries that have not run within their interval.
+func (c *menuQueryCache) dueQueries(now time.Time) []menu.MenuQuery {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	due := make([]menu.MenuQuery, 0)
+	for name, q := range c.queries {
+		if now.Sub(c.lastRun[name]) >= menuQueryInterval(q) {
+			due = append(due, q)
+		}
+	}
+
+	return due
+}
+
+// recordResults stores the results of the given query, returning true if they differ from
+// the previous results.
+func (c *menuQueryCache) recordResults(q menu.MenuQuery, results []map[string]string, ranAt time.Time) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	// The menu may have changed while the query was running
+	if current, found := c.queries[q.Name]; !found || current.Query != q.Query {
+		return false
+	}
+
+	c.lastRun[q.Name] = ranAt
+	previousResults, hadResults := c.results[q.Name]
+	c.results[q.Name] = results
+
+	return !hadResults || !reflect.DeepEqual(previousResults, results)
+}
+
+// currentResults returns a copy of the most recent results, suitable for use as template data.
+func (c *menuQueryCache) currentResults() map[string][]map[string]string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return maps.Clone(c.results)
+}
+
+func menuQueryInterval(q menu.MenuQuery) time.Duration {
+	if q.IntervalSeconds <= 0 {
+		return defaultMenuQueryInterval
+	}
+
+	return max(time.Duration(q.IntervalSeconds)*time.Second, minMenuQueryInterval)
+}
+
+// runMenuQueries runs the menu's queries as they come due, until ctx is canceled. When their
+// results change, it signals the execute loop to refresh the menu.
+func (r *DesktopUsersProcessesRunner) runMenuQueries(ctx context.Context) {
+	ticker := time.NewTicker(menuQueryCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.menuQueriesDue:
+		}
+
+		if !r.runDueMenuQueries(ctx) {
+			continue
+		}
+
+		select {
+		case r.menuQueryResultsChanged <- struct{}{}:
+		default:
+			// A refresh is already pending, and will pick up these results
+		}
+	}
+}
+
+// signalMenuQueriesDue asks runMenuQueries to check for due queries now, rather than at its next check.
+func (r *DesktopUsersProcessesRunner) signalMenuQueriesDue() {
+	select {
+	case r.menuQueriesDue <- struct{}{}:
+	default:
+		// A check is already pending
+	}
+}
+
+// runDueMenuQueries runs any menu queries that are due, returning true if any results changed
+// and the menu should be refreshed.
+func (r *DesktopUsersProcessesRunner) runDueMenuQueries(ctx context.Context) bool {
+	if r.querier == nil {
+		return false
+	}
+
+	resultsChanged := false
+	for _, q := range r.menuQueries.dueQueries(time.Now()) {
+		results, err := r.querier.Query(q.Query)
+		if err != nil {
+			// Keep the previous results, and try again on the next check -- osquery may not be up yet
+			r.slogger.Log(ctx, slog.LevelWarn,
+				"could not run menu query",
+				"query_name", q.Name,
+				"err", err,
+			)
+			continue
+		}
+
+		if r.menuQueries.recordResults(q, results, time.Now()) {
+			resultsChanged = true
+		}
+	}
+
+	return resultsChanged
+}
+
+// WithQuerier sets the querier used to run the queries declared by the menu.
+func WithQuerier(querier types.Querier) desktopUsersProcessesRunnerOption {
+	return func(r *DesktopUsersProcessesRunner) {
+		r.querier = querier
+	}
+}
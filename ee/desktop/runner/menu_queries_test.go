@@ -0,0 +1,169 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kolide/launcher/v2/ee/agent/flags/keys"
+	"github.com/kolide/launcher/v2/ee/agent/types"
+	"github.com/kolide/launcher/v2/ee/agent/types/mocks"
+	"github.com/kolide/launcher/v2/ee/desktop/user/menu"
+	"github.com/kolide/launcher/v2/pkg/log/multislogger"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type testQuerier struct {
+	lock    sync.Mutex
+	results map[string][]map[string]string
+	calls   int
+}
+
+func (q *testQuerier) Query(query string) ([]map[string]string, error) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.calls += 1
+	results, ok := q.results[query]
+	if !ok {
+		return nil, errors.New("unknown query")
+	}
+	return results, nil
+}
+
+func (q *testQuerier) setResults(query string, results []map[string]string) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.results[query] = results
+}
+
+func Test_menuQueryCache(t *testing.T) {
+	t.Parallel()
+
+	c := newMenuQueryCache()
+	diskQuery := menu.MenuQuery{Name: "disk", Query: "SELECT 1 AS encrypted;"}
+	firewallQuery := menu.MenuQuery{Name: "firewall", Query: "SELECT 0 AS enabled;", IntervalSeconds: 3600}
+	c.setQueries([]menu.MenuQuery{diskQuery, firewallQuery, {Name: "missing query"}})
+
+	// Queries that have never run are due, and queries without a name or SQL are ignored
+	now := time.Now()
+	require.ElementsMatch(t, []menu.MenuQuery{diskQuery, firewallQuery}, c.dueQueries(now))
+
+	// The first results, and changed results, are reported as changes
+	require.True(t, c.recordResults(diskQuery, []map[string]string{{"encrypted": "1"}}, now))
+	require.False(t, c.recordResults(diskQuery, []map[string]string{{"encrypted": "1"}}, now))
+	require.True(t, c.recordResults(diskQuery, []map[string]string{{"encrypted": "0"}}, now))
+	require.True(t, c.recordResults(firewallQuery, []map[string]string{}, now))
+	require.Empty(t, c.dueQueries(now))
+
+	// Queries run again once their interval has passed
+	require.Equal(t, []menu.MenuQuery{diskQuery}, c.dueQueries(now.Add(defaultMenuQueryInterval)))
+	require.ElementsMatch(t, []menu.MenuQuery{diskQuery, firewallQuery}, c.dueQueries(now.Add(time.Hour)))
+	require.Equal(t, map[string][]map[string]string{"disk": {{"encrypted": "0"}}, "firewall": {}}, c.currentResults())
+
+	// Changing a query's SQL discards its results, and results for the old SQL are not recorded
+	updatedDiskQuery := menu.MenuQuery{Name: "disk", Query: "SELECT 2 AS encrypted;"}
+	c.setQueries([]menu.MenuQuery{updatedDiskQuery, firewallQuery})
+	require.Equal(t, map[string][]map[string]string{"firewall": {}}, c.currentResults())
+	require.Equal(t, []menu.MenuQuery{updatedDiskQuery}, c.dueQueries(now))
+	require.False(t, c.recordResults(diskQuery, []map[string]string{{"encrypted": "1"}}, now))
+
+	// Removing a query discards its results
+	require.True(t, c.recordResults(updatedDiskQuery, []map[string]string{{"encrypted": "2"}}, now))
+	c.setQueries([]menu.MenuQuery{firewallQuery})
+	require.Equal(t, map[string][]map[string]string{"firewall": {}}, c.currentResults())
+}
+
+func Test_menuQueryInterval(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, defaultMenuQueryInterval, menuQueryInterval(menu.MenuQuery{}))
+	require.Equal(t, minMenuQueryInterval, menuQueryInterval(menu.MenuQuery{IntervalSeconds: 1}))
+	require.Equal(t, 10*time.Minute, menuQueryInterval(menu.MenuQuery{IntervalSeconds: 600}))
+}
+
+func TestUpdate_MenuQueries(t *testing.T) {
+	t.Parallel()
+
+	mockKnapsack := mocks.NewKnapsack(t)
+	mockKnapsack.On("RegisterChangeObserver", mock.Anything, keys.DesktopEnabled)
+	mockKnapsack.On("RegisterChangeObserver", mock.Anything, keys.DesktopGoMaxProcs)
+	mockKnapsack.On("RegisterChangeObserver", mock.Anything, keys.DesktopUpdateInterval)
+	mockKnapsack.On("RegisterChangeObserver", mock.Anything, keys.KolideServerURL)
+	mockKnapsack.On("DesktopUpdateInterval").Return(time.Millisecond * 250)
+	mockKnapsack.On("DesktopMenuRefreshInterval").Return(time.Millisecond * 250)
+	mockKnapsack.On("KolideServerURL").Return("somewhere-over-the-rainbow.example.com")
+	mockKnapsack.On("Slogger").Return(multislogger.NewNopLogger())
+	mockKnapsack.On("InModernStandby").Return(false)
+	mockKnapsack.On("LocalizationData").Return(types.LocalizationData{}).Maybe()
+
+	diskEncryptionQuery := "SELECT encrypted FROM disk_encryption LIMIT 1;"
+	querier := &testQuerier{
+		results: map[string][]map[string]string{
+			diskEncryptionQuery: {{"encrypted": "0"}},
+		},
+	}
+
+	r, err := New(mockKnapsack, nil, WithUsersFilesRoot(t.TempDir()), WithQuerier(querier))
+	require.NoError(t, err)
+
+	menuTemplate := `{
+		"icon": "default",
+		"queries": [{"name": "disk_encryption", "query": "` + diskEncryptionQuery + `"}],
+		"items": [
+			{{if hasCapability ` + "`menuQueries`" + `}}
+			{{if queryHasRows "disk_encryption"}}
+			{
+				"label": "Disk encryption: {{if eq (queryValue "disk_encryption" "encrypted") "1"}}on{{else}}off{{end}}",
+				"badge": "{{if eq (queryValue "disk_encryption" "encrypted") "1"}}success{{else}}error{{end}}"
+			},
+			{{end}}
+			{{end}}
+			{"label": "About Kolide..."}
+		]
+	}`
+
+	ctx, cancel := context.WithCancel(t.Context())
+	queriesDone := make(chan struct{})
+	go func() {
+		defer close(queriesDone)
+		r.runMenuQueries(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-queriesDone
+	})
+
+	// The query runs as soon as the menu declaring it is received, and a menu refresh is requested
+	require.NoError(t, r.Update(strings.NewReader(menuTemplate)))
+	select {
+	case <-r.menuQueryResultsChanged:
+	case <-time.After(5 * time.Second):
+		t.Error("menu refresh was not requested within 5 seconds")
+		t.FailNow()
+	}
+	r.refreshMenu()
+	menuContents, err := os.ReadFile(r.menuPath())
+	require.NoError(t, err)
+	require.Contains(t, string(menuContents), "Disk encryption: off")
+	require.Contains(t, string(menuContents), `"badge": "error"`)
+
+	// The query doesn't run again until it is due
+	require.False(t, r.runDueMenuQueries(t.Context()))
+	require.Equal(t, 1, querier.calls)
+
+	// Once it is due, changed results are picked up
+	querier.setResults(diskEncryptionQuery, []map[string]string{{"encrypted": "1"}})
+	r.menuQueries.lastRun["disk_encryption"] = time.Now().Add(-1 * defaultMenuQueryInterval)
+	require.True(t, r.runDueMenuQueries(t.Context()))
+	r.refreshMenu()
+	menuContents, err = os.ReadFile(r.menuPath())
+	require.NoError(t, err)
+	require.Contains(t, string(menuContents), "Disk encryption: on")
+	require.Contains(t, string(menuContents), `"badge": "success"`)
+}
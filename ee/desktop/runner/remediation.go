@@ -0,0 +1,397 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"reflect"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/kolide/kit/ulid"
+	"github.com/kolide/launcher/v2/ee/allowedcmd"
+	runnerserver "github.com/kolide/launcher/v2/ee/desktop/runner/server"
+	"github.com/kolide/launcher/v2/ee/desktop/user/client"
+	"github.com/kolide/launcher/v2/ee/desktop/user/menu"
+	"github.com/kolide/launcher/v2/ee/desktop/user/notify"
+	"github.com/kolide/launcher/v2/ee/gowrapper"
+)
+
+const (
+	// remediationResultMethod is the method used to report remediation results to the control server
+	remediationResultMethod = "remediation_result"
+	// defaultRemediationTimeout is how long remediations may run, if they don't specify a timeout
+	defaultRemediationTimeout = 1 * time.Minute
+	// maxRemediationTimeout is the longest that remediations may run
+	maxRemediationTimeout = 10 * time.Minute
+	// maxRemediationOutputLength is the most output we report to the control server, per remediation
+	maxRemediationOutputLength = 4096
+	defaultRemediationTitle    = "Kolide"
+	defaultRemediationSuccess  = "Finished successfully."
+	defaultRemediationFailure  = "Could not finish. If this keeps happening, contact your IT team."
+)
+
+// remediationRegistry holds the remediations declared by the current menu, and tracks which are running.
+// Desktop processes only send a remediation's ID, so we only ever run what the control server sent us.
+type remediationRegistry struct {
+	lock         sync.Mutex
+	remediations map[string]menu.MenuRemediation
+	running      map[string]struct{}
+	runningWg    sync.WaitGroup
+	stopped      bool // once stopped, no more remediations may begin
+}
+
+func newRemediationRegistry() *remediationRegistry {
+	return &remediationRegistry{
+		remediations: make(map[string]menu.MenuRemediation),
+		running:      make(map[string]struct{}),
+	}
+}
+
+// setRemediations replaces the set of remediations that may be run, skipping and returning errors
+// for any that are invalid. menuTemplate is the menu template as received from the control server,
+// before template expansion.
+func (rr *remediationRegistry) setRemediations(remediations []menu.MenuRemediation, menuTemplate []byte) []error {
+	newRemediations := make(map[string]menu.MenuRemediation)
+	errs := make([]error, 0)
+
+	declared, err := templateRemediations(menuTemplate)
+	if err != nil && len(remediations) > 0 {
+		// We can't tell which remediations the control server wrote, so we can't run any of them
+		errs = append(errs, fmt.Errorf("reading remediations from menu template: %w", err))
+		remediations = nil
+	}
+
+	for _, remediation := range remediations {
+		if err := validateRemediation(remediation); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := requireTemplateLiteral(remediation, declared); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		newRemediations[remediation.ID] = remediation
+	}
+
+	rr.lock.Lock()
+	defer rr.lock.Unlock()
+	rr.remediations = newRemediations
+
+	return errs
+}
+
+// begin marks the remediation with the given ID as running, and returns it.
+func (rr *remediationRegistry) begin(id string) (menu.MenuRemediation, error) {
+	rr.lock.Lock()
+	defer rr.lock.Unlock()
+
+	remediation, found := rr.remediations[id]
+	if !found {
+		return menu.MenuRemediation{}, fmt.Errorf("%w: %q", runnerserver.ErrUnknownRemediation, id)
+	}
+
+	if _, running := rr.running[id]; running {
+		return menu.MenuRemediation{}, fmt.Errorf("%w: %q", runnerserver.ErrRemediationInProgress, id)
+	}
+
+	if rr.stopped {
+		return menu.MenuRemediation{}, errors.New("desktop runner is shutting down")
+	}
+
+	rr.running[id] = struct{}{}
+	rr.runningWg.Add(1)
+
+	return remediation, nil
+}
+
+// finish marks the remediation with the given ID as no longer running.
+func (rr *remediationRegistry) finish(id string) {
+	rr.lock.Lock()
+	defer rr.lock.Unlock()
+
+	if _, running := rr.running[id]; !running {
+		return
+	}
+	delete(rr.running, id)
+	rr.runningWg.Done()
+}
+
+// stop prevents any more remediations from beginning, and waits for running remediations to finish,
+// or for ctx to be done.
+func (rr *remediationRegistry) stop(ctx context.Context) error {
+	rr.lock.Lock()
+	rr.stopped = true
+	rr.lock.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		rr.runningWg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("waiting for remediations to finish: %w", ctx.Err())
+	}
+}
+
+// remediationsKeyPattern finds the remediations key in the menu template
+var remediationsKeyPattern = regexp.MustCompile(`"remediations"\s*:`)
+
+// templateRemediations returns the remediations declared in the menu template, by ID, read from the
+// template before expansion. The rest of the template may not be valid JSON until it is expanded, so
+// we decode only the remediations array. Any remediation containing a template action is rejected.
+func templateRemediations(menuTemplate []byte) (map[string]menu.MenuRemediation, error) {
+	declared := make(map[string]menu.MenuRemediation)
+
+	keyLocations := remediationsKeyPattern.FindAllIndex(menuTemplate, -1)
+	switch len(keyLocations) {
+	case 0:
+		return declared, nil
+	case 1:
+	default:
+		return nil, fmt.Errorf("menu template declares remediations %d times", len(keyLocations))
+	}
+
+	var rawRemediations []json.RawMessage
+	if err := json.NewDecoder(bytes.NewReader(menuTemplate[keyLocations[0][1]:])).Decode(&rawRemediations); err != nil {
+		return nil, fmt.Errorf("remediations are not a literal JSON array: %w", err)
+	}
+
+	for _, rawRemediation := range rawRemediations {
+		var remediation menu.MenuRemediation
+		if err := json.Unmarshal(rawRemediation, &remediation); err != nil {
+			return nil, fmt.Errorf("unmarshalling remediation: %w", err)
+		}
+		if bytes.Contains(rawRemediation, []byte("{{")) {
+			return nil, fmt.Errorf("remediation %s contains a template action", remediation.ID)
+		}
+		if _, found := declared[remediation.ID]; found {
+			return nil, fmt.Errorf("remediation %s is declared more than once", remediation.ID)
+		}
+		declared[remediation.ID] = remediation
+	}
+
+	return declared, nil
+}
+
+// requireTemplateLiteral returns an error unless the remediation is exactly as declared in the menu
+// template, before expansion. The rendered menu includes osquery results, which local users may
+// influence, so what we run as root must be the control server's own text rather than the product
+// of template expansion.
+func requireTemplateLiteral(remediation menu.MenuRemediation, declared map[string]menu.MenuRemediation) error {
+	declaredRemediation, found := declared[remediation.ID]
+	if !found {
+		return fmt.Errorf("remediation %s is not declared in the menu template", remediation.ID)
+	}
+	if !reflect.DeepEqual(remediation, declaredRemediation) {
+		return fmt.Errorf("remediation %s does not match the menu template", remediation.ID)
+	}
+
+	return nil
+}
+
+func validateRemediation(remediation menu.MenuRemediation) error {
+	switch {
+	case remediation.ID == "":
+		return errors.New("remediation has no id")
+	case remediation.Command == "" && remediation.Script == "":
+		return fmt.Errorf("remediation %s has neither a command nor a script", remediation.ID)
+	case remediation.Command != "" && remediation.Script != "":
+		return fmt.Errorf("remediation %s has both a command and a script", remediation.ID)
+	case remediation.Script != "" && len(remediation.Args) > 0:
+		return fmt.Errorf("remediation %s has args for a script", remediation.ID)
+	}
+
+	if remediation.Command != "" {
+		if _, found := remediationCommands[remediation.Command]; !found {
+			return fmt.Errorf("remediation %s: command %q may not be used for remediations", remediation.ID, remediation.Command)
+		}
+	}
+
+	return nil
+}
+
+func remediationTimeout(remediation menu.MenuRemediation) time.Duration {
+	if remediation.TimeoutSeconds <= 0 {
+		return defaultRemediationTimeout
+	}
+
+	return min(time.Duration(remediation.TimeoutSeconds)*time.Second, maxRemediationTimeout)
+}
+
+// remediationResult is reported to the control server once a remediation finishes.
+type remediationResult struct {
+	RemediationID string    `json:"remediation_id"`
+	Uid           string    `json:"uid"`
+	Success       bool      `json:"success"`
+	ExitCode      int       `json:"exit_code"`
+	Output        string    `json:"output,omitempty"`
+	Error         string    `json:"error,omitempty"`
+	StartedAt     time.Time `json:"started_at"`
+	FinishedAt    time.Time `json:"finished_at"`
+}
+
+// StartRemediation runs the remediation with the given ID in the background, on behalf of the desktop
+// process registered under clientKey. The user is notified of the result, and it is reported to the
+// control server.
+func (r *DesktopUsersProcessesRunner) StartRemediation(_ context.Context, clientKey string, remediationID string) error {
+	r.uidProcsLock.Lock()
+	proc, found := r.uidProcs[clientKey]
+	r.uidProcsLock.Unlock()
+	if !found {
+		return fmt.Errorf("no desktop process registered under %s", clientKey)
+	}
+
+	remediation, err := r.remediations.begin(remediationID)
+	if err != nil {
+		return err
+	}
+
+	r.slogger.Log(context.TODO(), slog.LevelInfo,
+		"starting remediation requested by user",
+		"remediation_id", remediation.ID,
+		"uid", proc.Session.Uid,
+	)
+
+	gowrapper.Go(r.remediationsCtx, r.slogger, func() {
+		defer r.remediations.finish(remediation.ID)
+
+		result := runRemediation(r.remediationsCtx, remediation)
+		result.Uid = proc.Session.Uid
+		r.reportRemediationResult(clientKey, remediation, result)
+	})
+
+	return nil
+}
+
+// runRemediation runs the remediation, until it finishes, times out, or ctx is cancelled.
+func runRemediation(ctx context.Context, remediation menu.MenuRemediation) remediationResult {
+	ctx, cancel := context.WithTimeout(ctx, remediationTimeout(remediation))
+	defer cancel()
+
+	result := remediationResult{
+		RemediationID: remediation.ID,
+		ExitCode:      -1,
+		StartedAt:     time.Now().UTC(),
+	}
+
+	cmd, err := remediationCmd(ctx, remediation)
+	if err != nil {
+		result.Error = fmt.Sprintf("creating command: %v", err)
+		result.FinishedAt = time.Now().UTC()
+		return result
+	}
+
+	out, err := cmd.CombinedOutput()
+	result.FinishedAt = time.Now().UTC()
+	if len(out) > maxRemediationOutputLength {
+		out = out[len(out)-maxRemediationOutputLength:]
+	}
+	result.Output = string(out)
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		result.ExitCode = 0
+		result.Success = true
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+		result.Error = err.Error()
+	default:
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+func remediationCmd(ctx context.Context, remediation menu.MenuRemediation) (*allowedcmd.TracedCmd, error) {
+	if remediation.Script != "" {
+		return scriptCmd(ctx, remediation.Script)
+	}
+
+	command, found := remediationCommands[remediation.Command]
+	if !found {
+		return nil, fmt.Errorf("command %q may not be used for remediations", remediation.Command)
+	}
+
+	return command.Cmd(ctx, remediation.Args...)
+}
+
+// reportRemediationResult notifies the user who requested the remediation of its result, and reports
+// the result to the control server.
+func (r *DesktopUsersProcessesRunner) reportRemediationResult(clientKey string, remediation menu.MenuRemediation, result remediationResult) {
+	r.slogger.Log(context.TODO(), slog.LevelInfo,
+		"remediation finished",
+		"remediation_id", result.RemediationID,
+		"uid", result.Uid,
+		"success", result.Success,
+		"exit_code", result.ExitCode,
+		"err", result.Error,
+	)
+
+	if err := r.notifyProcess(clientKey, remediationNotification(remediation, result)); err != nil {
+		r.slogger.Log(context.TODO(), slog.LevelWarn,
+			"could not notify user of remediation result",
+			"remediation_id", result.RemediationID,
+			"err", err,
+		)
+	}
+
+	if r.messenger == nil {
+		return
+	}
+
+	if err := r.messenger.SendMessage(remediationResultMethod, result); err != nil {
+		r.slogger.Log(context.TODO(), slog.LevelWarn,
+			"could not report remediation result",
+			"remediation_id", result.RemediationID,
+			"err", err,
+		)
+	}
+}
+
+func remediationNotification(remediation menu.MenuRemediation, result remediationResult) notify.Notification {
+	n := notify.Notification{
+		ID:       ulid.New(),
+		Title:    remediation.Title,
+		Body:     remediation.SuccessMessage,
+		DedupKey: fmt.Sprintf("remediation_%s", remediation.ID),
+	}
+
+	if n.Title == "" {
+		n.Title = defaultRemediationTitle
+	}
+
+	if !result.Success {
+		n.Body = remediation.FailureMessage
+		if n.Body == "" {
+			n.Body = defaultRemediationFailure
+		}
+	} else if n.Body == "" {
+		n.Body = defaultRemediationSuccess
+	}
+
+	return n
+}
+
+// notifyProcess sends the notification to the desktop process registered under the given key only.
+func (r *DesktopUsersProcessesRunner) notifyProcess(key string, n notify.Notification) error {
+	r.uidProcsLock.Lock()
+	proc, found := r.uidProcs[key]
+	r.uidProcsLock.Unlock()
+	if !found {
+		return fmt.Errorf("no desktop process registered under %s", key)
+	}
+
+	client := client.New(r.userServerAuthToken, proc.socketPath)
+	return client.Notify(n)
+}
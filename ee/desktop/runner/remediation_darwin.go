@@ -0,0 +1,21 @@
+//go:build darwin
+
+package runner
+
+import (
+	"context"
+
+	"github.com/kolide/launcher/v2/ee/allowedcmd"
+)
+
+// remediationCommands are the allowed commands that remediations may run, by the name used in the menu
+var remediationCommands = map[string]allowedcmd.AllowedCommand{
+	"launchctl":      allowedcmd.Launchctl,
+	"socketfilterfw": allowedcmd.Socketfilterfw,
+	"softwareupdate": allowedcmd.Softwareupdate,
+}
+
+// scriptCmd returns a command that runs the remediation script with sh.
+func scriptCmd(ctx context.Context, script string) (*allowedcmd.TracedCmd, error) {
+	return allowedcmd.Sh.Cmd(ctx, "-c", script)
+}
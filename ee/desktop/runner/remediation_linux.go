@@ -0,0 +1,20 @@
+//go:build linux
+
+package runner
+
+import (
+	"context"
+
+	"github.com/kolide/launcher/v2/ee/allowedcmd"
+)
+
+// remediationCommands are the allowed commands that remediations may run, by the name used in the menu
+var remediationCommands = map[string]allowedcmd.AllowedCommand{
+	"nmcli":     allowedcmd.Nmcli,
+	"systemctl": allowedcmd.Systemctl,
+}
+
+// scriptCmd returns a command that runs the remediation script with sh.
+func scriptCmd(ctx context.Context, script string) (*allowedcmd.TracedCmd, error) {
+	return allowedcmd.Sh.Cmd(ctx, "-c", script)
+}
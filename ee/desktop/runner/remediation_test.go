@@ -0,0 +1,279 @@
+package runner
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kolide/launcher/v2/ee/agent/flags/keys"
+	"github.com/kolide/launcher/v2/ee/agent/types"
+	"github.com/kolide/launcher/v2/ee/agent/types/mocks"
+	runnerserver "github.com/kolide/launcher/v2/ee/desktop/runner/server"
+	"github.com/kolide/launcher/v2/ee/desktop/user/menu"
+	"github.com/kolide/launcher/v2/pkg/log/multislogger"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// anyRemediationCommand returns the name of a command that remediations may run on this platform
+func anyRemediationCommand(t *testing.T) string {
+	for name := range remediationCommands {
+		return name
+	}
+
+	t.Fatal("no remediation commands available")
+	return ""
+}
+
+func Test_remediationRegistry(t *testing.T) {
+	t.Parallel()
+
+	command := anyRemediationCommand(t)
+	menuTemplate := []byte(`{
+		"label": "{{queryValue "disk" "label"}}",
+		"remediations": [
+			{"id": "valid_command", "command": "` + command + `", "args": ["some", "args"]},
+			{"id": "valid_script", "script": "echo hello"},
+			{"id": "escaped_script", "script": "echo \"hi\" > /tmp/x"},
+			{"id": "changed_script", "script": "echo declared"},
+			{"id": "changed_args", "command": "` + command + `", "args": ["some", "args"]},
+			{"id": "changed_timeout", "script": "echo hello", "timeout_seconds": 10}
+		]
+	}`)
+	rr := newRemediationRegistry()
+	errs := rr.setRemediations([]menu.MenuRemediation{
+		{ID: "valid_command", Command: command, Args: []string{"some", "args"}},
+		{ID: "valid_script", Script: "echo hello"},
+		{ID: "escaped_script", Script: `echo "hi" > /tmp/x`},
+		{Script: "echo no id"},
+		{ID: "nothing_to_run"},
+		{ID: "both", Command: command, Script: "echo hello"},
+		{ID: "script_args", Script: "echo hello", Args: []string{"unused"}},
+		{ID: "unknown_command", Command: "rm", Args: []string{"-rf", "/"}},
+		{ID: "changed_script", Script: "echo injected"},
+		{ID: "changed_args", Command: command, Args: []string{"some", "injected"}},
+		{ID: "changed_timeout", Script: "echo hello", TimeoutSeconds: 600},
+		{ID: "undeclared", Script: "echo hello"},
+	}, menuTemplate)
+	require.Len(t, errs, 9)
+
+	_, err := rr.begin("escaped_script")
+	require.NoError(t, err)
+
+	for _, invalidId := range []string{"", "nothing_to_run", "both", "script_args", "unknown_command", "changed_script", "changed_args", "changed_timeout", "undeclared"} {
+		_, err := rr.begin(invalidId)
+		require.ErrorIs(t, err, runnerserver.ErrUnknownRemediation, invalidId)
+	}
+
+	// A remediation can't be started again until it finishes
+	remediation, err := rr.begin("valid_command")
+	require.NoError(t, err)
+	require.Equal(t, command, remediation.Command)
+	_, err = rr.begin("valid_command")
+	require.ErrorIs(t, err, runnerserver.ErrRemediationInProgress)
+	_, err = rr.begin("valid_script")
+	require.NoError(t, err)
+
+	rr.finish("valid_command")
+	_, err = rr.begin("valid_command")
+	require.NoError(t, err)
+
+	// Remediations removed from the menu can no longer be run
+	require.Empty(t, rr.setRemediations([]menu.MenuRemediation{{ID: "valid_script", Script: "echo hello"}}, menuTemplate))
+	rr.finish("valid_command")
+	_, err = rr.begin("valid_command")
+	require.ErrorIs(t, err, runnerserver.ErrUnknownRemediation)
+}
+
+func Test_templateRemediations(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name        string
+		template    string
+		expectedIds []string
+		expectErr   bool
+	}{
+		{
+			name:     "no remediations",
+			template: `{"label": "{{queryValue "disk" "label"}}"}`,
+		},
+		{
+			name:        "literal remediations",
+			template:    `{"label": "{{.ServerHostname}}", "remediations": [{"id": "one", "script": "echo one"}, {"id": "two", "script": "echo two"}]}`,
+			expectedIds: []string{"one", "two"},
+		},
+		{
+			name:      "template action in a field",
+			template:  `{"remediations": [{"id": "one", "script": "echo {{.ServerHostname}}"}]}`,
+			expectErr: true,
+		},
+		{
+			name:      "template action in the array",
+			template:  `{"remediations": [{{range .Remediations}}{"id": "{{.}}"}{{end}}]}`,
+			expectErr: true,
+		},
+		{
+			name:      "declared twice",
+			template:  `{"remediations": [{"id": "one", "script": "echo one"}], "items": [{"remediations": []}]}`,
+			expectErr: true,
+		},
+		{
+			name:      "duplicate id",
+			template:  `{"remediations": [{"id": "one", "script": "echo one"}, {"id": "one", "script": "echo two"}]}`,
+			expectErr: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			declared, err := templateRemediations([]byte(tt.template))
+			if tt.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, declared, len(tt.expectedIds))
+			for _, id := range tt.expectedIds {
+				require.Contains(t, declared, id)
+			}
+		})
+	}
+}
+
+func Test_remediationTimeout(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, defaultRemediationTimeout, remediationTimeout(menu.MenuRemediation{}))
+	require.Equal(t, 30*time.Second, remediationTimeout(menu.MenuRemediation{TimeoutSeconds: 30}))
+	require.Equal(t, maxRemediationTimeout, remediationTimeout(menu.MenuRemediation{TimeoutSeconds: 86400}))
+}
+
+func Test_runRemediation(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("scripts run with sh in this test")
+	}
+
+	result := runRemediation(t.Context(), menu.MenuRemediation{ID: "succeeds", Script: "echo fixed"})
+	require.True(t, result.Success)
+	require.Equal(t, 0, result.ExitCode)
+	require.Equal(t, "fixed\n", result.Output)
+	require.Empty(t, result.Error)
+	require.False(t, result.FinishedAt.Before(result.StartedAt))
+
+	result = runRemediation(t.Context(), menu.MenuRemediation{ID: "fails", Script: "echo could not fix; exit 3"})
+	require.False(t, result.Success)
+	require.Equal(t, 3, result.ExitCode)
+	require.Equal(t, "could not fix\n", result.Output)
+	require.NotEmpty(t, result.Error)
+
+	// Only the end of long output is kept
+	result = runRemediation(t.Context(), menu.MenuRemediation{ID: "verbose", Script: "yes | head -c 10000; echo done"})
+	require.True(t, result.Success)
+	require.Len(t, result.Output, maxRemediationOutputLength)
+	require.True(t, strings.HasSuffix(result.Output, "done\n"))
+
+	// Remediations stop when their context is cancelled
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	result = runRemediation(ctx, menu.MenuRemediation{ID: "cancelled", Script: "sleep 30"})
+	require.False(t, result.Success)
+	require.Less(t, result.FinishedAt.Sub(result.StartedAt), 10*time.Second)
+}
+
+func Test_remediationRegistry_stop(t *testing.T) {
+	t.Parallel()
+
+	rr := newRemediationRegistry()
+	require.Empty(t, rr.setRemediations([]menu.MenuRemediation{{ID: "running", Script: "echo hello"}},
+		[]byte(`{"remediations": [{"id": "running", "script": "echo hello"}]}`)))
+	_, err := rr.begin("running")
+	require.NoError(t, err)
+
+	// stop waits for running remediations, until its context is done
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+	require.Error(t, rr.stop(ctx))
+
+	// No more remediations may begin once stopped
+	rr.finish("running")
+	_, err = rr.begin("running")
+	require.Error(t, err)
+
+	require.NoError(t, rr.stop(t.Context()))
+}
+
+func Test_remediationNotification(t *testing.T) {
+	t.Parallel()
+
+	remediation := menu.MenuRemediation{ID: "enable_firewall", Title: "Enable firewall", SuccessMessage: "Your firewall is on."}
+
+	n := remediationNotification(remediation, remediationResult{Success: true})
+	require.Equal(t, "Enable firewall", n.Title)
+	require.Equal(t, "Your firewall is on.", n.Body)
+	require.Equal(t, "remediation_enable_firewall", n.DedupKey)
+	require.NotEmpty(t, n.ID)
+
+	n = remediationNotification(remediation, remediationResult{Success: false})
+	require.Equal(t, defaultRemediationFailure, n.Body)
+
+	n = remediationNotification(menu.MenuRemediation{ID: "untitled"}, remediationResult{Success: true})
+	require.Equal(t, defaultRemediationTitle, n.Title)
+	require.Equal(t, defaultRemediationSuccess, n.Body)
+}
+
+func TestUpdate_MenuRemediations(t *testing.T) {
+	t.Parallel()
+
+	mockKnapsack := mocks.NewKnapsack(t)
+	mockKnapsack.On("RegisterChangeObserver", mock.Anything, keys.DesktopEnabled)
+	mockKnapsack.On("RegisterChangeObserver", mock.Anything, keys.DesktopGoMaxProcs)
+	mockKnapsack.On("RegisterChangeObserver", mock.Anything, keys.DesktopUpdateInterval)
+	mockKnapsack.On("RegisterChangeObserver", mock.Anything, keys.KolideServerURL)
+	mockKnapsack.On("DesktopUpdateInterval").Return(time.Millisecond * 250)
+	mockKnapsack.On("DesktopMenuRefreshInterval").Return(time.Millisecond * 250)
+	mockKnapsack.On("KolideServerURL").Return("somewhere-over-the-rainbow.example.com")
+	mockKnapsack.On("Slogger").Return(multislogger.NewNopLogger())
+	mockKnapsack.On("InModernStandby").Return(false)
+	mockKnapsack.On("LocalizationData").Return(types.LocalizationData{}).Maybe()
+
+	r, err := New(mockKnapsack, nil, WithUsersFilesRoot(t.TempDir()))
+	require.NoError(t, err)
+
+	menuTemplate := `{
+		"icon": "default",
+		"remediations": [
+			{"id": "restart_agent", "title": "Restart agent", "script": "echo restarting"},
+			{"id": "invalid", "title": "Invalid"}
+		],
+		"items": [
+			{"label": "Restart agent", "action": {"type": "remediate", "action": {"remediation_id": "restart_agent"}}}
+		]
+	}`
+
+	// Valid remediations declared by the menu may be run; invalid ones are skipped
+	require.NoError(t, r.Update(strings.NewReader(menuTemplate)))
+	_, err = r.remediations.begin("invalid")
+	require.ErrorIs(t, err, runnerserver.ErrUnknownRemediation)
+	remediation, err := r.remediations.begin("restart_agent")
+	require.NoError(t, err)
+	require.Equal(t, "echo restarting", remediation.Script)
+	r.remediations.finish("restart_agent")
+
+	// Remediations must run exactly what the control server sent, not the results of template expansion,
+	// so a menu with templated remediations can't run any of them
+	expandedMenuTemplate := strings.Replace(menuTemplate, `{"id": "invalid", "title": "Invalid"}`, `{"id": "expanded", "title": "Expanded", "script": "echo {{.ServerHostname}}"}`, 1)
+	require.NoError(t, r.Update(strings.NewReader(expandedMenuTemplate)))
+	for _, id := range []string{"expanded", "restart_agent"} {
+		_, err = r.remediations.begin(id)
+		require.ErrorIs(t, err, runnerserver.ErrUnknownRemediation, id)
+	}
+	require.NoError(t, r.Update(strings.NewReader(menuTemplate)))
+
+	// Only desktop processes that we spawned may request remediations
+	require.Error(t, r.StartRemediation(t.Context(), "some_unknown_client", "restart_agent"))
+}
@@ -0,0 +1,21 @@
+//go:build windows
+
+package runner
+
+import (
+	"context"
+
+	"github.com/kolide/launcher/v2/ee/allowedcmd"
+)
+
+// remediationCommands are the allowed commands that remediations may run, by the name used in the menu
+var remediationCommands = map[string]allowedcmd.AllowedCommand{
+	"dism":     allowedcmd.Dism,
+	"powercfg": allowedcmd.Powercfg,
+	"taskkill": allowedcmd.Taskkill,
+}
+
+// scriptCmd returns a command that runs the remediation script with PowerShell.
+func scriptCmd(ctx context.Context, script string) (*allowedcmd.TracedCmd, error) {
+	return allowedcmd.Powershell.Cmd(ctx, "-NoProfile", "-NonInteractive", "-Command", script)
+}
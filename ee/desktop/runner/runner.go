@@ -35,6 +35,7 @@ import (
 	"github.com/kolide/launcher/v2/ee/desktop/user/notify"
 	"github.com/kolide/launcher/v2/ee/gowrapper"
 	"github.com/kolide/launcher/v2/ee/log"
+	"github.com/kolide/launcher/v2/ee/nativemessaging"
 	"github.com/kolide/launcher/v2/ee/observability"
 	"github.com/kolide/launcher/v2/ee/presencedetection"
 	"github.com/kolide/launcher/v2/ee/ui/assets"
@@ -125,7 +126,7 @@ type DesktopUsersProcessesRunner struct {
 	menuRefreshInterval time.Duration
 	interrupt           chan struct{}
 	interrupted         syncatomic.Bool
-	// uidProcs is a map of uid to desktop process
+	// uidProcs is a map of desktop process key (see processKey) to desktop process
 	uidProcs     map[string]processRecord
 	uidProcsLock *sync.Mutex
 	// procsWg is a WaitGroup to wait for all desktop processes to finish during an interrupt
@@ -149,6 +150,21 @@ type DesktopUsersProcessesRunner struct {
 	osVersion string
 	// cachedMenuData is the cached label values of the currently displayed menu data, used for detecting changes
 	cachedMenuData *menuItemCache
+	// querier runs the osquery queries declared by the menu; if nil, menu queries are not run
+	querier types.Querier
+	// menuQueries holds the queries declared by the menu, and their results for use in the menu template
+	menuQueries *menuQueryCache
+	// menuQueriesDue signals runMenuQueries to check for due queries right away
+	menuQueriesDue chan struct{}
+	// menuQueryResultsChanged signals the execute loop to refresh the menu with new query results
+	menuQueryResultsChanged chan struct{}
+	// remediations holds the remediations declared by the menu, which users may run from the menu
+	remediations *remediationRegistry
+	// remediationsCtx is the context remediations run under; Interrupt cancels it with cancelRemediations
+	remediationsCtx    context.Context //nolint:containedctx // remediations run in the background, and must stop on interrupt
+	cancelRemediations context.CancelFunc
+	// messenger sends messages to the control server
+	messenger runnerserver.Messenger
 }
 
 // processRecord is used to track spawned desktop processes.
@@ -159,10 +175,37 @@ type DesktopUsersProcessesRunner struct {
 type processRecord struct {
 	Process                    *os.Process
 	StartTime, LastHealthCheck time.Time
+	Session                    consoleuser.Session
 	path                       string
 	socketPath                 string
 }
 
+// processKey returns the key that the desktop process for the given session is tracked under,
+// both in uidProcs and with the runner server. Sessions without an ID (i.e. on macOS and Windows,
+// where each console user has a single session) are tracked by uid alone.
+func processKey(session consoleuser.Session) string {
+	if session.Id == "" {
+		return session.Uid
+	}
+	return fmt.Sprintf("%s_%s", session.Uid, session.Id)
+}
+
+// procForUid returns a desktop process running for the given user, in any of their sessions.
+// Callers must hold uidProcsLock.
+func (r *DesktopUsersProcessesRunner) procForUid(uid string) (processRecord, bool) {
+	if proc, ok := r.uidProcs[uid]; ok {
+		return proc, true
+	}
+
+	for _, proc := range r.uidProcs {
+		if proc.Session.Uid == uid {
+			return proc, true
+		}
+	}
+
+	return processRecord{}, false
+}
+
 func (pr processRecord) String() string {
 	return fmt.Sprintf("%s [socket: %s, started: %s, last_health_check: %s])",
 		pr.path,
@@ -175,17 +218,23 @@ func (pr processRecord) String() string {
 // New creates and returns a new DesktopUsersProcessesRunner runner and initializes all required fields
 func New(k types.Knapsack, messenger runnerserver.Messenger, opts ...desktopUsersProcessesRunnerOption) (*DesktopUsersProcessesRunner, error) {
 	runner := &DesktopUsersProcessesRunner{
-		interrupt:           make(chan struct{}),
-		uidProcs:            make(map[string]processRecord),
-		uidProcsLock:        &sync.Mutex{},
-		updateInterval:      atomic.NewDuration(k.DesktopUpdateInterval()),
-		menuRefreshInterval: k.DesktopMenuRefreshInterval(),
-		procsWg:             &sync.WaitGroup{},
-		interruptTimeout:    time.Second * 5,
-		usersFilesRoot:      agent.TempPath("kolide-desktop"),
-		knapsack:            k,
-		cachedMenuData:      newMenuItemCache(),
-	}
+		interrupt:               make(chan struct{}),
+		uidProcs:                make(map[string]processRecord),
+		uidProcsLock:            &sync.Mutex{},
+		updateInterval:          atomic.NewDuration(k.DesktopUpdateInterval()),
+		menuRefreshInterval:     k.DesktopMenuRefreshInterval(),
+		procsWg:                 &sync.WaitGroup{},
+		interruptTimeout:        time.Second * 5,
+		usersFilesRoot:          agent.TempPath("kolide-desktop"),
+		knapsack:                k,
+		cachedMenuData:          newMenuItemCache(),
+		menuQueries:             newMenuQueryCache(),
+		menuQueriesDue:          make(chan struct{}, 1),
+		menuQueryResultsChanged: make(chan struct{}, 1),
+		remediations:            newRemediationRegistry(),
+		messenger:               messenger,
+	}
+	runner.remediationsCtx, runner.cancelRemediations = context.WithCancel(context.Background())
 
 	runner.slogger = k.Slogger().With("component", "desktop_runner")
 
@@ -215,6 +264,7 @@ func New(k types.Knapsack, messenger runnerserver.Messenger, opts ...desktopUser
 	}
 
 	runner.runnerServer = rs
+	runner.runnerServer.SetRemediationHandler(runner)
 
 	if runtime.GOOS == "darwin" {
 		runner.osVersion, err = osversion() //nolint:staticcheck // Ignore SA4023 on Windows/Linux; this only runs on darwin, where err is not always nil
@@ -258,6 +308,13 @@ func (r *DesktopUsersProcessesRunner) Execute() error {
 	osUpdateCheckTicker := time.NewTicker(1 * time.Minute)
 	defer osUpdateCheckTicker.Stop()
 
+	// Menu queries may be slow, so they run apart from this loop
+	menuQueriesCtx, cancelMenuQueries := context.WithCancel(context.Background())
+	defer cancelMenuQueries()
+	gowrapper.Go(menuQueriesCtx, r.slogger, func() {
+		r.runMenuQueries(menuQueriesCtx)
+	})
+
 	for {
 		// Check immediately on each iteration, avoiding the initial ticker delay
 		if err := r.runConsoleUserDesktop(); err != nil {
@@ -284,6 +341,9 @@ func (r *DesktopUsersProcessesRunner) Execute() error {
 		case <-osUpdateCheckTicker.C:
 			r.checkOsUpdate()
 			continue
+		case <-r.menuQueryResultsChanged:
+			r.refreshMenu()
+			continue
 		case <-r.interrupt:
 			r.slogger.Log(context.TODO(), slog.LevelDebug,
 				"interrupt received, exiting desktop execute loop",
@@ -312,6 +372,15 @@ func (r *DesktopUsersProcessesRunner) Interrupt(_ error) {
 	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer cancel()
 
+	// Stop any running remediations, and wait for them to report their results
+	r.cancelRemediations()
+	if err := r.remediations.stop(ctx); err != nil {
+		r.slogger.Log(ctx, slog.LevelWarn,
+			"remediations did not finish before shutdown",
+			"err", err,
+		)
+	}
+
 	// Kill any desktop processes that may exist
 	r.killDesktopProcesses(ctx)
 
@@ -360,7 +429,7 @@ func (r *DesktopUsersProcessesRunner) CreateSecureEnclaveKey(ctx context.Context
 		return nil, errors.New("no desktop processes running")
 	}
 
-	proc, ok := r.uidProcs[uid]
+	proc, ok := r.procForUid(uid)
 	if !ok {
 		return nil, fmt.Errorf("no desktop process for uid: %s", uid)
 	}
@@ -392,7 +461,7 @@ func (r *DesktopUsersProcessesRunner) VerifySecureEnclaveKey(ctx context.Context
 		return false, errors.New("no desktop processes running")
 	}
 
-	proc, ok := r.uidProcs[uid]
+	proc, ok := r.procForUid(uid)
 	if !ok {
 		return false, fmt.Errorf("no desktop process for uid: %s", uid)
 	}
@@ -401,6 +470,48 @@ func (r *DesktopUsersProcessesRunner) VerifySecureEnclaveKey(ctx context.Context
 	return client.VerifySecureEnclaveKey(ctx, pubKey)
 }
 
+// GetUserSecret returns the secret stored under key by the given user's desktop process.
+func (r *DesktopUsersProcessesRunner) GetUserSecret(ctx context.Context, uid string, key string) ([]byte, error) {
+	r.uidProcsLock.Lock()
+	defer r.uidProcsLock.Unlock()
+
+	proc, ok := r.procForUid(uid)
+	if !ok {
+		return nil, fmt.Errorf("no desktop process for uid: %s", uid)
+	}
+
+	client := client.New(r.userServerAuthToken, proc.socketPath)
+	return client.GetSecret(ctx, key)
+}
+
+// SetUserSecret stores value under key via the given user's desktop process.
+func (r *DesktopUsersProcessesRunner) SetUserSecret(ctx context.Context, uid string, key string, value []byte) error {
+	r.uidProcsLock.Lock()
+	defer r.uidProcsLock.Unlock()
+
+	proc, ok := r.procForUid(uid)
+	if !ok {
+		return fmt.Errorf("no desktop process for uid: %s", uid)
+	}
+
+	client := client.New(r.userServerAuthToken, proc.socketPath)
+	return client.SetSecret(ctx, key, value)
+}
+
+// DeleteUserSecret removes the secret stored under key by the given user's desktop process.
+func (r *DesktopUsersProcessesRunner) DeleteUserSecret(ctx context.Context, uid string, key string) error {
+	r.uidProcsLock.Lock()
+	defer r.uidProcsLock.Unlock()
+
+	proc, ok := r.procForUid(uid)
+	if !ok {
+		return fmt.Errorf("no desktop process for uid: %s", uid)
+	}
+
+	client := client.New(r.userServerAuthToken, proc.socketPath)
+	return client.DeleteSecret(ctx, key)
+}
+
 // killDesktopProcesses kills any existing desktop processes
 func (r *DesktopUsersProcessesRunner) killDesktopProcesses(ctx context.Context) {
 	ctx, span := observability.StartSpan(ctx)
@@ -416,15 +527,17 @@ func (r *DesktopUsersProcessesRunner) killDesktopProcesses(ctx context.Context)
 	})
 
 	shutdownRequestCount := 0
-	for uid, proc := range r.uidProcs {
+	for key, proc := range r.uidProcs {
 		// unregistering client from runner server so server will not respond to its requests
-		r.runnerServer.DeRegisterClient(uid)
+		r.runnerServer.DeRegisterClient(key)
+		r.runnerServer.DeRegisterClient(nativeMessagingClientKey(proc.Session.Uid))
 
 		client := client.New(r.userServerAuthToken, proc.socketPath)
 		if err := client.Shutdown(ctx); err != nil {
 			r.slogger.Log(ctx, slog.LevelError,
 				"sending shutdown command to user desktop process",
-				"uid", uid,
+				"uid", proc.Session.Uid,
+				"session_id", proc.Session.Id,
 				"pid", proc.Process.Pid,
 				"path", proc.path,
 				"err", err,
@@ -450,14 +563,15 @@ func (r *DesktopUsersProcessesRunner) killDesktopProcesses(ctx context.Context)
 			"timeout waiting for desktop processes to exit, now killing",
 		)
 
-		for uid, processRecord := range r.uidProcs {
+		for _, processRecord := range r.uidProcs {
 			if !r.processExists(processRecord) {
 				continue
 			}
 			if err := processRecord.Process.Kill(); err != nil {
 				r.slogger.Log(ctx, slog.LevelError,
 					"killing desktop process",
-					"uid", uid,
+					"uid", processRecord.Session.Uid,
+					"session_id", processRecord.Session.Id,
 					"pid", processRecord.Process.Pid,
 					"path", processRecord.path,
 					"err", err,
@@ -471,55 +585,64 @@ func (r *DesktopUsersProcessesRunner) killDesktopProcesses(ctx context.Context)
 	)
 }
 
-// killDesktopProcess kills the existing desktop process for the given uid
-func (r *DesktopUsersProcessesRunner) killDesktopProcess(ctx context.Context, uid string) error {
+// killDesktopProcess kills the existing desktop process tracked under the given key (see processKey)
+func (r *DesktopUsersProcessesRunner) killDesktopProcess(ctx context.Context, key string) error {
 	r.uidProcsLock.Lock()
 	defer r.uidProcsLock.Unlock()
 
-	proc, ok := r.uidProcs[uid]
+	proc, ok := r.uidProcs[key]
 	if !ok {
-		return fmt.Errorf("could not find desktop proc for uid %s, cannot kill process", uid)
+		return fmt.Errorf("could not find desktop proc for %s, cannot kill process", key)
 	}
 
 	// unregistering client from runner server so server will not respond to its requests
-	r.runnerServer.DeRegisterClient(uid)
+	r.runnerServer.DeRegisterClient(key)
+
+	// The native messaging host is registered per user rather than per session, so leave it
+	// registered while the user has desktop processes in other sessions
+	delete(r.uidProcs, key)
+	if _, userHasOtherProcs := r.procForUid(proc.Session.Uid); !userHasOtherProcs {
+		r.runnerServer.DeRegisterClient(nativeMessagingClientKey(proc.Session.Uid))
+	}
 
 	client := client.New(r.userServerAuthToken, proc.socketPath)
 	err := client.Shutdown(ctx)
 	if err == nil {
 		r.slogger.Log(ctx, slog.LevelInfo,
 			"shut down user desktop process",
-			"uid", uid,
+			"uid", proc.Session.Uid,
+			"session_id", proc.Session.Id,
 		)
-		delete(r.uidProcs, uid)
 		return nil
 	}
 
 	// We didn't successfully send a shutdown request -- check to see if it's because
 	// the process is already gone.
 	if !r.processExists(proc) {
-		delete(r.uidProcs, uid)
 		return nil
 	}
 
 	r.slogger.Log(ctx, slog.LevelWarn,
 		"failed to send shutdown command to user desktop process, killing process instead",
-		"uid", uid,
+		"uid", proc.Session.Uid,
+		"session_id", proc.Session.Id,
 		"pid", proc.Process.Pid,
 		"path", proc.path,
 		"err", err,
 	)
 
 	if err := proc.Process.Kill(); err != nil {
-		return fmt.Errorf("could not kill desktop process for uid %s with pid %d: %w", uid, proc.Process.Pid, err)
+		// Keep tracking the process, since it's still running
+		r.uidProcs[key] = proc
+		return fmt.Errorf("could not kill desktop process for %s with pid %d: %w", key, proc.Process.Pid, err)
 	}
 
 	// Successfully killed process
 	r.slogger.Log(ctx, slog.LevelInfo,
 		"killed user desktop process",
-		"uid", uid,
+		"uid", proc.Session.Uid,
+		"session_id", proc.Session.Id,
 	)
-	delete(r.uidProcs, uid)
 	return nil
 }
 
@@ -588,6 +711,9 @@ func (r *DesktopUsersProcessesRunner) Update(data io.Reader) error {
 	// any desktop user processes, either when they refresh, or when they are spawned.
 	r.refreshMenu()
 
+	// Run any queries newly declared by the menu right away, rather than waiting for the next check
+	r.signalMenuQueriesDue()
+
 	return nil
 }
 
@@ -781,6 +907,7 @@ func (r *DesktopUsersProcessesRunner) generateMenuFile() error {
 		menu.ServerHostname:     r.knapsack.KolideServerURL(),
 		menu.LastMenuUpdateTime: info.ModTime().Unix(),
 		menu.MenuVersion:        menu.CurrentMenuVersion,
+		menu.QueryResults:       r.menuQueries.currentResults(),
 	}
 
 	menuTemplateFileBytes, err := os.ReadFile(r.menuTemplatePath())
@@ -798,6 +925,24 @@ func (r *DesktopUsersProcessesRunner) generateMenuFile() error {
 	// Convert the parsed string back to bytes, which can now be decoded per usual
 	parsedMenuDataBytes := []byte(parsedMenuDataStr)
 
+	// Pick up any changes to the queries and remediations declared by the menu
+	var parsedMenuData menu.MenuData
+	if err := json.Unmarshal(parsedMenuDataBytes, &parsedMenuData); err != nil {
+		r.slogger.Log(context.TODO(), slog.LevelWarn,
+			"could not decode menu data to read menu queries and remediations",
+			"err", err,
+		)
+	} else {
+		r.menuQueries.setQueries(parsedMenuData.Queries)
+		// Remediations run as root, so they're checked against the template as the control server sent it
+		for _, err := range r.remediations.setRemediations(parsedMenuData.Remediations, menuTemplateFileBytes) {
+			r.slogger.Log(context.TODO(), slog.LevelWarn,
+				"skipping invalid menu remediation",
+				"err", err,
+			)
+		}
+	}
+
 	// Write the menu data out to a file that can be grabbed by
 	// any desktop user processes, either when they refresh, or when they are spawned.
 	if err := r.writeSharedFile(r.menuPath(), parsedMenuDataBytes); err != nil {
@@ -856,50 +1001,98 @@ func (r *DesktopUsersProcessesRunner) runConsoleUserDesktop() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 40*time.Second)
 	defer cancel()
 
-	consoleUsers, err := consoleuser.CurrentUids(ctx)
+	sessions, err := consoleuser.CurrentSessions(ctx)
 	if err != nil {
-		return fmt.Errorf("getting console users: %w", err)
+		return fmt.Errorf("getting console user sessions: %w", err)
 	}
 
-	for _, uid := range consoleUsers {
-		if r.userHasDesktopProcess(uid) {
+	r.shutDownEndedSessions(ctx, sessions)
+
+	var spawnErrs []error
+	for _, session := range sessions {
+		if r.userHasDesktopProcess(processKey(session)) {
 			continue
 		}
 
 		// Check to see if necessary dependencies are running on macOS before we spawn the desktop process.
 		// This will block for up to 30 seconds, at which point we proceed with trying to spawn anyway.
-		r.waitForReadyToSpawnDesktopState(ctx, uid)
+		r.waitForReadyToSpawnDesktopState(ctx, session.Uid)
 
-		// we've decided to spawn a new desktop user process for this user
-		if err := r.spawnForUser(ctx, uid); err != nil {
-			return fmt.Errorf("spawning new desktop user process for %s: %w", uid, err)
+		// we've decided to spawn a new desktop user process for this session. A failure
+		// shouldn't prevent us from spawning processes for the remaining sessions.
+		if err := r.spawnForSession(ctx, session); err != nil {
+			spawnErrs = append(spawnErrs, fmt.Errorf("spawning new desktop user process for %s: %w", processKey(session), err))
 		}
 	}
 
-	return nil
+	return errors.Join(spawnErrs...)
+}
+
+// shutDownEndedSessions shuts down desktop processes for logind sessions that no longer
+// exist or are closing; sessions in the background of their seat are still current. Session
+// IDs are not reused, so these processes would otherwise never be replaced.
+// Processes tracked without a session ID are left alone, to be replaced when their user
+// next logs in.
+func (r *DesktopUsersProcessesRunner) shutDownEndedSessions(ctx context.Context, currentSessions []consoleuser.Session) {
+	currentKeys := make(map[string]struct{})
+	for _, session := range currentSessions {
+		currentKeys[processKey(session)] = struct{}{}
+	}
+
+	r.uidProcsLock.Lock()
+	var endedKeys []string
+	for key, proc := range r.uidProcs {
+		if proc.Session.Id == "" {
+			continue
+		}
+		if _, ok := currentKeys[key]; !ok {
+			endedKeys = append(endedKeys, key)
+		}
+	}
+	r.uidProcsLock.Unlock()
+
+	for _, key := range endedKeys {
+		if err := r.killDesktopProcess(ctx, key); err != nil {
+			r.slogger.Log(ctx, slog.LevelWarn,
+				"could not shut down desktop process for ended session",
+				"process_key", key,
+				"err", err,
+			)
+		}
+	}
 }
 
-func (r *DesktopUsersProcessesRunner) spawnForUser(ctx context.Context, uid string) error {
-	ctx, span := observability.StartSpan(ctx, "uid", uid)
+func (r *DesktopUsersProcessesRunner) spawnForSession(ctx context.Context, session consoleuser.Session) error {
+	ctx, span := observability.StartSpan(ctx, "uid", session.Uid, "session_id", session.Id)
 	defer span.End()
 
+	key := processKey(session)
+
 	// make sure any existing user desktop processes stop being
 	// recognized by the runner server
-	r.runnerServer.DeRegisterClient(uid)
+	r.runnerServer.DeRegisterClient(key)
 
-	socketPath, err := r.setupSocketPath(uid)
+	socketPath, err := r.setupSocketPath(session)
 	if err != nil {
 		observability.SetError(span, fmt.Errorf("getting socket path: %w", err))
 		return fmt.Errorf("getting socket path: %w", err)
 	}
 
-	cmd, err := r.desktopCommand(uid, socketPath, r.menuPath())
+	if err := r.writeNativeMessagingConnection(session.Uid); err != nil {
+		r.slogger.Log(ctx, slog.LevelWarn,
+			"could not write native messaging connection file for user",
+			"uid", session.Uid,
+			"err", err,
+		)
+	}
+
+	cmd, err := r.desktopCommand(key, socketPath, r.menuPath())
 	if err != nil {
 		observability.SetError(span, fmt.Errorf("creating desktop command: %w", err))
 		return fmt.Errorf("creating desktop command: %w", err)
 	}
 
-	if err := r.runAsUser(ctx, uid, cmd); err != nil {
+	if err := r.runAsUser(ctx, session, cmd); err != nil {
 		observability.SetError(span, fmt.Errorf("running desktop command as user: %w", err))
 		return fmt.Errorf("running desktop command as user: %w", err)
 	}
@@ -911,7 +1104,7 @@ func (r *DesktopUsersProcessesRunner) spawnForUser(ctx context.Context, uid stri
 
 	span.AddEvent("command_started")
 
-	r.waitOnProcessAsync(uid, cmd.Process)
+	r.waitOnProcessAsync(key, cmd.Process)
 
 	client := client.New(r.userServerAuthToken, socketPath)
 
@@ -929,7 +1122,7 @@ func (r *DesktopUsersProcessesRunner) spawnForUser(ctx context.Context, uid stri
 		observability.SetError(span, fmt.Errorf("pinging user desktop server after startup: pid %d: %w", cmd.Process.Pid, err))
 
 		// unregister proc from desktop server so server will not respond to its requests
-		r.runnerServer.DeRegisterClient(uid)
+		r.runnerServer.DeRegisterClient(key)
 
 		// Try to kill the process. It may already be gone, in which case Process.Kill() will return an error --
 		// we can ignore those.
@@ -942,13 +1135,14 @@ func (r *DesktopUsersProcessesRunner) spawnForUser(ctx context.Context, uid stri
 
 	r.slogger.Log(ctx, slog.LevelDebug,
 		"desktop process started",
-		"uid", uid,
+		"uid", session.Uid,
+		"session_id", session.Id,
 		"pid", cmd.Process.Pid,
 	)
 
 	span.AddEvent("desktop_started")
 
-	if err := r.addProcessTrackingRecordForUser(uid, socketPath, cmd.Process); err != nil {
+	if err := r.addProcessTrackingRecordForSession(session, socketPath, cmd.Process); err != nil {
 		observability.SetError(span, fmt.Errorf("adding process to internal tracking state: %w", err))
 		return fmt.Errorf("adding process to internal tracking state: %w", err)
 	}
@@ -956,8 +1150,8 @@ func (r *DesktopUsersProcessesRunner) spawnForUser(ctx context.Context, uid stri
 	return nil
 }
 
-// addProcessTrackingRecordForUser adds process information to the internal tracking state
-func (r *DesktopUsersProcessesRunner) addProcessTrackingRecordForUser(uid string, socketPath string, osProcess *os.Process) error {
+// addProcessTrackingRecordForSession adds process information to the internal tracking state
+func (r *DesktopUsersProcessesRunner) addProcessTrackingRecordForSession(session consoleuser.Session, socketPath string, osProcess *os.Process) error {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
 	defer cancel()
 
@@ -974,9 +1168,10 @@ func (r *DesktopUsersProcessesRunner) addProcessTrackingRecordForUser(uid string
 	r.uidProcsLock.Lock()
 	defer r.uidProcsLock.Unlock()
 
-	r.uidProcs[uid] = processRecord{
+	r.uidProcs[processKey(session)] = processRecord{
 		Process:    osProcess,
 		StartTime:  time.Now().UTC(),
+		Session:    session,
 		path:       path,
 		socketPath: socketPath,
 	}
@@ -988,16 +1183,16 @@ func (r *DesktopUsersProcessesRunner) addProcessTrackingRecordForUser(uid string
 // The go routine will decrement DesktopUserProcessRunner.procsWg when it exits. This is necessary because if
 // the process dies and we do not wait for it, it will live as a zombie and not get cleaned up by the parent.
 // The wait group is needed to prevent races.
-func (r *DesktopUsersProcessesRunner) waitOnProcessAsync(uid string, proc *os.Process) {
+func (r *DesktopUsersProcessesRunner) waitOnProcessAsync(key string, proc *os.Process) {
 	r.procsWg.Add(1)
-	gowrapper.Go(context.TODO(), r.slogger.With("uid", uid, "pid", proc.Pid), func() {
+	gowrapper.Go(context.TODO(), r.slogger.With("process_key", key, "pid", proc.Pid), func() {
 		defer r.procsWg.Done()
 		// waiting here gives the parent a chance to clean up
 		state, err := proc.Wait()
 		if err != nil {
 			r.slogger.Log(context.TODO(), slog.LevelError,
 				"desktop process died",
-				"uid", uid,
+				"process_key", key,
 				"pid", proc.Pid,
 				"err", err,
 				"state", state,
@@ -1006,12 +1201,12 @@ func (r *DesktopUsersProcessesRunner) waitOnProcessAsync(uid string, proc *os.Pr
 	})
 }
 
-func (r *DesktopUsersProcessesRunner) userHasDesktopProcess(uid string) bool {
+func (r *DesktopUsersProcessesRunner) userHasDesktopProcess(key string) bool {
 	r.uidProcsLock.Lock()
 	defer r.uidProcsLock.Unlock()
 
 	// have no record of process
-	proc, ok := r.uidProcs[uid]
+	proc, ok := r.uidProcs[key]
 	if !ok {
 		return false
 	}
@@ -1022,7 +1217,7 @@ func (r *DesktopUsersProcessesRunner) userHasDesktopProcess(uid string) bool {
 			"found existing desktop process dead for console user",
 			"pid", proc.Process.Pid,
 			"process_path", proc.path,
-			"uid", uid,
+			"process_key", key,
 		)
 
 		return false
@@ -1031,7 +1226,7 @@ func (r *DesktopUsersProcessesRunner) userHasDesktopProcess(uid string) bool {
 	if _, err := os.Stat(proc.socketPath); err != nil {
 		r.slogger.Log(context.TODO(), slog.LevelDebug,
 			"error stating desktop process socket path",
-			"uid", uid,
+			"process_key", key,
 			"socket_path", proc.socketPath,
 			"err", err,
 		)
@@ -1039,19 +1234,19 @@ func (r *DesktopUsersProcessesRunner) userHasDesktopProcess(uid string) bool {
 		if err := proc.Process.Kill(); err != nil {
 			r.slogger.Log(context.TODO(), slog.LevelError,
 				"error killing desktop process after socket path does not exist",
-				"uid", uid,
+				"process_key", key,
 				"pid", proc.Process.Pid,
 				"err", err,
 			)
 		}
 
-		delete(r.uidProcs, uid)
+		delete(r.uidProcs, key)
 
 		return false
 	}
 
 	proc.LastHealthCheck = time.Now().UTC()
-	r.uidProcs[uid] = proc
+	r.uidProcs[key] = proc
 
 	// have running process
 	return true
@@ -1090,13 +1285,15 @@ func (r *DesktopUsersProcessesRunner) processExists(processRecord processRecord)
 
 // setupSocketPath returns standard pipe path for windows.
 // On posix systems, it creates a directory and changes owner to the user,
-// deletes any existing desktop sockets in the directory,
-// then provides a path to the socket in that folder.
-func (r *DesktopUsersProcessesRunner) setupSocketPath(uid string) (string, error) {
+// deletes any existing desktop sockets for the session in the directory,
+// then provides a path to the socket in that folder. The directory is shared
+// by all of the user's sessions.
+func (r *DesktopUsersProcessesRunner) setupSocketPath(session consoleuser.Session) (string, error) {
 	if runtime.GOOS == "windows" {
 		return fmt.Sprintf(`\\.\pipe\kolide_desktop_%s`, ulid.New()), nil
 	}
 
+	uid := session.Uid
 	userFolderPath := filepath.Join(r.usersFilesRoot, fmt.Sprintf("desktop_%s", uid))
 	if err := os.MkdirAll(userFolderPath, 0700); err != nil {
 		return "", fmt.Errorf("creating user folder: %w", err)
@@ -1111,17 +1308,27 @@ func (r *DesktopUsersProcessesRunner) setupSocketPath(uid string) (string, error
 		return "", fmt.Errorf("chowning user folder: %w", err)
 	}
 
-	if err := removeFilesWithPrefix(userFolderPath, nonWindowsDesktopSocketPrefix); err != nil {
+	// Only remove this session's sockets -- the user's other sessions may still be using theirs
+	socketPrefix := nonWindowsDesktopSocketPrefix
+	if session.Id != "" {
+		socketPrefix = fmt.Sprintf("%s_%s_", nonWindowsDesktopSocketPrefix, session.Id)
+	}
+	if err := removeFilesWithPrefix(userFolderPath, socketPrefix); err != nil {
 		r.slogger.Log(context.TODO(), slog.LevelInfo,
 			"removing existing desktop sockets for user",
 			"uid", uid,
+			"session_id", session.Id,
 			"err", err,
 		)
 	}
 
 	// using random 4 digit number instead of ulid to keep name short so we don't
 	// exceed char limit
-	path := filepath.Join(userFolderPath, fmt.Sprintf("%s_%d", nonWindowsDesktopSocketPrefix, rand.Intn(10000)))
+	socketName := fmt.Sprintf("%s_%d", nonWindowsDesktopSocketPrefix, rand.Intn(10000))
+	if session.Id != "" {
+		socketName = fmt.Sprintf("%s%d", socketPrefix, rand.Intn(10000))
+	}
+	path := filepath.Join(userFolderPath, socketName)
 	const maxSocketLength = 103
 	if len(path) > maxSocketLength {
 		return "", fmt.Errorf("socket path %s (length %d) is too long, max is %d", path, len(path), maxSocketLength)
@@ -1130,6 +1337,78 @@ func (r *DesktopUsersProcessesRunner) setupSocketPath(uid string) (string, error
 	return path, nil
 }
 
+// nativeMessagingClientKey is the key that the native messaging host for the given user
+// is registered under with the runner server; it is registered separately from the
+// user's desktop process so that each can be deregistered independently.
+func nativeMessagingClientKey(uid string) string {
+	return fmt.Sprintf("nativemessaging_%s", uid)
+}
+
+// writeNativeMessagingConnection writes the runner server URL and a fresh auth token
+// to the user's desktop folder, where the native messaging host (running as the user)
+// reads them in order to forward browser extension requests to root launcher. There is
+// no per-user folder on Windows, so this is not supported there yet -- launcher does not
+// register the native messaging host on Windows.
+func (r *DesktopUsersProcessesRunner) writeNativeMessagingConnection(uid string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+
+	uidInt, err := strconv.Atoi(uid)
+	if err != nil {
+		return fmt.Errorf("converting uid to int: %w", err)
+	}
+
+	connBytes, err := json.Marshal(nativemessaging.RunnerConnection{
+		URL:       r.runnerServer.Url(),
+		AuthToken: r.runnerServer.RegisterClient(nativeMessagingClientKey(uid)),
+	})
+	if err != nil {
+		return fmt.Errorf("marshalling connection: %w", err)
+	}
+
+	userFolderPath := filepath.Join(r.usersFilesRoot, fmt.Sprintf("desktop_%s", uid))
+	return writeUserOwnedFile(userFolderPath, nativemessaging.RunnerConnectionFilename, connBytes, uidInt)
+}
+
+// writeUserOwnedFile writes data to name in dir, owned by uid. The user owns dir, and so may have
+// planted anything at name -- we never open that path, instead writing to a new file that we then
+// rename over it, so that we can't be tricked into writing to or chowning a file a symlink points to.
+func writeUserOwnedFile(dir string, name string, data []byte, uid int) error {
+	f, err := os.CreateTemp(dir, name+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("creating temp file: %w", err)
+	}
+	tmpPath := f.Name()
+	defer os.Remove(tmpPath) // no-op once renamed
+
+	if err := f.Chown(uid, -1); err != nil {
+		f.Close()
+		return fmt.Errorf("chowning temp file: %w", err)
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("writing temp file: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("closing temp file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, filepath.Join(dir, name)); err != nil {
+		return fmt.Errorf("renaming temp file: %w", err)
+	}
+
+	return nil
+}
+
+// SetNativeMessageHandler sets the handler for browser extension requests forwarded
+// by the native messaging host.
+func (r *DesktopUsersProcessesRunner) SetNativeMessageHandler(handler runnerserver.NativeMessageHandler) {
+	r.runnerServer.SetNativeMessageHandler(handler)
+}
+
 // menuPath returns the path to the menu file
 func (r *DesktopUsersProcessesRunner) menuPath() string {
 	return filepath.Join(r.usersFilesRoot, "menu.json")
@@ -1159,8 +1438,9 @@ func (r *DesktopUsersProcessesRunner) writeLocalizationFile() error {
 	return nil
 }
 
-// desktopCommand invokes the launcher desktop executable with the appropriate env vars
-func (r *DesktopUsersProcessesRunner) desktopCommand(uid, socketPath, menuPath string) (*allowedcmd.TracedCmd, error) {
+// desktopCommand invokes the launcher desktop executable with the appropriate env vars. key is the
+// key the process is tracked under (see processKey).
+func (r *DesktopUsersProcessesRunner) desktopCommand(key, socketPath, menuPath string) (*allowedcmd.TracedCmd, error) {
 	cmd, err := allowedcmd.Launcher.Cmd(context.TODO(), "desktop")
 	if err != nil {
 		return nil, fmt.Errorf("creating launcher desktop command: %w", err)
@@ -1190,8 +1470,10 @@ func (r *DesktopUsersProcessesRunner) desktopCommand(uid, socketPath, menuPath s
 		fmt.Sprintf("LOCALIZATION_PATH=%s", r.localizationPath()),
 		fmt.Sprintf("PPID=%d", os.Getpid()),
 		fmt.Sprintf("RUNNER_SERVER_URL=%s", r.runnerServer.Url()),
-		fmt.Sprintf("RUNNER_SERVER_AUTH_TOKEN=%s", r.runnerServer.RegisterClient(uid)),
+		fmt.Sprintf("RUNNER_SERVER_AUTH_TOKEN=%s", r.runnerServer.RegisterClient(key)),
 		fmt.Sprintf("DEBUG=%v", r.knapsack.Debug()),
+		// the identifier names the polkit action used for presence detection on linux
+		fmt.Sprintf("IDENTIFIER=%s", r.knapsack.Identifier()),
 		// needed for windows to find various allowed commands
 		fmt.Sprintf("WINDIR=%s", os.Getenv("WINDIR")),
 		// pass the desktop enabled flag so if it's already enabled, we show desktop immeadiately
@@ -1213,7 +1495,7 @@ func (r *DesktopUsersProcessesRunner) desktopCommand(uid, socketPath, menuPath s
 	}
 
 	gowrapper.Go(context.TODO(), r.slogger, func() {
-		r.processLogs(uid, stdErr, stdOut)
+		r.processLogs(key, stdErr, stdOut)
 	})
 
 	return cmd, nil
@@ -1221,11 +1503,11 @@ func (r *DesktopUsersProcessesRunner) desktopCommand(uid, socketPath, menuPath s
 
 // processLogs scans logs from the desktop process stdout/stderr, logs them,
 // and examines them to see if any action should be taken in response.
-func (r *DesktopUsersProcessesRunner) processLogs(uid string, stdErr io.ReadCloser, stdOut io.ReadCloser) {
+func (r *DesktopUsersProcessesRunner) processLogs(key string, stdErr io.ReadCloser, stdOut io.ReadCloser) {
 	combined := io.MultiReader(stdErr, stdOut)
 	scanner := bufio.NewScanner(combined)
 
-	slogger := r.slogger.With("uid", uid, "subprocess", "desktop")
+	slogger := r.slogger.With("process_key", key, "subprocess", "desktop")
 
 	for scanner.Scan() {
 		logLine := scanner.Text()
@@ -1261,18 +1543,18 @@ func (r *DesktopUsersProcessesRunner) processLogs(uid string, stdErr io.ReadClos
 		r.slogger.Log(context.TODO(), slog.LevelInfo,
 			"noticed systray error -- shutting down and restarting desktop processes",
 			"systray_log", logLine,
-			"uid", uid,
+			"process_key", key,
 		)
 
 		// We want to perform some retries if we can't kill the process -- we aren't likely to get
 		// another log indicating that systray needs to restart, so we really want to fix this now.
 		// The call to `Shutdown` has a 30-second timeout, so we have a 35-second retry interval.
 		if err := backoff.WaitFor(func() error {
-			if err := r.killDesktopProcess(context.Background(), uid); err != nil {
+			if err := r.killDesktopProcess(context.Background(), key); err != nil {
 				r.slogger.Log(context.TODO(), slog.LevelWarn,
 					"could not kill desktop process, will retry",
 					"err", err,
-					"uid", uid,
+					"process_key", key,
 				)
 			}
 
@@ -1281,7 +1563,7 @@ func (r *DesktopUsersProcessesRunner) processLogs(uid string, stdErr io.ReadClos
 			r.slogger.Log(context.TODO(), slog.LevelError,
 				"could not kill desktop process after detecting systray initialization error",
 				"err", err,
-				"uid", uid,
+				"process_key", key,
 			)
 			// Keep processing logs, since we couldn't kill the process
 			continue
@@ -1293,7 +1575,7 @@ func (r *DesktopUsersProcessesRunner) processLogs(uid string, stdErr io.ReadClos
 
 	r.slogger.Log(context.TODO(), slog.LevelDebug,
 		"ending log processing for desktop process",
-		"uid", uid,
+		"process_key", key,
 	)
 }
 
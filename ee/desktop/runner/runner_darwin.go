@@ -11,13 +11,15 @@ import (
 	"time"
 
 	"github.com/kolide/launcher/v2/ee/allowedcmd"
+	"github.com/kolide/launcher/v2/ee/consoleuser"
 	"github.com/kolide/launcher/v2/ee/observability"
 	"github.com/kolide/launcher/v2/pkg/backoff"
 	"golang.org/x/sys/unix"
 )
 
 // For notifications to work, we must run in the user context with launchctl asuser.
-func (r *DesktopUsersProcessesRunner) runAsUser(ctx context.Context, uid string, cmd *allowedcmd.TracedCmd) error {
+func (r *DesktopUsersProcessesRunner) runAsUser(ctx context.Context, session consoleuser.Session, cmd *allowedcmd.TracedCmd) error {
+	uid := session.Uid
 	_, span := observability.StartSpan(ctx, "uid", uid)
 	defer span.End()
 
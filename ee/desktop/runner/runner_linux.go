@@ -17,6 +17,7 @@ import (
 	"syscall"
 
 	"github.com/kolide/launcher/v2/ee/allowedcmd"
+	"github.com/kolide/launcher/v2/ee/consoleuser"
 	"github.com/kolide/launcher/v2/ee/observability"
 	"github.com/shirou/gopsutil/v4/net"
 	"github.com/shirou/gopsutil/v4/process"
@@ -30,8 +31,9 @@ const (
 // Display takes the format host:displaynumber.screen
 var displayRegex = regexp.MustCompile(`^[a-z]*:\d+.?\d*$`)
 
-func (r *DesktopUsersProcessesRunner) runAsUser(ctx context.Context, uid string, cmd *allowedcmd.TracedCmd) error {
-	ctx, span := observability.StartSpan(ctx, "uid", uid)
+func (r *DesktopUsersProcessesRunner) runAsUser(ctx context.Context, session consoleuser.Session, cmd *allowedcmd.TracedCmd) error {
+	uid := session.Uid
+	ctx, span := observability.StartSpan(ctx, "uid", uid, "session_id", session.Id)
 	defer span.End()
 
 	currentUser, err := user.Current()
@@ -77,7 +79,7 @@ func (r *DesktopUsersProcessesRunner) runAsUser(ctx context.Context, uid string,
 	}
 
 	// Set any necessary environment variables on the command (like DISPLAY)
-	envVars := r.userEnvVars(ctx, uid, runningUser.Username)
+	envVars := r.userEnvVars(ctx, session, runningUser.Username)
 	for k, v := range envVars {
 		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
 	}
@@ -85,7 +87,8 @@ func (r *DesktopUsersProcessesRunner) runAsUser(ctx context.Context, uid string,
 	return cmd.Start()
 }
 
-func (r *DesktopUsersProcessesRunner) userEnvVars(ctx context.Context, uid string, username string) map[string]string {
+func (r *DesktopUsersProcessesRunner) userEnvVars(ctx context.Context, session consoleuser.Session, username string) map[string]string {
+	uid := session.Uid
 	envVars := make(map[string]string)
 
 	uidInt, err := strconv.ParseInt(uid, 10, 32)
@@ -97,6 +100,91 @@ func (r *DesktopUsersProcessesRunner) userEnvVars(ctx context.Context, uid strin
 		return envVars
 	}
 
+	// The most reliable source for a session's environment is a process already running in it --
+	// this matters when the user has more than one graphical session, each with its own display.
+	sessionEnv := make(map[string]string)
+	if session.Id != "" {
+		sessionEnv = r.sessionEnvironment(ctx, session, int32(uidInt))
+		envVars["XDG_SESSION_ID"] = session.Id
+		envVars["XDG_SESSION_TYPE"] = session.Type
+		for k, v := range r.sessionDisplayEnvVars(ctx, session, int32(uidInt), sessionEnv) {
+			envVars[k] = v
+		}
+	} else {
+		for k, v := range r.userDisplayEnvVars(ctx, uid, int32(uidInt)) {
+			envVars[k] = v
+		}
+	}
+
+	// For opening links with xdg-open, we need HOME set so that xdg-open can function correctly.
+	envVars["HOME"] = fmt.Sprintf("/home/%s", username)
+
+	// For opening links with xdg-open, we need XDG_DATA_DIRS so that xdg-open can find the mimetype configuration
+	// files to figure out what application to launch.
+
+	// We take the default value according to https://specifications.freedesktop.org/basedir-spec/basedir-spec-latest.html,
+	// but also include the snapd directory due to an issue on Ubuntu 22.04 where the default
+	// /usr/share/applications/mimeinfo.cache does not contain any applications installed via snap.
+	xdgDataDirs := "/usr/local/share/:/usr/share/:/var/lib/snapd/desktop"
+	// XDG_DATA_DIRS is different on NixOS -- handle it separately.
+	if allowedcmd.IsNixOS() {
+		xdgDataDirs = nixXdgDataDirs(username)
+	}
+	envVars["XDG_DATA_DIRS"] = xdgDataDirs
+	envVars["XDG_RUNTIME_DIR"] = getXdgRuntimeDir(uid)
+
+	// We need xauthority set in order to launch the browser on Ubuntu 23.04
+	if xauthorityLocation, ok := sessionEnv["XAUTHORITY"]; ok {
+		envVars["XAUTHORITY"] = xauthorityLocation
+	} else if xauthorityLocation := r.getXauthority(ctx, uid, username); xauthorityLocation != "" {
+		envVars["XAUTHORITY"] = xauthorityLocation
+	}
+
+	// We need the session bus for notifications and the secret service
+	if busAddress, ok := sessionEnv["DBUS_SESSION_BUS_ADDRESS"]; ok {
+		envVars["DBUS_SESSION_BUS_ADDRESS"] = busAddress
+	} else if busAddress := defaultSessionBusAddress(uid); busAddress != "" {
+		envVars["DBUS_SESSION_BUS_ADDRESS"] = busAddress
+	}
+
+	return envVars
+}
+
+// sessionDisplayEnvVars returns DISPLAY and WAYLAND_DISPLAY for the given logind session.
+func (r *DesktopUsersProcessesRunner) sessionDisplayEnvVars(ctx context.Context, session consoleuser.Session, uid int32, sessionEnv map[string]string) map[string]string {
+	envVars := make(map[string]string)
+
+	switch session.Type {
+	case "x11":
+		switch {
+		case sessionEnv["DISPLAY"] != "":
+			envVars["DISPLAY"] = sessionEnv["DISPLAY"]
+		case session.Display != "":
+			envVars["DISPLAY"] = session.Display
+		default:
+			envVars["DISPLAY"] = r.displayFromX11(ctx, session.Id, uid)
+		}
+	case "wayland":
+		if display := sessionEnv["DISPLAY"]; display != "" {
+			envVars["DISPLAY"] = display
+		} else {
+			envVars["DISPLAY"] = r.displayFromDisplayServerProcess(ctx, uid)
+		}
+		if waylandDisplay := sessionEnv["WAYLAND_DISPLAY"]; waylandDisplay != "" {
+			envVars["WAYLAND_DISPLAY"] = waylandDisplay
+		} else {
+			envVars["WAYLAND_DISPLAY"] = r.getWaylandDisplay(ctx, session.Uid)
+		}
+	}
+
+	return envVars
+}
+
+// userDisplayEnvVars returns DISPLAY and WAYLAND_DISPLAY for the first of the user's sessions
+// that is graphical.
+func (r *DesktopUsersProcessesRunner) userDisplayEnvVars(ctx context.Context, uid string, uidInt int32) map[string]string {
+	envVars := make(map[string]string)
+
 	// Get the user's session so we can get their display (needed for opening notification action URLs in browser)
 	cmd, err := allowedcmd.Loginctl.Cmd(ctx, "show-user", uid, "--value", "--property=Sessions")
 	if err != nil {
@@ -146,39 +234,95 @@ func (r *DesktopUsersProcessesRunner) userEnvVars(ctx context.Context, uid strin
 
 		sessionType := strings.Trim(string(typeOutput), "\n")
 		if sessionType == "x11" {
-			envVars["DISPLAY"] = r.displayFromX11(ctx, session, int32(uidInt))
+			envVars["DISPLAY"] = r.displayFromX11(ctx, session, uidInt)
 			break
 		} else if sessionType == "wayland" {
-			envVars["DISPLAY"] = r.displayFromDisplayServerProcess(ctx, int32(uidInt))
+			envVars["DISPLAY"] = r.displayFromDisplayServerProcess(ctx, uidInt)
 			envVars["WAYLAND_DISPLAY"] = r.getWaylandDisplay(ctx, uid)
 
 			break
 		}
 	}
 
-	// For opening links with xdg-open, we need HOME set so that xdg-open can function correctly.
-	envVars["HOME"] = fmt.Sprintf("/home/%s", username)
+	return envVars
+}
 
-	// For opening links with xdg-open, we need XDG_DATA_DIRS so that xdg-open can find the mimetype configuration
-	// files to figure out what application to launch.
+// sessionEnvVarNames are the environment variables we take from processes already running in a session
+var sessionEnvVarNames = []string{"DISPLAY", "WAYLAND_DISPLAY", "DBUS_SESSION_BUS_ADDRESS", "XAUTHORITY"}
 
-	// We take the default value according to https://specifications.freedesktop.org/basedir-spec/basedir-spec-latest.html,
-	// but also include the snapd directory due to an issue on Ubuntu 22.04 where the default
-	// /usr/share/applications/mimeinfo.cache does not contain any applications installed via snap.
-	xdgDataDirs := "/usr/local/share/:/usr/share/:/var/lib/snapd/desktop"
-	// XDG_DATA_DIRS is different on NixOS -- handle it separately.
-	if allowedcmd.IsNixOS() {
-		xdgDataDirs = nixXdgDataDirs(username)
+// sessionEnvironment looks for a process owned by the user that is running in the given logind
+// session (pam_systemd sets XDG_SESSION_ID for everything in the session) and has a display,
+// and returns its display and session bus environment variables.
+func (r *DesktopUsersProcessesRunner) sessionEnvironment(ctx context.Context, session consoleuser.Session, uid int32) map[string]string {
+	processes, err := process.ProcessesWithContext(ctx)
+	if err != nil {
+		r.slogger.Log(ctx, slog.LevelDebug,
+			"could not query processes to find session environment",
+			"err", err,
+		)
+		return map[string]string{}
 	}
-	envVars["XDG_DATA_DIRS"] = xdgDataDirs
-	envVars["XDG_RUNTIME_DIR"] = getXdgRuntimeDir(uid)
 
-	// We need xauthority set in order to launch the browser on Ubuntu 23.04
-	if xauthorityLocation := r.getXauthority(ctx, uid, username); xauthorityLocation != "" {
-		envVars["XAUTHORITY"] = xauthorityLocation
+	for _, p := range processes {
+		uids, err := p.UidsWithContext(ctx)
+		if err != nil || len(uids) == 0 || uids[0] != uint32(uid) {
+			continue
+		}
+
+		environ, err := p.EnvironWithContext(ctx)
+		if err != nil {
+			continue
+		}
+
+		if envVars, ok := sessionEnvVarsFromEnviron(environ, session.Id); ok {
+			return envVars
+		}
 	}
 
-	return envVars
+	r.slogger.Log(ctx, slog.LevelDebug,
+		"could not find process in session to take environment from",
+		"uid", session.Uid,
+		"session_id", session.Id,
+	)
+	return map[string]string{}
+}
+
+// sessionEnvVarsFromEnviron returns the session environment variables from environ, if environ
+// belongs to a process in the given session that is attached to a display.
+func sessionEnvVarsFromEnviron(environ []string, sessionId string) (map[string]string, bool) {
+	env := make(map[string]string)
+	for _, kv := range environ {
+		k, v, found := strings.Cut(kv, "=")
+		if !found {
+			continue
+		}
+		env[k] = v
+	}
+
+	if env["XDG_SESSION_ID"] != sessionId {
+		return nil, false
+	}
+	if env["DISPLAY"] == "" && env["WAYLAND_DISPLAY"] == "" {
+		return nil, false
+	}
+
+	envVars := make(map[string]string)
+	for _, name := range sessionEnvVarNames {
+		if v := env[name]; v != "" {
+			envVars[name] = v
+		}
+	}
+
+	return envVars, true
+}
+
+// defaultSessionBusAddress returns the address of the user's systemd-managed session bus, if it exists.
+func defaultSessionBusAddress(uid string) string {
+	busPath := filepath.Join(getXdgRuntimeDir(uid), "bus")
+	if info, err := os.Stat(busPath); err != nil || info.Mode().Type() != fs.ModeSocket {
+		return ""
+	}
+	return fmt.Sprintf("unix:path=%s", busPath)
 }
 
 // These are the XDG data dirs on NixOS that live in the Nix store that we can glob for --
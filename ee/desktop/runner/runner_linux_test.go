@@ -3,6 +3,8 @@
 package runner
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -39,3 +41,106 @@ func TestNixXdgDataDirs(t *testing.T) {
 	require.Contains(t, result, "/home/testuser/.local/state/nix/profile/share")
 	require.Contains(t, result, "/etc/profiles/per-user/testuser/share")
 }
+
+func Test_sessionEnvVarsFromEnviron(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		testCaseName    string
+		environ         []string
+		sessionId       string
+		expectedEnvVars map[string]string
+		expectedFound   bool
+	}{
+		{
+			testCaseName: "x11 session process",
+			environ: []string{
+				"HOME=/home/test",
+				"XDG_SESSION_ID=c2",
+				"DISPLAY=:10.0",
+				"DBUS_SESSION_BUS_ADDRESS=unix:path=/tmp/dbus-abc123",
+				"XAUTHORITY=/home/test/.Xauthority",
+			},
+			sessionId: "c2",
+			expectedEnvVars: map[string]string{
+				"DISPLAY":                  ":10.0",
+				"DBUS_SESSION_BUS_ADDRESS": "unix:path=/tmp/dbus-abc123",
+				"XAUTHORITY":               "/home/test/.Xauthority",
+			},
+			expectedFound: true,
+		},
+		{
+			testCaseName: "wayland session process",
+			environ: []string{
+				"XDG_SESSION_ID=3",
+				"WAYLAND_DISPLAY=wayland-1",
+				"DISPLAY=:1",
+				"DBUS_SESSION_BUS_ADDRESS=unix:path=/run/user/1000/bus",
+			},
+			sessionId: "3",
+			expectedEnvVars: map[string]string{
+				"DISPLAY":                  ":1",
+				"WAYLAND_DISPLAY":          "wayland-1",
+				"DBUS_SESSION_BUS_ADDRESS": "unix:path=/run/user/1000/bus",
+			},
+			expectedFound: true,
+		},
+		{
+			testCaseName:  "process in another session",
+			environ:       []string{"XDG_SESSION_ID=c1", "DISPLAY=:10.0"},
+			sessionId:     "c2",
+			expectedFound: false,
+		},
+		{
+			testCaseName:  "process in session without display",
+			environ:       []string{"XDG_SESSION_ID=c2", "DBUS_SESSION_BUS_ADDRESS=unix:path=/run/user/1000/bus"},
+			sessionId:     "c2",
+			expectedFound: false,
+		},
+		{
+			testCaseName:  "process outside of any session",
+			environ:       []string{"DISPLAY=:0"},
+			sessionId:     "c2",
+			expectedFound: false,
+		},
+	} {
+		t.Run(tt.testCaseName, func(t *testing.T) {
+			t.Parallel()
+
+			envVars, found := sessionEnvVarsFromEnviron(tt.environ, tt.sessionId)
+			require.Equal(t, tt.expectedFound, found)
+			if tt.expectedFound {
+				require.Equal(t, tt.expectedEnvVars, envVars)
+			}
+		})
+	}
+}
+
+func Test_writeUserOwnedFile_doesNotFollowSymlinks(t *testing.T) {
+	t.Parallel()
+
+	userDir := t.TempDir()
+	targetPath := filepath.Join(t.TempDir(), "sudoers")
+	require.NoError(t, os.WriteFile(targetPath, []byte("original"), 0600))
+	require.NoError(t, os.Symlink(targetPath, filepath.Join(userDir, "connection.json")))
+
+	require.NoError(t, writeUserOwnedFile(userDir, "connection.json", []byte("connection"), os.Getuid()))
+
+	// The symlink's target is untouched, and the symlink was replaced with our file
+	targetContents, err := os.ReadFile(targetPath)
+	require.NoError(t, err)
+	require.Equal(t, "original", string(targetContents))
+
+	info, err := os.Lstat(filepath.Join(userDir, "connection.json"))
+	require.NoError(t, err)
+	require.True(t, info.Mode().IsRegular())
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	contents, err := os.ReadFile(filepath.Join(userDir, "connection.json"))
+	require.NoError(t, err)
+	require.Equal(t, "connection", string(contents))
+
+	// No temp files are left behind
+	entries, err := os.ReadDir(userDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
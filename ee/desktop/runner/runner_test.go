@@ -100,8 +100,19 @@ func TestDesktopUserProcessRunner_Execute(t *testing.T) {
 				}
 				user, err := user.Current()
 				require.NoError(t, err)
-				r.uidProcs[user.Uid] = processRecord{
+				// on linux, desktop processes are tracked per session
+				session := consoleuser.Session{Uid: user.Uid}
+				if sessions, err := consoleuser.CurrentSessions(t.Context()); err == nil {
+					for _, s := range sessions {
+						if s.Uid == user.Uid {
+							session = s
+							break
+						}
+					}
+				}
+				r.uidProcs[processKey(session)] = processRecord{
 					Process: &os.Process{},
+					Session: session,
 					path:    "test",
 				}
 			},
@@ -146,6 +157,7 @@ func TestDesktopUserProcessRunner_Execute(t *testing.T) {
 			mockKnapsack.On("DesktopUpdateInterval").Return(time.Millisecond * 250)
 			mockKnapsack.On("DesktopMenuRefreshInterval").Return(time.Millisecond * 250)
 			mockKnapsack.On("DesktopGoMaxProcs").Return(2).Maybe()
+			mockKnapsack.On("Identifier").Return("kolide-k2").Maybe()
 			mockKnapsack.On("KolideServerURL").Return("somewhere-over-the-rainbow.example.com")
 
 			// if we're not in CI, always expect desktop enabled call
@@ -208,7 +220,8 @@ func TestDesktopUserProcessRunner_Execute(t *testing.T) {
 					require.Equal(t, 1, len(currentUids))
 					assert.Contains(t, r.uidProcs, currentUids[0], "process not found for expected user, logs: ", logBytes.String())
 				} else {
-					assert.Contains(t, r.uidProcs, user.Uid)
+					_, found := r.procForUid(user.Uid)
+					assert.True(t, found, "process not found for expected user, logs: ", logBytes.String())
 				}
 				assert.Len(t, r.uidProcs, 1)
 
@@ -391,6 +404,7 @@ func TestUpdate(t *testing.T) {
 			mockKnapsack.On("DesktopUpdateInterval").Return(time.Millisecond * 250)
 			mockKnapsack.On("DesktopMenuRefreshInterval").Return(time.Millisecond * 250)
 			mockKnapsack.On("DesktopGoMaxProcs").Return(2).Maybe()
+			mockKnapsack.On("Identifier").Return("kolide-k2").Maybe()
 			mockKnapsack.On("KolideServerURL").Return("somewhere-over-the-rainbow.example.com")
 			mockKnapsack.On("Slogger").Return(multislogger.NewNopLogger())
 			mockKnapsack.On("InModernStandby").Return(false)
@@ -430,6 +444,7 @@ func TestSendNotification_NoProcessesYet(t *testing.T) {
 	mockKnapsack.On("DesktopUpdateInterval").Return(time.Millisecond * 250)
 	mockKnapsack.On("DesktopMenuRefreshInterval").Return(time.Millisecond * 250)
 	mockKnapsack.On("DesktopGoMaxProcs").Return(2).Maybe()
+	mockKnapsack.On("Identifier").Return("kolide-k2").Maybe()
 	mockKnapsack.On("KolideServerURL").Return("somewhere-over-the-rainbow.example.com")
 	mockKnapsack.On("DesktopEnabled").Return(true)
 	mockKnapsack.On("Slogger").Return(multislogger.NewNopLogger())
@@ -459,7 +474,7 @@ func TestDesktopUsersProcessesRunner_setupSocketPath(t *testing.T) {
 	u, err := user.Current()
 	require.NoError(t, err)
 
-	socketPath, err := runner.setupSocketPath(u.Uid)
+	socketPath, err := runner.setupSocketPath(consoleuser.Session{Uid: u.Uid})
 	require.NoError(t, err)
 
 	// get dir of socket path
@@ -480,7 +495,7 @@ func TestDesktopUsersProcessesRunner_setupSocketPath(t *testing.T) {
 	require.Equal(t, 4, count)
 
 	// calling set up socket path should remove the fake socket files
-	_, err = runner.setupSocketPath(u.Uid)
+	_, err = runner.setupSocketPath(consoleuser.Session{Uid: u.Uid})
 	require.NoError(t, err)
 
 	// make sure all old sockets got deleted
@@ -497,6 +512,78 @@ func TestDesktopUsersProcessesRunner_setupSocketPath(t *testing.T) {
 	require.NoError(t, os.Remove(shouldNotBeDeletedFilePath))
 }
 
+func TestDesktopUsersProcessesRunner_setupSocketPath_MultipleSessions(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("windows sockets differently, test does not apply")
+	}
+
+	// Use a short directory rather than t.TempDir(), to keep the socket paths under the length limit
+	usersFilesRoot, err := os.MkdirTemp("", "sock")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(usersFilesRoot) })
+
+	runner := DesktopUsersProcessesRunner{
+		uidProcsLock:   &sync.Mutex{},
+		usersFilesRoot: usersFilesRoot,
+	}
+
+	u, err := user.Current()
+	require.NoError(t, err)
+
+	firstSession := consoleuser.Session{Uid: u.Uid, Id: "c1"}
+	secondSession := consoleuser.Session{Uid: u.Uid, Id: "c2"}
+
+	firstSocketPath, err := runner.setupSocketPath(firstSession)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(firstSocketPath, []byte{}, 0600))
+
+	// Both sessions should share the user's folder, but have their own sockets
+	secondSocketPath, err := runner.setupSocketPath(secondSession)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Dir(firstSocketPath), filepath.Dir(secondSocketPath))
+	require.NotEqual(t, firstSocketPath, secondSocketPath)
+	require.NoError(t, os.WriteFile(secondSocketPath, []byte{}, 0600))
+
+	// Setting up the second session's socket again should not remove the first session's socket
+	_, err = runner.setupSocketPath(secondSession)
+	require.NoError(t, err)
+	require.FileExists(t, firstSocketPath)
+	require.NoFileExists(t, secondSocketPath)
+}
+
+func Test_processKey(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "501", processKey(consoleuser.Session{Uid: "501"}))
+	require.Equal(t, "1000_c2", processKey(consoleuser.Session{Uid: "1000", Id: "c2"}))
+}
+
+func TestDesktopUsersProcessesRunner_procForUid(t *testing.T) {
+	t.Parallel()
+
+	firstSession := consoleuser.Session{Uid: "1000", Id: "3"}
+	runner := DesktopUsersProcessesRunner{
+		uidProcsLock: &sync.Mutex{},
+		uidProcs: map[string]processRecord{
+			processKey(firstSession): {Session: firstSession, socketPath: "first"},
+			"501":                    {Session: consoleuser.Session{Uid: "501"}, socketPath: "second"},
+		},
+	}
+
+	proc, found := runner.procForUid("1000")
+	require.True(t, found)
+	require.Equal(t, "first", proc.socketPath)
+
+	proc, found = runner.procForUid("501")
+	require.True(t, found)
+	require.Equal(t, "second", proc.socketPath)
+
+	_, found = runner.procForUid("1001")
+	require.False(t, found)
+}
+
 func countFilesWithPrefix(folderPath, prefix string) (int, error) {
 	count := 0
 
@@ -574,6 +661,7 @@ func Test_writeLocalizationFile(t *testing.T) {
 	mockKnapsack.On("DesktopUpdateInterval").Return(time.Millisecond * 250)
 	mockKnapsack.On("DesktopMenuRefreshInterval").Return(time.Millisecond * 250)
 	mockKnapsack.On("DesktopGoMaxProcs").Return(2).Maybe()
+	mockKnapsack.On("Identifier").Return("kolide-k2").Maybe()
 	mockKnapsack.On("KolideServerURL").Return("somewhere-over-the-rainbow.example.com")
 	mockKnapsack.On("Slogger").Return(multislogger.NewNopLogger())
 	mockKnapsack.On("InModernStandby").Return(false).Maybe()
@@ -613,6 +701,7 @@ func Test_Ping_writesLocalizationFile(t *testing.T) {
 	mockKnapsack.On("DesktopUpdateInterval").Return(time.Millisecond * 250)
 	mockKnapsack.On("DesktopMenuRefreshInterval").Return(time.Millisecond * 250)
 	mockKnapsack.On("DesktopGoMaxProcs").Return(2).Maybe()
+	mockKnapsack.On("Identifier").Return("kolide-k2").Maybe()
 	mockKnapsack.On("KolideServerURL").Return("somewhere-over-the-rainbow.example.com")
 	mockKnapsack.On("Slogger").Return(multislogger.NewNopLogger())
 	mockKnapsack.On("InModernStandby").Return(false)
@@ -15,7 +15,8 @@ import (
 	"github.com/kolide/systray"
 )
 
-func (r *DesktopUsersProcessesRunner) runAsUser(ctx context.Context, uid string, cmd *allowedcmd.TracedCmd) error {
+func (r *DesktopUsersProcessesRunner) runAsUser(ctx context.Context, session consoleuser.Session, cmd *allowedcmd.TracedCmd) error {
+	uid := session.Uid
 	ctx, span := observability.StartSpan(ctx, "uid", uid)
 	defer span.End()
 
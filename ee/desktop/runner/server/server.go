@@ -13,6 +13,8 @@ import (
 	"time"
 
 	"github.com/kolide/kit/ulid"
+	"github.com/kolide/launcher/v2/ee/desktop/user/notify"
+	"github.com/kolide/launcher/v2/ee/nativemessaging"
 )
 
 // RunnerServer provides IPC for user desktop processes to communicate back to the root desktop runner.
@@ -25,12 +27,22 @@ type RunnerServer struct {
 	mutex                 sync.Mutex
 	accelerator           requestAcclerator
 	messenger             Messenger
+	nativeMessageHandler  NativeMessageHandler
+	remediationHandler    RemediationHandler
+	// respondedNotifications holds the IDs of notifications we've reported a response to, and when.
+	// Notifications are sent to every desktop process, so we may hear about each from several.
+	respondedNotifications map[string]time.Time
 }
 
 const (
 	HealthCheckEndpoint                = "/health"
 	MenuOpenedEndpoint                 = "/menuopened"
 	MessageEndpoint                    = "/message"
+	NativeMessageEndpoint              = "/nativemessage"
+	NotificationResponseEndpoint       = "/notificationresponse"
+	notificationResponseMethod         = "notification_response"
+	notificationResponseRetention      = 24 * time.Hour
+	RemediationEndpoint                = "/remediation"
 	controlRequestAccelerationInterval = 5 * time.Second
 	controlRequestAcclerationDuration  = 1 * time.Minute
 )
@@ -48,6 +60,41 @@ type Messenger interface {
 	SendMessage(method string, params any) error
 }
 
+// NativeMessageHandler handles requests from the browser extension, forwarded by the
+// native messaging host running as the user. The returned value is marshalled to JSON
+// and sent back as the response body.
+//
+//mockery:generate: true
+//mockery:filename: native_message_handler.go
+type NativeMessageHandler interface {
+	HandleNativeMessage(ctx context.Context, req nativemessaging.Request) (any, error)
+}
+
+// RemediationHandler runs the remediations declared by the menu, when requested by a desktop process.
+//
+//mockery:generate: true
+//mockery:filename: remediation_handler.go
+type RemediationHandler interface {
+	// StartRemediation begins running the remediation on behalf of the desktop process registered
+	// under clientKey, returning once it has started.
+	StartRemediation(ctx context.Context, clientKey string, remediationID string) error
+}
+
+var (
+	ErrUnknownRemediation    = errors.New("unknown remediation")
+	ErrRemediationInProgress = errors.New("remediation already in progress")
+)
+
+// RemediationRequest is sent by the desktop process once the user has confirmed a remediation.
+type RemediationRequest struct {
+	RemediationID string `json:"remediation_id"`
+}
+
+type contextKey string
+
+// clientKeyContextKey holds the key that the requesting desktop process was registered under
+const clientKeyContextKey contextKey = "client_key"
+
 func New(slogger *slog.Logger,
 	accelerator requestAcclerator,
 	messenger Messenger) (*RunnerServer, error) {
@@ -57,11 +104,12 @@ func New(slogger *slog.Logger,
 	}
 
 	rs := &RunnerServer{
-		listener:              listener,
-		slogger:               slogger,
-		desktopProcAuthTokens: make(map[string]string),
-		accelerator:           accelerator,
-		messenger:             messenger,
+		listener:               listener,
+		slogger:                slogger,
+		desktopProcAuthTokens:  make(map[string]string),
+		accelerator:            accelerator,
+		messenger:              messenger,
+		respondedNotifications: make(map[string]time.Time),
 	}
 
 	if rs.slogger == nil {
@@ -92,6 +140,9 @@ func New(slogger *slog.Logger,
 	})
 
 	mux.Handle(MessageEndpoint, http.HandlerFunc(rs.sendMessage))
+	mux.Handle(NativeMessageEndpoint, http.HandlerFunc(rs.handleNativeMessage))
+	mux.Handle(NotificationResponseEndpoint, http.HandlerFunc(rs.handleNotificationResponse))
+	mux.Handle(RemediationEndpoint, http.HandlerFunc(rs.handleRemediation))
 
 	rs.server = &http.Server{
 		Handler: rs.authMiddleware(mux),
@@ -131,6 +182,38 @@ func (ms *RunnerServer) DeRegisterClient(key string) {
 	}
 }
 
+// SetNativeMessageHandler sets the handler for requests forwarded by the native messaging host.
+// Until it is set, those requests are rejected as unavailable.
+func (ms *RunnerServer) SetNativeMessageHandler(handler NativeMessageHandler) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	ms.nativeMessageHandler = handler
+}
+
+func (ms *RunnerServer) getNativeMessageHandler() NativeMessageHandler {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	return ms.nativeMessageHandler
+}
+
+// SetRemediationHandler sets the handler for remediations requested by desktop processes.
+// Until it is set, those requests are rejected as unavailable.
+func (ms *RunnerServer) SetRemediationHandler(handler RemediationHandler) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	ms.remediationHandler = handler
+}
+
+func (ms *RunnerServer) getRemediationHandler() RemediationHandler {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	return ms.remediationHandler
+}
+
 func (ms *RunnerServer) Url() string {
 	return fmt.Sprintf("http://%s", ms.listener.Addr().String())
 }
@@ -147,7 +230,8 @@ func (ms *RunnerServer) authMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		if !ms.isAuthTokenValid(authHeader[1]) {
+		clientKey, ok := ms.clientKeyForAuthToken(authHeader[1])
+		if !ok {
 			ms.slogger.Log(r.Context(), slog.LevelDebug,
 				"invalid desktop auth token",
 			)
@@ -155,21 +239,23 @@ func (ms *RunnerServer) authMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientKeyContextKey, clientKey)))
 	})
 }
 
-func (ms *RunnerServer) isAuthTokenValid(authToken string) bool {
+// clientKeyForAuthToken returns the key that the client with the given auth token was registered under,
+// and false if the token is not valid.
+func (ms *RunnerServer) clientKeyForAuthToken(authToken string) (string, bool) {
 	ms.mutex.Lock()
 	defer ms.mutex.Unlock()
 
-	for _, v := range ms.desktopProcAuthTokens {
+	for k, v := range ms.desktopProcAuthTokens {
 		if v == authToken {
-			return true
+			return k, true
 		}
 	}
 
-	return false
+	return "", false
 }
 
 func (ms *RunnerServer) sendMessage(w http.ResponseWriter, r *http.Request) {
@@ -219,3 +305,217 @@ func (ms *RunnerServer) sendMessage(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusOK)
 }
+
+func (ms *RunnerServer) handleNativeMessage(w http.ResponseWriter, r *http.Request) {
+	if r.Body == nil {
+		ms.slogger.Log(r.Context(), slog.LevelError,
+			"no request body",
+		)
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	handler := ms.getNativeMessageHandler()
+	if handler == nil {
+		http.Error(w, "native message handler is not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req nativemessaging.Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		ms.slogger.Log(r.Context(), slog.LevelError,
+			"could not decode native message request",
+			"err", err,
+		)
+
+		http.Error(w, "could not decode request", http.StatusBadRequest)
+		return
+	}
+
+	// The native messaging host should have rejected these already, but we check again here
+	if !req.Type.Allowed() {
+		ms.slogger.Log(r.Context(), slog.LevelWarn,
+			"rejecting native message request with unsupported type",
+			"request_type", req.Type,
+		)
+
+		http.Error(w, fmt.Sprintf("%s: %q", nativemessaging.ErrUnsupportedRequestType, req.Type), http.StatusBadRequest)
+		return
+	}
+
+	result, err := handler.HandleNativeMessage(r.Context(), req)
+	if err != nil {
+		ms.slogger.Log(r.Context(), slog.LevelError,
+			"error handling native message request",
+			"request_type", req.Type,
+			"err", err,
+		)
+
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, nativemessaging.ErrUnsupportedRequestType):
+			status = http.StatusBadRequest
+		case errors.Is(err, nativemessaging.ErrExtensionNotAllowlisted):
+			status = http.StatusForbidden
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	resultBytes, err := json.Marshal(result)
+	if err != nil {
+		ms.slogger.Log(r.Context(), slog.LevelError,
+			"could not marshal native message response",
+			"request_type", req.Type,
+			"err", err,
+		)
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resultBytes)
+}
+
+// handleNotificationResponse reports the user's response to a notification to the control server.
+func (ms *RunnerServer) handleNotificationResponse(w http.ResponseWriter, r *http.Request) {
+	if r.Body == nil {
+		ms.slogger.Log(r.Context(), slog.LevelError,
+			"no request body",
+		)
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	var response notify.Response
+	if err := json.NewDecoder(r.Body).Decode(&response); err != nil {
+		ms.slogger.Log(r.Context(), slog.LevelError,
+			"could not decode notification response",
+			"err", err,
+		)
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if response.NotificationID == "" || !response.Type.IsValid() {
+		ms.slogger.Log(r.Context(), slog.LevelError,
+			"received invalid notification response",
+			"notification_id", response.NotificationID,
+			"response_type", response.Type,
+		)
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if !ms.claimNotificationResponse(response.NotificationID) {
+		ms.slogger.Log(r.Context(), slog.LevelDebug,
+			"already reported a response to notification, discarding",
+			"notification_id", response.NotificationID,
+			"response_type", response.Type,
+		)
+
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if err := ms.messenger.SendMessage(notificationResponseMethod, response); err != nil {
+		ms.slogger.Log(r.Context(), slog.LevelError,
+			"error sending notification response",
+			"notification_id", response.NotificationID,
+			"err", err,
+		)
+
+		ms.releaseNotificationResponse(response.NotificationID)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// claimNotificationResponse returns true if no response to the given notification has been
+// reported yet, in which case the caller should report it.
+func (ms *RunnerServer) claimNotificationResponse(notificationId string) bool {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	for id, respondedAt := range ms.respondedNotifications {
+		if time.Since(respondedAt) > notificationResponseRetention {
+			delete(ms.respondedNotifications, id)
+		}
+	}
+
+	if _, found := ms.respondedNotifications[notificationId]; found {
+		return false
+	}
+	ms.respondedNotifications[notificationId] = time.Now()
+	return true
+}
+
+// releaseNotificationResponse allows a response to the given notification to be reported again,
+// after we failed to report it.
+func (ms *RunnerServer) releaseNotificationResponse(notificationId string) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	delete(ms.respondedNotifications, notificationId)
+}
+
+// handleRemediation starts a remediation that the user has confirmed. It responds once the remediation
+// has started; the result is reported to the user and the control server by the handler.
+func (ms *RunnerServer) handleRemediation(w http.ResponseWriter, r *http.Request) {
+	if r.Body == nil {
+		ms.slogger.Log(r.Context(), slog.LevelError,
+			"no request body",
+		)
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	handler := ms.getRemediationHandler()
+	if handler == nil {
+		http.Error(w, "remediation handler is not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req RemediationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		ms.slogger.Log(r.Context(), slog.LevelError,
+			"could not decode remediation request",
+			"err", err,
+		)
+
+		http.Error(w, "could not decode request", http.StatusBadRequest)
+		return
+	}
+
+	clientKey, _ := r.Context().Value(clientKeyContextKey).(string)
+	if err := handler.StartRemediation(r.Context(), clientKey, req.RemediationID); err != nil {
+		ms.slogger.Log(r.Context(), slog.LevelError,
+			"could not start remediation",
+			"remediation_id", req.RemediationID,
+			"err", err,
+		)
+
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, ErrUnknownRemediation):
+			status = http.StatusNotFound
+		case errors.Is(err, ErrRemediationInProgress):
+			status = http.StatusConflict
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -4,12 +4,15 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"testing"
 	"time"
 
 	"github.com/kolide/launcher/v2/ee/agent/types/mocks"
 	servermocks "github.com/kolide/launcher/v2/ee/desktop/runner/server/mocks"
+	"github.com/kolide/launcher/v2/ee/desktop/user/notify"
+	"github.com/kolide/launcher/v2/ee/nativemessaging"
 	"github.com/kolide/launcher/v2/pkg/authedclient"
 	"github.com/kolide/launcher/v2/pkg/log/multislogger"
 	"github.com/stretchr/testify/mock"
@@ -96,6 +99,187 @@ func TestRootServer(t *testing.T) {
 	require.NoError(t, monitorServer.Shutdown(t.Context()))
 }
 
+func TestRootServer_NativeMessage(t *testing.T) {
+	t.Parallel()
+
+	monitorServer, err := New(multislogger.NewNopLogger(), mocks.NewKnapsack(t), servermocks.NewMessenger(t))
+	require.NoError(t, err)
+
+	go func() {
+		if err := monitorServer.Serve(); err != nil {
+			require.ErrorIs(t, err, http.ErrServerClosed)
+		}
+	}()
+
+	token := monitorServer.RegisterClient("nativemessaging_0")
+	client := authedclient.New(token, 1*time.Second)
+	healthRequest := []byte(`{"id":"1","type":"health","extension":"chrome-extension://test"}`)
+
+	// No handler set yet
+	response, err := client.Post(endpointUrl(monitorServer.Url(), NativeMessageEndpoint), "application/json", bytes.NewReader(healthRequest)) //nolint:noctx // We don't care about this in tests
+	require.NoError(t, err)
+	require.NoError(t, response.Body.Close())
+	require.Equal(t, http.StatusServiceUnavailable, response.StatusCode)
+
+	handler := servermocks.NewNativeMessageHandler(t)
+	monitorServer.SetNativeMessageHandler(handler)
+
+	response, err = client.Post(endpointUrl(monitorServer.Url(), NativeMessageEndpoint), "application/json", bytes.NewReader([]byte(`not json`))) //nolint:noctx // We don't care about this in tests
+	require.NoError(t, err)
+	require.NoError(t, response.Body.Close())
+	require.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	// Unsupported request types never reach the handler
+	response, err = client.Post(endpointUrl(monitorServer.Url(), NativeMessageEndpoint), "application/json", bytes.NewReader([]byte(`{"id":"2","type":"run_query"}`))) //nolint:noctx // We don't care about this in tests
+	require.NoError(t, err)
+	require.NoError(t, response.Body.Close())
+	require.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	handler.On("HandleNativeMessage", mock.Anything, nativemessaging.Request{
+		ID:        "1",
+		Type:      nativemessaging.RequestTypeHealth,
+		Extension: "chrome-extension://test",
+	}).Return(map[string]string{"status": "ok"}, nil).Once()
+	response, err = client.Post(endpointUrl(monitorServer.Url(), NativeMessageEndpoint), "application/json", bytes.NewReader(healthRequest)) //nolint:noctx // We don't care about this in tests
+	require.NoError(t, err)
+	body, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	require.NoError(t, response.Body.Close())
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	require.JSONEq(t, `{"status":"ok"}`, string(body))
+
+	handler.On("HandleNativeMessage", mock.Anything, mock.Anything).Return(nil, errors.New("some error")).Once()
+	response, err = client.Post(endpointUrl(monitorServer.Url(), NativeMessageEndpoint), "application/json", bytes.NewReader(healthRequest)) //nolint:noctx // We don't care about this in tests
+	require.NoError(t, err)
+	require.NoError(t, response.Body.Close())
+	require.Equal(t, http.StatusInternalServerError, response.StatusCode)
+
+	// Deregistered clients are unauthorized
+	monitorServer.DeRegisterClient("nativemessaging_0")
+	response, err = client.Post(endpointUrl(monitorServer.Url(), NativeMessageEndpoint), "application/json", bytes.NewReader(healthRequest)) //nolint:noctx // We don't care about this in tests
+	require.NoError(t, err)
+	require.NoError(t, response.Body.Close())
+	require.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+	client.CloseIdleConnections()
+	require.NoError(t, monitorServer.Shutdown(t.Context()))
+}
+
+func TestRootServer_NotificationResponse(t *testing.T) {
+	t.Parallel()
+
+	messenger := servermocks.NewMessenger(t)
+	monitorServer, err := New(multislogger.NewNopLogger(), mocks.NewKnapsack(t), messenger)
+	require.NoError(t, err)
+
+	go func() {
+		if err := monitorServer.Serve(); err != nil {
+			require.ErrorIs(t, err, http.ErrServerClosed)
+		}
+	}()
+
+	token := monitorServer.RegisterClient("0")
+	client := authedclient.New(token, 1*time.Second)
+	clickedResponse := []byte(`{"notification_id":"abc","type":"action_clicked","action_id":"remediate","responded_at":"2024-01-01T00:00:00Z"}`)
+
+	for _, invalidResponse := range []string{
+		`not json`,
+		`{"type":"dismissed"}`,
+		`{"notification_id":"abc","type":"ignored"}`,
+	} {
+		response, err := client.Post(endpointUrl(monitorServer.Url(), NotificationResponseEndpoint), "application/json", bytes.NewReader([]byte(invalidResponse))) //nolint:noctx // We don't care about this in tests
+		require.NoError(t, err)
+		require.NoError(t, response.Body.Close())
+		require.Equal(t, http.StatusBadRequest, response.StatusCode, invalidResponse)
+	}
+
+	messenger.On("SendMessage", notificationResponseMethod, notify.Response{
+		NotificationID: "abc",
+		Type:           notify.ResponseActionClicked,
+		ActionID:       "remediate",
+		RespondedAt:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}).Return(nil).Once()
+	response, err := client.Post(endpointUrl(monitorServer.Url(), NotificationResponseEndpoint), "application/json", bytes.NewReader(clickedResponse)) //nolint:noctx // We don't care about this in tests
+	require.NoError(t, err)
+	require.NoError(t, response.Body.Close())
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	// Other desktop processes responding to the same notification should not be reported
+	dismissedResponse := []byte(`{"notification_id":"abc","type":"dismissed","responded_at":"2024-01-01T00:00:00Z"}`)
+	response, err = client.Post(endpointUrl(monitorServer.Url(), NotificationResponseEndpoint), "application/json", bytes.NewReader(dismissedResponse)) //nolint:noctx // We don't care about this in tests
+	require.NoError(t, err)
+	require.NoError(t, response.Body.Close())
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	// A response we fail to report may be reported again
+	expiredResponse := []byte(`{"notification_id":"def","type":"expired","responded_at":"2024-01-01T00:00:00Z"}`)
+	messenger.On("SendMessage", notificationResponseMethod, mock.Anything).Return(errors.New("some error")).Once()
+	response, err = client.Post(endpointUrl(monitorServer.Url(), NotificationResponseEndpoint), "application/json", bytes.NewReader(expiredResponse)) //nolint:noctx // We don't care about this in tests
+	require.NoError(t, err)
+	require.NoError(t, response.Body.Close())
+	require.Equal(t, http.StatusInternalServerError, response.StatusCode)
+
+	messenger.On("SendMessage", notificationResponseMethod, mock.Anything).Return(nil).Once()
+	response, err = client.Post(endpointUrl(monitorServer.Url(), NotificationResponseEndpoint), "application/json", bytes.NewReader(expiredResponse)) //nolint:noctx // We don't care about this in tests
+	require.NoError(t, err)
+	require.NoError(t, response.Body.Close())
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	client.CloseIdleConnections()
+	require.NoError(t, monitorServer.Shutdown(t.Context()))
+}
+
+func TestRootServer_Remediation(t *testing.T) {
+	t.Parallel()
+
+	monitorServer, err := New(multislogger.NewNopLogger(), mocks.NewKnapsack(t), servermocks.NewMessenger(t))
+	require.NoError(t, err)
+
+	go func() {
+		if err := monitorServer.Serve(); err != nil {
+			require.ErrorIs(t, err, http.ErrServerClosed)
+		}
+	}()
+
+	token := monitorServer.RegisterClient("501_c2")
+	client := authedclient.New(token, 1*time.Second)
+	remediationRequest := []byte(`{"remediation_id":"enable_firewall"}`)
+
+	// No handler set yet
+	response, err := client.Post(endpointUrl(monitorServer.Url(), RemediationEndpoint), "application/json", bytes.NewReader(remediationRequest)) //nolint:noctx // We don't care about this in tests
+	require.NoError(t, err)
+	require.NoError(t, response.Body.Close())
+	require.Equal(t, http.StatusServiceUnavailable, response.StatusCode)
+
+	handler := servermocks.NewRemediationHandler(t)
+	monitorServer.SetRemediationHandler(handler)
+
+	response, err = client.Post(endpointUrl(monitorServer.Url(), RemediationEndpoint), "application/json", bytes.NewReader([]byte(`not json`))) //nolint:noctx // We don't care about this in tests
+	require.NoError(t, err)
+	require.NoError(t, response.Body.Close())
+	require.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	// The handler learns which desktop process made the request from its auth token
+	for _, tt := range []struct {
+		handlerErr     error
+		expectedStatus int
+	}{
+		{handlerErr: nil, expectedStatus: http.StatusAccepted},
+		{handlerErr: ErrUnknownRemediation, expectedStatus: http.StatusNotFound},
+		{handlerErr: ErrRemediationInProgress, expectedStatus: http.StatusConflict},
+		{handlerErr: errors.New("some error"), expectedStatus: http.StatusInternalServerError},
+	} {
+		handler.On("StartRemediation", mock.Anything, "501_c2", "enable_firewall").Return(tt.handlerErr).Once()
+		response, err = client.Post(endpointUrl(monitorServer.Url(), RemediationEndpoint), "application/json", bytes.NewReader(remediationRequest)) //nolint:noctx // We don't care about this in tests
+		require.NoError(t, err)
+		require.NoError(t, response.Body.Close())
+		require.Equal(t, tt.expectedStatus, response.StatusCode)
+	}
+
+	client.CloseIdleConnections()
+	require.NoError(t, monitorServer.Shutdown(t.Context()))
+}
+
 func endpointUrl(url, endpoint string) string {
 	return fmt.Sprintf("%s%s", url, endpoint)
 }
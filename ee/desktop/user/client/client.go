@@ -209,6 +209,93 @@ func (c *client) VerifySecureEnclaveKey(ctx context.Context, key *ecdsa.PublicKe
 	return false, fmt.Errorf("unexpected status code, cannot verify existence of key: %d", resp.StatusCode)
 }
 
+// ErrSecretNotFound is returned when the user has no secret stored under the requested key.
+var ErrSecretNotFound = errors.New("secret not found")
+
+// GetSecret returns the secret stored for the desktop process's user under key.
+func (c *client) GetSecret(ctx context.Context, key string) ([]byte, error) {
+	ctx, span := observability.StartSpan(ctx)
+	defer span.End()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, secretURL(key), http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("creating get secret request: %w", err)
+	}
+
+	resp, err := c.base.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("getting secret: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := secretStatusError(resp); err != nil {
+		return nil, err
+	}
+
+	var secretResp server.SecretResponse
+	if err := json.NewDecoder(resp.Body).Decode(&secretResp); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	return secretResp.Value, nil
+}
+
+// SetSecret stores value for the desktop process's user under key, replacing any existing secret.
+func (c *client) SetSecret(ctx context.Context, key string, value []byte) error {
+	ctx, span := observability.StartSpan(ctx)
+	defer span.End()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, secretURL(key), bytes.NewReader(value))
+	if err != nil {
+		return fmt.Errorf("creating set secret request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := c.base.Do(req)
+	if err != nil {
+		return fmt.Errorf("setting secret: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return secretStatusError(resp)
+}
+
+// DeleteSecret removes the secret stored for the desktop process's user under key.
+func (c *client) DeleteSecret(ctx context.Context, key string) error {
+	ctx, span := observability.StartSpan(ctx)
+	defer span.End()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, secretURL(key), http.NoBody)
+	if err != nil {
+		return fmt.Errorf("creating delete secret request: %w", err)
+	}
+
+	resp, err := c.base.Do(req)
+	if err != nil {
+		return fmt.Errorf("deleting secret: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return secretStatusError(resp)
+}
+
+func secretURL(key string) string {
+	return fmt.Sprintf("http://unix/secret?key=%s", url.QueryEscape(key))
+}
+
+func secretStatusError(resp *http.Response) error {
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		return ErrSecretNotFound
+	default:
+		// The server includes a short error message in the body
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+}
+
 func (c *client) Notify(n notify.Notification) error {
 	timeout := c.base.Timeout
 	if timeout == 0 {
@@ -219,9 +306,15 @@ func (c *client) Notify(n notify.Notification) error {
 	defer cancel()
 
 	notificationToSend := notify.Notification{
-		Title:     n.Title,
-		Body:      n.Body,
-		ActionUri: n.ActionUri,
+		Title:      n.Title,
+		Body:       n.Body,
+		ActionUri:  n.ActionUri,
+		ID:         n.ID,
+		ValidUntil: n.ValidUntil,
+		ExpiresAt:  n.ExpiresAt,
+		Actions:    n.Actions,
+		Priority:   n.Priority,
+		DedupKey:   n.DedupKey,
 	}
 	bodyBytes, err := json.Marshal(notificationToSend)
 	if err != nil {
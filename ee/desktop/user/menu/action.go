@@ -13,6 +13,7 @@ const (
 	OpenURL        actionType = "open-url"
 	Flare          actionType = "flare"
 	MessageControl actionType = "message-control"
+	Remediate      actionType = "remediate"
 )
 
 // Action encapsulates what action should be performed when a menu item is invoked
@@ -58,6 +59,12 @@ func (a *Action) UnmarshalJSON(data []byte) error {
 			return fmt.Errorf("failed to unmarshal ActionMessage: %w", err)
 		}
 		a.Performer = message
+	case Remediate:
+		remediate := actionRemediate{}
+		if err := json.Unmarshal(a.Action, &remediate); err != nil {
+			return fmt.Errorf("failed to unmarshal ActionRemediate: %w", err)
+		}
+		a.Performer = remediate
 	default:
 		// Silently ignore unrecognized actions because:
 		// 1. We don't have a logger reference here
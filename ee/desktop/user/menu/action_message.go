@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -21,71 +22,66 @@ type actionMessage struct {
 }
 
 func (a actionMessage) Perform(m *menu) {
-	runnerServerUrl := os.Getenv("RUNNER_SERVER_URL")
-	if runnerServerUrl == "" {
+	statusCode, err := postToRunnerServer(server.MessageEndpoint, a)
+	if err != nil {
 		m.slogger.Log(context.TODO(), slog.LevelError,
-			"runner server url not set",
+			"failed to perform message action",
+			"method", a.Method,
+			"params", a.Params,
+			"err", err,
 		)
+
 		return
 	}
 
-	runnerServerAuthToken := os.Getenv("RUNNER_SERVER_AUTH_TOKEN")
-	if runnerServerAuthToken == "" {
+	if statusCode != http.StatusOK {
 		m.slogger.Log(context.TODO(), slog.LevelError,
-			"runner server auth token not set",
+			"failed to perform message action",
+			"method", a.Method,
+			"params", a.Params,
+			"status_code", statusCode,
 		)
-		return
+	}
+}
+
+// postToRunnerServer posts the JSON-encoded body to the given endpoint of the root runner server,
+// returning the response status code.
+func postToRunnerServer(endpoint string, body any) (int, error) {
+	runnerServerUrl := os.Getenv("RUNNER_SERVER_URL")
+	if runnerServerUrl == "" {
+		return 0, errors.New("runner server url not set")
+	}
+
+	runnerServerAuthToken := os.Getenv("RUNNER_SERVER_AUTH_TOKEN")
+	if runnerServerAuthToken == "" {
+		return 0, errors.New("runner server auth token not set")
 	}
 
 	client := authedclient.New(runnerServerAuthToken, 2*time.Second)
-	runnerMessageUrl := fmt.Sprintf("%s%s", runnerServerUrl, server.MessageEndpoint)
+	runnerUrl := fmt.Sprintf("%s%s", runnerServerUrl, endpoint)
 
-	jsonBody, err := json.Marshal(a)
+	jsonBody, err := json.Marshal(body)
 	if err != nil {
-		m.slogger.Log(context.TODO(), slog.LevelError,
-			"failed to marshal message body",
-			"err", err,
-		)
-
-		return
+		return 0, fmt.Errorf("marshalling request body: %w", err)
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), client.Timeout)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, runnerMessageUrl, bytes.NewReader(jsonBody))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, runnerUrl, bytes.NewReader(jsonBody))
 	if err != nil {
-		m.slogger.Log(context.TODO(), slog.LevelError,
-			"failed to create request",
-			"err", err,
-		)
-
-		return
+		return 0, fmt.Errorf("creating request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
 	response, err := client.Do(req)
 	if err != nil {
-		m.slogger.Log(context.TODO(), slog.LevelError,
-			"failed to perform message action",
-			"method", a.Method,
-			"params", a.Params,
-			"err", err,
-		)
-
-		return
+		return 0, fmt.Errorf("performing request: %w", err)
 	}
 
 	if response.Body != nil {
 		defer response.Body.Close()
 	}
 
-	if response.StatusCode != http.StatusOK {
-		m.slogger.Log(context.TODO(), slog.LevelError,
-			"failed to perform message action",
-			"method", a.Method,
-			"params", a.Params,
-			"status_code", response.StatusCode,
-		)
-	}
+	return response.StatusCode, nil
 }
@@ -0,0 +1,91 @@
+package menu
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/kolide/launcher/v2/ee/desktop/runner/server"
+)
+
+const (
+	// remediationConfirmationTimeout is how long we wait for the user to confirm a remediation
+	remediationConfirmationTimeout = 2 * time.Minute
+	remediationConfirmLabel        = "Run"
+	remediationCancelLabel         = "Cancel"
+)
+
+// actionRemediate asks the user to confirm, then asks the root launcher to run a remediation
+// declared by the menu. Only the ID is sent; the root launcher looks up what to run in its own
+// copy of the menu, so the remediation must have been approved by the control server.
+type actionRemediate struct {
+	RemediationID string `json:"remediation_id"`
+}
+
+func (a actionRemediate) Perform(m *menu) {
+	remediation, found := m.getMenuData().Remediation(a.RemediationID)
+	if !found {
+		m.slogger.Log(context.TODO(), slog.LevelError,
+			"menu does not declare remediation",
+			"remediation_id", a.RemediationID,
+		)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), remediationConfirmationTimeout)
+	defer cancel()
+
+	title, prompt := remediation.confirmationText()
+	confirmed, err := confirmRemediation(ctx, title, prompt)
+	if err != nil {
+		m.slogger.Log(context.TODO(), slog.LevelError,
+			"could not confirm remediation with user",
+			"remediation_id", a.RemediationID,
+			"err", err,
+		)
+		return
+	}
+
+	if !confirmed {
+		m.slogger.Log(context.TODO(), slog.LevelInfo,
+			"user declined remediation",
+			"remediation_id", a.RemediationID,
+		)
+		return
+	}
+
+	statusCode, err := postToRunnerServer(server.RemediationEndpoint, server.RemediationRequest{RemediationID: a.RemediationID})
+	if err != nil {
+		m.slogger.Log(context.TODO(), slog.LevelError,
+			"failed to request remediation",
+			"remediation_id", a.RemediationID,
+			"err", err,
+		)
+		return
+	}
+
+	if statusCode != http.StatusAccepted {
+		m.slogger.Log(context.TODO(), slog.LevelError,
+			"failed to request remediation",
+			"remediation_id", a.RemediationID,
+			"status_code", statusCode,
+		)
+	}
+}
+
+// confirmationText returns the title and prompt to show when asking the user to confirm the remediation.
+func (r MenuRemediation) confirmationText() (string, string) {
+	title := r.Title
+	if title == "" {
+		title = "Kolide"
+	}
+
+	prompt := r.ConfirmationPrompt
+	if prompt == "" {
+		prompt = fmt.Sprintf("Kolide will run %q on this device. Do you want to continue?", title)
+	}
+
+	return title, prompt
+}
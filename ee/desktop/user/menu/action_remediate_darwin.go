@@ -0,0 +1,37 @@
+//go:build darwin
+
+package menu
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kolide/launcher/v2/ee/allowedcmd"
+)
+
+// confirmRemediation displays a dialog asking the user whether to run the remediation. The title and
+// prompt are passed as arguments, rather than interpolated into the script, so they need no escaping.
+func confirmRemediation(ctx context.Context, title, prompt string) (bool, error) {
+	cmd, err := allowedcmd.Osascript.Cmd(ctx,
+		"-e", "on run argv",
+		"-e", fmt.Sprintf(`display dialog (item 2 of argv) with title (item 1 of argv) buttons {"%s", "%s"} default button "%s" cancel button "%s" with icon caution`,
+			remediationCancelLabel, remediationConfirmLabel, remediationConfirmLabel, remediationCancelLabel),
+		"-e", "end run",
+		title, prompt,
+	)
+	if err != nil {
+		return false, fmt.Errorf("creating command: %w", err)
+	}
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		// -128 is the error AppleScript raises when the user clicks the cancel button
+		if strings.Contains(string(out), "(-128)") {
+			return false, nil
+		}
+		return false, fmt.Errorf("displaying dialog: %w: %s", err, string(out))
+	}
+
+	return strings.Contains(string(out), "button returned:"+remediationConfirmLabel), nil
+}
@@ -0,0 +1,49 @@
+//go:build linux
+
+package menu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+
+	"github.com/kolide/launcher/v2/ee/allowedcmd"
+)
+
+// confirmRemediation displays a dialog asking the user whether to run the remediation, using
+// zenity if it's available and kdialog otherwise.
+func confirmRemediation(ctx context.Context, title, prompt string) (bool, error) {
+	cmd, zenityErr := allowedcmd.Zenity.Cmd(ctx,
+		"--question",
+		"--no-markup",
+		"--title", title,
+		"--text", prompt,
+		"--ok-label", remediationConfirmLabel,
+		"--cancel-label", remediationCancelLabel,
+	)
+	if zenityErr != nil {
+		var kdialogErr error
+		cmd, kdialogErr = allowedcmd.Kdialog.Cmd(ctx,
+			"--title", title,
+			"--yes-label", remediationConfirmLabel,
+			"--no-label", remediationCancelLabel,
+			"--yesno", prompt,
+		)
+		if kdialogErr != nil {
+			return false, fmt.Errorf("no dialog available (zenity: %v): %w", zenityErr, kdialogErr)
+		}
+	}
+
+	// Both zenity and kdialog exit 1 when the user declines
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 1:
+		return false, nil
+	default:
+		return false, fmt.Errorf("displaying dialog: %w", err)
+	}
+}
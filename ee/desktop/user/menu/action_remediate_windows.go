@@ -0,0 +1,52 @@
+//go:build windows
+
+package menu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"syscall"
+
+	"github.com/kolide/launcher/v2/ee/allowedcmd"
+)
+
+// confirmRemediation displays a dialog asking the user whether to run the remediation. The message box
+// only supports its standard button labels, so we use OK and Cancel.
+func confirmRemediation(ctx context.Context, title, prompt string) (bool, error) {
+	script := fmt.Sprintf(`Add-Type -AssemblyName PresentationFramework; if ([System.Windows.MessageBox]::Show('%s', '%s', 'OKCancel', 'Warning') -eq 'OK') { exit 0 } else { exit 1 }`,
+		powershellQuote(prompt), powershellQuote(title))
+
+	cmd, err := allowedcmd.Powershell.Cmd(ctx, "-NoProfile", "-NonInteractive", "-Command", script)
+	if err != nil {
+		return false, fmt.Errorf("creating command: %w", err)
+	}
+
+	// Otherwise the powershell window will appear behind the dialog
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+
+	err = cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 1:
+		return false, nil
+	default:
+		return false, fmt.Errorf("displaying dialog: %w", err)
+	}
+}
+
+// powershellQuote escapes s for use inside a single-quoted PowerShell string. PowerShell also treats
+// typographic single quotes as quote characters, so those are doubled too.
+func powershellQuote(s string) string {
+	return strings.NewReplacer(
+		"'", "''",
+		"‘", "‘‘",
+		"’", "’’",
+		"‚", "‚‚",
+		"‛", "‛‛",
+	).Replace(s)
+}
@@ -45,6 +45,11 @@ func Test_Unmarshal(t *testing.T) {
 			data:   `{"type":"open-url","action":{"url":"https://localhost:3443"}}`,
 			action: Action{Type: OpenURL, Action: json.RawMessage(`{"url":"https://localhost:3443"}`), Performer: actionOpenURL{URL: "https://localhost:3443"}},
 		},
+		{
+			name:   "remediate",
+			data:   `{"type":"remediate","action":{"remediation_id":"enable_firewall"}}`,
+			action: Action{Type: Remediate, Action: json.RawMessage(`{"remediation_id":"enable_firewall"}`), Performer: actionRemediate{RemediationID: "enable_firewall"}},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -25,11 +25,59 @@ const (
 	CircleDotIcon           menuIcon = "circle-dot"
 )
 
+// menuBadges are named identifiers for the state shown alongside a menu item's label
+type menuBadge string
+
+const (
+	NoBadge      menuBadge = ""
+	SuccessBadge menuBadge = "success"
+	WarningBadge menuBadge = "warning"
+	ErrorBadge   menuBadge = "error"
+)
+
 // MenuData encapsulates a menu bar icon and accessible menu items
 type MenuData struct {
 	Icon    menuIcon       `json:"icon"`
 	Tooltip string         `json:"tooltip,omitempty"`
 	Items   []menuItemData `json:"items"`
+	// Queries are run by the root launcher, and their results made available to the menu template
+	Queries []MenuQuery `json:"queries,omitempty"`
+	// Remediations are the server-approved fixes that remediate actions may ask the root launcher to run
+	Remediations []MenuRemediation `json:"remediations,omitempty"`
+}
+
+// MenuQuery is an osquery query whose results are available to the menu template under QueryResults,
+// and via queryValue. Results are not escaped, so the template must output them with json.
+type MenuQuery struct {
+	Name            string `json:"name"`
+	Query           string `json:"query"`
+	IntervalSeconds int64  `json:"interval_seconds,omitempty"` // How often to run the query; the launcher enforces a minimum
+}
+
+// MenuRemediation is a fix the user may run from the menu, after confirming. It runs as root, as either
+// a single allowed command, or a script run by the platform's shell. Remediations must be written literally
+// in the menu template's remediations array, without template actions; any that differ once expanded are rejected.
+type MenuRemediation struct {
+	ID                 string   `json:"id"`
+	Title              string   `json:"title"`                         // Shown when confirming, and in the result notification
+	ConfirmationPrompt string   `json:"confirmation_prompt,omitempty"` // Explains to the user what will happen
+	Command            string   `json:"command,omitempty"`             // Name of the allowed command to run
+	Args               []string `json:"args,omitempty"`
+	Script             string   `json:"script,omitempty"`
+	TimeoutSeconds     int64    `json:"timeout_seconds,omitempty"` // How long the remediation may run; the launcher enforces a maximum
+	SuccessMessage     string   `json:"success_message,omitempty"` // Body of the notification shown when the remediation succeeds
+	FailureMessage     string   `json:"failure_message,omitempty"` // Body of the notification shown when the remediation fails
+}
+
+// Remediation returns the remediation with the given ID, if the menu declares it.
+func (md *MenuData) Remediation(id string) (MenuRemediation, bool) {
+	for _, r := range md.Remediations {
+		if r.ID == id {
+			return r, true
+		}
+	}
+
+	return MenuRemediation{}, false
 }
 
 // menuItemData represents a menu item, optionally containing sub menu items
@@ -37,6 +85,8 @@ type menuItemData struct {
 	Label     string         `json:"label,omitempty"`
 	Tooltip   string         `json:"tooltip,omitempty"`
 	Disabled  bool           `json:"disabled,omitempty"` // Whether the item is grey text, or selectable
+	Hidden    bool           `json:"hidden,omitempty"`   // Whether the item, and its sub menu items, are omitted from the menu
+	Badge     menuBadge      `json:"badge,omitempty"`
 	Separator bool           `json:"separator,omitempty"`
 	Action    Action         `json:"action,omitempty"`
 	Items     []menuItemData `json:"items,omitempty"`
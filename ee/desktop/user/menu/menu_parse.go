@@ -15,7 +15,7 @@ func parseMenuData(m *MenuData, builder menuBuilder) {
 }
 
 func parseMenuItem(m *menuItemData, builder menuBuilder, parent any) {
-	if m == nil {
+	if m == nil || m.Hidden {
 		return
 	}
 
@@ -27,7 +27,7 @@ func parseMenuItem(m *menuItemData, builder menuBuilder, parent any) {
 
 	var item any
 	if m.Label != "" {
-		item = builder.addMenuItem(m.Label, m.Tooltip, m.Disabled, m.Action.Performer, parent)
+		item = builder.addMenuItem(badgedLabel(m.Label, m.Badge), m.Tooltip, m.Disabled, m.Action.Performer, parent)
 	}
 
 	if item == nil {
@@ -40,3 +40,19 @@ func parseMenuItem(m *menuItemData, builder menuBuilder, parent any) {
 		parseMenuItem(&child, builder, item)
 	}
 }
+
+// badgedLabel prefixes the label with a symbol for the badge, since menu items can't otherwise
+// display state across all platforms
+func badgedLabel(label string, badge menuBadge) string {
+	switch badge {
+	case SuccessBadge:
+		return "🟢 " + label
+	case WarningBadge:
+		return "🟡 " + label
+	case ErrorBadge:
+		return "🔴 " + label
+	default:
+		// Ignore unrecognized badges, so that new badges can be added without breaking older versions of launcher
+		return label
+	}
+}
@@ -126,3 +126,65 @@ func Test_ParseMenuItem(t *testing.T) {
 		})
 	}
 }
+
+func Test_ParseMenuItem_HiddenAndBadges(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		data     *menuItemData
+		expected *menuItemData
+	}{
+		{
+			name: "hidden",
+			data: &menuItemData{Label: "hidden item", Hidden: true},
+		},
+		{
+			name: "hidden submenu",
+			data: &menuItemData{
+				Label:  "parent",
+				Hidden: true,
+				Items: []menuItemData{
+					{Label: "first item"},
+				}},
+		},
+		{
+			name:     "success badge",
+			data:     &menuItemData{Label: "Disk encryption: on", Badge: SuccessBadge},
+			expected: &menuItemData{Label: "🟢 Disk encryption: on"},
+		},
+		{
+			name:     "error badge",
+			data:     &menuItemData{Label: "Disk encryption: off", Badge: ErrorBadge},
+			expected: &menuItemData{Label: "🔴 Disk encryption: off"},
+		},
+		{
+			name:     "unknown badge",
+			data:     &menuItemData{Label: "Disk encryption: off", Badge: "sparkles"},
+			expected: &menuItemData{Label: "Disk encryption: off"},
+		},
+		{
+			name: "hidden sub menu item",
+			data: &menuItemData{
+				Label: "parent",
+				Items: []menuItemData{
+					{Label: "first item", Hidden: true},
+					{Label: "second item", Badge: WarningBadge},
+				}},
+			expected: &menuItemData{
+				Label: "parent",
+				Items: []menuItemData{
+					{Label: "🟡 second item"},
+				}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			builder := &testMenuBuilder{parent: menuItemData{Label: "parent item"}}
+			parseMenuItem(tt.data, builder, nil)
+			assert.Equal(t, tt.expected, builder.itemCopy)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package menu
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strconv"
@@ -17,9 +18,13 @@ const (
 	// Capabilities queriable via hasCapability
 	funcHasCapability     = "hasCapability"
 	funcRelativeTime      = "relativeTime"
+	funcQueryValue        = "queryValue"
+	funcQueryHasRows      = "queryHasRows"
+	funcJson              = "json"
 	errorlessTemplateVars = "errorlessTemplateVars" // capability to evaluate undefined template vars without failing
 	errorlessActions      = "errorlessActions"      // capability to evaluate undefined menu item actions without failing
 	circleDot             = "circleDot"             // capability to use circle-dot icon
+	menuQueries           = "menuQueries"           // capability to run the menu's queries, and use hidden items and badges
 
 	// TemplateData keys
 	LauncherVersion    string = "LauncherVersion"
@@ -28,6 +33,7 @@ const (
 	ServerHostname     string = "ServerHostname"
 	LastMenuUpdateTime string = "LastMenuUpdateTime"
 	MenuVersion        string = "MenuVersion"
+	QueryResults       string = "QueryResults" // map of query name to the rows returned by the query; output values with json
 )
 
 type TemplateData map[string]any
@@ -202,6 +208,28 @@ func relativeTimeDefault(diff int64) string {
 	}
 }
 
+// escapeJsonString returns s escaped for use inside a JSON string, without the surrounding quotes.
+// The menu template is JSON, and query results may contain anything a local user can put into a
+// file name, window title, etc., so templates must output them with json, so that they cannot
+// alter its structure.
+func escapeJsonString(s string) string {
+	b, err := json.Marshal(s)
+	if err != nil {
+		// Marshalling a string cannot fail
+		return ""
+	}
+	return string(b[1 : len(b)-1])
+}
+
+// queryValue returns the value of the given column in the first of rows,
+// or an empty string if the query has not run or returned no rows.
+func queryValue(rows []map[string]string, column string) string {
+	if len(rows) == 0 {
+		return ""
+	}
+	return rows[0][column]
+}
+
 // Parse parses text as a template body for the menu template data
 // if an error occurs while parsing, an empty string is returned along with the error
 func (tp *templateParser) Parse(text string) (string, error) {
@@ -209,6 +237,8 @@ func (tp *templateParser) Parse(text string) (string, error) {
 		return "", errors.New("templateData is nil")
 	}
 
+	queryResults, _ := (*tp.td)[QueryResults].(map[string][]map[string]string)
+
 	t, err := template.New("menu_template").Funcs(template.FuncMap{
 		funcHasCapability: func(capability string) bool {
 			switch capability {
@@ -220,10 +250,21 @@ func (tp *templateParser) Parse(text string) (string, error) {
 				return true
 			case circleDot:
 				return true
+			case menuQueries:
+				return true
+			case funcJson:
+				return true
 			}
 			return false
 		},
 		funcRelativeTime: tp.relativeTimeLocalized,
+		funcQueryValue: func(name string, column string) string {
+			return queryValue(queryResults[name], column)
+		},
+		funcQueryHasRows: func(name string) bool {
+			return len(queryResults[name]) > 0
+		},
+		funcJson: escapeJsonString,
 	}).Parse(text)
 	if err != nil {
 		return "", fmt.Errorf("could not parse template: %w", err)
@@ -171,6 +171,73 @@ func Test_Parse(t *testing.T) {
 			text:   "{{if .UndefinedKey}}UndefinedKey is: {{.UndefinedKey}}{{else}}UndefinedKey is NOT set.{{end}}",
 			output: "UndefinedKey is NOT set.",
 		},
+		{
+			name:   "menuQueries capability",
+			td:     &TemplateData{},
+			text:   "{{if hasCapability `menuQueries`}}supported{{else}}unsupported{{end}}",
+			output: "supported",
+		},
+		{
+			name: "query value",
+			td: &TemplateData{QueryResults: map[string][]map[string]string{
+				"disk_encryption": {{"encrypted": "0"}, {"encrypted": "1"}},
+			}},
+			text:   "Disk encryption: {{if eq (queryValue `disk_encryption` `encrypted`) `1`}}on{{else}}off{{end}}",
+			output: "Disk encryption: off",
+		},
+		{
+			name: "query value for missing column",
+			td: &TemplateData{QueryResults: map[string][]map[string]string{
+				"disk_encryption": {{"encrypted": "1"}},
+			}},
+			text:   "Value: {{queryValue `disk_encryption` `missing`}}",
+			output: "Value: ",
+		},
+		{
+			name:   "query value before query has run",
+			td:     &TemplateData{},
+			text:   "Value: {{queryValue `disk_encryption` `encrypted`}}",
+			output: "Value: ",
+		},
+		{
+			name: "query has rows",
+			td: &TemplateData{QueryResults: map[string][]map[string]string{
+				"disk_encryption": {{"encrypted": "1"}},
+				"firewall":        {},
+			}},
+			text:   "{{queryHasRows `disk_encryption`}} {{queryHasRows `firewall`}} {{queryHasRows `missing`}}",
+			output: "true false false",
+		},
+		{
+			name: "query value is compared unescaped",
+			td: &TemplateData{QueryResults: map[string][]map[string]string{
+				"window_title": {{"title": `a "quoted" <title> & \ more`}},
+			}},
+			text:   "{{if eq (queryValue `window_title` `title`) `a \"quoted\" <title> & \\ more`}}match{{else}}no match{{end}}",
+			output: "match",
+		},
+		{
+			name:   "json capability",
+			td:     &TemplateData{},
+			text:   "{{if hasCapability `json`}}supported{{else}}unsupported{{end}}",
+			output: "supported",
+		},
+		{
+			name: "query value output with json",
+			td: &TemplateData{QueryResults: map[string][]map[string]string{
+				"window_title": {{"title": "x\", \"action\": {\"type\": \"remediate\"}, \"y\": \"\\\n"}},
+			}},
+			text:   `{"label": "{{json (queryValue ` + "`window_title` `title`" + `)}}"}`,
+			output: `{"label": "x\", \"action\": {\"type\": \"remediate\"}, \"y\": \"\\\n"}`,
+		},
+		{
+			name: "query results output with json",
+			td: &TemplateData{QueryResults: map[string][]map[string]string{
+				"window_title": {{"title": `"}`}},
+			}},
+			text:   `{"label": "{{with index .QueryResults.window_title 0}}{{json .title}}{{end}}"}`,
+			output: `{"label": "\"}"}`,
+		},
 		{
 			name:   "current menu version",
 			td:     &TemplateData{MenuVersion: CurrentMenuVersion},
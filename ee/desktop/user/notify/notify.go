@@ -1,6 +1,9 @@
 package notify
 
-import "time"
+import (
+	"sync"
+	"time"
+)
 
 // Represents notification received from control server; SentAt is set by this consumer after sending.
 // For the time being, notifications are per-end user device and not per-user.
@@ -9,6 +12,245 @@ type Notification struct {
 	Body       string    `json:"body"`
 	ActionUri  string    `json:"action_uri,omitempty"`
 	ID         string    `json:"id"`
-	ValidUntil int64     `json:"valid_until"` // timestamp
+	ValidUntil int64     `json:"valid_until"` // timestamp; the notification is not delivered after this
 	SentAt     time.Time `json:"sent_at,omitempty"`
+	// ExpiresAt, when set, is the timestamp at which a delivered notification is withdrawn and
+	// reported as expired.
+	ExpiresAt int64 `json:"expires_at,omitempty"`
+	// Actions are the buttons displayed on the notification, in order.
+	Actions []Action `json:"actions,omitempty"`
+	// Priority controls how prominently the notification is displayed; defaults to PriorityNormal.
+	Priority Priority `json:"priority,omitempty"`
+	// DedupKey, when set, causes this notification to replace any still-displayed notification
+	// with the same key, rather than being displayed alongside it.
+	DedupKey string `json:"dedup_key,omitempty"`
+}
+
+// Action is a button displayed on a notification. If Uri is set, it is opened when the button
+// is clicked; either way, the click is reported back as a Response.
+type Action struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+	Uri   string `json:"uri,omitempty"`
+}
+
+type Priority string
+
+const (
+	PriorityLow      Priority = "low"
+	PriorityNormal   Priority = "normal"
+	PriorityCritical Priority = "critical"
+)
+
+// IsValid returns true if the priority is known, or unset.
+func (p Priority) IsValid() bool {
+	switch p {
+	case "", PriorityLow, PriorityNormal, PriorityCritical:
+		return true
+	default:
+		return false
+	}
+}
+
+type ResponseType string
+
+const (
+	ResponseActionClicked ResponseType = "action_clicked"
+	ResponseDismissed     ResponseType = "dismissed"
+	ResponseExpired       ResponseType = "expired"
+)
+
+// IsValid returns true if the response type is known.
+func (r ResponseType) IsValid() bool {
+	switch r {
+	case ResponseActionClicked, ResponseDismissed, ResponseExpired:
+		return true
+	default:
+		return false
+	}
+}
+
+// DefaultActionID is reported as the ActionID when the user clicks on the body of the notification,
+// rather than on one of its buttons.
+const DefaultActionID = "default"
+
+// learnMoreActionID identifies the button created for notifications that set ActionUri.
+const learnMoreActionID = "learn_more"
+
+// Response describes how the user responded to a notification.
+type Response struct {
+	NotificationID string       `json:"notification_id"`
+	DedupKey       string       `json:"dedup_key,omitempty"`
+	Type           ResponseType `json:"type"`
+	ActionID       string       `json:"action_id,omitempty"`
+	RespondedAt    time.Time    `json:"responded_at"`
+}
+
+// displayActions returns the buttons to display on the notification. Notifications that
+// only set ActionUri get a single "Learn more" button, labeled with learnMoreLabel.
+func (n Notification) displayActions(learnMoreLabel string) []Action {
+	if len(n.Actions) > 0 {
+		return n.Actions
+	}
+
+	if n.ActionUri == "" {
+		return nil
+	}
+
+	return []Action{
+		{
+			ID:    learnMoreActionID,
+			Label: learnMoreLabel,
+			Uri:   n.ActionUri,
+		},
+	}
+}
+
+// actionUri returns the URI to open when the given action is clicked, if any.
+func (n Notification) actionUri(actionId string) string {
+	if actionId == DefaultActionID || actionId == learnMoreActionID {
+		return n.ActionUri
+	}
+
+	for _, a := range n.Actions {
+		if a.ID == actionId {
+			return a.Uri
+		}
+	}
+
+	return ""
+}
+
+// expiresIn returns the time remaining until the notification expires, and false if
+// it does not expire.
+func (n Notification) expiresIn() (time.Duration, bool) {
+	if n.ExpiresAt <= 0 {
+		return 0, false
+	}
+
+	return max(time.Until(time.Unix(n.ExpiresAt, 0)), 0), true
+}
+
+// responseTracker keeps track of the notifications we've displayed, so that we can report the
+// user's response to each exactly once, and expire notifications that the platform won't.
+type responseTracker struct {
+	lock     sync.Mutex
+	handler  func(Response)
+	sent     map[string]*trackedNotification
+	dedupIds map[string]string
+}
+
+type trackedNotification struct {
+	notification Notification
+	expiryTimer  *time.Timer
+}
+
+func newResponseTracker() *responseTracker {
+	return &responseTracker{
+		sent:     make(map[string]*trackedNotification),
+		dedupIds: make(map[string]string),
+	}
+}
+
+func (r *responseTracker) setHandler(handler func(Response)) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.handler = handler
+}
+
+// replacedBy returns the platform ID of the notification that n should replace, if any,
+// and stops tracking that notification.
+func (r *responseTracker) replacedBy(n Notification) (string, bool) {
+	if n.DedupKey == "" {
+		return "", false
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	platformId, found := r.dedupIds[n.DedupKey]
+	if !found {
+		return "", false
+	}
+	r.untrack(platformId)
+
+	return platformId, true
+}
+
+// track begins tracking the notification displayed under the given platform ID. If the
+// notification expires, onExpire is called once it does, and an expired response is reported.
+func (r *responseTracker) track(platformId string, n Notification, onExpire func(platformId string)) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	// The platform may reuse IDs, e.g. when replacing a notification
+	r.untrack(platformId)
+
+	tracked := &trackedNotification{notification: n}
+	if expiresIn, ok := n.expiresIn(); ok {
+		tracked.expiryTimer = time.AfterFunc(expiresIn, func() {
+			if onExpire != nil {
+				onExpire(platformId)
+			}
+			r.respond(platformId, ResponseExpired, "")
+		})
+	}
+
+	r.sent[platformId] = tracked
+	if n.DedupKey != "" {
+		r.dedupIds[n.DedupKey] = platformId
+	}
+}
+
+// untrack stops tracking the given notification; callers must hold the lock.
+func (r *responseTracker) untrack(platformId string) {
+	tracked, found := r.sent[platformId]
+	if !found {
+		return
+	}
+
+	if tracked.expiryTimer != nil {
+		tracked.expiryTimer.Stop()
+	}
+	delete(r.sent, platformId)
+	if tracked.notification.DedupKey != "" && r.dedupIds[tracked.notification.DedupKey] == platformId {
+		delete(r.dedupIds, tracked.notification.DedupKey)
+	}
+}
+
+// respond reports the user's response to the notification with the given platform ID, returning
+// the notification. Only the first response to a notification is reported; false is returned
+// for subsequent responses, and for notifications that did not originate with us.
+func (r *responseTracker) respond(platformId string, responseType ResponseType, actionId string) (Notification, bool) {
+	r.lock.Lock()
+	tracked, found := r.sent[platformId]
+	if !found {
+		r.lock.Unlock()
+		return Notification{}, false
+	}
+	r.untrack(platformId)
+	handler := r.handler
+	r.lock.Unlock()
+
+	if handler != nil {
+		handler(Response{
+			NotificationID: tracked.notification.ID,
+			DedupKey:       tracked.notification.DedupKey,
+			Type:           responseType,
+			ActionID:       actionId,
+			RespondedAt:    time.Now().UTC(),
+		})
+	}
+
+	return tracked.notification, true
+}
+
+// stop stops all pending expiry timers.
+func (r *responseTracker) stop() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for platformId := range r.sent {
+		r.untrack(platformId)
+	}
 }
@@ -9,11 +9,13 @@ package notify
 #include <stdbool.h>
 #include <stdlib.h>
 
-bool sendNotification(char *cTitle, char *cBody, char *cActionUri);
-void runNotificationListenerApp(char *cLearnMoreLabel);
+bool sendNotification(char *cIdentifier, char *cTitle, char *cBody, char *cActionsJson, char *cDefaultUri, int interruptionLevel);
+void removeNotification(char *cIdentifier);
+void runNotificationListenerApp(void);
 */
 import "C"
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -21,6 +23,8 @@ import (
 	"strings"
 	"sync/atomic"
 	"unsafe"
+
+	"github.com/kolide/kit/ulid"
 )
 
 type macNotifier struct {
@@ -29,6 +33,17 @@ type macNotifier struct {
 	interrupted      atomic.Bool
 }
 
+// Values for UNNotificationInterruptionLevel
+var interruptionLevels = map[Priority]int{
+	PriorityLow:      0, // passive
+	PriorityNormal:   1, // active
+	PriorityCritical: 2, // time sensitive
+}
+
+// responses is shared by all notifiers, since responses are delivered to a single
+// notification center delegate.
+var responses = newResponseTracker()
+
 func NewDesktopNotifier(_ *slog.Logger, _ string, localizationPath string) *macNotifier {
 	return &macNotifier{
 		localizationPath: localizationPath,
@@ -47,6 +62,7 @@ func (m *macNotifier) Interrupt(err error) {
 	}
 
 	m.interrupt <- struct{}{}
+	responses.stop()
 }
 
 func (m *macNotifier) Listen() {
@@ -54,13 +70,12 @@ func (m *macNotifier) Listen() {
 		return
 	}
 
-	// Resolve the "Learn More" label once at listener startup. The action title
-	// is baked into the registered notification category, so live locale changes
-	// only take effect after the desktop process restarts.
-	learnMoreCStr := C.CString(learnMoreLabel(m.localizationPath))
-	defer C.free(unsafe.Pointer(learnMoreCStr))
+	C.runNotificationListenerApp()
+}
 
-	C.runNotificationListenerApp(learnMoreCStr)
+// SetResponseHandler sets the function called with the user's response to each notification.
+func (m *macNotifier) SetResponseHandler(handler func(Response)) {
+	responses.setHandler(handler)
 }
 
 func (m *macNotifier) SendNotification(n Notification) error {
@@ -70,23 +85,84 @@ func (m *macNotifier) SendNotification(n Notification) error {
 		return errors.New("cannot send notification because this application is not bundled")
 	}
 
+	// Reusing the identifier of a delivered notification replaces it
+	identifier, found := responses.replacedBy(n)
+	if !found {
+		identifier = notificationIdentifier(n)
+	}
+
+	actionsJson, err := json.Marshal(n.displayActions(learnMoreLabel(m.localizationPath)))
+	if err != nil {
+		return fmt.Errorf("marshalling notification actions: %w", err)
+	}
+
+	identifierCStr := C.CString(identifier)
+	defer C.free(unsafe.Pointer(identifierCStr))
 	titleCStr := C.CString(n.Title)
 	defer C.free(unsafe.Pointer(titleCStr))
 	bodyCStr := C.CString(n.Body)
 	defer C.free(unsafe.Pointer(bodyCStr))
-	actionUriCStr := C.CString(n.ActionUri)
-	defer C.free(unsafe.Pointer(actionUriCStr))
+	actionsJsonCStr := C.CString(string(actionsJson))
+	defer C.free(unsafe.Pointer(actionsJsonCStr))
+	defaultUriCStr := C.CString(n.actionUri(DefaultActionID))
+	defer C.free(unsafe.Pointer(defaultUriCStr))
+
+	interruptionLevel, ok := interruptionLevels[n.Priority]
+	if !ok {
+		interruptionLevel = interruptionLevels[PriorityNormal]
+	}
 
-	success := C.sendNotification(titleCStr, bodyCStr, actionUriCStr)
+	success := C.sendNotification(identifierCStr, titleCStr, bodyCStr, actionsJsonCStr, defaultUriCStr, C.int(interruptionLevel))
 	if !success {
 		// We don't need to log the lack of success here -- `C.sendNotification`
 		// performs an NSLog with the actual error in it.
 		return fmt.Errorf("could not send notification: %s", n.Title)
 	}
 
+	responses.track(identifier, n, removeNotification)
+
 	return nil
 }
 
+func notificationIdentifier(n Notification) string {
+	if n.DedupKey != "" {
+		return "kolide-notify-" + n.DedupKey
+	}
+	if n.ID != "" {
+		return "kolide-notify-" + n.ID
+	}
+	return "kolide-notify-" + ulid.New()
+}
+
+// removeNotification removes the given notification, and its buttons, from the notification center
+// once it has expired.
+func removeNotification(identifier string) {
+	identifierCStr := C.CString(identifier)
+	defer C.free(unsafe.Pointer(identifierCStr))
+
+	C.removeNotification(identifierCStr)
+}
+
+// notificationResponseReceived is called by the notification center delegate when the user
+// responds to a notification. It returns the URI to open for the clicked action, if any;
+// the caller is responsible for freeing it. It returns nil for notifications we aren't tracking,
+// e.g. those sent before the desktop process restarted, so the caller falls back to the URIs
+// stored with the notification.
+//
+//export notificationResponseReceived
+func notificationResponseReceived(cIdentifier *C.char, cResponseType *C.char, cActionId *C.char) *C.char {
+	n, found := responses.respond(C.GoString(cIdentifier), ResponseType(C.GoString(cResponseType)), C.GoString(cActionId))
+	if !found {
+		return nil
+	}
+
+	actionUri := n.actionUri(C.GoString(cActionId))
+	if actionUri == "" {
+		return nil
+	}
+	return C.CString(actionUri)
+}
+
 func isBundle() bool {
 	currentExecutable, err := os.Executable()
 	if err != nil {
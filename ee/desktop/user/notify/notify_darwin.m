@@ -7,60 +7,158 @@
 #import <UserNotifications/UserNotifications.h>
 #import <AppKit/AppKit.h>
 
+#include <stdlib.h>
+#include <string.h>
+
+#import "_cgo_export.h"
+
+// Notifications without any buttons use this category, so that we are informed when they are dismissed
+NSString *const kolideCategoryIdentifier = @"KolideNotificationCategory";
+
+NSString *categoryIdentifierFor(NSString *identifier) {
+    return [NSString stringWithFormat:@"kolide-category-%@", identifier];
+}
+
+// updateCategories replaces the category with the given identifier, or removes it if category is nil.
+// Categories can only be read and written as a set, so updates are serialized.
+void updateCategories(UNUserNotificationCenter *center, NSString *categoryIdentifier, UNNotificationCategory *category) {
+    @synchronized (kolideCategoryIdentifier) {
+        dispatch_semaphore_t semaphore = dispatch_semaphore_create(0);
+        [center getNotificationCategoriesWithCompletionHandler:^(NSSet<UNNotificationCategory *> *existingCategories) {
+            NSMutableSet *categories = [NSMutableSet setWithCapacity:[existingCategories count] + 1];
+            for (UNNotificationCategory *existingCategory in existingCategories) {
+                if (![existingCategory.identifier isEqualToString:categoryIdentifier]) {
+                    [categories addObject:existingCategory];
+                }
+            }
+            if (category != nil) {
+                [categories addObject:category];
+            }
+            [center setNotificationCategories:categories];
+            dispatch_semaphore_signal(semaphore);
+        }];
+        dispatch_semaphore_wait(semaphore, dispatch_time(DISPATCH_TIME_NOW, 10 * NSEC_PER_SEC));
+    }
+}
+
 @interface NotificationDelegate: NSObject <UNUserNotificationCenterDelegate>
 @end
 @implementation NotificationDelegate
 - (void)userNotificationCenter:(UNUserNotificationCenter *)center didReceiveNotificationResponse:(UNNotificationResponse *)response withCompletionHandler:(void (^)(void))completionHandler {
-    NSDictionary *userInfo = response.notification.request.content.userInfo;
+    NSString *identifier = response.notification.request.identifier;
+
+    NSString *responseType = @"action_clicked";
+    NSString *actionId = response.actionIdentifier;
+    if ([response.actionIdentifier isEqualToString:UNNotificationDismissActionIdentifier]) {
+        responseType = @"dismissed";
+        actionId = @"";
+    } else if ([response.actionIdentifier isEqualToString:UNNotificationDefaultActionIdentifier]) {
+        actionId = @"default";
+    }
 
-    NSString *actionUri = userInfo[@"action_uri"];
-    if ([actionUri length] != 0) {
+    NSString *actionUri = nil;
+    char *cActionUri = notificationResponseReceived((char *)[identifier UTF8String], (char *)[responseType UTF8String], (char *)[actionId UTF8String]);
+    if (cActionUri != NULL) {
+        actionUri = [NSString stringWithUTF8String:cActionUri];
+        free(cActionUri);
+    } else {
+        // The desktop process may have restarted since sending this notification, in which case
+        // only the notification itself knows where its actions lead
+        id actionUris = response.notification.request.content.userInfo[@"action_uris"];
+        if ([actionUris isKindOfClass:[NSDictionary class]] && [actionUris[actionId] isKindOfClass:[NSString class]]) {
+            actionUri = actionUris[actionId];
+        }
+    }
+    if (actionUri != nil && [actionUri length] != 0) {
         [[NSWorkspace sharedWorkspace] openURL:[NSURL URLWithString:actionUri]];
     }
 
+    // The notification has been handled, so its buttons are no longer needed
+    dispatch_async(dispatch_get_global_queue(DISPATCH_QUEUE_PRIORITY_DEFAULT, 0), ^{
+        updateCategories(center, categoryIdentifierFor(identifier), nil);
+    });
+
     completionHandler();
 }
 @end
 
 NotificationDelegate *notificationDelegate;
 
-void runNotificationListenerApp(char *cLearnMoreLabel) {
+void runNotificationListenerApp(void) {
     @autoreleasepool {
         [NSApplication sharedApplication];
 
         UNUserNotificationCenter *center = [UNUserNotificationCenter currentNotificationCenter];
 
-        NSString *learnMoreTitle = [NSString stringWithUTF8String:cLearnMoreLabel];
-
-        // Define our custom notification category with actions we will want to use on notifications later
-        UNNotificationAction *learnMoreAction = [UNNotificationAction actionWithIdentifier:@"LearnMoreAction"
-            title:learnMoreTitle options:UNNotificationActionOptionNone];
-
-        UNNotificationCategory *category = [UNNotificationCategory categoryWithIdentifier:@"KolideNotificationWithButtonCategory"
-            actions:@[learnMoreAction] intentIdentifiers:@[]
-            options:UNNotificationCategoryOptionNone];
-        NSSet *categories = [NSSet setWithObject:category];
-        [center setNotificationCategories:categories];
+        UNNotificationCategory *category = [UNNotificationCategory categoryWithIdentifier:kolideCategoryIdentifier
+            actions:@[] intentIdentifiers:@[]
+            options:UNNotificationCategoryOptionCustomDismissAction];
+        [center setNotificationCategories:[NSSet setWithObject:category]];
 
         notificationDelegate = [[NotificationDelegate alloc] init];
         [center setDelegate:notificationDelegate];
     }
 }
 
-BOOL doSendNotification(UNUserNotificationCenter *center, NSString *title, NSString *body, NSString *actionUri) {
+// registerCategory registers a category holding the given notification's buttons, returning its identifier.
+// Buttons are defined per category rather than per notification, so each notification with buttons gets its own;
+// it is removed once the notification is responded to or expires.
+NSString *registerCategory(UNUserNotificationCenter *center, NSString *identifier, NSArray *actions) {
+    if (actions == nil || [actions count] == 0) {
+        return kolideCategoryIdentifier;
+    }
+
+    NSMutableArray *notificationActions = [NSMutableArray arrayWithCapacity:[actions count]];
+    for (NSDictionary *action in actions) {
+        NSString *actionId = action[@"id"];
+        NSString *label = action[@"label"];
+        if (actionId == nil || label == nil) {
+            continue;
+        }
+        [notificationActions addObject:[UNNotificationAction actionWithIdentifier:actionId
+            title:label options:UNNotificationActionOptionNone]];
+    }
+
+    NSString *categoryIdentifier = categoryIdentifierFor(identifier);
+    UNNotificationCategory *category = [UNNotificationCategory categoryWithIdentifier:categoryIdentifier
+        actions:notificationActions intentIdentifiers:@[]
+        options:UNNotificationCategoryOptionCustomDismissAction];
+
+    // Add our category to the existing ones, replacing any previous category for this notification
+    updateCategories(center, categoryIdentifier, category);
+
+    return categoryIdentifier;
+}
+
+// actionUris maps each action's identifier to the URI it opens, including the default action for clicks
+// on the notification body. They are stored on the notification, so that they outlive this process.
+NSDictionary *actionUris(NSArray *actions, NSString *defaultUri) {
+    NSMutableDictionary *uris = [NSMutableDictionary dictionary];
+    for (NSDictionary *action in actions) {
+        NSString *actionId = action[@"id"];
+        NSString *uri = action[@"uri"];
+        if ([actionId isKindOfClass:[NSString class]] && [uri isKindOfClass:[NSString class]] && [uri length] != 0) {
+            uris[actionId] = uri;
+        }
+    }
+    if ([defaultUri length] != 0) {
+        uris[@"default"] = defaultUri;
+    }
+    return uris;
+}
+
+BOOL doSendNotification(UNUserNotificationCenter *center, NSString *identifier, NSString *title, NSString *body, NSArray *actions, NSString *defaultUri, int interruptionLevel) {
     UNMutableNotificationContent *content = [UNMutableNotificationContent new];
     [content autorelease];
     content.title = title;
     content.body = body;
+    content.categoryIdentifier = registerCategory(center, identifier, actions);
+    content.userInfo = @{@"action_uris": actionUris(actions, defaultUri)};
 
-    if (actionUri != (id)[NSNull null] && actionUri.length > 0) {
-        // Only create "Learn more" button if we have an action URI to go with it
-        content.categoryIdentifier = @"KolideNotificationWithButtonCategory";
-        content.userInfo = @{@"action_uri": actionUri};
+    if (@available(macOS 12.0, *)) {
+        content.interruptionLevel = (UNNotificationInterruptionLevel)interruptionLevel;
     }
 
-    NSString *uuid = [[NSUUID UUID] UUIDString];
-    NSString *identifier = [NSString stringWithFormat:@"kolide-notify-%@", uuid];
     UNNotificationRequest *request = [UNNotificationRequest requestWithIdentifier:identifier
         content:content trigger:nil];
 
@@ -89,12 +187,28 @@ BOOL doSendNotification(UNUserNotificationCenter *center, NSString *title, NSStr
     return success;
 }
 
-BOOL sendNotification(char *cTitle, char *cBody, char *cActionUri) {
+void removeNotification(char *cIdentifier) {
     UNUserNotificationCenter *center = [UNUserNotificationCenter currentNotificationCenter];
+    NSString *identifier = [NSString stringWithUTF8String:cIdentifier];
+
+    [center removePendingNotificationRequestsWithIdentifiers:@[identifier]];
+    [center removeDeliveredNotificationsWithIdentifiers:@[identifier]];
+    updateCategories(center, categoryIdentifierFor(identifier), nil);
+}
 
+BOOL sendNotification(char *cIdentifier, char *cTitle, char *cBody, char *cActionsJson, char *cDefaultUri, int interruptionLevel) {
+    UNUserNotificationCenter *center = [UNUserNotificationCenter currentNotificationCenter];
+
+    NSString *identifier = [NSString stringWithUTF8String:cIdentifier];
     NSString *title = [NSString stringWithUTF8String:cTitle];
     NSString *body = [NSString stringWithUTF8String:cBody];
-    NSString *actionUri = [NSString stringWithUTF8String:cActionUri];
+    NSString *defaultUri = [NSString stringWithUTF8String:cDefaultUri];
+
+    NSData *actionsJson = [NSData dataWithBytes:cActionsJson length:strlen(cActionsJson)];
+    id actions = [NSJSONSerialization JSONObjectWithData:actionsJson options:0 error:nil];
+    if (![actions isKindOfClass:[NSArray class]]) {
+        actions = nil;
+    }
 
     __block BOOL canSendNotification = NO;
     UNAuthorizationOptions options = (UNAuthorizationOptionAlert | UNAuthorizationStatusProvisional);
@@ -121,7 +235,7 @@ BOOL sendNotification(char *cTitle, char *cBody, char *cActionUri) {
     dispatch_semaphore_wait(semaphore, timeout);
 
     if (canSendNotification) {
-        return doSendNotification(center, title, body, actionUri);
+        return doSendNotification(center, identifier, title, body, actions, defaultUri, interruptionLevel);
     }
 
     return NO;
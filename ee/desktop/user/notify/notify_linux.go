@@ -7,7 +7,8 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
-	"sync"
+	"math"
+	"strconv"
 	"sync/atomic"
 
 	"github.com/godbus/dbus/v5"
@@ -15,26 +16,44 @@ import (
 )
 
 type dbusNotifier struct {
-	iconFilepath        string
-	localizationPath    string
-	slogger             *slog.Logger
-	conn                *dbus.Conn
-	signal              chan *dbus.Signal
-	interrupt           chan struct{}
-	interrupted         atomic.Bool
-	sentNotificationIds map[uint32]bool
-	lock                sync.RWMutex
+	iconFilepath     string
+	localizationPath string
+	slogger          *slog.Logger
+	conn             *dbus.Conn
+	signal           chan *dbus.Signal
+	interrupt        chan struct{}
+	interrupted      atomic.Bool
+	responses        *responseTracker
 }
 
 const (
 	notificationServiceObj       = "/org/freedesktop/Notifications"
 	notificationServiceInterface = "org.freedesktop.Notifications"
 	signalActionInvoked          = "org.freedesktop.Notifications.ActionInvoked"
+	signalNotificationClosed     = "org.freedesktop.Notifications.NotificationClosed"
+	methodNotify                 = "org.freedesktop.Notifications.Notify"
+	methodCloseNotification      = "org.freedesktop.Notifications.CloseNotification"
 	desktopPortalPath            = "/org/freedesktop/portal/desktop"
 	desktopPortalName            = "org.freedesktop.portal.Desktop"
 	methodOpenUri                = "org.freedesktop.portal.OpenURI.OpenURI"
 )
 
+// Reasons given in the NotificationClosed signal.
+// See: https://specifications.freedesktop.org/notification-spec/latest/protocol.html#signal-notification-closed
+const (
+	closeReasonExpired   uint32 = 1
+	closeReasonDismissed uint32 = 2
+	closeReasonClosed    uint32 = 3
+)
+
+// Values for the urgency hint.
+// See: https://specifications.freedesktop.org/notification-spec/latest/urgency-levels.html
+var urgencyLevels = map[Priority]byte{
+	PriorityLow:      0,
+	PriorityNormal:   1,
+	PriorityCritical: 2,
+}
+
 // We default to xdg-open first because, if available, it appears to be better at picking
 // the correct default browser.
 var browserLaunchers = []allowedcmd.AllowedCommand{allowedcmd.XdgOpen, allowedcmd.XWwwBrowser}
@@ -49,14 +68,13 @@ func NewDesktopNotifier(slogger *slog.Logger, iconFilepath string, localizationP
 	}
 
 	return &dbusNotifier{
-		iconFilepath:        iconFilepath,
-		localizationPath:    localizationPath,
-		slogger:             slogger.With("component", "desktop_notifier"),
-		conn:                conn,
-		signal:              make(chan *dbus.Signal, 5),
-		interrupt:           make(chan struct{}),
-		sentNotificationIds: make(map[uint32]bool),
-		lock:                sync.RWMutex{},
+		iconFilepath:     iconFilepath,
+		localizationPath: localizationPath,
+		slogger:          slogger.With("component", "desktop_notifier"),
+		conn:             conn,
+		signal:           make(chan *dbus.Signal, 5),
+		interrupt:        make(chan struct{}),
+		responses:        newResponseTracker(),
 	}
 }
 
@@ -86,70 +104,96 @@ func (d *dbusNotifier) Execute() error {
 				return errors.New("dbus signal channel closed, cannot proceed")
 			}
 
-			if signal == nil || signal.Name != signalActionInvoked {
-				continue
+			// Attempt to open a browser to the URL for the clicked action, if any
+			if actionUri := d.handleSignal(signal); actionUri != "" {
+				d.openUri(actionUri)
 			}
 
-			if len(signal.Body) < 2 {
-				// Malformed signal -- we expect notification ID + action URI
-				continue
-			}
+		case <-d.interrupt:
+			return nil
+		}
+	}
+}
 
-			// Confirm that this is a Kolide-originated notification by checking for known notification IDs
-			notificationId, ok := signal.Body[0].(uint32)
-			if !ok {
-				continue
-			}
-			d.lock.RLock()
-			if _, found := d.sentNotificationIds[notificationId]; !found {
-				// This notification didn't come from us -- ignore it
-				d.lock.RUnlock()
-				continue
-			}
-			d.lock.RUnlock()
+// handleSignal reports the user's response to a Kolide-originated notification, returning
+// the URI to open, if any.
+func (d *dbusNotifier) handleSignal(signal *dbus.Signal) string {
+	if signal == nil || len(signal.Body) < 2 {
+		// Malformed signal -- we expect notification ID + action key or close reason
+		return ""
+	}
 
-			// Attempt to open a browser to the given URL
-			actionUri, ok := signal.Body[1].(string)
-			if !ok {
-				continue
-			}
+	// We confirm that this is a Kolide-originated notification by checking for known notification IDs
+	notificationId, ok := signal.Body[0].(uint32)
+	if !ok {
+		return ""
+	}
+	platformId := strconv.FormatUint(uint64(notificationId), 10)
 
-			// Try via dbus before falling back to xdg-open and www-browser --
-			// we see improved behavior when using dbus.
-			err := OpenViaDbus(actionUri)
-			if err == nil {
-				continue
-			}
+	switch signal.Name {
+	case signalActionInvoked:
+		actionId, ok := signal.Body[1].(string)
+		if !ok {
+			return ""
+		}
+		n, found := d.responses.respond(platformId, ResponseActionClicked, actionId)
+		if !found {
+			return ""
+		}
+		return n.actionUri(actionId)
+
+	case signalNotificationClosed:
+		reason, ok := signal.Body[1].(uint32)
+		if !ok {
+			return ""
+		}
+		switch reason {
+		case closeReasonExpired:
+			d.responses.respond(platformId, ResponseExpired, "")
+		case closeReasonDismissed:
+			d.responses.respond(platformId, ResponseDismissed, "")
+		case closeReasonClosed:
+			// We closed it ourselves -- the expiry timer will report the response
+		}
+		return ""
+
+	default:
+		return ""
+	}
+}
+
+func (d *dbusNotifier) openUri(actionUri string) {
+	// Try via dbus before falling back to xdg-open and www-browser --
+	// we see improved behavior when using dbus.
+	err := OpenViaDbus(actionUri)
+	if err == nil {
+		return
+	}
+	d.slogger.Log(context.TODO(), slog.LevelWarn,
+		"couldn't open URI via dbus, falling back to exec",
+		"err", err,
+	)
+
+	for _, browserLauncher := range browserLaunchers {
+		cmd, err := browserLauncher.Cmd(context.TODO(), actionUri)
+		if err != nil {
 			d.slogger.Log(context.TODO(), slog.LevelWarn,
-				"couldn't open URI via dbus, falling back to exec",
+				"couldn't create command to start process",
 				"err", err,
+				"browser_launcher", browserLauncher,
 			)
+			continue
+		}
 
-			for _, browserLauncher := range browserLaunchers {
-				cmd, err := browserLauncher.Cmd(context.TODO(), actionUri)
-				if err != nil {
-					d.slogger.Log(context.TODO(), slog.LevelWarn,
-						"couldn't create command to start process",
-						"err", err,
-						"browser_launcher", browserLauncher,
-					)
-					continue
-				}
-
-				err = cmd.Start()
-				if err == nil {
-					break
-				}
-				d.slogger.Log(context.TODO(), slog.LevelError,
-					"couldn't start process",
-					"err", err,
-					"browser_launcher", browserLauncher,
-				)
-			}
-
-		case <-d.interrupt:
-			return nil
+		err = cmd.Start()
+		if err == nil {
+			break
 		}
+		d.slogger.Log(context.TODO(), slog.LevelError,
+			"couldn't start process",
+			"err", err,
+			"browser_launcher", browserLauncher,
+		)
 	}
 }
 
@@ -159,6 +203,7 @@ func (d *dbusNotifier) Interrupt(err error) {
 	}
 
 	d.interrupt <- struct{}{}
+	d.responses.stop()
 
 	if d.conn != nil {
 		d.conn.RemoveSignal(d.signal)
@@ -183,6 +228,11 @@ func (d *dbusNotifier) Interrupt(err error) {
 // just make compiler happy, this is only needed on darwin
 func (d *dbusNotifier) Listen() {}
 
+// SetResponseHandler sets the function called with the user's response to each notification.
+func (d *dbusNotifier) SetResponseHandler(handler func(Response)) {
+	d.responses.setHandler(handler)
+}
+
 func (d *dbusNotifier) SendNotification(n Notification) error {
 	if err := d.sendNotificationViaDbus(n); err == nil {
 		return nil
@@ -191,9 +241,24 @@ func (d *dbusNotifier) SendNotification(n Notification) error {
 	return d.sendNotificationViaNotifySend(n)
 }
 
+// sessionConn returns the connection used by the listener, so that signals for the notifications
+// we send are delivered to it, or a new connection if the listener has none. The returned
+// function must be called when done with the connection.
+func (d *dbusNotifier) sessionConn() (*dbus.Conn, func(), error) {
+	if d.conn != nil {
+		return d.conn, func() {}, nil
+	}
+
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, nil, err
+	}
+	return conn, func() { conn.Close() }, nil
+}
+
 // See: https://specifications.freedesktop.org/notification-spec/notification-spec-latest.html
 func (d *dbusNotifier) sendNotificationViaDbus(n Notification) error {
-	conn, err := dbus.ConnectSessionBus()
+	conn, done, err := d.sessionConn()
 	if err != nil {
 		d.slogger.Log(context.TODO(), slog.LevelDebug,
 			"could not connect to dbus, will try alternate method of notification",
@@ -201,23 +266,20 @@ func (d *dbusNotifier) sendNotificationViaDbus(n Notification) error {
 		)
 		return fmt.Errorf("could not connect to dbus: %w", err)
 	}
+	defer done()
 
-	actions := []string{}
-	if n.ActionUri != "" {
-		actions = append(actions, n.ActionUri, learnMoreLabel(d.localizationPath))
-	}
+	actions := dbusActions(n, learnMoreLabel(d.localizationPath))
 
-	notificationsService := conn.Object(notificationServiceInterface, notificationServiceObj)
-	call := notificationsService.Call("org.freedesktop.Notifications.Notify",
-		0,                         // no flags
-		"Kolide",                  // app_name
-		uint32(0),                 // replaces_id -- 0 means this notification won't replace any existing notifications
-		d.iconFilepath,            // app_icon
-		n.Title,                   // summary
-		n.Body,                    // body
-		actions,                   // actions
-		map[string]dbus.Variant{}, // hints
-		int32(0))                  // expire_timeout -- 0 means the notification will not expire
+	call := conn.Object(notificationServiceInterface, notificationServiceObj).Call(methodNotify,
+		0,                // no flags
+		"Kolide",         // app_name
+		d.replacesId(n),  // replaces_id -- 0 means this notification won't replace any existing notifications
+		d.iconFilepath,   // app_icon
+		n.Title,          // summary
+		n.Body,           // body
+		actions,          // actions
+		dbusHints(n),     // hints
+		expireTimeout(n)) // expire_timeout
 
 	if call.Err != nil {
 		d.slogger.Log(context.TODO(), slog.LevelError,
@@ -236,25 +298,103 @@ func (d *dbusNotifier) sendNotificationViaDbus(n Notification) error {
 			"err", err,
 		)
 	} else {
-		d.lock.Lock()
-		defer d.lock.Unlock()
-		d.sentNotificationIds[notificationId] = true
+		d.responses.track(strconv.FormatUint(uint64(notificationId), 10), n, d.closeNotification)
 	}
 
 	return nil
 }
 
+// replacesId returns the ID of the still-displayed notification with the same dedup key, if any.
+func (d *dbusNotifier) replacesId(n Notification) uint32 {
+	platformId, found := d.responses.replacedBy(n)
+	if !found {
+		return 0
+	}
+
+	replacesId, err := strconv.ParseUint(platformId, 10, 32)
+	if err != nil {
+		return 0
+	}
+	return uint32(replacesId)
+}
+
+// closeNotification closes the notification with the given ID once it has expired, since not all
+// notification servers respect expire_timeout.
+func (d *dbusNotifier) closeNotification(platformId string) {
+	notificationId, err := strconv.ParseUint(platformId, 10, 32)
+	if err != nil {
+		return
+	}
+
+	conn, done, err := d.sessionConn()
+	if err != nil {
+		return
+	}
+	defer done()
+
+	if call := conn.Object(notificationServiceInterface, notificationServiceObj).Call(methodCloseNotification, 0, uint32(notificationId)); call.Err != nil {
+		d.slogger.Log(context.TODO(), slog.LevelDebug,
+			"could not close expired notification",
+			"err", call.Err,
+		)
+	}
+}
+
+// dbusActions returns the actions for the notification as a list of action identifiers,
+// each followed by its label.
+func dbusActions(n Notification, learnMoreLabel string) []string {
+	actions := []string{}
+	for _, a := range n.displayActions(learnMoreLabel) {
+		actions = append(actions, a.ID, a.Label)
+	}
+
+	// Allow the user to click on the body of the notification, too
+	if n.ActionUri != "" {
+		actions = append(actions, DefaultActionID, learnMoreLabel)
+	}
+
+	return actions
+}
+
+func dbusHints(n Notification) map[string]dbus.Variant {
+	hints := map[string]dbus.Variant{}
+	if urgency, ok := urgencyLevels[n.Priority]; ok {
+		hints["urgency"] = dbus.MakeVariant(urgency)
+	}
+	return hints
+}
+
+// expireTimeout returns the time in milliseconds until the notification expires -- 0 means
+// the notification will not expire.
+func expireTimeout(n Notification) int32 {
+	expiresIn, ok := n.expiresIn()
+	if !ok {
+		return 0
+	}
+
+	// A timeout of 0 would mean never expiring, so use the shortest timeout possible instead
+	return int32(max(min(expiresIn.Milliseconds(), math.MaxInt32), 1))
+}
+
 func (d *dbusNotifier) sendNotificationViaNotifySend(n Notification) error {
 	// notify-send doesn't support actions, but URLs in notifications are clickable in at least
 	// some desktop environments.
-	if n.ActionUri != "" {
-		n.Body += " " + learnMoreLabel(d.localizationPath) + ": " + n.ActionUri
+	for _, a := range n.displayActions(learnMoreLabel(d.localizationPath)) {
+		if a.Uri != "" {
+			n.Body += "\n" + a.Label + ": " + a.Uri
+		}
 	}
 
 	args := []string{n.Title, n.Body}
 	if d.iconFilepath != "" {
 		args = append(args, "-i", d.iconFilepath)
 	}
+	if n.Priority != "" {
+		args = append(args, "-u", string(n.Priority))
+	}
+	if expiresIn, ok := n.expiresIn(); ok {
+		args = append(args, "-t", strconv.FormatInt(max(expiresIn.Milliseconds(), 1), 10))
+	}
 
 	cmd, err := allowedcmd.NotifySend.Cmd(context.TODO(), args...)
 	if err != nil {
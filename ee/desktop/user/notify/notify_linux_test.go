@@ -0,0 +1,130 @@
+//go:build linux
+
+package notify
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/kolide/launcher/v2/pkg/log/multislogger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleSignal(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		testCaseName         string
+		signal               *dbus.Signal
+		expectedUri          string
+		expectedResponseType ResponseType
+		expectedActionId     string
+	}{
+		{
+			testCaseName:         "action clicked",
+			signal:               &dbus.Signal{Name: signalActionInvoked, Body: []any{uint32(7), "fix"}},
+			expectedUri:          "https://www.kolide.com/fix",
+			expectedResponseType: ResponseActionClicked,
+			expectedActionId:     "fix",
+		},
+		{
+			testCaseName:         "action without URI clicked",
+			signal:               &dbus.Signal{Name: signalActionInvoked, Body: []any{uint32(7), "snooze"}},
+			expectedResponseType: ResponseActionClicked,
+			expectedActionId:     "snooze",
+		},
+		{
+			testCaseName:         "body clicked",
+			signal:               &dbus.Signal{Name: signalActionInvoked, Body: []any{uint32(7), DefaultActionID}},
+			expectedUri:          "https://www.kolide.com",
+			expectedResponseType: ResponseActionClicked,
+			expectedActionId:     DefaultActionID,
+		},
+		{
+			testCaseName:         "dismissed",
+			signal:               &dbus.Signal{Name: signalNotificationClosed, Body: []any{uint32(7), closeReasonDismissed}},
+			expectedResponseType: ResponseDismissed,
+		},
+		{
+			testCaseName:         "expired",
+			signal:               &dbus.Signal{Name: signalNotificationClosed, Body: []any{uint32(7), closeReasonExpired}},
+			expectedResponseType: ResponseExpired,
+		},
+		{
+			testCaseName: "closed by us",
+			signal:       &dbus.Signal{Name: signalNotificationClosed, Body: []any{uint32(7), closeReasonClosed}},
+		},
+		{
+			testCaseName: "notification from another app",
+			signal:       &dbus.Signal{Name: signalActionInvoked, Body: []any{uint32(8), "fix"}},
+		},
+		{
+			testCaseName: "malformed signal",
+			signal:       &dbus.Signal{Name: signalActionInvoked, Body: []any{"7"}},
+		},
+	} {
+		t.Run(tt.testCaseName, func(t *testing.T) {
+			t.Parallel()
+
+			responses := make(chan Response, 1)
+			d := &dbusNotifier{
+				slogger:   multislogger.NewNopLogger(),
+				responses: newResponseTracker(),
+			}
+			d.SetResponseHandler(func(r Response) { responses <- r })
+			d.responses.track("7", Notification{
+				ID:        "abc",
+				ActionUri: "https://www.kolide.com",
+				Actions: []Action{
+					{ID: "fix", Label: "Fix it", Uri: "https://www.kolide.com/fix"},
+					{ID: "snooze", Label: "Remind me later"},
+				},
+			}, nil)
+
+			require.Equal(t, tt.expectedUri, d.handleSignal(tt.signal))
+
+			if tt.expectedResponseType == "" {
+				require.Len(t, responses, 0)
+				return
+			}
+			require.Len(t, responses, 1)
+			response := <-responses
+			require.Equal(t, "abc", response.NotificationID)
+			require.Equal(t, tt.expectedResponseType, response.Type)
+			require.Equal(t, tt.expectedActionId, response.ActionID)
+		})
+	}
+}
+
+func TestDbusActions(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []string{}, dbusActions(Notification{}, "Learn more"))
+	require.Equal(t,
+		[]string{learnMoreActionID, "Learn more", DefaultActionID, "Learn more"},
+		dbusActions(Notification{ActionUri: "https://www.kolide.com"}, "Learn more"),
+	)
+	require.Equal(t,
+		[]string{"fix", "Fix it", "snooze", "Remind me later"},
+		dbusActions(Notification{Actions: []Action{{ID: "fix", Label: "Fix it"}, {ID: "snooze", Label: "Remind me later"}}}, "Learn more"),
+	)
+}
+
+func TestDbusHints(t *testing.T) {
+	t.Parallel()
+
+	require.Empty(t, dbusHints(Notification{}))
+	require.Equal(t, dbus.MakeVariant(byte(2)), dbusHints(Notification{Priority: PriorityCritical})["urgency"])
+	require.Equal(t, dbus.MakeVariant(byte(0)), dbusHints(Notification{Priority: PriorityLow})["urgency"])
+}
+
+func TestExpireTimeout(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, int32(0), expireTimeout(Notification{}))
+	require.Equal(t, int32(1), expireTimeout(Notification{ExpiresAt: time.Now().Add(-1 * time.Hour).Unix()}))
+	require.InDelta(t, time.Hour.Milliseconds(), expireTimeout(Notification{ExpiresAt: time.Now().Add(1 * time.Hour).Unix()}), float64(2*time.Second.Milliseconds()))
+	require.Equal(t, int32(math.MaxInt32), expireTimeout(Notification{ExpiresAt: time.Now().Add(365 * 24 * time.Hour).Unix()}))
+}
@@ -58,3 +58,130 @@ func TestInterrupt_Multiple(t *testing.T) {
 
 	require.Equal(t, expectedInterrupts, receivedInterrupts)
 }
+
+func TestResponseTracker_RespondsOnce(t *testing.T) {
+	t.Parallel()
+
+	responses := make(chan Response, 2)
+	tracker := newResponseTracker()
+	tracker.setHandler(func(r Response) { responses <- r })
+
+	n := Notification{
+		ID: "abc",
+		Actions: []Action{
+			{ID: "fix", Label: "Fix it", Uri: "https://www.kolide.com/fix"},
+		},
+		DedupKey: "test-dedup-key",
+	}
+	tracker.track("1", n, nil)
+
+	respondedTo, found := tracker.respond("1", ResponseActionClicked, "fix")
+	require.True(t, found)
+	require.Equal(t, "https://www.kolide.com/fix", respondedTo.actionUri("fix"))
+
+	// Further responses to the same notification, e.g. the close that follows the click, are not reported
+	_, found = tracker.respond("1", ResponseDismissed, "")
+	require.False(t, found)
+
+	// Nor are responses to notifications that we didn't send
+	_, found = tracker.respond("2", ResponseDismissed, "")
+	require.False(t, found)
+
+	require.Len(t, responses, 1)
+	response := <-responses
+	require.Equal(t, "abc", response.NotificationID)
+	require.Equal(t, "test-dedup-key", response.DedupKey)
+	require.Equal(t, ResponseActionClicked, response.Type)
+	require.Equal(t, "fix", response.ActionID)
+	require.False(t, response.RespondedAt.IsZero())
+}
+
+func TestResponseTracker_Dedup(t *testing.T) {
+	t.Parallel()
+
+	tracker := newResponseTracker()
+
+	_, found := tracker.replacedBy(Notification{ID: "first", DedupKey: "test-dedup-key"})
+	require.False(t, found)
+	tracker.track("1", Notification{ID: "first", DedupKey: "test-dedup-key"}, nil)
+
+	// Notifications without a dedup key never replace anything
+	_, found = tracker.replacedBy(Notification{ID: "unrelated"})
+	require.False(t, found)
+
+	replacedId, found := tracker.replacedBy(Notification{ID: "second", DedupKey: "test-dedup-key"})
+	require.True(t, found)
+	require.Equal(t, "1", replacedId)
+
+	// The replaced notification is no longer tracked
+	_, found = tracker.respond("1", ResponseDismissed, "")
+	require.False(t, found)
+}
+
+func TestResponseTracker_Expiry(t *testing.T) {
+	t.Parallel()
+
+	responses := make(chan Response, 1)
+	tracker := newResponseTracker()
+	tracker.setHandler(func(r Response) { responses <- r })
+
+	expiredIds := make(chan string, 1)
+	tracker.track("1", Notification{ID: "abc", ExpiresAt: time.Now().Add(1 * time.Second).Unix()}, func(platformId string) {
+		expiredIds <- platformId
+	})
+
+	select {
+	case response := <-responses:
+		require.Equal(t, "abc", response.NotificationID)
+		require.Equal(t, ResponseExpired, response.Type)
+	case <-time.After(5 * time.Second):
+		t.Error("notification did not expire within 5 seconds")
+		t.FailNow()
+	}
+	require.Equal(t, "1", <-expiredIds)
+
+	// Notifications that are responded to before they expire are not reported as expired
+	tracker.track("2", Notification{ID: "def", ExpiresAt: time.Now().Add(1 * time.Second).Unix()}, nil)
+	_, found := tracker.respond("2", ResponseDismissed, "")
+	require.True(t, found)
+	require.Equal(t, ResponseDismissed, (<-responses).Type)
+
+	time.Sleep(2 * time.Second)
+	require.Len(t, responses, 0)
+}
+
+func TestNotification_displayActions(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		testCaseName    string
+		notification    Notification
+		expectedActions []Action
+	}{
+		{
+			testCaseName: "no actions",
+			notification: Notification{Title: "test", Body: "test"},
+		},
+		{
+			testCaseName: "action URI only",
+			notification: Notification{ActionUri: "https://www.kolide.com"},
+			expectedActions: []Action{
+				{ID: learnMoreActionID, Label: "Learn more", Uri: "https://www.kolide.com"},
+			},
+		},
+		{
+			testCaseName: "actions take precedence over action URI",
+			notification: Notification{
+				ActionUri: "https://www.kolide.com",
+				Actions:   []Action{{ID: "fix", Label: "Fix it"}},
+			},
+			expectedActions: []Action{{ID: "fix", Label: "Fix it"}},
+		},
+	} {
+		t.Run(tt.testCaseName, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.expectedActions, tt.notification.displayActions("Learn more"))
+		})
+	}
+}
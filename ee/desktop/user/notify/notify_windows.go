@@ -47,6 +47,10 @@ func (w *windowsNotifier) Interrupt(err error) {
 	w.interrupt <- struct{}{}
 }
 
+// SetResponseHandler doesn't do anything on Windows -- toast notifications open their URLs
+// via protocol activation, which does not report back to us.
+func (w *windowsNotifier) SetResponseHandler(_ func(Response)) {}
+
 func (w *windowsNotifier) SendNotification(n Notification) error {
 	notification := toast.Notification{
 		AppID:   "Kolide",
@@ -59,19 +63,27 @@ func (w *windowsNotifier) SendNotification(n Notification) error {
 	}
 
 	if n.ActionUri != "" {
-		actionUri := strings.ReplaceAll(n.ActionUri, "&", "&amp;")
-
 		// Set the default action when the user clicks on the notification
-		notification.ActivationArguments = actionUri
-
-		// Additionally, create a "Learn more" button that will open the same URL
-		notification.Actions = []toast.Action{
-			{
-				Type:      "protocol",
-				Label:     learnMoreLabel(w.localizationPath),
-				Arguments: actionUri,
-			},
+		notification.ActivationArguments = escapeToastArgument(n.ActionUri)
+	}
+
+	// Create a button for each action that opens a URL -- we have no way of handling other actions
+	for _, a := range n.displayActions(learnMoreLabel(w.localizationPath)) {
+		if a.Uri == "" {
+			continue
 		}
+		notification.Actions = append(notification.Actions, toast.Action{
+			Type:      "protocol",
+			Label:     a.Label,
+			Arguments: escapeToastArgument(a.Uri),
+		})
+	}
+
+	switch n.Priority {
+	case PriorityLow:
+		notification.Audio = toast.Silent
+	case PriorityCritical:
+		notification.Duration = toast.Long
 	}
 
 	if err := notification.Push(); err != nil {
@@ -85,3 +97,7 @@ func (w *windowsNotifier) SendNotification(n Notification) error {
 
 	return nil
 }
+
+func escapeToastArgument(arg string) string {
+	return strings.ReplaceAll(arg, "&", "&amp;")
+}
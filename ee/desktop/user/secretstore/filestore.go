@@ -0,0 +1,180 @@
+package secretstore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	fileStoreKeyFilename = "secrets.key"
+	fileStoreSuffix      = ".secret"
+	fileStoreKeySize     = 32
+)
+
+// fileStore stores each secret in its own AES-GCM encrypted file. The encryption key is
+// stored alongside, readable only by the user. Only on Windows is the key additionally protected
+// (see protectKey); elsewhere, Backend reports the store as file-only.
+type fileStore struct {
+	dir  string
+	lock sync.Mutex
+	aead cipher.AEAD
+}
+
+func newFileStore(dir string) (*fileStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("creating secret directory: %w", err)
+	}
+
+	key, err := loadOrCreateFileStoreKey(filepath.Join(dir, fileStoreKeyFilename))
+	if err != nil {
+		return nil, fmt.Errorf("loading encryption key: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("creating cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("creating gcm: %w", err)
+	}
+
+	return &fileStore{
+		dir:  dir,
+		aead: aead,
+	}, nil
+}
+
+func loadOrCreateFileStoreKey(keyPath string) ([]byte, error) {
+	protectedKey, err := os.ReadFile(keyPath)
+	if err == nil {
+		key, err := unprotectKey(protectedKey)
+		if err != nil {
+			return nil, fmt.Errorf("unprotecting key: %w", err)
+		}
+		if len(key) != fileStoreKeySize {
+			return nil, fmt.Errorf("key has unexpected length %d", len(key))
+		}
+		return key, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("reading key: %w", err)
+	}
+
+	key := make([]byte, fileStoreKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("generating key: %w", err)
+	}
+	protectedKey, err = protectKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("protecting key: %w", err)
+	}
+	if err := os.WriteFile(keyPath, protectedKey, 0600); err != nil {
+		return nil, fmt.Errorf("writing key: %w", err)
+	}
+
+	return key, nil
+}
+
+func (f *fileStore) Backend() string {
+	return fileStoreBackend
+}
+
+// secretPath hashes the key, so that key names are not visible on disk.
+func (f *fileStore) secretPath(key string) string {
+	hashedKey := sha256.Sum256([]byte(key))
+	return filepath.Join(f.dir, hex.EncodeToString(hashedKey[:])+fileStoreSuffix)
+}
+
+func (f *fileStore) Get(key string) ([]byte, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, err
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	ciphertext, err := os.ReadFile(f.secretPath(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading secret: %w", err)
+	}
+
+	nonceSize := f.aead.NonceSize()
+	if len(ciphertext) < nonceSize {
+		return nil, errors.New("secret file is truncated")
+	}
+
+	// The key is bound to the ciphertext as additional data, so that secret files cannot be swapped
+	plaintext, err := f.aead.Open(nil, ciphertext[:nonceSize], ciphertext[nonceSize:], []byte(key))
+	if err != nil {
+		return nil, fmt.Errorf("decrypting secret: %w", err)
+	}
+
+	return plaintext, nil
+}
+
+func (f *fileStore) Set(key string, value []byte) error {
+	if err := validateSecret(key, value); err != nil {
+		return err
+	}
+
+	nonce := make([]byte, f.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("generating nonce: %w", err)
+	}
+	ciphertext := f.aead.Seal(nonce, nonce, value, []byte(key))
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	// Write to a temp file and rename, so that a partial write never replaces a good secret
+	tmpFile, err := os.CreateTemp(f.dir, "secret-*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating temp file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(ciphertext); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("writing secret: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("closing secret file: %w", err)
+	}
+	if err := os.Rename(tmpFile.Name(), f.secretPath(key)); err != nil {
+		return fmt.Errorf("replacing secret file: %w", err)
+	}
+
+	return nil
+}
+
+func (f *fileStore) Delete(key string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	err := os.Remove(f.secretPath(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("removing secret: %w", err)
+	}
+
+	return nil
+}
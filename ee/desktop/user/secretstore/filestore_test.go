@@ -0,0 +1,100 @@
+package secretstore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+func TestFileStore(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	store, err := newFileStore(dir)
+	require.NoError(t, err)
+	require.Equal(t, fileStoreBackend, store.Backend())
+
+	_, err = store.Get("device_trust_key")
+	require.ErrorIs(t, err, ErrNotFound)
+	require.ErrorIs(t, store.Delete("device_trust_key"), ErrNotFound)
+
+	require.NoError(t, store.Set("device_trust_key", []byte("first")))
+	require.NoError(t, store.Set("device_trust_key", []byte("second")))
+	value, err := store.Get("device_trust_key")
+	require.NoError(t, err)
+	require.Equal(t, []byte("second"), value)
+
+	// The secret should not be stored in plaintext, nor under its key name
+	matches, err := filepath.Glob(filepath.Join(dir, "*"+fileStoreSuffix))
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	require.NotContains(t, matches[0], "device_trust_key")
+	raw, err := os.ReadFile(matches[0])
+	require.NoError(t, err)
+	require.NotContains(t, string(raw), "second")
+
+	// A new store in the same directory should be able to read the secret
+	reopened, err := newFileStore(dir)
+	require.NoError(t, err)
+	value, err = reopened.Get("device_trust_key")
+	require.NoError(t, err)
+	require.Equal(t, []byte("second"), value)
+
+	require.NoError(t, store.Delete("device_trust_key"))
+	_, err = reopened.Get("device_trust_key")
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestFileStore_SwappedSecretFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	store, err := newFileStore(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Set("key_one", []byte("one")))
+	require.NoError(t, store.Set("key_two", []byte("two")))
+
+	// Copy key_one's ciphertext over key_two's; decryption should fail rather than return the wrong secret
+	raw, err := os.ReadFile(store.secretPath("key_one"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(store.secretPath("key_two"), raw, 0600))
+
+	_, err = store.Get("key_two")
+	require.Error(t, err)
+}
+
+func TestValidateSecret(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name        string
+		key         string
+		value       []byte
+		expectedErr error
+	}{
+		{name: "valid", key: "auth.token-1_v2", value: []byte("value")},
+		{name: "empty key", key: "", expectedErr: ErrInvalidKey},
+		{name: "path traversal", key: "../secrets", expectedErr: ErrInvalidKey},
+		{name: "key too long", key: string(make([]byte, 129)), expectedErr: ErrInvalidKey},
+		{name: "secret too large", key: "large", value: make([]byte, MaxSecretSize+1), expectedErr: ErrSecretTooLarge},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateSecret(tt.key, tt.value)
+			if tt.expectedErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
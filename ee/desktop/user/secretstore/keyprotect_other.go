@@ -0,0 +1,17 @@
+//go:build !windows
+
+package secretstore
+
+// fileStoreBackend reports that the file store is file-only here: without platform protection,
+// the key is stored in plaintext next to the secrets, so they are only protected by file permissions.
+const fileStoreBackend = "file"
+
+// protectKey does nothing outside of Windows; the key file is only readable by the user.
+func protectKey(key []byte) ([]byte, error) {
+	return key, nil
+}
+
+// unprotectKey does nothing outside of Windows; see protectKey.
+func unprotectKey(protectedKey []byte) ([]byte, error) {
+	return protectedKey, nil
+}
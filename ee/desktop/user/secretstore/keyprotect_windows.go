@@ -0,0 +1,47 @@
+//go:build windows
+
+package secretstore
+
+import (
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// fileStoreBackend reports that the file store is encrypted, since its key is protected by DPAPI.
+const fileStoreBackend = "encrypted_file"
+
+// protectKey encrypts the key with DPAPI, so that it can only be decrypted by the current user.
+func protectKey(key []byte) ([]byte, error) {
+	in := windows.DataBlob{Size: uint32(len(key)), Data: &key[0]}
+	var out windows.DataBlob
+	if err := windows.CryptProtectData(&in, nil, nil, 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out); err != nil {
+		return nil, fmt.Errorf("CryptProtectData: %w", err)
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(out.Data)))
+
+	return copyDataBlob(out), nil
+}
+
+// unprotectKey decrypts a key encrypted by protectKey.
+func unprotectKey(protectedKey []byte) ([]byte, error) {
+	if len(protectedKey) == 0 {
+		return nil, fmt.Errorf("protected key is empty")
+	}
+
+	in := windows.DataBlob{Size: uint32(len(protectedKey)), Data: &protectedKey[0]}
+	var out windows.DataBlob
+	if err := windows.CryptUnprotectData(&in, nil, nil, 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out); err != nil {
+		return nil, fmt.Errorf("CryptUnprotectData: %w", err)
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(out.Data)))
+
+	return copyDataBlob(out), nil
+}
+
+func copyDataBlob(blob windows.DataBlob) []byte {
+	data := make([]byte, blob.Size)
+	copy(data, unsafe.Slice(blob.Data, blob.Size))
+	return data
+}
@@ -0,0 +1,211 @@
+//go:build linux
+
+package secretstore
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	secretServiceName           = "org.freedesktop.secrets"
+	secretServicePath           = "/org/freedesktop/secrets"
+	secretServiceInterface      = "org.freedesktop.Secret.Service"
+	secretCollectionInterface   = "org.freedesktop.Secret.Collection"
+	secretItemInterface         = "org.freedesktop.Secret.Item"
+	secretItemLabelProperty     = "org.freedesktop.Secret.Item.Label"
+	secretItemAttributeProperty = "org.freedesktop.Secret.Item.Attributes"
+	secretApplicationAttribute  = "kolide-launcher"
+	noPrompt                    = dbus.ObjectPath("/")
+)
+
+var errSecretServiceLocked = errors.New("secret service collection is locked, and unlocking requires a user prompt")
+
+// secretServiceSecret is the Secret struct defined by the Secret Service API.
+type secretServiceSecret struct {
+	Session     dbus.ObjectPath
+	Parameters  []byte
+	Value       []byte
+	ContentType string
+}
+
+// secretServiceStore stores secrets in the user's default Secret Service collection (e.g.
+// GNOME Keyring or KWallet), via D-Bus. We use a plain session: secrets are only transferred
+// over the user's own session bus.
+type secretServiceStore struct {
+	lock       sync.Mutex
+	conn       *dbus.Conn
+	session    dbus.ObjectPath
+	collection dbus.ObjectPath
+}
+
+func newSecretServiceStore() (*secretServiceStore, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("connecting to session bus: %w", err)
+	}
+
+	service := conn.Object(secretServiceName, secretServicePath)
+
+	var sessionOutput dbus.Variant
+	var session dbus.ObjectPath
+	if err := service.Call(secretServiceInterface+".OpenSession", 0, "plain", dbus.MakeVariant("")).Store(&sessionOutput, &session); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("opening secret service session: %w", err)
+	}
+
+	var collection dbus.ObjectPath
+	if err := service.Call(secretServiceInterface+".ReadAlias", 0, "default").Store(&collection); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("reading default collection alias: %w", err)
+	}
+	if collection == noPrompt {
+		// Creating a collection requires prompting the user, so we won't do it
+		conn.Close()
+		return nil, errors.New("no default secret service collection")
+	}
+
+	return &secretServiceStore{
+		conn:       conn,
+		session:    session,
+		collection: collection,
+	}, nil
+}
+
+func (s *secretServiceStore) Backend() string {
+	return "secret_service"
+}
+
+func (s *secretServiceStore) attributes(key string) map[string]string {
+	return map[string]string{
+		"application": secretApplicationAttribute,
+		"key":         key,
+	}
+}
+
+// findItem returns the unlocked item holding the secret for key.
+func (s *secretServiceStore) findItem(key string) (dbus.ObjectPath, error) {
+	var unlocked, locked []dbus.ObjectPath
+	if err := s.conn.Object(secretServiceName, secretServicePath).Call(secretServiceInterface+".SearchItems", 0, s.attributes(key)).Store(&unlocked, &locked); err != nil {
+		return "", fmt.Errorf("searching for secret: %w", err)
+	}
+
+	if len(unlocked) > 0 {
+		return unlocked[0], nil
+	}
+	if len(locked) > 0 {
+		if err := s.unlock(locked[0]); err != nil {
+			return "", err
+		}
+		return locked[0], nil
+	}
+
+	return "", ErrNotFound
+}
+
+// unlock unlocks the given object, if that can be done without prompting the user.
+func (s *secretServiceStore) unlock(object dbus.ObjectPath) error {
+	var unlocked []dbus.ObjectPath
+	var prompt dbus.ObjectPath
+	if err := s.conn.Object(secretServiceName, secretServicePath).Call(secretServiceInterface+".Unlock", 0, []dbus.ObjectPath{object}).Store(&unlocked, &prompt); err != nil {
+		return fmt.Errorf("unlocking %s: %w", object, err)
+	}
+	if prompt != noPrompt {
+		return errSecretServiceLocked
+	}
+	return nil
+}
+
+func (s *secretServiceStore) Get(key string) ([]byte, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	item, err := s.findItem(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var secret secretServiceSecret
+	if err := s.conn.Object(secretServiceName, item).Call(secretItemInterface+".GetSecret", 0, s.session).Store(&secret); err != nil {
+		return nil, fmt.Errorf("getting secret: %w", err)
+	}
+
+	return secret.Value, nil
+}
+
+func (s *secretServiceStore) Set(key string, value []byte) error {
+	if err := validateSecret(key, value); err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	properties := map[string]dbus.Variant{
+		secretItemLabelProperty:     dbus.MakeVariant(fmt.Sprintf("Kolide: %s", key)),
+		secretItemAttributeProperty: dbus.MakeVariant(s.attributes(key)),
+	}
+	secret := secretServiceSecret{
+		Session:     s.session,
+		Parameters:  []byte{},
+		Value:       value,
+		ContentType: "application/octet-stream",
+	}
+
+	collection := s.conn.Object(secretServiceName, s.collection)
+	var item, prompt dbus.ObjectPath
+	call := collection.Call(secretCollectionInterface+".CreateItem", 0, properties, secret, true)
+	if call.Err != nil {
+		return fmt.Errorf("creating secret: %w", call.Err)
+	}
+	if err := call.Store(&item, &prompt); err != nil {
+		return fmt.Errorf("reading create secret response: %w", err)
+	}
+	if prompt == noPrompt {
+		return nil
+	}
+
+	// The collection is locked -- try to unlock it and create the item again
+	if err := s.unlock(s.collection); err != nil {
+		return err
+	}
+	if err := collection.Call(secretCollectionInterface+".CreateItem", 0, properties, secret, true).Store(&item, &prompt); err != nil {
+		return fmt.Errorf("creating secret after unlocking: %w", err)
+	}
+	if prompt != noPrompt {
+		return errSecretServiceLocked
+	}
+
+	return nil
+}
+
+func (s *secretServiceStore) Delete(key string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	item, err := s.findItem(key)
+	if err != nil {
+		return err
+	}
+
+	var prompt dbus.ObjectPath
+	if err := s.conn.Object(secretServiceName, item).Call(secretItemInterface+".Delete", 0).Store(&prompt); err != nil {
+		return fmt.Errorf("deleting secret: %w", err)
+	}
+	if prompt != noPrompt {
+		return errSecretServiceLocked
+	}
+
+	return nil
+}
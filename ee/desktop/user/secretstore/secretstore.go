@@ -0,0 +1,53 @@
+// Package secretstore persists secrets on behalf of the console user running launcher desktop,
+// so that per-user secrets (e.g. device trust keys, cached auth tokens) are available to root
+// launcher via the desktop user server. Each platform uses the best backend available to it,
+// falling back to files in the user's desktop directory. The file store's key is only protected
+// on Windows, so elsewhere it reports itself as file-only.
+package secretstore
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// MaxSecretSize is the largest secret that any store will accept.
+const MaxSecretSize = 64 * 1024
+
+var (
+	ErrNotFound       = errors.New("secret not found")
+	ErrInvalidKey     = errors.New("invalid secret key")
+	ErrSecretTooLarge = fmt.Errorf("secret exceeds maximum size of %d bytes", MaxSecretSize)
+
+	validKeyPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+)
+
+// Store persists secrets for the current user.
+type Store interface {
+	// Get returns the secret stored under key, or ErrNotFound.
+	Get(key string) ([]byte, error)
+	// Set stores value under key, replacing any existing secret.
+	Set(key string, value []byte) error
+	// Delete removes the secret stored under key, returning ErrNotFound if there is none.
+	Delete(key string) error
+	// Backend names the storage backend, for logging and debugging.
+	Backend() string
+}
+
+// ValidateKey checks that the key is safe to use in file names and backend attributes.
+func ValidateKey(key string) error {
+	if !validKeyPattern.MatchString(key) {
+		return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	return nil
+}
+
+func validateSecret(key string, value []byte) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	if len(value) > MaxSecretSize {
+		return ErrSecretTooLarge
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+//go:build linux
+
+package secretstore
+
+import (
+	"context"
+	"log/slog"
+)
+
+// New returns a store backed by the Secret Service, if one is running on the user's session
+// bus with a default collection available; otherwise, it falls back to a file store
+// in fallbackDir.
+func New(slogger *slog.Logger, fallbackDir string) (Store, error) {
+	secretServiceStore, err := newSecretServiceStore()
+	if err == nil {
+		return secretServiceStore, nil
+	}
+
+	slogger.Log(context.TODO(), slog.LevelInfo,
+		"secret service unavailable, falling back to file store",
+		"err", err,
+	)
+
+	return newFileStore(fallbackDir)
+}
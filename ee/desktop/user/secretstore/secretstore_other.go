@@ -0,0 +1,12 @@
+//go:build !linux
+
+package secretstore
+
+import (
+	"log/slog"
+)
+
+// New returns a file store in dir.
+func New(_ *slog.Logger, dir string) (Store, error) {
+	return newFileStore(dir)
+}
@@ -6,6 +6,7 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -20,6 +21,7 @@ import (
 
 	"github.com/kolide/launcher/v2/ee/agent"
 	"github.com/kolide/launcher/v2/ee/desktop/user/notify"
+	"github.com/kolide/launcher/v2/ee/desktop/user/secretstore"
 	"github.com/kolide/launcher/v2/ee/presencedetection"
 	"github.com/kolide/launcher/v2/pkg/backoff"
 )
@@ -39,13 +41,16 @@ type UserServer struct {
 	socketPath          string
 	notifier            notificationSender
 	refreshListeners    []func()
-	presenceDetector    presencedetection.PresenceDetector
+	presenceDetector    *presencedetection.PresenceDetector
 	showDesktopOnceFunc func()
+	secretStore         secretstore.Store
+	secretStoreLock     sync.RWMutex
 }
 
 func New(slogger *slog.Logger,
 	authToken string,
 	socketPath string,
+	identifier string,
 	shutdownChan chan<- struct{},
 	showDesktopChan chan<- struct{},
 	notifier notificationSender) (*UserServer, error) {
@@ -55,6 +60,9 @@ func New(slogger *slog.Logger,
 		slogger:      slogger.With("component", "desktop_server"),
 		socketPath:   socketPath,
 		notifier:     notifier,
+		presenceDetector: &presencedetection.PresenceDetector{
+			Identifier: identifier,
+		},
 		showDesktopOnceFunc: sync.OnceFunc(func() {
 			if showDesktopChan == nil {
 				return
@@ -72,6 +80,9 @@ func New(slogger *slog.Logger,
 	authedMux.HandleFunc("/detect_presence", userServer.detectPresence)
 	authedMux.HandleFunc("POST /secure_enclave_key", userServer.createSecureEnclaveKey)
 	authedMux.HandleFunc("GET /secure_enclave_key", userServer.getSecureEnclaveKey)
+	authedMux.HandleFunc("GET /secret", userServer.getSecret)
+	authedMux.HandleFunc("PUT /secret", userServer.setSecret)
+	authedMux.HandleFunc("DELETE /secret", userServer.deleteSecret)
 	authedMux.HandleFunc("POST /cpuprofile", userServer.cpuProfileHandler)
 	authedMux.HandleFunc("POST /memprofile", userServer.memProfileHandler)
 
@@ -255,6 +266,97 @@ func (s *UserServer) notifyRefreshListeners() {
 	}
 }
 
+// SetSecretStore sets the store backing the secret endpoints; until it is set,
+// secret requests will receive a 503.
+func (s *UserServer) SetSecretStore(store secretstore.Store) {
+	s.secretStoreLock.Lock()
+	defer s.secretStoreLock.Unlock()
+	s.secretStore = store
+}
+
+func (s *UserServer) getSecretStore() secretstore.Store {
+	s.secretStoreLock.RLock()
+	defer s.secretStoreLock.RUnlock()
+	return s.secretStore
+}
+
+type SecretResponse struct {
+	Value   []byte `json:"value"`
+	Backend string `json:"backend"`
+}
+
+func (s *UserServer) getSecret(w http.ResponseWriter, req *http.Request) {
+	store := s.getSecretStore()
+	if store == nil {
+		http.Error(w, "secret store unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	value, err := store.Get(req.URL.Query().Get("key"))
+	if err != nil {
+		s.writeSecretError(w, req, "getting secret", err)
+		return
+	}
+
+	s.respondWithJSON(w, SecretResponse{
+		Value:   value,
+		Backend: store.Backend(),
+	})
+}
+
+func (s *UserServer) setSecret(w http.ResponseWriter, req *http.Request) {
+	store := s.getSecretStore()
+	if store == nil {
+		http.Error(w, "secret store unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	defer req.Body.Close()
+	// Read one byte past the limit so that the store can reject oversized secrets
+	value, err := io.ReadAll(io.LimitReader(req.Body, secretstore.MaxSecretSize+1))
+	if err != nil {
+		http.Error(w, "could not read body", http.StatusBadRequest)
+		return
+	}
+
+	if err := store.Set(req.URL.Query().Get("key"), value); err != nil {
+		s.writeSecretError(w, req, "setting secret", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *UserServer) deleteSecret(w http.ResponseWriter, req *http.Request) {
+	store := s.getSecretStore()
+	if store == nil {
+		http.Error(w, "secret store unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	if err := store.Delete(req.URL.Query().Get("key")); err != nil {
+		s.writeSecretError(w, req, "deleting secret", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *UserServer) writeSecretError(w http.ResponseWriter, req *http.Request, msg string, err error) {
+	switch {
+	case errors.Is(err, secretstore.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, secretstore.ErrInvalidKey), errors.Is(err, secretstore.ErrSecretTooLarge):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		s.slogger.Log(req.Context(), slog.LevelError,
+			msg,
+			"err", err,
+		)
+		http.Error(w, msg, http.StatusInternalServerError)
+	}
+}
+
 type ProfileResponse struct {
 	FilePath string `json:"file_path"`
 	Error    string `json:"error,omitempty"`
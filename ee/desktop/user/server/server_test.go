@@ -2,6 +2,7 @@ package server
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -13,6 +14,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/kolide/launcher/v2/ee/desktop/user/secretstore"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/goleak"
@@ -116,6 +118,81 @@ func TestUserServer_shutdownHandler(t *testing.T) {
 	}
 }
 
+func TestUserServer_secretHandlers(t *testing.T) {
+	t.Parallel()
+
+	var logBytes bytes.Buffer
+	server, _ := testServer(t, validAuthHeader, testSocketPath(t), &logBytes)
+	handler := server.server.Handler
+
+	doRequest := func(method string, key string, body []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequestWithContext(t.Context(), method, "http://unix/secret?key="+key, bytes.NewReader(body))
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", validAuthHeader))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// No store set yet
+	require.Equal(t, http.StatusServiceUnavailable, doRequest(http.MethodGet, "test_key", nil).Code)
+
+	server.SetSecretStore(&testSecretStore{secrets: make(map[string][]byte)})
+
+	require.Equal(t, http.StatusNotFound, doRequest(http.MethodGet, "test_key", nil).Code)
+	require.Equal(t, http.StatusBadRequest, doRequest(http.MethodPut, "bad%2Fkey", []byte("value")).Code)
+	require.Equal(t, http.StatusBadRequest, doRequest(http.MethodPut, "test_key", make([]byte, secretstore.MaxSecretSize+1)).Code)
+	require.Equal(t, http.StatusOK, doRequest(http.MethodPut, "test_key", []byte("value")).Code)
+
+	rr := doRequest(http.MethodGet, "test_key", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	var resp SecretResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Equal(t, []byte("value"), resp.Value)
+	require.Equal(t, "test", resp.Backend)
+
+	require.Equal(t, http.StatusOK, doRequest(http.MethodDelete, "test_key", nil).Code)
+	require.Equal(t, http.StatusNotFound, doRequest(http.MethodDelete, "test_key", nil).Code)
+
+	require.NoError(t, server.Shutdown(t.Context()))
+
+	time.Sleep(5 * time.Second) // wait for removeSocket to finish
+}
+
+type testSecretStore struct {
+	secrets map[string][]byte
+}
+
+func (s *testSecretStore) Get(key string) ([]byte, error) {
+	value, ok := s.secrets[key]
+	if !ok {
+		return nil, secretstore.ErrNotFound
+	}
+	return value, nil
+}
+
+func (s *testSecretStore) Set(key string, value []byte) error {
+	if err := secretstore.ValidateKey(key); err != nil {
+		return err
+	}
+	if len(value) > secretstore.MaxSecretSize {
+		return secretstore.ErrSecretTooLarge
+	}
+	s.secrets[key] = value
+	return nil
+}
+
+func (s *testSecretStore) Delete(key string) error {
+	if _, ok := s.secrets[key]; !ok {
+		return secretstore.ErrNotFound
+	}
+	delete(s.secrets, key)
+	return nil
+}
+
+func (s *testSecretStore) Backend() string {
+	return "test"
+}
+
 func testHandler() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte(r.URL.String()))
@@ -130,7 +207,7 @@ func testServer(t *testing.T, authHeader, socketPath string, logBytes *bytes.Buf
 		Level:     slog.LevelDebug,
 	}))
 
-	server, err := New(slogger, authHeader, socketPath, shutdownChan, make(chan<- struct{}), nil)
+	server, err := New(slogger, authHeader, socketPath, "kolide-k2", shutdownChan, make(chan<- struct{}), nil)
 	require.NoError(t, err)
 	return server, shutdownChan
 }
@@ -40,6 +40,7 @@ const (
 	kolidePresenceDetectionIntervalHeaderKey          = "X-Kolide-Presence-Detection-Interval"
 	kolidePresenceDetectionReasonMacosHeaderKey       = "X-Kolide-Presence-Detection-Reason-Macos"
 	kolidePresenceDetectionReasonWindowsHeaderKey     = "X-Kolide-Presence-Detection-Reason-Windows"
+	kolidePresenceDetectionReasonLinuxHeaderKey       = "X-Kolide-Presence-Detection-Reason-Linux"
 	kolideDurationSinceLastPresenceDetectionHeaderKey = "X-Kolide-Duration-Since-Last-Presence-Detection"
 	kolideOsHeaderKey                                 = "X-Kolide-Os"
 	kolideArchHeaderKey                               = "X-Kolide-Arch"
@@ -459,7 +460,6 @@ func (e *kryptoEcMiddleware) Wrap(next http.Handler) http.Handler {
 
 		// bhr contains the data returned by the request defined above
 		bhr := &bufferedHttpResponse{}
-		next.ServeHTTP(bhr, newReq)
 
 		if len(kolideSessionId) > 0 {
 			bhr.Header().Add(kolideSessionIdHeaderKey, kolideSessionId[0])
@@ -468,6 +468,105 @@ func (e *kryptoEcMiddleware) Wrap(next http.Handler) http.Handler {
 		bhr.Header().Add(kolideOsHeaderKey, runtime.GOOS)
 		bhr.Header().Add(kolideArchHeaderKey, runtime.GOARCH)
 
+		// Handlers that stream their response (e.g. /query with stream set) flush after each chunk.
+		// We box each chunk individually, and write it as its own base64-encoded line. Streaming
+		// isn't supported for png responses, so those are always buffered in full.
+		// The callback can only carry a single response, so we also accumulate the chunks, and box
+		// the complete response for the callback. Since the chunks together are not a single JSON
+		// document, the callback carries them as the response body.
+		// Each chunk carries a sequence number, so that clients can tell if any are missing. If a chunk
+		// cannot be boxed, we end the stream with a final error chunk in its place, and stop the handler.
+		fullResponse := &bufferedHttpResponse{}
+		wroteChunk := false
+		streamFailed := false
+		sequence := 0
+		handlerCtx, cancelHandler := context.WithCancel(newReq.Context())
+		defer cancelHandler()
+		newReq = newReq.WithContext(handlerCtx)
+		if !strings.HasSuffix(cmdReq.Path, ".png") {
+			writeChunk := func(chunk *bufferedHttpResponse) error {
+				chunkResponse, err := e.bufferedHttpResponseToKryptoResponse(challengeBox, chunk)
+				if err != nil {
+					return err
+				}
+
+				if !wroteChunk {
+					w.Header().Add(kolideKryptoHeaderKey, kolideKryptoEccHeader20230130Value)
+					wroteChunk = true
+				}
+				w.Write([]byte(base64.StdEncoding.EncodeToString(chunkResponse) + "\n"))
+				_ = http.NewResponseController(w).Flush()
+				return nil
+			}
+
+			bhr.flushChunk = func(chunk *bufferedHttpResponse) {
+				if streamFailed {
+					return
+				}
+
+				chunkSequence := sequence
+				sequence += 1
+				err := writeChunk(chunk)
+				if err == nil {
+					fullResponse.Write(chunk.Bytes())
+					return
+				}
+
+				observability.SetError(span, err)
+				e.slogger.Log(r.Context(), slog.LevelError,
+					"error creating krypto response for chunk, ending stream",
+					"err", err,
+					"sequence", chunkSequence,
+				)
+				streamFailed = true
+				cancelHandler()
+
+				errChunkBytes, err := json.Marshal(streamErrorChunk{
+					Sequence: chunkSequence,
+					Done:     true,
+					Error:    fmt.Sprintf("error creating krypto response for chunk %d", chunkSequence),
+				})
+				if err != nil {
+					e.slogger.Log(r.Context(), slog.LevelError,
+						"error marshalling stream error chunk",
+						"err", err,
+					)
+					return
+				}
+				fullResponse.Write(errChunkBytes)
+
+				errChunk := &bufferedHttpResponse{header: chunk.header}
+				errChunk.Write(errChunkBytes)
+				if err := writeChunk(errChunk); err != nil {
+					e.slogger.Log(r.Context(), slog.LevelError,
+						"error creating krypto response for stream error chunk",
+						"err", err,
+					)
+				}
+			}
+		}
+
+		next.ServeHTTP(bhr, newReq)
+
+		if bhr.chunked {
+			// Anything written after the last flush becomes the final chunk
+			bhr.Flush()
+
+			fullResponse.header = bhr.header
+			fullResponse.code = bhr.code
+			callbackResponse, err := e.bufferedHttpResponseToKryptoResponse(challengeBox, fullResponse)
+			if err != nil {
+				observability.SetError(span, err)
+				e.slogger.Log(r.Context(), slog.LevelError,
+					"error creating krypto response for callback",
+					"err", err,
+				)
+				return
+			}
+			callbackData.Response = base64.StdEncoding.EncodeToString(callbackResponse)
+			return
+		}
+
 		response, err := e.bufferedHttpResponseToKryptoResponse(challengeBox, bhr)
 		if err != nil {
 			observability.SetError(span, err)
@@ -540,10 +639,23 @@ func extractChallenge(r *http.Request) (*challenge.OuterChallenge, error) {
 	return challenge.UnmarshalChallenge(decoded)
 }
 
+// streamErrorChunk ends a streamed response when a chunk could not be sent. It takes the place,
+// and the sequence number, of the chunk that failed.
+type streamErrorChunk struct {
+	Sequence int    `json:"sequence"`
+	Done     bool   `json:"done"`
+	Error    string `json:"error"`
+}
+
 type bufferedHttpResponse struct {
 	header http.Header
 	code   int
 	buf    bytes.Buffer
+
+	// flushChunk, if set, is called on Flush to send the response buffered so far as a chunk,
+	// after which the buffer is reset. If unset, Flush is a no-op.
+	flushChunk func(*bufferedHttpResponse)
+	chunked    bool // true once any chunk has been sent
 }
 
 func (bhr *bufferedHttpResponse) Header() http.Header {
@@ -566,6 +678,17 @@ func (bhr *bufferedHttpResponse) Bytes() []byte {
 	return bhr.buf.Bytes()
 }
 
+// Flush implements http.Flusher, for handlers that stream their response in chunks.
+func (bhr *bufferedHttpResponse) Flush() {
+	if bhr.flushChunk == nil || bhr.buf.Len() == 0 {
+		return
+	}
+
+	bhr.flushChunk(bhr)
+	bhr.chunked = true
+	bhr.buf.Reset()
+}
+
 // detectPresence prompts the user for presence detection and sends periodic status updates to
 // k2 (waiting , complete, error, timeout)
 func (e *kryptoEcMiddleware) detectPresence(challengeBox *challenge.OuterChallenge) {
@@ -573,11 +696,6 @@ func (e *kryptoEcMiddleware) detectPresence(challengeBox *challenge.OuterChallen
 	ctx, cancel := context.WithTimeout(context.Background(), presencedetection.DetectionTimeout)
 	defer cancel()
 
-	// presence detection is not yet available on linux
-	if runtime.GOOS == "linux" {
-		return
-	}
-
 	var cmdReq v2CmdRequestType
 	if err := json.Unmarshal(challengeBox.RequestData(), &cmdReq); err != nil {
 		e.slogger.Log(ctx, slog.LevelError,
@@ -606,10 +724,16 @@ func (e *kryptoEcMiddleware) detectPresence(challengeBox *challenge.OuterChallen
 	reason := "authenticate"
 
 	reasonKey := kolidePresenceDetectionReasonMacosHeaderKey
-	if runtime.GOOS == "windows" {
+	switch runtime.GOOS {
+	case "windows":
 		// On windows presence detection text is expected to be a full sentence
 		reason = "Kolide is requesting authentication"
 		reasonKey = kolidePresenceDetectionReasonWindowsHeaderKey
+	case "linux":
+		// On linux, the reason is only shown when prompting for a fingerprint via notification
+		// (the polkit dialog shows the message from our installed policy), so it should be a full sentence
+		reason = "Kolide is requesting authentication"
+		reasonKey = kolidePresenceDetectionReasonLinuxHeaderKey
 	}
 
 	if reasonStr, ok := cmdReq.CallbackHeaders[reasonKey]; ok && len(reasonStr) > 0 && len(reasonStr[0]) > 0 {
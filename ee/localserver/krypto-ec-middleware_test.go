@@ -2,10 +2,12 @@ package localserver
 
 import (
 	"bytes"
+	"crypto"
 	"crypto/ecdsa"
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -178,8 +180,7 @@ func TestKryptoEcMiddleware(t *testing.T) {
 			simulatedPresenceDetectionCompletionTime := 1 * time.Second
 
 			// assume that if we have presence detection headers, we should have a presence detection callback
-			// presence detection is not yet available on linux
-			if tt.expectedPresenceDetectionCallbackHeaders != nil && runtime.GOOS != "linux" {
+			if tt.expectedPresenceDetectionCallbackHeaders != nil {
 
 				// we fire off one call back per request immediately when presence detection is starts
 				expectedCallbacks += len(requests)
@@ -204,12 +205,10 @@ func TestKryptoEcMiddleware(t *testing.T) {
 
 					mockPresenceDetector := mocks.NewPresenceDetector(t)
 
-					if runtime.GOOS != "linux" { // only doing persence detection on windows and macos for now
-						mockPresenceDetector.On("DetectPresence", mock.AnythingOfType("string"), mock.AnythingOfType("Duration")).
-							After(simulatedPresenceDetectionCompletionTime).
-							Return(0*time.Second, nil).
-							Once()
-					}
+					mockPresenceDetector.On("DetectPresence", mock.AnythingOfType("string"), mock.AnythingOfType("Duration")).
+						After(simulatedPresenceDetectionCompletionTime).
+						Return(0*time.Second, nil).
+						Once()
 
 					k := typesmocks.NewKnapsack(t)
 					k.On("PersistAgentIngesterKeys", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return().Maybe()
@@ -273,6 +272,176 @@ func TestKryptoEcMiddleware(t *testing.T) {
 	}
 }
 
+func TestKryptoEcMiddleware_StreamedChunks(t *testing.T) {
+	t.Parallel()
+
+	remoteServerPrivateKey := mustGenEcdsaKey(t)
+	localServerPrivateKey := mustGenEcdsaKey(t)
+
+	challengeId := []byte(ulid.New())
+	challengeData := []byte(ulid.New())
+
+	// The callback gets the complete response
+	callbackBodies := make(chan []byte, 1)
+	callbackServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		callbackBodies <- body
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(callbackServer.Close)
+
+	cmdReq := v2CmdRequestType{
+		Path: "query",
+		Headers: map[string][]string{
+			kolideMunemoHeaderKey: {"test-munemo"},
+		},
+		CallbackUrl: callbackServer.URL,
+	}
+	challengeKryptoBoxB64, challengePrivateKey := mustGenerateChallenge(t, remoteServerPrivateKey, challengeId, challengeData, mustMarshal(t, cmdReq))
+
+	k := typesmocks.NewKnapsack(t)
+	k.On("PersistAgentIngesterKeys", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return().Maybe()
+
+	kryptoEcMiddleware := newKryptoEcMiddleware(multislogger.NewNopLogger(), k, localServerPrivateKey, remoteServerPrivateKey.PublicKey, mocks.NewPresenceDetector(t), "test-munemo")
+	t.Cleanup(func() {
+		kryptoEcMiddleware.Close()
+	})
+
+	chunks := []string{"first", "second", "third"}
+
+	rr := httptest.NewRecorder()
+	kryptoEcMiddleware.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i, chunk := range chunks {
+			w.Write(mustMarshal(t, map[string]string{"chunk": chunk}))
+			// The final chunk is sent without an explicit flush
+			if i < len(chunks)-1 {
+				w.(http.Flusher).Flush()
+			}
+		}
+	})).ServeHTTP(rr, mustMakeGetRequest(t, challengeKryptoBoxB64))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, kolideKryptoEccHeader20230130Value, rr.Header().Get(kolideKryptoHeaderKey))
+
+	// Each chunk is individually boxed, on its own line
+	lines := strings.Split(strings.TrimSuffix(rr.Body.String(), "\n"), "\n")
+	require.Len(t, lines, len(chunks))
+	for i, line := range lines {
+		outerResponse := mustUnmarshallOuterResponse(t, line)
+		require.Equal(t, challengeId, outerResponse.ChallengeId)
+
+		opened, err := outerResponse.Open(challengePrivateKey)
+		require.NoError(t, err)
+		require.Equal(t, challengeData, opened.ChallengeData)
+		require.Equal(t, chunks[i], mustExtractJsonProperty[string](t, opened.ResponseData, "chunk"))
+
+		responseHeaders := mustExtractJsonProperty[map[string][]string](t, opened.ResponseData, "headers")
+		require.Equal(t, []string{runtime.GOOS}, responseHeaders[kolideOsHeaderKey])
+	}
+
+	var expectedCallbackBody strings.Builder
+	for _, chunk := range chunks {
+		expectedCallbackBody.Write(mustMarshal(t, map[string]string{"chunk": chunk}))
+	}
+
+	select {
+	case callbackBody := <-callbackBodies:
+		outerResponse := mustUnmarshallOuterResponse(t, mustExtractJsonProperty[string](t, callbackBody, "Response"))
+		opened, err := outerResponse.Open(challengePrivateKey)
+		require.NoError(t, err)
+		require.Equal(t, expectedCallbackBody.String(), mustExtractJsonProperty[string](t, opened.ResponseData, "body"))
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for callback")
+	}
+}
+
+// failingSigner fails every signature while failing is set.
+type failingSigner struct {
+	crypto.Signer
+	failing atomic.Bool
+}
+
+func (f *failingSigner) Sign(r io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if f.failing.Load() {
+		return nil, errors.New("test signing failure")
+	}
+	return f.Signer.Sign(r, digest, opts)
+}
+
+// logWatcher is called with each line logged.
+type logWatcher func(line []byte)
+
+func (l logWatcher) Write(p []byte) (int, error) {
+	l(p)
+	return len(p), nil
+}
+
+func TestKryptoEcMiddleware_StreamedChunkError(t *testing.T) {
+	t.Parallel()
+
+	remoteServerPrivateKey := mustGenEcdsaKey(t)
+	signer := &failingSigner{Signer: mustGenEcdsaKey(t)}
+
+	challengeId := []byte(ulid.New())
+	challengeData := []byte(ulid.New())
+
+	cmdReq := v2CmdRequestType{
+		Path: "query",
+		Headers: map[string][]string{
+			kolideMunemoHeaderKey: {"test-munemo"},
+		},
+	}
+	challengeKryptoBoxB64, challengePrivateKey := mustGenerateChallenge(t, remoteServerPrivateKey, challengeId, challengeData, mustMarshal(t, cmdReq))
+
+	k := typesmocks.NewKnapsack(t)
+	k.On("PersistAgentIngesterKeys", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return().Maybe()
+
+	// Signing recovers once the failed chunk is logged, so that the error chunk can be boxed
+	slogger := slog.New(slog.NewTextHandler(logWatcher(func(line []byte) {
+		if bytes.Contains(line, []byte("ending stream")) {
+			signer.failing.Store(false)
+		}
+	}), nil))
+
+	kryptoEcMiddleware := newKryptoEcMiddleware(slogger, k, signer, remoteServerPrivateKey.PublicKey, mocks.NewPresenceDetector(t), "test-munemo")
+	t.Cleanup(func() {
+		kryptoEcMiddleware.Close()
+	})
+
+	handlerStopped := false
+	rr := httptest.NewRecorder()
+	kryptoEcMiddleware.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := range 3 {
+			if r.Context().Err() != nil {
+				handlerStopped = true
+				return
+			}
+			// The second chunk cannot be boxed
+			signer.failing.Store(i == 1)
+			w.Write(mustMarshal(t, map[string]any{"sequence": i, "done": i == 2}))
+			w.(http.Flusher).Flush()
+		}
+	})).ServeHTTP(rr, mustMakeGetRequest(t, challengeKryptoBoxB64))
+
+	require.True(t, handlerStopped, "handler should be stopped after a chunk fails")
+
+	// The first chunk, then an error chunk in place of the second, which ends the stream
+	lines := strings.Split(strings.TrimSuffix(rr.Body.String(), "\n"), "\n")
+	require.Len(t, lines, 2)
+
+	opened, err := mustUnmarshallOuterResponse(t, lines[0]).Open(challengePrivateKey)
+	require.NoError(t, err)
+	require.Equal(t, 0, mustExtractJsonProperty[int](t, opened.ResponseData, "sequence"))
+	require.False(t, mustExtractJsonProperty[bool](t, opened.ResponseData, "done"))
+
+	opened, err = mustUnmarshallOuterResponse(t, lines[1]).Open(challengePrivateKey)
+	require.NoError(t, err)
+	require.Equal(t, 1, mustExtractJsonProperty[int](t, opened.ResponseData, "sequence"))
+	require.True(t, mustExtractJsonProperty[bool](t, opened.ResponseData, "done"))
+	require.NotEmpty(t, mustExtractJsonProperty[string](t, opened.ResponseData, "error"))
+}
+
 func TestKryptoEcMiddlewareErrors(t *testing.T) {
 	t.Parallel()
 
@@ -105,6 +105,24 @@ func (ls *localServer) requestIdHandlerFunc(w http.ResponseWriter, r *http.Reque
 	r, span := observability.StartHttpRequestSpan(r, "path", r.URL.Path)
 	defer span.End()
 
+	response := ls.idsResponse(r.Header.Get("Origin"))
+
+	jsonBytes, err := json.Marshal(response)
+	if err != nil {
+		observability.SetError(span, err)
+		ls.slogger.Log(r.Context(), slog.LevelError,
+			"marshaling json",
+			"err", err,
+		)
+
+		jsonBytes = fmt.Appendf(nil, "unable to marshal json: %v", err)
+	}
+
+	w.Write(jsonBytes)
+}
+
+// idsResponse builds the response for an ID request from the given origin.
+func (ls *localServer) idsResponse(origin string) requestIdsResponse {
 	enrollmentStatus, _ := ls.knapsack.CurrentEnrollmentStatus()
 	enrollmentDetails := ls.knapsack.GetEnrollmentDetails()
 
@@ -121,7 +139,7 @@ func (ls *localServer) requestIdHandlerFunc(w http.ResponseWriter, r *http.Reque
 	response := requestIdsResponse{
 		Nonce:     ulid.New(),
 		Timestamp: time.Now(),
-		Origin:    r.Header.Get("Origin"),
+		Origin:    origin,
 		Status: status{
 			EnrollmentStatus: string(enrollmentStatus),
 		},
@@ -130,16 +148,5 @@ func (ls *localServer) requestIdHandlerFunc(w http.ResponseWriter, r *http.Reque
 	}
 	response.identifiers = ls.identifiers
 
-	jsonBytes, err := json.Marshal(response)
-	if err != nil {
-		observability.SetError(span, err)
-		ls.slogger.Log(r.Context(), slog.LevelError,
-			"marshaling json",
-			"err", err,
-		)
-
-		jsonBytes = fmt.Appendf(nil, "unable to marshal json: %v", err)
-	}
-
-	w.Write(jsonBytes)
+	return response
 }
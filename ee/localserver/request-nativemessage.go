@@ -0,0 +1,107 @@
+package localserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kolide/launcher/v2/ee/nativemessaging"
+	"github.com/kolide/launcher/v2/ee/observability"
+)
+
+const (
+	defaultNativeMessagePresenceReason   = "Kolide is requesting authentication"
+	defaultNativeMessagePresenceInterval = 1 * time.Minute
+)
+
+type nativeMessagePresenceDetectionResponse struct {
+	DurationSinceLastDetection time.Duration `json:"duration_since_last_detection"`
+}
+
+// HandleNativeMessage handles a request from the browser extension that the native messaging host
+// has forwarded to us via the desktop runner server. The returned value is sent back to the extension
+// as the response body.
+func (ls *localServer) HandleNativeMessage(ctx context.Context, req nativemessaging.Request) (any, error) {
+	ctx, span := observability.StartSpan(ctx, "request_type", string(req.Type))
+	defer span.End()
+
+	// The native messaging host validates the calling extension, but we check it again here, since
+	// the extension is used as the origin for the request. Unlike an http origin, it must be set.
+	if req.Extension == "" || !originIsAllowlisted(req.Extension) {
+		err := fmt.Errorf("%w: %q", nativemessaging.ErrExtensionNotAllowlisted, req.Extension)
+		observability.SetError(span, err)
+		return nil, err
+	}
+
+	switch req.Type {
+	case nativemessaging.RequestTypeHealth:
+		return map[string]string{"status": "ok"}, nil
+	case nativemessaging.RequestTypeDeviceIdentity:
+		return ls.idsResponse(req.Extension), nil
+	case nativemessaging.RequestTypeDt4aInfo:
+		return ls.nativeMessageDt4aInfo(req)
+	case nativemessaging.RequestTypePresenceDetection:
+		durationSinceLastDetection, err := ls.nativeMessagePresenceDetection(req)
+		if err != nil {
+			observability.SetError(span, err)
+			return nil, err
+		}
+		return nativeMessagePresenceDetectionResponse{
+			DurationSinceLastDetection: durationSinceLastDetection,
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", nativemessaging.ErrUnsupportedRequestType, req.Type)
+	}
+}
+
+func (ls *localServer) nativeMessageDt4aInfo(req nativemessaging.Request) (any, error) {
+	var body nativemessaging.Dt4aInfoRequest
+	if len(req.Body) > 0 {
+		if err := json.Unmarshal(req.Body, &body); err != nil {
+			return nil, fmt.Errorf("unmarshalling dt4a info request: %w", err)
+		}
+	}
+
+	key := legacyDt4aInfoKey
+	if body.AccountUuid != "" {
+		key = []byte(body.AccountUuid)
+	}
+
+	dt4aInfo, err := ls.knapsack.Dt4aInfoStore().Get(key)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving dt4a info from store: %w", err)
+	}
+
+	if len(dt4aInfo) == 0 {
+		return nil, errors.New("no dt4a info available")
+	}
+
+	return json.RawMessage(dt4aInfo), nil
+}
+
+func (ls *localServer) nativeMessagePresenceDetection(req nativemessaging.Request) (time.Duration, error) {
+	body := nativemessaging.PresenceDetectionRequest{
+		Reason:   defaultNativeMessagePresenceReason,
+		Interval: defaultNativeMessagePresenceInterval,
+	}
+	if len(req.Body) > 0 {
+		if err := json.Unmarshal(req.Body, &body); err != nil {
+			return 0, fmt.Errorf("unmarshalling presence detection request: %w", err)
+		}
+	}
+
+	// Share the lock with kryptoEcMiddleware, so that the user is only ever prompted once at a time
+	if !ls.kryptoMiddleware.presenceDetectionLock.TryLock() {
+		return 0, errors.New("presence detection already in progress")
+	}
+	defer ls.kryptoMiddleware.presenceDetectionLock.Unlock()
+
+	durationSinceLastDetection, err := ls.kryptoMiddleware.presenceDetector.DetectPresence(body.Reason, body.Interval)
+	if err != nil {
+		return durationSinceLastDetection, fmt.Errorf("detecting presence: %w", err)
+	}
+
+	return durationSinceLastDetection, nil
+}
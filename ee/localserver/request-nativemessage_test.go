@@ -0,0 +1,117 @@
+package localserver
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kolide/kit/ulid"
+	"github.com/kolide/launcher/v2/ee/agent/storage"
+	storageci "github.com/kolide/launcher/v2/ee/agent/storage/ci"
+	"github.com/kolide/launcher/v2/ee/agent/types"
+	typesmocks "github.com/kolide/launcher/v2/ee/agent/types/mocks"
+	"github.com/kolide/launcher/v2/ee/localserver/mocks"
+	"github.com/kolide/launcher/v2/ee/nativemessaging"
+	"github.com/kolide/launcher/v2/pkg/log/multislogger"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_localServer_HandleNativeMessage(t *testing.T) {
+	t.Parallel()
+
+	testExtension := "chrome-extension://gejiddohjgogedgjnonbofjigllpkmbf"
+
+	dt4aAccountId := ulid.New()
+
+	slogger := multislogger.NewNopLogger()
+	dt4aInfoStore, err := storageci.NewStore(t, slogger, storage.Dt4aInfoStore.String())
+	require.NoError(t, err)
+	require.NoError(t, dt4aInfoStore.Set(legacyDt4aInfoKey, []byte(`{"legacy_test_data":"legacy_test_value"}`)))
+	require.NoError(t, dt4aInfoStore.Set([]byte(dt4aAccountId), []byte(`{"some_test_data":"some_test_value"}`)))
+
+	k := typesmocks.NewKnapsack(t)
+	k.On("KolideServerURL").Return("localserver")
+	k.On("Slogger").Return(slogger)
+	k.On("Dt4aInfoStore").Return(dt4aInfoStore)
+	k.On("CurrentEnrollmentStatus").Return(types.Enrolled, nil)
+	k.On("GetEnrollmentDetails").Return(types.EnrollmentDetails{OSVersion: "1", Hostname: "test"})
+	k.On("Enrollments").Return([]types.Enrollment{
+		{
+			EnrollmentID: types.DefaultEnrollmentID,
+			Munemo:       "test-munemo",
+		},
+	}, nil)
+	testConfigStore, err := storageci.NewStore(t, multislogger.NewNopLogger(), storage.ConfigStore.String())
+	require.NoError(t, err, "could not create test config store")
+	k.On("ConfigStore").Return(testConfigStore).Maybe()
+	k.On("PersistAgentIngesterKeys", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return().Maybe()
+
+	presenceDetector := mocks.NewPresenceDetector(t)
+	ls, err := New(t.Context(), k, presenceDetector)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		ls.Interrupt(errors.New("test"))
+	})
+
+	// health
+	result, err := ls.HandleNativeMessage(t.Context(), nativemessaging.Request{Type: nativemessaging.RequestTypeHealth, Extension: testExtension})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"status": "ok"}, result)
+
+	// device identity uses the extension as the origin
+	result, err = ls.HandleNativeMessage(t.Context(), nativemessaging.Request{
+		Type:      nativemessaging.RequestTypeDeviceIdentity,
+		Extension: testExtension,
+	})
+	require.NoError(t, err)
+	idsResponse, ok := result.(requestIdsResponse)
+	require.True(t, ok)
+	require.Equal(t, testExtension, idsResponse.Origin)
+	require.Equal(t, string(types.Enrolled), idsResponse.Status.EnrollmentStatus)
+
+	// dt4a info, with and without an account uuid
+	result, err = ls.HandleNativeMessage(t.Context(), nativemessaging.Request{Type: nativemessaging.RequestTypeDt4aInfo, Extension: testExtension})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"legacy_test_data":"legacy_test_value"}`, string(result.(json.RawMessage)))
+
+	result, err = ls.HandleNativeMessage(t.Context(), nativemessaging.Request{
+		Type:      nativemessaging.RequestTypeDt4aInfo,
+		Extension: testExtension,
+		Body:      json.RawMessage(`{"account_uuid":"` + dt4aAccountId + `"}`),
+	})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"some_test_data":"some_test_value"}`, string(result.(json.RawMessage)))
+
+	_, err = ls.HandleNativeMessage(t.Context(), nativemessaging.Request{
+		Type:      nativemessaging.RequestTypeDt4aInfo,
+		Extension: testExtension,
+		Body:      json.RawMessage(`{"account_uuid":"unknown"}`),
+	})
+	require.Error(t, err)
+
+	// presence detection
+	presenceDetector.On("DetectPresence", "test reason", 5*time.Minute).Return(time.Second, nil).Once()
+	result, err = ls.HandleNativeMessage(t.Context(), nativemessaging.Request{
+		Type:      nativemessaging.RequestTypePresenceDetection,
+		Extension: testExtension,
+		Body:      json.RawMessage(`{"reason":"test reason","interval":300000000000}`),
+	})
+	require.NoError(t, err)
+	require.Equal(t, nativeMessagePresenceDetectionResponse{DurationSinceLastDetection: time.Second}, result)
+
+	presenceDetector.On("DetectPresence", defaultNativeMessagePresenceReason, defaultNativeMessagePresenceInterval).Return(time.Duration(0), errors.New("cancelled")).Once()
+	_, err = ls.HandleNativeMessage(t.Context(), nativemessaging.Request{Type: nativemessaging.RequestTypePresenceDetection, Extension: testExtension})
+	require.ErrorContains(t, err, "cancelled")
+
+	// unsupported types
+	_, err = ls.HandleNativeMessage(t.Context(), nativemessaging.Request{Type: "run_query", Extension: testExtension})
+	require.ErrorIs(t, err, nativemessaging.ErrUnsupportedRequestType)
+
+	// extensions not on the allowlist, or missing
+	for _, extension := range []string{"chrome-extension://notallowlisted", ""} {
+		_, err = ls.HandleNativeMessage(t.Context(), nativemessaging.Request{Type: nativemessaging.RequestTypeHealth, Extension: extension})
+		require.ErrorIs(t, err, nativemessaging.ErrExtensionNotAllowlisted)
+	}
+}
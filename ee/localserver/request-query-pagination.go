@@ -0,0 +1,339 @@
+package localserver
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/osquery/osquery-go/plugin/distributed"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Large result sets cannot be returned in a single krypto-boxed response. Callers may instead
+// request them a page at a time, by setting page_size and then passing the returned next_cursor
+// back with the same query (or scheduled query name); or all at once as a stream of chunks, by
+// setting stream. When the request comes through kryptoEcMiddleware, each streamed chunk is
+// boxed individually, and written as a base64-encoded line.
+const (
+	defaultQueryPageSize   = 1000
+	maxQueryPageSize       = 10000
+	queryResultsCacheTTL   = 2 * time.Minute
+	maxCachedQueryResults  = 16
+	queryCursorIdByteCount = 16
+)
+
+// queryRequest is the request body for the /query and /scheduledquery endpoints.
+type queryRequest struct {
+	Query    string `json:"query"`
+	Name     string `json:"name"`
+	PageSize int    `json:"page_size"`
+	Cursor   string `json:"cursor"`
+	Stream   bool   `json:"stream"`
+}
+
+// paginated returns true if the caller has asked for results a page or chunk at a time, rather
+// than all in a single response.
+func (q queryRequest) paginated() bool {
+	return q.PageSize > 0 || q.Cursor != "" || q.Stream
+}
+
+func (q queryRequest) pageSize() int {
+	if q.PageSize <= 0 {
+		return defaultQueryPageSize
+	}
+	return min(q.PageSize, maxQueryPageSize)
+}
+
+// queryResultsPage is a single page, or a single streamed chunk, of query results.
+type queryResultsPage struct {
+	Results    any    `json:"results"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Done       bool   `json:"done"`
+}
+
+// queryResultsChunk is a single streamed chunk of query results. Chunks are numbered from zero,
+// and a chunk with an error ends the stream.
+type queryResultsChunk struct {
+	queryResultsPage
+	Sequence int    `json:"sequence"`
+	Error    string `json:"error,omitempty"`
+}
+
+// pageableResults is a query result set that can be split into pages. Pages are measured in
+// units, which are rows.
+type pageableResults interface {
+	units() int
+	page(offset, size int) any
+}
+
+type queryRows []map[string]string
+
+func (r queryRows) units() int {
+	return len(r)
+}
+
+func (r queryRows) page(offset, size int) any {
+	page := r[offset:min(offset+size, len(r))]
+	if page == nil {
+		// Marshal an empty page as an empty list, rather than null
+		return queryRows{}
+	}
+	return page
+}
+
+// scheduledQueryResults pages the results of multiple scheduled queries. A result is split across
+// pages when its rows don't fit on one page; a result with no rows (e.g. a failed query) counts as
+// a single unit, so that it is still returned.
+type scheduledQueryResults []distributed.Result
+
+func (r scheduledQueryResults) units() int {
+	total := 0
+	for _, result := range r {
+		total += max(len(result.Rows), 1)
+	}
+	return total
+}
+
+func (r scheduledQueryResults) page(offset, size int) any {
+	page := make([]distributed.Result, 0)
+	start := 0
+	for _, result := range r {
+		resultUnits := max(len(result.Rows), 1)
+		end := start + resultUnits
+		if end <= offset {
+			start = end
+			continue
+		}
+		if start >= offset+size {
+			break
+		}
+
+		if len(result.Rows) == 0 {
+			page = append(page, result)
+		} else {
+			pageResult := result
+			pageResult.Rows = result.Rows[max(offset-start, 0):min(offset+size-start, len(result.Rows))]
+			page = append(page, pageResult)
+		}
+		start = end
+	}
+	return page
+}
+
+// queryResultsCache holds result sets that are being paged through, so that later pages come from
+// the same result set as the first page, without re-running the query.
+type queryResultsCache struct {
+	lock    sync.Mutex
+	entries map[string]*cachedQueryResults
+}
+
+type cachedQueryResults struct {
+	key     string // the query or scheduled query name, which must match on requests for later pages
+	results pageableResults
+	expires time.Time
+}
+
+func newQueryResultsCache() *queryResultsCache {
+	return &queryResultsCache{
+		entries: make(map[string]*cachedQueryResults),
+	}
+}
+
+// store caches the results, returning the ID to use in cursors for them.
+func (c *queryResultsCache) store(key string, results pageableResults) (string, error) {
+	idBytes := make([]byte, queryCursorIdByteCount)
+	if _, err := rand.Read(idBytes); err != nil {
+		return "", fmt.Errorf("generating cursor id: %w", err)
+	}
+	id := hex.EncodeToString(idBytes)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.removeExpired()
+	if len(c.entries) >= maxCachedQueryResults {
+		// Evict the entry closest to expiring
+		var oldestId string
+		for entryId, entry := range c.entries {
+			if oldestId == "" || entry.expires.Before(c.entries[oldestId].expires) {
+				oldestId = entryId
+			}
+		}
+		delete(c.entries, oldestId)
+	}
+
+	c.entries[id] = &cachedQueryResults{
+		key:     key,
+		results: results,
+		expires: time.Now().Add(queryResultsCacheTTL),
+	}
+
+	return id, nil
+}
+
+func (c *queryResultsCache) get(id string, key string) (pageableResults, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.removeExpired()
+	entry, ok := c.entries[id]
+	if !ok {
+		return nil, errors.New("cursor is expired or unknown")
+	}
+	if entry.key != key {
+		return nil, errors.New("cursor does not belong to this query")
+	}
+
+	entry.expires = time.Now().Add(queryResultsCacheTTL)
+	return entry.results, nil
+}
+
+func (c *queryResultsCache) remove(id string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.entries, id)
+}
+
+// removeExpired must be called with the lock held.
+func (c *queryResultsCache) removeExpired() {
+	now := time.Now()
+	for id, entry := range c.entries {
+		if now.After(entry.expires) {
+			delete(c.entries, id)
+		}
+	}
+}
+
+// queryCursor is an opaque continuation token, pointing to an offset in a cached result set.
+func queryCursor(id string, offset int) string {
+	return fmt.Sprintf("%s.%d", id, offset)
+}
+
+func parseQueryCursor(cursor string) (string, int, error) {
+	id, rawOffset, found := strings.Cut(cursor, ".")
+	if !found || id == "" {
+		return "", 0, fmt.Errorf("malformed cursor %q", cursor)
+	}
+	offset, err := strconv.Atoi(rawOffset)
+	if err != nil || offset < 0 {
+		return "", 0, fmt.Errorf("malformed cursor %q", cursor)
+	}
+	return id, offset, nil
+}
+
+// cachedQueryResultsForCursor returns the cached result set and offset the request's cursor points to.
+func (ls *localServer) cachedQueryResultsForCursor(req queryRequest, key string) (pageableResults, string, int, error) {
+	id, offset, err := parseQueryCursor(req.Cursor)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	results, err := ls.queryResults.get(id, key)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	if offset > results.units() {
+		return nil, "", 0, fmt.Errorf("cursor offset %d is out of range", offset)
+	}
+	return results, id, offset, nil
+}
+
+// writePaginatedQueryResults writes the results the request asks for: either every result from
+// the given offset onwards as a stream of chunks, or a single page. cursorId is the ID of the
+// cached result set, if the results came from the cache.
+func (ls *localServer) writePaginatedQueryResults(w http.ResponseWriter, r *http.Request, span trace.Span, req queryRequest, key string, results pageableResults, cursorId string, offset int) {
+	if req.Stream {
+		ls.streamQueryResults(w, r, req, results, offset)
+		if cursorId != "" {
+			ls.queryResults.remove(cursorId)
+		}
+		return
+	}
+
+	pageSize := req.pageSize()
+	page := queryResultsPage{
+		Results: results.page(offset, pageSize),
+		Done:    offset+pageSize >= results.units(),
+	}
+
+	if page.Done {
+		if cursorId != "" {
+			ls.queryResults.remove(cursorId)
+		}
+	} else {
+		if cursorId == "" {
+			var err error
+			cursorId, err = ls.queryResults.store(key, results)
+			if err != nil {
+				sendClientError(w, span, fmt.Errorf("error caching results for pagination: %w", err))
+				return
+			}
+		}
+		page.NextCursor = queryCursor(cursorId, offset+pageSize)
+	}
+
+	jsonBytes, err := json.Marshal(page)
+	if err != nil {
+		sendClientError(w, span, fmt.Errorf("error marshalling results to json: %w", err))
+		return
+	}
+
+	w.Write(jsonBytes)
+}
+
+// streamQueryResults writes the results as newline-delimited chunks, flushing after each one.
+// kryptoEcMiddleware boxes each flushed chunk individually. There is always at least one chunk,
+// and the last chunk is marked done.
+func (ls *localServer) streamQueryResults(w http.ResponseWriter, r *http.Request, req queryRequest, results pageableResults, offset int) {
+	rc := http.NewResponseController(w)
+	chunkSize := req.pageSize()
+	total := results.units()
+
+	for sequence := 0; ; sequence++ {
+		// The middleware stops the stream if it cannot send a chunk
+		if r.Context().Err() != nil {
+			return
+		}
+
+		chunk := queryResultsChunk{
+			queryResultsPage: queryResultsPage{
+				Results: results.page(offset, chunkSize),
+				Done:    offset+chunkSize >= total,
+			},
+			Sequence: sequence,
+		}
+
+		jsonBytes, err := json.Marshal(chunk)
+		if err != nil {
+			// Headers have likely already been sent, so end the stream with an error chunk instead
+			ls.slogger.Log(r.Context(), slog.LevelError,
+				"error marshalling streamed results chunk to json",
+				"err", err,
+				"sequence", sequence,
+			)
+			chunk = queryResultsChunk{
+				queryResultsPage: queryResultsPage{Done: true},
+				Sequence:         sequence,
+				Error:            "error marshalling results to json",
+			}
+			jsonBytes, _ = json.Marshal(chunk)
+		}
+
+		w.Write(jsonBytes)
+		w.Write([]byte("\n"))
+		_ = rc.Flush()
+
+		if chunk.Done {
+			return
+		}
+		offset += chunkSize
+	}
+}
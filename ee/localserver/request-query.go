@@ -27,24 +27,40 @@ func (ls *localServer) requestQueryHanlderFunc(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	var body map[string]string
+	var body queryRequest
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		sendClientError(w, span, fmt.Errorf("error unmarshaling request body: %s", err))
 		return
 	}
 
-	query, ok := body["query"]
-	if !ok || query == "" {
+	query := body.Query
+	if query == "" {
 		sendClientError(w, span, errors.New("no query key found in request body json"))
 		return
 	}
 
+	// Later pages come from the cached result set, rather than re-running the query
+	if body.Cursor != "" {
+		cachedResults, cursorId, offset, err := ls.cachedQueryResultsForCursor(body, query)
+		if err != nil {
+			sendClientError(w, span, fmt.Errorf("error using cursor: %w", err))
+			return
+		}
+		ls.writePaginatedQueryResults(w, r, span, body, query, cachedResults, cursorId, offset)
+		return
+	}
+
 	results, err := queryWithRetries(ls.querier, query)
 	if err != nil {
 		sendClientError(w, span, fmt.Errorf("error executing query: %s", err))
 		return
 	}
 
+	if body.paginated() {
+		ls.writePaginatedQueryResults(w, r, span, body, query, queryRows(results), "", 0)
+		return
+	}
+
 	jsonBytes, err := json.Marshal(results)
 	if err != nil {
 		sendClientError(w, span, fmt.Errorf("error marshalling results to json: %s", err))
@@ -71,25 +87,36 @@ func (ls *localServer) requestScheduledQueryHandlerFunc(w http.ResponseWriter, r
 	// 2. K2 calls `/scheduledquery`, with body `{ "name": "name_of_scheduled_query" }`
 	// 3. Launcher looks up the query's sql using the provided name
 	// 4. Launcher executes the query
-	// 5. Launcher returns results
+	// 5. Launcher returns results -- all at once, a page at a time, or streamed in chunks
 
 	if r.Body == nil {
 		sendClientError(w, span, errors.New("request body is nil"))
 		return
 	}
 
-	var body map[string]string
+	var body queryRequest
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		sendClientError(w, span, fmt.Errorf("error unmarshaling request body: %s", err))
 		return
 	}
 
-	name, ok := body["name"]
-	if !ok || name == "" {
+	name := body.Name
+	if name == "" {
 		sendClientError(w, span, errors.New("no name key found in request body json"))
 		return
 	}
 
+	// Later pages come from the cached result set, rather than re-running the scheduled queries
+	if body.Cursor != "" {
+		cachedResults, cursorId, offset, err := ls.cachedQueryResultsForCursor(body, name)
+		if err != nil {
+			sendClientError(w, span, fmt.Errorf("error using cursor: %w", err))
+			return
+		}
+		ls.writePaginatedQueryResults(w, r, span, body, name, cachedResults, cursorId, offset)
+		return
+	}
+
 	scheduledQueryQuery := fmt.Sprintf("select name, query from osquery_schedule where name like '%s'", name)
 
 	scheduledQueriesQueryResults, err := queryWithRetries(ls.querier, scheduledQueryQuery)
@@ -122,6 +149,11 @@ func (ls *localServer) requestScheduledQueryHandlerFunc(w http.ResponseWriter, r
 		results[i].Rows = scheduledQueryResult
 	}
 
+	if body.paginated() {
+		ls.writePaginatedQueryResults(w, r, span, body, name, scheduledQueryResults(results), "", 0)
+		return
+	}
+
 	jsonBytes, err := json.Marshal(results)
 	if err != nil {
 		sendClientError(w, span, fmt.Errorf("error marshalling results to json: %w", err))
@@ -282,3 +282,173 @@ func Test_localServer_requestRunScheduledQueryHandler(t *testing.T) {
 		})
 	}
 }
+
+func Test_localServer_requestQueryHandler_Pagination(t *testing.T) {
+	t.Parallel()
+
+	mockKnapsack := typesMocks.NewKnapsack(t)
+	mockKnapsack.On("KolideServerURL").Return("localhost")
+	mockKnapsack.On("Slogger").Return(multislogger.NewNopLogger())
+	mockKnapsack.On("Enrollments").Return([]types.Enrollment{
+		{
+			EnrollmentID: types.DefaultEnrollmentID,
+			Munemo:       "test-munemo",
+		},
+	}, nil)
+	testConfigStore, err := storageci.NewStore(t, multislogger.NewNopLogger(), storage.ConfigStore.String())
+	require.NoError(t, err, "could not create test config store")
+	mockKnapsack.On("ConfigStore").Return(testConfigStore).Maybe()
+	mockKnapsack.On("PersistAgentIngesterKeys", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return().Maybe()
+
+	query := "select * from lots_of_rows"
+	rows := make([]map[string]string, 5)
+	for i := range rows {
+		rows[i] = map[string]string{"row": fmt.Sprintf("%d", i)}
+	}
+
+	// The query should only run once per paged result set -- later pages come from the cached results
+	mockQuerier := mocks.NewQuerier(t)
+	mockQuerier.On("Query", query).Return(rows, nil).Twice()
+
+	server := testServer(t, mockKnapsack)
+	server.querier = mockQuerier
+
+	doRequest := func(body map[string]any) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("", "", bytes.NewBuffer(mustMarshal(t, body))) //nolint:noctx // Don't care about this in tests
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(server.requestQueryHanlderFunc).ServeHTTP(rr, req)
+		return rr
+	}
+
+	var gotRows []map[string]string
+	cursor := ""
+	for range 3 {
+		body := map[string]any{"query": query, "page_size": 2}
+		if cursor != "" {
+			body["cursor"] = cursor
+		}
+		rr := doRequest(body)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		pageRows := mustExtractJsonProperty[[]map[string]string](t, rr.Body.Bytes(), "results")
+		require.LessOrEqual(t, len(pageRows), 2)
+		gotRows = append(gotRows, pageRows...)
+
+		if mustExtractJsonProperty[bool](t, rr.Body.Bytes(), "done") {
+			cursor = ""
+			break
+		}
+		cursor = mustExtractJsonProperty[string](t, rr.Body.Bytes(), "next_cursor")
+		require.NotEmpty(t, cursor)
+	}
+	require.Empty(t, cursor, "expected final page to be marked done")
+	require.Equal(t, rows, gotRows)
+
+	// Cursors must be used with the query they were issued for
+	rr := doRequest(map[string]any{"query": query, "page_size": 2})
+	cursor = mustExtractJsonProperty[string](t, rr.Body.Bytes(), "next_cursor")
+	rr = doRequest(map[string]any{"query": "select * from other_rows", "cursor": cursor})
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), "cursor does not belong to this query")
+
+	// Unknown cursors are rejected
+	rr = doRequest(map[string]any{"query": query, "cursor": "deadbeef.2"})
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), "cursor is expired or unknown")
+
+	rr = doRequest(map[string]any{"query": query, "cursor": "not a cursor"})
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), "malformed cursor")
+}
+
+func Test_localServer_requestQueryHandler_Stream(t *testing.T) {
+	t.Parallel()
+
+	mockKnapsack := typesMocks.NewKnapsack(t)
+	mockKnapsack.On("KolideServerURL").Return("localhost")
+	mockKnapsack.On("Slogger").Return(multislogger.NewNopLogger())
+	mockKnapsack.On("Enrollments").Return([]types.Enrollment{
+		{
+			EnrollmentID: types.DefaultEnrollmentID,
+			Munemo:       "test-munemo",
+		},
+	}, nil)
+	testConfigStore, err := storageci.NewStore(t, multislogger.NewNopLogger(), storage.ConfigStore.String())
+	require.NoError(t, err, "could not create test config store")
+	mockKnapsack.On("ConfigStore").Return(testConfigStore).Maybe()
+	mockKnapsack.On("PersistAgentIngesterKeys", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return().Maybe()
+
+	for _, tt := range []struct {
+		name           string
+		rowCount       int
+		expectedChunks int
+	}{
+		{name: "multiple chunks", rowCount: 5, expectedChunks: 3},
+		{name: "exact chunks", rowCount: 4, expectedChunks: 2},
+		{name: "no rows", rowCount: 0, expectedChunks: 1},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			query := fmt.Sprintf("select * from %d_rows", tt.rowCount)
+			rows := make([]map[string]string, tt.rowCount)
+			for i := range rows {
+				rows[i] = map[string]string{"row": fmt.Sprintf("%d", i)}
+			}
+
+			mockQuerier := mocks.NewQuerier(t)
+			mockQuerier.On("Query", query).Return(rows, nil).Once()
+
+			server := testServer(t, mockKnapsack)
+			server.querier = mockQuerier
+
+			req, err := http.NewRequest("", "", bytes.NewBuffer(mustMarshal(t, map[string]any{ //nolint:noctx // Don't care about this in tests
+				"query":     query,
+				"page_size": 2,
+				"stream":    true,
+			})))
+			require.NoError(t, err)
+			rr := httptest.NewRecorder()
+			http.HandlerFunc(server.requestQueryHanlderFunc).ServeHTTP(rr, req)
+			require.True(t, rr.Flushed)
+
+			lines := bytes.Split(bytes.TrimSuffix(rr.Body.Bytes(), []byte("\n")), []byte("\n"))
+			require.Len(t, lines, tt.expectedChunks)
+
+			gotRows := make([]map[string]string, 0)
+			for i, line := range lines {
+				gotRows = append(gotRows, mustExtractJsonProperty[[]map[string]string](t, line, "results")...)
+				require.Equal(t, i == len(lines)-1, mustExtractJsonProperty[bool](t, line, "done"))
+				require.Equal(t, i, mustExtractJsonProperty[int](t, line, "sequence"))
+			}
+			require.Equal(t, rows, gotRows)
+		})
+	}
+}
+
+func Test_scheduledQueryResults_page(t *testing.T) {
+	t.Parallel()
+
+	results := scheduledQueryResults{
+		{QueryName: "one", Rows: []map[string]string{{"a": "1"}, {"a": "2"}, {"a": "3"}}},
+		{QueryName: "failed", Status: 1},
+		{QueryName: "two", Rows: []map[string]string{{"b": "1"}}},
+	}
+	require.Equal(t, 5, results.units())
+
+	require.Equal(t, []distributed.Result{
+		{QueryName: "one", Rows: []map[string]string{{"a": "1"}, {"a": "2"}}},
+	}, results.page(0, 2))
+
+	require.Equal(t, []distributed.Result{
+		{QueryName: "one", Rows: []map[string]string{{"a": "3"}}},
+		{QueryName: "failed", Status: 1},
+	}, results.page(2, 2))
+
+	require.Equal(t, []distributed.Result{
+		{QueryName: "two", Rows: []map[string]string{{"b": "1"}}},
+	}, results.page(4, 2))
+
+	require.Equal(t, []distributed.Result{}, results.page(5, 2))
+}
@@ -51,6 +51,7 @@ type localServer struct {
 	kryptoMiddleware       *kryptoEcMiddleware
 	tlsCerts               []tls.Certificate
 	querier                Querier
+	queryResults           *queryResultsCache
 	kolideServer           string
 	cancel                 context.CancelFunc
 	interrupted            *syncatomic.Bool
@@ -84,6 +85,7 @@ func New(ctx context.Context, k types.Knapsack, presenceDetector presenceDetecto
 		kolideServer:    k.KolideServerURL(),
 		myLocalDbSigner: agent.LocalDbKeys(),
 		interrupted:     &syncatomic.Bool{},
+		queryResults:    newQueryResultsCache(),
 	}
 
 	// TODO: As there may be things that adjust the keys during runtime, we need to persist that across
@@ -148,6 +150,8 @@ func New(ctx context.Context, k types.Knapsack, presenceDetector presenceDetecto
 	// uncomment to test without going through middleware
 	// for example:
 	// curl localhost:40978/query --data '{"query":"select * from kolide_launcher_info"}'
+	// curl localhost:40978/query --data '{"query":"select * from processes", "page_size": 100}'
+	// curl localhost:40978/query --data '{"query":"select * from processes", "page_size": 100, "stream": true}'
 	// rootMux.Handle("/query", ls.requestQueryHandler())
 	// curl localhost:40978/scheduledquery --data '{"name":"pack:kolide_device_updaters:agentprocesses-all:snapshot"}'
 	// rootMux.Handle("/scheduledquery", ls.requestScheduledQueryHandler())
@@ -0,0 +1,114 @@
+package nativemessaging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+)
+
+// forwarder sends a validated request on to root launcher, returning the response body.
+type forwarder interface {
+	Forward(ctx context.Context, req Request) (json.RawMessage, error)
+}
+
+// serve reads requests from msgReader until the stream is closed, dispatching each one
+// and writing its response to msgWriter. Requests are handled concurrently, since some
+// (e.g. presence detection) may take a long time to complete; each response is written
+// as a single framed message, so that responses are never interleaved.
+func serve(ctx context.Context, slogger *slog.Logger, extension string, msgReader io.Reader, msgWriter io.Writer, fwd forwarder) {
+	var writeLock sync.Mutex
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		msgContent, err := readMessage(msgReader)
+		if err != nil {
+			// May be a genuine error, or may just be the stream closing
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				slogger.Log(ctx, slog.LevelInfo,
+					"stream closed",
+				)
+			} else {
+				slogger.Log(ctx, slog.LevelError,
+					"terminating processing after error",
+					"err", err,
+				)
+			}
+
+			return
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			resp := dispatch(ctx, slogger, extension, msgContent, fwd)
+
+			writeLock.Lock()
+			defer writeLock.Unlock()
+			if err := sendMessage(msgWriter, resp); err != nil {
+				slogger.Log(ctx, slog.LevelError,
+					"sending response",
+					"err", err,
+					"request_id", resp.ID,
+					"request_type", resp.Type,
+				)
+			}
+		}()
+	}
+}
+
+// dispatch parses and validates the given request, forwarding it if it is allowed.
+// It always returns a response, setting Error if the request could not be handled.
+func dispatch(ctx context.Context, slogger *slog.Logger, extension string, msgContent []byte, fwd forwarder) Response {
+	var req Request
+	if err := json.Unmarshal(msgContent, &req); err != nil {
+		slogger.Log(ctx, slog.LevelWarn,
+			"could not unmarshal request",
+			"err", err,
+		)
+		return Response{Error: fmt.Sprintf("invalid request: %v", err)}
+	}
+
+	resp := Response{
+		ID:   req.ID,
+		Type: req.Type,
+	}
+
+	if !req.Type.Allowed() {
+		slogger.Log(ctx, slog.LevelWarn,
+			"rejecting request with unsupported type",
+			"request_id", req.ID,
+			"request_type", req.Type,
+		)
+		resp.Error = fmt.Errorf("%w: %q", ErrUnsupportedRequestType, req.Type).Error()
+		return resp
+	}
+
+	// Only trust the extension that we validated when the host was started
+	req.Extension = extension
+
+	body, err := fwd.Forward(ctx, req)
+	if err != nil {
+		slogger.Log(ctx, slog.LevelError,
+			"could not forward request",
+			"err", err,
+			"request_id", req.ID,
+			"request_type", req.Type,
+		)
+		resp.Error = err.Error()
+		return resp
+	}
+
+	slogger.Log(ctx, slog.LevelDebug,
+		"handled request",
+		"request_id", req.ID,
+		"request_type", req.Type,
+	)
+	resp.Body = body
+	return resp
+}
@@ -0,0 +1,138 @@
+package nativemessaging
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kolide/launcher/v2/pkg/log/multislogger"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeForwarder struct {
+	lock     sync.Mutex
+	received []Request
+	// release, if set, blocks requests with the given ID until it is closed
+	release map[string]chan struct{}
+}
+
+func (f *fakeForwarder) Forward(_ context.Context, req Request) (json.RawMessage, error) {
+	f.lock.Lock()
+	f.received = append(f.received, req)
+	releaseChan := f.release[req.ID]
+	f.lock.Unlock()
+
+	if releaseChan != nil {
+		<-releaseChan
+	}
+
+	if req.Type == RequestTypeDt4aInfo {
+		return nil, errors.New("no dt4a info available")
+	}
+	return json.RawMessage(`{"status":"ok"}`), nil
+}
+
+// testHost runs serve over in-process pipes, returning the browser's ends of them.
+func testHost(t *testing.T, fwd forwarder) (io.WriteCloser, *bufio.Reader) {
+	browserToHostReader, browserToHostWriter := io.Pipe()
+	hostToBrowserReader, hostToBrowserWriter := io.Pipe()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		serve(t.Context(), multislogger.NewNopLogger(), "chrome-extension://test", browserToHostReader, hostToBrowserWriter, fwd)
+	}()
+
+	t.Cleanup(func() {
+		browserToHostWriter.Close()
+		hostToBrowserReader.Close()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Error("serve did not return after stream was closed")
+		}
+	})
+
+	return browserToHostWriter, bufio.NewReader(hostToBrowserReader)
+}
+
+func sendRequest(t *testing.T, w io.Writer, req any) {
+	require.NoError(t, sendMessage(w, req))
+}
+
+func receiveResponse(t *testing.T, r io.Reader) Response {
+	raw, err := readMessage(r)
+	require.NoError(t, err)
+
+	var resp Response
+	require.NoError(t, json.Unmarshal(raw, &resp))
+	return resp
+}
+
+func Test_serve(t *testing.T) {
+	t.Parallel()
+
+	fwd := &fakeForwarder{}
+	browserWriter, browserReader := testHost(t, fwd)
+
+	// Allowed request is forwarded, with the validated extension
+	sendRequest(t, browserWriter, Request{ID: "1", Type: RequestTypeHealth, Extension: "chrome-extension://spoofed"})
+	resp := receiveResponse(t, browserReader)
+	require.Equal(t, "1", resp.ID)
+	require.Equal(t, RequestTypeHealth, resp.Type)
+	require.Empty(t, resp.Error)
+	require.JSONEq(t, `{"status":"ok"}`, string(resp.Body))
+
+	// Forwarding errors are returned in the response
+	sendRequest(t, browserWriter, Request{ID: "2", Type: RequestTypeDt4aInfo})
+	resp = receiveResponse(t, browserReader)
+	require.Equal(t, "2", resp.ID)
+	require.Equal(t, "no dt4a info available", resp.Error)
+	require.Empty(t, resp.Body)
+
+	// Unsupported request types are rejected without being forwarded
+	sendRequest(t, browserWriter, Request{ID: "3", Type: "run_query"})
+	resp = receiveResponse(t, browserReader)
+	require.Equal(t, "3", resp.ID)
+	require.Contains(t, resp.Error, ErrUnsupportedRequestType.Error())
+
+	// Malformed requests get an error response
+	sendRequest(t, browserWriter, "not an envelope")
+	resp = receiveResponse(t, browserReader)
+	require.Empty(t, resp.ID)
+	require.Contains(t, resp.Error, "invalid request")
+
+	fwd.lock.Lock()
+	defer fwd.lock.Unlock()
+	require.Len(t, fwd.received, 2)
+	for _, req := range fwd.received {
+		require.Equal(t, "chrome-extension://test", req.Extension)
+	}
+}
+
+func Test_serve_concurrentRequests(t *testing.T) {
+	t.Parallel()
+
+	releaseSlow := make(chan struct{})
+	fwd := &fakeForwarder{
+		release: map[string]chan struct{}{"slow": releaseSlow},
+	}
+	browserWriter, browserReader := testHost(t, fwd)
+
+	// A slow request should not hold up the requests after it
+	sendRequest(t, browserWriter, Request{ID: "slow", Type: RequestTypePresenceDetection})
+	sendRequest(t, browserWriter, Request{ID: "fast", Type: RequestTypeHealth})
+
+	resp := receiveResponse(t, browserReader)
+	require.Equal(t, "fast", resp.ID)
+
+	close(releaseSlow)
+	resp = receiveResponse(t, browserReader)
+	require.Equal(t, "slow", resp.ID)
+	require.Empty(t, resp.Error)
+}
@@ -0,0 +1,96 @@
+package nativemessaging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kolide/launcher/v2/pkg/authedclient"
+)
+
+const (
+	// runnerNativeMessageEndpoint must match the endpoint registered by the desktop runner server
+	runnerNativeMessageEndpoint = "/nativemessage"
+	// forwardTimeout is generous because presence detection waits on the user
+	forwardTimeout = 90 * time.Second
+	// maxForwardedResponseSize bounds what we'll read back from root launcher
+	maxForwardedResponseSize = 1 << 20
+)
+
+// runnerForwarder forwards requests to root launcher via the desktop runner server.
+type runnerForwarder struct {
+	connectionFile string
+}
+
+func newRunnerForwarder(connectionFile string) *runnerForwarder {
+	return &runnerForwarder{
+		connectionFile: connectionFile,
+	}
+}
+
+// Forward implements forwarder. The connection file is re-read on each request, since root
+// launcher rewrites it whenever the desktop runner server restarts.
+func (r *runnerForwarder) Forward(ctx context.Context, req Request) (json.RawMessage, error) {
+	conn, err := r.connection()
+	if err != nil {
+		return nil, fmt.Errorf("root launcher is unavailable: %w", err)
+	}
+
+	reqBody, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, conn.URL+runnerNativeMessageEndpoint, bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// The token may change between requests, so we use a new client each time
+	client := authedclient.New(conn.AuthToken, forwardTimeout)
+	defer client.CloseIdleConnections()
+	httpResp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("forwarding request to root launcher: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(httpResp.Body, maxForwardedResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("reading response from root launcher: %w", err)
+	}
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("root launcher returned %d: %s", httpResp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	if !json.Valid(respBody) {
+		return nil, fmt.Errorf("root launcher returned invalid json response")
+	}
+
+	return respBody, nil
+}
+
+func (r *runnerForwarder) connection() (*RunnerConnection, error) {
+	raw, err := os.ReadFile(r.connectionFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading connection file: %w", err)
+	}
+
+	var conn RunnerConnection
+	if err := json.Unmarshal(raw, &conn); err != nil {
+		return nil, fmt.Errorf("unmarshalling connection file: %w", err)
+	}
+	if conn.URL == "" || conn.AuthToken == "" {
+		return nil, fmt.Errorf("connection file %s is incomplete", r.connectionFile)
+	}
+
+	return &conn, nil
+}
@@ -0,0 +1,61 @@
+package nativemessaging
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_runnerForwarder_Forward(t *testing.T) {
+	t.Parallel()
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != runnerNativeMessageEndpoint || r.Header.Get("Authorization") != "Bearer test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var req Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if req.Type == RequestTypeDt4aInfo {
+			http.Error(w, "no dt4a info available", http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"extension":"` + req.Extension + `"}`))
+	}))
+	t.Cleanup(testServer.Close)
+
+	connectionFile := filepath.Join(t.TempDir(), RunnerConnectionFilename)
+	fwd := newRunnerForwarder(connectionFile)
+
+	// No connection file yet
+	_, err := fwd.Forward(t.Context(), Request{ID: "1", Type: RequestTypeHealth})
+	require.ErrorContains(t, err, "root launcher is unavailable")
+
+	connBytes, err := json.Marshal(RunnerConnection{URL: testServer.URL, AuthToken: "test-token"})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(connectionFile, connBytes, 0600))
+
+	body, err := fwd.Forward(t.Context(), Request{ID: "2", Type: RequestTypeDeviceIdentity, Extension: "chrome-extension://test"})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"extension":"chrome-extension://test"}`, string(body))
+
+	_, err = fwd.Forward(t.Context(), Request{ID: "3", Type: RequestTypeDt4aInfo})
+	require.ErrorContains(t, err, "root launcher returned 500: no dt4a info available")
+
+	// The connection file is re-read on each request, so a rotated token is picked up
+	connBytes, err = json.Marshal(RunnerConnection{URL: testServer.URL, AuthToken: "stale-token"})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(connectionFile, connBytes, 0600))
+
+	_, err = fwd.Forward(t.Context(), Request{ID: "4", Type: RequestTypeHealth})
+	require.ErrorContains(t, err, "root launcher returned 401")
+}
@@ -3,7 +3,6 @@ package nativemessaging
 import (
 	"bufio"
 	"context"
-	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -64,43 +63,11 @@ func ReadNativeMessages(ctx context.Context) {
 		"extension", extension,
 	)
 
-	// Handle input until the connection is closed
+	// Handle requests until the connection is closed, forwarding them to root launcher
+	// via the desktop runner server.
+	connectionFile := filepath.Join(determineRootDirectory(), fmt.Sprintf("desktop_%d", os.Getuid()), RunnerConnectionFilename)
 	stdinReader := bufio.NewReaderSize(os.Stdin, msgBufferSize)
-	for {
-		msgContent, err := readMessage(stdinReader)
-		if err != nil {
-			// May be a genuine error, or may just be the stream closing
-			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
-				slogger.Log(ctx, slog.LevelInfo,
-					"stream closed",
-				)
-			} else {
-				slogger.Log(ctx, slog.LevelError,
-					"terminating processing after error",
-					"err", err,
-				)
-			}
-
-			break
-		}
-
-		// In the future, we would JSON unmarshal this request and then forward it
-		slogger.Log(ctx, slog.LevelInfo,
-			"message",
-			"contents", string(msgContent),
-		)
-
-		// Write a test message
-		if err := sendMessage(map[string]any{
-			"msg":     "received message",
-			"msg_len": len(msgContent),
-		}); err != nil {
-			slogger.Log(ctx, slog.LevelError,
-				"sending message",
-				"err", err,
-			)
-		}
-	}
+	serve(ctx, slogger.Logger, extension, stdinReader, os.Stdout, newRunnerForwarder(connectionFile))
 
 	slogger.Log(ctx, slog.LevelInfo,
 		"shutting down",
@@ -0,0 +1,82 @@
+package nativemessaging
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// RequestType identifies the kind of request the browser extension is making.
+type RequestType string
+
+const (
+	RequestTypeHealth            RequestType = "health"
+	RequestTypeDeviceIdentity    RequestType = "device_identity"
+	RequestTypeDt4aInfo          RequestType = "dt4a_info"
+	RequestTypePresenceDetection RequestType = "presence_detection"
+)
+
+// allowedRequestTypes are the request types that the native messaging host will forward
+// to root launcher. All other requests are rejected by the host without being forwarded.
+var allowedRequestTypes = map[RequestType]struct{}{
+	RequestTypeHealth:            {},
+	RequestTypeDeviceIdentity:    {},
+	RequestTypeDt4aInfo:          {},
+	RequestTypePresenceDetection: {},
+}
+
+var ErrUnsupportedRequestType = errors.New("unsupported request type")
+
+// ErrExtensionNotAllowlisted is returned for requests from an extension that is not on our allowlist.
+var ErrExtensionNotAllowlisted = errors.New("extension not allowlisted")
+
+// Allowed returns true if this request type may be forwarded to root launcher.
+func (r RequestType) Allowed() bool {
+	_, ok := allowedRequestTypes[r]
+	return ok
+}
+
+// Request is the envelope for a message sent by the browser extension. The ID is chosen by
+// the extension, and is echoed back in the corresponding Response, so that the extension can
+// match up responses with requests -- responses are not guaranteed to be sent in the same
+// order that requests were received.
+type Request struct {
+	ID   string          `json:"id"`
+	Type RequestType     `json:"type"`
+	Body json.RawMessage `json:"body,omitempty"`
+	// Extension is the origin of the extension that made the request. It is set by the
+	// native messaging host after validating the calling browser, and is never taken
+	// from the message itself.
+	Extension string `json:"extension,omitempty"`
+}
+
+// Response is the envelope for a message sent back to the browser extension. Exactly one
+// of Body or Error is set.
+type Response struct {
+	ID    string          `json:"id"`
+	Type  RequestType     `json:"type"`
+	Body  json.RawMessage `json:"body,omitempty"`
+	Error string          `json:"error,omitempty"`
+}
+
+// Dt4aInfoRequest is the body for a RequestTypeDt4aInfo request.
+type Dt4aInfoRequest struct {
+	AccountUuid string `json:"account_uuid,omitempty"`
+}
+
+// PresenceDetectionRequest is the body for a RequestTypePresenceDetection request.
+type PresenceDetectionRequest struct {
+	Reason   string        `json:"reason"`
+	Interval time.Duration `json:"interval"`
+}
+
+// RunnerConnectionFilename is the name of the file, in the user's desktop directory, that root
+// launcher writes to tell the native messaging host how to reach the desktop runner server.
+const RunnerConnectionFilename = "nativemessaging_runner.json"
+
+// RunnerConnection holds the details the native messaging host needs to forward requests to
+// root launcher via the desktop runner server.
+type RunnerConnection struct {
+	URL       string `json:"url"`
+	AuthToken string `json:"auth_token"`
+}
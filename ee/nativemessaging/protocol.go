@@ -6,7 +6,6 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"os"
 )
 
 const (
@@ -42,15 +41,15 @@ func readMessage(msgReader io.Reader) ([]byte, error) {
 }
 
 // sendMessage formats the given message body appropropriately
-// and then writes it to stdout.
+// and then writes it to msgWriter (stdout, outside of tests).
 // The native messaging documentation states that I/O mode must be explicitly set to O_BINARY
 // on Windows -- however, this appears to already be handled appropriately for Golang.
-func sendMessage(msgBody any) error {
+func sendMessage(msgWriter io.Writer, msgBody any) error {
 	msg, err := formatMessage(msgBody)
 	if err != nil {
 		return fmt.Errorf("formatting message: %w", err)
 	}
-	written, err := os.Stdout.Write(msg)
+	written, err := msgWriter.Write(msg)
 	if written != len(msg) || err != nil {
 		return fmt.Errorf("sending message: wrote %d of %d expected bytes: %w", written, len(msg), err)
 	}
@@ -0,0 +1,173 @@
+package exporter
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kolide/launcher/v2/ee/agent/flags/keys"
+	"github.com/kolide/launcher/v2/ee/agent/types"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// customerCollectorKeys are the flags that configure export to a customer-operated collector.
+var customerCollectorKeys = []keys.FlagKey{
+	keys.CustomerOTLPEndpoint,
+	keys.CustomerOTLPHeaders,
+	keys.CustomerOTLPCAPath,
+	keys.DisableCustomerOTLPTLS,
+	keys.CustomerOTLPSamplingRate,
+	keys.CustomerOTLPOnly,
+}
+
+func customerCollectorChanged(flagKeys []keys.FlagKey) bool {
+	for _, k := range customerCollectorKeys {
+		if keys.Contains(flagKeys, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// customerCollectorConfig configures export of traces, metrics, and logs to a customer-operated
+// OpenTelemetry collector, alongside (or instead of) export to Kolide's ingest server.
+type customerCollectorConfig struct {
+	endpoint     string
+	headers      map[string]string
+	creds        credentials.TransportCredentials
+	samplingRate float64
+	only         bool // export traces and metrics only to the customer collector
+}
+
+// loadCustomerCollectorConfig returns the current customer collector configuration, or nil if
+// no customer collector is configured.
+func loadCustomerCollectorConfig(k types.Knapsack) (*customerCollectorConfig, error) {
+	endpoint := strings.TrimSpace(k.CustomerOTLPEndpoint())
+	if endpoint == "" {
+		return nil, nil
+	}
+
+	headers, err := parseHeaders(k.CustomerOTLPHeaders())
+	if err != nil {
+		return nil, fmt.Errorf("parsing customer collector headers: %w", err)
+	}
+
+	creds := insecure.NewCredentials()
+	if !k.DisableCustomerOTLPTLS() {
+		tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+		if caPath := k.CustomerOTLPCAPath(); caPath != "" {
+			pem, err := os.ReadFile(caPath)
+			if err != nil {
+				return nil, fmt.Errorf("reading customer collector CA file: %w", err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificates found in customer collector CA file %s", caPath)
+			}
+			tlsConfig.RootCAs = pool
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	return &customerCollectorConfig{
+		endpoint:     endpoint,
+		headers:      headers,
+		creds:        creds,
+		samplingRate: k.CustomerOTLPSamplingRate(),
+		only:         k.CustomerOTLPOnly(),
+	}, nil
+}
+
+// parseHeaders parses headers in the OTEL_EXPORTER_OTLP_HEADERS format: comma-separated key=value pairs.
+func parseHeaders(raw string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(raw, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		k, v, found := strings.Cut(pair, "=")
+		k = strings.TrimSpace(k)
+		if !found || k == "" {
+			return nil, fmt.Errorf("header %q is not in key=value format", pair)
+		}
+		headers[strings.ToLower(k)] = strings.TrimSpace(v)
+	}
+	return headers, nil
+}
+
+func (c *customerCollectorConfig) traceExporterOpts() []otlptracegrpc.Option {
+	return []otlptracegrpc.Option{
+		otlptracegrpc.WithEndpoint(c.endpoint),
+		otlptracegrpc.WithHeaders(c.headers),
+		otlptracegrpc.WithTLSCredentials(c.creds),
+	}
+}
+
+func (c *customerCollectorConfig) metricExporterOpts() []otlpmetricgrpc.Option {
+	return []otlpmetricgrpc.Option{
+		otlpmetricgrpc.WithEndpoint(c.endpoint),
+		otlpmetricgrpc.WithHeaders(c.headers),
+		otlpmetricgrpc.WithTLSCredentials(c.creds),
+	}
+}
+
+func (c *customerCollectorConfig) dialOpts() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithTransportCredentials(c.creds),
+	}
+}
+
+// ratioFilterSpanProcessor passes through only the spans that a TraceIDRatioBased sampler with the
+// given fraction would sample. When we export to multiple destinations with different sampling rates,
+// the tracer provider samples at the highest rate, and each destination filters down to its own rate.
+// Since the decision is based on the trace ID, whole traces are kept or dropped together.
+type ratioFilterSpanProcessor struct {
+	next              sdktrace.SpanProcessor
+	traceIDUpperBound uint64
+	all               bool
+}
+
+func newRatioFilterSpanProcessor(next sdktrace.SpanProcessor, fraction float64) *ratioFilterSpanProcessor {
+	return &ratioFilterSpanProcessor{
+		next:              next,
+		traceIDUpperBound: uint64(max(fraction, 0) * (1 << 63)),
+		all:               fraction >= 1,
+	}
+}
+
+func (r *ratioFilterSpanProcessor) keep(traceID [16]byte) bool {
+	if r.all {
+		return true
+	}
+	// Mirrors the calculation in sdktrace.TraceIDRatioBased
+	return binary.BigEndian.Uint64(traceID[8:16])>>1 < r.traceIDUpperBound
+}
+
+func (r *ratioFilterSpanProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	if r.keep(s.SpanContext().TraceID()) {
+		r.next.OnStart(parent, s)
+	}
+}
+
+func (r *ratioFilterSpanProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	if r.keep(s.SpanContext().TraceID()) {
+		r.next.OnEnd(s)
+	}
+}
+
+func (r *ratioFilterSpanProcessor) Shutdown(ctx context.Context) error {
+	return r.next.Shutdown(ctx)
+}
+
+func (r *ratioFilterSpanProcessor) ForceFlush(ctx context.Context) error {
+	return r.next.ForceFlush(ctx)
+}
@@ -0,0 +1,165 @@
+package exporter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	typesmocks "github.com/kolide/launcher/v2/ee/agent/types/mocks"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func Test_parseHeaders(t *testing.T) {
+	t.Parallel()
+
+	headers, err := parseHeaders("X-Api-Key=abcd, tenant = acme,,")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"x-api-key": "abcd", "tenant": "acme"}, headers)
+
+	headers, err = parseHeaders("")
+	require.NoError(t, err)
+	require.Empty(t, headers)
+
+	_, err = parseHeaders("x-api-key")
+	require.Error(t, err)
+
+	_, err = parseHeaders("=abcd")
+	require.Error(t, err)
+}
+
+func Test_loadCustomerCollectorConfig(t *testing.T) {
+	t.Parallel()
+
+	notACert := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(notACert, []byte("not a certificate"), 0644))
+
+	for _, tt := range []struct {
+		name        string
+		endpoint    string
+		caPath      string
+		disableTLS  bool
+		expectNil   bool
+		expectedErr bool
+	}{
+		{
+			name:      "not configured",
+			endpoint:  " ",
+			expectNil: true,
+		},
+		{
+			name:     "default system CAs",
+			endpoint: "otel.example.com:4317",
+		},
+		{
+			name:       "TLS disabled",
+			endpoint:   "localhost:4317",
+			caPath:     notACert,
+			disableTLS: true,
+		},
+		{
+			name:        "missing CA file",
+			endpoint:    "otel.example.com:4317",
+			caPath:      filepath.Join(t.TempDir(), "missing.pem"),
+			expectedErr: true,
+		},
+		{
+			name:        "invalid CA file",
+			endpoint:    "otel.example.com:4317",
+			caPath:      notACert,
+			expectedErr: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockKnapsack := typesmocks.NewKnapsack(t)
+			mockKnapsack.On("CustomerOTLPEndpoint").Return(tt.endpoint)
+			mockKnapsack.On("CustomerOTLPHeaders").Return("").Maybe()
+			mockKnapsack.On("DisableCustomerOTLPTLS").Return(tt.disableTLS).Maybe()
+			mockKnapsack.On("CustomerOTLPCAPath").Return(tt.caPath).Maybe()
+			mockKnapsack.On("CustomerOTLPSamplingRate").Return(0.25).Maybe()
+			mockKnapsack.On("CustomerOTLPOnly").Return(true).Maybe()
+
+			cfg, err := loadCustomerCollectorConfig(mockKnapsack)
+			if tt.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tt.expectNil {
+				require.Nil(t, cfg)
+				return
+			}
+
+			require.Equal(t, tt.endpoint, cfg.endpoint)
+			require.Equal(t, 0.25, cfg.samplingRate)
+			require.True(t, cfg.only)
+			if tt.disableTLS {
+				require.Equal(t, "insecure", cfg.creds.Info().SecurityProtocol)
+			} else {
+				require.Equal(t, "tls", cfg.creds.Info().SecurityProtocol)
+			}
+		})
+	}
+}
+
+func Test_ratioFilterSpanProcessor(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name     string
+		fraction float64
+		traceID  trace.TraceID
+		expected int
+	}{
+		{
+			name:     "all",
+			fraction: 1.0,
+			traceID:  trace.TraceID{0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+			expected: 1,
+		},
+		{
+			name:     "none",
+			fraction: 0.0,
+			traceID:  trace.TraceID{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+			expected: 0,
+		},
+		{
+			name:     "low trace ID kept",
+			fraction: 0.5,
+			traceID:  trace.TraceID{0, 0, 0, 0, 0, 0, 0, 0, 0x10, 0, 0, 0, 0, 0, 0, 0},
+			expected: 1,
+		},
+		{
+			name:     "high trace ID dropped",
+			fraction: 0.5,
+			traceID:  trace.TraceID{0, 0, 0, 0, 0, 0, 0, 0, 0xf0, 0, 0, 0, 0, 0, 0, 0},
+			expected: 0,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			recorder := tracetest.NewSpanRecorder()
+			provider := sdktrace.NewTracerProvider(
+				sdktrace.WithSampler(sdktrace.AlwaysSample()),
+				sdktrace.WithSpanProcessor(newRatioFilterSpanProcessor(recorder, tt.fraction)),
+			)
+			t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+			ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+				TraceID:    tt.traceID,
+				SpanID:     trace.SpanID{1},
+				TraceFlags: trace.FlagsSampled,
+			}))
+			_, span := provider.Tracer("test").Start(ctx, "test")
+			span.End()
+
+			require.Len(t, recorder.Ended(), tt.expected)
+		})
+	}
+}
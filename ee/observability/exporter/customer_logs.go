@@ -0,0 +1,294 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	customerLogBufferSize    = 2048
+	customerLogBatchSize     = 512
+	customerLogFlushInterval = 10 * time.Second
+	customerLogExportTimeout = 30 * time.Second
+	customerLogLevel         = slog.LevelInfo
+)
+
+// customerLogShipper batches launcher logs and exports them to the customer collector via OTLP.
+// It cannot log its own errors, since those logs would be shipped through it -- instead, it counts
+// the records it was unable to ship.
+type customerLogShipper struct {
+	enabled  atomic.Bool
+	records  chan *logspb.LogRecord
+	lock     sync.Mutex
+	conn     *grpc.ClientConn
+	client   collogspb.LogsServiceClient
+	headers  map[string]string
+	resource *resourcepb.Resource
+	dropped  atomic.Uint64
+}
+
+func newCustomerLogShipper() *customerLogShipper {
+	return &customerLogShipper{
+		records: make(chan *logspb.LogRecord, customerLogBufferSize),
+	}
+}
+
+// setConfig points the shipper at the given collector, or disables it if cfg is nil.
+func (s *customerLogShipper) setConfig(cfg *customerCollectorConfig, attrs []attribute.KeyValue) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+		s.client = nil
+	}
+
+	if cfg == nil {
+		s.enabled.Store(false)
+		return nil
+	}
+
+	conn, err := grpc.NewClient(cfg.endpoint, cfg.dialOpts()...)
+	if err != nil {
+		s.enabled.Store(false)
+		return fmt.Errorf("creating customer collector log client: %w", err)
+	}
+
+	s.conn = conn
+	s.client = collogspb.NewLogsServiceClient(conn)
+	s.headers = cfg.headers
+	s.resource = &resourcepb.Resource{Attributes: keyValuesToProto(attrs)}
+	s.enabled.Store(true)
+
+	return nil
+}
+
+func (s *customerLogShipper) enqueue(record *logspb.LogRecord) {
+	select {
+	case s.records <- record:
+	default:
+		s.dropped.Add(1)
+	}
+}
+
+// run ships batches of logs until the context is canceled, then ships any remaining logs.
+func (s *customerLogShipper) run(ctx context.Context) {
+	ticker := time.NewTicker(customerLogFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*logspb.LogRecord, 0, customerLogBatchSize)
+	for {
+		select {
+		case <-ctx.Done():
+			// Drain what we have, using a fresh context since ours is canceled
+			for len(s.records) > 0 && len(batch) < customerLogBufferSize {
+				batch = append(batch, <-s.records)
+			}
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			s.export(shutdownCtx, batch)
+			cancel()
+			s.close()
+			return
+		case record := <-s.records:
+			batch = append(batch, record)
+			if len(batch) < customerLogBatchSize {
+				continue
+			}
+		case <-ticker.C:
+		}
+
+		s.export(ctx, batch)
+		batch = batch[:0]
+	}
+}
+
+func (s *customerLogShipper) export(ctx context.Context, batch []*logspb.LogRecord) {
+	if len(batch) == 0 {
+		return
+	}
+
+	// Take the current configuration under the lock, but don't hold it during the export -- that
+	// would block configuration updates and shutdown for as long as the collector takes to respond.
+	// If the configuration changes mid-export, the old connection is closed and the batch is dropped.
+	s.lock.Lock()
+	client, headers, resource := s.client, s.headers, s.resource
+	s.lock.Unlock()
+
+	if client == nil {
+		s.dropped.Add(uint64(len(batch)))
+		return
+	}
+
+	req := &collogspb.ExportLogsServiceRequest{
+		ResourceLogs: []*logspb.ResourceLogs{
+			{
+				Resource: resource,
+				ScopeLogs: []*logspb.ScopeLogs{
+					{
+						Scope:      &commonpb.InstrumentationScope{Name: applicationName},
+						LogRecords: batch,
+					},
+				},
+			},
+		},
+	}
+
+	exportCtx, cancel := context.WithTimeout(metadata.NewOutgoingContext(ctx, metadata.New(headers)), customerLogExportTimeout)
+	defer cancel()
+	if _, err := client.Export(exportCtx, req); err != nil {
+		s.dropped.Add(uint64(len(batch)))
+	}
+}
+
+func (s *customerLogShipper) close() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.enabled.Store(false)
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+		s.client = nil
+	}
+}
+
+// customerLogHandler is a slog.Handler that sends launcher logs to the customer collector.
+// Logs are correlated with traces via the span in the logging context, if any.
+type customerLogHandler struct {
+	shipper *customerLogShipper
+	attrs   []*commonpb.KeyValue
+	group   string // prefix for attribute keys, from WithGroup
+}
+
+func (h *customerLogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= customerLogLevel && h.shipper.enabled.Load()
+}
+
+func (h *customerLogHandler) Handle(ctx context.Context, r slog.Record) error {
+	attrs := make([]*commonpb.KeyValue, 0, len(h.attrs)+r.NumAttrs())
+	attrs = append(attrs, h.attrs...)
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = appendSlogAttr(attrs, h.group, a)
+		return true
+	})
+
+	record := &logspb.LogRecord{
+		TimeUnixNano:         uint64(r.Time.UnixNano()),
+		ObservedTimeUnixNano: uint64(time.Now().UnixNano()),
+		SeverityNumber:       severityNumber(r.Level),
+		SeverityText:         r.Level.String(),
+		Body:                 &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: r.Message}},
+		Attributes:           attrs,
+	}
+
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		traceID := spanContext.TraceID()
+		spanID := spanContext.SpanID()
+		record.TraceId = traceID[:]
+		record.SpanId = spanID[:]
+		record.Flags = uint32(spanContext.TraceFlags())
+	}
+
+	h.shipper.enqueue(record)
+	return nil
+}
+
+func (h *customerLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	newAttrs := make([]*commonpb.KeyValue, 0, len(h.attrs)+len(attrs))
+	newAttrs = append(newAttrs, h.attrs...)
+	for _, a := range attrs {
+		newAttrs = appendSlogAttr(newAttrs, h.group, a)
+	}
+	return &customerLogHandler{shipper: h.shipper, attrs: newAttrs, group: h.group}
+}
+
+func (h *customerLogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &customerLogHandler{shipper: h.shipper, attrs: h.attrs, group: h.group + name + "."}
+}
+
+func severityNumber(level slog.Level) logspb.SeverityNumber {
+	switch {
+	case level >= slog.LevelError:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_ERROR
+	case level >= slog.LevelWarn:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_WARN
+	case level >= slog.LevelInfo:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_INFO
+	default:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_DEBUG
+	}
+}
+
+// appendSlogAttr converts a slog attribute to OTLP attributes, flattening groups into dotted keys.
+func appendSlogAttr(kvs []*commonpb.KeyValue, prefix string, a slog.Attr) []*commonpb.KeyValue {
+	v := a.Value.Resolve()
+	if v.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		if a.Key != "" {
+			groupPrefix = prefix + a.Key + "."
+		}
+		for _, ga := range v.Group() {
+			kvs = appendSlogAttr(kvs, groupPrefix, ga)
+		}
+		return kvs
+	}
+	if a.Key == "" {
+		return kvs
+	}
+
+	var av *commonpb.AnyValue
+	switch v.Kind() {
+	case slog.KindBool:
+		av = &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: v.Bool()}}
+	case slog.KindInt64:
+		av = &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: v.Int64()}}
+	case slog.KindFloat64:
+		av = &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: v.Float64()}}
+	case slog.KindString:
+		av = &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v.String()}}
+	case slog.KindTime:
+		av = &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v.Time().Format(time.RFC3339Nano)}}
+	default:
+		// Durations, uints, and arbitrary values
+		av = &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v.String()}}
+	}
+
+	return append(kvs, &commonpb.KeyValue{Key: prefix + a.Key, Value: av})
+}
+
+// keyValuesToProto converts resource attributes to their OTLP representation.
+func keyValuesToProto(attrs []attribute.KeyValue) []*commonpb.KeyValue {
+	kvs := make([]*commonpb.KeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		var av *commonpb.AnyValue
+		switch attr.Value.Type() {
+		case attribute.BOOL:
+			av = &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: attr.Value.AsBool()}}
+		case attribute.INT64:
+			av = &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: attr.Value.AsInt64()}}
+		case attribute.FLOAT64:
+			av = &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: attr.Value.AsFloat64()}}
+		default:
+			av = &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: attr.Value.Emit()}}
+		}
+		kvs = append(kvs, &commonpb.KeyValue{Key: string(attr.Key), Value: av})
+	}
+	return kvs
+}
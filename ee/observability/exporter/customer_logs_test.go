@@ -0,0 +1,66 @@
+package exporter
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+)
+
+func TestCustomerLogHandler(t *testing.T) {
+	t.Parallel()
+
+	shipper := newCustomerLogShipper()
+	handler := &customerLogHandler{shipper: shipper}
+
+	// Nothing is accepted until the shipper is configured
+	require.False(t, handler.Enabled(context.TODO(), slog.LevelError))
+	shipper.enabled.Store(true)
+	require.False(t, handler.Enabled(context.TODO(), slog.LevelDebug))
+	require.True(t, handler.Enabled(context.TODO(), slog.LevelInfo))
+
+	traceID := trace.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	spanID := trace.SpanID{1, 2, 3, 4, 5, 6, 7, 8}
+	ctx := trace.ContextWithSpanContext(context.TODO(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	slogger := slog.New(handler).With("component", "test").WithGroup("request")
+	slogger.Log(ctx, slog.LevelWarn, "something happened",
+		"status", 404,
+		slog.Group("client", "ip", "127.0.0.1"),
+	)
+
+	require.Len(t, shipper.records, 1)
+	record := <-shipper.records
+
+	require.Equal(t, "something happened", record.Body.GetStringValue())
+	require.Equal(t, logspb.SeverityNumber_SEVERITY_NUMBER_WARN, record.SeverityNumber)
+	require.Equal(t, traceID[:], record.TraceId)
+	require.Equal(t, spanID[:], record.SpanId)
+
+	attrs := make(map[string]*commonpb.AnyValue)
+	for _, kv := range record.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	require.Equal(t, "test", attrs["component"].GetStringValue())
+	require.Equal(t, int64(404), attrs["request.status"].GetIntValue())
+	require.Equal(t, "127.0.0.1", attrs["request.client.ip"].GetStringValue())
+}
+
+func TestCustomerLogShipper_dropsWhenFull(t *testing.T) {
+	t.Parallel()
+
+	shipper := newCustomerLogShipper()
+	for range customerLogBufferSize + 10 {
+		shipper.enqueue(&logspb.LogRecord{})
+	}
+
+	require.Equal(t, uint64(10), shipper.dropped.Load())
+}
@@ -2,6 +2,7 @@ package exporter
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"runtime"
 	"sync"
@@ -22,6 +23,7 @@ import (
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
@@ -42,9 +44,14 @@ var archAttributeMap = map[string]attribute.KeyValue{
 
 var enrollmentDetailsRecheckInterval = 5 * time.Second
 
+// ErrLocalMetricsUnavailable is returned by CollectMetrics when no local metrics endpoint is configured,
+// or the meter provider has not been set up yet.
+var ErrLocalMetricsUnavailable = errors.New("local metrics are not available")
+
 type TelemetryExporter struct {
 	tracerProvider            *sdktrace.TracerProvider
 	meterProvider             *sdkmetric.MeterProvider
+	localMetricsReader        *sdkmetric.ManualReader // set when the local metrics endpoint is enabled
 	providerLock              sync.Mutex
 	bufSpanProcessor          *bufspanprocessor.BufSpanProcessor
 	knapsack                  types.Knapsack
@@ -56,6 +63,10 @@ type TelemetryExporter struct {
 	ingestUrl                 string
 	disableIngestTLS          bool
 	enabled                   bool
+	localMetricsEnabled       bool
+	customer                  *customerCollectorConfig // set when export to a customer collector is configured
+	customerLogs              *customerLogShipper
+	kolideSpanProcessor       sdktrace.SpanProcessor // the span processor registered for export to Kolide, if any
 	traceSamplingRate         float64
 	gomaxprocsAttrValue       *atomic.Int64
 	batchTimeout              time.Duration
@@ -64,7 +75,10 @@ type TelemetryExporter struct {
 	interrupted               atomic.Bool
 }
 
-// NewTelemetryExporter sets up our telemetry (traces and soon metrics) to be exported via OTLP over HTTP.
+// NewTelemetryExporter sets up our telemetry (traces and metrics) to be exported via OTLP over HTTP.
+// Telemetry is exported to Kolide's ingest server, and/or to a customer collector if one is configured;
+// launcher logs are also exported to the customer collector. If the local metrics endpoint is enabled,
+// metrics are also made available via CollectMetrics, regardless of whether export is enabled.
 // On interrupt, the provider will be shut down.
 func NewTelemetryExporter(ctx context.Context, k types.Knapsack, initialTraceBuffer *InitialTraceBuffer) (*TelemetryExporter, error) {
 	ctx, span := observability.StartSpan(ctx)
@@ -83,16 +97,28 @@ func NewTelemetryExporter(ctx context.Context, k types.Knapsack, initialTraceBuf
 		ingestUrl:                 k.TraceIngestServerURL(),
 		disableIngestTLS:          k.DisableTraceIngestTLS(),
 		enabled:                   k.ExportTraces(),
+		localMetricsEnabled:       k.MetricsEndpointAddress() != "",
 		traceSamplingRate:         k.TraceSamplingRate(),
+		customerLogs:              newCustomerLogShipper(),
 		gomaxprocsAttrValue:       &atomic.Int64{},
 		batchTimeout:              k.TraceBatchTimeout(),
 		ctx:                       ctx,
 		cancel:                    cancel,
 	}
 
+	if customer, err := loadCustomerCollectorConfig(k); err != nil {
+		t.slogger.Log(ctx, slog.LevelWarn,
+			"could not load customer collector configuration",
+			"err", err,
+		)
+	} else {
+		t.customer = customer
+	}
+
 	if initialTraceBuffer != nil {
 		t.tracerProvider = initialTraceBuffer.provider
 		t.bufSpanProcessor = initialTraceBuffer.bufSpanProcessor
+		t.kolideSpanProcessor = initialTraceBuffer.bufSpanProcessor
 		t.attrs = initialTraceBuffer.attrs
 	} else {
 		t.bufSpanProcessor = bufspanprocessor.NewBufSpanProcessor(500)
@@ -102,9 +128,10 @@ func NewTelemetryExporter(ctx context.Context, k types.Knapsack, initialTraceBuf
 
 	// Observe changes to trace configuration to know when to start/stop exporting, and when
 	// to adjust exporting behavior
-	t.knapsack.RegisterChangeObserver(t, keys.ExportTraces, keys.TraceSamplingRate, keys.TraceIngestServerURL, keys.DisableTraceIngestTLS, keys.TraceBatchTimeout, keys.LauncherGoMaxProcs)
+	observedKeys := []keys.FlagKey{keys.ExportTraces, keys.TraceSamplingRate, keys.TraceIngestServerURL, keys.DisableTraceIngestTLS, keys.TraceBatchTimeout, keys.LauncherGoMaxProcs}
+	t.knapsack.RegisterChangeObserver(t, append(observedKeys, customerCollectorKeys...)...)
 
-	if !t.enabled {
+	if !t.exporting() {
 		return t, nil
 	}
 
@@ -211,9 +238,48 @@ func (t *TelemetryExporter) addAttributesFromEnrollmentDetails(details types.Enr
 	)
 }
 
+// exporting returns true if we are exporting telemetry anywhere.
+func (t *TelemetryExporter) exporting() bool {
+	return t.enabled || t.customer != nil
+}
+
+// kolideExportEnabled returns true if we are exporting traces and metrics to Kolide's ingest server.
+func (t *TelemetryExporter) kolideExportEnabled() bool {
+	return t.enabled && (t.customer == nil || !t.customer.only)
+}
+
+// CustomerLogHandler returns a slog.Handler that exports launcher logs to the customer collector,
+// when one is configured.
+func (t *TelemetryExporter) CustomerLogHandler() slog.Handler {
+	return &customerLogHandler{shipper: t.customerLogs}
+}
+
+// updateCustomerLogs points the customer log shipper at the current customer collector, if any.
+func (t *TelemetryExporter) updateCustomerLogs() {
+	if err := t.customerLogs.setConfig(t.customer, t.launcherResource().Attributes()); err != nil {
+		t.slogger.Log(context.TODO(), slog.LevelWarn,
+			"could not set up customer collector log export",
+			"err", err,
+		)
+	}
+}
+
 // setNewGlobalProvider creates and sets new global providers with the currently-available
 // attributes. If providers were previously set, they will be shut down.
 func (t *TelemetryExporter) setNewGlobalProvider(rebuildExporter bool) {
+	r := t.launcherResource()
+
+	t.setNewGlobalTracerProvider(r, rebuildExporter)
+	t.setNewGlobalMeterProvider(r)
+
+	// set ingest url after successfully setting up new child processor
+	if t.kolideExportEnabled() {
+		t.ingestUrl = t.knapsack.TraceIngestServerURL()
+	}
+}
+
+// launcherResource returns a resource with the currently-available attributes.
+func (t *TelemetryExporter) launcherResource() *resource.Resource {
 	t.attrLock.RLock()
 	defer t.attrLock.RUnlock()
 
@@ -237,11 +303,7 @@ func (t *TelemetryExporter) setNewGlobalProvider(rebuildExporter bool) {
 		r = resource.Default()
 	}
 
-	t.setNewGlobalTracerProvider(r, rebuildExporter)
-	t.setNewGlobalMeterProvider(r)
-
-	// set ingest url after successfully setting up new child processor
-	t.ingestUrl = t.knapsack.TraceIngestServerURL()
+	return r
 }
 
 // setNewGlobalTracerProvider updates the global tracer provider:
@@ -251,16 +313,53 @@ func (t *TelemetryExporter) setNewGlobalTracerProvider(launcherResource *resourc
 	t.providerLock.Lock()
 	defer t.providerLock.Unlock()
 
-	// Sample root spans based on t.traceSamplingRate, then sample child spans based on the
+	// When exporting to both Kolide and a customer collector, sample at the higher of the two rates,
+	// and filter spans down to each destination's rate.
+	samplingRate := 0.0
+	if t.kolideExportEnabled() {
+		samplingRate = t.traceSamplingRate
+	}
+	if t.customer != nil {
+		samplingRate = max(samplingRate, t.customer.samplingRate)
+	}
+
+	// Sample root spans based on samplingRate, then sample child spans based on the
 	// decision made for their parent: if parent is sampled, then children should be as well;
 	// otherwise, do not sample child spans.
-	parentBasedSampler := sdktrace.ParentBased(sdktrace.TraceIDRatioBased(t.traceSamplingRate))
+	parentBasedSampler := sdktrace.ParentBased(sdktrace.TraceIDRatioBased(samplingRate))
 
-	newProvider := sdktrace.NewTracerProvider(
-		sdktrace.WithSpanProcessor(t.bufSpanProcessor),
+	providerOpts := []sdktrace.TracerProviderOption{
 		sdktrace.WithResource(launcherResource),
 		sdktrace.WithSampler(parentBasedSampler),
-	)
+	}
+
+	var kolideSpanProcessor sdktrace.SpanProcessor
+	if t.kolideExportEnabled() {
+		kolideSpanProcessor = t.bufSpanProcessor
+		if t.traceSamplingRate < samplingRate {
+			kolideSpanProcessor = newRatioFilterSpanProcessor(t.bufSpanProcessor, t.traceSamplingRate)
+		}
+		providerOpts = append(providerOpts, sdktrace.WithSpanProcessor(kolideSpanProcessor))
+	}
+
+	if t.customer != nil {
+		// The customer exporter is recreated along with the provider, and shut down with the old provider
+		customerExporter, err := otlptrace.New(t.ctx, otlptracegrpc.NewClient(t.customer.traceExporterOpts()...))
+		if err != nil {
+			t.slogger.Log(context.TODO(), slog.LevelWarn,
+				"could not create customer collector trace exporter",
+				"err", err,
+			)
+		} else {
+			var customerSpanProcessor sdktrace.SpanProcessor = sdktrace.NewBatchSpanProcessor(customerExporter, sdktrace.WithBatchTimeout(t.batchTimeout))
+			if t.customer.samplingRate < samplingRate {
+				customerSpanProcessor = newRatioFilterSpanProcessor(customerSpanProcessor, t.customer.samplingRate)
+			}
+			providerOpts = append(providerOpts, sdktrace.WithSpanProcessor(customerSpanProcessor))
+		}
+	}
+
+	newProvider := sdktrace.NewTracerProvider(providerOpts...)
 
 	otel.SetTracerProvider(newProvider)
 	osquerygotraces.SetTracerProvider(newProvider)
@@ -268,7 +367,9 @@ func (t *TelemetryExporter) setNewGlobalTracerProvider(launcherResource *resourc
 	if t.tracerProvider != nil {
 		// shutdown still gets called even though the span processor is unregistered
 		// leaving this in because it just feel correct
-		t.tracerProvider.UnregisterSpanProcessor(t.bufSpanProcessor)
+		if t.kolideSpanProcessor != nil {
+			t.tracerProvider.UnregisterSpanProcessor(t.kolideSpanProcessor)
+		}
 		if err := t.tracerProvider.Shutdown(t.ctx); err != nil {
 			t.slogger.Log(t.ctx, slog.LevelWarn,
 				"could not shut down old tracer provider to replace it",
@@ -278,8 +379,9 @@ func (t *TelemetryExporter) setNewGlobalTracerProvider(launcherResource *resourc
 	}
 
 	t.tracerProvider = newProvider
+	t.kolideSpanProcessor = kolideSpanProcessor
 
-	if !rebuildExporter {
+	if !rebuildExporter || !t.kolideExportEnabled() {
 		return
 	}
 
@@ -310,35 +412,59 @@ func (t *TelemetryExporter) setNewGlobalTracerProvider(launcherResource *resourc
 }
 
 // setNewGlobalMeterProvider updates the global meter provider:
-// * It creates a metrics exporter to ship the metrics with the latest ingest server URL and authentication
+// * If export is enabled, it creates a metrics exporter to ship the metrics with the latest ingest server URL and authentication
+// * If the local metrics endpoint is enabled, it creates a reader for the endpoint to collect metrics from
 // * It sets the latest launcher attributes on the resource, so that those details will be exported with the metrics
 // (Unlike with setNewGlobalTracerProvider, we always have to create a new exporter here.)
 func (t *TelemetryExporter) setNewGlobalMeterProvider(launcherResource *resource.Resource) {
 	t.providerLock.Lock()
 	defer t.providerLock.Unlock()
 
-	traceClientOpts := []otlpmetricgrpc.Option{
-		otlpmetricgrpc.WithEndpoint(t.knapsack.TraceIngestServerURL()),
-		otlpmetricgrpc.WithDialOption(grpc.WithPerRPCCredentials(t.ingestClientAuthenticator)),
+	meterProviderOpts := []sdkmetric.Option{
+		sdkmetric.WithResource(launcherResource),
 	}
-	if t.disableIngestTLS {
-		traceClientOpts = append(traceClientOpts, otlpmetricgrpc.WithInsecure())
+
+	if t.kolideExportEnabled() {
+		traceClientOpts := []otlpmetricgrpc.Option{
+			otlpmetricgrpc.WithEndpoint(t.knapsack.TraceIngestServerURL()),
+			otlpmetricgrpc.WithDialOption(grpc.WithPerRPCCredentials(t.ingestClientAuthenticator)),
+		}
+		if t.disableIngestTLS {
+			traceClientOpts = append(traceClientOpts, otlpmetricgrpc.WithInsecure())
+		}
+
+		metricsExporter, err := otlpmetricgrpc.New(context.TODO(), traceClientOpts...)
+		if err != nil {
+			t.slogger.Log(context.TODO(), slog.LevelWarn,
+				"could not create metrics gRPC exporter",
+				"err", err,
+			)
+			return
+		}
+		meterProviderOpts = append(meterProviderOpts, sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricsExporter, sdkmetric.WithInterval(15*time.Minute))))
 	}
 
-	metricsExporter, err := otlpmetricgrpc.New(context.TODO(), traceClientOpts...)
-	if err != nil {
-		t.slogger.Log(context.TODO(), slog.LevelWarn,
-			"could not create metrics gRPC exporter",
-			"err", err,
-		)
-		return
+	if t.customer != nil {
+		customerExporter, err := otlpmetricgrpc.New(context.TODO(), t.customer.metricExporterOpts()...)
+		if err != nil {
+			t.slogger.Log(context.TODO(), slog.LevelWarn,
+				"could not create customer collector metrics exporter",
+				"err", err,
+			)
+		} else {
+			meterProviderOpts = append(meterProviderOpts, sdkmetric.WithReader(sdkmetric.NewPeriodicReader(customerExporter, sdkmetric.WithInterval(1*time.Minute))))
+		}
+	}
+
+	// A reader can only be registered with a single meter provider, so we need a new one every time
+	var localMetricsReader *sdkmetric.ManualReader
+	if t.localMetricsEnabled {
+		localMetricsReader = sdkmetric.NewManualReader()
+		meterProviderOpts = append(meterProviderOpts, sdkmetric.WithReader(localMetricsReader))
 	}
 
 	// Create new meter provider and let otel set it globally
-	newMeterProvider := sdkmetric.NewMeterProvider(
-		sdkmetric.WithResource(launcherResource),
-		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricsExporter, sdkmetric.WithInterval(15*time.Minute))),
-	)
+	newMeterProvider := sdkmetric.NewMeterProvider(meterProviderOpts...)
 	otel.SetMeterProvider(newMeterProvider)
 
 	// Shut down and replace old meter provider with new one
@@ -351,18 +477,43 @@ func (t *TelemetryExporter) setNewGlobalMeterProvider(launcherResource *resource
 		}
 	}
 	t.meterProvider = newMeterProvider
+	t.localMetricsReader = localMetricsReader
 
 	observability.ReinitializeMetrics()
 	dedup.ReinitializeMetrics()
 }
 
+// CollectMetrics gathers the current value of all metrics, for the local metrics endpoint.
+func (t *TelemetryExporter) CollectMetrics(ctx context.Context) (*metricdata.ResourceMetrics, error) {
+	t.providerLock.Lock()
+	defer t.providerLock.Unlock()
+
+	if t.localMetricsReader == nil {
+		return nil, ErrLocalMetricsUnavailable
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := t.localMetricsReader.Collect(ctx, &rm); err != nil {
+		return nil, err
+	}
+	return &rm, nil
+}
+
 // Execute begins exporting telemetry if exporting is enabled.
 func (t *TelemetryExporter) Execute() error {
-	if t.enabled {
+	gowrapper.Go(t.ctx, t.slogger, func() {
+		t.customerLogs.run(t.ctx)
+	})
+	t.updateCustomerLogs()
+
+	if t.exporting() {
 		t.setNewGlobalProvider(true)
 		t.slogger.Log(context.TODO(), slog.LevelDebug,
 			"successfully replaced global provider after adding more attributes",
 		)
+	} else if t.localMetricsEnabled {
+		// We are not exporting, but still need a meter provider to serve the local metrics endpoint
+		t.setNewGlobalMeterProvider(t.launcherResource())
 	}
 
 	<-t.ctx.Done()
@@ -446,29 +597,43 @@ func (t *TelemetryExporter) FlagsChanged(ctx context.Context, flagKeys ...keys.F
 			)
 		} else if t.enabled && !t.knapsack.ExportTraces() {
 			// Newly disabled
-			if t.tracerProvider != nil {
-				if err := t.tracerProvider.Shutdown(context.TODO()); err != nil {
-					t.slogger.Log(ctx, slog.LevelWarn,
-						"could not shut down tracer provider on trace disable",
-						"err", err,
-					)
-				}
-			}
-			if t.meterProvider != nil {
-				if err := t.meterProvider.Shutdown(context.TODO()); err != nil {
-					t.slogger.Log(ctx, slog.LevelWarn,
-						"could not shut down meter provider on trace disable",
-						"err", err,
-					)
-				}
-			}
 			t.enabled = false
+			if t.customer != nil {
+				// Still exporting to the customer collector
+				needsNewProvider = true
+			} else {
+				t.stopExporting(ctx)
+			}
 			t.slogger.Log(ctx, slog.LevelDebug,
 				"disabling telemetry export",
 			)
 		}
 	}
 
+	// Handle customer collector configuration updates
+	if customerCollectorChanged(flagKeys) {
+		hadCustomer := t.customer != nil
+		customer, err := loadCustomerCollectorConfig(t.knapsack)
+		if err != nil {
+			t.slogger.Log(ctx, slog.LevelWarn,
+				"could not load customer collector configuration, disabling export to customer collector",
+				"err", err,
+			)
+		}
+		t.customer = customer
+		t.updateCustomerLogs()
+
+		if t.customer != nil || t.enabled {
+			needsNewProvider = true
+		} else if hadCustomer {
+			t.stopExporting(ctx)
+		}
+		t.slogger.Log(ctx, slog.LevelDebug,
+			"updated customer collector configuration",
+			"enabled", t.customer != nil,
+		)
+	}
+
 	// Handle trace_sampling_rate updates
 	if keys.Contains(flagKeys, keys.TraceSamplingRate) {
 		if t.traceSamplingRate != t.knapsack.TraceSamplingRate() {
@@ -532,9 +697,33 @@ func (t *TelemetryExporter) FlagsChanged(ctx context.Context, flagKeys ...keys.F
 		}
 	}
 
-	if !t.enabled || !needsNewProvider {
+	if !t.exporting() || !needsNewProvider {
 		return
 	}
 
 	t.setNewGlobalProvider(true)
 }
+
+// stopExporting shuts down our providers once we are no longer exporting anywhere. If the local
+// metrics endpoint is enabled, the meter provider is replaced with one that serves only the endpoint.
+func (t *TelemetryExporter) stopExporting(ctx context.Context) {
+	if t.tracerProvider != nil {
+		if err := t.tracerProvider.Shutdown(context.TODO()); err != nil {
+			t.slogger.Log(ctx, slog.LevelWarn,
+				"could not shut down tracer provider on trace disable",
+				"err", err,
+			)
+		}
+	}
+
+	if t.localMetricsEnabled {
+		t.setNewGlobalMeterProvider(t.launcherResource())
+	} else if t.meterProvider != nil {
+		if err := t.meterProvider.Shutdown(context.TODO()); err != nil {
+			t.slogger.Log(ctx, slog.LevelWarn,
+				"could not shut down meter provider on trace disable",
+				"err", err,
+			)
+		}
+	}
+}
@@ -17,6 +17,7 @@ import (
 	storageci "github.com/kolide/launcher/v2/ee/agent/storage/ci"
 	"github.com/kolide/launcher/v2/ee/agent/types"
 	typesmocks "github.com/kolide/launcher/v2/ee/agent/types/mocks"
+	"github.com/kolide/launcher/v2/ee/observability"
 	"github.com/kolide/launcher/v2/ee/observability/bufspanprocessor"
 	"github.com/kolide/launcher/v2/pkg/log/multislogger"
 	"github.com/kolide/launcher/v2/pkg/threadsafebuffer"
@@ -48,9 +49,12 @@ func TestNewTelemetryExporter(t *testing.T) { //nolint:paralleltest
 	mockKnapsack.On("TraceIngestServerURL").Return("localhost:3417")
 	mockKnapsack.On("DisableTraceIngestTLS").Return(false)
 	mockKnapsack.On("ExportTraces").Return(true)
+	mockKnapsack.On("MetricsEndpointAddress").Return("")
 	mockKnapsack.On("TraceSamplingRate").Return(1.0)
 	mockKnapsack.On("TraceBatchTimeout").Return(1 * time.Minute)
-	mockKnapsack.On("RegisterChangeObserver", mock.Anything, keys.ExportTraces, keys.TraceSamplingRate, keys.TraceIngestServerURL, keys.DisableTraceIngestTLS, keys.TraceBatchTimeout, keys.LauncherGoMaxProcs).Return(nil)
+	mockKnapsack.On("CustomerOTLPEndpoint").Return("")
+	mockKnapsack.On("RegisterChangeObserver", mock.Anything, keys.ExportTraces, keys.TraceSamplingRate, keys.TraceIngestServerURL, keys.DisableTraceIngestTLS, keys.TraceBatchTimeout, keys.LauncherGoMaxProcs,
+		keys.CustomerOTLPEndpoint, keys.CustomerOTLPHeaders, keys.CustomerOTLPCAPath, keys.DisableCustomerOTLPTLS, keys.CustomerOTLPSamplingRate, keys.CustomerOTLPOnly).Return(nil)
 	mockKnapsack.On("Slogger").Return(multislogger.NewNopLogger())
 	mockKnapsack.On("UpdateChannel").Return("nightly").Maybe()
 	mockKnapsack.On("GetRunID").Return(ulid.New()).Maybe()
@@ -91,9 +95,12 @@ func TestNewTelemetryExporter_exportNotEnabled(t *testing.T) {
 	mockKnapsack.On("TraceIngestServerURL").Return("localhost:3417")
 	mockKnapsack.On("DisableTraceIngestTLS").Return(false)
 	mockKnapsack.On("ExportTraces").Return(false)
+	mockKnapsack.On("MetricsEndpointAddress").Return("")
 	mockKnapsack.On("TraceSamplingRate").Return(0.0)
 	mockKnapsack.On("TraceBatchTimeout").Return(1 * time.Minute)
-	mockKnapsack.On("RegisterChangeObserver", mock.Anything, keys.ExportTraces, keys.TraceSamplingRate, keys.TraceIngestServerURL, keys.DisableTraceIngestTLS, keys.TraceBatchTimeout, keys.LauncherGoMaxProcs).Return(nil)
+	mockKnapsack.On("CustomerOTLPEndpoint").Return("")
+	mockKnapsack.On("RegisterChangeObserver", mock.Anything, keys.ExportTraces, keys.TraceSamplingRate, keys.TraceIngestServerURL, keys.DisableTraceIngestTLS, keys.TraceBatchTimeout, keys.LauncherGoMaxProcs,
+		keys.CustomerOTLPEndpoint, keys.CustomerOTLPHeaders, keys.CustomerOTLPCAPath, keys.DisableCustomerOTLPTLS, keys.CustomerOTLPSamplingRate, keys.CustomerOTLPOnly).Return(nil)
 	mockKnapsack.On("UpdateChannel").Return("alpha").Maybe()
 	mockKnapsack.On("GetRunID").Return(ulid.New()).Maybe()
 	mockKnapsack.On("Slogger").Return(multislogger.NewNopLogger())
@@ -133,9 +140,12 @@ func TestInterrupt_Multiple(t *testing.T) { //nolint:paralleltest
 	mockKnapsack.On("TraceIngestServerURL").Return("localhost:3417")
 	mockKnapsack.On("DisableTraceIngestTLS").Return(false)
 	mockKnapsack.On("ExportTraces").Return(false)
+	mockKnapsack.On("MetricsEndpointAddress").Return("")
 	mockKnapsack.On("TraceSamplingRate").Return(0.0)
 	mockKnapsack.On("TraceBatchTimeout").Return(1 * time.Minute)
-	mockKnapsack.On("RegisterChangeObserver", mock.Anything, keys.ExportTraces, keys.TraceSamplingRate, keys.TraceIngestServerURL, keys.DisableTraceIngestTLS, keys.TraceBatchTimeout, keys.LauncherGoMaxProcs).Return(nil)
+	mockKnapsack.On("CustomerOTLPEndpoint").Return("")
+	mockKnapsack.On("RegisterChangeObserver", mock.Anything, keys.ExportTraces, keys.TraceSamplingRate, keys.TraceIngestServerURL, keys.DisableTraceIngestTLS, keys.TraceBatchTimeout, keys.LauncherGoMaxProcs,
+		keys.CustomerOTLPEndpoint, keys.CustomerOTLPHeaders, keys.CustomerOTLPCAPath, keys.DisableCustomerOTLPTLS, keys.CustomerOTLPSamplingRate, keys.CustomerOTLPOnly).Return(nil)
 	mockKnapsack.On("UpdateChannel").Return("beta").Maybe()
 	mockKnapsack.On("GetRunID").Return(ulid.New()).Maybe()
 	var logBytes threadsafebuffer.ThreadSafeBuffer
@@ -178,6 +188,48 @@ func TestInterrupt_Multiple(t *testing.T) { //nolint:paralleltest
 	require.Equal(t, expectedInterrupts, receivedInterrupts)
 }
 
+func TestCollectMetrics_localOnly(t *testing.T) { //nolint:paralleltest
+	mockKnapsack := typesmocks.NewKnapsack(t)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	telemetryExporter := &TelemetryExporter{
+		knapsack:            mockKnapsack,
+		slogger:             multislogger.NewNopLogger(),
+		attrs:               make([]attribute.KeyValue, 0),
+		attrLock:            sync.RWMutex{},
+		enabled:             false,
+		localMetricsEnabled: true,
+		gomaxprocsAttrValue: &atomic.Int64{},
+		ctx:                 ctx,
+		cancel:              cancel,
+	}
+	t.Cleanup(func() {
+		telemetryExporter.Interrupt(errors.New("test"))
+	})
+
+	// No metrics are available until the meter provider is set up
+	_, err := telemetryExporter.CollectMetrics(ctx)
+	require.ErrorIs(t, err, ErrLocalMetricsUnavailable)
+
+	telemetryExporter.setNewGlobalMeterProvider(telemetryExporter.launcherResource())
+	require.NotNil(t, telemetryExporter.meterProvider)
+
+	observability.TablewrapperTimeoutCounter.Add(ctx, 1)
+
+	rm, err := telemetryExporter.CollectMetrics(ctx)
+	require.NoError(t, err)
+
+	found := false
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == "launcher.tablewrapper.timeout" {
+				found = true
+			}
+		}
+	}
+	require.True(t, found, "expected to collect counter from local metrics reader")
+}
+
 func Test_addOrgAttributes(t *testing.T) {
 	t.Parallel()
 
@@ -812,3 +864,48 @@ func testTokenStore(t *testing.T) types.KVStore {
 	require.NoError(t, err)
 	return s
 }
+
+func TestFlagsChanged_CustomerCollector(t *testing.T) { //nolint:paralleltest
+	customerEndpoint := "localhost:4317"
+	mockKnapsack := typesmocks.NewKnapsack(t)
+	mockKnapsack.On("CustomerOTLPEndpoint").Return(func() string { return customerEndpoint })
+	mockKnapsack.On("CustomerOTLPHeaders").Return("x-api-key=abcd").Maybe()
+	mockKnapsack.On("DisableCustomerOTLPTLS").Return(true).Maybe()
+	mockKnapsack.On("CustomerOTLPSamplingRate").Return(0.5).Maybe()
+	mockKnapsack.On("CustomerOTLPOnly").Return(false).Maybe()
+	mockKnapsack.On("UpdateChannel").Return("nightly").Maybe()
+	mockKnapsack.On("GetRunID").Return(ulid.New()).Maybe()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	traceExporter := &TelemetryExporter{
+		knapsack:            mockKnapsack,
+		bufSpanProcessor:    bufspanprocessor.NewBufSpanProcessor(500),
+		slogger:             multislogger.NewNopLogger(),
+		attrs:               make([]attribute.KeyValue, 0),
+		attrLock:            sync.RWMutex{},
+		enabled:             false,
+		traceSamplingRate:   1.0,
+		customerLogs:        newCustomerLogShipper(),
+		gomaxprocsAttrValue: &atomic.Int64{},
+		ctx:                 ctx,
+		cancel:              cancel,
+	}
+	t.Cleanup(func() {
+		traceExporter.Interrupt(errors.New("test"))
+	})
+
+	// Configuring a customer collector starts export, even though export to Kolide is disabled
+	traceExporter.FlagsChanged(ctx, keys.CustomerOTLPEndpoint)
+	require.NotNil(t, traceExporter.customer)
+	require.Equal(t, map[string]string{"x-api-key": "abcd"}, traceExporter.customer.headers)
+	require.NotNil(t, traceExporter.tracerProvider)
+	require.NotNil(t, traceExporter.meterProvider)
+	require.Nil(t, traceExporter.kolideSpanProcessor, "should not export to Kolide")
+	require.True(t, traceExporter.customerLogs.enabled.Load())
+
+	// Removing the customer collector stops export
+	customerEndpoint = ""
+	traceExporter.FlagsChanged(ctx, keys.CustomerOTLPEndpoint)
+	require.Nil(t, traceExporter.customer)
+	require.False(t, traceExporter.customerLogs.enabled.Load())
+}
@@ -52,6 +52,8 @@ const (
 	autoupdateFailureCounterDescription          = "The number of TUF autoupdate failures"
 	checkupErrorCounterName                      = "launcher.checkup.error"
 	checkupErrorCounterDescription               = "The number of errors when running checkups"
+	autoupdateRollbackCounterName                = "launcher.autoupdate.rollback"
+	autoupdateRollbackCounterDescription         = "The number of automatic rollbacks of launcher updates that failed post-update probation"
 )
 
 var (
@@ -77,6 +79,7 @@ var (
 	TablewrapperTimeoutCounter        metric.Int64Counter
 	AutoupdateFailureCounter          metric.Int64Counter
 	CheckupErrorCounter               metric.Int64Counter
+	AutoupdateRollbackCounter         metric.Int64Counter
 )
 
 // Initialize all of our meters. All meter names should have "launcher." prepended,
@@ -145,6 +148,9 @@ func ReinitializeMetrics() {
 	CheckupErrorCounter = int64CounterOrNoop(checkupErrorCounterName,
 		metric.WithDescription(checkupErrorCounterDescription),
 		metric.WithUnit(unitFailure))
+	AutoupdateRollbackCounter = int64CounterOrNoop(autoupdateRollbackCounterName,
+		metric.WithDescription(autoupdateRollbackCounterDescription),
+		metric.WithUnit(unitFailure))
 }
 
 // int64GaugeOrNoop is guaranteed to return an Int64Gauge -- if we cannot create
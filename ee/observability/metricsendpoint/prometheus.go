@@ -0,0 +1,237 @@
+package metricsendpoint
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// contentType is the content type for version 0.0.4 of the Prometheus text exposition format.
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// unitSuffixes maps the units our instruments use to the suffixes Prometheus expects on metric names.
+// Annotation units (e.g. "{restart}") and dimensionless counts get no suffix.
+var unitSuffixes = map[string]string{
+	"B":  "bytes",
+	"By": "bytes",
+	"%":  "percent",
+	"s":  "seconds",
+	"ms": "milliseconds",
+}
+
+// promMetric is a single metric family, ready to be written in the text exposition format.
+type promMetric struct {
+	name    string
+	help    string
+	kind    string // counter, gauge, or histogram
+	samples []promSample
+}
+
+type promSample struct {
+	suffix string // e.g. _bucket, for histograms
+	labels []label
+	value  float64
+}
+
+type label struct {
+	name  string
+	value string
+}
+
+// writePrometheus writes the given metrics in the Prometheus text exposition format. Metrics that
+// cannot be represented in the format (e.g. exponential histograms) are skipped.
+func writePrometheus(w io.Writer, rm *metricdata.ResourceMetrics) error {
+	families := make(map[string]*promMetric)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			converted := convertMetric(m)
+			if converted == nil {
+				continue
+			}
+			// The same instrument may be registered by multiple scopes -- merge them
+			if existing, ok := families[converted.name]; ok && existing.kind == converted.kind {
+				existing.samples = append(existing.samples, converted.samples...)
+				continue
+			}
+			families[converted.name] = converted
+		}
+	}
+
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	bw := bufio.NewWriter(w)
+	for _, name := range names {
+		f := families[name]
+		if f.help != "" {
+			fmt.Fprintf(bw, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+		}
+		fmt.Fprintf(bw, "# TYPE %s %s\n", f.name, f.kind)
+		for _, s := range f.samples {
+			bw.WriteString(f.name)
+			bw.WriteString(s.suffix)
+			writeLabels(bw, s.labels)
+			bw.WriteByte(' ')
+			bw.WriteString(formatFloat(s.value))
+			bw.WriteByte('\n')
+		}
+	}
+
+	return bw.Flush()
+}
+
+func convertMetric(m metricdata.Metrics) *promMetric {
+	name := metricName(m.Name, m.Unit)
+	switch data := m.Data.(type) {
+	case metricdata.Gauge[int64]:
+		return &promMetric{name: name, help: m.Description, kind: "gauge", samples: gaugeSamples(data.DataPoints)}
+	case metricdata.Gauge[float64]:
+		return &promMetric{name: name, help: m.Description, kind: "gauge", samples: gaugeSamples(data.DataPoints)}
+	case metricdata.Sum[int64]:
+		return sumMetric(name, m.Description, data.IsMonotonic, data.DataPoints)
+	case metricdata.Sum[float64]:
+		return sumMetric(name, m.Description, data.IsMonotonic, data.DataPoints)
+	case metricdata.Histogram[int64]:
+		return &promMetric{name: name, help: m.Description, kind: "histogram", samples: histogramSamples(data.DataPoints)}
+	case metricdata.Histogram[float64]:
+		return &promMetric{name: name, help: m.Description, kind: "histogram", samples: histogramSamples(data.DataPoints)}
+	default:
+		return nil
+	}
+}
+
+func gaugeSamples[N int64 | float64](points []metricdata.DataPoint[N]) []promSample {
+	samples := make([]promSample, 0, len(points))
+	for _, p := range points {
+		samples = append(samples, promSample{labels: attributeLabels(p.Attributes), value: float64(p.Value)})
+	}
+	return samples
+}
+
+func sumMetric[N int64 | float64](name string, help string, isMonotonic bool, points []metricdata.DataPoint[N]) *promMetric {
+	if !isMonotonic {
+		// Up-down counters are gauges, as far as Prometheus is concerned
+		return &promMetric{name: name, help: help, kind: "gauge", samples: gaugeSamples(points)}
+	}
+	return &promMetric{name: name + "_total", help: help, kind: "counter", samples: gaugeSamples(points)}
+}
+
+func histogramSamples[N int64 | float64](points []metricdata.HistogramDataPoint[N]) []promSample {
+	samples := make([]promSample, 0)
+	for _, p := range points {
+		labels := attributeLabels(p.Attributes)
+
+		// OTel bucket counts are per-bucket; Prometheus buckets are cumulative
+		var cumulative uint64
+		for i, bound := range p.Bounds {
+			if i < len(p.BucketCounts) {
+				cumulative += p.BucketCounts[i]
+			}
+			samples = append(samples, promSample{suffix: "_bucket", labels: withLabel(labels, "le", formatFloat(bound)), value: float64(cumulative)})
+		}
+		samples = append(samples,
+			promSample{suffix: "_bucket", labels: withLabel(labels, "le", "+Inf"), value: float64(p.Count)},
+			promSample{suffix: "_sum", labels: labels, value: float64(p.Sum)},
+			promSample{suffix: "_count", labels: labels, value: float64(p.Count)},
+		)
+	}
+	return samples
+}
+
+func withLabel(labels []label, name, value string) []label {
+	withNew := make([]label, 0, len(labels)+1)
+	withNew = append(withNew, labels...)
+	return append(withNew, label{name: name, value: value})
+}
+
+func attributeLabels(attrs attribute.Set) []label {
+	labels := make([]label, 0, attrs.Len())
+	iter := attrs.Iter()
+	for iter.Next() {
+		kv := iter.Attribute()
+		labels = append(labels, label{name: sanitizeName(string(kv.Key)), value: kv.Value.Emit()})
+	}
+	return labels
+}
+
+// metricName converts an OTel instrument name (e.g. launcher.memory.rss) and unit into a
+// Prometheus metric name (e.g. launcher_memory_rss_bytes).
+func metricName(name string, unit string) string {
+	name = sanitizeName(name)
+	if suffix, ok := unitSuffixes[unit]; ok && !strings.HasSuffix(name, "_"+suffix) {
+		name += "_" + suffix
+	}
+	return name
+}
+
+// sanitizeName replaces characters that are not valid in Prometheus metric and label names.
+func sanitizeName(name string) string {
+	var sb strings.Builder
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_', r == ':':
+			sb.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				sb.WriteRune('_')
+			}
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune('_')
+		}
+	}
+	return sb.String()
+}
+
+func writeLabels(bw *bufio.Writer, labels []label) {
+	if len(labels) == 0 {
+		return
+	}
+	bw.WriteByte('{')
+	for i, l := range labels {
+		if i > 0 {
+			bw.WriteByte(',')
+		}
+		bw.WriteString(l.name)
+		bw.WriteString(`="`)
+		bw.WriteString(escapeLabelValue(l.value))
+		bw.WriteByte('"')
+	}
+	bw.WriteByte('}')
+}
+
+var (
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func escapeLabelValue(s string) string {
+	return labelValueEscaper.Replace(s)
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+}
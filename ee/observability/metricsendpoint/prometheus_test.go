@@ -0,0 +1,72 @@
+package metricsendpoint
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func Test_writePrometheus(t *testing.T) {
+	t.Parallel()
+
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	t.Cleanup(func() { require.NoError(t, provider.Shutdown(t.Context())) })
+	meter := provider.Meter("test")
+
+	counter, err := meter.Int64Counter("launcher.autoupdate.failed", metric.WithDescription("The number of TUF autoupdate failures"), metric.WithUnit("{failure}"))
+	require.NoError(t, err)
+	counter.Add(t.Context(), 2, metric.WithAttributes(attribute.String("binary", "osqueryd")))
+
+	gauge, err := meter.Int64Gauge("launcher.memory.golang", metric.WithDescription("Go runtime memory usage"), metric.WithUnit("B"))
+	require.NoError(t, err)
+	gauge.Record(t.Context(), 1024)
+
+	histogram, err := meter.Float64Histogram("launcher.osquery.cpu.percent", metric.WithDescription("osquery process CPU percent"), metric.WithUnit("%"),
+		metric.WithExplicitBucketBoundaries(10, 50))
+	require.NoError(t, err)
+	histogram.Record(t.Context(), 5)
+	histogram.Record(t.Context(), 20)
+	histogram.Record(t.Context(), 80)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(t.Context(), &rm))
+
+	var out bytes.Buffer
+	require.NoError(t, writePrometheus(&out, &rm))
+
+	expected := `# HELP launcher_autoupdate_failed_total The number of TUF autoupdate failures
+# TYPE launcher_autoupdate_failed_total counter
+launcher_autoupdate_failed_total{binary="osqueryd"} 2
+# HELP launcher_memory_golang_bytes Go runtime memory usage
+# TYPE launcher_memory_golang_bytes gauge
+launcher_memory_golang_bytes 1024
+# HELP launcher_osquery_cpu_percent osquery process CPU percent
+# TYPE launcher_osquery_cpu_percent histogram
+launcher_osquery_cpu_percent_bucket{le="10"} 1
+launcher_osquery_cpu_percent_bucket{le="50"} 2
+launcher_osquery_cpu_percent_bucket{le="+Inf"} 3
+launcher_osquery_cpu_percent_sum 105
+launcher_osquery_cpu_percent_count 3
+`
+	require.Equal(t, expected, out.String())
+}
+
+func Test_sanitizeName(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "launcher_memory_rss", sanitizeName("launcher.memory.rss"))
+	require.Equal(t, "_1st_label", sanitizeName("1st-label"))
+	require.Equal(t, "already_valid:name", sanitizeName("already_valid:name"))
+}
+
+func Test_escapeLabelValue(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, `a \"quoted\" \\path\n`, escapeLabelValue("a \"quoted\" \\path\n"))
+}
@@ -0,0 +1,158 @@
+// Package metricsendpoint serves launcher's metrics on a localhost HTTP endpoint, in the Prometheus
+// text exposition format, so that local monitoring (e.g. a node-exporter based setup) can scrape
+// launcher health without an external service. It is opt-in, via the metrics_endpoint_address option.
+package metricsendpoint
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/kolide/launcher/v2/ee/agent/types"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+const metricsPath = "/metrics"
+
+// collector provides the current value of launcher's metrics -- see exporter.TelemetryExporter.
+type collector interface {
+	CollectMetrics(ctx context.Context) (*metricdata.ResourceMetrics, error)
+}
+
+type Server struct {
+	slogger     *slog.Logger
+	address     string
+	collector   collector
+	srv         *http.Server
+	interrupt   chan struct{}
+	interrupted atomic.Bool
+}
+
+// New returns a server for the configured metrics endpoint address. The address must be on the
+// loopback interface -- launcher metrics are not meant to be exposed to the network.
+func New(k types.Knapsack, c collector) (*Server, error) {
+	address := k.MetricsEndpointAddress()
+	if err := validateAddress(address); err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		slogger:   k.Slogger().With("component", "metrics_endpoint"),
+		address:   address,
+		collector: c,
+		interrupt: make(chan struct{}, 1),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(metricsPath, s.serveMetrics)
+	s.srv = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+		WriteTimeout:      30 * time.Second,
+	}
+
+	return s, nil
+}
+
+// validateAddress confirms that the address is a host:port on the loopback interface.
+func validateAddress(address string) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("invalid metrics endpoint address %q: %w", address, err)
+	}
+
+	if host == "localhost" {
+		return nil
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !ip.IsLoopback() {
+		return fmt.Errorf("metrics endpoint address %q is not a loopback address", address)
+	}
+
+	return nil
+}
+
+// Execute serves metrics until interrupted. Failing to serve is logged rather than returned, since
+// the metrics endpoint is not essential to launcher's operation.
+func (s *Server) Execute() error {
+	listener, err := net.Listen("tcp", s.address)
+	if err != nil {
+		s.slogger.Log(context.TODO(), slog.LevelWarn,
+			"could not listen on metrics endpoint address",
+			"address", s.address,
+			"err", err,
+		)
+		<-s.interrupt
+		return nil
+	}
+
+	s.slogger.Log(context.TODO(), slog.LevelInfo,
+		"serving metrics",
+		"address", listener.Addr().String(),
+	)
+
+	if err := s.srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		s.slogger.Log(context.TODO(), slog.LevelWarn,
+			"metrics endpoint stopped unexpectedly",
+			"err", err,
+		)
+	}
+
+	<-s.interrupt
+	return nil
+}
+
+func (s *Server) Interrupt(_ error) {
+	// Only perform shutdown tasks on first call to interrupt -- no need to repeat on potential extra calls.
+	if s.interrupted.Swap(true) {
+		return
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.srv.Shutdown(shutdownCtx); err != nil {
+		s.slogger.Log(context.TODO(), slog.LevelWarn,
+			"could not shut down metrics endpoint",
+			"err", err,
+		)
+	}
+
+	s.interrupt <- struct{}{}
+}
+
+func (s *Server) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	rm, err := s.collector.CollectMetrics(r.Context())
+	if err != nil {
+		s.slogger.Log(r.Context(), slog.LevelDebug,
+			"could not collect metrics",
+			"err", err,
+		)
+		http.Error(w, "metrics unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	// Render fully before writing, so that a failure doesn't produce a partial scrape
+	var buf bytes.Buffer
+	if err := writePrometheus(&buf, rm); err != nil {
+		http.Error(w, "could not render metrics", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet {
+		_, _ = w.Write(buf.Bytes())
+	}
+}
@@ -0,0 +1,124 @@
+package metricsendpoint
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kolide/launcher/v2/pkg/log/multislogger"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func Test_validateAddress(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		address   string
+		expectErr bool
+	}{
+		{address: "127.0.0.1:9464"},
+		{address: "localhost:9464"},
+		{address: "[::1]:9464"},
+		{address: "0.0.0.0:9464", expectErr: true},
+		{address: ":9464", expectErr: true},
+		{address: "192.168.1.10:9464", expectErr: true},
+		{address: "example.com:9464", expectErr: true},
+		{address: "127.0.0.1", expectErr: true},
+	} {
+		t.Run(tt.address, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateAddress(tt.address)
+			if tt.expectErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+type testCollector struct {
+	rm  *metricdata.ResourceMetrics
+	err error
+}
+
+func (c *testCollector) CollectMetrics(_ context.Context) (*metricdata.ResourceMetrics, error) {
+	return c.rm, c.err
+}
+
+func TestServeMetrics(t *testing.T) {
+	t.Parallel()
+
+	rm := &metricdata.ResourceMetrics{
+		ScopeMetrics: []metricdata.ScopeMetrics{
+			{
+				Scope: instrumentation.Scope{Name: "test"},
+				Metrics: []metricdata.Metrics{
+					{
+						Name: "launcher.restart",
+						Data: metricdata.Sum[int64]{
+							IsMonotonic: true,
+							Temporality: metricdata.CumulativeTemporality,
+							DataPoints:  []metricdata.DataPoint[int64]{{Value: 3}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range []struct {
+		name           string
+		method         string
+		collector      *testCollector
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "get",
+			method:         http.MethodGet,
+			collector:      &testCollector{rm: rm},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "# TYPE launcher_restart_total counter\nlauncher_restart_total 3\n",
+		},
+		{
+			name:           "post",
+			method:         http.MethodPost,
+			collector:      &testCollector{rm: rm},
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:           "unavailable",
+			method:         http.MethodGet,
+			collector:      &testCollector{err: errors.New("test error")},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   "metrics unavailable\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := &Server{
+				slogger:   multislogger.NewNopLogger(),
+				collector: tt.collector,
+			}
+
+			rr := httptest.NewRecorder()
+			s.serveMetrics(rr, httptest.NewRequest(tt.method, metricsPath, nil))
+
+			require.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedBody != "" {
+				require.Equal(t, tt.expectedBody, rr.Body.String())
+			}
+			if tt.expectedStatus == http.StatusOK {
+				require.True(t, strings.HasPrefix(rr.Header().Get("Content-Type"), "text/plain; version=0.0.4"))
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+//go:build linux
+
+package presencedetection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/user"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	fprintdName             = "net.reactivated.Fprint"
+	fprintdManagerPath      = "/net/reactivated/Fprint/Manager"
+	fprintdManagerInterface = "net.reactivated.Fprint.Manager"
+	fprintdDeviceInterface  = "net.reactivated.Fprint.Device"
+	fprintdVerifyStatus     = "VerifyStatus"
+	fprintdVerifyMatch      = "verify-match"
+	fprintdVerifyNoMatch    = "verify-no-match"
+	fprintdAnyFinger        = "any"
+
+	// maxFingerprintAttempts is how many non-matching scans we accept before giving up
+	maxFingerprintAttempts = 3
+
+	notificationName      = "org.freedesktop.Notifications"
+	notificationPath      = "/org/freedesktop/Notifications"
+	notificationInterface = "org.freedesktop.Notifications"
+)
+
+// verifyViaFprintd verifies the current user's fingerprint using the default fprintd device. fprintd
+// has no UI of its own, so we send a desktop notification asking the user to scan their finger.
+// See: https://fprint.freedesktop.org/fprintd-dev/Device.html
+func verifyViaFprintd(ctx context.Context, reason string) error {
+	currentUser, err := user.Current()
+	if err != nil {
+		return fmt.Errorf("getting current user: %w", err)
+	}
+
+	conn, err := dbus.ConnectSystemBus(dbus.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("connecting to system bus: %w", err)
+	}
+	defer conn.Close()
+
+	var devicePath dbus.ObjectPath
+	if err := conn.Object(fprintdName, fprintdManagerPath).CallWithContext(ctx, fprintdManagerInterface+".GetDefaultDevice", 0).Store(&devicePath); err != nil {
+		return fmt.Errorf("getting default fingerprint device: %w", err)
+	}
+	device := conn.Object(fprintdName, devicePath)
+
+	var enrolledFingers []string
+	if err := device.CallWithContext(ctx, fprintdDeviceInterface+".ListEnrolledFingers", 0, currentUser.Username).Store(&enrolledFingers); err != nil {
+		return fmt.Errorf("listing enrolled fingers: %w", err)
+	}
+	if len(enrolledFingers) == 0 {
+		return fmt.Errorf("no fingerprints enrolled for %s", currentUser.Username)
+	}
+
+	statusOpts := []dbus.MatchOption{
+		dbus.WithMatchObjectPath(devicePath),
+		dbus.WithMatchInterface(fprintdDeviceInterface),
+		dbus.WithMatchMember(fprintdVerifyStatus),
+	}
+	if err := conn.AddMatchSignalContext(ctx, statusOpts...); err != nil {
+		return fmt.Errorf("subscribing to verify status: %w", err)
+	}
+	signals := make(chan *dbus.Signal, 10)
+	conn.Signal(signals)
+	defer conn.RemoveSignal(signals)
+
+	// An empty username claims the device for the caller
+	if err := device.CallWithContext(ctx, fprintdDeviceInterface+".Claim", 0, "").Err; err != nil {
+		return fmt.Errorf("claiming fingerprint device: %w", err)
+	}
+	defer device.Call(fprintdDeviceInterface+".Release", 0)
+
+	notificationId := notifyFingerprintRequested(ctx, reason)
+	defer closeNotification(notificationId)
+
+	for attempt := 1; ; attempt++ {
+		if err := device.CallWithContext(ctx, fprintdDeviceInterface+".VerifyStart", 0, fprintdAnyFinger).Err; err != nil {
+			return fmt.Errorf("starting fingerprint verification: %w", err)
+		}
+
+		result, err := awaitVerifyResult(ctx, signals)
+		device.Call(fprintdDeviceInterface+".VerifyStop", 0)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case result == fprintdVerifyMatch:
+			return nil
+		case result == fprintdVerifyNoMatch && attempt < maxFingerprintAttempts:
+			continue
+		default:
+			return fmt.Errorf("fingerprint verification failed: %s", result)
+		}
+	}
+}
+
+// awaitVerifyResult waits for a VerifyStatus signal marking the end of the verification attempt,
+// and returns its result.
+func awaitVerifyResult(ctx context.Context, signals <-chan *dbus.Signal) (string, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("waiting for fingerprint: %w", ctx.Err())
+		case signal, ok := <-signals:
+			if !ok {
+				return "", errors.New("dbus signal channel closed")
+			}
+
+			result, done, ok := parseVerifyStatus(signal)
+			if !ok || !done {
+				// Intermediate results (e.g. verify-retry-scan) mean fprintd is still waiting on the user
+				continue
+			}
+
+			return result, nil
+		}
+	}
+}
+
+// parseVerifyStatus extracts the result and done flag from a VerifyStatus(s result, b done) signal.
+func parseVerifyStatus(signal *dbus.Signal) (string, bool, bool) {
+	if signal == nil || signal.Name != fprintdDeviceInterface+"."+fprintdVerifyStatus || len(signal.Body) < 2 {
+		return "", false, false
+	}
+
+	result, ok := signal.Body[0].(string)
+	if !ok {
+		return "", false, false
+	}
+	done, ok := signal.Body[1].(bool)
+	if !ok {
+		return "", false, false
+	}
+
+	return result, done, true
+}
+
+// notifyFingerprintRequested sends a best-effort desktop notification prompting the user to scan
+// their finger, returning its ID (or 0 if the notification could not be sent).
+func notifyFingerprintRequested(ctx context.Context, reason string) uint32 {
+	conn, err := dbus.ConnectSessionBus(dbus.WithContext(ctx))
+	if err != nil {
+		return 0
+	}
+	defer conn.Close()
+
+	var notificationId uint32
+	if err := conn.Object(notificationName, notificationPath).CallWithContext(ctx, notificationInterface+".Notify", 0,
+		"Kolide",                             // app_name
+		uint32(0),                            // replaces_id
+		"",                                   // app_icon
+		reason,                               // summary
+		"Scan your fingerprint to continue.", // body
+		[]string{},                           // actions
+		map[string]dbus.Variant{},            // hints
+		int32(0),                             // expire_timeout -- we close the notification ourselves
+	).Store(&notificationId); err != nil {
+		return 0
+	}
+
+	return notificationId
+}
+
+func closeNotification(notificationId uint32) {
+	if notificationId == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, err := dbus.ConnectSessionBus(dbus.WithContext(ctx))
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	conn.Object(notificationName, notificationPath).CallWithContext(ctx, notificationInterface+".CloseNotification", 0, notificationId)
+}
@@ -0,0 +1,183 @@
+//go:build linux
+
+package presencedetection
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	polkitName                    = "org.freedesktop.PolicyKit1"
+	polkitPath                    = "/org/freedesktop/PolicyKit1/Authority"
+	polkitAuthorityInterface      = "org.freedesktop.PolicyKit1.Authority"
+	polkitAllowUserInteraction    = uint32(1)
+	polkitErrorFailed             = "org.freedesktop.PolicyKit1.Error.Failed"
+	polkitErrorCancelled          = "org.freedesktop.PolicyKit1.Error.Cancelled"
+	polkitDismissedDetail         = "polkit.dismissed"
+	polkitCancellationIdPrefix    = "kolide-presence-"
+	polkitCancellationCallTimeout = 5 * time.Second
+)
+
+// polkitActionId is the polkit action we check authorization for. It is installed with the
+// launcher package (see pkg/packaging/assets/polkit-presence.policy), and requires the user to
+// authenticate as themselves each time. Each identifier installs its own action, so that
+// several launcher packages don't conflict.
+func polkitActionId(identifier string) string {
+	return fmt.Sprintf("com.kolide.launcher.%s.detect-presence", identifier)
+}
+
+// errPolkitUnavailable indicates that polkit cannot prompt the user, rather than that the user
+// failed to authenticate.
+var errPolkitUnavailable = errors.New("polkit cannot prompt for authentication")
+
+// polkitAuthorizationResult is the (bba{ss}) AuthorizationResult struct returned by CheckAuthorization.
+type polkitAuthorizationResult struct {
+	IsAuthorized bool
+	IsChallenge  bool
+	Details      map[string]string
+}
+
+// polkitSubject is the (sa{sv}) Subject struct identifying the process polkit should authorize.
+type polkitSubject struct {
+	Kind    string
+	Details map[string]dbus.Variant
+}
+
+// verifyViaPolkit asks polkit to authorize this process for identifier's action, allowing it to prompt
+// the user via the authentication agent registered for their session.
+// See: https://www.freedesktop.org/software/polkit/docs/latest/eggdbus-interface-org.freedesktop.PolicyKit1.Authority.html
+func verifyViaPolkit(ctx context.Context, identifier string) error {
+	conn, err := dbus.ConnectSystemBus(dbus.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("%w: connecting to system bus: %w", errPolkitUnavailable, err)
+	}
+	defer conn.Close()
+
+	subject, err := currentProcessSubject()
+	if err != nil {
+		return fmt.Errorf("building polkit subject: %w", err)
+	}
+
+	cancellationId := fmt.Sprintf("%s%d-%d", polkitCancellationIdPrefix, os.Getpid(), time.Now().UnixNano())
+	authority := conn.Object(polkitName, polkitPath)
+
+	var result polkitAuthorizationResult
+	err = authority.CallWithContext(ctx, polkitAuthorityInterface+".CheckAuthorization", 0,
+		subject,
+		polkitActionId(identifier),
+		map[string]string{}, // details -- polkit only accepts these from privileged callers
+		polkitAllowUserInteraction,
+		cancellationId,
+	).Store(&result)
+	if err != nil {
+		if ctx.Err() != nil {
+			// Dismiss the authentication dialog, since we're no longer waiting on it
+			cancelCtx, cancel := context.WithTimeout(context.Background(), polkitCancellationCallTimeout)
+			defer cancel()
+			authority.CallWithContext(cancelCtx, polkitAuthorityInterface+".CancelCheckAuthorization", 0, cancellationId)
+			return fmt.Errorf("waiting for polkit authorization: %w", ctx.Err())
+		}
+
+		return interpretPolkitError(err)
+	}
+
+	return interpretPolkitResult(result)
+}
+
+// interpretPolkitError distinguishes errors meaning polkit can't prompt the user at all from
+// other failures.
+func interpretPolkitError(err error) error {
+	var dbusErr dbus.Error
+	if !errors.As(err, &dbusErr) {
+		return fmt.Errorf("checking polkit authorization: %w", err)
+	}
+
+	switch dbusErr.Name {
+	case polkitErrorCancelled:
+		return errors.New("polkit authorization was cancelled")
+	case polkitErrorFailed:
+		// Returned when our action is not registered, e.g. if the policy file is missing
+		return fmt.Errorf("%w: %w", errPolkitUnavailable, err)
+	default:
+		if strings.HasPrefix(dbusErr.Name, "org.freedesktop.DBus.Error.") {
+			// polkitd is not running, or not reachable
+			return fmt.Errorf("%w: %w", errPolkitUnavailable, err)
+		}
+		return fmt.Errorf("checking polkit authorization: %w", err)
+	}
+}
+
+func interpretPolkitResult(result polkitAuthorizationResult) error {
+	if result.IsAuthorized {
+		return nil
+	}
+
+	if result.Details[polkitDismissedDetail] != "" {
+		return errors.New("user dismissed polkit authentication dialog")
+	}
+
+	// When user interaction is allowed but the result is still a challenge, there was no
+	// authentication agent available to prompt the user.
+	if result.IsChallenge {
+		return fmt.Errorf("%w: no authentication agent is running in the user's session", errPolkitUnavailable)
+	}
+
+	return errors.New("polkit did not authorize user")
+}
+
+// currentProcessSubject returns the unix-process subject for this process.
+func currentProcessSubject() (polkitSubject, error) {
+	pid := os.Getpid()
+
+	statBytes, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return polkitSubject{}, fmt.Errorf("reading process stat: %w", err)
+	}
+
+	startTime, err := processStartTime(statBytes)
+	if err != nil {
+		return polkitSubject{}, fmt.Errorf("parsing process start time: %w", err)
+	}
+
+	return polkitSubject{
+		Kind: "unix-process",
+		Details: map[string]dbus.Variant{
+			"pid":        dbus.MakeVariant(uint32(pid)),
+			"start-time": dbus.MakeVariant(startTime),
+			"uid":        dbus.MakeVariant(int32(os.Getuid())),
+		},
+	}, nil
+}
+
+// processStartTime extracts the starttime field (22) from the contents of /proc/<pid>/stat. polkit
+// uses it to guard against pid reuse. The comm field (2) may contain spaces and parentheses, so we
+// parse from its closing parenthesis.
+func processStartTime(stat []byte) (uint64, error) {
+	commEnd := bytes.LastIndexByte(stat, ')')
+	if commEnd < 0 {
+		return 0, errors.New("malformed stat: no comm field")
+	}
+
+	// Fields after comm begin with state (3); starttime is 19 fields later.
+	fields := strings.Fields(string(stat[commEnd+1:]))
+	const startTimeIndex = 22 - 3
+	if len(fields) <= startTimeIndex {
+		return 0, fmt.Errorf("malformed stat: expected at least %d fields after comm, got %d", startTimeIndex+1, len(fields))
+	}
+
+	startTime, err := strconv.ParseUint(fields[startTimeIndex], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parsing starttime %q: %w", fields[startTimeIndex], err)
+	}
+
+	return startTime, nil
+}
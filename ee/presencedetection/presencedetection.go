@@ -13,6 +13,8 @@ const (
 )
 
 type PresenceDetector struct {
+	// Identifier is launcher's identifier, which on linux names the polkit action we authorize
+	Identifier    string
 	lastDetection time.Time
 	mutext        sync.Mutex
 	// detector is an interface to allow for mocking in tests
@@ -28,10 +30,12 @@ type detectorIface interface {
 	Detect(reason string, timeout time.Duration) (bool, error)
 }
 
-type detector struct{}
+type detector struct {
+	identifier string
+}
 
 func (d *detector) Detect(reason string, timeout time.Duration) (bool, error) {
-	return Detect(reason, timeout)
+	return Detect(d.identifier, reason, timeout)
 }
 
 // DetectPresence checks if the user is present by detecting the presence of a user.
@@ -41,7 +45,7 @@ func (pd *PresenceDetector) DetectPresence(reason string, detectionInterval time
 	defer pd.mutext.Unlock()
 
 	if pd.detector == nil {
-		pd.detector = &detector{}
+		pd.detector = &detector{identifier: pd.Identifier}
 	}
 
 	// Check if the last detection was within the detection interval
@@ -15,7 +15,8 @@ import (
 	"unsafe"
 )
 
-func Detect(reason string, timeout time.Duration) (bool, error) {
+// Detect prompts the user via LocalAuthentication. The identifier is only needed on linux.
+func Detect(_ string, reason string, timeout time.Duration) (bool, error) {
 	reasonStr := C.CString(reason)
 	defer C.free(unsafe.Pointer(reasonStr))
 
@@ -26,7 +26,7 @@ func Test_detectSuccess(t *testing.T) {
 		t.Skip("Skipping Test_detectSuccess")
 	}
 
-	success, err := Detect("IS TRYING TO TEST SUCCESS, PLEASE AUTHENTICATE", DetectionTimeout)
+	success, err := Detect("kolide-k2", "IS TRYING TO TEST SUCCESS, PLEASE AUTHENTICATE", DetectionTimeout)
 	require.NoError(t, err, "should not get an error on successful detect")
 	assert.True(t, success, "should be successful")
 }
@@ -43,7 +43,7 @@ func Test_detectCancel(t *testing.T) {
 		t.Skip("Skipping test_biometricDetectCancel")
 	}
 
-	success, err := Detect("IS TRYING TO TEST CANCEL, PLEASE PRESS CANCEL", DetectionTimeout)
+	success, err := Detect("kolide-k2", "IS TRYING TO TEST CANCEL, PLEASE PRESS CANCEL", DetectionTimeout)
 	require.Error(t, err, "should get an error on failed detect")
 	assert.False(t, success, "should not be successful")
 }
@@ -60,7 +60,7 @@ func Test_timeout(t *testing.T) {
 		t.Skip("Skipping test_biometricDetectCancel")
 	}
 
-	success, err := Detect("IS TRYING TO TEST TIMEOUT, PLEASE DO NOTHING", 3*time.Second)
+	success, err := Detect("kolide-k2", "IS TRYING TO TEST TIMEOUT, PLEASE DO NOTHING", 3*time.Second)
 	require.Error(t, err, "should get an error on failed detect")
 	assert.False(t, success, "should not be successful")
 }
@@ -3,11 +3,33 @@
 package presencedetection
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"time"
 )
 
-func Detect(reason string, timeout time.Duration) (bool, error) {
-	// Implement detection logic for non-Darwin platforms
-	return false, errors.New("detection not implemented for this platform")
+// Detect prompts the user to authenticate. We prefer polkit: its authentication agent runs in the
+// console user's session and verifies the user via their PAM stack, which includes fingerprint
+// authentication when pam_fprintd is configured. When polkit cannot prompt the user (our action
+// isn't installed, or no authentication agent is running), we fall back to verifying a
+// fingerprint directly via fprintd. The identifier names the polkit action installed for this
+// launcher.
+func Detect(identifier string, reason string, timeout time.Duration) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	polkitErr := verifyViaPolkit(ctx, identifier)
+	if polkitErr == nil {
+		return true, nil
+	}
+	if !errors.Is(polkitErr, errPolkitUnavailable) {
+		return false, fmt.Errorf("verifying via polkit: %w", polkitErr)
+	}
+
+	if err := verifyViaFprintd(ctx, reason); err != nil {
+		return false, fmt.Errorf("verifying via fprintd (polkit unavailable: %s): %w", polkitErr.Error(), err)
+	}
+
+	return true, nil
 }
@@ -0,0 +1,187 @@
+//go:build linux
+
+package presencedetection
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/require"
+)
+
+const testPresenceEnvVar = "LAUNCHER_TEST_PRESENCE"
+
+// Since there is no way to test user presence in a CI / automated fashion,
+// this test is expected to be run manually from a desktop session when needed.
+
+// To test this run
+//
+// LAUNCHER_TEST_PRESENCE=true go test ./ee/presencedetection/ -run Test_detectSuccess
+//
+// then successfully authenticate with the polkit dialog, or scan your fingerprint
+func Test_detectSuccess(t *testing.T) {
+	t.Parallel()
+
+	if os.Getenv(testPresenceEnvVar) == "" {
+		t.Skip("Skipping Test_detectSuccess")
+	}
+
+	success, err := Detect("kolide-k2", "Kolide is trying to test success, please authenticate", 30*time.Second)
+	require.NoError(t, err, "should not get an error on successful detect")
+	require.True(t, success, "should be successful")
+}
+
+func Test_processStartTime(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		testCaseName      string
+		stat              string
+		expectedStartTime uint64
+		expectErr         bool
+	}{
+		{
+			testCaseName:      "simple comm",
+			stat:              "1234 (launcher) S 1 1234 1234 0 -1 4194560 1000 0 0 0 10 5 0 0 20 0 12 0 98765 1000000 500 18446744073709551615",
+			expectedStartTime: 98765,
+		},
+		{
+			testCaseName:      "comm with spaces and parentheses",
+			stat:              "1234 (my (weird) proc) S 1 1234 1234 0 -1 4194560 1000 0 0 0 10 5 0 0 20 0 12 0 4242 1000000 500 18446744073709551615",
+			expectedStartTime: 4242,
+		},
+		{
+			testCaseName: "missing comm",
+			stat:         "1234 launcher S 1",
+			expectErr:    true,
+		},
+		{
+			testCaseName: "truncated",
+			stat:         "1234 (launcher) S 1 1234",
+			expectErr:    true,
+		},
+		{
+			testCaseName: "non-numeric start time",
+			stat:         "1234 (launcher) S 1 1234 1234 0 -1 4194560 1000 0 0 0 10 5 0 0 20 0 12 0 abc 1000000",
+			expectErr:    true,
+		},
+	} {
+		t.Run(tt.testCaseName, func(t *testing.T) {
+			t.Parallel()
+
+			startTime, err := processStartTime([]byte(tt.stat))
+			if tt.expectErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedStartTime, startTime)
+		})
+	}
+}
+
+func Test_currentProcessSubject(t *testing.T) {
+	t.Parallel()
+
+	subject, err := currentProcessSubject()
+	require.NoError(t, err)
+	require.Equal(t, "unix-process", subject.Kind)
+	require.Equal(t, uint32(os.Getpid()), subject.Details["pid"].Value())
+	require.NotZero(t, subject.Details["start-time"].Value())
+}
+
+func Test_interpretPolkitResult(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, interpretPolkitResult(polkitAuthorizationResult{IsAuthorized: true}))
+
+	dismissedErr := interpretPolkitResult(polkitAuthorizationResult{Details: map[string]string{polkitDismissedDetail: "true"}})
+	require.Error(t, dismissedErr)
+	require.False(t, errors.Is(dismissedErr, errPolkitUnavailable), "dismissal should not fall back to fprintd")
+
+	require.ErrorIs(t, interpretPolkitResult(polkitAuthorizationResult{IsChallenge: true}), errPolkitUnavailable)
+
+	notAuthorizedErr := interpretPolkitResult(polkitAuthorizationResult{})
+	require.Error(t, notAuthorizedErr)
+	require.False(t, errors.Is(notAuthorizedErr, errPolkitUnavailable))
+}
+
+func Test_interpretPolkitError(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		testCaseName      string
+		err               error
+		expectUnavailable bool
+	}{
+		{
+			testCaseName:      "action not registered",
+			err:               dbus.Error{Name: polkitErrorFailed, Body: []any{"Action com.kolide.launcher.detect-presence is not registered"}},
+			expectUnavailable: true,
+		},
+		{
+			testCaseName:      "polkitd not running",
+			err:               dbus.Error{Name: "org.freedesktop.DBus.Error.ServiceUnknown"},
+			expectUnavailable: true,
+		},
+		{
+			testCaseName: "cancelled",
+			err:          dbus.Error{Name: polkitErrorCancelled},
+		},
+		{
+			testCaseName: "other polkit error",
+			err:          dbus.Error{Name: "org.freedesktop.PolicyKit1.Error.NotAuthorized"},
+		},
+		{
+			testCaseName: "non-dbus error",
+			err:          fmt.Errorf("wrapped: %w", errors.New("test error")),
+		},
+	} {
+		t.Run(tt.testCaseName, func(t *testing.T) {
+			t.Parallel()
+
+			err := interpretPolkitError(tt.err)
+			require.Error(t, err)
+			require.Equal(t, tt.expectUnavailable, errors.Is(err, errPolkitUnavailable))
+		})
+	}
+}
+
+func Test_parseVerifyStatus(t *testing.T) {
+	t.Parallel()
+
+	result, done, ok := parseVerifyStatus(&dbus.Signal{
+		Name: fprintdDeviceInterface + "." + fprintdVerifyStatus,
+		Body: []any{fprintdVerifyMatch, true},
+	})
+	require.True(t, ok)
+	require.True(t, done)
+	require.Equal(t, fprintdVerifyMatch, result)
+
+	_, done, ok = parseVerifyStatus(&dbus.Signal{
+		Name: fprintdDeviceInterface + "." + fprintdVerifyStatus,
+		Body: []any{"verify-retry-scan", false},
+	})
+	require.True(t, ok)
+	require.False(t, done)
+
+	_, _, ok = parseVerifyStatus(&dbus.Signal{
+		Name: fprintdDeviceInterface + ".EnrollStatus",
+		Body: []any{"enroll-completed", true},
+	})
+	require.False(t, ok)
+
+	_, _, ok = parseVerifyStatus(&dbus.Signal{
+		Name: fprintdDeviceInterface + "." + fprintdVerifyStatus,
+		Body: []any{fprintdVerifyMatch},
+	})
+	require.False(t, ok)
+
+	_, _, ok = parseVerifyStatus(nil)
+	require.False(t, ok)
+}
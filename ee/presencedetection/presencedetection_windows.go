@@ -69,8 +69,8 @@ var roInitialize = sync.OnceFunc(func() {
 	ole.RoInitialize(1)
 })
 
-// Detect prompts the user via Hello.
-func Detect(reason string, timeout time.Duration) (bool, error) {
+// Detect prompts the user via Hello. The identifier is only needed on linux.
+func Detect(_ string, reason string, timeout time.Duration) (bool, error) {
 	roInitialize()
 
 	if err := requestVerification(reason, timeout); err != nil {
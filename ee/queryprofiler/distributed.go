@@ -0,0 +1,52 @@
+package queryprofiler
+
+import (
+	"context"
+	"time"
+
+	"github.com/kolide/launcher/v2/ee/agent/types"
+	"github.com/osquery/osquery-go/plugin/distributed"
+)
+
+// RecordDistributedResults stores profiles for any distributed query results that include
+// execution stats from osquery. Distributed queries run only once, so unlike scheduled queries,
+// we record these as they arrive rather than on an interval.
+func RecordDistributedResults(ctx context.Context, store types.Setter, results []distributed.Result) error {
+	if store == nil {
+		return nil
+	}
+
+	now := time.Now()
+	profiles := make([]Profile, 0, len(results))
+	for _, result := range results {
+		if result.QueryStats == nil {
+			continue
+		}
+
+		p := Profile{
+			Name:          result.QueryName,
+			Source:        SourceDistributed,
+			Timestamp:     now.Unix(),
+			Executions:    1,
+			WallTimeMs:    int64(result.QueryStats.WallTimeMs),
+			MaxWallTimeMs: int64(result.QueryStats.WallTimeMs),
+			UserTimeMs:    int64(result.QueryStats.UserTime),
+			SystemTimeMs:  int64(result.QueryStats.SystemTime),
+			MemoryBytes:   int64(result.QueryStats.Memory),
+			Rows:          int64(len(result.Rows)),
+		}
+		if result.Status != 0 {
+			p.Errors = 1
+		}
+
+		profiles = append(profiles, p)
+	}
+
+	if len(profiles) == 0 {
+		return nil
+	}
+
+	recordProfileSpans(ctx, profiles)
+
+	return storeProfiles(store, profiles...)
+}
@@ -0,0 +1,256 @@
+// Package queryprofiler periodically collects per-query execution stats for osquery scheduled
+// queries, distributed queries, and Kolide extension tables, and keeps a rolling window of
+// these stats in a store so that expensive queries can be identified.
+package queryprofiler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/kolide/launcher/v2/ee/agent/types"
+	"github.com/kolide/launcher/v2/ee/observability"
+	"github.com/kolide/launcher/v2/ee/tables/tablewrapper"
+)
+
+const (
+	profilingInitialDelay = 1 * time.Minute
+	profilingInterval     = 10 * time.Minute
+
+	scheduleQuery = `SELECT name, executions, denylisted, output_size, wall_time_ms, last_wall_time_ms, user_time, system_time, average_memory FROM osquery_schedule;`
+)
+
+// scheduleStats holds the cumulative stats reported by osquery_schedule for a single query.
+type scheduleStats struct {
+	executions     int64
+	denylisted     bool
+	outputSize     int64
+	wallTimeMs     int64
+	lastWallTimeMs int64
+	userTime       int64
+	systemTime     int64
+	averageMemory  int64
+}
+
+type QueryProfiler struct {
+	slogger     *slog.Logger
+	store       types.KVStore
+	querier     types.Querier
+	interval    time.Duration
+	lastStats   map[string]scheduleStats
+	interrupt   chan struct{}
+	interrupted *atomic.Bool
+}
+
+type QueryProfilerOption func(*QueryProfiler)
+
+// WithInterval overrides the default profiling interval of ten minutes.
+func WithInterval(interval time.Duration) QueryProfilerOption {
+	return func(qp *QueryProfiler) {
+		qp.interval = interval
+	}
+}
+
+// New returns a new QueryProfiler, which will store its profiles in the given store.
+// The querier is used to query osquery_schedule.
+func New(k types.Knapsack, store types.KVStore, querier types.Querier, opts ...QueryProfilerOption) *QueryProfiler {
+	qp := &QueryProfiler{
+		slogger:     k.Slogger().With("component", "query_profiler"),
+		store:       store,
+		querier:     querier,
+		interval:    profilingInterval,
+		lastStats:   make(map[string]scheduleStats),
+		interrupt:   make(chan struct{}, 1),
+		interrupted: &atomic.Bool{},
+	}
+
+	for _, opt := range opts {
+		opt(qp)
+	}
+
+	return qp
+}
+
+func (qp *QueryProfiler) Execute() error {
+	// Wait a bit for osquery to start up before beginning profiling
+	select {
+	case <-qp.interrupt:
+		return nil
+	case <-time.After(profilingInitialDelay):
+		break
+	}
+
+	ticker := time.NewTicker(qp.interval)
+	defer ticker.Stop()
+	for {
+		qp.collect(context.Background())
+
+		select {
+		case <-ticker.C:
+			continue
+		case <-qp.interrupt:
+			return nil
+		}
+	}
+}
+
+func (qp *QueryProfiler) Interrupt(_ error) {
+	// Only perform shutdown tasks on first call to interrupt -- no need to repeat on potential extra calls.
+	if qp.interrupted.Swap(true) {
+		return
+	}
+
+	qp.interrupt <- struct{}{}
+}
+
+// collect gathers stats for scheduled queries and Kolide tables, stores them, and prunes
+// any profiles that have aged out of the rolling window.
+func (qp *QueryProfiler) collect(ctx context.Context) {
+	ctx, span := observability.StartSpan(ctx)
+	defer span.End()
+
+	now := time.Now()
+
+	profiles, err := qp.scheduledQueryProfiles(now)
+	if err != nil {
+		// We still want to collect table stats, so we log this error and continue
+		qp.slogger.Log(ctx, slog.LevelWarn,
+			"could not collect scheduled query profiles",
+			"err", err,
+		)
+	}
+	profiles = append(profiles, tableProfiles(now)...)
+
+	recordProfileSpans(ctx, profiles)
+
+	if err := storeProfiles(qp.store, profiles...); err != nil {
+		qp.slogger.Log(ctx, slog.LevelWarn,
+			"could not store query profiles",
+			"err", err,
+		)
+	}
+
+	if err := pruneProfiles(qp.store, now); err != nil {
+		qp.slogger.Log(ctx, slog.LevelWarn,
+			"could not prune query profiles",
+			"err", err,
+		)
+	}
+}
+
+// scheduledQueryProfiles queries osquery_schedule and returns profiles for each query that
+// ran since the last collection. osquery_schedule reports cumulative stats since osquery
+// started, so we compare against the previous collection to calculate the stats for this interval.
+func (qp *QueryProfiler) scheduledQueryProfiles(now time.Time) ([]Profile, error) {
+	if qp.querier == nil {
+		return nil, nil
+	}
+
+	rows, err := qp.querier.Query(scheduleQuery)
+	if err != nil {
+		return nil, fmt.Errorf("querying osquery_schedule: %w", err)
+	}
+
+	currentStats := make(map[string]scheduleStats)
+	profiles := make([]Profile, 0)
+	for _, row := range rows {
+		name := row["name"]
+		current := scheduleStats{
+			executions:     parseInt(row["executions"]),
+			denylisted:     row["denylisted"] == "1",
+			outputSize:     parseInt(row["output_size"]),
+			wallTimeMs:     parseInt(row["wall_time_ms"]),
+			lastWallTimeMs: parseInt(row["last_wall_time_ms"]),
+			userTime:       parseInt(row["user_time"]),
+			systemTime:     parseInt(row["system_time"]),
+			averageMemory:  parseInt(row["average_memory"]),
+		}
+		currentStats[name] = current
+
+		delta := current
+		if previous, ok := qp.lastStats[name]; ok && previous.executions <= current.executions {
+			// If executions went down, osquery restarted and its counters reset -- in that case,
+			// the current stats are already the delta.
+			delta.executions -= previous.executions
+			delta.outputSize -= previous.outputSize
+			delta.wallTimeMs -= previous.wallTimeMs
+			delta.userTime -= previous.userTime
+			delta.systemTime -= previous.systemTime
+		}
+
+		if delta.executions <= 0 {
+			continue
+		}
+
+		profiles = append(profiles, Profile{
+			Name:          name,
+			Source:        SourceScheduled,
+			Timestamp:     now.Unix(),
+			Executions:    delta.executions,
+			WallTimeMs:    delta.wallTimeMs,
+			MaxWallTimeMs: current.lastWallTimeMs,
+			UserTimeMs:    delta.userTime,
+			SystemTimeMs:  delta.systemTime,
+			MemoryBytes:   current.averageMemory,
+			OutputBytes:   delta.outputSize,
+			Denylisted:    current.denylisted,
+		})
+	}
+
+	qp.lastStats = currentStats
+
+	return profiles, nil
+}
+
+// tableProfiles returns profiles for each Kolide table that was queried since the last collection.
+func tableProfiles(now time.Time) []Profile {
+	tableStats := tablewrapper.DrainGenerateStats()
+	profiles := make([]Profile, 0, len(tableStats))
+	for _, s := range tableStats {
+		profiles = append(profiles, Profile{
+			Name:          s.TableName,
+			Source:        SourceKolideTable,
+			Timestamp:     now.Unix(),
+			Executions:    s.Executions,
+			WallTimeMs:    s.WallTimeMs,
+			MaxWallTimeMs: s.MaxWallTimeMs,
+			Rows:          s.Rows,
+			Errors:        s.Errors,
+			Timeouts:      s.Timeouts,
+		})
+	}
+
+	return profiles
+}
+
+// recordProfileSpans emits a span per profile, so that query performance is available
+// alongside our other traces.
+func recordProfileSpans(ctx context.Context, profiles []Profile) {
+	for _, p := range profiles {
+		_, span := observability.StartSpan(ctx,
+			"query_name", p.Name,
+			"source", p.Source,
+			"executions", p.Executions,
+			"wall_time_ms", p.WallTimeMs,
+			"max_wall_time_ms", p.MaxWallTimeMs,
+			"user_time_ms", p.UserTimeMs,
+			"system_time_ms", p.SystemTimeMs,
+			"memory_bytes", p.MemoryBytes,
+			"output_bytes", p.OutputBytes,
+			"errors", p.Errors,
+			"timeouts", p.Timeouts,
+		)
+		span.End()
+	}
+}
+
+func parseInt(s string) int64 {
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return i
+}
@@ -0,0 +1,220 @@
+package queryprofiler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kolide/launcher/v2/ee/agent/storage/inmemory"
+	typesmocks "github.com/kolide/launcher/v2/ee/agent/types/mocks"
+	"github.com/kolide/launcher/v2/pkg/log/multislogger"
+	"github.com/osquery/osquery-go/plugin/distributed"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduledQueryProfiles(t *testing.T) {
+	t.Parallel()
+
+	k := typesmocks.NewKnapsack(t)
+	k.On("Slogger").Return(multislogger.NewNopLogger())
+
+	querier := typesmocks.NewQuerier(t)
+	qp := New(k, inmemory.NewStore(), querier)
+
+	// First collection -- osquery_schedule stats are cumulative since osquery start, so
+	// we should record them as-is.
+	querier.On("Query", scheduleQuery).Return([]map[string]string{
+		{
+			"name":              "pack:kolide:expensive_query",
+			"executions":        "2",
+			"denylisted":        "0",
+			"output_size":       "1024",
+			"wall_time_ms":      "3000",
+			"last_wall_time_ms": "2000",
+			"user_time":         "1500",
+			"system_time":       "500",
+			"average_memory":    "4096",
+		},
+		{
+			"name":       "pack:kolide:never_run",
+			"executions": "0",
+		},
+	}, nil).Once()
+
+	now := time.Now()
+	profiles, err := qp.scheduledQueryProfiles(now)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(profiles))
+	require.Equal(t, Profile{
+		Name:          "pack:kolide:expensive_query",
+		Source:        SourceScheduled,
+		Timestamp:     now.Unix(),
+		Executions:    2,
+		WallTimeMs:    3000,
+		MaxWallTimeMs: 2000,
+		UserTimeMs:    1500,
+		SystemTimeMs:  500,
+		MemoryBytes:   4096,
+		OutputBytes:   1024,
+	}, profiles[0])
+
+	// Second collection -- we should only record the difference since the last collection
+	querier.On("Query", scheduleQuery).Return([]map[string]string{
+		{
+			"name":              "pack:kolide:expensive_query",
+			"executions":        "3",
+			"denylisted":        "1",
+			"output_size":       "1536",
+			"wall_time_ms":      "7000",
+			"last_wall_time_ms": "4000",
+			"user_time":         "3500",
+			"system_time":       "600",
+			"average_memory":    "8192",
+		},
+	}, nil).Once()
+
+	profiles, err = qp.scheduledQueryProfiles(now)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(profiles))
+	require.Equal(t, int64(1), profiles[0].Executions)
+	require.Equal(t, int64(4000), profiles[0].WallTimeMs)
+	require.Equal(t, int64(4000), profiles[0].MaxWallTimeMs)
+	require.Equal(t, int64(2000), profiles[0].UserTimeMs)
+	require.Equal(t, int64(100), profiles[0].SystemTimeMs)
+	require.Equal(t, int64(8192), profiles[0].MemoryBytes)
+	require.Equal(t, int64(512), profiles[0].OutputBytes)
+	require.True(t, profiles[0].Denylisted)
+
+	// Third collection -- osquery restarted, so counters reset; we should record them as-is
+	querier.On("Query", scheduleQuery).Return([]map[string]string{
+		{
+			"name":         "pack:kolide:expensive_query",
+			"executions":   "1",
+			"wall_time_ms": "500",
+		},
+	}, nil).Once()
+
+	profiles, err = qp.scheduledQueryProfiles(now)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(profiles))
+	require.Equal(t, int64(1), profiles[0].Executions)
+	require.Equal(t, int64(500), profiles[0].WallTimeMs)
+
+	// Query error should be returned
+	querier.On("Query", scheduleQuery).Return(nil, errors.New("test error")).Once()
+	_, err = qp.scheduledQueryProfiles(now)
+	require.Error(t, err)
+}
+
+func TestPruneProfiles(t *testing.T) {
+	t.Parallel()
+
+	store := inmemory.NewStore()
+	now := time.Now()
+
+	require.NoError(t, storeProfiles(store,
+		Profile{Name: "old_query", Source: SourceScheduled, Timestamp: now.Add(-25 * time.Hour).Unix(), Executions: 1},
+		Profile{Name: "recent_query", Source: SourceScheduled, Timestamp: now.Add(-1 * time.Hour).Unix(), Executions: 1},
+		Profile{Name: "kolide_some_table", Source: SourceKolideTable, Timestamp: now.Unix(), Executions: 1},
+	))
+
+	require.NoError(t, pruneProfiles(store, now))
+
+	profiles, err := Profiles(store)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(profiles))
+	require.Equal(t, "recent_query", profiles[0].Name)
+	require.Equal(t, "kolide_some_table", profiles[1].Name)
+}
+
+func TestStoreProfiles_SameSecond(t *testing.T) {
+	t.Parallel()
+
+	store := inmemory.NewStore()
+	now := time.Now().Unix()
+
+	// e.g. the same distributed query run twice within a second
+	require.NoError(t, storeProfiles(store,
+		Profile{Name: "some_query", Source: SourceDistributed, Timestamp: now, Executions: 1, WallTimeMs: 10},
+	))
+	require.NoError(t, storeProfiles(store,
+		Profile{Name: "some_query", Source: SourceDistributed, Timestamp: now, Executions: 1, WallTimeMs: 20},
+	))
+
+	profiles, err := Profiles(store)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(profiles))
+
+	summaries := Summarize(profiles)
+	require.Equal(t, 1, len(summaries))
+	require.Equal(t, int64(2), summaries[0].Executions)
+	require.Equal(t, int64(30), summaries[0].WallTimeMs)
+}
+
+func TestRecordDistributedResults(t *testing.T) {
+	t.Parallel()
+
+	store := inmemory.NewStore()
+
+	require.NoError(t, RecordDistributedResults(t.Context(), store, []distributed.Result{
+		{
+			QueryName: "query_with_stats",
+			Status:    0,
+			Rows:      []map[string]string{{"a": "b"}, {"c": "d"}},
+			QueryStats: &distributed.Stats{
+				WallTimeMs: 250,
+				UserTime:   100,
+				SystemTime: 50,
+				Memory:     2048,
+			},
+		},
+		{
+			QueryName:  "failed_query_with_stats",
+			Status:     1,
+			QueryStats: &distributed.Stats{WallTimeMs: 10},
+		},
+		{
+			QueryName: "query_without_stats",
+		},
+	}))
+
+	profiles, err := Profiles(store)
+	require.NoError(t, err)
+
+	summaries := Summarize(profiles)
+	require.Equal(t, 2, len(summaries))
+
+	require.Equal(t, "query_with_stats", summaries[0].Name)
+	require.Equal(t, SourceDistributed, summaries[0].Source)
+	require.Equal(t, int64(1), summaries[0].Executions)
+	require.Equal(t, int64(250), summaries[0].WallTimeMs)
+	require.Equal(t, int64(2048), summaries[0].MaxMemory)
+	require.Equal(t, int64(0), summaries[0].Errors)
+
+	require.Equal(t, "failed_query_with_stats", summaries[1].Name)
+	require.Equal(t, int64(1), summaries[1].Errors)
+}
+
+func TestSummarize(t *testing.T) {
+	t.Parallel()
+
+	summaries := Summarize([]Profile{
+		{Name: "cheap_query", Source: SourceScheduled, Timestamp: 1, Executions: 10, WallTimeMs: 100},
+		{Name: "expensive_query", Source: SourceScheduled, Timestamp: 1, Executions: 1, WallTimeMs: 900, MaxWallTimeMs: 900},
+		{Name: "expensive_query", Source: SourceScheduled, Timestamp: 2, Executions: 2, WallTimeMs: 600, MaxWallTimeMs: 400, MemoryBytes: 10},
+	})
+
+	require.Equal(t, 2, len(summaries))
+	require.Equal(t, QuerySummary{
+		Name:          "expensive_query",
+		Source:        SourceScheduled,
+		Executions:    3,
+		WallTimeMs:    1500,
+		AvgWallTimeMs: 500,
+		MaxWallTimeMs: 900,
+		MaxMemory:     10,
+		LastSeen:      2,
+	}, summaries[0])
+	require.Equal(t, "cheap_query", summaries[1].Name)
+	require.Equal(t, int64(10), summaries[1].AvgWallTimeMs)
+}
@@ -0,0 +1,194 @@
+package queryprofiler
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/kolide/kit/ulid"
+	"github.com/kolide/launcher/v2/ee/agent/types"
+)
+
+const (
+	// Profile sources
+	SourceScheduled   = "scheduled"
+	SourceDistributed = "distributed"
+	SourceKolideTable = "kolide_table"
+
+	// We keep a rolling window of profiles -- anything older than maxProfileAge,
+	// or beyond the most recent maxProfiles, is pruned.
+	maxProfileAge = 24 * time.Hour
+	maxProfiles   = 10000
+)
+
+// Profile holds execution stats for a single query (or Kolide table) over a single
+// collection interval.
+type Profile struct {
+	Name          string `json:"name"`
+	Source        string `json:"source"`
+	Timestamp     int64  `json:"timestamp"`
+	Executions    int64  `json:"executions"`
+	WallTimeMs    int64  `json:"wall_time_ms"`
+	MaxWallTimeMs int64  `json:"max_wall_time_ms,omitempty"`
+	UserTimeMs    int64  `json:"user_time_ms,omitempty"`
+	SystemTimeMs  int64  `json:"system_time_ms,omitempty"`
+	MemoryBytes   int64  `json:"memory_bytes,omitempty"`
+	OutputBytes   int64  `json:"output_bytes,omitempty"`
+	Rows          int64  `json:"rows,omitempty"`
+	Errors        int64  `json:"errors,omitempty"`
+	Timeouts      int64  `json:"timeouts,omitempty"`
+	Denylisted    bool   `json:"denylisted,omitempty"`
+}
+
+// newStoreKey generates a new, unique key for the profile. Keys sort by timestamp; the
+// ulid suffix keeps profiles for the same query collected within the same second apart.
+func (p Profile) newStoreKey() []byte {
+	return []byte(fmt.Sprintf("%019d_%s_%s_%s", p.Timestamp, p.Source, p.Name, ulid.New()))
+}
+
+// storeProfiles saves the given profiles to the store.
+func storeProfiles(store types.Setter, profiles ...Profile) error {
+	for _, p := range profiles {
+		profileRaw, err := json.Marshal(p)
+		if err != nil {
+			return fmt.Errorf("marshalling profile for %s: %w", p.Name, err)
+		}
+
+		if err := store.Set(p.newStoreKey(), profileRaw); err != nil {
+			return fmt.Errorf("storing profile for %s: %w", p.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// Profiles returns all profiles in the given store, sorted by timestamp, oldest first.
+func Profiles(store types.Iterator) ([]Profile, error) {
+	stored, err := storedProfiles(store)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make([]Profile, len(stored))
+	for i, sp := range stored {
+		profiles[i] = sp.profile
+	}
+
+	return profiles, nil
+}
+
+// storedProfile is a profile along with the key it is stored under.
+type storedProfile struct {
+	key     []byte
+	profile Profile
+}
+
+// storedProfiles returns all profiles in the given store along with their keys, sorted by
+// timestamp, oldest first.
+func storedProfiles(store types.Iterator) ([]storedProfile, error) {
+	stored := make([]storedProfile, 0)
+	if err := store.ForEach(func(k, v []byte) error {
+		var p Profile
+		if err := json.Unmarshal(v, &p); err != nil {
+			return fmt.Errorf("unmarshalling profile at key %s: %w", string(k), err)
+		}
+		stored = append(stored, storedProfile{key: slices.Clone(k), profile: p})
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("iterating over profiles: %w", err)
+	}
+
+	slices.SortStableFunc(stored, func(a, b storedProfile) int {
+		return cmp.Compare(a.profile.Timestamp, b.profile.Timestamp)
+	})
+
+	return stored, nil
+}
+
+// pruneProfiles deletes profiles older than maxProfileAge, and the oldest profiles beyond
+// maxProfiles, from the store.
+func pruneProfiles(store types.KVStore, now time.Time) error {
+	profiles, err := storedProfiles(store)
+	if err != nil {
+		return fmt.Errorf("reading profiles: %w", err)
+	}
+
+	cutoff := now.Add(-1 * maxProfileAge).Unix()
+	keysToDelete := make([][]byte, 0)
+	for i, sp := range profiles {
+		if sp.profile.Timestamp < cutoff || len(profiles)-i > maxProfiles {
+			keysToDelete = append(keysToDelete, sp.key)
+		}
+	}
+
+	if len(keysToDelete) == 0 {
+		return nil
+	}
+
+	if err := store.Delete(keysToDelete...); err != nil {
+		return fmt.Errorf("deleting %d old profiles: %w", len(keysToDelete), err)
+	}
+
+	return nil
+}
+
+// QuerySummary aggregates all profiles for a single query over the rolling window.
+type QuerySummary struct {
+	Name          string `json:"name"`
+	Source        string `json:"source"`
+	Executions    int64  `json:"executions"`
+	WallTimeMs    int64  `json:"wall_time_ms"`
+	AvgWallTimeMs int64  `json:"avg_wall_time_ms"`
+	MaxWallTimeMs int64  `json:"max_wall_time_ms"`
+	UserTimeMs    int64  `json:"user_time_ms"`
+	SystemTimeMs  int64  `json:"system_time_ms"`
+	MaxMemory     int64  `json:"max_memory_bytes"`
+	OutputBytes   int64  `json:"output_bytes"`
+	Errors        int64  `json:"errors"`
+	Timeouts      int64  `json:"timeouts"`
+	LastSeen      int64  `json:"last_seen"`
+}
+
+// Summarize aggregates the given profiles by source and query name, returning the summaries
+// sorted by total wall time, most expensive first.
+func Summarize(profiles []Profile) []QuerySummary {
+	summariesByKey := make(map[string]*QuerySummary)
+	for _, p := range profiles {
+		key := p.Source + "/" + p.Name
+		s, ok := summariesByKey[key]
+		if !ok {
+			s = &QuerySummary{Name: p.Name, Source: p.Source}
+			summariesByKey[key] = s
+		}
+
+		s.Executions += p.Executions
+		s.WallTimeMs += p.WallTimeMs
+		s.MaxWallTimeMs = max(s.MaxWallTimeMs, p.MaxWallTimeMs)
+		s.UserTimeMs += p.UserTimeMs
+		s.SystemTimeMs += p.SystemTimeMs
+		s.MaxMemory = max(s.MaxMemory, p.MemoryBytes)
+		s.OutputBytes += p.OutputBytes
+		s.Errors += p.Errors
+		s.Timeouts += p.Timeouts
+		s.LastSeen = max(s.LastSeen, p.Timestamp)
+	}
+
+	summaries := make([]QuerySummary, 0, len(summariesByKey))
+	for _, s := range summariesByKey {
+		if s.Executions > 0 {
+			s.AvgWallTimeMs = s.WallTimeMs / s.Executions
+		}
+		summaries = append(summaries, *s)
+	}
+
+	slices.SortFunc(summaries, func(a, b QuerySummary) int {
+		if c := cmp.Compare(b.WallTimeMs, a.WallTimeMs); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Source+"/"+a.Name, b.Source+"/"+b.Name)
+	})
+
+	return summaries
+}
@@ -0,0 +1,292 @@
+//go:build darwin
+
+package secureenclaverunner
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/kolide/launcher/v2/ee/agent/keys"
+	"github.com/kolide/launcher/v2/ee/observability"
+)
+
+const (
+	pendingPublicEccDataKey   = "pendingPublicEccData"
+	publicEccDataCreatedAtKey = "publicEccDataCreatedAt"
+)
+
+type pendingKeyEntry struct {
+	pubKey    *ecdsa.PublicKey
+	rotatedAt time.Time
+}
+
+type storedPendingKeyEntry struct {
+	PubKey    string    `json:"pub_key"`
+	RotatedAt time.Time `json:"rotated_at"`
+}
+
+// publicOnlySigner wraps a pending secure enclave key. As with the current key, root launcher
+// cannot sign with secure enclave keys, so Sign is not implemented.
+type publicOnlySigner struct {
+	pubKey *ecdsa.PublicKey
+}
+
+func (p publicOnlySigner) Public() crypto.PublicKey {
+	return p.pubKey
+}
+
+func (p publicOnlySigner) Sign(_ io.Reader, _ []byte, _ crypto.SignerOpts) ([]byte, error) {
+	return nil, errors.New("not implemented")
+}
+
+// RotateKey creates a new secure enclave key for the current console user, to replace their current
+// key once the server acknowledges it. Root launcher cannot sign with secure enclave keys, so unlike
+// TPM rotations, the new key is not signed by the old one.
+func (ser *secureEnclaveRunner) RotateKey(ctx context.Context) error {
+	ctx, span := observability.StartSpan(ctx)
+	defer span.End()
+
+	ser.uidPubKeyMapMux.Lock()
+	defer ser.uidPubKeyMapMux.Unlock()
+
+	cu, err := firstConsoleUser(ctx)
+	if err != nil {
+		return fmt.Errorf("getting console user: %w", err)
+	}
+
+	entry, ok := ser.uidPubKeyMap[cu.Uid]
+	if !ok || !entry.verifiedInSecureEnclave {
+		return fmt.Errorf("no verified key to rotate for uid %s", cu.Uid)
+	}
+	if _, ok := ser.uidPendingKeyMap[cu.Uid]; ok {
+		return keys.ErrRotationPending
+	}
+
+	key, err := ser.secureEnclaveClient.CreateSecureEnclaveKey(ctx, cu.Uid)
+	if err != nil {
+		observability.SetError(span, fmt.Errorf("creating key: %w", err))
+		return fmt.Errorf("creating key: %w", err)
+	}
+
+	ser.uidPendingKeyMap[cu.Uid] = &pendingKeyEntry{
+		pubKey:    key,
+		rotatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+
+	if err := ser.saveRotationState(); err != nil {
+		delete(ser.uidPendingKeyMap, cu.Uid)
+		observability.SetError(span, fmt.Errorf("saving key rotation state: %w", err))
+		return fmt.Errorf("saving key rotation state: %w", err)
+	}
+
+	ser.slogger.Log(ctx, slog.LevelInfo,
+		"rotated secure enclave key for console user, awaiting acknowledgement",
+		"uid", cu.Uid,
+	)
+	span.AddEvent("rotated_secure_enclave_key")
+
+	return nil
+}
+
+// AcknowledgeKeyRotation makes the current console user's pending key their current key, once the
+// server has confirmed that it knows about it. The old key remains in the secure enclave, since
+// only the user's desktop process can access it.
+func (ser *secureEnclaveRunner) AcknowledgeKeyRotation(ctx context.Context, publicKeyDer []byte) error {
+	ctx, span := observability.StartSpan(ctx)
+	defer span.End()
+
+	ser.uidPubKeyMapMux.Lock()
+	defer ser.uidPubKeyMapMux.Unlock()
+
+	cu, err := firstConsoleUser(ctx)
+	if err != nil {
+		return fmt.Errorf("getting console user: %w", err)
+	}
+
+	pending, ok := ser.uidPendingKeyMap[cu.Uid]
+	if !ok {
+		return keys.ErrNoPendingRotation
+	}
+
+	pendingKeyDer, err := x509.MarshalPKIXPublicKey(pending.pubKey)
+	if err != nil {
+		return fmt.Errorf("marshalling pending public key: %w", err)
+	}
+	if !bytes.Equal(pendingKeyDer, publicKeyDer) {
+		return keys.ErrRotationMismatch
+	}
+
+	previousEntry := ser.uidPubKeyMap[cu.Uid]
+	ser.uidPubKeyMap[cu.Uid] = &keyEntry{
+		pubKey:                  pending.pubKey,
+		verifiedInSecureEnclave: true,
+	}
+	if err := ser.save(); err != nil {
+		ser.uidPubKeyMap[cu.Uid] = previousEntry
+		observability.SetError(span, fmt.Errorf("saving secure enclave signer: %w", err))
+		return fmt.Errorf("saving secure enclave signer: %w", err)
+	}
+
+	delete(ser.uidPendingKeyMap, cu.Uid)
+	ser.uidKeyCreatedAtMap[cu.Uid] = pending.rotatedAt
+	if err := ser.saveRotationState(); err != nil {
+		ser.slogger.Log(ctx, slog.LevelWarn,
+			"could not save key rotation state after acknowledgement",
+			"err", err,
+		)
+	}
+
+	ser.slogger.Log(ctx, slog.LevelInfo,
+		"secure enclave key rotation acknowledged, now using new key",
+		"uid", cu.Uid,
+	)
+	span.AddEvent("secure_enclave_key_rotation_acknowledged")
+
+	return nil
+}
+
+// PendingKeyRotation returns the current console user's rotated-in key awaiting acknowledgement, if any.
+func (ser *secureEnclaveRunner) PendingKeyRotation() *keys.PendingRotation {
+	ser.uidPubKeyMapMux.Lock()
+	defer ser.uidPubKeyMapMux.Unlock()
+
+	cu, err := firstConsoleUser(context.TODO())
+	if err != nil {
+		return nil
+	}
+
+	pending, ok := ser.uidPendingKeyMap[cu.Uid]
+	if !ok {
+		return nil
+	}
+
+	return &keys.PendingRotation{
+		Signer:    publicOnlySigner{pubKey: pending.pubKey},
+		RotatedAt: pending.rotatedAt,
+	}
+}
+
+// KeyCreatedAt returns when the current console user's key was created. Keys created before we
+// started tracking this are treated as created now, so that they are not all rotated at once.
+func (ser *secureEnclaveRunner) KeyCreatedAt() time.Time {
+	ser.uidPubKeyMapMux.Lock()
+	defer ser.uidPubKeyMapMux.Unlock()
+
+	cu, err := firstConsoleUser(context.TODO())
+	if err != nil {
+		return time.Time{}
+	}
+
+	if _, ok := ser.uidPubKeyMap[cu.Uid]; !ok {
+		return time.Time{}
+	}
+
+	createdAt, ok := ser.uidKeyCreatedAtMap[cu.Uid]
+	if ok {
+		return createdAt
+	}
+
+	createdAt = time.Now().UTC().Truncate(time.Second)
+	ser.uidKeyCreatedAtMap[cu.Uid] = createdAt
+	if err := ser.saveRotationState(); err != nil {
+		ser.slogger.Log(context.TODO(), slog.LevelWarn,
+			"could not save key creation time",
+			"err", err,
+		)
+	}
+
+	return createdAt
+}
+
+func (ser *secureEnclaveRunner) loadRotationState() error {
+	pendingData, err := ser.store.Get([]byte(pendingPublicEccDataKey))
+	if err != nil {
+		return fmt.Errorf("getting pending public ecc data from store: %w", err)
+	}
+	if pendingData != nil {
+		var storedPending map[string]storedPendingKeyEntry
+		if err := json.Unmarshal(pendingData, &storedPending); err != nil {
+			return fmt.Errorf("unmarshalling pending keys: %w", err)
+		}
+
+		for uid, stored := range storedPending {
+			decoded, err := base64.StdEncoding.DecodeString(stored.PubKey)
+			if err != nil {
+				return fmt.Errorf("decoding base64: %w", err)
+			}
+			pubKey, err := x509.ParsePKIXPublicKey(decoded)
+			if err != nil {
+				return fmt.Errorf("parsing PKIX public key: %w", err)
+			}
+			ecdsaPubKey, ok := pubKey.(*ecdsa.PublicKey)
+			if !ok {
+				return errors.New("public key is not ecdsa")
+			}
+
+			ser.uidPendingKeyMap[uid] = &pendingKeyEntry{
+				pubKey:    ecdsaPubKey,
+				rotatedAt: stored.RotatedAt,
+			}
+		}
+	}
+
+	createdAtData, err := ser.store.Get([]byte(publicEccDataCreatedAtKey))
+	if err != nil {
+		return fmt.Errorf("getting key creation times from store: %w", err)
+	}
+	if createdAtData != nil {
+		if err := json.Unmarshal(createdAtData, &ser.uidKeyCreatedAtMap); err != nil {
+			return fmt.Errorf("unmarshalling key creation times: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (ser *secureEnclaveRunner) saveRotationState() error {
+	storedPending := make(map[string]storedPendingKeyEntry)
+	for uid, pending := range ser.uidPendingKeyMap {
+		pubKeyBytes, err := x509.MarshalPKIXPublicKey(pending.pubKey)
+		if err != nil {
+			return fmt.Errorf("marshalling to PKIX public key: %w", err)
+		}
+		storedPending[uid] = storedPendingKeyEntry{
+			PubKey:    base64.StdEncoding.EncodeToString(pubKeyBytes),
+			RotatedAt: pending.rotatedAt,
+		}
+	}
+
+	pendingData, err := json.Marshal(storedPending)
+	if err != nil {
+		return fmt.Errorf("marshalling pending keys: %w", err)
+	}
+	if err := ser.store.Set([]byte(pendingPublicEccDataKey), pendingData); err != nil {
+		return fmt.Errorf("setting pending public ecc data: %w", err)
+	}
+
+	createdAtData, err := json.Marshal(ser.uidKeyCreatedAtMap)
+	if err != nil {
+		return fmt.Errorf("marshalling key creation times: %w", err)
+	}
+	if err := ser.store.Set([]byte(publicEccDataCreatedAtKey), createdAtData); err != nil {
+		return fmt.Errorf("setting key creation times: %w", err)
+	}
+
+	return nil
+}
+
+func (ser *secureEnclaveRunner) clearRotationState() {
+	ser.uidPendingKeyMap = make(map[string]*pendingKeyEntry)
+	ser.uidKeyCreatedAtMap = make(map[string]time.Time)
+	_ = ser.store.Delete([]byte(pendingPublicEccDataKey), []byte(publicEccDataCreatedAtKey))
+}
@@ -41,6 +41,8 @@ type keyEntry struct {
 
 type secureEnclaveRunner struct {
 	uidPubKeyMap        map[string]*keyEntry
+	uidPendingKeyMap    map[string]*pendingKeyEntry
+	uidKeyCreatedAtMap  map[string]time.Time
 	uidPubKeyMapMux     *sync.Mutex
 	secureEnclaveClient secureEnclaveClient
 	store               types.GetterSetterDeleter
@@ -61,6 +63,8 @@ type secureEnclaveClient interface {
 func New(_ context.Context, slogger *slog.Logger, store types.GetterSetterDeleter, secureEnclaveClient secureEnclaveClient) (*secureEnclaveRunner, error) {
 	return &secureEnclaveRunner{
 		uidPubKeyMap:        make(map[string]*keyEntry),
+		uidPendingKeyMap:    make(map[string]*pendingKeyEntry),
+		uidKeyCreatedAtMap:  make(map[string]time.Time),
 		store:               store,
 		secureEnclaveClient: secureEnclaveClient,
 		slogger:             slogger.With("component", "secureenclaverunner"),
@@ -94,6 +98,14 @@ func (ser *secureEnclaveRunner) Execute() error {
 		}
 	}
 
+	if err := ser.loadRotationState(); err != nil {
+		ser.slogger.Log(context.TODO(), slog.LevelError,
+			"unable to load key rotation state, data may be corrupt, wiping",
+			"err", err,
+		)
+		ser.clearRotationState()
+	}
+
 	durationCounter := backoff.NewMultiplicativeDurationCounter(time.Second, time.Minute)
 	retryTicker := time.NewTicker(time.Second)
 	defer retryTicker.Stop()
@@ -289,6 +301,14 @@ func (ser *secureEnclaveRunner) currentConsoleUserKey(ctx context.Context) (*ecd
 		return nil, fmt.Errorf("saving secure enclave signer: %w", err)
 	}
 
+	ser.uidKeyCreatedAtMap[cu.Uid] = time.Now().UTC().Truncate(time.Second)
+	if err := ser.saveRotationState(); err != nil {
+		ser.slogger.Log(ctx, slog.LevelWarn,
+			"could not save key creation time",
+			"err", err,
+		)
+	}
+
 	span.AddEvent("saved_key_for_console_user")
 	return key, nil
 }
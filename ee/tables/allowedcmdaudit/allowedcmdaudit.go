@@ -0,0 +1,70 @@
+package allowedcmdaudit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/kolide/launcher/v2/ee/agent/types"
+	"github.com/kolide/launcher/v2/ee/allowedcmd"
+	"github.com/kolide/launcher/v2/ee/observability"
+	"github.com/kolide/launcher/v2/ee/tables/tablewrapper"
+	"github.com/osquery/osquery-go/plugin/table"
+)
+
+const tableName = "kolide_allowedcmd_audit"
+
+func TablePlugin(flags types.Flags, slogger *slog.Logger) *table.Plugin {
+	columns := []table.ColumnDefinition{
+		table.BigIntColumn("timestamp"),
+		table.TextColumn("name"),
+		table.TextColumn("path"),
+		table.IntegerColumn("arg_count"),
+		table.IntegerColumn("uid"),
+		table.BigIntColumn("duration_ms"),
+		table.IntegerColumn("exit_code"),
+		table.BigIntColumn("output_bytes"),
+		table.IntegerColumn("running"),
+		table.IntegerColumn("denied"),
+		table.TextColumn("error"),
+	}
+	return tablewrapper.New(flags, slogger, tableName, columns, generate(),
+		tablewrapper.WithDescription("Recent commands executed by launcher, including the number of arguments (but not the arguments themselves), uid, duration, exit code, output size, and whether the command was denied by policy. Useful for auditing what launcher has run on the device."),
+	)
+}
+
+func generate() table.GenerateFunc {
+	return func(ctx context.Context, queryContext table.QueryContext) ([]map[string]string, error) {
+		_, span := observability.StartSpan(ctx, "table_name", tableName)
+		defer span.End()
+
+		records := allowedcmd.AuditRecords()
+		results := make([]map[string]string, 0, len(records))
+
+		for _, record := range records {
+			results = append(results, map[string]string{
+				"timestamp":    fmt.Sprint(record.Timestamp.Unix()),
+				"name":         record.Name,
+				"path":         record.Path,
+				"arg_count":    strconv.Itoa(record.ArgCount),
+				"uid":          strconv.Itoa(record.Uid),
+				"duration_ms":  fmt.Sprint(record.Duration.Milliseconds()),
+				"exit_code":    strconv.Itoa(record.ExitCode),
+				"output_bytes": fmt.Sprint(record.OutputBytes),
+				"running":      boolToIntString(record.Running),
+				"denied":       boolToIntString(record.Denied),
+				"error":        record.Error,
+			})
+		}
+
+		return results, nil
+	}
+}
+
+func boolToIntString(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
@@ -15,12 +15,17 @@ import (
 func TablePlugin(flags types.Flags, slogger *slog.Logger) *table.Plugin {
 	columns := []table.ColumnDefinition{
 		table.TextColumn("uid"),
+		table.TextColumn("session_id"),
+		table.TextColumn("session_type"),
+		table.TextColumn("seat"),
+		table.TextColumn("display"),
+		table.TextColumn("remote"),
 		table.TextColumn("pid"),
 		table.TextColumn("start_time"),
 		table.TextColumn("last_health_check"),
 	}
 	return tablewrapper.New(flags, slogger, "kolide_desktop_procs", columns, generate(),
-		tablewrapper.WithDescription("Running Kolide Desktop UI processes, including PID, UID, start time, and last health check. Useful for verifying the desktop component is running."),
+		tablewrapper.WithDescription("Running Kolide Desktop UI processes, including PID, UID, start time, and last health check. On Linux there is one process per graphical session, with the session ID, type, seat, and display. Useful for verifying the desktop component is running."),
 	)
 }
 
@@ -32,8 +37,17 @@ func generate() table.GenerateFunc {
 		results := []map[string]string{}
 
 		for k, v := range runner.InstanceDesktopProcessRecords() {
+			uid := v.Session.Uid
+			if uid == "" {
+				uid = k
+			}
 			results = append(results, map[string]string{
-				"uid":               k,
+				"uid":               uid,
+				"session_id":        v.Session.Id,
+				"session_type":      v.Session.Type,
+				"seat":              v.Session.Seat,
+				"display":           v.Session.Display,
+				"remote":            fmt.Sprint(v.Session.Remote),
 				"pid":               fmt.Sprint(v.Process.Pid),
 				"start_time":        fmt.Sprint(v.StartTime),
 				"last_health_check": fmt.Sprint(v.LastHealthCheck),
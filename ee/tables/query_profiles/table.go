@@ -0,0 +1,98 @@
+package query_profiles
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+
+	"github.com/kolide/launcher/v2/ee/agent/types"
+	"github.com/kolide/launcher/v2/ee/observability"
+	"github.com/kolide/launcher/v2/ee/queryprofiler"
+	"github.com/kolide/launcher/v2/ee/tables/tablewrapper"
+	"github.com/osquery/osquery-go/plugin/table"
+)
+
+const tableName = "kolide_launcher_query_profiles"
+
+type Table struct {
+	slogger *slog.Logger
+	store   types.Iterator
+}
+
+func TablePlugin(flags types.Flags, slogger *slog.Logger, store types.Iterator) *table.Plugin {
+	columns := []table.ColumnDefinition{
+		table.TextColumn("name"),
+		table.TextColumn("source"),
+		table.BigIntColumn("timestamp"),
+		table.BigIntColumn("executions"),
+		table.BigIntColumn("wall_time_ms"),
+		table.BigIntColumn("avg_wall_time_ms"),
+		table.BigIntColumn("max_wall_time_ms"),
+		table.BigIntColumn("user_time_ms"),
+		table.BigIntColumn("system_time_ms"),
+		table.BigIntColumn("memory_bytes"),
+		table.BigIntColumn("output_bytes"),
+		table.BigIntColumn("rows"),
+		table.BigIntColumn("errors"),
+		table.BigIntColumn("timeouts"),
+		table.IntegerColumn("denylisted"),
+	}
+
+	t := &Table{
+		slogger: slogger.With("table", tableName),
+		store:   store,
+	}
+
+	return tablewrapper.New(flags, slogger, tableName, columns, t.generate,
+		tablewrapper.WithDescription("Per-query execution stats for osquery scheduled queries, distributed queries, and Kolide tables, over a rolling 24-hour window. Each row covers a single collection interval. Useful for finding expensive queries."),
+	)
+}
+
+func (t *Table) generate(ctx context.Context, queryContext table.QueryContext) ([]map[string]string, error) {
+	ctx, span := observability.StartSpan(ctx, "table_name", tableName)
+	defer span.End()
+
+	if t.store == nil {
+		return nil, errors.New("query profiles store is unavailable")
+	}
+
+	profiles, err := queryprofiler.Profiles(t.store)
+	if err != nil {
+		t.slogger.Log(ctx, slog.LevelInfo, "failure getting query profiles from store", "err", err)
+		return nil, err
+	}
+
+	results := make([]map[string]string, 0, len(profiles))
+	for _, p := range profiles {
+		var avgWallTimeMs int64
+		if p.Executions > 0 {
+			avgWallTimeMs = p.WallTimeMs / p.Executions
+		}
+
+		denylisted := "0"
+		if p.Denylisted {
+			denylisted = "1"
+		}
+
+		results = append(results, map[string]string{
+			"name":             p.Name,
+			"source":           p.Source,
+			"timestamp":        strconv.FormatInt(p.Timestamp, 10),
+			"executions":       strconv.FormatInt(p.Executions, 10),
+			"wall_time_ms":     strconv.FormatInt(p.WallTimeMs, 10),
+			"avg_wall_time_ms": strconv.FormatInt(avgWallTimeMs, 10),
+			"max_wall_time_ms": strconv.FormatInt(p.MaxWallTimeMs, 10),
+			"user_time_ms":     strconv.FormatInt(p.UserTimeMs, 10),
+			"system_time_ms":   strconv.FormatInt(p.SystemTimeMs, 10),
+			"memory_bytes":     strconv.FormatInt(p.MemoryBytes, 10),
+			"output_bytes":     strconv.FormatInt(p.OutputBytes, 10),
+			"rows":             strconv.FormatInt(p.Rows, 10),
+			"errors":           strconv.FormatInt(p.Errors, 10),
+			"timeouts":         strconv.FormatInt(p.Timeouts, 10),
+			"denylisted":       denylisted,
+		})
+	}
+
+	return results, nil
+}
@@ -0,0 +1,77 @@
+package tablewrapper
+
+import (
+	"sync"
+	"time"
+)
+
+// GenerateStats holds aggregated timing information about calls to a single table's
+// generate function, collected since the last call to `DrainGenerateStats`.
+type GenerateStats struct {
+	TableName     string
+	Executions    int64
+	WallTimeMs    int64
+	MaxWallTimeMs int64
+	Rows          int64
+	Errors        int64
+	Timeouts      int64
+	LastExecuted  time.Time
+}
+
+// generateStatsTracker accumulates GenerateStats for all wrapped tables. It is shared across
+// all tables so that consumers (e.g. the query profiler) can retrieve stats for every Kolide
+// table in one place.
+type generateStatsTracker struct {
+	lock  sync.Mutex
+	stats map[string]*GenerateStats
+}
+
+var tableGenerateStats = &generateStatsTracker{
+	stats: make(map[string]*GenerateStats),
+}
+
+// record adds the results of a single generate call to the stats for the given table.
+func (g *generateStatsTracker) record(tableName string, startTime time.Time, duration time.Duration, rowCount int, err error, timedOut bool) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	s, ok := g.stats[tableName]
+	if !ok {
+		s = &GenerateStats{TableName: tableName}
+		g.stats[tableName] = s
+	}
+
+	durationMs := duration.Milliseconds()
+	s.Executions += 1
+	s.WallTimeMs += durationMs
+	if durationMs > s.MaxWallTimeMs {
+		s.MaxWallTimeMs = durationMs
+	}
+	s.Rows += int64(rowCount)
+	if timedOut {
+		s.Timeouts += 1
+	} else if err != nil {
+		s.Errors += 1
+	}
+	s.LastExecuted = startTime
+}
+
+// drain returns all currently-collected stats and resets the tracker.
+func (g *generateStatsTracker) drain() []GenerateStats {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	results := make([]GenerateStats, 0, len(g.stats))
+	for _, s := range g.stats {
+		results = append(results, *s)
+	}
+	g.stats = make(map[string]*GenerateStats)
+
+	return results
+}
+
+// DrainGenerateStats returns the generate stats collected for all wrapped tables since the
+// last call to DrainGenerateStats, and resets the collected stats.
+func DrainGenerateStats() []GenerateStats {
+	return tableGenerateStats.drain()
+}
@@ -0,0 +1,47 @@
+package tablewrapper
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateStatsTracker(t *testing.T) {
+	t.Parallel()
+
+	tracker := &generateStatsTracker{
+		stats: make(map[string]*GenerateStats),
+	}
+
+	firstStart := time.Now().Add(-1 * time.Minute)
+	lastStart := time.Now()
+	tracker.record("table_a", firstStart, 100*time.Millisecond, 3, nil, false)
+	tracker.record("table_a", lastStart, 300*time.Millisecond, 2, errors.New("test error"), false)
+	tracker.record("table_b", lastStart, 4*time.Minute, 0, context.DeadlineExceeded, true)
+
+	stats := tracker.drain()
+	require.Equal(t, 2, len(stats))
+
+	statsByTable := make(map[string]GenerateStats)
+	for _, s := range stats {
+		statsByTable[s.TableName] = s
+	}
+
+	require.Equal(t, int64(2), statsByTable["table_a"].Executions)
+	require.Equal(t, int64(400), statsByTable["table_a"].WallTimeMs)
+	require.Equal(t, int64(300), statsByTable["table_a"].MaxWallTimeMs)
+	require.Equal(t, int64(5), statsByTable["table_a"].Rows)
+	require.Equal(t, int64(1), statsByTable["table_a"].Errors)
+	require.Equal(t, int64(0), statsByTable["table_a"].Timeouts)
+	require.True(t, lastStart.Equal(statsByTable["table_a"].LastExecuted))
+
+	require.Equal(t, int64(1), statsByTable["table_b"].Executions)
+	require.Equal(t, int64(0), statsByTable["table_b"].Errors)
+	require.Equal(t, int64(1), statsByTable["table_b"].Timeouts)
+
+	// Draining should reset the tracker
+	require.Equal(t, 0, len(tracker.drain()))
+}
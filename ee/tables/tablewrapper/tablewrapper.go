@@ -183,8 +183,10 @@ func (wt *wrappedTable) generate(ctx context.Context, queryContext table.QueryCo
 	// Wait for results up until the timeout
 	select {
 	case result := <-resultChan:
+		tableGenerateStats.record(wt.name, queryStartTime, time.Since(queryStartTime), len(result.rows), result.err, false)
 		return result.rows, result.err
 	case <-ctx.Done():
+		tableGenerateStats.record(wt.name, queryStartTime, time.Since(queryStartTime), 0, ctx.Err(), true)
 		queriedColumns := columnsFromConstraints(queryContext)
 		wt.slogger.Log(ctx, slog.LevelWarn,
 			"query timed out",
@@ -0,0 +1,342 @@
+//go:build linux
+// +build linux
+
+package tpmrunner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpmutil"
+	"github.com/kolide/launcher/v2/ee/observability"
+)
+
+const (
+	// ekCertNVIndexRSA and ekCertNVIndexECC are where TPM manufacturers provision the endorsement
+	// key certificates, per the TCG EK Credential Profile
+	ekCertNVIndexRSA tpmutil.Handle = 0x01C00002
+	ekCertNVIndexECC tpmutil.Handle = 0x01C0000A
+)
+
+// ekPolicy is the endorsement key authorization policy from the TCG EK Credential Profile:
+// PolicySecret(TPM_RH_ENDORSEMENT).
+var ekPolicy = []byte{
+	0x83, 0x71, 0x97, 0x67, 0x44, 0x84, 0xB3, 0xF8, 0x1A, 0x90, 0xCC, 0x8D, 0x46, 0xA5, 0xD7, 0x24,
+	0xFD, 0x52, 0xD7, 0x6E, 0x06, 0x52, 0x0B, 0x64, 0xF2, 0xA1, 0xDA, 0x1B, 0x33, 0x14, 0x69, 0xAA,
+}
+
+// ekTemplateRSA and ekTemplateECC are the default EK templates from the TCG EK Credential
+// Profile, so the EK we derive matches the one the manufacturer's certificate was issued for.
+var ekTemplateRSA = tpm2.Public{
+	Type:       tpm2.AlgRSA,
+	NameAlg:    tpm2.AlgSHA256,
+	Attributes: tpm2.FlagFixedTPM | tpm2.FlagFixedParent | tpm2.FlagSensitiveDataOrigin | tpm2.FlagAdminWithPolicy | tpm2.FlagRestricted | tpm2.FlagDecrypt,
+	AuthPolicy: ekPolicy,
+	RSAParameters: &tpm2.RSAParams{
+		Symmetric: &tpm2.SymScheme{
+			Alg:     tpm2.AlgAES,
+			KeyBits: 128,
+			Mode:    tpm2.AlgCFB,
+		},
+		KeyBits:    2048,
+		ModulusRaw: make([]byte, 256),
+	},
+}
+
+var ekTemplateECC = tpm2.Public{
+	Type:       tpm2.AlgECC,
+	NameAlg:    tpm2.AlgSHA256,
+	Attributes: tpm2.FlagFixedTPM | tpm2.FlagFixedParent | tpm2.FlagSensitiveDataOrigin | tpm2.FlagAdminWithPolicy | tpm2.FlagRestricted | tpm2.FlagDecrypt,
+	AuthPolicy: ekPolicy,
+	ECCParameters: &tpm2.ECCParams{
+		Symmetric: &tpm2.SymScheme{
+			Alg:     tpm2.AlgAES,
+			KeyBits: 128,
+			Mode:    tpm2.AlgCFB,
+		},
+		CurveID: tpm2.CurveNISTP256,
+		Point: tpm2.ECPoint{
+			XRaw: make([]byte, 32),
+			YRaw: make([]byte, 32),
+		},
+	},
+}
+
+// quotedPCRs are the PCRs covering firmware and boot configuration.
+var quotedPCRs = tpm2.PCRSelection{Hash: tpm2.AlgSHA256, PCRs: []int{0, 1, 2, 3, 4, 5, 6, 7}}
+
+// srkTemplate must match the parent key template used by krypto's tpm package, so that
+// the primary key we derive is the same one the signing keys were created under.
+var srkTemplate = tpm2.Public{
+	Type:       tpm2.AlgECC,
+	NameAlg:    tpm2.AlgSHA256,
+	Attributes: tpm2.FlagRestricted | tpm2.FlagDecrypt | tpm2.FlagUserWithAuth | tpm2.FlagFixedParent | tpm2.FlagFixedTPM | tpm2.FlagSensitiveDataOrigin,
+	ECCParameters: &tpm2.ECCParams{
+		Symmetric: &tpm2.SymScheme{
+			Alg:     tpm2.AlgAES,
+			KeyBits: 128,
+			Mode:    tpm2.AlgCFB,
+		},
+		CurveID: tpm2.CurveNISTP256,
+	},
+}
+
+// akTemplate is a restricted signing key, which the TPM will only use to sign data it
+// generates itself (quotes and certifications). It is a primary key, so the same AK is
+// derived each time without our needing to store it.
+var akTemplate = tpm2.Public{
+	Type:       tpm2.AlgECC,
+	NameAlg:    tpm2.AlgSHA256,
+	Attributes: tpm2.FlagSignerDefault,
+	ECCParameters: &tpm2.ECCParams{
+		Sign: &tpm2.SigScheme{
+			Alg:  tpm2.AlgECDSA,
+			Hash: tpm2.AlgSHA256,
+		},
+		CurveID: tpm2.CurveNISTP256,
+	},
+}
+
+// Attestation is the AK's certification of the hardware key (and any pending rotated-in key),
+// and its quote of the boot PCRs; both are bound to the server's nonce. On its own, it only
+// shows that the hardware key lives in the same TPM as the AK. The server establishes that the
+// AK is TPM-resident by checking the EK against its certificate, then challenging the AK with
+// TPM2_MakeCredential against the EK, which only this TPM can answer via ActivateCredential.
+// All TPM structures are in TPM wire format.
+type Attestation struct {
+	// AttestationKey is the AK's TPMT_PUBLIC area
+	AttestationKey []byte
+	// EndorsementKey is the EK's TPMT_PUBLIC area, which the server uses for TPM2_MakeCredential
+	EndorsementKey []byte
+	// EndorsementKeyCertificate is the manufacturer's certificate for EndorsementKey, if provisioned
+	EndorsementKeyCertificate []byte
+	// CertifyInfo and CertifySignature are the TPMS_ATTEST and TPMT_SIGNATURE from TPM2_Certify
+	// over the current hardware key
+	CertifyInfo      []byte
+	CertifySignature []byte
+	// PendingCertifyInfo and PendingCertifySignature certify the pending key, if there is one
+	PendingCertifyInfo      []byte
+	PendingCertifySignature []byte
+	// Quote and QuoteSignature are the TPMS_ATTEST and TPMT_SIGNATURE from TPM2_Quote
+	Quote          []byte
+	QuoteSignature []byte
+	// PCRs are the SHA256 PCR values covered by the quote
+	PCRs map[int][]byte
+}
+
+// Attest produces an Attestation for the current hardware key, bound to nonce.
+func (tr *tpmRunner) Attest(ctx context.Context, nonce []byte) (*Attestation, error) {
+	ctx, span := observability.StartSpan(ctx)
+	defer span.End()
+
+	if len(nonce) == 0 {
+		return nil, errors.New("nonce is required")
+	}
+
+	tr.mux.Lock()
+	defer tr.mux.Unlock()
+
+	if tr.signer == nil {
+		return nil, errors.New("no hardware key available")
+	}
+
+	priData, pubData, err := fetchKeyData(tr.store)
+	if err != nil {
+		return nil, fmt.Errorf("fetching key data: %w", err)
+	}
+
+	rw, err := tr.openTpm()
+	if err != nil {
+		return nil, fmt.Errorf("opening tpm: %w", err)
+	}
+	defer rw.Close()
+
+	// TPMs may only have room for three loaded objects, so we're done with the EK before
+	// loading the SRK, AK, and hardware key
+	ekCert, ekTemplate := readEKCertificate(rw)
+	ekPublicBytes, err := readEKPublic(rw, ekTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("reading endorsement key: %w", err)
+	}
+
+	srkHandle, _, err := tpm2.CreatePrimary(rw, tpm2.HandleOwner, tpm2.PCRSelection{}, "", "", srkTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("creating storage root key: %w", err)
+	}
+	defer tpm2.FlushContext(rw, srkHandle)
+
+	akHandle, _, err := tpm2.CreatePrimary(rw, tpm2.HandleOwner, tpm2.PCRSelection{}, "", "", akTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("creating attestation key: %w", err)
+	}
+	defer tpm2.FlushContext(rw, akHandle)
+
+	akPublic, _, _, err := tpm2.ReadPublic(rw, akHandle)
+	if err != nil {
+		return nil, fmt.Errorf("reading attestation key public area: %w", err)
+	}
+	akPublicBytes, err := akPublic.Encode()
+	if err != nil {
+		return nil, fmt.Errorf("encoding attestation key public area: %w", err)
+	}
+
+	attestation := &Attestation{
+		AttestationKey:            akPublicBytes,
+		EndorsementKey:            ekPublicBytes,
+		EndorsementKeyCertificate: ekCert,
+	}
+
+	attestation.CertifyInfo, attestation.CertifySignature, err = certifyKey(rw, srkHandle, akHandle, pubData, priData, nonce)
+	if err != nil {
+		thisErr := fmt.Errorf("certifying hardware key: %w", err)
+		observability.SetError(span, thisErr)
+		return nil, thisErr
+	}
+
+	if tr.pending != nil {
+		pendingPriData, err := tr.store.Get([]byte(pendingPrivateEccData))
+		if err != nil {
+			return nil, fmt.Errorf("fetching pending private key data: %w", err)
+		}
+		pendingPubData, err := tr.store.Get([]byte(pendingPublicEccData))
+		if err != nil {
+			return nil, fmt.Errorf("fetching pending public key data: %w", err)
+		}
+
+		attestation.PendingCertifyInfo, attestation.PendingCertifySignature, err = certifyKey(rw, srkHandle, akHandle, pendingPubData, pendingPriData, nonce)
+		if err != nil {
+			thisErr := fmt.Errorf("certifying pending hardware key: %w", err)
+			observability.SetError(span, thisErr)
+			return nil, thisErr
+		}
+	}
+
+	attestation.Quote, attestation.QuoteSignature, err = tpm2.QuoteRaw(rw, akHandle, "", "", nonce, quotedPCRs, tpm2.AlgNull)
+	if err != nil {
+		thisErr := fmt.Errorf("quoting pcrs: %w", err)
+		observability.SetError(span, thisErr)
+		return nil, thisErr
+	}
+
+	attestation.PCRs, err = tpm2.ReadPCRs(rw, quotedPCRs)
+	if err != nil {
+		return nil, fmt.Errorf("reading pcrs: %w", err)
+	}
+
+	span.AddEvent("created_tpm_attestation")
+	return attestation, nil
+}
+
+// ActivateCredential answers the server's TPM2_MakeCredential challenge: the TPM will only
+// decrypt the credential if the AK it names is resident alongside the EK that protects it.
+// credentialBlob and encryptedSecret are the TPM2B_ID_OBJECT and TPM2B_ENCRYPTED_SECRET
+// contents, without their size prefixes.
+func (tr *tpmRunner) ActivateCredential(ctx context.Context, credentialBlob, encryptedSecret []byte) ([]byte, error) {
+	ctx, span := observability.StartSpan(ctx)
+	defer span.End()
+
+	if len(credentialBlob) == 0 || len(encryptedSecret) == 0 {
+		return nil, errors.New("credential and secret are required")
+	}
+
+	tr.mux.Lock()
+	defer tr.mux.Unlock()
+
+	rw, err := tr.openTpm()
+	if err != nil {
+		return nil, fmt.Errorf("opening tpm: %w", err)
+	}
+	defer rw.Close()
+
+	akHandle, _, err := tpm2.CreatePrimary(rw, tpm2.HandleOwner, tpm2.PCRSelection{}, "", "", akTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("creating attestation key: %w", err)
+	}
+	defer tpm2.FlushContext(rw, akHandle)
+
+	_, ekTemplate := readEKCertificate(rw)
+	ekHandle, err := createEK(rw, ekTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("creating endorsement key: %w", err)
+	}
+	defer tpm2.FlushContext(rw, ekHandle)
+
+	// The EK's policy requires the endorsement hierarchy's authorization
+	sessionHandle, _, err := tpm2.StartAuthSession(rw, tpm2.HandleNull, tpm2.HandleNull, make([]byte, 16), nil, tpm2.SessionPolicy, tpm2.AlgNull, tpm2.AlgSHA256)
+	if err != nil {
+		return nil, fmt.Errorf("starting policy session: %w", err)
+	}
+	defer tpm2.FlushContext(rw, sessionHandle)
+
+	if _, _, err := tpm2.PolicySecret(rw, tpm2.HandleEndorsement, tpm2.AuthCommand{Session: tpm2.HandlePasswordSession, Attributes: tpm2.AttrContinueSession}, sessionHandle, nil, nil, nil, 0); err != nil {
+		return nil, fmt.Errorf("satisfying endorsement key policy: %w", err)
+	}
+
+	credential, err := tpm2.ActivateCredentialUsingAuth(rw, []tpm2.AuthCommand{
+		{Session: tpm2.HandlePasswordSession, Attributes: tpm2.AttrContinueSession},
+		{Session: sessionHandle, Attributes: tpm2.AttrContinueSession},
+	}, akHandle, ekHandle, credentialBlob, encryptedSecret)
+	if err != nil {
+		thisErr := fmt.Errorf("activating credential: %w", err)
+		observability.SetError(span, thisErr)
+		return nil, thisErr
+	}
+
+	span.AddEvent("activated_tpm_credential")
+	return credential, nil
+}
+
+// certifyKey loads the key under the SRK, and has the AK certify it.
+func certifyKey(rw io.ReadWriter, srkHandle, akHandle tpmutil.Handle, pubData, priData []byte, nonce []byte) ([]byte, []byte, error) {
+	keyHandle, _, err := tpm2.Load(rw, srkHandle, "", pubData, priData)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading key: %w", err)
+	}
+	defer tpm2.FlushContext(rw, keyHandle)
+
+	return tpm2.CertifyEx(rw, "", "", keyHandle, akHandle, nonce, tpm2.SigScheme{
+		Alg:  tpm2.AlgECDSA,
+		Hash: tpm2.AlgSHA256,
+	})
+}
+
+// readEKCertificate returns the EK certificate, preferring ECC, and the template for the EK it
+// certifies. If no certificate is provisioned, it returns nil and the RSA template.
+func readEKCertificate(rw io.ReadWriter) ([]byte, tpm2.Public) {
+	for _, ek := range []struct {
+		index    tpmutil.Handle
+		template tpm2.Public
+	}{
+		{ekCertNVIndexECC, ekTemplateECC},
+		{ekCertNVIndexRSA, ekTemplateRSA},
+	} {
+		if cert, err := tpm2.NVReadEx(rw, ek.index, tpm2.HandleOwner, "", 0); err == nil && len(cert) > 0 {
+			return cert, ek.template
+		}
+	}
+	return nil, ekTemplateRSA
+}
+
+// readEKPublic returns the EK's TPMT_PUBLIC area.
+func readEKPublic(rw io.ReadWriter, template tpm2.Public) ([]byte, error) {
+	ekHandle, err := createEK(rw, template)
+	if err != nil {
+		return nil, fmt.Errorf("creating endorsement key: %w", err)
+	}
+	defer tpm2.FlushContext(rw, ekHandle)
+
+	ekPublic, _, _, err := tpm2.ReadPublic(rw, ekHandle)
+	if err != nil {
+		return nil, fmt.Errorf("reading endorsement key public area: %w", err)
+	}
+	return ekPublic.Encode()
+}
+
+// createEK derives the endorsement key from the endorsement hierarchy.
+func createEK(rw io.ReadWriter, template tpm2.Public) (tpmutil.Handle, error) {
+	handle, _, err := tpm2.CreatePrimary(rw, tpm2.HandleEndorsement, tpm2.PCRSelection{}, "", "", template)
+	return handle, err
+}
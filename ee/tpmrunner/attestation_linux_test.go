@@ -0,0 +1,208 @@
+//go:build linux && tpmsimulator
+// +build linux,tpmsimulator
+
+package tpmrunner
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"io"
+	"testing"
+
+	"github.com/google/go-tpm-tools/simulator"
+	"github.com/google/go-tpm/tpm2"
+	"github.com/kolide/krypto/pkg/tpm"
+	"github.com/kolide/launcher/v2/ee/agent/storage/inmemory"
+	"github.com/kolide/launcher/v2/pkg/log/multislogger"
+	"github.com/stretchr/testify/require"
+)
+
+// simulatorSignerCreator creates keys in the software TPM simulator
+type simulatorSignerCreator struct {
+	sim io.ReadWriteCloser
+}
+
+func (s simulatorSignerCreator) CreateKey(opts ...tpm.TpmSignerOption) ([]byte, []byte, error) {
+	return tpm.CreateKey(append(opts, tpm.WithExternalTpm(s.sim))...)
+}
+
+func (s simulatorSignerCreator) New(private, public []byte) (crypto.Signer, error) {
+	return tpm.New(private, public, tpm.WithExternalTpm(s.sim))
+}
+
+// nopCloser keeps the runner from closing the shared simulator after each attestation
+type nopCloser struct {
+	io.ReadWriter
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+func simulatorTpmRunner(t *testing.T) *tpmRunner {
+	sim, err := simulator.Get()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, sim.Close())
+	})
+
+	tpmRunner, err := New(t.Context(), multislogger.NewNopLogger(), inmemory.NewStore(),
+		withTpmSignerCreator(simulatorSignerCreator{sim: sim}),
+		func(tr *tpmRunner) {
+			tr.openTpm = func() (io.ReadWriteCloser, error) {
+				return nopCloser{sim}, nil
+			}
+		},
+	)
+	require.NoError(t, err)
+	tpmRunner.machineHasTpm.Store(true)
+
+	require.NotNil(t, tpmRunner.Public())
+	return tpmRunner
+}
+
+func TestAttest(t *testing.T) {
+	t.Parallel()
+
+	tpmRunner := simulatorTpmRunner(t)
+	nonce := []byte("server provided nonce")
+
+	attestation, err := tpmRunner.Attest(t.Context(), nonce)
+	require.NoError(t, err)
+
+	akPublic, err := tpm2.DecodePublic(attestation.AttestationKey)
+	require.NoError(t, err)
+	akKey, err := akPublic.Key()
+	require.NoError(t, err)
+
+	// The certification should name the hardware key, and be bound to the nonce
+	certifyData := verifyAttestation(t, akKey, attestation.CertifyInfo, attestation.CertifySignature, nonce)
+	require.Equal(t, tpm2.TagAttestCertify, certifyData.Type)
+	requireCertifiesKey(t, pubDataFromStore(t, tpmRunner, publicEccData), certifyData)
+	require.Nil(t, attestation.PendingCertifyInfo)
+
+	// The quote should cover the PCRs we report
+	quoteData := verifyAttestation(t, akKey, attestation.Quote, attestation.QuoteSignature, nonce)
+	require.Equal(t, tpm2.TagAttestQuote, quoteData.Type)
+	require.Len(t, attestation.PCRs, len(quotedPCRs.PCRs))
+
+	// Attesting with a different nonce should produce a different certification
+	otherAttestation, err := tpmRunner.Attest(t.Context(), []byte("another nonce"))
+	require.NoError(t, err)
+	require.False(t, bytes.Equal(attestation.CertifyInfo, otherAttestation.CertifyInfo))
+}
+
+func TestAttest_PendingRotation(t *testing.T) {
+	t.Parallel()
+
+	tpmRunner := simulatorTpmRunner(t)
+	require.NoError(t, tpmRunner.RotateKey(t.Context()))
+
+	nonce := []byte("server provided nonce")
+	attestation, err := tpmRunner.Attest(t.Context(), nonce)
+	require.NoError(t, err)
+
+	akPublic, err := tpm2.DecodePublic(attestation.AttestationKey)
+	require.NoError(t, err)
+	akKey, err := akPublic.Key()
+	require.NoError(t, err)
+
+	pendingCertifyData := verifyAttestation(t, akKey, attestation.PendingCertifyInfo, attestation.PendingCertifySignature, nonce)
+	requireCertifiesKey(t, pubDataFromStore(t, tpmRunner, pendingPublicEccData), pendingCertifyData)
+
+	// The pending key must be usable for signing, as it is reported with a signature over the challenge
+	digest := sha256.Sum256([]byte("challenge"))
+	signature, err := tpmRunner.PendingKeyRotation().Signer.Sign(nil, digest[:], crypto.SHA256)
+	require.NoError(t, err)
+	require.True(t, ecdsa.VerifyASN1(tpmRunner.PendingKeyRotation().Signer.Public().(*ecdsa.PublicKey), digest[:], signature))
+}
+
+func TestAttest_RequiresNonce(t *testing.T) {
+	t.Parallel()
+
+	tpmRunner := simulatorTpmRunner(t)
+	_, err := tpmRunner.Attest(t.Context(), nil)
+	require.Error(t, err)
+}
+
+func TestActivateCredential(t *testing.T) {
+	t.Parallel()
+
+	tpmRunner := simulatorTpmRunner(t)
+	attestation, err := tpmRunner.Attest(t.Context(), []byte("server provided nonce"))
+	require.NoError(t, err)
+
+	// Make the credential as the server would, against the reported EK and AK
+	akPublic, err := tpm2.DecodePublic(attestation.AttestationKey)
+	require.NoError(t, err)
+	akName, err := akPublic.Name()
+	require.NoError(t, err)
+	akNameBytes, err := akName.Encode()
+	require.NoError(t, err)
+
+	rw, err := tpmRunner.openTpm()
+	require.NoError(t, err)
+	ekHandle, _, err := tpm2.CreatePrimary(rw, tpm2.HandleEndorsement, tpm2.PCRSelection{}, "", "", ekTemplateRSA)
+	require.NoError(t, err)
+	ekPublic, _, _, err := tpm2.ReadPublic(rw, ekHandle)
+	require.NoError(t, err)
+	ekPublicBytes, err := ekPublic.Encode()
+	require.NoError(t, err)
+	require.Equal(t, attestation.EndorsementKey, ekPublicBytes)
+
+	secret := []byte("credential activation secret")
+	credentialBlob, encryptedSecret, err := tpm2.MakeCredential(rw, ekHandle, secret, akNameBytes[2:])
+	require.NoError(t, err)
+	require.NoError(t, tpm2.FlushContext(rw, ekHandle))
+
+	activated, err := tpmRunner.ActivateCredential(t.Context(), credentialBlob, encryptedSecret)
+	require.NoError(t, err)
+	require.Equal(t, secret, activated)
+
+	// A credential made for a different key should not activate
+	otherName := append([]byte{}, akNameBytes[2:]...)
+	otherName[len(otherName)-1] ^= 0xff
+	ekHandle, _, err = tpm2.CreatePrimary(rw, tpm2.HandleEndorsement, tpm2.PCRSelection{}, "", "", ekTemplateRSA)
+	require.NoError(t, err)
+	credentialBlob, encryptedSecret, err = tpm2.MakeCredential(rw, ekHandle, secret, otherName)
+	require.NoError(t, err)
+	require.NoError(t, tpm2.FlushContext(rw, ekHandle))
+
+	_, err = tpmRunner.ActivateCredential(t.Context(), credentialBlob, encryptedSecret)
+	require.Error(t, err)
+}
+
+// verifyAttestation checks the AK's signature over the TPMS_ATTEST, and that it is bound to the nonce.
+func verifyAttestation(t *testing.T, akKey crypto.PublicKey, attest []byte, rawSignature []byte, nonce []byte) *tpm2.AttestationData {
+	signature, err := tpm2.DecodeSignature(bytes.NewBuffer(rawSignature))
+	require.NoError(t, err)
+	require.NotNil(t, signature.ECC)
+
+	digest := sha256.Sum256(attest)
+	require.True(t, ecdsa.Verify(akKey.(*ecdsa.PublicKey), digest[:], signature.ECC.R, signature.ECC.S), "signature should verify with attestation key")
+
+	attestationData, err := tpm2.DecodeAttestationData(attest)
+	require.NoError(t, err)
+	require.Equal(t, nonce, []byte(attestationData.ExtraData))
+
+	return attestationData
+}
+
+func requireCertifiesKey(t *testing.T, pubData []byte, certifyData *tpm2.AttestationData) {
+	require.NotNil(t, certifyData.AttestedCertifyInfo)
+
+	keyPublic, err := tpm2.DecodePublic(pubData)
+	require.NoError(t, err)
+	matches, err := certifyData.AttestedCertifyInfo.Name.MatchesPublic(keyPublic)
+	require.NoError(t, err)
+	require.True(t, matches, "certification should name the hardware key")
+}
+
+func pubDataFromStore(t *testing.T, tr *tpmRunner, key string) []byte {
+	pubData, err := tr.store.Get([]byte(key))
+	require.NoError(t, err)
+	require.NotNil(t, pubData)
+	return pubData
+}
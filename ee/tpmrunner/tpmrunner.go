@@ -4,8 +4,10 @@
 package tpmrunner
 
 import (
+	"bytes"
 	"context"
 	"crypto"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"io"
@@ -16,7 +18,10 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/google/go-tpm/tpm2"
+	"github.com/kolide/krypto/pkg/echelper"
 	"github.com/kolide/krypto/pkg/tpm"
+	"github.com/kolide/launcher/v2/ee/agent/keys"
 	"github.com/kolide/launcher/v2/ee/agent/types"
 	"github.com/kolide/launcher/v2/ee/observability"
 	"github.com/kolide/launcher/v2/pkg/backoff"
@@ -36,8 +41,11 @@ type tpmSignerCreator interface {
 type (
 	tpmRunner struct {
 		signer        crypto.Signer
+		keyCreatedAt  time.Time
+		pending       *keys.PendingRotation
 		mux           sync.Mutex
 		signerCreator tpmSignerCreator
+		openTpm       func() (io.ReadWriteCloser, error)
 		store         types.GetterSetterDeleter
 		slogger       *slog.Logger
 		interrupt     chan struct{}
@@ -56,7 +64,7 @@ type (
 
 // CreateKey creates a new TPM key
 func (d defaultTpmSignerCreator) CreateKey(opts ...tpm.TpmSignerOption) (private []byte, public []byte, err error) {
-	return tpm.CreateKey()
+	return tpm.CreateKey(opts...)
 }
 
 // New creates a new TPM signer
@@ -70,6 +78,9 @@ func New(ctx context.Context, slogger *slog.Logger, store types.GetterSetterDele
 		slogger:       slogger.With("component", "tpmrunner"),
 		interrupt:     make(chan struct{}),
 		signerCreator: defaultTpmSignerCreator{},
+		openTpm: func() (io.ReadWriteCloser, error) {
+			return tpm2.OpenTPM()
+		},
 	}
 
 	// assume we have a tpm until we know otherwise
@@ -183,6 +194,13 @@ func (tr *tpmRunner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts)
 const (
 	privateEccData = "privateEccData"
 	publicEccData  = "publicEccData"
+	keyCreatedAt   = "eccKeyCreatedAt"
+
+	// A rotated-in key is stored separately until the server acknowledges it
+	pendingPrivateEccData       = "pendingPrivateEccData"
+	pendingPublicEccData        = "pendingPublicEccData"
+	pendingKeyRotationSignature = "pendingEccKeyRotationSignature"
+	pendingKeyRotatedAt         = "pendingEccKeyRotatedAt"
 )
 
 func fetchKeyData(store types.Getter) ([]byte, []byte, error) {
@@ -289,11 +307,233 @@ func (tr *tpmRunner) loadOrCreateKeys(ctx context.Context) error {
 	}
 
 	tr.signer = k
+	tr.keyCreatedAt = tr.loadKeyCreatedAt(ctx)
 
 	tr.slogger.Log(ctx, slog.LevelDebug,
 		"tpm signer created",
 	)
 	span.AddEvent("created_tpm_signer")
 
+	if err := tr.loadPendingRotation(); err != nil {
+		// The pending key is not usable, so discard it -- the next rotation will create a new one
+		tr.slogger.Log(ctx, slog.LevelWarn,
+			"could not load pending key rotation, discarding it",
+			"err", err,
+		)
+		clearPendingRotation(tr.store)
+	}
+
+	return nil
+}
+
+// loadKeyCreatedAt returns when the current key was created. Keys created before we started
+// tracking this are treated as created now, so that they are not all rotated at once.
+func (tr *tpmRunner) loadKeyCreatedAt(ctx context.Context) time.Time {
+	createdAtRaw, err := tr.store.Get([]byte(keyCreatedAt))
+	if err == nil && createdAtRaw != nil {
+		if createdAt, err := time.Parse(time.RFC3339, string(createdAtRaw)); err == nil {
+			return createdAt
+		}
+	}
+
+	createdAt := time.Now().UTC().Truncate(time.Second)
+	if err := tr.store.Set([]byte(keyCreatedAt), []byte(createdAt.Format(time.RFC3339))); err != nil {
+		tr.slogger.Log(ctx, slog.LevelWarn,
+			"could not store key creation time",
+			"err", err,
+		)
+	}
+
+	return createdAt
+}
+
+// loadPendingRotation loads a rotated-in key that has not yet been acknowledged, if there is one.
+// Callers must hold tr.mux.
+func (tr *tpmRunner) loadPendingRotation() error {
+	priData, err := tr.store.Get([]byte(pendingPrivateEccData))
+	if err != nil {
+		return fmt.Errorf("fetching pending private key data: %w", err)
+	}
+	pubData, err := tr.store.Get([]byte(pendingPublicEccData))
+	if err != nil {
+		return fmt.Errorf("fetching pending public key data: %w", err)
+	}
+	if priData == nil || pubData == nil {
+		return nil
+	}
+
+	signature, err := tr.store.Get([]byte(pendingKeyRotationSignature))
+	if err != nil {
+		return fmt.Errorf("fetching pending key rotation signature: %w", err)
+	}
+	rotatedAtRaw, err := tr.store.Get([]byte(pendingKeyRotatedAt))
+	if err != nil {
+		return fmt.Errorf("fetching pending key rotation time: %w", err)
+	}
+	rotatedAt, err := time.Parse(time.RFC3339, string(rotatedAtRaw))
+	if err != nil {
+		return fmt.Errorf("parsing pending key rotation time: %w", err)
+	}
+
+	signer, err := tr.signerCreator.New(priData, pubData)
+	if err != nil {
+		return fmt.Errorf("creating pending tpm signer: %w", err)
+	}
+
+	tr.pending = &keys.PendingRotation{
+		Signer:    signer,
+		Signature: signature,
+		RotatedAt: rotatedAt,
+	}
+
+	return nil
+}
+
+func clearPendingRotation(deleter types.Deleter) {
+	_ = deleter.Delete([]byte(pendingPrivateEccData), []byte(pendingPublicEccData), []byte(pendingKeyRotationSignature), []byte(pendingKeyRotatedAt))
+}
+
+// RotateKey creates a new TPM key to replace the current one, and signs it with the current key.
+// The new key is reported alongside the current one until the server acknowledges it via
+// AcknowledgeKeyRotation; until then, the current key remains in use.
+func (tr *tpmRunner) RotateKey(ctx context.Context) error {
+	ctx, span := observability.StartSpan(ctx)
+	defer span.End()
+
+	tr.mux.Lock()
+	defer tr.mux.Unlock()
+
+	if tr.signer == nil {
+		return errors.New("no current key to rotate")
+	}
+	if tr.pending != nil {
+		return keys.ErrRotationPending
+	}
+
+	priData, pubData, err := tr.signerCreator.CreateKey()
+	if err != nil {
+		thisErr := fmt.Errorf("creating key: %w", err)
+		observability.SetError(span, thisErr)
+		return thisErr
+	}
+
+	newSigner, err := tr.signerCreator.New(priData, pubData)
+	if err != nil {
+		thisErr := fmt.Errorf("creating tpm signer for new key: %w", err)
+		observability.SetError(span, thisErr)
+		return thisErr
+	}
+
+	newKeyDer, err := x509.MarshalPKIXPublicKey(newSigner.Public())
+	if err != nil {
+		return fmt.Errorf("marshalling new public key: %w", err)
+	}
+
+	signature, err := echelper.Sign(tr.signer, newKeyDer)
+	if err != nil {
+		thisErr := fmt.Errorf("signing new key with current key: %w", err)
+		observability.SetError(span, thisErr)
+		return thisErr
+	}
+
+	rotatedAt := time.Now().UTC().Truncate(time.Second)
+	for k, v := range map[string][]byte{
+		pendingPrivateEccData:       priData,
+		pendingPublicEccData:        pubData,
+		pendingKeyRotationSignature: signature,
+		pendingKeyRotatedAt:         []byte(rotatedAt.Format(time.RFC3339)),
+	} {
+		if err := tr.store.Set([]byte(k), v); err != nil {
+			clearPendingRotation(tr.store)
+			thisErr := fmt.Errorf("storing pending key data: %w", err)
+			observability.SetError(span, thisErr)
+			return thisErr
+		}
+	}
+
+	tr.pending = &keys.PendingRotation{
+		Signer:    newSigner,
+		Signature: signature,
+		RotatedAt: rotatedAt,
+	}
+
+	tr.slogger.Log(ctx, slog.LevelInfo,
+		"rotated tpm key, awaiting acknowledgement",
+	)
+	span.AddEvent("rotated_tpm_key")
+
 	return nil
 }
+
+// AcknowledgeKeyRotation makes the pending key the current key, once the server has confirmed that
+// it knows about it. publicKeyDer must match the pending key, so that a stale acknowledgement cannot
+// promote a different key than the one the server saw.
+func (tr *tpmRunner) AcknowledgeKeyRotation(ctx context.Context, publicKeyDer []byte) error {
+	ctx, span := observability.StartSpan(ctx)
+	defer span.End()
+
+	tr.mux.Lock()
+	defer tr.mux.Unlock()
+
+	if tr.pending == nil {
+		return keys.ErrNoPendingRotation
+	}
+
+	pendingKeyDer, err := x509.MarshalPKIXPublicKey(tr.pending.Signer.Public())
+	if err != nil {
+		return fmt.Errorf("marshalling pending public key: %w", err)
+	}
+	if !bytes.Equal(pendingKeyDer, publicKeyDer) {
+		return keys.ErrRotationMismatch
+	}
+
+	priData, err := tr.store.Get([]byte(pendingPrivateEccData))
+	if err != nil {
+		return fmt.Errorf("fetching pending private key data: %w", err)
+	}
+	pubData, err := tr.store.Get([]byte(pendingPublicEccData))
+	if err != nil {
+		return fmt.Errorf("fetching pending public key data: %w", err)
+	}
+
+	if err := storeKeyData(tr.store, priData, pubData); err != nil {
+		// The pending key data is left in place, so that a retried acknowledgement can complete the rotation
+		thisErr := fmt.Errorf("storing new key data: %w", err)
+		observability.SetError(span, thisErr)
+		return thisErr
+	}
+	if err := tr.store.Set([]byte(keyCreatedAt), []byte(tr.pending.RotatedAt.Format(time.RFC3339))); err != nil {
+		tr.slogger.Log(ctx, slog.LevelWarn,
+			"could not store key creation time",
+			"err", err,
+		)
+	}
+	clearPendingRotation(tr.store)
+
+	tr.signer = tr.pending.Signer
+	tr.keyCreatedAt = tr.pending.RotatedAt
+	tr.pending = nil
+
+	tr.slogger.Log(ctx, slog.LevelInfo,
+		"tpm key rotation acknowledged, now using new key",
+	)
+	span.AddEvent("tpm_key_rotation_acknowledged")
+
+	return nil
+}
+
+// PendingKeyRotation returns the rotated-in key awaiting acknowledgement, if any.
+func (tr *tpmRunner) PendingKeyRotation() *keys.PendingRotation {
+	tr.mux.Lock()
+	defer tr.mux.Unlock()
+
+	return tr.pending
+}
+
+// KeyCreatedAt returns when the current key was created.
+func (tr *tpmRunner) KeyCreatedAt() time.Time {
+	tr.mux.Lock()
+	defer tr.mux.Unlock()
+
+	return tr.keyCreatedAt
+}
@@ -4,12 +4,14 @@
 package tpmrunner
 
 import (
+	"crypto/x509"
 	"errors"
 	"log/slog"
 	"testing"
 	"time"
 
 	"github.com/kolide/krypto/pkg/echelper"
+	"github.com/kolide/launcher/v2/ee/agent/keys"
 	"github.com/kolide/launcher/v2/ee/agent/storage/inmemory"
 	"github.com/kolide/launcher/v2/ee/tpmrunner/mocks"
 	"github.com/kolide/launcher/v2/pkg/log/multislogger"
@@ -150,3 +152,78 @@ func Test_tpmRunner(t *testing.T) {
 		require.Equal(t, expectedInterrupts, receivedInterrupts)
 	})
 }
+
+func Test_tpmRunner_rotation(t *testing.T) {
+	t.Parallel()
+
+	currentKey, err := echelper.GenerateEcdsaKey()
+	require.NoError(t, err)
+	newKey, err := echelper.GenerateEcdsaKey()
+	require.NoError(t, err)
+
+	fakePrivData, fakePubData := []byte("fake priv data"), []byte("fake pub data")
+	newPrivData, newPubData := []byte("new priv data"), []byte("new pub data")
+
+	store := inmemory.NewStore()
+	require.NoError(t, storeKeyData(store, fakePrivData, fakePubData))
+
+	tpmSignerCreatorMock := mocks.NewTpmSignerCreator(t)
+	tpmRunner, err := New(t.Context(), multislogger.NewNopLogger(), store, withTpmSignerCreator(tpmSignerCreatorMock))
+	require.NoError(t, err)
+	tpmRunner.machineHasTpm.Store(true)
+
+	tpmSignerCreatorMock.On("New", fakePrivData, fakePubData).Return(currentKey, nil).Once()
+	require.Equal(t, &currentKey.PublicKey, tpmRunner.Public())
+	require.False(t, tpmRunner.KeyCreatedAt().IsZero(), "existing key should be given a creation time")
+	require.Nil(t, tpmRunner.PendingKeyRotation())
+
+	// Acknowledging without a pending rotation is an error
+	require.ErrorIs(t, tpmRunner.AcknowledgeKeyRotation(t.Context(), []byte("anything")), keys.ErrNoPendingRotation)
+
+	tpmSignerCreatorMock.On("CreateKey").Return(newPrivData, newPubData, nil).Once()
+	tpmSignerCreatorMock.On("New", newPrivData, newPubData).Return(newKey, nil).Once()
+	require.NoError(t, tpmRunner.RotateKey(t.Context()))
+
+	// The current key stays in use until the rotation is acknowledged
+	require.Equal(t, &currentKey.PublicKey, tpmRunner.Public())
+	require.ErrorIs(t, tpmRunner.RotateKey(t.Context()), keys.ErrRotationPending)
+
+	pending := tpmRunner.PendingKeyRotation()
+	require.NotNil(t, pending)
+	require.Equal(t, &newKey.PublicKey, pending.Signer.Public())
+	newKeyDer, err := x509.MarshalPKIXPublicKey(&newKey.PublicKey)
+	require.NoError(t, err)
+	require.NoError(t, echelper.VerifySignature(&currentKey.PublicKey, newKeyDer, pending.Signature), "new key should be signed by current key")
+
+	// A new runner over the same store should load the pending rotation
+	reloadedSignerCreatorMock := mocks.NewTpmSignerCreator(t)
+	reloadedRunner, err := New(t.Context(), multislogger.NewNopLogger(), store, withTpmSignerCreator(reloadedSignerCreatorMock))
+	require.NoError(t, err)
+	reloadedRunner.machineHasTpm.Store(true)
+	reloadedSignerCreatorMock.On("New", fakePrivData, fakePubData).Return(currentKey, nil).Once()
+	reloadedSignerCreatorMock.On("New", newPrivData, newPubData).Return(newKey, nil).Once()
+	require.NotNil(t, reloadedRunner.Public())
+	require.NotNil(t, reloadedRunner.PendingKeyRotation())
+	require.Equal(t, pending.Signature, reloadedRunner.PendingKeyRotation().Signature)
+
+	// Acknowledging a different key should not promote the pending key
+	otherKey, err := echelper.GenerateEcdsaKey()
+	require.NoError(t, err)
+	otherKeyDer, err := x509.MarshalPKIXPublicKey(&otherKey.PublicKey)
+	require.NoError(t, err)
+	require.ErrorIs(t, tpmRunner.AcknowledgeKeyRotation(t.Context(), otherKeyDer), keys.ErrRotationMismatch)
+	require.Equal(t, &currentKey.PublicKey, tpmRunner.Public())
+
+	require.NoError(t, tpmRunner.AcknowledgeKeyRotation(t.Context(), newKeyDer))
+	require.Equal(t, &newKey.PublicKey, tpmRunner.Public())
+	require.Nil(t, tpmRunner.PendingKeyRotation())
+	require.Equal(t, pending.RotatedAt, tpmRunner.KeyCreatedAt())
+
+	priData, pubData, err := fetchKeyData(store)
+	require.NoError(t, err)
+	require.Equal(t, newPrivData, priData)
+	require.Equal(t, newPubData, pubData)
+	pendingPriData, err := store.Get([]byte(pendingPrivateEccData))
+	require.NoError(t, err)
+	require.Nil(t, pendingPriData)
+}
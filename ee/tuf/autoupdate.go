@@ -75,11 +75,13 @@ type (
 	controlServerAutoupdateRequest struct {
 		BinariesToUpdate   []binaryToUpdate `json:"binaries_to_update"`
 		BypassInitialDelay bool             `json:"bypass_initial_delay,omitempty"`
+		UpdateChannel      string           `json:"update_channel,omitempty"` // if set, temporarily overrides the update channel until PinExpiry
+		PinExpiry          int64            `json:"pin_expiry,omitempty"`     // unix timestamp at which any version pins or channel override expire
 	}
 
-	// In the future, we may allow for setting a particular version here as well
 	binaryToUpdate struct {
-		Name string `json:"name"`
+		Name    string `json:"name"`
+		Version string `json:"version,omitempty"` // if set, temporarily pins the binary to this version until PinExpiry
 	}
 )
 
@@ -97,6 +99,7 @@ type librarian interface {
 type TufAutoupdater struct {
 	metadataClient       *client.Client
 	libraryManager       librarian
+	updateDirectory      string
 	osqueryTimeout       time.Duration
 	knapsack             types.Knapsack
 	updateChannel        string
@@ -114,6 +117,8 @@ type TufAutoupdater struct {
 	restartFuncs         map[autoupdatableBinary]func(context.Context) error
 	calculatedSplayDelay *atomic.Int64          // the randomly selected delay within the download splay window
 	osqueryHistory       types.OsqueryHistorian // used to determine the version of the currently-running osquery process
+	pinStore             types.KVStore          // persists version pins and channel overrides sent via the autoupdate action
+	isMeteredConnection  func(context.Context) (bool, error)
 	// Restarts are gated by the initial delay: during the delay we still check for and download
 	// updates, but defer the restart until the delay ends, recording the pending restart here.
 	pendingRestartLauncherVersion *kolideatomic.String // launcher version awaiting restart (empty if none)
@@ -131,6 +136,14 @@ func WithOsqueryRestart(restart func(context.Context) error) TufAutoupdaterOptio
 	}
 }
 
+// WithPinStore provides the autoupdater with a store to persist version pins and channel overrides
+// sent via the autoupdate action, so that they survive launcher restarts until they expire.
+func WithPinStore(store types.KVStore) TufAutoupdaterOption {
+	return func(ta *TufAutoupdater) {
+		ta.pinStore = store
+	}
+}
+
 // WithOsqueryHistory provides the autoupdater with osquery instance history, so that it can
 // determine the version of the currently-running osquery process.
 func WithOsqueryHistory(osqueryHistory types.OsqueryHistorian) TufAutoupdaterOption {
@@ -164,6 +177,7 @@ func NewTufAutoupdater(ctx context.Context, k types.Knapsack, metadataHttpClient
 		slogger:                       k.Slogger().With("component", "tuf_autoupdater"),
 		restartFuncs:                  make(map[autoupdatableBinary]func(context.Context) error),
 		calculatedSplayDelay:          &atomic.Int64{},
+		isMeteredConnection:           isMeteredConnection,
 		pendingRestartLauncherVersion: kolideatomic.NewString(""),
 		pendingRestartOsquerydVersion: kolideatomic.NewString(""),
 	}
@@ -186,11 +200,18 @@ func NewTufAutoupdater(ctx context.Context, k types.Knapsack, metadataHttpClient
 	if updateDirectory == "" {
 		updateDirectory = DefaultLibraryDirectory(k.RootDirectory())
 	}
-	ta.libraryManager, err = newUpdateLibraryManager(k.MirrorServerURL(), mirrorHttpClient, updateDirectory, k.Slogger())
+	ta.updateDirectory = updateDirectory
+	ta.libraryManager, err = newUpdateLibraryManager(k.MirrorServerURL(), mirrorHttpClient, updateDirectory, k.Slogger(),
+		withLanCache(k),
+		withBandwidthLimit(k.AutoupdateBandwidthLimitKB),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("could not init update library manager: %w", err)
 	}
 
+	// Re-apply any unexpired pins that the control server previously sent via the autoupdate action
+	ta.restorePins(ctx)
+
 	// Subscribe to changes in update-related flags
 	ta.knapsack.RegisterChangeObserver(ta, keys.UpdateChannel, keys.PinnedLauncherVersion, keys.PinnedOsquerydVersion, keys.AutoupdateDownloadSplay, keys.AutoupdateInterval, keys.AutoupdateInitialDelay)
 
@@ -402,6 +423,18 @@ func (ta *TufAutoupdater) Do(data io.Reader) error {
 		return nil
 	}
 
+	if updateRequest.hasPins() {
+		if err := ta.applyPins(ctx, updateRequest); err != nil {
+			observability.AutoupdateFailureCounter.Add(ctx, 1)
+			ta.slogger.Log(ctx, slog.LevelError,
+				"error applying pins per control server request",
+				"err", err,
+			)
+
+			return fmt.Errorf("could not apply pins: %w", err)
+		}
+	}
+
 	ta.slogger.Log(ctx, slog.LevelInfo,
 		"received request from control server to check for update now",
 		"binaries_to_update", fmt.Sprintf("%+v", binariesToUpdate),
@@ -640,27 +673,10 @@ func (ta *TufAutoupdater) checkForUpdate(ctx context.Context, binariesToCheck []
 		return nil
 	}
 
-	// Attempt an update a couple times before returning an error -- sometimes we just hit caching issues.
-	errs := make([]error, 0)
-	successfulUpdate := false
-	updateTryCount := 3
-	for i := 0; i < updateTryCount; i += 1 {
-		_, err := ta.metadataClient.Update()
-		if err == nil {
-			successfulUpdate = true
-			break
-		}
-
-		errs = append(errs, fmt.Errorf("try %d: %w", i, err))
-	}
-	if !successfulUpdate {
-		return fmt.Errorf("could not update metadata after %d tries: %+v", updateTryCount, errs)
-	}
-
 	// Find the newest release for our channel
-	targets, err := ta.metadataClient.Targets()
+	targets, err := ta.fetchTargets()
 	if err != nil {
-		return fmt.Errorf("could not get complete list of targets: %w", err)
+		return err
 	}
 
 	// Check for and download any new releases that are available
@@ -757,6 +773,34 @@ func (ta *TufAutoupdater) checkForUpdate(ctx context.Context, binariesToCheck []
 	return nil
 }
 
+// fetchTargets fetches the latest metadata from the TUF server and returns the complete list of targets.
+// Callers are expected to hold ta.updateLock.
+func (ta *TufAutoupdater) fetchTargets() (data.TargetFiles, error) {
+	// Attempt an update a couple times before returning an error -- sometimes we just hit caching issues.
+	errs := make([]error, 0)
+	successfulUpdate := false
+	updateTryCount := 3
+	for i := 0; i < updateTryCount; i += 1 {
+		_, err := ta.metadataClient.Update()
+		if err == nil {
+			successfulUpdate = true
+			break
+		}
+
+		errs = append(errs, fmt.Errorf("try %d: %w", i, err))
+	}
+	if !successfulUpdate {
+		return nil, fmt.Errorf("could not update metadata after %d tries: %+v", updateTryCount, errs)
+	}
+
+	targets, err := ta.metadataClient.Targets()
+	if err != nil {
+		return nil, fmt.Errorf("could not get complete list of targets: %w", err)
+	}
+
+	return targets, nil
+}
+
 // downloadUpdate will download a new release for the given binary, if available from TUF
 // and not already downloaded. If allowDelay is true, the download may be delayed according to
 // the promotion time and the knapsack.AutoupdateDownloadSplay.
@@ -766,6 +810,16 @@ func (ta *TufAutoupdater) downloadUpdate(binary autoupdatableBinary, targets dat
 		return "", fmt.Errorf("could not find appropriate target: %w", err)
 	}
 
+	// Don't download or restart into a version that failed post-update probation
+	if isBadVersion(ta.updateDirectory, binary, versionFromTarget(binary, target)) {
+		ta.slogger.Log(context.TODO(), slog.LevelWarn,
+			"target was marked bad after failing post-update probation, not updating to it",
+			"binary", binary,
+			"target", target,
+		)
+		return "", nil
+	}
+
 	// Ensure we don't download duplicate versions
 	var currentVersion string
 	currentVersion, _ = ta.currentRunningVersion(binary)
@@ -802,6 +856,17 @@ func (ta *TufAutoupdater) downloadUpdate(binary autoupdatableBinary, targets dat
 		return "", nil
 	}
 
+	// Hold off on scheduled downloads while on a metered connection -- downloads requested via
+	// the control server still proceed.
+	if allowDelay && ta.onMeteredConnection() {
+		ta.slogger.Log(context.TODO(), slog.LevelInfo,
+			"on metered connection, deferring download",
+			"binary", binary,
+			"target", target,
+		)
+		return "", nil
+	}
+
 	// We haven't yet downloaded this release -- download it
 	if err := ta.libraryManager.AddToLibrary(binary, currentVersion, target, targetMetadata); err != nil {
 		return "", fmt.Errorf("could not add target %s for binary %s to library: %w", target, binary, err)
@@ -810,6 +875,24 @@ func (ta *TufAutoupdater) downloadUpdate(binary autoupdatableBinary, targets dat
 	return target, nil
 }
 
+// onMeteredConnection returns true if the device is currently on a metered connection.
+// If we cannot determine whether the connection is metered, we assume it is not.
+func (ta *TufAutoupdater) onMeteredConnection() bool {
+	ctx, cancel := context.WithTimeout(context.TODO(), 30*time.Second)
+	defer cancel()
+
+	metered, err := ta.isMeteredConnection(ctx)
+	if err != nil {
+		ta.slogger.Log(ctx, slog.LevelDebug,
+			"could not determine whether connection is metered",
+			"err", err,
+		)
+		return false
+	}
+
+	return metered
+}
+
 // findTarget selects the appropriate target from `targets` for the given binary, using the pinned version (if set)
 // and otherwise selecting the correct release for the given channel.
 func findTarget(ctx context.Context, binary autoupdatableBinary, targets data.TargetFiles, pinnedVersion string, channel string, slogger *slog.Logger) (string, data.TargetFileMeta, error) {
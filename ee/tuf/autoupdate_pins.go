@@ -0,0 +1,270 @@
+package tuf
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/kolide/launcher/v2/ee/observability"
+	"github.com/kolide/launcher/v2/pkg/launcher"
+	"github.com/theupdateframework/go-tuf/data"
+)
+
+const (
+	// defaultPinDuration is used when the control server sends a pin without an expiry --
+	// we never want a device to stay pinned indefinitely via the autoupdate action.
+	defaultPinDuration = 24 * time.Hour
+	maxPinDuration     = 30 * 24 * time.Hour
+
+	// updateChannelPinKey is the key in the pin store for the update channel override;
+	// version pins are stored under their binary's name.
+	updateChannelPinKey = "update_channel"
+)
+
+// autoupdatePin is a version pin or channel override sent via the autoupdate action,
+// as persisted in the pin store.
+type autoupdatePin struct {
+	Value  string `json:"value"`
+	Expiry int64  `json:"expiry"`
+}
+
+// hasPins returns true if the request sets a version for any binary or overrides the update channel.
+func (r controlServerAutoupdateRequest) hasPins() bool {
+	if r.UpdateChannel != "" {
+		return true
+	}
+	for _, b := range r.BinariesToUpdate {
+		if b.Version != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// pinDuration returns how long pins in the request should last, or an error if the expiry has already passed.
+func (r controlServerAutoupdateRequest) pinDuration(now time.Time) (time.Duration, error) {
+	if r.PinExpiry == 0 {
+		return defaultPinDuration, nil
+	}
+
+	duration := time.Unix(r.PinExpiry, 0).Sub(now)
+	if duration <= 0 {
+		return 0, fmt.Errorf("pin expiry %d is in the past", r.PinExpiry)
+	}
+
+	return min(duration, maxPinDuration), nil
+}
+
+// applyPins validates the version pins and channel override in the given request against the
+// current TUF targets, then applies the valid ones as flag overrides that last until the pin expiry.
+// Invalid pins are logged and ignored. When a pin expires, the flag override expires with it, and
+// FlagsChanged will revert the binary to the release for the update channel.
+func (ta *TufAutoupdater) applyPins(ctx context.Context, updateRequest controlServerAutoupdateRequest) error {
+	ctx, span := observability.StartSpan(ctx)
+	defer span.End()
+
+	now := time.Now()
+	duration, err := updateRequest.pinDuration(now)
+	if err != nil {
+		ta.slogger.Log(ctx, slog.LevelWarn,
+			"received expired pin from control server, ignoring",
+			"err", err,
+		)
+		return nil
+	}
+	expiry := now.Add(duration)
+
+	ta.updateLock.Lock()
+	targets, err := ta.fetchTargets()
+	ta.updateLock.Unlock()
+	if err != nil {
+		return fmt.Errorf("fetching targets to validate pins: %w", err)
+	}
+
+	if updateRequest.UpdateChannel != "" {
+		if err := validateChannel(ctx, updateRequest.UpdateChannel, targets); err != nil {
+			ta.slogger.Log(ctx, slog.LevelWarn,
+				"received invalid update channel override from control server, ignoring",
+				"update_channel", updateRequest.UpdateChannel,
+				"err", err,
+			)
+		} else {
+			// Update our stored channel before setting the override so that FlagsChanged does not
+			// perform a redundant update check -- Do will check for updates shortly.
+			ta.updateChannel = updateRequest.UpdateChannel
+			ta.knapsack.SetUpdateChannelOverride(updateRequest.UpdateChannel, duration)
+			ta.storePin(ctx, updateChannelPinKey, updateRequest.UpdateChannel, expiry)
+
+			ta.slogger.Log(ctx, slog.LevelInfo,
+				"applied update channel override from control server",
+				"update_channel", updateRequest.UpdateChannel,
+				"expiry", expiry.UTC().Format(time.RFC3339),
+			)
+		}
+	}
+
+	for _, b := range updateRequest.BinariesToUpdate {
+		if b.Version == "" {
+			continue
+		}
+		binary, ok := autoupdatableBinaryMap[b.Name]
+		if !ok {
+			continue
+		}
+
+		if err := validatePinnedVersion(ctx, binary, b.Version, targets); err != nil {
+			ta.slogger.Log(ctx, slog.LevelWarn,
+				"received invalid version pin from control server, ignoring",
+				"binary", binary,
+				"version", b.Version,
+				"err", err,
+			)
+			continue
+		}
+
+		// As above, update our stored pin first so that FlagsChanged does not perform a redundant update check
+		ta.pinnedVersions[binary] = b.Version
+		ta.setPinnedVersionOverride(binary, b.Version, duration)
+		ta.storePin(ctx, string(binary), b.Version, expiry)
+
+		ta.slogger.Log(ctx, slog.LevelInfo,
+			"applied version pin from control server",
+			"binary", binary,
+			"version", b.Version,
+			"expiry", expiry.UTC().Format(time.RFC3339),
+		)
+	}
+
+	return nil
+}
+
+// validateChannel confirms that the given channel is valid and has a release for each binary.
+func validateChannel(ctx context.Context, channel string, targets data.TargetFiles) error {
+	if launcher.SanitizeUpdateChannel(channel) != channel {
+		return fmt.Errorf("unknown update channel %s", channel)
+	}
+
+	for _, binary := range binaries {
+		if _, _, err := findRelease(ctx, binary, targets, channel); err != nil {
+			return fmt.Errorf("finding release for %s: %w", binary, err)
+		}
+	}
+
+	return nil
+}
+
+// validatePinnedVersion confirms that the given version is a valid pin for the binary and exists in the TUF targets.
+func validatePinnedVersion(ctx context.Context, binary autoupdatableBinary, version string, targets data.TargetFiles) error {
+	if SanitizePinnedVersion(binary, version) != version {
+		return fmt.Errorf("version %s is not valid for pinning", version)
+	}
+
+	if _, _, err := findTargetByVersion(ctx, binary, targets, version); err != nil {
+		return fmt.Errorf("finding target: %w", err)
+	}
+
+	return nil
+}
+
+// setPinnedVersionOverride overrides the pinned version flag for the given binary.
+func (ta *TufAutoupdater) setPinnedVersionOverride(binary autoupdatableBinary, version string, duration time.Duration) {
+	switch binary {
+	case binaryLauncher:
+		ta.knapsack.SetPinnedLauncherVersionOverride(version, duration)
+	case binaryOsqueryd:
+		ta.knapsack.SetPinnedOsquerydVersionOverride(version, duration)
+	}
+}
+
+// storePin persists the given pin, if we have a pin store, so that it can be restored after a restart.
+func (ta *TufAutoupdater) storePin(ctx context.Context, key string, value string, expiry time.Time) {
+	if ta.pinStore == nil {
+		return
+	}
+
+	rawPin, err := json.Marshal(autoupdatePin{Value: value, Expiry: expiry.Unix()})
+	if err != nil {
+		ta.slogger.Log(ctx, slog.LevelWarn,
+			"could not marshal pin",
+			"key", key,
+			"err", err,
+		)
+		return
+	}
+
+	if err := ta.pinStore.Set([]byte(key), rawPin); err != nil {
+		ta.slogger.Log(ctx, slog.LevelWarn,
+			"could not store pin",
+			"key", key,
+			"err", err,
+		)
+	}
+}
+
+// restorePins re-applies any unexpired pins from the pin store as flag overrides, and removes expired pins.
+// Flag overrides are held in memory only, so this ensures that pins survive launcher restarts --
+// including the restart performed to load a newly-pinned launcher version.
+func (ta *TufAutoupdater) restorePins(ctx context.Context) {
+	if ta.pinStore == nil {
+		return
+	}
+
+	ctx, span := observability.StartSpan(ctx)
+	defer span.End()
+
+	now := time.Now()
+	expiredKeys := make([][]byte, 0)
+	if err := ta.pinStore.ForEach(func(k, v []byte) error {
+		var pin autoupdatePin
+		if err := json.Unmarshal(v, &pin); err != nil {
+			expiredKeys = append(expiredKeys, bytes.Clone(k))
+			return nil
+		}
+
+		remaining := time.Unix(pin.Expiry, 0).Sub(now)
+		if remaining <= 0 {
+			expiredKeys = append(expiredKeys, bytes.Clone(k))
+			return nil
+		}
+
+		if string(k) == updateChannelPinKey {
+			ta.knapsack.SetUpdateChannelOverride(pin.Value, remaining)
+		} else if binary, ok := autoupdatableBinaryMap[string(k)]; ok {
+			ta.setPinnedVersionOverride(binary, pin.Value, remaining)
+		} else {
+			expiredKeys = append(expiredKeys, bytes.Clone(k))
+			return nil
+		}
+
+		ta.slogger.Log(ctx, slog.LevelInfo,
+			"restored pin from control server",
+			"key", string(k),
+			"value", pin.Value,
+			"expiry", time.Unix(pin.Expiry, 0).UTC().Format(time.RFC3339),
+		)
+		return nil
+	}); err != nil {
+		ta.slogger.Log(ctx, slog.LevelWarn,
+			"could not iterate over stored pins",
+			"err", err,
+		)
+	}
+
+	if len(expiredKeys) > 0 {
+		if err := ta.pinStore.Delete(expiredKeys...); err != nil {
+			ta.slogger.Log(ctx, slog.LevelWarn,
+				"could not delete expired pins",
+				"err", err,
+			)
+		}
+	}
+
+	// Refresh our view of the update settings now that the overrides are in place
+	ta.updateChannel = ta.knapsack.UpdateChannel()
+	for binary, getter := range ta.pinnedVersionGetters {
+		ta.pinnedVersions[binary] = getter()
+	}
+}
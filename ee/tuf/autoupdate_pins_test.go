@@ -0,0 +1,232 @@
+package tuf
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/kolide/launcher/v2/ee/agent/flags/keys"
+	"github.com/kolide/launcher/v2/ee/agent/storage/inmemory"
+	typesmocks "github.com/kolide/launcher/v2/ee/agent/types/mocks"
+	tufci "github.com/kolide/launcher/v2/ee/tuf/ci"
+	"github.com/kolide/launcher/v2/pkg/log/multislogger"
+	mock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDo_WithPins(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name                  string
+		updateData            controlServerAutoupdateRequest
+		expectedTarget        string
+		expectedPinnedVersion string
+		expectedUpdateChannel string
+	}{
+		{
+			name: "valid version pin and channel override",
+			updateData: controlServerAutoupdateRequest{
+				BinariesToUpdate: []binaryToUpdate{
+					{
+						Name:    "osqueryd",
+						Version: tufci.NonReleaseVersion,
+					},
+				},
+				UpdateChannel: "beta",
+				PinExpiry:     time.Now().Add(1 * time.Hour).Unix(),
+			},
+			expectedTarget:        fmt.Sprintf("osqueryd-%s.tar.gz", tufci.NonReleaseVersion),
+			expectedPinnedVersion: tufci.NonReleaseVersion,
+			expectedUpdateChannel: "beta",
+		},
+		{
+			name: "version that does not exist in TUF",
+			updateData: controlServerAutoupdateRequest{
+				BinariesToUpdate: []binaryToUpdate{
+					{
+						Name:    "osqueryd",
+						Version: "9.9.9",
+					},
+				},
+				PinExpiry: time.Now().Add(1 * time.Hour).Unix(),
+			},
+			expectedTarget:        "osqueryd-2.2.3.tar.gz",
+			expectedPinnedVersion: "",
+			expectedUpdateChannel: "nightly",
+		},
+		{
+			name: "invalid channel",
+			updateData: controlServerAutoupdateRequest{
+				BinariesToUpdate: []binaryToUpdate{
+					{
+						Name: "osqueryd",
+					},
+				},
+				UpdateChannel: "not_a_channel",
+			},
+			expectedTarget:        "osqueryd-2.2.3.tar.gz",
+			expectedPinnedVersion: "",
+			expectedUpdateChannel: "nightly",
+		},
+		{
+			name: "expired pin",
+			updateData: controlServerAutoupdateRequest{
+				BinariesToUpdate: []binaryToUpdate{
+					{
+						Name:    "osqueryd",
+						Version: tufci.NonReleaseVersion,
+					},
+				},
+				PinExpiry: time.Now().Add(-1 * time.Hour).Unix(),
+			},
+			expectedTarget:        "osqueryd-2.2.3.tar.gz",
+			expectedPinnedVersion: "",
+			expectedUpdateChannel: "nightly",
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			testRootDir := t.TempDir()
+			testReleaseVersion := "2.2.3"
+			tufServerUrl, rootJson := tufci.InitRemoteTufServer(t, testReleaseVersion)
+
+			// Set up osquery binary
+			osqBinaryPath := filepath.Join(t.TempDir(), "osqueryd")
+			if runtime.GOOS == "windows" {
+				osqBinaryPath += ".exe"
+			}
+			tufci.CopyOlderBinary(t, osqBinaryPath)
+
+			mockKnapsack := typesmocks.NewKnapsack(t)
+			mockKnapsack.On("RootDirectory").Return(testRootDir)
+			mockKnapsack.On("UpdateChannel").Return("nightly")
+			mockKnapsack.On("PinnedLauncherVersion").Return("")
+			mockKnapsack.On("PinnedOsquerydVersion").Return("")
+			mockKnapsack.On("AutoupdateInitialDelay").Return(0 * time.Second)
+			mockKnapsack.On("TufServerURL").Return(tufServerUrl)
+			mockKnapsack.On("UpdateDirectory").Return("")
+			mockKnapsack.On("MirrorServerURL").Return("https://example.com")
+			mockKnapsack.On("LocalDevelopmentPath").Return("").Maybe()
+			mockKnapsack.On("Slogger").Return(multislogger.NewNopLogger())
+			mockKnapsack.On("InModernStandby").Return(false)
+			mockKnapsack.On("RegisterChangeObserver", mock.Anything, keys.UpdateChannel, keys.PinnedLauncherVersion, keys.PinnedOsquerydVersion, keys.AutoupdateDownloadSplay, keys.AutoupdateInterval, keys.AutoupdateInitialDelay).Return()
+			mockKnapsack.On("LatestOsquerydPath", mock.Anything).Return(osqBinaryPath).Maybe()
+			if tt.expectedPinnedVersion != "" {
+				mockKnapsack.On("SetPinnedOsquerydVersionOverride", tt.expectedPinnedVersion, mock.Anything).Return()
+			}
+			if tt.expectedUpdateChannel != "nightly" {
+				mockKnapsack.On("SetUpdateChannelOverride", tt.expectedUpdateChannel, mock.Anything).Return()
+			}
+
+			// Set up autoupdater
+			pinStore := inmemory.NewStore()
+			client := &http.Client{}
+			t.Cleanup(func() {
+				client.CloseIdleConnections()
+			})
+			autoupdater, err := NewTufAutoupdater(t.Context(), mockKnapsack, client, client, WithOsqueryRestart(func(context.Context) error { return nil }), WithPinStore(pinStore))
+			require.NoError(t, err, "could not initialize new TUF autoupdater")
+
+			// Update the metadata client with our test root JSON
+			require.NoError(t, autoupdater.metadataClient.Init(rootJson), "could not initialize metadata client with test root JSON")
+
+			// Expect that we attempt to update the library with the expected target
+			mockLibraryManager := NewMocklibrarian(t)
+			autoupdater.libraryManager = mockLibraryManager
+			mockLibraryManager.On("Available", binaryOsqueryd, tt.expectedTarget).Return(false)
+			mockLibraryManager.On("AddToLibrary", binaryOsqueryd, mock.Anything, tt.expectedTarget, mock.Anything).Return(nil)
+
+			// Prepare control server request
+			rawRequest, err := json.Marshal(tt.updateData)
+			require.NoError(t, err, "marshalling update request")
+
+			// Make request
+			require.NoError(t, autoupdater.Do(bytes.NewReader(rawRequest)), "expected no error making update request")
+
+			mockLibraryManager.AssertExpectations(t)
+			mockKnapsack.AssertExpectations(t)
+
+			// Confirm the pins were applied and persisted only if valid
+			require.Equal(t, tt.expectedPinnedVersion, autoupdater.pinnedVersions[binaryOsqueryd])
+			require.Equal(t, tt.expectedUpdateChannel, autoupdater.updateChannel)
+
+			storedPin, err := pinStore.Get([]byte(binaryOsqueryd))
+			require.NoError(t, err)
+			if tt.expectedPinnedVersion == "" {
+				require.Nil(t, storedPin)
+			} else {
+				var pin autoupdatePin
+				require.NoError(t, json.Unmarshal(storedPin, &pin))
+				require.Equal(t, tt.expectedPinnedVersion, pin.Value)
+				require.Equal(t, tt.updateData.PinExpiry, pin.Expiry)
+			}
+		})
+	}
+}
+
+func TestRestorePins(t *testing.T) {
+	t.Parallel()
+
+	pinStore := inmemory.NewStore()
+	setPin := func(key string, value string, expiry time.Time) {
+		rawPin, err := json.Marshal(autoupdatePin{Value: value, Expiry: expiry.Unix()})
+		require.NoError(t, err)
+		require.NoError(t, pinStore.Set([]byte(key), rawPin))
+	}
+	setPin(string(binaryLauncher), "1.20.0", time.Now().Add(1*time.Hour))
+	setPin(string(binaryOsqueryd), "5.11.0", time.Now().Add(-1*time.Hour))
+	setPin(updateChannelPinKey, "beta", time.Now().Add(1*time.Hour))
+
+	tufServerUrl, _ := tufci.InitRemoteTufServer(t, "1.20.0")
+
+	mockKnapsack := typesmocks.NewKnapsack(t)
+	mockKnapsack.On("RootDirectory").Return(t.TempDir())
+	mockKnapsack.On("AutoupdateInitialDelay").Return(0 * time.Second)
+	mockKnapsack.On("TufServerURL").Return(tufServerUrl)
+	mockKnapsack.On("UpdateDirectory").Return("")
+	mockKnapsack.On("MirrorServerURL").Return("https://example.com")
+	mockKnapsack.On("Slogger").Return(multislogger.NewNopLogger())
+	mockKnapsack.On("RegisterChangeObserver", mock.Anything, keys.UpdateChannel, keys.PinnedLauncherVersion, keys.PinnedOsquerydVersion, keys.AutoupdateDownloadSplay, keys.AutoupdateInterval, keys.AutoupdateInitialDelay).Return()
+	mockKnapsack.On("PinnedOsquerydVersion").Return("")
+
+	// Only the unexpired pins should be restored
+	mockKnapsack.On("SetPinnedLauncherVersionOverride", "1.20.0", mock.MatchedBy(func(d time.Duration) bool {
+		return d > 59*time.Minute && d <= 1*time.Hour
+	})).Return()
+	mockKnapsack.On("SetUpdateChannelOverride", "beta", mock.Anything).Return()
+
+	// Before the pins are restored, we have no overrides; afterward, we should see them
+	mockKnapsack.On("UpdateChannel").Return("nightly").Once()
+	mockKnapsack.On("UpdateChannel").Return("beta")
+	mockKnapsack.On("PinnedLauncherVersion").Return("").Once()
+	mockKnapsack.On("PinnedLauncherVersion").Return("1.20.0")
+
+	client := &http.Client{}
+	t.Cleanup(func() {
+		client.CloseIdleConnections()
+	})
+	autoupdater, err := NewTufAutoupdater(t.Context(), mockKnapsack, client, client, WithPinStore(pinStore))
+	require.NoError(t, err, "could not initialize new TUF autoupdater")
+
+	mockKnapsack.AssertExpectations(t)
+	require.Equal(t, "beta", autoupdater.updateChannel)
+	require.Equal(t, "1.20.0", autoupdater.pinnedVersions[binaryLauncher])
+	require.Equal(t, "", autoupdater.pinnedVersions[binaryOsqueryd])
+
+	// The expired pin should have been removed from the store
+	expiredPin, err := pinStore.Get([]byte(binaryOsqueryd))
+	require.NoError(t, err)
+	require.Nil(t, expiredPin)
+	pinCount, err := pinStore.Count()
+	require.NoError(t, err)
+	require.Equal(t, 2, pinCount)
+}
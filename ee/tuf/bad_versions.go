@@ -0,0 +1,83 @@
+package tuf
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// badVersionsFilename is the name of the file, in the base update directory, that records versions
+// that failed their post-update probation period. We store this alongside the update library rather
+// than in the agent database because library lookup happens before the database is available.
+const badVersionsFilename = "bad_versions.json"
+
+var badVersionsLock sync.Mutex
+
+func badVersionsPath(baseUpdateDirectory string) string {
+	return filepath.Join(baseUpdateDirectory, badVersionsFilename)
+}
+
+// readBadVersions returns all versions marked bad, by binary. A missing file means no versions are bad.
+func readBadVersions(baseUpdateDirectory string) (map[autoupdatableBinary][]string, error) {
+	badVersions := make(map[autoupdatableBinary][]string)
+
+	rawBadVersions, err := os.ReadFile(badVersionsPath(baseUpdateDirectory))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return badVersions, nil
+		}
+		return nil, fmt.Errorf("reading bad versions file: %w", err)
+	}
+
+	if err := json.Unmarshal(rawBadVersions, &badVersions); err != nil {
+		return nil, fmt.Errorf("unmarshalling bad versions: %w", err)
+	}
+
+	return badVersions, nil
+}
+
+// isBadVersion returns true if the given version of the binary has been marked bad.
+func isBadVersion(baseUpdateDirectory string, binary autoupdatableBinary, version string) bool {
+	badVersions, err := readBadVersions(baseUpdateDirectory)
+	if err != nil {
+		return false
+	}
+	return slices.Contains(badVersions[binary], version)
+}
+
+// markBadVersion records that the given version of the binary failed its post-update probation period,
+// so that library lookup will no longer select it and the autoupdater will not download or restart into it again.
+func markBadVersion(baseUpdateDirectory string, binary autoupdatableBinary, version string) error {
+	badVersionsLock.Lock()
+	defer badVersionsLock.Unlock()
+
+	badVersions, err := readBadVersions(baseUpdateDirectory)
+	if err != nil {
+		// The file is corrupt -- start over rather than failing to record this version
+		badVersions = make(map[autoupdatableBinary][]string)
+	}
+
+	if slices.Contains(badVersions[binary], version) {
+		return nil
+	}
+	badVersions[binary] = append(badVersions[binary], version)
+
+	rawBadVersions, err := json.Marshal(badVersions)
+	if err != nil {
+		return fmt.Errorf("marshalling bad versions: %w", err)
+	}
+
+	if err := os.MkdirAll(baseUpdateDirectory, 0755); err != nil {
+		return fmt.Errorf("creating update directory: %w", err)
+	}
+
+	if err := os.WriteFile(badVersionsPath(baseUpdateDirectory), rawBadVersions, 0644); err != nil {
+		return fmt.Errorf("writing bad versions file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,193 @@
+package tuf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/theupdateframework/go-tuf/data"
+	tufutil "github.com/theupdateframework/go-tuf/util"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// stagedUpdatesDirectoryPrefix prefixes the staging directory for each target version. The staging
+	// directory persists across download attempts so that interrupted downloads can be resumed.
+	stagedUpdatesDirectoryPrefix = "staged-updates-"
+
+	// maxStagedUpdateAge is how long we keep a staging directory with a partial download around
+	// before TidyLibrary removes it.
+	maxStagedUpdateAge = 7 * 24 * time.Hour
+
+	partialDownloadSuffix = ".partial"
+)
+
+// errDownloadIncomplete indicates that a download was interrupted, and the partial download
+// was retained so that it can be resumed later.
+var errDownloadIncomplete = errors.New("download incomplete")
+
+// stagingDirectory returns the staging directory for the given version of the binary.
+func stagingDirectory(binary autoupdatableBinary, baseUpdateDirectory string, targetVersion string) string {
+	return filepath.Join(updatesDirectory(binary, baseUpdateDirectory), stagedUpdatesDirectoryPrefix+targetVersion)
+}
+
+// isRecentStagingDirectory returns true if the given entry in the binary's update library is a staging
+// directory that may still hold a partial download worth resuming.
+func isRecentStagingDirectory(binary autoupdatableBinary, baseUpdateDirectory string, name string) bool {
+	if !strings.HasPrefix(name, stagedUpdatesDirectoryPrefix) {
+		return false
+	}
+
+	info, err := os.Stat(filepath.Join(updatesDirectory(binary, baseUpdateDirectory), name))
+	if err != nil {
+		return false
+	}
+
+	return time.Since(info.ModTime()) < maxStagedUpdateAge
+}
+
+// downloadResumable downloads the target from the given URL to partialPath, resuming from any data
+// already present there via an HTTP range request. Once the download is complete, it verifies the
+// download against the given, validated local metadata. A partial download that fails verification
+// is removed. If the download is interrupted, it returns errDownloadIncomplete.
+func downloadResumable(ctx context.Context, client *http.Client, url string, signRequest func(*http.Request), limiter *rate.Limiter,
+	partialPath string, localTargetMetadata data.TargetFileMeta) error {
+	var offset int64
+	if info, err := os.Stat(partialPath); err == nil {
+		offset = info.Size()
+	}
+	if offset > localTargetMetadata.Length {
+		if err := os.Remove(partialPath); err != nil {
+			return fmt.Errorf("removing oversized partial download: %w", err)
+		}
+		offset = 0
+	}
+
+	if offset < localTargetMetadata.Length {
+		if err := downloadRemainder(ctx, client, url, signRequest, limiter, partialPath, offset, localTargetMetadata.Length); err != nil {
+			return err
+		}
+	}
+
+	// Verify the complete download against the confirmed local metadata
+	downloaded, err := os.Open(partialPath)
+	if err != nil {
+		return fmt.Errorf("opening download for verification: %w", err)
+	}
+	actualTargetMeta, err := tufutil.GenerateTargetFileMeta(downloaded, localTargetMetadata.HashAlgorithms()...)
+	downloaded.Close()
+	if err != nil {
+		return fmt.Errorf("could not compute metadata for download: %w", err)
+	}
+
+	if err := tufutil.TargetFileMetaEqual(actualTargetMeta, localTargetMetadata); err != nil {
+		// Start over next time rather than resuming a corrupt download
+		os.Remove(partialPath)
+		return fmt.Errorf("verification failed for download from %s: %w", url, err)
+	}
+
+	return nil
+}
+
+// downloadRemainder requests the bytes of the target after offset, appending them to partialPath.
+func downloadRemainder(ctx context.Context, client *http.Client, url string, signRequest func(*http.Request), limiter *rate.Limiter,
+	partialPath string, offset int64, length int64) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	if signRequest != nil {
+		signRequest(req)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: making request: %w", errDownloadIncomplete, err)
+	}
+	defer resp.Body.Close()
+
+	fileFlags := os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		var rangeStart int64
+		if _, err := fmt.Sscanf(resp.Header.Get("Content-Range"), "bytes %d-", &rangeStart); err != nil || rangeStart != offset {
+			os.Remove(partialPath)
+			return fmt.Errorf("server responded with unexpected range %q for offset %d", resp.Header.Get("Content-Range"), offset)
+		}
+	case http.StatusOK:
+		// The server does not support range requests -- start over
+		fileFlags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		offset = 0
+	default:
+		if resp.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+			os.Remove(partialPath)
+		}
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	// We create the file with 0655 permissions to prevent any other user from writing to this file.
+	out, err := os.OpenFile(partialPath, fileFlags, 0655)
+	if err != nil {
+		return fmt.Errorf("opening partial download %s: %w", partialPath, err)
+	}
+
+	// Wrap the download in a LimitReader so we read at most the expected length
+	var body io.Reader = io.LimitReader(resp.Body, length-offset)
+	if limiter != nil {
+		body = &rateLimitedReader{ctx: ctx, r: body, limiter: limiter}
+	}
+
+	written, copyErr := io.Copy(out, body)
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("closing partial download %s: %w", partialPath, err)
+	}
+	if copyErr != nil {
+		return fmt.Errorf("%w: downloaded %d of %d bytes: %w", errDownloadIncomplete, offset+written, length, copyErr)
+	}
+	if offset+written < length {
+		// The server completed its response without sending the whole target, so resuming won't help
+		os.Remove(partialPath)
+		return fmt.Errorf("server sent %d of %d bytes", offset+written, length)
+	}
+
+	return nil
+}
+
+// newDownloadLimiter returns a rate limiter for the given limit in KB per second, or nil if unlimited.
+func newDownloadLimiter(limitKB int) *rate.Limiter {
+	if limitKB <= 0 {
+		return nil
+	}
+	bytesPerSecond := limitKB * 1024
+	return rate.NewLimiter(rate.Limit(bytesPerSecond), bytesPerSecond)
+}
+
+// rateLimitedReader limits reads from the underlying reader to the limiter's rate.
+type rateLimitedReader struct {
+	ctx     context.Context // nolint:containedctx
+	r       io.Reader
+	limiter *rate.Limiter
+}
+
+func (rlr *rateLimitedReader) Read(p []byte) (int, error) {
+	if len(p) > rlr.limiter.Burst() {
+		p = p[:rlr.limiter.Burst()]
+	}
+
+	n, err := rlr.r.Read(p)
+	if n > 0 {
+		if waitErr := rlr.limiter.WaitN(rlr.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,135 @@
+package tuf
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	tufutil "github.com/theupdateframework/go-tuf/util"
+	"golang.org/x/time/rate"
+)
+
+func Test_downloadResumable(t *testing.T) {
+	t.Parallel()
+
+	targetContents := bytes.Repeat([]byte("test target "), 1000)
+	targetMeta, err := tufutil.GenerateTargetFileMeta(bytes.NewReader(targetContents), "sha512")
+	require.NoError(t, err)
+
+	t.Run("resumes partial download", func(t *testing.T) {
+		t.Parallel()
+
+		var rangeHeader atomic.Value
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rangeHeader.Store(r.Header.Get("Range"))
+			http.ServeContent(w, r, "target", time.Now(), bytes.NewReader(targetContents))
+		}))
+		t.Cleanup(srv.Close)
+
+		partialPath := filepath.Join(t.TempDir(), "target"+partialDownloadSuffix)
+		require.NoError(t, os.WriteFile(partialPath, targetContents[:5000], 0644))
+
+		require.NoError(t, downloadResumable(t.Context(), srv.Client(), srv.URL, nil, nil, partialPath, targetMeta))
+		require.Equal(t, "bytes=5000-", rangeHeader.Load())
+
+		downloaded, err := os.ReadFile(partialPath)
+		require.NoError(t, err)
+		require.Equal(t, targetContents, downloaded)
+	})
+
+	t.Run("starts over when server does not support ranges", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write(targetContents)
+		}))
+		t.Cleanup(srv.Close)
+
+		partialPath := filepath.Join(t.TempDir(), "target"+partialDownloadSuffix)
+		require.NoError(t, os.WriteFile(partialPath, targetContents[:5000], 0644))
+
+		require.NoError(t, downloadResumable(t.Context(), srv.Client(), srv.URL, nil, nil, partialPath, targetMeta))
+
+		downloaded, err := os.ReadFile(partialPath)
+		require.NoError(t, err)
+		require.Equal(t, targetContents, downloaded)
+	})
+
+	t.Run("retains partial download when interrupted", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Promise the full target, but only send part of it
+			w.Header().Set("Content-Length", "12000")
+			w.Write(targetContents[:3000])
+		}))
+		t.Cleanup(srv.Close)
+
+		partialPath := filepath.Join(t.TempDir(), "target"+partialDownloadSuffix)
+		err := downloadResumable(t.Context(), srv.Client(), srv.URL, nil, nil, partialPath, targetMeta)
+		require.True(t, errors.Is(err, errDownloadIncomplete), "expected incomplete download error, got %v", err)
+
+		partial, err := os.ReadFile(partialPath)
+		require.NoError(t, err)
+		require.Equal(t, targetContents[:3000], partial)
+	})
+
+	t.Run("removes download that fails verification", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.ServeContent(w, r, "target", time.Now(), strings.NewReader(strings.Repeat("evil target ", 1000)))
+		}))
+		t.Cleanup(srv.Close)
+
+		partialPath := filepath.Join(t.TempDir(), "target"+partialDownloadSuffix)
+		require.NoError(t, os.WriteFile(partialPath, targetContents[:5000], 0644))
+
+		err := downloadResumable(t.Context(), srv.Client(), srv.URL, nil, nil, partialPath, targetMeta)
+		require.Error(t, err)
+		require.False(t, errors.Is(err, errDownloadIncomplete))
+		require.NoFileExists(t, partialPath)
+	})
+}
+
+func Test_rateLimitedReader(t *testing.T) {
+	t.Parallel()
+
+	require.Nil(t, newDownloadLimiter(0))
+	require.Equal(t, 10*1024, newDownloadLimiter(10).Burst())
+
+	// 4 KB at 8 KB/s, with the initial 1 KB burst, should take at least 375ms
+	limiter := rate.NewLimiter(rate.Limit(8*1024), 1024)
+	rlr := &rateLimitedReader{ctx: t.Context(), r: bytes.NewReader(make([]byte, 4*1024)), limiter: limiter}
+
+	start := time.Now()
+	n, err := io.Copy(io.Discard, rlr)
+	require.NoError(t, err)
+	require.Equal(t, int64(4*1024), n)
+	require.GreaterOrEqual(t, time.Since(start), 350*time.Millisecond)
+}
+
+func Test_isRecentStagingDirectory(t *testing.T) {
+	t.Parallel()
+
+	baseDir := t.TempDir()
+	recentDir := stagingDirectory(binaryLauncher, baseDir, "1.20.0")
+	oldDir := stagingDirectory(binaryLauncher, baseDir, "1.19.0")
+	require.NoError(t, os.MkdirAll(recentDir, 0755))
+	require.NoError(t, os.MkdirAll(oldDir, 0755))
+	oldTime := time.Now().Add(-2 * maxStagedUpdateAge)
+	require.NoError(t, os.Chtimes(oldDir, oldTime, oldTime))
+
+	require.True(t, isRecentStagingDirectory(binaryLauncher, baseDir, filepath.Base(recentDir)))
+	require.False(t, isRecentStagingDirectory(binaryLauncher, baseDir, filepath.Base(oldDir)))
+	require.False(t, isRecentStagingDirectory(binaryLauncher, baseDir, "1.20.0"))
+}
@@ -7,7 +7,8 @@ import (
 )
 
 type LauncherReloadNeeded struct {
-	msg string
+	msg      string
+	rollback bool
 }
 
 func NewLauncherReloadNeededErr(launcherVersion string) LauncherReloadNeeded {
@@ -16,6 +17,15 @@ func NewLauncherReloadNeededErr(launcherVersion string) LauncherReloadNeeded {
 	}
 }
 
+// NewLauncherRollbackNeededErr returns a LauncherReloadNeeded error indicating that launcher should
+// reload to run the previous version, because the given version failed its post-update probation.
+func NewLauncherRollbackNeededErr(badLauncherVersion string) LauncherReloadNeeded {
+	return LauncherReloadNeeded{
+		msg:      fmt.Sprintf("need to reload launcher: version %s failed post-update probation", badLauncherVersion),
+		rollback: true,
+	}
+}
+
 func (e LauncherReloadNeeded) Error() string {
 	return e.msg
 }
@@ -30,3 +40,10 @@ func (e LauncherReloadNeeded) Is(target error) bool {
 func IsLauncherReloadNeededErr(err error) bool {
 	return errors.Is(err, LauncherReloadNeeded{})
 }
+
+// IsLauncherRollbackNeededErr returns true if the error indicates that the running launcher version
+// failed its post-update probation, and so must not be run again.
+func IsLauncherRollbackNeededErr(err error) bool {
+	var reloadErr LauncherReloadNeeded
+	return errors.As(err, &reloadErr) && reloadErr.rollback
+}
@@ -0,0 +1,299 @@
+package tuf
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/kolide/launcher/v2/ee/agent/flags/keys"
+	"github.com/kolide/launcher/v2/ee/agent/types"
+)
+
+// The LAN cache allows launcher installations on the same local network to share update downloads.
+// A launcher with a LAN cache listen address retains the archives it has downloaded and verified,
+// and serves them to peers. A launcher with configured LAN cache peers attempts to download targets
+// from those peers before falling back to the mirror. Peers are untrusted: every download, regardless
+// of its source, is verified against the official TUF metadata before it is added to the library.
+// Requests between peers are authenticated with an HMAC keyed with the enroll secret, so that
+// only launchers belonging to the same organization can download from one another.
+const (
+	lanCacheDirectoryName   = "lan-cache"
+	lanCacheTimestampHeader = "X-Kolide-Lan-Cache-Timestamp"
+	lanCacheSignatureHeader = "X-Kolide-Lan-Cache-Signature"
+	lanCacheMaxClockSkew    = 5 * time.Minute
+	lanCachePeerTimeout     = 2 * time.Minute
+)
+
+// lanCacheSettings is satisfied by the knapsack.
+type lanCacheSettings interface {
+	LanCacheListenAddress() string
+	LanCachePeers() string
+	ReadEnrollSecret() (string, error)
+}
+
+// lanCacheDirectory returns the location of the retained update archives for the given binary.
+func lanCacheDirectory(binary autoupdatableBinary, baseUpdateDirectory string) string {
+	return filepath.Join(baseUpdateDirectory, lanCacheDirectoryName, string(binary))
+}
+
+// targetDownloadPath returns the path to the given target on the mirror; LAN cache peers serve targets at the same path.
+func targetDownloadPath(binary autoupdatableBinary, targetFilename string) string {
+	return path.Join("/", "kolide", string(binary), runtime.GOOS, PlatformArch(), targetFilename)
+}
+
+// lanCachePeers parses the comma-separated peer list into base URLs.
+func lanCachePeers(settings lanCacheSettings) []string {
+	peers := make([]string, 0)
+	for _, peer := range strings.Split(settings.LanCachePeers(), ",") {
+		peer = strings.TrimSpace(peer)
+		if peer == "" {
+			continue
+		}
+		if !strings.Contains(peer, "://") {
+			peer = "http://" + peer
+		}
+		peers = append(peers, strings.TrimSuffix(peer, "/"))
+	}
+	return peers
+}
+
+// lanCacheSignature computes the HMAC for a LAN cache request.
+func lanCacheSignature(secret string, method string, requestPath string, timestamp string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(method + "\n" + requestPath + "\n" + timestamp))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// signLanCacheRequest adds authentication headers to a request to a LAN cache peer.
+func signLanCacheRequest(req *http.Request, secret string, now time.Time) {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	req.Header.Set(lanCacheTimestampHeader, timestamp)
+	req.Header.Set(lanCacheSignatureHeader, lanCacheSignature(secret, req.Method, req.URL.Path, timestamp))
+}
+
+// verifyLanCacheRequest validates the authentication headers on a request from a LAN cache peer.
+func verifyLanCacheRequest(r *http.Request, secret string, now time.Time) error {
+	timestamp := r.Header.Get(lanCacheTimestampHeader)
+	requestTime, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp %q: %w", timestamp, err)
+	}
+
+	skew := now.Sub(time.Unix(requestTime, 0))
+	if skew > lanCacheMaxClockSkew || skew < -lanCacheMaxClockSkew {
+		return fmt.Errorf("timestamp %s outside allowed clock skew", timestamp)
+	}
+
+	expected := lanCacheSignature(secret, r.Method, r.URL.Path, timestamp)
+	if !hmac.Equal([]byte(expected), []byte(r.Header.Get(lanCacheSignatureHeader))) {
+		return errors.New("invalid signature")
+	}
+
+	return nil
+}
+
+// LanCacheServer serves verified update archives from the update library to launcher peers on the
+// local network, when a LAN cache listen address is configured.
+type LanCacheServer struct {
+	slogger         *slog.Logger
+	knapsack        types.Knapsack
+	updateDirectory string
+	restart         chan struct{}
+	interrupt       chan struct{}
+	interrupted     atomic.Bool
+}
+
+func NewLanCacheServer(k types.Knapsack) *LanCacheServer {
+	updateDirectory := k.UpdateDirectory()
+	if updateDirectory == "" {
+		updateDirectory = DefaultLibraryDirectory(k.RootDirectory())
+	}
+
+	s := &LanCacheServer{
+		slogger:         k.Slogger().With("component", "lan_cache_server"),
+		knapsack:        k,
+		updateDirectory: updateDirectory,
+		restart:         make(chan struct{}, 1),
+		interrupt:       make(chan struct{}, 1),
+	}
+
+	k.RegisterChangeObserver(s, keys.LanCacheListenAddress)
+
+	return s
+}
+
+func (s *LanCacheServer) Execute() error {
+	ctx := context.TODO()
+
+	for {
+		srv := s.serve(ctx)
+
+		select {
+		case <-s.interrupt:
+			s.shutdown(ctx, srv)
+			return nil
+		case <-s.restart:
+			s.shutdown(ctx, srv)
+		}
+	}
+}
+
+func (s *LanCacheServer) Interrupt(_ error) {
+	// Only perform shutdown tasks on first call to interrupt -- no need to repeat on potential extra calls.
+	if s.interrupted.Swap(true) {
+		return
+	}
+
+	s.interrupt <- struct{}{}
+}
+
+// FlagsChanged restarts the server on the new listen address.
+func (s *LanCacheServer) FlagsChanged(ctx context.Context, flagKeys ...keys.FlagKey) {
+	if !keys.Contains(flagKeys, keys.LanCacheListenAddress) {
+		return
+	}
+
+	select {
+	case s.restart <- struct{}{}:
+	default:
+		// A restart is already pending
+	}
+}
+
+// serve starts serving on the configured listen address, returning nil if serving is disabled or
+// the server could not start.
+func (s *LanCacheServer) serve(ctx context.Context) *http.Server {
+	address := s.knapsack.LanCacheListenAddress()
+	if address == "" {
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		s.slogger.Log(ctx, slog.LevelWarn,
+			"could not listen on LAN cache address",
+			"address", address,
+			"err", err,
+		)
+		return nil
+	}
+
+	srv := &http.Server{
+		Handler:           s,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.slogger.Log(ctx, slog.LevelWarn,
+				"LAN cache server stopped unexpectedly",
+				"err", err,
+			)
+		}
+	}()
+
+	s.slogger.Log(ctx, slog.LevelInfo,
+		"serving LAN cache",
+		"address", listener.Addr().String(),
+	)
+
+	return srv
+}
+
+func (s *LanCacheServer) shutdown(ctx context.Context, srv *http.Server) {
+	if srv == nil {
+		return
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		s.slogger.Log(ctx, slog.LevelWarn,
+			"could not shut down LAN cache server",
+			"err", err,
+		)
+	}
+}
+
+// ServeHTTP serves a retained update archive at the same path the mirror would serve it at.
+func (s *LanCacheServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	secret, err := s.knapsack.ReadEnrollSecret()
+	if err != nil || secret == "" {
+		s.slogger.Log(r.Context(), slog.LevelDebug,
+			"cannot authenticate LAN cache request without enroll secret",
+			"err", err,
+		)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	if err := verifyLanCacheRequest(r, secret, time.Now()); err != nil {
+		s.slogger.Log(r.Context(), slog.LevelDebug,
+			"rejecting unauthenticated LAN cache request",
+			"remote_addr", r.RemoteAddr,
+			"err", err,
+		)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	// Path is /kolide/<binary>/<os>/<arch>/<target filename>; we only have archives for our own platform.
+	pathParts := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if len(pathParts) != 5 || pathParts[0] != "kolide" || pathParts[2] != runtime.GOOS || pathParts[3] != PlatformArch() {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	binary, ok := autoupdatableBinaryMap[pathParts[1]]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	targetFilename := pathParts[4]
+	if filepath.Base(targetFilename) != targetFilename || !strings.HasPrefix(targetFilename, string(binary)+"-") {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if isBadVersion(s.updateDirectory, binary, versionFromTarget(binary, targetFilename)) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	archive, err := os.Open(filepath.Join(lanCacheDirectory(binary, s.updateDirectory), targetFilename))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	defer archive.Close()
+
+	archiveInfo, err := archive.Stat()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	s.slogger.Log(r.Context(), slog.LevelDebug,
+		"serving target to LAN cache peer",
+		"remote_addr", r.RemoteAddr,
+		"target", targetFilename,
+	)
+
+	http.ServeContent(w, r, targetFilename, archiveInfo.ModTime(), archive)
+}
@@ -0,0 +1,219 @@
+package tuf
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kolide/launcher/v2/ee/agent/flags/keys"
+	typesmocks "github.com/kolide/launcher/v2/ee/agent/types/mocks"
+	"github.com/kolide/launcher/v2/pkg/log/multislogger"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	tufutil "github.com/theupdateframework/go-tuf/util"
+)
+
+type testLanCacheSettings struct {
+	listenAddress string
+	peers         string
+	secret        string
+}
+
+func (s *testLanCacheSettings) LanCacheListenAddress() string {
+	return s.listenAddress
+}
+
+func (s *testLanCacheSettings) LanCachePeers() string {
+	return s.peers
+}
+
+func (s *testLanCacheSettings) ReadEnrollSecret() (string, error) {
+	return s.secret, nil
+}
+
+func Test_lanCachePeers(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []string{}, lanCachePeers(&testLanCacheSettings{peers: ""}))
+	require.Equal(t,
+		[]string{"http://192.168.1.5:8947", "https://cache.example.com"},
+		lanCachePeers(&testLanCacheSettings{peers: " 192.168.1.5:8947, ,https://cache.example.com/"}),
+	)
+}
+
+func Test_verifyLanCacheRequest(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	req := httptest.NewRequest(http.MethodGet, "/kolide/osqueryd/linux/amd64/osqueryd-5.20.0.tar.gz", nil)
+	signLanCacheRequest(req, "test-secret", now)
+
+	require.NoError(t, verifyLanCacheRequest(req, "test-secret", now))
+	require.NoError(t, verifyLanCacheRequest(req, "test-secret", now.Add(1*time.Minute)))
+	require.Error(t, verifyLanCacheRequest(req, "other-secret", now), "expected error with different secret")
+	require.Error(t, verifyLanCacheRequest(req, "test-secret", now.Add(2*lanCacheMaxClockSkew)), "expected error for stale request")
+
+	// A signature for one target cannot be used for another
+	otherReq := httptest.NewRequest(http.MethodGet, "/kolide/osqueryd/linux/amd64/osqueryd-5.21.0.tar.gz", nil)
+	otherReq.Header = req.Header.Clone()
+	require.Error(t, verifyLanCacheRequest(otherReq, "test-secret", now))
+}
+
+func TestLanCacheServer_ServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	updateDir := t.TempDir()
+	mockKnapsack := typesmocks.NewKnapsack(t)
+	mockKnapsack.On("Slogger").Return(multislogger.NewNopLogger())
+	mockKnapsack.On("UpdateDirectory").Return(updateDir)
+	mockKnapsack.On("RegisterChangeObserver", mock.Anything, keys.LanCacheListenAddress).Return()
+	mockKnapsack.On("ReadEnrollSecret").Return("test-secret", nil)
+
+	s := NewLanCacheServer(mockKnapsack)
+
+	// Add a couple archives to the cache
+	archiveContents := []byte("test archive")
+	require.NoError(t, os.MkdirAll(lanCacheDirectory(binaryOsqueryd, updateDir), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(lanCacheDirectory(binaryOsqueryd, updateDir), "osqueryd-5.20.0.tar.gz"), archiveContents, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(lanCacheDirectory(binaryOsqueryd, updateDir), "osqueryd-5.19.0.tar.gz"), archiveContents, 0644))
+	require.NoError(t, markBadVersion(updateDir, binaryOsqueryd, "5.19.0"))
+
+	for _, tt := range []struct {
+		name           string
+		path           string
+		sign           bool
+		expectedStatus int
+	}{
+		{
+			name:           "valid request",
+			path:           targetDownloadPath(binaryOsqueryd, "osqueryd-5.20.0.tar.gz"),
+			sign:           true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "unauthenticated request",
+			path:           targetDownloadPath(binaryOsqueryd, "osqueryd-5.20.0.tar.gz"),
+			sign:           false,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "target not in cache",
+			path:           targetDownloadPath(binaryOsqueryd, "osqueryd-5.21.0.tar.gz"),
+			sign:           true,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "bad version",
+			path:           targetDownloadPath(binaryOsqueryd, "osqueryd-5.19.0.tar.gz"),
+			sign:           true,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "other platform",
+			path:           "/kolide/osqueryd/plan9/amd64/osqueryd-5.20.0.tar.gz",
+			sign:           true,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "path traversal",
+			path:           "/kolide/osqueryd/" + targetDownloadPath(binaryOsqueryd, "..%2F..%2Fbad_versions.json"),
+			sign:           true,
+			expectedStatus: http.StatusNotFound,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.sign {
+				signLanCacheRequest(req, "test-secret", time.Now())
+			}
+			rr := httptest.NewRecorder()
+			s.ServeHTTP(rr, req)
+
+			require.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus == http.StatusOK {
+				require.Equal(t, archiveContents, rr.Body.Bytes())
+			}
+		})
+	}
+}
+
+func Test_downloadFromPeers(t *testing.T) {
+	t.Parallel()
+
+	targetFilename := "osqueryd-5.20.0.tar.gz"
+	archiveContents := []byte("test archive")
+	targetMeta, err := tufutil.GenerateTargetFileMeta(bytes.NewReader(archiveContents), "sha256")
+	require.NoError(t, err)
+
+	// Set up one peer that serves a tampered archive, and one that serves the real archive
+	tamperedPeer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("evil archive"))
+	}))
+	t.Cleanup(tamperedPeer.Close)
+
+	updateDir := t.TempDir()
+	mockKnapsack := typesmocks.NewKnapsack(t)
+	mockKnapsack.On("Slogger").Return(multislogger.NewNopLogger())
+	mockKnapsack.On("UpdateDirectory").Return(updateDir)
+	mockKnapsack.On("RegisterChangeObserver", mock.Anything, keys.LanCacheListenAddress).Return()
+	mockKnapsack.On("ReadEnrollSecret").Return("test-secret", nil)
+	require.NoError(t, os.MkdirAll(lanCacheDirectory(binaryOsqueryd, updateDir), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(lanCacheDirectory(binaryOsqueryd, updateDir), targetFilename), archiveContents, 0644))
+	goodPeer := httptest.NewServer(NewLanCacheServer(mockKnapsack))
+	t.Cleanup(goodPeer.Close)
+
+	settings := &testLanCacheSettings{
+		peers:  tamperedPeer.URL + "," + goodPeer.URL,
+		secret: "test-secret",
+	}
+	ulm, err := newUpdateLibraryManager("", http.DefaultClient, t.TempDir(), multislogger.NewNopLogger(), withLanCache(settings))
+	require.NoError(t, err)
+
+	// We should skip the tampered archive and download from the good peer
+	partialPath := filepath.Join(t.TempDir(), targetFilename+partialDownloadSuffix)
+	require.True(t, ulm.downloadFromPeers(t.Context(), binaryOsqueryd, targetFilename, targetMeta, nil, partialPath))
+	downloaded, err := os.ReadFile(partialPath)
+	require.NoError(t, err)
+	require.Equal(t, archiveContents, downloaded)
+
+	// With the wrong secret, the good peer will reject us too
+	settings.secret = "other-secret"
+	require.False(t, ulm.downloadFromPeers(t.Context(), binaryOsqueryd, targetFilename, targetMeta, nil, filepath.Join(t.TempDir(), targetFilename+partialDownloadSuffix)))
+}
+
+func Test_tidyLanCache(t *testing.T) {
+	t.Parallel()
+
+	baseDir := t.TempDir()
+	settings := &testLanCacheSettings{listenAddress: "127.0.0.1:0"}
+	ulm, err := newUpdateLibraryManager("", http.DefaultClient, baseDir, multislogger.NewNopLogger(), withLanCache(settings))
+	require.NoError(t, err)
+
+	// Set up the cache with archives for a version in the library, a version no longer in the library, and a bad version
+	cacheDir := lanCacheDirectory(binaryLauncher, baseDir)
+	require.NoError(t, os.MkdirAll(cacheDir, 0755))
+	for _, v := range []string{"1.20.0", "1.19.0", "1.21.0"} {
+		require.NoError(t, os.WriteFile(filepath.Join(cacheDir, "launcher-"+v+".tar.gz"), []byte("test"), 0644))
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(updatesDirectory(binaryLauncher, baseDir), "1.20.0"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(updatesDirectory(binaryLauncher, baseDir), "1.21.0"), 0755))
+	require.NoError(t, markBadVersion(baseDir, binaryLauncher, "1.21.0"))
+
+	ulm.tidyLanCache(binaryLauncher)
+
+	remaining, err := os.ReadDir(cacheDir)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(remaining))
+	require.Equal(t, "launcher-1.20.0.tar.gz", remaining[0].Name())
+
+	// Once we stop serving, the cache should be removed entirely
+	settings.listenAddress = ""
+	ulm.tidyLanCache(binaryLauncher)
+	require.NoDirExists(t, cacheDir)
+}
@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/kolide/launcher/v2/ee/agent/flags/keys"
@@ -261,6 +262,9 @@ func findExecutable(ctx context.Context, binary autoupdatableBinary, tufReposito
 	}
 
 	targetPath, targetVersion := pathToTargetVersionExecutable(binary, targetName, baseUpdateDirectory)
+	if isBadVersion(baseUpdateDirectory, binary, targetVersion) {
+		return nil, fmt.Errorf("version %s from target %s was marked bad after failing post-update probation", targetVersion, targetName)
+	}
 	if _, err := os.Stat(targetPath); err != nil && errors.Is(err, os.ErrNotExist) {
 		observability.SetError(span, err)
 		return nil, fmt.Errorf("version %s from target %s at %s is either originally installed version or not yet downloaded", targetVersion, targetName, targetPath)
@@ -288,6 +292,18 @@ func mostRecentVersion(ctx context.Context, slogger *slog.Logger, binary autoupd
 		return nil, fmt.Errorf("could not get sorted versions in library for %s: %w", binary, err)
 	}
 
+	// Don't select any versions that failed post-update probation
+	badVersions, err := readBadVersions(baseUpdateDirectory)
+	if err != nil {
+		slogger.Log(ctx, slog.LevelWarn,
+			"could not read bad versions, proceeding without excluding them",
+			"err", err,
+		)
+	}
+	validVersionsInLibrary = slices.DeleteFunc(validVersionsInLibrary, func(v string) bool {
+		return slices.Contains(badVersions[binary], v)
+	})
+
 	// No valid versions in the library
 	if len(validVersionsInLibrary) < 1 {
 		return nil, fmt.Errorf("no versions of %s in library at %s", binary, baseUpdateDirectory)
@@ -2,7 +2,6 @@ package tuf
 
 import (
 	"archive/tar"
-	"bytes"
 	"compress/gzip"
 	"context"
 	"errors"
@@ -12,9 +11,8 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
-	"path"
 	"path/filepath"
-	"runtime"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -23,7 +21,7 @@ import (
 	"github.com/kolide/launcher/v2/ee/observability"
 	"github.com/kolide/launcher/v2/pkg/backoff"
 	"github.com/theupdateframework/go-tuf/data"
-	tufutil "github.com/theupdateframework/go-tuf/util"
+	"golang.org/x/time/rate"
 )
 
 // updateLibraryManager manages the update libraries for launcher and osquery.
@@ -36,9 +34,31 @@ type updateLibraryManager struct {
 	baseDir      string
 	lock         *libraryLock
 	slogger      *slog.Logger
+	lanCache     lanCacheSettings // if set, retains verified archives for LAN cache peers and downloads from them
+	peerClient   *http.Client
+	// bandwidthLimitKB returns the current download rate limit in KB per second; 0 is unlimited
+	bandwidthLimitKB func() int
 }
 
-func newUpdateLibraryManager(mirrorUrl string, mirrorClient *http.Client, baseDir string, slogger *slog.Logger) (*updateLibraryManager, error) {
+type updateLibraryManagerOption func(*updateLibraryManager)
+
+// withLanCache allows the library manager to download targets from LAN cache peers, and to retain
+// verified archives so that they can be served to peers.
+func withLanCache(settings lanCacheSettings) updateLibraryManagerOption {
+	return func(ulm *updateLibraryManager) {
+		ulm.lanCache = settings
+		ulm.peerClient = &http.Client{Timeout: lanCachePeerTimeout}
+	}
+}
+
+// withBandwidthLimit limits the rate at which the library manager downloads updates.
+func withBandwidthLimit(limitKB func() int) updateLibraryManagerOption {
+	return func(ulm *updateLibraryManager) {
+		ulm.bandwidthLimitKB = limitKB
+	}
+}
+
+func newUpdateLibraryManager(mirrorUrl string, mirrorClient *http.Client, baseDir string, slogger *slog.Logger, opts ...updateLibraryManagerOption) (*updateLibraryManager, error) {
 	ulm := updateLibraryManager{
 		mirrorUrl:    mirrorUrl,
 		mirrorClient: mirrorClient,
@@ -47,6 +67,10 @@ func newUpdateLibraryManager(mirrorUrl string, mirrorClient *http.Client, baseDi
 		slogger:      slogger.With("component", "tuf_autoupdater_library_manager"),
 	}
 
+	for _, opt := range opts {
+		opt(&ulm)
+	}
+
 	// Ensure the updates directory exists
 	if err := os.MkdirAll(baseDir, 0755); err != nil {
 		return nil, fmt.Errorf("could not make base directory for updates library: %w", err)
@@ -125,17 +149,31 @@ func (ulm *updateLibraryManager) AddToLibrary(binary autoupdatableBinary, curren
 		return fmt.Errorf("could not move verified update: %w", err)
 	}
 
+	ulm.retainForLanCache(binary, targetFilename, stagedUpdatePath)
+
 	return nil
 }
 
 // stageAndVerifyUpdate downloads the update indicated by `targetFilename` and verifies it against
-// the given, validated local metadata.
+// the given, validated local metadata. Downloads are staged in a directory that persists across attempts,
+// so that an interrupted download resumes where it left off on the next attempt; in that case,
+// stageAndVerifyUpdate returns an empty staged update path so that the caller retains the staging directory.
 func (ulm *updateLibraryManager) stageAndVerifyUpdate(binary autoupdatableBinary, targetFilename string, localTargetMetadata data.TargetFileMeta) (string, error) {
-	stagingDir, err := ulm.tempDir(binary, fmt.Sprintf("staged-updates-%s", versionFromTarget(binary, targetFilename)))
-	if err != nil {
-		return "", fmt.Errorf("could not create temporary directory for downloading target: %w", err)
+	stagingDir := stagingDirectory(binary, ulm.baseDir, versionFromTarget(binary, targetFilename))
+	if err := os.MkdirAll(stagingDir, 0755); err != nil {
+		return "", fmt.Errorf("could not create staging directory for downloading target: %w", err)
+	}
+	// Mark the staging directory as in use, so TidyLibrary knows it's worth keeping
+	now := time.Now()
+	if err := os.Chtimes(stagingDir, now, now); err != nil {
+		ulm.slogger.Log(context.TODO(), slog.LevelDebug,
+			"could not update staging directory modification time",
+			"directory", stagingDir,
+			"err", err,
+		)
 	}
 	stagedUpdatePath := filepath.Join(stagingDir, targetFilename)
+	partialPath := stagedUpdatePath + partialDownloadSuffix
 
 	// Ensure we set a timeout on our request to download the binary
 	timeout := ulm.mirrorClient.Timeout
@@ -146,51 +184,144 @@ func (ulm *updateLibraryManager) stageAndVerifyUpdate(binary autoupdatableBinary
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	// Request download from mirror
-	downloadPath := path.Join("/", "kolide", string(binary), runtime.GOOS, PlatformArch(), targetFilename)
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ulm.mirrorUrl+downloadPath, nil)
-	if err != nil {
-		return stagedUpdatePath, fmt.Errorf("creating request to download target %s: %w", targetFilename, err)
+	var limiter *rate.Limiter
+	if ulm.bandwidthLimitKB != nil {
+		limiter = newDownloadLimiter(ulm.bandwidthLimitKB())
 	}
-	resp, err := ulm.mirrorClient.Do(req)
-	if err != nil {
-		return stagedUpdatePath, fmt.Errorf("could not make request to download target %s: %w", targetFilename, err)
+
+	// Try LAN cache peers first, falling back to the mirror
+	if !ulm.downloadFromPeers(ctx, binary, targetFilename, localTargetMetadata, limiter, partialPath) {
+		if err := downloadResumable(ctx, ulm.mirrorClient, ulm.mirrorUrl+targetDownloadPath(binary, targetFilename), nil, limiter, partialPath, localTargetMetadata); err != nil {
+			if errors.Is(err, errDownloadIncomplete) {
+				ulm.slogger.Log(ctx, slog.LevelInfo,
+					"download of target incomplete, will resume on next attempt",
+					"target", targetFilename,
+					"err", err,
+				)
+				return "", fmt.Errorf("downloading target %s: %w", targetFilename, err)
+			}
+			return stagedUpdatePath, fmt.Errorf("downloading target %s: %w", targetFilename, err)
+		}
 	}
-	defer resp.Body.Close()
 
-	// Wrap the download in a LimitReader so we read at most localMeta.Length bytes
-	stream := io.LimitReader(resp.Body, localTargetMetadata.Length)
-	var fileBuffer bytes.Buffer
+	// Everything looks good: move the verified download into place for untarring.
+	if err := os.Rename(partialPath, stagedUpdatePath); err != nil {
+		return stagedUpdatePath, fmt.Errorf("could not move downloaded target %s to %s: %w", targetFilename, stagedUpdatePath, err)
+	}
+
+	return stagedUpdatePath, nil
+}
 
-	// Read the target file, simultaneously writing it to our file buffer and generating its metadata
-	actualTargetMeta, err := tufutil.GenerateTargetFileMeta(io.TeeReader(stream, io.Writer(&fileBuffer)), localTargetMetadata.HashAlgorithms()...)
+// downloadFromPeers attempts to download the target from each LAN cache peer in turn, returning true
+// once a download passes verification.
+func (ulm *updateLibraryManager) downloadFromPeers(ctx context.Context, binary autoupdatableBinary, targetFilename string, localTargetMetadata data.TargetFileMeta, limiter *rate.Limiter, partialPath string) bool {
+	if ulm.lanCache == nil {
+		return false
+	}
+	peers := lanCachePeers(ulm.lanCache)
+	if len(peers) == 0 {
+		return false
+	}
+
+	secret, err := ulm.lanCache.ReadEnrollSecret()
 	if err != nil {
-		return stagedUpdatePath, fmt.Errorf("could not write downloaded target %s to file %s and compute its metadata: %w", targetFilename, stagedUpdatePath, err)
+		ulm.slogger.Log(ctx, slog.LevelWarn,
+			"could not read enroll secret to authenticate to LAN cache peers",
+			"err", err,
+		)
+		return false
+	}
+	signRequest := func(req *http.Request) {
+		signLanCacheRequest(req, secret, time.Now())
 	}
 
-	// Verify the actual download against the confirmed local metadata
-	if err := tufutil.TargetFileMetaEqual(actualTargetMeta, localTargetMetadata); err != nil {
-		return stagedUpdatePath, fmt.Errorf("verification failed for target %s staged at %s: %w", targetFilename, stagedUpdatePath, err)
+	for _, peer := range peers {
+		if err := downloadResumable(ctx, ulm.peerClient, peer+targetDownloadPath(binary, targetFilename), signRequest, limiter, partialPath, localTargetMetadata); err != nil {
+			ulm.slogger.Log(ctx, slog.LevelDebug,
+				"could not download target from LAN cache peer",
+				"peer", peer,
+				"target", targetFilename,
+				"err", err,
+			)
+			continue
+		}
+
+		ulm.slogger.Log(ctx, slog.LevelInfo,
+			"downloaded target from LAN cache peer",
+			"peer", peer,
+			"target", targetFilename,
+		)
+		return true
 	}
 
-	// Everything looks good: create the file and write it to disk.
-	// We create the file with 0655 permissions to prevent any other user from writing to this file
-	// before we can copy to it.
-	out, err := os.OpenFile(stagedUpdatePath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0655)
-	if err != nil {
-		return "", fmt.Errorf("could not create file at %s: %w", stagedUpdatePath, err)
+	ulm.slogger.Log(ctx, slog.LevelInfo,
+		"no LAN cache peer could provide target, downloading from mirror",
+		"peer_count", len(peers),
+		"target", targetFilename,
+	)
+	return false
+}
+
+// retainForLanCache moves the verified, staged archive into the LAN cache so that it can be served to
+// peers, if this launcher is serving a LAN cache.
+func (ulm *updateLibraryManager) retainForLanCache(binary autoupdatableBinary, targetFilename string, stagedUpdatePath string) {
+	if ulm.lanCache == nil || ulm.lanCache.LanCacheListenAddress() == "" {
+		return
+	}
+
+	cacheDir := lanCacheDirectory(binary, ulm.baseDir)
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		ulm.slogger.Log(context.TODO(), slog.LevelWarn,
+			"could not create LAN cache directory",
+			"directory", cacheDir,
+			"err", err,
+		)
+		return
+	}
+
+	if err := os.Rename(stagedUpdatePath, filepath.Join(cacheDir, targetFilename)); err != nil {
+		ulm.slogger.Log(context.TODO(), slog.LevelWarn,
+			"could not retain archive for LAN cache",
+			"target", targetFilename,
+			"err", err,
+		)
 	}
-	if _, err := io.Copy(out, &fileBuffer); err != nil {
-		if err := out.Close(); err != nil {
-			return stagedUpdatePath, fmt.Errorf("could not write downloaded target %s to file %s and could not close file: %w", targetFilename, stagedUpdatePath, err)
+}
+
+// tidyLanCache removes retained archives that are no longer in the update library, or all retained
+// archives if this launcher is no longer serving a LAN cache.
+func (ulm *updateLibraryManager) tidyLanCache(binary autoupdatableBinary) {
+	cacheDir := lanCacheDirectory(binary, ulm.baseDir)
+	if ulm.lanCache == nil || ulm.lanCache.LanCacheListenAddress() == "" {
+		if err := os.RemoveAll(cacheDir); err != nil {
+			ulm.slogger.Log(context.TODO(), slog.LevelWarn,
+				"could not remove LAN cache",
+				"directory", cacheDir,
+				"err", err,
+			)
 		}
-		return stagedUpdatePath, fmt.Errorf("could not write downloaded target %s to file %s: %w", targetFilename, stagedUpdatePath, err)
+		return
 	}
-	if err := out.Close(); err != nil {
-		return stagedUpdatePath, fmt.Errorf("could not close downloaded target file %s after writing: %w", targetFilename, err)
+
+	archives, err := os.ReadDir(cacheDir)
+	if err != nil {
+		return
 	}
 
-	return stagedUpdatePath, nil
+	for _, archive := range archives {
+		archiveVersion := versionFromTarget(binary, archive.Name())
+		if _, err := os.Stat(filepath.Join(updatesDirectory(binary, ulm.baseDir), archiveVersion)); err == nil && !isBadVersion(ulm.baseDir, binary, archiveVersion) {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(cacheDir, archive.Name())); err != nil {
+			ulm.slogger.Log(context.TODO(), slog.LevelWarn,
+				"could not remove archive from LAN cache",
+				"archive", archive.Name(),
+				"err", err,
+			)
+		}
+	}
 }
 
 // tempDir creates a directory inside of the updates directory. It is the caller's responsibility to remove
@@ -374,6 +505,9 @@ func (ulm *updateLibraryManager) TidyLibrary(binary autoupdatableBinary, current
 	ulm.lock.Lock(binary)
 	defer ulm.lock.Unlock(binary)
 
+	// Once the library is tidied, remove any retained archives we no longer need
+	defer ulm.tidyLanCache(binary)
+
 	// Remove any updates we no longer need
 	if currentVersion == "" {
 		ulm.slogger.Log(context.TODO(), slog.LevelWarn,
@@ -396,6 +530,11 @@ func (ulm *updateLibraryManager) TidyLibrary(binary autoupdatableBinary, current
 	}
 
 	for _, invalidVersion := range invalidVersionsInLibrary {
+		// Keep staging directories with downloads that we may still resume
+		if isRecentStagingDirectory(binary, ulm.baseDir, invalidVersion) {
+			continue
+		}
+
 		ulm.slogger.Log(context.TODO(), slog.LevelWarn,
 			"updates library contains invalid version",
 			"library_path", invalidVersion,
@@ -405,6 +544,23 @@ func (ulm *updateLibraryManager) TidyLibrary(binary autoupdatableBinary, current
 		ulm.removeUpdate(binary, invalidVersion)
 	}
 
+	// Remove any versions that failed post-update probation, as long as we're not still running them
+	badVersions, err := readBadVersions(ulm.baseDir)
+	if err != nil {
+		ulm.slogger.Log(context.TODO(), slog.LevelWarn,
+			"could not read bad versions to tidy update library",
+			"binary", binary,
+			"err", err,
+		)
+	}
+	versionsInLibrary = slices.DeleteFunc(versionsInLibrary, func(v string) bool {
+		if v == currentVersion || !slices.Contains(badVersions[binary], v) {
+			return false
+		}
+		ulm.removeUpdate(binary, v)
+		return true
+	})
+
 	if len(versionsInLibrary) <= numberOfVersionsToKeep {
 		ulm.slogger.Log(context.TODO(), slog.LevelInfo,
 			"no need to tidy library",
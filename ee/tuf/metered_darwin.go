@@ -0,0 +1,11 @@
+//go:build darwin
+
+package tuf
+
+import "context"
+
+// isMeteredConnection always returns false on macOS: connection cost (e.g. a personal hotspot)
+// is only exposed via the Network framework, which we don't have access to here.
+func isMeteredConnection(_ context.Context) (bool, error) {
+	return false, nil
+}
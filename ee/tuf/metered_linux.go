@@ -0,0 +1,42 @@
+//go:build linux
+
+package tuf
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	networkManagerDestination     = "org.freedesktop.NetworkManager"
+	networkManagerObjectPath      = "/org/freedesktop/NetworkManager"
+	networkManagerMeteredProperty = "org.freedesktop.NetworkManager.Metered"
+
+	// See NMMetered: https://networkmanager.dev/docs/api/latest/nm-dbus-types.html#NMMetered
+	nmMeteredYes      uint32 = 1
+	nmMeteredGuessYes uint32 = 3
+)
+
+// isMeteredConnection asks NetworkManager whether the primary connection is metered. Devices without
+// NetworkManager are treated as unmetered.
+func isMeteredConnection(ctx context.Context) (bool, error) {
+	conn, err := dbus.ConnectSystemBus(dbus.WithContext(ctx))
+	if err != nil {
+		return false, fmt.Errorf("connecting to system bus: %w", err)
+	}
+	defer conn.Close()
+
+	metered, err := conn.Object(networkManagerDestination, networkManagerObjectPath).GetProperty(networkManagerMeteredProperty)
+	if err != nil {
+		return false, fmt.Errorf("getting NetworkManager metered property: %w", err)
+	}
+
+	meteredValue, ok := metered.Value().(uint32)
+	if !ok {
+		return false, fmt.Errorf("unexpected type %T for NetworkManager metered property", metered.Value())
+	}
+
+	return meteredValue == nmMeteredYes || meteredValue == nmMeteredGuessYes, nil
+}
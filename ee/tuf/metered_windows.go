@@ -0,0 +1,35 @@
+//go:build windows
+
+package tuf
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/kolide/launcher/v2/ee/allowedcmd"
+)
+
+// getConnectionCostCmd returns the NetworkCostType of the internet connection profile: Unknown, Unrestricted, Fixed, or Variable.
+const getConnectionCostCmd = `$p = [Windows.Networking.Connectivity.NetworkInformation,Windows.Networking.Connectivity,ContentType=WindowsRuntime]::GetInternetConnectionProfile(); if ($p) { $p.GetConnectionCost().NetworkCostType.ToString() } else { 'Unknown' }`
+
+// isMeteredConnection checks whether the current internet connection has a fixed or variable cost,
+// i.e. whether Windows considers it metered.
+func isMeteredConnection(ctx context.Context) (bool, error) {
+	cmd, err := allowedcmd.Powershell.Cmd(ctx, "-NoProfile", "-NonInteractive", "-Command", getConnectionCostCmd)
+	if err != nil {
+		return false, fmt.Errorf("creating powershell command: %w", err)
+	}
+
+	out, err := cmd.Output()
+	if err != nil {
+		return false, fmt.Errorf("getting connection cost: %w", err)
+	}
+
+	switch string(bytes.TrimSpace(out)) {
+	case "Fixed", "Variable":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
@@ -0,0 +1,438 @@
+package tuf
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/kolide/kit/version"
+	"github.com/kolide/launcher/v2/ee/agent/types"
+	"github.com/kolide/launcher/v2/ee/observability"
+)
+
+const (
+	probationPeriod        = 1 * time.Hour
+	probationCheckInterval = 1 * time.Minute
+
+	// Thresholds that, when breached during probation, trigger a rollback
+	maxProbationStarts               = 5                // launcher starts of the new version, i.e. the new version is crash-looping
+	maxConsecutiveOsqueryFailures    = 5                // consecutive failed osquery health checks
+	controlServerConnectivityTimeout = 30 * time.Minute // time since startup without a successful control server fetch
+
+	// Keys in the launcher history store
+	probationStateKey = "update_probation"
+	rollbackStateKey  = "update_rollback"
+
+	// rollbackMessageMethod is the method used to report rollbacks to the control server
+	rollbackMessageMethod = "autoupdate_rollback"
+)
+
+// probationState tracks the launcher version currently (or most recently) in probation.
+type probationState struct {
+	Version   string `json:"version"`
+	StartTime int64  `json:"start_time"`
+	Starts    int    `json:"starts"`
+	Completed bool   `json:"completed"`
+	// ReachedControlServer records whether this version has successfully fetched from the control server.
+	ReachedControlServer bool `json:"reached_control_server,omitempty"`
+	// PreviousVersionReachedControlServer records whether the version we updated from ever reached the
+	// control server on this device. If it didn't, we don't hold this version to a higher standard.
+	PreviousVersionReachedControlServer bool `json:"previous_version_reached_control_server,omitempty"`
+}
+
+// rollbackState records the most recent rollback, so that it can be reported to the control server
+// once connectivity is available, and so that we do not put the version we rolled back to on probation.
+type rollbackState struct {
+	Version  string `json:"version"`
+	Reason   string `json:"reason"`
+	Time     int64  `json:"time"`
+	Reported bool   `json:"reported"`
+}
+
+type (
+	// healthChecker is satisfied by the osquery runner
+	healthChecker interface {
+		Healthy() error
+	}
+
+	// controlServerConnection is satisfied by the control service
+	controlServerConnection interface {
+		LastSuccessfulFetch() time.Time
+		SendMessage(method string, params any) error
+	}
+)
+
+// UpdateProbation watches a newly-updated launcher version for a probation period after it starts up.
+// If the new version crash-loops, cannot keep osquery healthy, or cannot reach the control server,
+// UpdateProbation marks the version bad and exits with a LauncherReloadNeeded error so that launcher
+// restarts into the previous version.
+type UpdateProbation struct {
+	slogger                    *slog.Logger
+	knapsack                   types.Knapsack
+	store                      types.GetterSetter
+	updateDirectory            string
+	currentVersion             string
+	executablePath             string
+	processStartTime           time.Time
+	osqueryHealth              healthChecker
+	controlServer              controlServerConnection
+	inProbation                bool
+	consecutiveOsqueryFailures int
+	reachedControlServer       bool
+	interrupt                  chan struct{}
+	interrupted                atomic.Bool
+}
+
+type UpdateProbationOption func(*UpdateProbation)
+
+// WithOsqueryHealthChecker allows UpdateProbation to check osquery's health during probation.
+func WithOsqueryHealthChecker(h healthChecker) UpdateProbationOption {
+	return func(p *UpdateProbation) {
+		p.osqueryHealth = h
+	}
+}
+
+// WithControlServerConnection allows UpdateProbation to check control server connectivity during probation,
+// and to report rollbacks to the control server.
+func WithControlServerConnection(c controlServerConnection) UpdateProbationOption {
+	return func(p *UpdateProbation) {
+		p.controlServer = c
+	}
+}
+
+// NewUpdateProbation returns a new UpdateProbation, which stores its state in the given store.
+func NewUpdateProbation(k types.Knapsack, store types.GetterSetter, opts ...UpdateProbationOption) *UpdateProbation {
+	updateDirectory := k.UpdateDirectory()
+	if updateDirectory == "" {
+		updateDirectory = DefaultLibraryDirectory(k.RootDirectory())
+	}
+
+	executablePath, _ := os.Executable()
+
+	p := &UpdateProbation{
+		slogger:          k.Slogger().With("component", "update_probation"),
+		knapsack:         k,
+		store:            store,
+		updateDirectory:  updateDirectory,
+		currentVersion:   version.Version().Version,
+		executablePath:   executablePath,
+		processStartTime: time.Now(),
+		interrupt:        make(chan struct{}, 1),
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+func (p *UpdateProbation) Execute() error {
+	ctx := context.TODO()
+
+	if reason := p.begin(ctx, time.Now()); reason != "" {
+		return p.rollback(ctx, reason)
+	}
+
+	ticker := time.NewTicker(probationCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.interrupt:
+			return nil
+		case <-ticker.C:
+		}
+
+		p.reportRollback(ctx)
+		p.recordControlServerConnectivity(ctx)
+
+		if !p.inProbation {
+			continue
+		}
+
+		if reason := p.check(ctx, time.Now()); reason != "" {
+			return p.rollback(ctx, reason)
+		}
+	}
+}
+
+func (p *UpdateProbation) Interrupt(_ error) {
+	// Only perform shutdown tasks on first call to interrupt -- no need to repeat on potential extra calls.
+	if p.interrupted.Swap(true) {
+		return
+	}
+
+	p.interrupt <- struct{}{}
+}
+
+// begin determines whether the current launcher version should be on probation, updating the probation
+// state accordingly. It returns a reason for rollback if the current version has already breached the
+// threshold for starts during probation.
+func (p *UpdateProbation) begin(ctx context.Context, now time.Time) string {
+	var state probationState
+	if err := p.get(probationStateKey, &state); err != nil {
+		p.slogger.Log(ctx, slog.LevelWarn,
+			"could not read probation state",
+			"err", err,
+		)
+	}
+
+	if state.Version != p.currentVersion {
+		// This is the first time we're running this version -- it's on probation only if it's
+		// an update that we can roll back from, and not the version we just rolled back to.
+		newState := probationState{
+			Version:                             p.currentVersion,
+			StartTime:                           now.Unix(),
+			Starts:                              1,
+			Completed:                           !p.canRollBack() || p.isRollbackTarget(state.Version),
+			PreviousVersionReachedControlServer: state.ReachedControlServer,
+		}
+		p.set(ctx, probationStateKey, newState)
+
+		if newState.Completed {
+			return ""
+		}
+
+		p.inProbation = true
+		p.slogger.Log(ctx, slog.LevelInfo,
+			"beginning post-update probation",
+			"version", p.currentVersion,
+			"previous_version", state.Version,
+		)
+		return ""
+	}
+
+	if state.Completed {
+		return ""
+	}
+
+	if now.After(time.Unix(state.StartTime, 0).Add(probationPeriod)) {
+		p.complete(ctx, state)
+		return ""
+	}
+
+	state.Starts += 1
+	p.set(ctx, probationStateKey, state)
+	if state.Starts > maxProbationStarts {
+		return fmt.Sprintf("launcher started %d times during probation", state.Starts)
+	}
+
+	p.inProbation = true
+	return ""
+}
+
+// check performs health checks for the current version during probation, returning a reason for rollback
+// if any thresholds are breached. When the probation period ends, it marks probation complete.
+func (p *UpdateProbation) check(ctx context.Context, now time.Time) string {
+	var state probationState
+	if err := p.get(probationStateKey, &state); err != nil {
+		p.slogger.Log(ctx, slog.LevelWarn,
+			"could not read probation state",
+			"err", err,
+		)
+		return ""
+	}
+
+	if now.After(time.Unix(state.StartTime, 0).Add(probationPeriod)) {
+		p.complete(ctx, state)
+		return ""
+	}
+
+	if p.osqueryHealth != nil {
+		if err := p.osqueryHealth.Healthy(); err != nil {
+			p.consecutiveOsqueryFailures += 1
+			p.slogger.Log(ctx, slog.LevelWarn,
+				"osquery unhealthy during post-update probation",
+				"consecutive_failures", p.consecutiveOsqueryFailures,
+				"err", err,
+			)
+		} else {
+			p.consecutiveOsqueryFailures = 0
+		}
+
+		if p.consecutiveOsqueryFailures >= maxConsecutiveOsqueryFailures {
+			return fmt.Sprintf("osquery unhealthy for %d consecutive checks", p.consecutiveOsqueryFailures)
+		}
+	}
+
+	// Only hold the new version responsible for connectivity if the previous version had it on this device --
+	// otherwise, e.g. on a device that is only ever offline or behind a captive portal, we'd roll back a good update.
+	if p.controlServer != nil && state.PreviousVersionReachedControlServer && now.Sub(p.processStartTime) > controlServerConnectivityTimeout {
+		if p.controlServer.LastSuccessfulFetch().Before(p.processStartTime) {
+			return fmt.Sprintf("no successful control server fetch in %s", controlServerConnectivityTimeout)
+		}
+	}
+
+	return ""
+}
+
+// recordControlServerConnectivity records that the current version has reached the control server, once it
+// has, so that the next version's probation knows to expect connectivity.
+func (p *UpdateProbation) recordControlServerConnectivity(ctx context.Context) {
+	if p.controlServer == nil || p.reachedControlServer {
+		return
+	}
+
+	if p.controlServer.LastSuccessfulFetch().Before(p.processStartTime) {
+		return
+	}
+
+	var state probationState
+	if err := p.get(probationStateKey, &state); err != nil || state.Version != p.currentVersion {
+		return
+	}
+
+	p.reachedControlServer = true
+	if state.ReachedControlServer {
+		return
+	}
+
+	state.ReachedControlServer = true
+	p.set(ctx, probationStateKey, state)
+}
+
+// complete marks probation for the current version as successfully completed.
+func (p *UpdateProbation) complete(ctx context.Context, state probationState) {
+	state.Completed = true
+	p.set(ctx, probationStateKey, state)
+	p.inProbation = false
+
+	p.slogger.Log(ctx, slog.LevelInfo,
+		"completed post-update probation",
+		"version", state.Version,
+	)
+}
+
+// rollback marks the current version bad, records the rollback for reporting, and returns an error
+// indicating that launcher should reload to run the previous version.
+func (p *UpdateProbation) rollback(ctx context.Context, reason string) error {
+	ctx, span := observability.StartSpan(ctx, "version", p.currentVersion, "reason", reason)
+	defer span.End()
+
+	p.slogger.Log(ctx, slog.LevelError,
+		"launcher update failed post-update probation, rolling back",
+		"version", p.currentVersion,
+		"reason", reason,
+	)
+
+	if err := markBadVersion(p.updateDirectory, binaryLauncher, p.currentVersion); err != nil {
+		// Without marking the version bad, library lookup would select this version again -- don't restart.
+		observability.SetError(span, err)
+		p.slogger.Log(ctx, slog.LevelError,
+			"could not mark version bad, cannot roll back",
+			"err", err,
+		)
+		p.inProbation = false
+		return nil
+	}
+
+	observability.AutoupdateRollbackCounter.Add(ctx, 1)
+
+	p.set(ctx, probationStateKey, probationState{
+		Version:   p.currentVersion,
+		Completed: true,
+	})
+	p.set(ctx, rollbackStateKey, rollbackState{
+		Version: p.currentVersion,
+		Reason:  reason,
+		Time:    time.Now().Unix(),
+	})
+
+	// Attempt to report the rollback now; if this fails, the version we roll back to will report it.
+	p.reportRollback(ctx)
+
+	return NewLauncherRollbackNeededErr(p.currentVersion)
+}
+
+// reportRollback sends the most recent rollback to the control server, if it has not yet been reported.
+func (p *UpdateProbation) reportRollback(ctx context.Context) {
+	if p.controlServer == nil {
+		return
+	}
+
+	var rollback rollbackState
+	if err := p.get(rollbackStateKey, &rollback); err != nil || rollback.Version == "" || rollback.Reported {
+		return
+	}
+
+	if err := p.controlServer.SendMessage(rollbackMessageMethod, map[string]string{
+		"binary":  string(binaryLauncher),
+		"version": rollback.Version,
+		"reason":  rollback.Reason,
+		"time":    time.Unix(rollback.Time, 0).UTC().Format(time.RFC3339),
+	}); err != nil {
+		p.slogger.Log(ctx, slog.LevelDebug,
+			"could not report rollback to control server, will retry",
+			"err", err,
+		)
+		return
+	}
+
+	rollback.Reported = true
+	p.set(ctx, rollbackStateKey, rollback)
+}
+
+// canRollBack returns true if the current launcher executable was selected from the update library,
+// meaning that we can roll back by marking it bad.
+func (p *UpdateProbation) canRollBack() bool {
+	if p.knapsack.LocalDevelopmentPath() != "" || p.executablePath == "" {
+		return false
+	}
+
+	rel, err := filepath.Rel(updatesDirectory(binaryLauncher, p.updateDirectory), p.executablePath)
+	if err != nil {
+		return false
+	}
+	return !strings.HasPrefix(rel, "..")
+}
+
+// isRollbackTarget returns true if the given previous version was rolled back -- meaning that the
+// current version is the one we rolled back to.
+func (p *UpdateProbation) isRollbackTarget(previousVersion string) bool {
+	var rollback rollbackState
+	if err := p.get(rollbackStateKey, &rollback); err != nil {
+		return false
+	}
+	return rollback.Version != "" && rollback.Version == previousVersion
+}
+
+func (p *UpdateProbation) get(key string, v any) error {
+	raw, err := p.store.Get([]byte(key))
+	if err != nil {
+		return fmt.Errorf("getting %s: %w", key, err)
+	}
+	if raw == nil {
+		return nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("unmarshalling %s: %w", key, err)
+	}
+	return nil
+}
+
+func (p *UpdateProbation) set(ctx context.Context, key string, v any) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		p.slogger.Log(ctx, slog.LevelWarn,
+			"could not marshal probation data",
+			"key", key,
+			"err", err,
+		)
+		return
+	}
+
+	if err := p.store.Set([]byte(key), raw); err != nil {
+		p.slogger.Log(ctx, slog.LevelWarn,
+			"could not store probation data",
+			"key", key,
+			"err", err,
+		)
+	}
+}
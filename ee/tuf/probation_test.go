@@ -0,0 +1,273 @@
+package tuf
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kolide/launcher/v2/ee/agent/storage/inmemory"
+	typesmocks "github.com/kolide/launcher/v2/ee/agent/types/mocks"
+	"github.com/kolide/launcher/v2/pkg/log/multislogger"
+	"github.com/stretchr/testify/require"
+)
+
+type testHealthChecker struct {
+	err error
+}
+
+func (h *testHealthChecker) Healthy() error {
+	return h.err
+}
+
+type testControlServerConnection struct {
+	lastSuccessfulFetch time.Time
+	sendErr             error
+	sentMessages        []map[string]string
+}
+
+func (c *testControlServerConnection) LastSuccessfulFetch() time.Time {
+	return c.lastSuccessfulFetch
+}
+
+func (c *testControlServerConnection) SendMessage(method string, params any) error {
+	if c.sendErr != nil {
+		return c.sendErr
+	}
+	c.sentMessages = append(c.sentMessages, params.(map[string]string))
+	return nil
+}
+
+func setUpUpdateProbation(t *testing.T, currentVersion string, runningFromLibrary bool, opts ...UpdateProbationOption) *UpdateProbation {
+	updateDir := t.TempDir()
+
+	mockKnapsack := typesmocks.NewKnapsack(t)
+	mockKnapsack.On("Slogger").Return(multislogger.NewNopLogger())
+	mockKnapsack.On("UpdateDirectory").Return(updateDir)
+	mockKnapsack.On("LocalDevelopmentPath").Return("").Maybe()
+
+	p := NewUpdateProbation(mockKnapsack, inmemory.NewStore(), opts...)
+	p.currentVersion = currentVersion
+	p.executablePath = filepath.Join(t.TempDir(), "launcher")
+	if runningFromLibrary {
+		p.executablePath = executableLocation(filepath.Join(updatesDirectory(binaryLauncher, updateDir), currentVersion), binaryLauncher)
+	}
+
+	return p
+}
+
+func TestUpdateProbation_begin(t *testing.T) {
+	t.Parallel()
+
+	t.Run("new version from library is on probation", func(t *testing.T) {
+		t.Parallel()
+
+		p := setUpUpdateProbation(t, "1.20.0", true)
+		require.Equal(t, "", p.begin(t.Context(), time.Now()))
+		require.True(t, p.inProbation)
+	})
+
+	t.Run("new version not from library is not on probation", func(t *testing.T) {
+		t.Parallel()
+
+		p := setUpUpdateProbation(t, "1.20.0", false)
+		require.Equal(t, "", p.begin(t.Context(), time.Now()))
+		require.False(t, p.inProbation)
+	})
+
+	t.Run("version we rolled back to is not on probation", func(t *testing.T) {
+		t.Parallel()
+
+		p := setUpUpdateProbation(t, "1.19.0", true)
+		p.set(t.Context(), probationStateKey, probationState{Version: "1.20.0", Completed: true})
+		p.set(t.Context(), rollbackStateKey, rollbackState{Version: "1.20.0", Reason: "test"})
+
+		require.Equal(t, "", p.begin(t.Context(), time.Now()))
+		require.False(t, p.inProbation)
+	})
+
+	t.Run("repeated starts during probation trigger rollback", func(t *testing.T) {
+		t.Parallel()
+
+		p := setUpUpdateProbation(t, "1.20.0", true)
+		now := time.Now()
+		for i := 0; i < maxProbationStarts; i += 1 {
+			require.Equal(t, "", p.begin(t.Context(), now))
+			require.True(t, p.inProbation)
+		}
+		require.NotEqual(t, "", p.begin(t.Context(), now))
+	})
+
+	t.Run("probation completes after probation period", func(t *testing.T) {
+		t.Parallel()
+
+		p := setUpUpdateProbation(t, "1.20.0", true)
+		require.Equal(t, "", p.begin(t.Context(), time.Now().Add(-2*probationPeriod)))
+		require.Equal(t, "", p.begin(t.Context(), time.Now()))
+		require.False(t, p.inProbation)
+
+		var state probationState
+		require.NoError(t, p.get(probationStateKey, &state))
+		require.True(t, state.Completed)
+	})
+}
+
+func TestUpdateProbation_check(t *testing.T) {
+	t.Parallel()
+
+	t.Run("healthy", func(t *testing.T) {
+		t.Parallel()
+
+		controlServer := &testControlServerConnection{lastSuccessfulFetch: time.Now()}
+		p := setUpUpdateProbation(t, "1.20.0", true, WithOsqueryHealthChecker(&testHealthChecker{}), WithControlServerConnection(controlServer))
+		p.processStartTime = time.Now().Add(-2 * controlServerConnectivityTimeout)
+		require.Equal(t, "", p.begin(t.Context(), time.Now()))
+
+		for i := 0; i < maxConsecutiveOsqueryFailures+1; i += 1 {
+			require.Equal(t, "", p.check(t.Context(), time.Now()))
+		}
+		require.True(t, p.inProbation)
+	})
+
+	t.Run("osquery unhealthy", func(t *testing.T) {
+		t.Parallel()
+
+		osqueryHealth := &testHealthChecker{err: errors.New("test error")}
+		p := setUpUpdateProbation(t, "1.20.0", true, WithOsqueryHealthChecker(osqueryHealth))
+		require.Equal(t, "", p.begin(t.Context(), time.Now()))
+
+		for i := 0; i < maxConsecutiveOsqueryFailures-1; i += 1 {
+			require.Equal(t, "", p.check(t.Context(), time.Now()))
+		}
+
+		// A single healthy check should reset the count
+		osqueryHealth.err = nil
+		require.Equal(t, "", p.check(t.Context(), time.Now()))
+		osqueryHealth.err = errors.New("test error")
+		for i := 0; i < maxConsecutiveOsqueryFailures-1; i += 1 {
+			require.Equal(t, "", p.check(t.Context(), time.Now()))
+		}
+
+		require.NotEqual(t, "", p.check(t.Context(), time.Now()))
+	})
+
+	t.Run("no control server connectivity", func(t *testing.T) {
+		t.Parallel()
+
+		p := setUpUpdateProbation(t, "1.20.0", true, WithControlServerConnection(&testControlServerConnection{}))
+		p.set(t.Context(), probationStateKey, probationState{Version: "1.19.0", Completed: true, ReachedControlServer: true})
+		require.Equal(t, "", p.begin(t.Context(), time.Now()))
+
+		// Still within the grace period
+		require.Equal(t, "", p.check(t.Context(), time.Now()))
+
+		p.processStartTime = time.Now().Add(-2 * controlServerConnectivityTimeout)
+		require.NotEqual(t, "", p.check(t.Context(), time.Now()))
+	})
+
+	t.Run("no control server connectivity, and previous version never had it", func(t *testing.T) {
+		t.Parallel()
+
+		p := setUpUpdateProbation(t, "1.20.0", true, WithControlServerConnection(&testControlServerConnection{}))
+		p.set(t.Context(), probationStateKey, probationState{Version: "1.19.0", Completed: true})
+		require.Equal(t, "", p.begin(t.Context(), time.Now()))
+
+		p.processStartTime = time.Now().Add(-2 * controlServerConnectivityTimeout)
+		require.Equal(t, "", p.check(t.Context(), time.Now()))
+		require.True(t, p.inProbation)
+	})
+
+	t.Run("probation period ends", func(t *testing.T) {
+		t.Parallel()
+
+		p := setUpUpdateProbation(t, "1.20.0", true, WithOsqueryHealthChecker(&testHealthChecker{err: errors.New("test error")}))
+		require.Equal(t, "", p.begin(t.Context(), time.Now()))
+
+		require.Equal(t, "", p.check(t.Context(), time.Now().Add(2*probationPeriod)))
+		require.False(t, p.inProbation)
+	})
+}
+
+func TestUpdateProbation_recordControlServerConnectivity(t *testing.T) {
+	t.Parallel()
+
+	controlServer := &testControlServerConnection{}
+	p := setUpUpdateProbation(t, "1.19.0", false, WithControlServerConnection(controlServer))
+	require.Equal(t, "", p.begin(t.Context(), time.Now()))
+
+	// Nothing is recorded until a fetch succeeds
+	p.recordControlServerConnectivity(t.Context())
+	var state probationState
+	require.NoError(t, p.get(probationStateKey, &state))
+	require.False(t, state.ReachedControlServer)
+
+	controlServer.lastSuccessfulFetch = time.Now()
+	p.recordControlServerConnectivity(t.Context())
+	require.NoError(t, p.get(probationStateKey, &state))
+	require.True(t, state.ReachedControlServer)
+
+	// The next version's probation expects connectivity
+	next := setUpUpdateProbation(t, "1.20.0", true)
+	next.store = p.store
+	require.Equal(t, "", next.begin(t.Context(), time.Now()))
+	var nextState probationState
+	require.NoError(t, next.get(probationStateKey, &nextState))
+	require.Equal(t, "1.20.0", nextState.Version)
+	require.True(t, nextState.PreviousVersionReachedControlServer)
+	require.False(t, nextState.ReachedControlServer)
+}
+
+func TestUpdateProbation_rollback(t *testing.T) {
+	t.Parallel()
+
+	controlServer := &testControlServerConnection{sendErr: errors.New("test error")}
+	p := setUpUpdateProbation(t, "1.20.0", true, WithControlServerConnection(controlServer))
+	require.Equal(t, "", p.begin(t.Context(), time.Now()))
+
+	err := p.rollback(t.Context(), "test reason")
+	require.True(t, IsLauncherReloadNeededErr(err))
+	require.True(t, IsLauncherRollbackNeededErr(err))
+	require.False(t, IsLauncherRollbackNeededErr(NewLauncherReloadNeededErr("1.20.0")))
+	require.True(t, isBadVersion(p.updateDirectory, binaryLauncher, "1.20.0"))
+
+	// We couldn't report the rollback yet, so it should still be pending
+	var rollback rollbackState
+	require.NoError(t, p.get(rollbackStateKey, &rollback))
+	require.Equal(t, "1.20.0", rollback.Version)
+	require.Equal(t, "test reason", rollback.Reason)
+	require.False(t, rollback.Reported)
+
+	// Once the control server is reachable, we should report the rollback, exactly once
+	controlServer.sendErr = nil
+	p.reportRollback(t.Context())
+	p.reportRollback(t.Context())
+	require.Equal(t, 1, len(controlServer.sentMessages))
+	require.Equal(t, "1.20.0", controlServer.sentMessages[0]["version"])
+	require.Equal(t, "test reason", controlServer.sentMessages[0]["reason"])
+
+	rawRollback, err := p.store.Get([]byte(rollbackStateKey))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(rawRollback, &rollback))
+	require.True(t, rollback.Reported)
+}
+
+func Test_markBadVersion(t *testing.T) {
+	t.Parallel()
+
+	updateDir := t.TempDir()
+	require.False(t, isBadVersion(updateDir, binaryLauncher, "1.20.0"))
+
+	require.NoError(t, markBadVersion(updateDir, binaryLauncher, "1.20.0"))
+	require.NoError(t, markBadVersion(updateDir, binaryLauncher, "1.20.0"))
+	require.NoError(t, markBadVersion(updateDir, binaryOsqueryd, "5.20.0"))
+
+	require.True(t, isBadVersion(updateDir, binaryLauncher, "1.20.0"))
+	require.False(t, isBadVersion(updateDir, binaryLauncher, "1.19.0"))
+	require.False(t, isBadVersion(updateDir, binaryOsqueryd, "1.20.0"))
+
+	badVersions, err := readBadVersions(updateDir)
+	require.NoError(t, err)
+	require.Equal(t, []string{"1.20.0"}, badVersions[binaryLauncher])
+	require.Equal(t, []string{"5.20.0"}, badVersions[binaryOsqueryd])
+}
@@ -1,6 +1,6 @@
 // Package atomic provides convenience wrappers around the standard
 // library's sync/atomic primitives for the types it doesn't cover
-// directly: time.Duration and string.
+// directly: time.Duration, time.Time, and string.
 //
 // For atomic booleans, use sync/atomic.Bool from the standard library.
 package atomic
@@ -52,3 +52,28 @@ func (s *String) Load() string {
 
 // Store atomically stores val.
 func (s *String) Store(val string) { s.v.Store(val) }
+
+// Time is an atomic time.Time.
+//
+// The zero value is the zero time.
+type Time struct {
+	v atomic.Value
+}
+
+// NewTime returns a *Time initialized to t.
+func NewTime(t time.Time) *Time {
+	tm := &Time{}
+	tm.Store(t)
+	return tm
+}
+
+// Load atomically loads and returns the value.
+func (t *Time) Load() time.Time {
+	if v := t.v.Load(); v != nil {
+		return v.(time.Time)
+	}
+	return time.Time{}
+}
+
+// Store atomically stores val.
+func (t *Time) Store(val time.Time) { t.v.Store(val) }
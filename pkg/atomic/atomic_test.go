@@ -35,3 +35,18 @@ func TestString(t *testing.T) {
 	zero.Store("set")
 	require.Equal(t, "set", zero.Load())
 }
+
+func TestTime(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	tm := NewTime(now)
+	require.True(t, now.Equal(tm.Load()))
+
+	later := now.Add(1 * time.Hour)
+	tm.Store(later)
+	require.True(t, later.Equal(tm.Load()))
+
+	var zero Time
+	require.True(t, zero.Load().IsZero(), "zero value should be zero time")
+}
@@ -78,6 +78,8 @@ type Options struct {
 	UpdateDirectory string
 	// AutoupdateDownloadSplay duration of time over which launcher should select random delay before downloading
 	AutoupdateDownloadSplay time.Duration
+	// AutoupdateBandwidthLimitKB is the maximum rate, in KB per second, at which launcher will download updates. 0 is unlimited.
+	AutoupdateBandwidthLimitKB int
 
 	// Debug enables debug logging.
 	Debug bool
@@ -119,6 +121,21 @@ type Options struct {
 	TraceIngestServerURL string
 	// DisableTraceIngestTLS allows for disabling TLS when connecting to the observability ingest server
 	DisableTraceIngestTLS bool
+	// MetricsEndpointAddress is the localhost address (e.g. 127.0.0.1:9464) to serve launcher metrics on,
+	// in Prometheus text format. The endpoint is disabled if empty.
+	MetricsEndpointAddress string
+	// CustomerOTLPEndpoint is the host:port of a customer-operated OpenTelemetry collector to export traces, metrics, and logs to
+	CustomerOTLPEndpoint string
+	// CustomerOTLPHeaders are additional headers to send to the customer collector, as comma-separated key=value pairs
+	CustomerOTLPHeaders string
+	// CustomerOTLPCAPath is the path to a PEM file of CA certificates to verify the customer collector against
+	CustomerOTLPCAPath string
+	// DisableCustomerOTLPTLS disables TLS when connecting to the customer collector
+	DisableCustomerOTLPTLS bool
+	// CustomerOTLPSamplingRate is a number between 0.0 and 1.0 that indicates what fraction of traces should be sent to the customer collector
+	CustomerOTLPSamplingRate float64
+	// CustomerOTLPOnly exports traces and metrics only to the customer collector, and not to Kolide
+	CustomerOTLPOnly bool
 
 	// ConfigFilePath is the config file options were parsed from, if provided
 	ConfigFilePath string
@@ -224,6 +241,13 @@ func ParseOptions(subcommandName string, args []string) (*Options, error) {
 		flLogIngestServerURL              = flagset.String("log_ingest_url", "", "Where to export logs")
 		flTraceIngestServerURL            = flagset.String("trace_ingest_url", "", "Where to export traces")
 		flDisableIngestTLS                = flagset.Bool("disable_trace_ingest_tls", false, "Disable TLS for observability ingest server communication")
+		flMetricsEndpointAddress          = flagset.String("metrics_endpoint_address", "", "Localhost address (e.g. 127.0.0.1:9464) to serve launcher metrics on in Prometheus text format (default: disabled)")
+		flCustomerOTLPEndpoint            = flagset.String("customer_otlp_endpoint", "", "host:port of an OpenTelemetry collector to also export traces, metrics, and logs to (default: disabled)")
+		flCustomerOTLPHeaders             = flagset.String("customer_otlp_headers", "", "Comma-separated key=value headers to send to the customer OpenTelemetry collector")
+		flCustomerOTLPCAPath              = flagset.String("customer_otlp_ca_path", "", "Path to PEM file of CA certificates to verify the customer OpenTelemetry collector against")
+		flDisableCustomerOTLPTLS          = flagset.Bool("disable_customer_otlp_tls", false, "Disable TLS for customer OpenTelemetry collector communication")
+		flCustomerOTLPSamplingRate        = flagset.Float64("customer_otlp_sampling_rate", 0.1, "What fraction of traces should be exported to the customer OpenTelemetry collector")
+		flCustomerOTLPOnly                = flagset.Bool("customer_otlp_only", false, "Export traces and metrics only to the customer OpenTelemetry collector")
 		// Osquery log ingest configuration for dual publication cutover
 		flOsqueryPublisherURL            = flagset.String("osquery_publisher_url", "", "URL base for publishing osquery logs and status")
 		flOsqueryPublisherPercentEnabled = flagset.Int("osquery_publisher_percent_enabled", 0, "Percent of logs to publish to new ingest server. Default 0 is disabled.")
@@ -237,6 +261,7 @@ func ParseOptions(subcommandName string, args []string) (*Options, error) {
 		flAutoupdateInitialDelay  = flagset.Duration("autoupdater_initial_delay", 1*time.Hour, "Initial autoupdater subprocess delay")
 		flUpdateDirectory         = flagset.String("update_directory", "", "Local directory to hold updates for osqueryd and launcher")
 		flAutoupdateDownloadSplay = flagset.Duration("autoupdate_download_splay", 8*time.Hour, "duration of time over which launcher should select random delay before downloading")
+		flAutoupdateBandwidthKB   = flagset.Int("autoupdate_bandwidth_limit_kb", 0, "maximum rate, in KB per second, at which to download updates (default: 0, unlimited)")
 
 		// Development & Debugging options
 		flDebug                = flagset.Bool("debug", false, "Whether or not debug logging is enabled (default: false)")
@@ -389,6 +414,7 @@ func ParseOptions(subcommandName string, args []string) (*Options, error) {
 		AutoupdateInterval:              *flAutoupdateInterval,
 		AutoupdateInitialDelay:          *flAutoupdateInitialDelay,
 		AutoupdateDownloadSplay:         *flAutoupdateDownloadSplay,
+		AutoupdateBandwidthLimitKB:      *flAutoupdateBandwidthKB,
 		CertPins:                        certPins,
 		ConfigFilePath:                  *flConfigFilePath,
 		Control:                         false,
@@ -408,6 +434,13 @@ func ParseOptions(subcommandName string, args []string) (*Options, error) {
 		LocalDevelopmentPath:            *flLocalDevelopmentPath,
 		TraceIngestServerURL:            *flTraceIngestServerURL,
 		DisableTraceIngestTLS:           *flDisableIngestTLS,
+		MetricsEndpointAddress:          *flMetricsEndpointAddress,
+		CustomerOTLPEndpoint:            *flCustomerOTLPEndpoint,
+		CustomerOTLPHeaders:             *flCustomerOTLPHeaders,
+		CustomerOTLPCAPath:              *flCustomerOTLPCAPath,
+		DisableCustomerOTLPTLS:          *flDisableCustomerOTLPTLS,
+		CustomerOTLPSamplingRate:        *flCustomerOTLPSamplingRate,
+		CustomerOTLPOnly:                *flCustomerOTLPOnly,
 		IAmBreakingEELicense:            *flIAmBreakingEELicense,
 		InsecureTLS:                     *flInsecureTLS,
 		InsecureTransport:               *flInsecureTransport,
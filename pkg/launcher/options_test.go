@@ -345,6 +345,7 @@ func getArgsAndResponse() (map[string]string, *Options) {
 		LogIngestServerURL:              "",
 		LogMaxBytesPerBatch:             3 << 20,
 		DisableTraceIngestTLS:           false,
+		CustomerOTLPSamplingRate:        0.1,
 		KolideServerURL:                 randomHostname,
 		LoggingInterval:                 time.Duration(randomInt) * time.Second,
 		MirrorServerURL:                 "https://dl.kolide.co",
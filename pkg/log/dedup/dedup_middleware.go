@@ -1,6 +1,8 @@
 // Package dedup provides a stateful slog handler middleware that suppresses
-// bursts of duplicate log records and later emits a summarized record with
-// duplicate counts. See dedup_flow.mmd for a visual overview of the runtime behavior.
+// bursts of duplicate log records and periodically emits a summary record with
+// duplicate counts, so that callers can tell how much was suppressed. The window
+// may be overridden per component. See dedup_flow.mmd for a visual overview of
+// the runtime behavior.
 package dedup
 
 import (
@@ -8,12 +10,15 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"log/slog"
+	"maps"
 	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/metric/noop"
 )
@@ -25,6 +30,14 @@ const (
 	DefaultMaxCacheSize       = 2000
 	DefaultCleanupInterval    = 1 * time.Minute
 	DefaultDuplicateLogWindow = 0
+	DefaultSummaryInterval    = 1 * time.Minute
+
+	// fingerprintLength is the number of hex characters of the content hash included
+	// in summary records, enough to correlate summaries for the same record over time.
+	fingerprintLength = 16
+
+	componentKey     = "component"
+	unknownComponent = "unknown"
 
 	meterName = "github.com/kolide/launcher/v2/pkg/log/dedup"
 )
@@ -50,14 +63,14 @@ func ReinitializeMetrics() {
 	var err error
 
 	dedupSuppressedCounter, err = m.Int64Counter("launcher.dedup.suppressed",
-		metric.WithDescription("The number of log records suppressed by deduplication"),
+		metric.WithDescription("The number of log records suppressed by deduplication, by component"),
 		metric.WithUnit("{log}"))
 	if err != nil {
 		dedupSuppressedCounter = noop.Int64Counter{}
 	}
 
 	dedupPassedCounter, err = m.Int64Counter("launcher.dedup.passed",
-		metric.WithDescription("The number of log records passed through deduplication"),
+		metric.WithDescription("The number of log records passed through deduplication, by component"),
 		metric.WithUnit("{log}"))
 	if err != nil {
 		dedupPassedCounter = noop.Int64Counter{}
@@ -94,6 +107,9 @@ type Config struct {
 	MaxCacheSize       int
 	CleanupInterval    time.Duration
 	DuplicateLogWindow time.Duration
+	// SummaryInterval is the minimum time between summary records for a record that
+	// is still being suppressed; summaries are also never emitted more often than the window.
+	SummaryInterval time.Duration
 }
 
 type Option func(*Config)
@@ -104,11 +120,13 @@ func WithCleanupInterval(d time.Duration) Option { return func(c *Config) { c.Cl
 func WithDuplicateLogWindow(d time.Duration) Option {
 	return func(c *Config) { c.DuplicateLogWindow = d }
 }
+func WithSummaryInterval(d time.Duration) Option { return func(c *Config) { c.SummaryInterval = d } }
 
 type logEntry struct {
 	firstSeen time.Time
 	lastSeen  time.Time
 	count     int
+	component string
 
 	// Preserved for summary emission on cleanup
 	level   slog.Level
@@ -133,6 +151,8 @@ type Engine struct {
 
 	// Zero or negative disables dedup.
 	duplicateLogWindow atomic.Value // of type time.Duration
+	// Per-component overrides of duplicateLogWindow; zero or negative disables dedup for the component.
+	componentWindows atomic.Value // of type map[string]time.Duration
 }
 
 func New(opts ...Option) *Engine {
@@ -141,6 +161,7 @@ func New(opts ...Option) *Engine {
 		MaxCacheSize:       DefaultMaxCacheSize,
 		CleanupInterval:    DefaultCleanupInterval,
 		DuplicateLogWindow: DefaultDuplicateLogWindow,
+		SummaryInterval:    DefaultSummaryInterval,
 	}
 	for _, opt := range opts {
 		opt(&cfg)
@@ -159,7 +180,13 @@ func New(opts ...Option) *Engine {
 // but must be included in the hash so that logs from different handler chains
 // (e.g. different "component" values) are not incorrectly deduplicated together.
 func (d *Engine) handleRecord(ctx context.Context, record slog.Record, handlerAttrs []slog.Attr, next func(context.Context, slog.Record) error) error {
-	if !d.started.Load() || d.getDuplicateLogWindow() <= 0 {
+	if !d.started.Load() {
+		return next(ctx, record)
+	}
+
+	component := recordComponent(record, handlerAttrs)
+	window := d.windowFor(component)
+	if window <= 0 {
 		return next(ctx, record)
 	}
 
@@ -183,6 +210,7 @@ func (d *Engine) handleRecord(ctx context.Context, record slog.Record, handlerAt
 				firstSeen: now,
 				lastSeen:  now,
 				count:     1,
+				component: component,
 				level:     record.Level,
 				message:   record.Message,
 				attrs:     collectAttrs(record),
@@ -196,7 +224,7 @@ func (d *Engine) handleRecord(ctx context.Context, record slog.Record, handlerAt
 		entry.lastSeen = now
 		entry.count++
 		entry.next = nextFunc(next)
-		if now.Sub(entry.firstSeen) >= d.getDuplicateLogWindow() {
+		if now.Sub(entry.firstSeen) >= window {
 			duplicateCount = entry.count
 			firstSeen = entry.firstSeen
 			lastSeen = entry.lastSeen
@@ -211,12 +239,13 @@ func (d *Engine) handleRecord(ctx context.Context, record slog.Record, handlerAt
 		shouldPass = false
 	}()
 
+	metricAttrs := metric.WithAttributes(attribute.String(componentKey, metricComponent(component)))
 	if !shouldPass {
-		dedupSuppressedCounter.Add(ctx, 1)
+		dedupSuppressedCounter.Add(ctx, 1, metricAttrs)
 		return nil
 	}
 
-	dedupPassedCounter.Add(ctx, 1)
+	dedupPassedCounter.Add(ctx, 1, metricAttrs)
 
 	if addDuplicateMeta {
 		record.Add("duplicate_count", slog.IntValue(duplicateCount))
@@ -260,10 +289,43 @@ func (d *Engine) SetDuplicateLogWindow(window time.Duration) {
 	d.recordEnabledGauge()
 }
 
+// SetComponentWindows replaces the per-component window overrides, keyed by the
+// value of the record's "component" attribute. Components without an override use
+// the duplicate log window. A nil or empty map removes all overrides.
+func (d *Engine) SetComponentWindows(windows map[string]time.Duration) {
+	if d == nil {
+		return
+	}
+	d.componentWindows.Store(maps.Clone(windows))
+	d.recordEnabledGauge()
+}
+
+func (d *Engine) getComponentWindows() map[string]time.Duration {
+	if v := d.componentWindows.Load(); v != nil {
+		return v.(map[string]time.Duration)
+	}
+	return nil
+}
+
+// windowFor returns the duplicate log window that applies to the given component.
+func (d *Engine) windowFor(component string) time.Duration {
+	if window, ok := d.getComponentWindows()[component]; ok {
+		return window
+	}
+	return d.getDuplicateLogWindow()
+}
+
 func (d *Engine) recordEnabledGauge() {
 	var enabled int64
-	if d.started.Load() && d.getDuplicateLogWindow() > 0 {
-		enabled = 1
+	if d.started.Load() {
+		if d.getDuplicateLogWindow() > 0 {
+			enabled = 1
+		}
+		for _, window := range d.getComponentWindows() {
+			if window > 0 {
+				enabled = 1
+			}
+		}
 	}
 	dedupEnabledGauge.Record(context.Background(), enabled)
 }
@@ -302,8 +364,39 @@ func (d *Engine) periodicCleanupLoop(ctx context.Context) {
 	}
 }
 
-// performCleanup expires old entries and emits summary records for those with
-// duplicate counts. It also evicts the oldest entries when the cache exceeds
+// summary is a snapshot of a cache entry's suppressed duplicates, emitted as a summary record.
+type summary struct {
+	hash      string
+	level     slog.Level
+	message   string
+	attrs     []slog.Attr
+	count     int
+	component string
+	pc        uintptr
+	firstSeen time.Time
+	lastSeen  time.Time
+	next      nextFunc
+}
+
+func newSummary(hash string, entry *logEntry) summary {
+	return summary{
+		hash:      hash,
+		level:     entry.level,
+		message:   entry.message,
+		attrs:     append([]slog.Attr(nil), entry.attrs...),
+		count:     entry.count,
+		component: entry.component,
+		pc:        entry.pc,
+		firstSeen: entry.firstSeen,
+		lastSeen:  entry.lastSeen,
+		next:      entry.next,
+	}
+}
+
+// performCleanup emits summary records for entries with suppressed duplicates. A summary
+// is emitted when an entry's window and the summary interval have both elapsed, after which
+// the entry starts a new window, or when the entry expires or is evicted from the cache.
+// It also expires old entries, and evicts the oldest entries when the cache exceeds
 // MaxCacheSize. Emission happens outside the lock to avoid re-entrancy deadlocks.
 func (d *Engine) performCleanup() {
 	now := time.Now()
@@ -314,33 +407,21 @@ func (d *Engine) performCleanup() {
 
 	d.cacheLock.Lock()
 
-	type expired struct {
-		level     slog.Level
-		message   string
-		attrs     []slog.Attr
-		count     int
-		pc        uintptr
-		firstSeen time.Time
-		lastSeen  time.Time
-		next      nextFunc
-	}
-	var toEmit []expired
+	var toEmit []summary
 
 	for hash, entry := range d.cache {
 		if now.Sub(entry.lastSeen) <= d.cfg.CacheExpiry {
+			// Still active -- summarize the duplicates suppressed so far, rather than waiting
+			// for the next duplicate after the window or for the entry to expire.
+			if entry.count > 1 && now.Sub(entry.firstSeen) >= max(d.windowFor(entry.component), d.cfg.SummaryInterval) {
+				toEmit = append(toEmit, newSummary(hash, entry))
+				entry.firstSeen = now
+				entry.count = 1
+			}
 			continue
 		}
 		if entry.count > 1 {
-			toEmit = append(toEmit, expired{
-				level:     entry.level,
-				message:   entry.message,
-				attrs:     append([]slog.Attr(nil), entry.attrs...),
-				count:     entry.count,
-				pc:        entry.pc,
-				firstSeen: entry.firstSeen,
-				lastSeen:  entry.lastSeen,
-				next:      entry.next,
-			})
+			toEmit = append(toEmit, newSummary(hash, entry))
 		}
 		delete(d.cache, hash)
 	}
@@ -359,16 +440,7 @@ func (d *Engine) performCleanup() {
 		for i := range removeCount {
 			if entry, ok := d.cache[items[i].hash]; ok {
 				if entry.count > 1 {
-					toEmit = append(toEmit, expired{
-						level:     entry.level,
-						message:   entry.message,
-						attrs:     append([]slog.Attr(nil), entry.attrs...),
-						count:     entry.count,
-						pc:        entry.pc,
-						firstSeen: entry.firstSeen,
-						lastSeen:  entry.lastSeen,
-						next:      entry.next,
-					})
+					toEmit = append(toEmit, newSummary(items[i].hash, entry))
 				}
 				delete(d.cache, items[i].hash)
 			}
@@ -388,7 +460,10 @@ func (d *Engine) performCleanup() {
 			rec.AddAttrs(a)
 		}
 		rec.AddAttrs(
+			slog.Bool("dedup_summary", true),
+			slog.String("fingerprint", e.hash[:min(fingerprintLength, len(e.hash))]),
 			slog.Int("duplicate_count", e.count),
+			slog.Int("suppressed_count", e.count-1),
 			slog.String("original_msg", e.message),
 			slog.Time("first_seen", e.firstSeen),
 			slog.Time("last_seen", e.lastSeen),
@@ -399,6 +474,56 @@ func (d *Engine) performCleanup() {
 	}
 }
 
+// recordComponent returns the value of the "component" attribute for the record, preferring
+// record attrs over handler-chain attrs, and later attrs over earlier ones.
+func recordComponent(record slog.Record, handlerAttrs []slog.Attr) string {
+	var component string
+	for _, attr := range handlerAttrs {
+		if attr.Key == componentKey {
+			component = attr.Value.String()
+		}
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		if attr.Key == componentKey {
+			component = attr.Value.String()
+		}
+		return true
+	})
+	return component
+}
+
+func metricComponent(component string) string {
+	if component == "" {
+		return unknownComponent
+	}
+	return component
+}
+
+// ParseWindowOverrides parses per-component window overrides from comma-separated
+// component=duration pairs, e.g. "osquery_instance=5m,tablehelpers=0s".
+func ParseWindowOverrides(raw string) (map[string]time.Duration, error) {
+	overrides := make(map[string]time.Duration)
+	for _, pair := range strings.Split(raw, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		component, rawWindow, found := strings.Cut(pair, "=")
+		component = strings.TrimSpace(component)
+		if !found || component == "" {
+			return nil, fmt.Errorf("window override %q is not in component=duration format", pair)
+		}
+		window, err := time.ParseDuration(strings.TrimSpace(rawWindow))
+		if err != nil {
+			return nil, fmt.Errorf("parsing window override for component %s: %w", component, err)
+		}
+		if window < 0 {
+			return nil, fmt.Errorf("window override for component %s must not be negative", component)
+		}
+		overrides[component] = window
+	}
+	return overrides, nil
+}
+
 // hashRecordWithHandlerAttrs builds a stable content hash from the record and
 // any handler-chain attrs/groups. Handler-chain attrs (e.g. "component") are
 // added via slog.Logger.With() and invisible to slog.Record; including them
@@ -826,3 +826,194 @@ func TestHandlerEmissionsLackOriginalMsg(t *testing.T) {
 		t.Fatalf("handler emission should have last_seen")
 	}
 }
+
+// TestPeriodicSummaryForActiveEntry verifies that suppressed duplicates are summarized
+// once the window elapses, even if no further duplicate arrives and the entry has not expired.
+func TestPeriodicSummaryForActiveEntry(t *testing.T) {
+	t.Parallel()
+
+	capture := &captureHandler{}
+	engine := New(
+		WithDuplicateLogWindow(30*time.Millisecond),
+		WithSummaryInterval(30*time.Millisecond),
+		WithCleanupInterval(10*time.Millisecond),
+		WithCacheExpiry(10*time.Second),
+	)
+	engine.Start(t.Context())
+	defer engine.Stop()
+
+	handler := newTestHandler(engine, capture)
+	ctx := t.Context()
+
+	for range 4 {
+		if err := handler.Handle(ctx, makeRecord(slog.LevelWarn, "noisy", slog.String("component", "noisy_component"))); err != nil {
+			t.Fatalf("handle err: %v", err)
+		}
+	}
+
+	deadline := time.Now().Add(500 * time.Millisecond)
+	for time.Now().Before(deadline) && capture.Len() < 2 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if capture.Len() != 2 {
+		t.Fatalf("expected original record and one summary record, got %d", capture.Len())
+	}
+
+	summary := capture.Get(1)
+	if summary.Message != "noisy" {
+		t.Fatalf("expected message %q, got %q", "noisy", summary.Message)
+	}
+	if v, ok := getAttrValue(summary, "dedup_summary"); !ok || !v.Bool() {
+		t.Fatalf("expected dedup_summary attribute on summary record")
+	}
+	if v, ok := getAttrValue(summary, "suppressed_count"); !ok || v.Int64() != 3 {
+		t.Fatalf("expected suppressed_count 3, got %v", v)
+	}
+	if v, ok := getAttrValue(summary, "duplicate_count"); !ok || v.Int64() != 4 {
+		t.Fatalf("expected duplicate_count 4, got %v", v)
+	}
+	if v, ok := getAttrValue(summary, "fingerprint"); !ok || len(v.String()) != fingerprintLength {
+		t.Fatalf("expected fingerprint of length %d, got %q", fingerprintLength, v.String())
+	}
+	if v, ok := getAttrValue(summary, "component"); !ok || v.String() != "noisy_component" {
+		t.Fatalf("expected summary to include sample attributes, got component %q", v.String())
+	}
+
+	// The entry starts a new window after the summary, so no further summaries without new duplicates
+	time.Sleep(100 * time.Millisecond)
+	if capture.Len() != 2 {
+		t.Fatalf("expected no further summaries without new duplicates, got %d records", capture.Len())
+	}
+}
+
+func TestComponentWindowOverrides(t *testing.T) {
+	t.Parallel()
+
+	capture := &captureHandler{}
+	engine := New(
+		WithDuplicateLogWindow(10*time.Second),
+		WithCleanupInterval(1*time.Second),
+		WithCacheExpiry(10*time.Second),
+	)
+	engine.Start(t.Context())
+	defer engine.Stop()
+
+	mw := engine.NewMiddleware()
+	undeduped := mw(capture).WithAttrs([]slog.Attr{slog.String("component", "undeduped")})
+	shortWindow := mw(capture).WithAttrs([]slog.Attr{slog.String("component", "short_window")})
+	defaultWindow := mw(capture).WithAttrs([]slog.Attr{slog.String("component", "default_window")})
+
+	engine.SetComponentWindows(map[string]time.Duration{
+		"undeduped":    0,
+		"short_window": 30 * time.Millisecond,
+	})
+
+	ctx := t.Context()
+	for _, h := range []slog.Handler{undeduped, shortWindow, defaultWindow} {
+		for range 3 {
+			if err := h.Handle(ctx, makeRecord(slog.LevelInfo, "repeated")); err != nil {
+				t.Fatalf("handle err: %v", err)
+			}
+		}
+	}
+
+	// All 3 from the undeduped component, 1 each from the others
+	if capture.Len() != 5 {
+		t.Fatalf("expected 5 records to pass, got %d", capture.Len())
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	for _, h := range []slog.Handler{shortWindow, defaultWindow} {
+		if err := h.Handle(ctx, makeRecord(slog.LevelInfo, "repeated")); err != nil {
+			t.Fatalf("handle err: %v", err)
+		}
+	}
+
+	// Only the short window component has re-logged
+	if capture.Len() != 6 {
+		t.Fatalf("expected short window component to re-log after its window, got %d records", capture.Len())
+	}
+	if v, ok := getAttrValue(capture.Get(5), "duplicate_count"); !ok || v.Int64() != 4 {
+		t.Fatalf("expected duplicate_count 4 on short window re-log, got %v", v)
+	}
+
+	// Removing overrides restores the default window
+	engine.SetComponentWindows(nil)
+	if err := undeduped.Handle(ctx, makeRecord(slog.LevelInfo, "repeated")); err != nil {
+		t.Fatalf("handle err: %v", err)
+	}
+	if err := undeduped.Handle(ctx, makeRecord(slog.LevelInfo, "repeated")); err != nil {
+		t.Fatalf("handle err: %v", err)
+	}
+	if capture.Len() != 7 {
+		t.Fatalf("expected undeduped component to be deduplicated after overrides removed, got %d records", capture.Len())
+	}
+}
+
+func TestParseWindowOverrides(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name      string
+		raw       string
+		expected  map[string]time.Duration
+		expectErr bool
+	}{
+		{
+			name:     "empty",
+			raw:      "",
+			expected: map[string]time.Duration{},
+		},
+		{
+			name: "multiple components",
+			raw:  "osquery_instance=5m, tablehelpers = 0s,",
+			expected: map[string]time.Duration{
+				"osquery_instance": 5 * time.Minute,
+				"tablehelpers":     0,
+			},
+		},
+		{
+			name:      "missing duration",
+			raw:       "osquery_instance",
+			expectErr: true,
+		},
+		{
+			name:      "missing component",
+			raw:       "=5m",
+			expectErr: true,
+		},
+		{
+			name:      "invalid duration",
+			raw:       "osquery_instance=soon",
+			expectErr: true,
+		},
+		{
+			name:      "negative duration",
+			raw:       "osquery_instance=-1m",
+			expectErr: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			overrides, err := ParseWindowOverrides(tt.raw)
+			if tt.expectErr {
+				if err == nil {
+					t.Fatalf("expected error parsing %q", tt.raw)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error parsing %q: %v", tt.raw, err)
+			}
+			if len(overrides) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, overrides)
+			}
+			for component, window := range tt.expected {
+				if overrides[component] != window {
+					t.Fatalf("expected window %s for %s, got %s", window, component, overrides[component])
+				}
+			}
+		})
+	}
+}
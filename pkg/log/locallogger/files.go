@@ -0,0 +1,106 @@
+package locallogger
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// backupTimeFormat is the timestamp lumberjack uses when naming rotated log files,
+// e.g. debug-2024-01-02T15-04-05.000.json.gz for debug.json.
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// LogFiles returns the paths of the log file at logFilePath and its rotated backups that
+// currently exist, oldest first.
+func LogFiles(logFilePath string) ([]string, error) {
+	dir := filepath.Dir(logFilePath)
+	filename := filepath.Base(logFilePath)
+	ext := filepath.Ext(filename)
+	prefix := strings.TrimSuffix(filename, ext) + "-"
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading log directory %s: %w", dir, err)
+	}
+
+	// Backup names sort chronologically, since the timestamp is fixed-width and in UTC
+	backups := make([]string, 0)
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		name := e.Name()
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		timestamp := strings.TrimSuffix(strings.TrimSuffix(name, ".gz"), ext)
+		timestamp = strings.TrimPrefix(timestamp, prefix)
+		if len(timestamp) != len(backupTimeFormat) {
+			continue
+		}
+		backups = append(backups, name)
+	}
+	sort.Strings(backups)
+
+	// Backups are compressed in the background; while that's in progress, both versions exist,
+	// and the compressed one may be incomplete.
+	uncompressed := make(map[string]struct{})
+	for _, name := range backups {
+		if !strings.HasSuffix(name, ".gz") {
+			uncompressed[name] = struct{}{}
+		}
+	}
+	backups = slices.DeleteFunc(backups, func(name string) bool {
+		_, ok := uncompressed[strings.TrimSuffix(name, ".gz")]
+		return strings.HasSuffix(name, ".gz") && ok
+	})
+
+	files := make([]string, 0, len(backups)+1)
+	for _, b := range backups {
+		files = append(files, filepath.Join(dir, b))
+	}
+
+	if _, err := os.Stat(logFilePath); err == nil {
+		files = append(files, logFilePath)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("checking log file %s: %w", logFilePath, err)
+	}
+
+	return files, nil
+}
+
+// OpenLogFile opens a log file or rotated backup for reading, decompressing it if necessary.
+func OpenLogFile(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening log file: %w", err)
+	}
+
+	if !strings.HasSuffix(path, ".gz") {
+		return f, nil
+	}
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("decompressing log file %s: %w", path, err)
+	}
+
+	return &gzipFile{Reader: gz, f: f}, nil
+}
+
+type gzipFile struct {
+	*gzip.Reader
+	f *os.File
+}
+
+func (g *gzipFile) Close() error {
+	return errors.Join(g.Reader.Close(), g.f.Close())
+}
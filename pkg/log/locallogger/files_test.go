@@ -0,0 +1,39 @@
+package locallogger
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "debug.json")
+
+	// Use a real logger to produce rotated backups
+	ll := NewKitLogger(logPath)
+	require.NoError(t, ll.Log("msg", "first"))
+	require.NoError(t, ll.lj.Rotate())
+	require.NoError(t, ll.Log("msg", "second"))
+	require.NoError(t, ll.Close())
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "debug-notes.json"), []byte("{}"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other.json"), []byte("{}"), 0644))
+
+	files, err := LogFiles(logPath)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	require.Equal(t, logPath, files[1])
+
+	r, err := OpenLogFile(files[0])
+	require.NoError(t, err)
+	contents, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	require.Contains(t, string(contents), "first")
+}
@@ -0,0 +1,97 @@
+package logquery
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"time"
+)
+
+const followPollInterval = 500 * time.Millisecond
+
+// Follow calls fn for each matching entry appended to the log file at logFilePath, until ctx is
+// canceled. It starts from the current end of the file. The file is only held open while reading,
+// so that launcher remains free to rotate it; after rotation, Follow continues with the new file.
+func Follow(ctx context.Context, logFilePath string, filter Filter, fn func(Entry) error) error {
+	var offset int64
+	var current os.FileInfo
+	if fi, err := os.Stat(logFilePath); err == nil {
+		offset = fi.Size()
+		current = fi
+	}
+
+	var partial []byte
+	ticker := time.NewTicker(followPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		fi, err := os.Stat(logFilePath)
+		if errors.Is(err, fs.ErrNotExist) {
+			// Mid-rotation, or launcher has not started logging yet
+			continue
+		} else if err != nil {
+			return fmt.Errorf("checking log file: %w", err)
+		}
+
+		// Start over with the new file after rotation
+		if current != nil && (!os.SameFile(current, fi) || fi.Size() < offset) {
+			offset = 0
+			partial = nil
+		}
+		current = fi
+
+		if fi.Size() == offset {
+			continue
+		}
+
+		data, err := readFrom(logFilePath, offset)
+		if err != nil {
+			return err
+		}
+		offset += int64(len(data))
+
+		// Only process complete lines; hold on to any partial line until the rest is written
+		data = append(partial, data...)
+		lastNewline := bytes.LastIndexByte(data, '\n')
+		if lastNewline < 0 {
+			partial = data
+			continue
+		}
+		partial = bytes.Clone(data[lastNewline+1:])
+
+		for _, line := range bytes.Split(data[:lastNewline], []byte("\n")) {
+			if err := matchLine(line, filter, fn); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func readFrom(path string, offset int64) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening log file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("seeking in log file: %w", err)
+	}
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading log file: %w", err)
+	}
+
+	return data, nil
+}
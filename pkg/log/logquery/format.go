@@ -0,0 +1,73 @@
+package logquery
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// summaryFields are shown in the leading columns of the text format, rather than as key=value pairs
+var summaryFields = map[string]struct{}{
+	"time":      {},
+	"ts":        {},
+	"level":     {},
+	"msg":       {},
+	"component": {},
+}
+
+// Text formats the entry as a single human-readable line: timestamp, level, component, and
+// message, followed by the remaining fields as sorted key=value pairs.
+func (e Entry) Text() string {
+	var sb strings.Builder
+
+	if e.Time.IsZero() {
+		sb.WriteString("-")
+	} else {
+		sb.WriteString(e.Time.UTC().Format(time.RFC3339Nano))
+	}
+	fmt.Fprintf(&sb, " %-5s", e.Level.String())
+	if e.Component != "" {
+		fmt.Fprintf(&sb, " [%s]", e.Component)
+	}
+	if e.Message != "" {
+		sb.WriteString(" ")
+		sb.WriteString(e.Message)
+	}
+
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		if _, ok := summaryFields[k]; ok {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		sb.WriteString(" ")
+		sb.WriteString(k)
+		sb.WriteString("=")
+		sb.WriteString(formatValue(e.Fields[k]))
+	}
+
+	return sb.String()
+}
+
+func formatValue(v any) string {
+	switch val := v.(type) {
+	case string:
+		if val == "" || strings.ContainsAny(val, " \t\n\"=") {
+			return strconv.Quote(val)
+		}
+		return val
+	default:
+		b, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Sprintf("%v", val)
+		}
+		return string(b)
+	}
+}
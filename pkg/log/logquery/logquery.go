@@ -0,0 +1,192 @@
+// Package logquery searches launcher's local JSON logs (debug.json and its rotated backups),
+// which include osquery's own stdout/stderr logs, for use by `launcher logs`.
+package logquery
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kolide/launcher/v2/pkg/log/locallogger"
+)
+
+// Source selects logs by where they originated.
+type Source string
+
+const (
+	SourceAll      Source = "all"
+	SourceLauncher Source = "launcher"
+	SourceOsquery  Source = "osquery"
+)
+
+// maxLineBytes bounds a single log line; launcher truncates large values well before this.
+const maxLineBytes = 1024 * 1024
+
+// Entry is a single parsed log line.
+type Entry struct {
+	Time      time.Time
+	Level     slog.Level
+	Message   string
+	Component string
+	Fields    map[string]any
+	Raw       []byte
+}
+
+// Filter selects log entries. Zero values match everything.
+type Filter struct {
+	MinLevel     slog.Level
+	Components   []string // matches entries from any of these components
+	Since        time.Time
+	Until        time.Time
+	EnrollmentID string
+	Text         string // case-insensitive substring of the raw log line
+	Source       Source
+}
+
+// Matches returns true if the entry satisfies all the filter's criteria.
+func (f Filter) Matches(e Entry) bool {
+	if e.Level < f.MinLevel {
+		return false
+	}
+
+	if len(f.Components) > 0 && !slices.Contains(f.Components, e.Component) {
+		return false
+	}
+
+	// Entries without a timestamp cannot be placed in a time range
+	if !f.Since.IsZero() && (e.Time.IsZero() || e.Time.Before(f.Since)) {
+		return false
+	}
+	if !f.Until.IsZero() && (e.Time.IsZero() || e.Time.After(f.Until)) {
+		return false
+	}
+
+	if f.EnrollmentID != "" {
+		if enrollmentID, _ := e.Fields["enrollment_id"].(string); enrollmentID != f.EnrollmentID {
+			return false
+		}
+	}
+
+	if f.Text != "" && !bytes.Contains(bytes.ToLower(e.Raw), []byte(strings.ToLower(f.Text))) {
+		return false
+	}
+
+	switch f.Source {
+	case SourceOsquery:
+		return e.isOsquery()
+	case SourceLauncher:
+		return !e.isOsquery()
+	default:
+		return true
+	}
+}
+
+// isOsquery returns true for osquery's stdout/stderr logs, which launcher tags with osqlevel
+// (see osquerylogs.OsqueryLogAdapter).
+func (e Entry) isOsquery() bool {
+	_, ok := e.Fields["osqlevel"]
+	return ok
+}
+
+// ParseEntry parses a log line written by either slog's JSON handler or go-kit's JSON logger.
+func ParseEntry(line []byte) (Entry, error) {
+	fields := make(map[string]any)
+	if err := json.Unmarshal(line, &fields); err != nil {
+		return Entry{}, fmt.Errorf("parsing log line: %w", err)
+	}
+
+	e := Entry{
+		Level:  slog.LevelInfo, // go-kit logs do not always have a level
+		Fields: fields,
+		Raw:    line,
+	}
+
+	// slog uses `time`; go-kit uses `ts`
+	for _, key := range []string{"time", "ts"} {
+		if ts, ok := fields[key].(string); ok {
+			if t, err := time.Parse(time.RFC3339Nano, ts); err == nil {
+				e.Time = t
+				break
+			}
+		}
+	}
+
+	if lvl, ok := fields["level"].(string); ok {
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(lvl)); err == nil {
+			e.Level = level
+		}
+	}
+
+	e.Message, _ = fields["msg"].(string)
+	e.Component, _ = fields["component"].(string)
+
+	return e, nil
+}
+
+// Query calls fn, oldest first, for each entry in the log file at logFilePath and its rotated
+// backups that matches the filter. Lines that are not valid JSON are skipped.
+func Query(ctx context.Context, logFilePath string, filter Filter, fn func(Entry) error) error {
+	files, err := locallogger.LogFiles(logFilePath)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if err := queryFile(ctx, file, filter, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func queryFile(ctx context.Context, path string, filter Filter, fn func(Entry) error) error {
+	r, err := locallogger.OpenLogFile(path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineBytes)
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := matchLine(scanner.Bytes(), filter, fn); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func matchLine(line []byte, filter Filter, fn func(Entry) error) error {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return nil
+	}
+
+	// The scanner reuses its buffer, so take a copy before handing the line off
+	e, err := ParseEntry(bytes.Clone(line))
+	if err != nil {
+		return nil
+	}
+
+	if !filter.Matches(e) {
+		return nil
+	}
+
+	return fn(e)
+}
@@ -0,0 +1,196 @@
+package logquery
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	slogLine      = `{"time":"2024-05-01T10:00:00.000Z","level":"INFO","source":{"function":"main"},"msg":"osquery instance started","component":"osquery_instance","enrollment_id":"default"}`
+	kitLine       = `{"caller":"extension.go:100","level":"debug","msg":"checked in","ts":"2024-05-01T11:00:00.000Z"}`
+	osqueryLine   = `{"time":"2024-05-01T12:00:00.000Z","level":"WARN","msg":"W0501 osquery warning","component":"osquery","osqlevel":"stderr","enrollment_id":"second"}`
+	notJSONLine   = `panic: something went very wrong`
+	rotatedLine   = `{"time":"2024-04-30T09:00:00.000Z","level":"ERROR","msg":"from yesterday","component":"tuf_autoupdater"}`
+	rotatedGzLine = `{"time":"2024-04-29T09:00:00.000Z","level":"INFO","msg":"from two days ago","component":"tuf_autoupdater"}`
+)
+
+func writeTestLogs(t *testing.T) string {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "debug.json")
+	require.NoError(t, os.WriteFile(logPath, []byte(slogLine+"\n"+kitLine+"\n"+notJSONLine+"\n"+osqueryLine+"\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "debug-2024-04-30T23-59-59.000.json"), []byte(rotatedLine+"\n"), 0644))
+
+	gzFile, err := os.Create(filepath.Join(dir, "debug-2024-04-29T23-59-59.000.json.gz"))
+	require.NoError(t, err)
+	gz := gzip.NewWriter(gzFile)
+	_, err = gz.Write([]byte(rotatedGzLine + "\n"))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	require.NoError(t, gzFile.Close())
+
+	// Not a log file
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "debug-notes.json"), []byte(slogLine+"\n"), 0644))
+
+	return logPath
+}
+
+func queryMessages(t *testing.T, logPath string, filter Filter) []string {
+	messages := make([]string, 0)
+	require.NoError(t, Query(context.TODO(), logPath, filter, func(e Entry) error {
+		messages = append(messages, e.Message)
+		return nil
+	}))
+	return messages
+}
+
+func TestQuery(t *testing.T) {
+	t.Parallel()
+
+	logPath := writeTestLogs(t)
+
+	for _, tt := range []struct {
+		name     string
+		filter   Filter
+		expected []string
+	}{
+		{
+			name:     "everything, oldest first",
+			filter:   Filter{MinLevel: slog.LevelDebug},
+			expected: []string{"from two days ago", "from yesterday", "osquery instance started", "checked in", "W0501 osquery warning"},
+		},
+		{
+			name:     "default level excludes debug",
+			filter:   Filter{},
+			expected: []string{"from two days ago", "from yesterday", "osquery instance started", "W0501 osquery warning"},
+		},
+		{
+			name:     "min level",
+			filter:   Filter{MinLevel: slog.LevelWarn},
+			expected: []string{"from yesterday", "W0501 osquery warning"},
+		},
+		{
+			name:     "component",
+			filter:   Filter{Components: []string{"tuf_autoupdater"}},
+			expected: []string{"from two days ago", "from yesterday"},
+		},
+		{
+			name: "time range",
+			filter: Filter{
+				MinLevel: slog.LevelDebug,
+				Since:    time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+				Until:    time.Date(2024, 5, 1, 11, 30, 0, 0, time.UTC),
+			},
+			expected: []string{"osquery instance started", "checked in"},
+		},
+		{
+			name:     "enrollment id",
+			filter:   Filter{EnrollmentID: "second"},
+			expected: []string{"W0501 osquery warning"},
+		},
+		{
+			name:     "free text",
+			filter:   Filter{MinLevel: slog.LevelDebug, Text: "EXTENSION.GO"},
+			expected: []string{"checked in"},
+		},
+		{
+			name:     "osquery source",
+			filter:   Filter{Source: SourceOsquery},
+			expected: []string{"W0501 osquery warning"},
+		},
+		{
+			name:     "launcher source",
+			filter:   Filter{Source: SourceLauncher, Components: []string{"osquery_instance", "osquery"}},
+			expected: []string{"osquery instance started"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.expected, queryMessages(t, logPath, tt.filter))
+		})
+	}
+}
+
+func TestQuery_stopsOnCallbackError(t *testing.T) {
+	t.Parallel()
+
+	logPath := writeTestLogs(t)
+	stop := errors.New("stop")
+	seen := 0
+	err := Query(context.TODO(), logPath, Filter{}, func(e Entry) error {
+		seen++
+		return stop
+	})
+	require.ErrorIs(t, err, stop)
+	require.Equal(t, 1, seen)
+}
+
+func TestEntry_Text(t *testing.T) {
+	t.Parallel()
+
+	e, err := ParseEntry([]byte(osqueryLine))
+	require.NoError(t, err)
+	require.Equal(t, `2024-05-01T12:00:00Z WARN  [osquery] W0501 osquery warning enrollment_id=second osqlevel=stderr`, e.Text())
+
+	e, err = ParseEntry([]byte(slogLine))
+	require.NoError(t, err)
+	require.Equal(t, `2024-05-01T10:00:00Z INFO  [osquery_instance] osquery instance started enrollment_id=default source={"function":"main"}`, e.Text())
+}
+
+func TestFollow(t *testing.T) {
+	t.Parallel()
+
+	logPath := writeTestLogs(t)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+
+	entries := make(chan Entry, 10)
+	followErr := make(chan error, 1)
+	go func() {
+		followErr <- Follow(ctx, logPath, Filter{}, func(e Entry) error {
+			entries <- e
+			return nil
+		})
+	}()
+
+	// Give Follow a moment to find the end of the existing file
+	time.Sleep(followPollInterval)
+
+	f, err := os.OpenFile(logPath, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"level":"INFO","msg":"appended`)
+	require.NoError(t, err)
+	time.Sleep(2 * followPollInterval)
+	_, err = f.WriteString("\"}\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	select {
+	case e := <-entries:
+		require.Equal(t, "appended", e.Message)
+	case <-time.After(5 * time.Second):
+		t.Fatal("did not follow appended line")
+	}
+
+	// Simulate rotation: the file is replaced with a new, smaller one
+	require.NoError(t, os.Rename(logPath, logPath+".old"))
+	require.NoError(t, os.WriteFile(logPath, []byte(`{"level":"INFO","msg":"rotated"}`+"\n"), 0644))
+
+	select {
+	case e := <-entries:
+		require.Equal(t, "rotated", e.Message)
+	case <-time.After(5 * time.Second):
+		t.Fatal("did not follow after rotation")
+	}
+
+	cancel()
+	require.NoError(t, <-followErr)
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/kolide/kit/version"
 	"github.com/kolide/launcher/v2/ee/agent/flags/keys"
 	"github.com/kolide/launcher/v2/ee/agent/storage"
+	agentsqlite "github.com/kolide/launcher/v2/ee/agent/storage/sqlite"
 	"github.com/kolide/launcher/v2/ee/agent/types"
 	"github.com/kolide/launcher/v2/ee/gowrapper"
 	"github.com/kolide/launcher/v2/ee/observability"
@@ -31,17 +32,21 @@ const (
 	defaultSendInterval   = 1 * time.Minute
 	debugSendInterval     = 5 * time.Second
 	maxDrainTimeout       = 5 * time.Second
+	// maxSpoolSizeBytes bounds the logs held on disk while we are unable to ship them
+	maxSpoolSizeBytes = 10 * 1024 * 1024
 )
 
 type LogShipper struct {
 	sender     *authedHttpSender
 	sendBuffer *sendbuffer.SendBuffer
+	// spool holds logs on disk when they do not fit in the send buffer; may be nil
+	spool types.LogStore
 	//shippingLogger is the logs that will be shipped
 	shippingLogger log.Logger
 	//baseLogger is for logShipper internal logging
 	baseLogger          log.Logger
 	knapsack            types.Knapsack
-	stopFunc            context.CancelFunc
+	stopFunc            func()
 	stopFuncMutex       sync.Mutex
 	isShippingStarted   *atomic.Bool
 	slogLevel           *slog.LevelVar
@@ -53,7 +58,20 @@ func New(k types.Knapsack, baseLogger log.Logger) *LogShipper {
 	sender := newAuthHttpSender()
 
 	sendInterval := defaultSendInterval
-	sendBuffer := sendbuffer.New(sender, sendbuffer.WithSendInterval(sendInterval), sendbuffer.WithLogger(baseLogger))
+
+	// Spool logs to disk when offline, so that they survive until we're able to ship them
+	var spool types.LogStore
+	var sendBuffer *sendbuffer.SendBuffer
+	if spoolStore, err := agentsqlite.OpenRW(context.TODO(), k.RootDirectory(), agentsqlite.LogSpoolStore); err != nil {
+		level.Info(baseLogger).Log(
+			"msg", "could not open log spool, logs will not be persisted while offline",
+			"err", err,
+		)
+		sendBuffer = sendbuffer.New(sender, sendbuffer.WithSendInterval(sendInterval), sendbuffer.WithLogger(baseLogger))
+	} else {
+		spool = spoolStore
+		sendBuffer = sendbuffer.New(sender, sendbuffer.WithSendInterval(sendInterval), sendbuffer.WithLogger(baseLogger), sendbuffer.WithSpool(spoolStore, maxSpoolSizeBytes))
+	}
 
 	// setting a ulid as session_ulid allows us to follow a single run of launcher
 	shippingLogger := log.With(log.NewJSONLogger(sendBuffer), "caller", log.Caller(6), "session_ulid", ulid.New())
@@ -61,6 +79,7 @@ func New(k types.Knapsack, baseLogger log.Logger) *LogShipper {
 	ls := &LogShipper{
 		sender:              sender,
 		sendBuffer:          sendBuffer,
+		spool:               spool,
 		shippingLogger:      shippingLogger,
 		baseLogger:          log.With(baseLogger, "component", "logshipper"),
 		knapsack:            k,
@@ -140,8 +159,16 @@ func (ls *LogShipper) Run() error {
 
 	ctx, cancel := context.WithCancel(context.Background())
 
+	// Stop must wait for the send buffer to stop sending before it drains the buffer and
+	// closes the spool.
+	runDone := make(chan struct{})
+	defer close(runDone)
+
 	ls.stopFuncMutex.Lock()
-	ls.stopFunc = cancel
+	ls.stopFunc = func() {
+		cancel()
+		<-runDone
+	}
 	ls.stopFuncMutex.Unlock()
 
 	return ls.sendBuffer.Run(ctx)
@@ -165,7 +192,32 @@ func (ls *LogShipper) Stop(_ error) {
 			"err",
 			err,
 		)
+
+		// Keep whatever we couldn't send for the next run
+		if err := ls.sendBuffer.SpillToSpool(); err != nil {
+			ls.knapsack.Slogger().Log(context.Background(), slog.LevelError,
+				"could not spool buffered logs",
+				"err", err,
+			)
+		}
+	}
+
+	ls.closeSpool()
+}
+
+// closeSpool closes the log spool, if it is open.
+func (ls *LogShipper) closeSpool() {
+	if ls.spool == nil {
+		return
+	}
+
+	if err := ls.spool.Close(); err != nil {
+		ls.knapsack.Slogger().Log(context.Background(), slog.LevelWarn,
+			"could not close log spool",
+			"err", err,
+		)
 	}
+	ls.spool = nil
 }
 
 func (ls *LogShipper) Log(keyvals ...any) error {
@@ -42,6 +42,7 @@ func TestLogShipper(t *testing.T) {
 			t.Parallel()
 
 			knapsack := mocks.NewKnapsack(t)
+			knapsack.On("RootDirectory").Return(t.TempDir())
 			knapsack.On("RegisterChangeObserver", mock.Anything, keys.LogShippingLevel, keys.LogIngestServerURL)
 			knapsack.On("LogShippingLevel").Return("info").Times(5)
 			knapsack.On("CurrentRunningOsqueryVersion").Return("5.12.3")
@@ -52,6 +53,7 @@ func TestLogShipper(t *testing.T) {
 
 			// no auth token
 			ls := New(knapsack, log.NewNopLogger())
+			t.Cleanup(ls.closeSpool)
 			require.False(t, ls.isShippingStarted.Load(), "shipping should not have stared since there is no auth token")
 
 			// no ingest server url
@@ -135,6 +137,7 @@ func TestStop_Multiple(t *testing.T) {
 	t.Parallel()
 
 	knapsack := mocks.NewKnapsack(t)
+	knapsack.On("RootDirectory").Return(t.TempDir())
 
 	tokenStore := testKVStore(t, storage.TokenStore.String())
 	authToken := ulid.New()
@@ -206,6 +209,7 @@ func TestStopWithoutRun(t *testing.T) {
 	t.Parallel()
 
 	knapsack := mocks.NewKnapsack(t)
+	knapsack.On("RootDirectory").Return(t.TempDir())
 	tokenStore := testKVStore(t, storage.TokenStore.String())
 	authToken := ulid.New()
 
@@ -254,6 +258,7 @@ func TestUpdateLogShippingLevel(t *testing.T) {
 	t.Parallel()
 
 	knapsack := mocks.NewKnapsack(t)
+	knapsack.On("RootDirectory").Return(t.TempDir())
 	tokenStore := testKVStore(t, storage.TokenStore.String())
 	authToken := ulid.New()
 
@@ -275,6 +280,7 @@ func TestUpdateLogShippingLevel(t *testing.T) {
 	})
 
 	ls := New(knapsack, log.NewNopLogger())
+	t.Cleanup(ls.closeSpool)
 	// new immediately calls Ping -> updateLogShippingLevel, expect that we are initialized with correct log level
 	require.Equal(t, slog.LevelWarn, ls.slogLevel.Level())
 
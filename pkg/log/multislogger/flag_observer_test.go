@@ -8,6 +8,7 @@ import (
 
 	"github.com/kolide/launcher/v2/ee/agent/flags/keys"
 	"github.com/kolide/launcher/v2/ee/agent/types/mocks"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMultiSlogger_FlagsChanged_DuplicateLogWindow(t *testing.T) {
@@ -57,6 +58,40 @@ func TestMultiSlogger_FlagsChanged_OtherFlags(t *testing.T) {
 
 	// Verify that DuplicateLogWindow() was NOT called since we didn't pass that flag
 	mockFlags.AssertNotCalled(t, "DuplicateLogWindow")
+	mockFlags.AssertNotCalled(t, "DuplicateLogWindowOverrides")
+}
+
+func TestMultiSlogger_FlagsChanged_DuplicateLogWindowOverrides(t *testing.T) {
+	t.Parallel()
+
+	ms := NewWithDedup(50 * time.Millisecond)
+	ms.Start(t.Context())
+	defer ms.Stop()
+
+	mockFlags := mocks.NewFlags(t)
+	mockFlags.On("DuplicateLogWindowOverrides").Return("osquery_instance=5m").Once()
+	ms.SetFlags(mockFlags)
+
+	ms.FlagsChanged(t.Context(), keys.DuplicateLogWindowOverrides)
+
+	mockFlags.AssertNotCalled(t, "DuplicateLogWindow")
+
+	// Invalid overrides are logged rather than applied -- should not panic
+	mockFlags.On("DuplicateLogWindowOverrides").Return("osquery_instance").Once()
+	ms.FlagsChanged(t.Context(), keys.DuplicateLogWindowOverrides)
+}
+
+func TestMultiSlogger_UpdateDuplicateLogWindowOverrides(t *testing.T) {
+	t.Parallel()
+
+	ms := NewWithDedup(50 * time.Millisecond)
+
+	require.NoError(t, ms.UpdateDuplicateLogWindowOverrides("osquery_instance=5m,tablehelpers=0s"))
+	require.NoError(t, ms.UpdateDuplicateLogWindowOverrides(""))
+	require.Error(t, ms.UpdateDuplicateLogWindowOverrides("osquery_instance=soon"))
+
+	var nilMs *MultiSlogger
+	require.NoError(t, nilMs.UpdateDuplicateLogWindowOverrides("osquery_instance=5m"))
 }
 
 func TestMultiSlogger_FlagsChanged_NilFlags(t *testing.T) {
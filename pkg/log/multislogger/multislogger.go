@@ -2,6 +2,7 @@ package multislogger
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"sync/atomic"
 	"time"
@@ -178,6 +179,21 @@ func (m *MultiSlogger) UpdateDuplicateLogWindow(window time.Duration) {
 	m.dedupEngine.SetDuplicateLogWindow(window)
 }
 
+// UpdateDuplicateLogWindowOverrides updates the dedup engine's per-component window overrides,
+// given as comma-separated component=duration pairs. Invalid overrides are rejected, leaving
+// the current overrides in place.
+func (m *MultiSlogger) UpdateDuplicateLogWindowOverrides(rawOverrides string) error {
+	if m == nil || m.dedupEngine == nil {
+		return nil
+	}
+	overrides, err := dedup.ParseWindowOverrides(rawOverrides)
+	if err != nil {
+		return fmt.Errorf("parsing duplicate log window overrides: %w", err)
+	}
+	m.dedupEngine.SetComponentWindows(overrides)
+	return nil
+}
+
 // SetFlags sets the flags interface for this MultiSlogger to listen for flag changes.
 func (m *MultiSlogger) SetFlags(flags types.Flags) {
 	if m == nil {
@@ -187,20 +203,24 @@ func (m *MultiSlogger) SetFlags(flags types.Flags) {
 }
 
 // FlagsChanged implements types.FlagsChangeObserver to respond to flag changes.
-// When the DuplicateLogWindow flag changes, it updates the dedup engine configuration.
+// When the DuplicateLogWindow or DuplicateLogWindowOverrides flags change, it updates
+// the dedup engine configuration.
 func (m *MultiSlogger) FlagsChanged(ctx context.Context, flagKeys ...keys.FlagKey) {
 	if m == nil || m.flags == nil {
 		return
 	}
 
-	// Check if DuplicateLogWindow flag is among the changed flags
-	for _, key := range flagKeys {
-		if key != keys.DuplicateLogWindow {
-			continue
+	if keys.Contains(flagKeys, keys.DuplicateLogWindow) {
+		m.UpdateDuplicateLogWindow(m.flags.DuplicateLogWindow())
+	}
+
+	if keys.Contains(flagKeys, keys.DuplicateLogWindowOverrides) {
+		if err := m.UpdateDuplicateLogWindowOverrides(m.flags.DuplicateLogWindowOverrides()); err != nil {
+			m.Log(ctx, slog.LevelWarn,
+				"could not update duplicate log window overrides",
+				"err", err,
+			)
 		}
-		newWindow := m.flags.DuplicateLogWindow()
-		m.UpdateDuplicateLogWindow(newWindow)
-		return
 	}
 }
 
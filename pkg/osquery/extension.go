@@ -18,6 +18,7 @@ import (
 	"github.com/kolide/launcher/v2/ee/agent/storage"
 	"github.com/kolide/launcher/v2/ee/agent/types"
 	"github.com/kolide/launcher/v2/ee/observability"
+	"github.com/kolide/launcher/v2/ee/queryprofiler"
 	"github.com/kolide/launcher/v2/ee/uninstall"
 	"github.com/kolide/launcher/v2/pkg/backoff"
 	"github.com/kolide/launcher/v2/pkg/service"
@@ -1112,6 +1113,15 @@ func (e *Extension) WriteResults(ctx context.Context, results []distributed.Resu
 	ctx, span := observability.StartSpan(ctx)
 	defer span.End()
 
+	// Record execution stats for these queries before shipping them -- we log but do not
+	// return errors here, since profiling should never interfere with result delivery.
+	if err := queryprofiler.RecordDistributedResults(ctx, e.knapsack.QueryProfilesStore(), results); err != nil {
+		e.slogger.Log(ctx, slog.LevelWarn,
+			"could not record distributed query profiles",
+			"err", err,
+		)
+	}
+
 	return e.writeResultsWithReenroll(ctx, results, true)
 }
 
@@ -84,6 +84,9 @@ func makeKnapsack(t *testing.T) *mocks.Knapsack {
 	err = serverProvidedDataStore.Set([]byte("organization_id"), []byte("54321"))
 	require.NoError(t, err)
 	m.On("ServerProvidedDataStore").Return(serverProvidedDataStore).Maybe()
+	queryProfilesStore, err := storageci.NewStore(t, multislogger.NewNopLogger(), storage.QueryProfilesStore.String())
+	require.NoError(t, err)
+	m.On("QueryProfilesStore").Return(queryProfilesStore).Maybe()
 
 	return m
 }
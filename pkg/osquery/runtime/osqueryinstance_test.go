@@ -536,6 +536,7 @@ func TestReloadKatcExtension(t *testing.T) {
 	s.On("WriteSettings").Return(nil).Maybe()
 	osqHistory := setupHistory(t, k)
 	k.On("ServerReleaseTrackerDataStore").Return(inmemory.NewStore()).Maybe()
+	k.On("QueryProfilesStore").Return(inmemory.NewStore()).Maybe()
 	lpc := makeTestOsqLogPublisher(t, k)
 	testServer := setupMockDeviceServer(t)
 	k.On("KolideServerURL").Return(testServer).Maybe()
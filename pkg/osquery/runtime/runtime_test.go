@@ -416,6 +416,7 @@ func TestPing(t *testing.T) {
 	k.On("DeregisterChangeObserver", mock.Anything).Maybe().Return()
 	k.On("UseCachedDataForScheduledQueries").Return(true).Maybe()
 	k.On("ServerReleaseTrackerDataStore").Return(inmemory.NewStore()).Maybe()
+	k.On("QueryProfilesStore").Return(inmemory.NewStore()).Maybe()
 	lpc := makeTestOsqLogPublisher(t, k)
 	testServer := setupMockDeviceServer(t)
 	k.On("KolideServerURL").Return(testServer).Maybe()
@@ -1234,6 +1235,7 @@ func setUpMockStores(t *testing.T, k *typesMocks.Knapsack) {
 	k.On("BboltDB").Return(storageci.SetupDB(t)).Maybe()
 	k.On("WindowsUpdatesCacheStore").Return(inmemory.NewStore()).Maybe()
 	k.On("ServerReleaseTrackerDataStore").Return(inmemory.NewStore()).Maybe()
+	k.On("QueryProfilesStore").Return(inmemory.NewStore()).Maybe()
 }
 
 func setupHistory(t *testing.T, k *typesMocks.Knapsack) *history.History {
@@ -48,6 +48,11 @@ func LauncherInfoTable(knapsack types.Knapsack, slogger *slog.Logger, configStor
 		table.TextColumn("hardware_key"),
 		table.TextColumn("hardware_key_source"),
 
+		// A rotated-in hardware key that the server has not yet acknowledged
+		table.TextColumn("pending_hardware_key"),
+		table.TextColumn("pending_hardware_key_signature"),
+		table.TextColumn("pending_hardware_key_rotated_at"),
+
 		// Old RSA Key
 		table.TextColumn("fingerprint"),
 		table.TextColumn("public_key"),
@@ -125,6 +130,16 @@ func generateLauncherInfoTable(knapsack types.Knapsack, configStore types.Getter
 			return results, nil
 		}
 
+		if rotator, ok := agent.HardwareKeyRotator(); ok {
+			if pending := rotator.PendingKeyRotation(); pending != nil {
+				if pendingKeyDer, err := x509.MarshalPKIXPublicKey(pending.Signer.Public()); err == nil {
+					results[0]["pending_hardware_key"] = base64.StdEncoding.EncodeToString(pendingKeyDer)
+					results[0]["pending_hardware_key_signature"] = base64.StdEncoding.EncodeToString(pending.Signature)
+					results[0]["pending_hardware_key_rotated_at"] = pending.RotatedAt.Format(time.RFC3339)
+				}
+			}
+		}
+
 		if runtime.GOOS == "darwin" && agent.HardwareKeys() != nil && agent.HardwareKeys().Public() != nil {
 			jsonBytes, err := json.Marshal(agent.HardwareKeys())
 			if err != nil {
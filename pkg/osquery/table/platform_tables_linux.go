@@ -49,6 +49,7 @@ func platformSpecificTables(k types.Knapsack, slogger *slog.Logger, currentOsque
 		falcon_kernel_check.TablePlugin(k, slogger),
 		falconctl.NewFalconctlOptionTable(k, slogger),
 		xfconf.TablePlugin(k, slogger),
+		TpmAttestation(k, slogger),
 
 		dataflattentable.NewExecAndParseTable(k, slogger, "kolide_nmcli_wifi", key_value.NewWithDelimiter(":"), allowedcmd.Nmcli, []string{"--mode=multiline", "--fields=all", "device", "wifi", "list"}),
 		dataflattentable.NewExecAndParseTable(k, slogger, "kolide_lsblk", json.Parser, allowedcmd.Lsblk, []string{"-fJp"}),
@@ -13,6 +13,7 @@ import (
 	"github.com/kolide/launcher/v2/ee/allowedcmd"
 	"github.com/kolide/launcher/v2/ee/filewalker"
 	"github.com/kolide/launcher/v2/ee/katc"
+	"github.com/kolide/launcher/v2/ee/tables/allowedcmdaudit"
 	"github.com/kolide/launcher/v2/ee/tables/cryptoinfotable"
 	"github.com/kolide/launcher/v2/ee/tables/dataflattentable"
 	"github.com/kolide/launcher/v2/ee/tables/desktopprocs"
@@ -22,6 +23,7 @@ import (
 	"github.com/kolide/launcher/v2/ee/tables/jwt"
 	"github.com/kolide/launcher/v2/ee/tables/launcher_db"
 	"github.com/kolide/launcher/v2/ee/tables/osquery_instance_history"
+	"github.com/kolide/launcher/v2/ee/tables/query_profiles"
 	"github.com/kolide/launcher/v2/ee/tables/release_tracker_data"
 	"github.com/kolide/launcher/v2/ee/tables/secretscan"
 	"github.com/kolide/launcher/v2/ee/tables/sleeper"
@@ -47,9 +49,11 @@ func LauncherTables(k types.Knapsack, slogger *slog.Logger) []osquery.OsqueryPlu
 		),
 		LauncherAutoupdateConfigTable(slogger, k),
 		osquery_instance_history.TablePlugin(k, slogger),
+		query_profiles.TablePlugin(k, slogger, k.QueryProfilesStore()),
 		release_tracker_data.TablePlugin(k, slogger, k.ServerReleaseTrackerDataStore()),
 		tufinfo.TufReleaseVersionTable(slogger, k),
 		desktopprocs.TablePlugin(k, slogger),
+		allowedcmdaudit.TablePlugin(k, slogger),
 	}
 }
 
@@ -0,0 +1,154 @@
+package table
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/kolide/launcher/v2/ee/agent"
+	"github.com/kolide/launcher/v2/ee/agent/types"
+	"github.com/kolide/launcher/v2/ee/observability"
+	"github.com/kolide/launcher/v2/ee/tables/tablehelpers"
+	"github.com/kolide/launcher/v2/ee/tables/tablewrapper"
+	"github.com/kolide/launcher/v2/ee/tpmrunner"
+	"github.com/osquery/osquery-go/plugin/table"
+)
+
+const (
+	minAttestationNonceBytes = 8
+	maxAttestationNonceBytes = 32
+)
+
+type tpmAttester interface {
+	Attest(ctx context.Context, nonce []byte) (*tpmrunner.Attestation, error)
+	ActivateCredential(ctx context.Context, credentialBlob, encryptedSecret []byte) ([]byte, error)
+}
+
+func TpmAttestation(flags types.Flags, slogger *slog.Logger) *table.Plugin {
+	t := &tpmAttestationTable{
+		slogger: slogger.With("table", "kolide_tpm_attestation"),
+	}
+	columns := []table.ColumnDefinition{
+		table.TextColumn("nonce"),
+		table.TextColumn("credential_blob"),
+		table.TextColumn("encrypted_secret"),
+		table.TextColumn("activated_credential"),
+		table.TextColumn("attestation_key"),
+		table.TextColumn("endorsement_key"),
+		table.TextColumn("ek_certificate"),
+		table.TextColumn("certify_info"),
+		table.TextColumn("certify_signature"),
+		table.TextColumn("pending_certify_info"),
+		table.TextColumn("pending_certify_signature"),
+		table.TextColumn("quote"),
+		table.TextColumn("quote_signature"),
+		table.TextColumn("pcrs"),
+	}
+
+	return tablewrapper.New(flags, slogger, "kolide_tpm_attestation", columns, t.generate,
+		tablewrapper.WithDescription("TPM2 attestation of launcher's hardware key, bound to a caller-provided hex nonce: a certification of the key by a TPM attestation key, and a quote of the boot PCRs. Requires a WHERE nonce = constraint. The attestation key is only trusted once it answers a credential challenge made against the endorsement key: pass the base64 credential_blob and encrypted_secret from TPM2_MakeCredential to receive the activated_credential. Together, useful for verifying that the hardware key is TPM-resident."),
+	)
+}
+
+type tpmAttestationTable struct {
+	slogger *slog.Logger
+}
+
+func (t *tpmAttestationTable) generate(ctx context.Context, queryContext table.QueryContext) ([]map[string]string, error) {
+	ctx, span := observability.StartSpan(ctx, "table_name", "kolide_tpm_attestation")
+	defer span.End()
+
+	nonces := tablehelpers.GetConstraints(queryContext, "nonce")
+	if len(nonces) == 0 {
+		return nil, errors.New("the kolide_tpm_attestation table requires a nonce")
+	}
+
+	attester, ok := agent.HardwareKeys().(tpmAttester)
+	if !ok {
+		t.slogger.Log(ctx, slog.LevelDebug,
+			"hardware keys do not support attestation",
+			"hardware_key_source", agent.HardwareKeys().Type(),
+		)
+		return nil, nil
+	}
+
+	// Credential activation is optional, and at most one challenge may be answered per query
+	credentialBlobs := tablehelpers.GetConstraints(queryContext, "credential_blob")
+	encryptedSecrets := tablehelpers.GetConstraints(queryContext, "encrypted_secret")
+	if len(credentialBlobs) > 1 || len(encryptedSecrets) > 1 || len(credentialBlobs) != len(encryptedSecrets) {
+		return nil, errors.New("credential_blob and encrypted_secret must be provided together, at most once")
+	}
+
+	var credentialBlobB64, encryptedSecretB64, activatedCredential string
+	if len(credentialBlobs) == 1 {
+		credentialBlobB64, encryptedSecretB64 = credentialBlobs[0], encryptedSecrets[0]
+		credentialBlob, err := base64.StdEncoding.DecodeString(credentialBlobB64)
+		if err != nil {
+			return nil, fmt.Errorf("decoding credential_blob: %w", err)
+		}
+		encryptedSecret, err := base64.StdEncoding.DecodeString(encryptedSecretB64)
+		if err != nil {
+			return nil, fmt.Errorf("decoding encrypted_secret: %w", err)
+		}
+
+		credential, err := attester.ActivateCredential(ctx, credentialBlob, encryptedSecret)
+		if err != nil {
+			t.slogger.Log(ctx, slog.LevelInfo,
+				"activating tpm credential",
+				"err", err,
+			)
+			return nil, nil
+		}
+		activatedCredential = base64.StdEncoding.EncodeToString(credential)
+	}
+
+	var results []map[string]string
+	for _, nonceHex := range nonces {
+		nonce, err := hex.DecodeString(nonceHex)
+		if err != nil || len(nonce) < minAttestationNonceBytes || len(nonce) > maxAttestationNonceBytes {
+			return nil, fmt.Errorf("nonce must be %d to %d hex-encoded bytes", minAttestationNonceBytes, maxAttestationNonceBytes)
+		}
+
+		attestation, err := attester.Attest(ctx, nonce)
+		if err != nil {
+			t.slogger.Log(ctx, slog.LevelInfo,
+				"creating tpm attestation",
+				"err", err,
+			)
+			continue
+		}
+
+		pcrs := make(map[string]string, len(attestation.PCRs))
+		for index, value := range attestation.PCRs {
+			pcrs[strconv.Itoa(index)] = hex.EncodeToString(value)
+		}
+		pcrsJson, err := json.Marshal(pcrs)
+		if err != nil {
+			return nil, fmt.Errorf("marshalling pcrs: %w", err)
+		}
+
+		results = append(results, map[string]string{
+			"nonce":                     nonceHex,
+			"credential_blob":           credentialBlobB64,
+			"encrypted_secret":          encryptedSecretB64,
+			"activated_credential":      activatedCredential,
+			"attestation_key":           base64.StdEncoding.EncodeToString(attestation.AttestationKey),
+			"endorsement_key":           base64.StdEncoding.EncodeToString(attestation.EndorsementKey),
+			"ek_certificate":            base64.StdEncoding.EncodeToString(attestation.EndorsementKeyCertificate),
+			"certify_info":              base64.StdEncoding.EncodeToString(attestation.CertifyInfo),
+			"certify_signature":         base64.StdEncoding.EncodeToString(attestation.CertifySignature),
+			"pending_certify_info":      base64.StdEncoding.EncodeToString(attestation.PendingCertifyInfo),
+			"pending_certify_signature": base64.StdEncoding.EncodeToString(attestation.PendingCertifySignature),
+			"quote":                     base64.StdEncoding.EncodeToString(attestation.Quote),
+			"quote_signature":           base64.StdEncoding.EncodeToString(attestation.QuoteSignature),
+			"pcrs":                      string(pcrsJson),
+		})
+	}
+
+	return results, nil
+}
@@ -270,6 +270,13 @@ func (p *PackageOptions) Build(ctx context.Context, packageWriter io.Writer, tar
 		}
 	}
 
+	// Install the polkit action used for presence detection on linux
+	if p.target.Platform == Linux {
+		if err := p.renderPolkitPolicy(ctx); err != nil {
+			return fmt.Errorf("render: %w", err)
+		}
+	}
+
 	// amazon linux ami uses an upstart so old, it doesn't have
 	// integrated logging. So we'll need a logrotate config.
 	if p.target.Init == UpstartAmazonAMI {
@@ -536,6 +543,38 @@ func (p *PackageOptions) renderLogrotateConfig(ctx context.Context) error {
 	return nil
 }
 
+// renderPolkitPolicy installs the polkit action that desktop presence detection checks
+// authorization for. The action ID includes the identifier, so that packages for different
+// identifiers don't define the same action; it must match the one in ee/presencedetection.
+func (p *PackageOptions) renderPolkitPolicy(ctx context.Context) error {
+	policyDirectory := filepath.Join("/usr", "share", "polkit-1", "actions")
+
+	if err := os.MkdirAll(filepath.Join(p.packageRoot, policyDirectory), fsutil.DirMode); err != nil {
+		return fmt.Errorf("making polkit actions dir: %w", err)
+	}
+
+	policyPath := filepath.Join(p.packageRoot, policyDirectory, fmt.Sprintf("com.kolide.launcher.%s.policy", p.Identifier))
+	policyFile, err := os.Create(policyPath)
+	if err != nil {
+		return fmt.Errorf("creating polkit policy file: %w", err)
+	}
+	defer policyFile.Close()
+
+	policyTemplate, err := assets.ReadFile("assets/polkit-presence.policy")
+	if err != nil {
+		return fmt.Errorf("failed to get template named %s: %w", "assets/polkit-presence.policy", err)
+	}
+
+	tmpl, err := template.New("polkit").Parse(string(policyTemplate))
+	if err != nil {
+		return fmt.Errorf("not able to parse polkit policy template: %w", err)
+	}
+	if err := tmpl.ExecuteTemplate(policyFile, "polkit", struct{ Identifier string }{Identifier: p.Identifier}); err != nil {
+		return fmt.Errorf("execute template: %w", err)
+	}
+	return nil
+}
+
 // setupInit setups the init scripts.
 //
 // Note that windows is a special
@@ -506,3 +506,22 @@ func TestGeneratePosixRootDir(t *testing.T) {
 		})
 	}
 }
+
+func Test_renderPolkitPolicy(t *testing.T) {
+	t.Parallel()
+
+	p := &PackageOptions{
+		Identifier:  "kolide-nababe-k2",
+		packageRoot: t.TempDir(),
+		target: Target{
+			Platform: Linux,
+		},
+	}
+
+	require.NoError(t, p.renderPolkitPolicy(t.Context()))
+
+	policy, err := os.ReadFile(filepath.Join(p.packageRoot, "usr", "share", "polkit-1", "actions", "com.kolide.launcher.kolide-nababe-k2.policy"))
+	require.NoError(t, err)
+	require.Contains(t, string(policy), `<action id="com.kolide.launcher.kolide-nababe-k2.detect-presence">`)
+	require.Contains(t, string(policy), "<allow_active>auth_self</allow_active>")
+}
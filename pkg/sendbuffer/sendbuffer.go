@@ -41,6 +41,17 @@ type SendBuffer struct {
 	isSending  bool
 	// logsJustPurged is used to prevent attempting to delete logs that were just purged
 	logsJustPurged bool
+	// inFlight is the number of logs, from the start of logs, that are currently being sent.
+	// They are left in memory when spilling to the spool, so that they are not sent twice.
+	inFlight int
+
+	// spool, if set, holds logs on disk that did not fit in memory -- see WithSpool
+	spool             spool
+	spoolMutex        sync.Mutex
+	spoolSize         int
+	maxSpoolSizeBytes int
+	// evictedThrough is the highest rowid evicted from the spool, so that sends do not double-count evicted rows
+	evictedThrough int64
 
 	// configurables
 	logger                                                     log.Logger
@@ -111,6 +122,12 @@ func New(sender sender, opts ...option) *SendBuffer {
 
 	sb.logger = log.With(sb.logger, "component", "sendbuffer")
 
+	if sb.spool != nil {
+		if err := sb.loadSpoolSize(); err != nil {
+			sb.logger.Log("msg", "could not load spool, spooled data may not be sent", "err", err)
+		}
+	}
+
 	return sb
 }
 
@@ -140,13 +157,24 @@ func (sb *SendBuffer) Write(in []byte) (int, error) {
 	}
 
 	// if we are full, something has backed up
-	// purge everything
+	// spill everything to the spool if we have one, otherwise purge everything
+	if len(in)+sb.size > sb.maxStorageSizeBytes && sb.spool != nil {
+		if err := sb.spill(); err != nil {
+			sb.logger.Log(
+				"msg", "could not spill data to spool",
+				"method", "Write",
+				"err", err,
+			)
+		}
+	}
+
 	if len(in)+sb.size > sb.maxStorageSizeBytes {
 		sb.deleteLogs(len(sb.logs))
 
 		// mark that we have just purged the logs so that any waiting deletes
 		// will not try to delete what was purged
 		sb.logsJustPurged = true
+		sb.inFlight = 0
 
 		sb.logger.Log(
 			"msg", "reached capacity, dropping all data and starting over",
@@ -181,7 +209,7 @@ func (sb *SendBuffer) Run(ctx context.Context) error {
 	}()
 
 	for {
-		if _, err := sb.sendAndPurge(ctx); err != nil {
+		if _, err := sb.send(ctx); err != nil {
 			sb.logger.Log("msg", "failed to send and purge", "err", err)
 		}
 
@@ -291,11 +319,20 @@ func (sb *SendBuffer) DeleteAllData() {
 	defer sb.writeMutex.Unlock()
 	sb.logs = nil
 	sb.size = 0
+	sb.logsJustPurged = true
+	sb.inFlight = 0
+
+	if sb.spool != nil {
+		if err := sb.deleteSpool(); err != nil {
+			sb.logger.Log("msg", "could not delete spooled data", "err", err)
+		}
+	}
 }
 
 // Attempts to drain the send buffer, sending everything present through its configured
 // sender until empty, ctx is done, or it drains at least the number of logs originally
-// present at call.
+// present at call. Only logs held in memory are drained; spooled logs are left for the
+// next run.
 func (sb *SendBuffer) Drain(ctx context.Context) error {
 	storedLines := func() int {
 		sb.writeMutex.Lock()
@@ -329,6 +366,14 @@ func (sb *SendBuffer) Drain(ctx context.Context) error {
 	return nil
 }
 
+// send sends the oldest data available: from the spool if anything is spooled, otherwise from memory.
+func (sb *SendBuffer) send(ctx context.Context) (int, error) {
+	if sb.hasSpooledLogs() {
+		return sb.sendSpooledAndPurge(ctx)
+	}
+	return sb.sendAndPurge(ctx)
+}
+
 // Sends from the buffer if anything is present, returning the number of entries sent
 // or an error.
 func (sb *SendBuffer) sendAndPurge(ctx context.Context) (int, error) {
@@ -348,10 +393,7 @@ func (sb *SendBuffer) sendAndPurge(ctx context.Context) (int, error) {
 		return 0, nil
 	}
 
-	if err := sb.sender.Send(ctx, toSendBuff); err != nil {
-		sb.logger.Log("msg", "failed to send, will retry", "err", err)
-		return 0, err
-	}
+	sendErr := sb.sender.Send(ctx, toSendBuff)
 
 	// testing on a new enrollment in debug mode, log size hit 130K bytes
 	// before enrollment completed and was able to ship logs
@@ -359,6 +401,13 @@ func (sb *SendBuffer) sendAndPurge(ctx context.Context) (int, error) {
 	sb.writeMutex.Lock()
 	defer sb.writeMutex.Unlock()
 
+	sb.inFlight = 0
+
+	if sendErr != nil {
+		sb.logger.Log("msg", "failed to send, will retry", "err", sendErr)
+		return 0, sendErr
+	}
+
 	// There is a possibility that the log buffer gets full while were in the middle of sending
 	// and gets deleted. However, we don't want to block writes while were waiting on a network call
 	// to send the logs. To live with this, we just verify that the logs didn't just get purged.
@@ -376,8 +425,12 @@ func (sb *SendBuffer) sendAndPurge(ctx context.Context) (int, error) {
 // before for copying and returning when the next log would exceed the maxSize,
 // it's up to the caller to delete any copied logs
 func (sb *SendBuffer) copyLogs(w io.Writer, maxSizeBytes int) (int, error) {
-	sb.writeMutex.RLock()
-	defer sb.writeMutex.RUnlock()
+	sb.writeMutex.Lock()
+	defer sb.writeMutex.Unlock()
+
+	// any purge before this point does not affect the logs we are copying, so it
+	// should not prevent their deletion once sent
+	sb.logsJustPurged = false
 
 	size := 0
 	lastLogIndex := 0
@@ -395,6 +448,8 @@ func (sb *SendBuffer) copyLogs(w io.Writer, maxSizeBytes int) (int, error) {
 		lastLogIndex++
 	}
 
+	sb.inFlight = lastLogIndex
+
 	return lastLogIndex, nil
 }
 
@@ -0,0 +1,237 @@
+package sendbuffer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kolide/launcher/v2/ee/agent/types"
+)
+
+// spool is on-disk storage for logs that do not fit in the in-memory buffer -- see agentsqlite.LogSpoolStore.
+type spool interface {
+	types.TimestampedIterator
+	types.TimestampedAppender
+	types.RowDeleter
+}
+
+// errStopIteration is used to end iteration over the spool early
+var errStopIteration = errors.New("stop iteration")
+
+// WithSpool spills logs to the given store, up to maxSizeBytes, instead of dropping them when the
+// in-memory buffer is full. Spooled logs survive restarts, and are sent oldest-first, before any
+// logs held in memory. When the spool is full, the oldest spooled logs are dropped.
+func WithSpool(s spool, maxSizeBytes int) option {
+	return func(sb *SendBuffer) {
+		sb.spool = s
+		sb.maxSpoolSizeBytes = maxSizeBytes
+	}
+}
+
+// loadSpoolSize sets the current spool size from the logs already in the spool, e.g. from a previous run.
+func (sb *SendBuffer) loadSpoolSize() error {
+	sb.spoolMutex.Lock()
+	defer sb.spoolMutex.Unlock()
+
+	size := 0
+	if err := sb.spool.ForEach(func(_, _ int64, v []byte) error {
+		size += len(v)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("reading spool: %w", err)
+	}
+
+	sb.spoolSize = size
+	return sb.evictFromSpool()
+}
+
+// SpillToSpool writes all logs held in memory to the spool, if one is configured, so that they are
+// not lost when launcher exits.
+func (sb *SendBuffer) SpillToSpool() error {
+	sb.writeMutex.Lock()
+	defer sb.writeMutex.Unlock()
+
+	if sb.spool == nil || len(sb.logs) == 0 {
+		return nil
+	}
+
+	return sb.spill()
+}
+
+// spill moves the logs held in memory to the spool, in rows no larger than the max send size.
+// Logs that are currently being sent stay in memory, to be deleted once the send completes.
+// It's up to the caller to lock the write mutex.
+func (sb *SendBuffer) spill() error {
+	sb.spoolMutex.Lock()
+	defer sb.spoolMutex.Unlock()
+
+	timestamp := time.Now().Unix()
+	row := &bytes.Buffer{}
+	appendRow := func() error {
+		if row.Len() == 0 {
+			return nil
+		}
+		if err := sb.spool.AppendValue(timestamp, row.Bytes()); err != nil {
+			return fmt.Errorf("appending to spool: %w", err)
+		}
+		sb.spoolSize += row.Len()
+		row.Reset()
+		return nil
+	}
+
+	inFlight := min(sb.inFlight, len(sb.logs))
+	spilledSize := 0
+	for _, l := range sb.logs[inFlight:] {
+		if row.Len()+len(l) > sb.maxSendSizeBytes {
+			if err := appendRow(); err != nil {
+				return err
+			}
+		}
+		row.Write(l)
+		spilledSize += len(l)
+	}
+	if err := appendRow(); err != nil {
+		return err
+	}
+
+	sb.logs = sb.logs[:inFlight]
+	sb.size -= spilledSize
+
+	return sb.evictFromSpool()
+}
+
+// evictFromSpool deletes the oldest spooled logs until the spool is within its maximum size.
+// It's up to the caller to lock the spool mutex.
+func (sb *SendBuffer) evictFromSpool() error {
+	if sb.spoolSize <= sb.maxSpoolSizeBytes {
+		return nil
+	}
+
+	toFree := sb.spoolSize - sb.maxSpoolSizeBytes
+	freed := 0
+	var rowids []any
+	var lastRowid int64
+	if err := sb.spool.ForEach(func(rowid, _ int64, v []byte) error {
+		if freed >= toFree {
+			return errStopIteration
+		}
+		rowids = append(rowids, rowid)
+		lastRowid = rowid
+		freed += len(v)
+		return nil
+	}); err != nil && !errors.Is(err, errStopIteration) {
+		return fmt.Errorf("reading spool for eviction: %w", err)
+	}
+
+	if err := sb.spool.DeleteRows(rowids...); err != nil {
+		return fmt.Errorf("evicting from spool: %w", err)
+	}
+
+	sb.spoolSize -= freed
+	sb.evictedThrough = max(sb.evictedThrough, lastRowid)
+
+	sb.logger.Log(
+		"msg", "spool reached capacity, dropped oldest data",
+		"method", "evictFromSpool",
+		"dropped_bytes", freed,
+		"spool_size_bytes", sb.spoolSize,
+		"max_spool_size", sb.maxSpoolSizeBytes,
+	)
+
+	return nil
+}
+
+func (sb *SendBuffer) hasSpooledLogs() bool {
+	if sb.spool == nil {
+		return false
+	}
+
+	sb.spoolMutex.Lock()
+	defer sb.spoolMutex.Unlock()
+	return sb.spoolSize > 0
+}
+
+// sendSpooledAndPurge sends the oldest spooled logs, up to the max send size, returning the
+// number of spooled rows sent or an error.
+func (sb *SendBuffer) sendSpooledAndPurge(ctx context.Context) (int, error) {
+	if !sb.sendMutex.TryLock() {
+		sb.logger.Log("msg", "could not get lock on send mutex, will retry")
+		return 0, nil
+	}
+	defer sb.sendMutex.Unlock()
+
+	toSendBuff := &bytes.Buffer{}
+	var rowids []int64
+	var rowSizes []int
+
+	sb.spoolMutex.Lock()
+	err := sb.spool.ForEach(func(rowid, _ int64, v []byte) error {
+		// always send at least one row, even if it's larger than the current max send size
+		if toSendBuff.Len() > 0 && toSendBuff.Len()+len(v) > sb.maxSendSizeBytes {
+			return errStopIteration
+		}
+		toSendBuff.Write(v)
+		rowids = append(rowids, rowid)
+		rowSizes = append(rowSizes, len(v))
+		return nil
+	})
+	sb.spoolMutex.Unlock()
+	if err != nil && !errors.Is(err, errStopIteration) {
+		return 0, fmt.Errorf("reading spool: %w", err)
+	}
+
+	if len(rowids) == 0 {
+		return 0, nil
+	}
+
+	if err := sb.sender.Send(ctx, toSendBuff); err != nil {
+		sb.logger.Log("msg", "failed to send spooled data, will retry", "err", err)
+		return 0, err
+	}
+
+	sb.spoolMutex.Lock()
+	defer sb.spoolMutex.Unlock()
+
+	toDelete := make([]any, len(rowids))
+	sizeDeleted := 0
+	for i, rowid := range rowids {
+		toDelete[i] = rowid
+		// rows may have been evicted while we were sending; their size has already been accounted for
+		if rowid > sb.evictedThrough {
+			sizeDeleted += rowSizes[i]
+		}
+	}
+
+	if err := sb.spool.DeleteRows(toDelete...); err != nil {
+		return 0, fmt.Errorf("deleting sent rows from spool: %w", err)
+	}
+	sb.spoolSize -= sizeDeleted
+
+	return len(rowids), nil
+}
+
+// deleteSpool deletes everything in the spool.
+func (sb *SendBuffer) deleteSpool() error {
+	sb.spoolMutex.Lock()
+	defer sb.spoolMutex.Unlock()
+
+	var rowids []any
+	var lastRowid int64
+	if err := sb.spool.ForEach(func(rowid, _ int64, _ []byte) error {
+		rowids = append(rowids, rowid)
+		lastRowid = rowid
+		return nil
+	}); err != nil {
+		return fmt.Errorf("reading spool: %w", err)
+	}
+
+	if err := sb.spool.DeleteRows(rowids...); err != nil {
+		return fmt.Errorf("deleting from spool: %w", err)
+	}
+
+	sb.spoolSize = 0
+	sb.evictedThrough = max(sb.evictedThrough, lastRowid)
+	return nil
+}
@@ -0,0 +1,165 @@
+package sendbuffer
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	agentsqlite "github.com/kolide/launcher/v2/ee/agent/storage/sqlite"
+	"github.com/stretchr/testify/require"
+)
+
+func testSpool(t *testing.T) spool {
+	s, err := agentsqlite.OpenRW(t.Context(), t.TempDir(), agentsqlite.LogSpoolStore)
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func spooledRows(t *testing.T, s spool) []string {
+	var rows []string
+	require.NoError(t, s.ForEach(func(_, _ int64, v []byte) error {
+		rows = append(rows, string(v))
+		return nil
+	}))
+	return rows
+}
+
+func TestSpool_spillsWhenFullAndSendsOldestFirst(t *testing.T) {
+	t.Parallel()
+
+	s := testSpool(t)
+	sender := &drainTestSender{}
+	sb := New(sender, WithMaxStorageSizeBytes(4), WithMaxSendSizeBytes(4), WithSpool(s, 1000))
+
+	for _, line := range []string{"01", "23", "45", "67", "8"} {
+		_, err := sb.Write([]byte(line))
+		require.NoError(t, err)
+	}
+
+	// Memory filled up twice, so the first two writes of each fill were spilled rather than dropped
+	require.Equal(t, []string{"0123", "4567"}, spooledRows(t, s))
+	require.Equal(t, [][]byte{[]byte("8")}, sb.logs)
+
+	for range 3 {
+		_, err := sb.send(t.Context())
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, "012345678", sender.receivedData())
+	require.Empty(t, spooledRows(t, s))
+	require.Zero(t, sb.spoolSize)
+	require.Empty(t, sb.logs)
+}
+
+// blockingSender blocks each send until released.
+type blockingSender struct {
+	started  chan struct{}
+	release  chan struct{}
+	received bytes.Buffer
+}
+
+func (s *blockingSender) Send(_ context.Context, r io.Reader) error {
+	s.started <- struct{}{}
+	<-s.release
+	_, err := io.Copy(&s.received, r)
+	return err
+}
+
+func TestSpool_doesNotSpillLogsBeingSent(t *testing.T) {
+	t.Parallel()
+
+	s := testSpool(t)
+	sender := &blockingSender{started: make(chan struct{}), release: make(chan struct{})}
+	sb := New(sender, WithMaxStorageSizeBytes(6), WithMaxSendSizeBytes(4), WithSpool(s, 1000))
+
+	for _, line := range []string{"01", "23"} {
+		_, err := sb.Write([]byte(line))
+		require.NoError(t, err)
+	}
+
+	sendDone := make(chan error)
+	go func() {
+		_, err := sb.sendAndPurge(context.TODO())
+		sendDone <- err
+	}()
+	<-sender.started
+
+	// Memory fills up while "0123" is being sent, so only the logs written since are spilled
+	for _, line := range []string{"45", "67"} {
+		_, err := sb.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	require.Equal(t, []string{"45"}, spooledRows(t, s))
+
+	close(sender.release)
+	require.NoError(t, <-sendDone)
+	require.Equal(t, [][]byte{[]byte("67")}, sb.logs)
+
+	go func() {
+		for range sender.started {
+		}
+	}()
+	for range 2 {
+		_, err := sb.send(t.Context())
+		require.NoError(t, err)
+	}
+	close(sender.started)
+
+	// Everything is sent exactly once, oldest first
+	require.Equal(t, "01234567", sender.received.String())
+	require.Empty(t, spooledRows(t, s))
+	require.Empty(t, sb.logs)
+}
+
+func TestSpool_survivesRestart(t *testing.T) {
+	t.Parallel()
+
+	s := testSpool(t)
+	sb := New(&drainTestSender{}, WithSpool(s, 1000))
+	_, err := sb.Write([]byte("before restart"))
+	require.NoError(t, err)
+	require.NoError(t, sb.SpillToSpool())
+	require.Empty(t, sb.logs)
+
+	sender := &drainTestSender{}
+	restarted := New(sender, WithSpool(s, 1000))
+	require.Equal(t, len("before restart"), restarted.spoolSize)
+
+	_, err = restarted.send(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, "before restart", sender.receivedData())
+	require.Empty(t, spooledRows(t, s))
+}
+
+func TestSpool_evictsOldest(t *testing.T) {
+	t.Parallel()
+
+	s := testSpool(t)
+	sb := New(&drainTestSender{}, WithMaxSendSizeBytes(2), WithSpool(s, 4))
+
+	for _, line := range []string{"aa", "bb", "cc"} {
+		_, err := sb.Write([]byte(line))
+		require.NoError(t, err)
+		require.NoError(t, sb.SpillToSpool())
+	}
+
+	require.Equal(t, []string{"bb", "cc"}, spooledRows(t, s))
+	require.Equal(t, 4, sb.spoolSize)
+}
+
+func TestSpool_DeleteAllData(t *testing.T) {
+	t.Parallel()
+
+	s := testSpool(t)
+	sb := New(&drainTestSender{}, WithSpool(s, 1000))
+	_, err := sb.Write([]byte("spooled"))
+	require.NoError(t, err)
+	require.NoError(t, sb.SpillToSpool())
+
+	sb.DeleteAllData()
+
+	require.Empty(t, spooledRows(t, s))
+	require.False(t, sb.hasSpooledLogs())
+}